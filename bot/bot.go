@@ -6,14 +6,20 @@ import (
 	"fmt"
 	"log"
 	"runtime/debug"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 	dbi "vrcmemes-bot/internal/database"
 	"vrcmemes-bot/internal/database/models" // Import models
+	"vrcmemes-bot/internal/debugmode"
+	"vrcmemes-bot/internal/errbudget"
 	"vrcmemes-bot/internal/handlers"
+	"vrcmemes-bot/internal/incidents"
 	"vrcmemes-bot/internal/locales"
 	"vrcmemes-bot/internal/mediagroups"
+	"vrcmemes-bot/internal/publisher"
+	"vrcmemes-bot/internal/simulation"
 	"vrcmemes-bot/internal/suggestions"
 	telegoapi "vrcmemes-bot/pkg/telegoapi"
 
@@ -29,8 +35,7 @@ import (
 type Bot struct {
 	bot           telegoapi.BotAPI // Use BotAPI interface
 	updatesChan   <-chan telego.Update
-	mediaGroups   sync.Map
-	debug         bool
+	debug         *debugmode.Switch // Runtime debug logging and update tracing, see /debug and /trace
 	channelID     int64
 	captionProv   dbi.CaptionProvider
 	postLogger    dbi.PostLogger
@@ -41,14 +46,25 @@ type Bot struct {
 	actionLogger  dbi.UserActionLogger
 	mediaGroupMgr *mediagroups.Manager
 	handler       *handlers.MessageHandler
+	publishQueue  *publisher.Queue
+	timeouts      UpdateTimeouts
+	recentUpdates *recentUpdates           // In-memory deduplication of resent updates
+	updateStore   dbi.ProcessedUpdateStore // Optional persistent deduplication (nil if disabled)
+	errorBudget   *errbudget.Tracker       // Optional (nil if disabled)
+	incidents     *incidents.Recorder      // Optional (nil if disabled)
 	ratelimiter   ratelimit.Limiter
+	tenantRouter  TenantRouter // Optional: set in multi-tenant mode
+	tenantsMu     sync.RWMutex
+	tenants       map[int64]*Bot // Bots of the connected channels, by channel ID
+	groups        GroupPolicy
+	discussion    discussionGroup // Cached discussion group of the channel, always allowed
 }
 
 // BotDeps holds the dependencies required by the Bot.
 type BotDeps struct {
 	Bot           telegoapi.BotAPI     // Use BotAPI interface
 	UpdatesChan   <-chan telego.Update // Receive the channel
-	Debug         bool
+	Debug         *debugmode.Switch    // Optional: nil keeps debug logging off
 	ChannelID     int64
 	CaptionProv   dbi.CaptionProvider
 	PostLogger    dbi.PostLogger
@@ -59,6 +75,12 @@ type BotDeps struct {
 	ActionLogger  dbi.UserActionLogger
 	MediaGroupMgr *mediagroups.Manager
 	Handler       *handlers.MessageHandler
+	PublishQueue  *publisher.Queue         // Paces all channel publications
+	Timeouts      UpdateTimeouts           // Per-update-type processing timeouts; zero values use defaults
+	UpdateStore   dbi.ProcessedUpdateStore // Optional: persists processed update IDs across restarts
+	ErrorBudget   *errbudget.Tracker       // Optional: counts recovered panics for error budget alerts
+	Incidents     *incidents.Recorder      // Optional: records recovered panics for /incidents
+	Groups        GroupPolicy              // Groups the bot works in; the zero value ignores other groups
 }
 
 // New creates a new Bot instance from its dependencies.
@@ -95,6 +117,9 @@ func New(deps BotDeps) (*Bot, error) {
 	if deps.Handler == nil { // Add check for Handler if it becomes essential
 		return nil, fmt.Errorf("message handler cannot be nil")
 	}
+	if deps.PublishQueue == nil {
+		return nil, fmt.Errorf("publish queue cannot be nil")
+	}
 	if deps.UpdatesChan == nil {
 		return nil, fmt.Errorf("updates channel cannot be nil") // Add check
 	}
@@ -113,6 +138,13 @@ func New(deps BotDeps) (*Bot, error) {
 		actionLogger:  deps.ActionLogger,
 		mediaGroupMgr: deps.MediaGroupMgr,
 		handler:       deps.Handler,
+		publishQueue:  deps.PublishQueue,
+		timeouts:      deps.Timeouts.withDefaults(),
+		recentUpdates: newRecentUpdates(recentUpdatesCapacity),
+		updateStore:   deps.UpdateStore,
+		errorBudget:   deps.ErrorBudget,
+		incidents:     deps.Incidents,
+		groups:        deps.Groups,
 		ratelimiter:   ratelimit.New(20),
 	}, nil
 }
@@ -127,7 +159,7 @@ func (b *Bot) handleCommandUpdate(ctx context.Context, message telego.Message) {
 
 	handlerFunc := b.handerProv.GetCommandHandler(command) // Returns func(..., telegoapi.BotAPI, ...)
 	if handlerFunc != nil {
-		if b.debug {
+		if b.debug.Enabled() {
 			log.Printf("%s Executing handler", logPrefix)
 		}
 		// Pass b.bot (which is telegoapi.BotAPI) to the handler
@@ -136,7 +168,7 @@ func (b *Bot) handleCommandUpdate(ctx context.Context, message telego.Message) {
 			log.Printf("%s Handler error: %v", logPrefix, err)
 			sentry.CaptureException(fmt.Errorf("%s handler error: %w", logPrefix, err))
 		} else {
-			if b.debug {
+			if b.debug.Enabled() {
 				log.Printf("%s Handler finished successfully", logPrefix)
 			}
 		}
@@ -161,7 +193,7 @@ func (b *Bot) handleCommandUpdate(ctx context.Context, message telego.Message) {
 func (b *Bot) handlePhotoUpdate(ctx context.Context, message telego.Message) {
 	logPrefix := fmt.Sprintf("[Photo User:%d Msg:%d]", message.From.ID, message.MessageID)
 	if message.Photo != nil && message.MediaGroupID == "" {
-		if b.debug {
+		if b.debug.Enabled() {
 			log.Printf("%s Processing single photo", logPrefix)
 		}
 		// Pass b.bot (telegoapi.BotAPI) to HandlePhoto
@@ -179,7 +211,7 @@ func (b *Bot) handlePhotoUpdate(ctx context.Context, message telego.Message) {
 func (b *Bot) handleTextUpdate(ctx context.Context, message telego.Message) {
 	logPrefix := fmt.Sprintf("[Text User:%d Msg:%d]", message.From.ID, message.MessageID)
 	if message.Text != "" && !strings.HasPrefix(message.Text, "/") {
-		if b.debug {
+		if b.debug.Enabled() {
 			log.Printf("%s Processing text message", logPrefix)
 		}
 		// Pass b.bot (telegoapi.BotAPI) to HandleText
@@ -197,7 +229,7 @@ func (b *Bot) handleTextUpdate(ctx context.Context, message telego.Message) {
 func (b *Bot) handleVideoUpdate(ctx context.Context, message telego.Message) {
 	logPrefix := fmt.Sprintf("[Video User:%d Msg:%d]", message.From.ID, message.MessageID)
 	if message.Video != nil && message.MediaGroupID == "" {
-		if b.debug {
+		if b.debug.Enabled() {
 			log.Printf("%s Processing single video", logPrefix)
 		}
 		// Pass b.bot (telegoapi.BotAPI) to HandleVideo
@@ -214,13 +246,13 @@ func (b *Bot) handleVideoUpdate(ctx context.Context, message telego.Message) {
 // handleCallbackQuery processes an incoming callback query.
 func (b *Bot) handleCallbackQuery(ctx context.Context, query telego.CallbackQuery) {
 	logPrefix := fmt.Sprintf("[Callback User:%d QueryID:%s]", query.From.ID, query.ID)
-	if b.debug {
+	if b.debug.Enabled() {
 		log.Printf("%s Received callback query with data: %q", logPrefix, query.Data)
 	}
 	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String()) // Use tag
 
-	// Delegate to suggestion manager
-	processed, err := b.suggestionMgr.HandleCallbackQuery(ctx, query)
+	// Delegate to suggestion manager; users granted /review with /perm press review buttons as admins
+	processed, err := b.suggestionMgr.HandleCallbackQuery(b.handler.GrantedCallbackContext(ctx, query), query)
 	if err != nil {
 		log.Printf("%s Suggestion callback handler error: %v", logPrefix, err)
 		sentry.CaptureException(fmt.Errorf("%s suggestion callback handler error: %w", logPrefix, err))
@@ -230,13 +262,24 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, query telego.CallbackQuer
 	}
 
 	if processed {
-		if b.debug {
+		if b.debug.Enabled() {
 			log.Printf("%s Callback handled by suggestion manager", logPrefix)
 		}
 		// Suggestion manager should answer the query.
 		return
 	}
 
+	// Buttons of the handler's own commands, such as /incidents
+	processed, err = b.handler.HandleCallback(ctx, b.bot, query)
+	if err != nil {
+		log.Printf("%s Handler callback error: %v", logPrefix, err)
+		sentry.CaptureException(fmt.Errorf("%s handler callback error: %w", logPrefix, err))
+		return
+	}
+	if processed {
+		return
+	}
+
 	// If not processed by suggestion manager, maybe log or answer with default?
 	log.Printf("%s Callback query not handled", logPrefix)
 	defaultAnswer := locales.GetMessage(localizer, "MsgCallbackNotHandled", nil, nil) // Assuming this key exists
@@ -248,24 +291,56 @@ func (b *Bot) processUpdate(ctx context.Context, update telego.Update) {
 	// Apply global rate limiting
 	b.ratelimiter.Take()
 
-	// Handle potential panics in handlers
+	// A panic in a handler becomes an incident report instead of silence for the user
 	defer func() {
 		if r := recover(); r != nil {
-			log.Printf("PANIC recovered in processUpdate: %v\n%s", r, debug.Stack())
-			sentry.CurrentHub().Recover(r)
-			sentry.Flush(time.Second * 2)
+			incident, user := incidentFromUpdate(update)
+			b.reportPanic(r, debug.Stack(), incident, user)
 		}
 	}()
 
-	// Create a context with timeout for the update processing
-	processingCtx, cancel := context.WithTimeout(ctx, 30*time.Second) // 30 seconds timeout for processing
+	// Skip updates Telegram resent because processing was slow; simulated ones have no real ID.
+	// The update counts as processed only once its handler returned, so one that panicked is
+	// processed again if resent
+	processed := false
+	if !simulation.Active(ctx) {
+		if !b.claimUpdate(ctx, update.UpdateID, b.timeouts.forUpdate(update)+updateClaimMargin) {
+			log.Printf("Skipping duplicate update %d", update.UpdateID)
+			return
+		}
+		defer func() { b.finishUpdate(ctx, update.UpdateID, processed) }()
+	}
+
+	// Raw updates of the types chosen with /trace are mirrored to the admin chat
+	b.traceUpdate(ctx, update)
+
+	// In multi-tenant mode, updates for another channel are processed by that channel's bot
+	if bot := b.tenantFor(ctx, update); bot != nil {
+		bot.handleUpdate(ctx, update)
+	}
+	processed = true
+}
+
+// Inject processes a simulated update of /simulate as if Telegram had sent it.
+func (b *Bot) Inject(ctx context.Context, update telego.Update) {
+	b.processUpdate(simulation.With(ctx), update)
+}
+
+// handleUpdate routes an update to the appropriate handlers.
+func (b *Bot) handleUpdate(ctx context.Context, update telego.Update) {
+	// Create a context with timeout for the update processing.
+	// Publishing is queued as a background job, so this only needs to cover the handler itself.
+	processingCtx, cancel := context.WithTimeout(ctx, b.timeouts.forUpdate(update))
 	defer cancel()
 
 	switch {
 	case update.Message != nil:
 		message := *update.Message
-		if message.From == nil { // Ignore messages without a sender (e.g., channel posts from linked chat)
-			log.Printf("Ignoring message %d from chat %d without sender", message.MessageID, message.Chat.ID)
+		if !b.groupAllowed(processingCtx, message.Chat) {
+			return
+		}
+		if sentOnBehalfOfChat(message) { // Anonymous admins, channels and messages without a sender
+			b.handleSenderChatMessage(processingCtx, message)
 			return
 		}
 
@@ -278,14 +353,11 @@ func (b *Bot) processUpdate(ctx context.Context, update telego.Update) {
 
 		// 1. Handle Media Groups via MediaGroupManager
 		if message.MediaGroupID != "" {
-			// Pass message, not update, to HandleMessage
-			// Add default delay and size arguments
+			// Pass message, not update, to HandleMessage; delay and size come from the manager's config
 			err := b.mediaGroupMgr.HandleMessage(
 				processingCtx,
 				message,
 				b.handleCombinedMediaGroup,
-				mediagroups.DefaultProcessDelay, // Add delay
-				mediagroups.DefaultMaxGroupSize, // Add max size
 			)
 			if err != nil {
 				log.Printf("Error handling media group %s via manager: %v", message.MediaGroupID, err)
@@ -301,7 +373,7 @@ func (b *Bot) processUpdate(ctx context.Context, update telego.Update) {
 			return
 		}
 		if processed {
-			if b.debug {
+			if b.debug.Enabled() {
 				log.Printf("Message %d processed by suggestion manager", message.MessageID)
 			}
 			return
@@ -317,7 +389,7 @@ func (b *Bot) processUpdate(ctx context.Context, update telego.Update) {
 		} else if message.Text != "" {
 			b.handleTextUpdate(processingCtx, message)
 		} else {
-			if b.debug {
+			if b.debug.Enabled() {
 				log.Printf("Ignoring unhandled message type (ID: %d)", message.MessageID)
 			}
 		}
@@ -325,8 +397,19 @@ func (b *Bot) processUpdate(ctx context.Context, update telego.Update) {
 	case update.CallbackQuery != nil:
 		b.handleCallbackQuery(processingCtx, *update.CallbackQuery)
 
+	case update.ChatMember != nil:
+		if err := b.suggestionMgr.HandleChatMemberUpdate(processingCtx, *update.ChatMember); err != nil {
+			log.Printf("Error handling chat member update in chat %d: %v", update.ChatMember.Chat.ID, err)
+			sentry.CaptureException(fmt.Errorf("chat member update error: %w", err))
+		}
+		// After the suggestion manager, which drops the cached admin status of promoted and demoted users
+		b.handler.UpdateAdminMenu(processingCtx, b.bot, *update.ChatMember)
+
+	case update.MessageReactionCount != nil:
+		b.handler.RecordReactionCount(processingCtx, *update.MessageReactionCount)
+
 	default:
-		if b.debug {
+		if b.debug.Enabled() {
 			log.Printf("Ignoring unhandled update type: %+v", update)
 		}
 	}
@@ -380,11 +463,21 @@ func (b *Bot) handleCombinedMediaGroup(ctx context.Context, groupID string, mess
 	firstMessage := messages[0]
 	userID := firstMessage.From.ID
 	chatID := firstMessage.Chat.ID
+	// Albums are handled on the media group manager's timer, outside processUpdate's recovery
+	defer func() {
+		if r := recover(); r != nil {
+			incident := models.Incident{UpdateKind: "media_group", UserID: userID, ChatID: chatID}
+			b.reportPanic(r, debug.Stack(), incident, firstMessage.From)
+		}
+	}()
 	log.Printf("[MediaGroupHandler] Processing group %s from User %d in Chat %d (%d messages)", groupID, userID, chatID, len(messages))
 
+	ctx, cancel := context.WithTimeout(ctx, b.timeouts.MediaGroup)
+	defer cancel()
+
 	userState := b.suggestionMgr.GetUserState(userID)
 
-	if userState == suggestions.StateAwaitingSuggestion || userState == suggestions.StateAwaitingFeedback {
+	if userState != suggestions.StateIdle || b.suggestionMgr.IsIntakeChat(chatID) {
 		log.Printf("[MediaGroupHandler Group:%s] Delegating to SuggestionManager (UserState: %v)", groupID, userState)
 		// Use the suggestion manager interface method
 		return b.suggestionMgr.HandleCombinedMediaGroup(ctx, groupID, messages)
@@ -414,6 +507,14 @@ func (b *Bot) handleAdminMediaGroup(ctx context.Context, groupID string, message
 		return fmt.Errorf("admin check failed: %w", err)
 	}
 	if !isAdmin {
+		// Offer to submit the album as a suggestion instead of just refusing
+		offered, offerErr := b.suggestionMgr.OfferDirectSuggestion(ctx, messages)
+		if offerErr != nil {
+			log.Printf("[AdminMediaGroup] Error offering direct suggestion to user %d: %v", userID, offerErr)
+		}
+		if offered {
+			return nil
+		}
 		log.Printf("[AdminMediaGroup] Non-admin user %d attempted to send media group directly.", userID)
 		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
 		_, _ = b.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), msg))
@@ -430,119 +531,90 @@ func (b *Bot) handleAdminMediaGroup(ctx context.Context, groupID string, message
 			caption = activeCaption
 		}
 	}
+	caption, allowed := b.handler.ScreenPost(ctx, b.bot, localizer, userID, chatID, caption)
+	if !allowed {
+		return nil
+	}
 
-	// Prepare media
-	media := make([]telego.InputMedia, 0, len(messages))
-	for i, msg := range messages {
-		var input telego.InputMedia
-		if msg.Photo != nil {
-			inputFile := telego.InputFile{FileID: msg.Photo[len(msg.Photo)-1].FileID}
-			mediaPhoto := tu.MediaPhoto(inputFile)
-			if i == 0 {
-				mediaPhoto.Caption = caption // Set caption directly
-			}
-			input = mediaPhoto
-		} else if msg.Video != nil {
-			inputFile := telego.InputFile{FileID: msg.Video.FileID}
-			mediaVideo := tu.MediaVideo(inputFile)
-			if i == 0 {
-				mediaVideo.Caption = caption // Set caption directly
-			}
-			input = mediaVideo
-		} else {
-			log.Printf("[AdminMediaGroup] Unsupported media type in group %s from user %d, skipping message %d", groupID, userID, msg.MessageID)
-			continue
+	// Prepare media; the caption goes on the first item
+	media, skipped := createInputMedia(messages, caption)
+	if len(skipped) > 0 {
+		// Tell the admin exactly which items won't reach the channel
+		positions := make([]string, len(skipped))
+		for i, pos := range skipped {
+			positions[i] = strconv.Itoa(pos)
+		}
+		warning := locales.GetMessage(localizer, "MsgAlbumItemsSkipped", map[string]interface{}{
+			"Items": strings.Join(positions, ", "),
+			"Total": len(messages),
+		}, nil)
+		if _, err := b.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), warning)); err != nil {
+			log.Printf("[AdminMediaGroup] Error sending skipped items warning for group %s: %v", groupID, err)
 		}
-		media = append(media, input)
 	}
 	if len(media) == 0 {
 		log.Printf("[AdminMediaGroup] No valid media found in group %s from user %d after filtering.", groupID, userID)
 		return nil // No media to send
 	}
 
-	// Send media group using b.bot
-	sentMessages, err := b.bot.SendMediaGroup(ctx, tu.MediaGroup(tu.ID(b.handler.GetChannelID()), media...))
-	if err != nil {
-		log.Printf("[AdminMediaGroup] Failed to send media group %s: %v", groupID, err)
-		errorMsg := locales.GetMessage(localizer, "MsgErrorSendToChannel", nil, nil)
-		_, _ = b.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), errorMsg))
-		return err
-	}
-
-	// Log post using b.handler.LogPublishedPost
-	publishedTime := time.Now()
-	channelMessageID := 0
-	if len(sentMessages) > 0 {
-		channelMessageID = sentMessages[0].MessageID
-	}
-	logEntry := models.PostLog{
-		SenderID:             userID,
-		SenderUsername:       firstMessage.From.Username,
-		Caption:              caption,
-		MessageType:          "media_group",
-		ReceivedAt:           time.Unix(int64(firstMessage.Date), 0),
-		PublishedAt:          publishedTime,
-		ChannelID:            b.handler.GetChannelID(),
-		ChannelPostID:        channelMessageID,
-		OriginalMediaGroupID: groupID,
-	}
-	if err := b.handler.LogPublishedPost(logEntry); err != nil {
-		log.Printf("Error logging admin media group post for group %s: %v", groupID, err)
-	}
-
-	// Record activity using b.handler.RecordUserActivity (assuming firstMessage is defined)
-	b.handler.RecordUserActivity(ctx, firstMessage.From, "send_media_group_to_channel", isAdmin, map[string]interface{}{
-		"chat_id":            chatID,
-		"media_group_id":     groupID,
-		"message_count":      len(messages),
-		"channel_message_id": channelMessageID,
-		"caption_used":       caption,
-	})
-
-	// Send confirmation using b.bot
-	confirmationMsg := locales.GetMessage(localizer, "MsgPostSentToChannel", nil, nil)
-	_, _ = b.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), confirmationMsg))
+	// Send media group through the publish queue as a background job
+	buildCaption := b.handler.RubricCaption(chatID, caption)
+	protect := b.handler.ProtectContent(ctx)
+	position := b.publishQueue.Submit(ctx, "media_group:"+groupID, func(ctx context.Context) error {
+		caption, err := buildCaption(ctx)
+		if err != nil {
+			return err
+		}
+		media, _ := createInputMedia(messages, caption)
+		setCaptionEntities(media, b.handler.CaptionEntities(ctx, chatID, caption))
+		params := tu.MediaGroup(tu.ID(b.handler.GetChannelID()), media...)
+		params.ProtectContent = protect
+		sentMessages, err := b.bot.SendMediaGroup(ctx, params)
+		if err != nil {
+			return err
+		}
 
-	return nil
-}
+		// Log post using b.handler.LogPublishedPost
+		publishedTime := time.Now()
+		channelMessageID := 0
+		if len(sentMessages) > 0 {
+			channelMessageID = sentMessages[0].MessageID
+		}
+		logEntry := models.PostLog{
+			SenderID:             userID,
+			SenderUsername:       firstMessage.From.Username,
+			Caption:              caption,
+			MessageType:          "media_group",
+			ReceivedAt:           time.Unix(int64(firstMessage.Date), 0),
+			PublishedAt:          publishedTime,
+			ChannelID:            b.handler.GetChannelID(),
+			ChannelPostID:        channelMessageID,
+			OriginalMediaGroupID: groupID,
+			ProtectContent:       protect,
+			Origin:               models.PostOriginDirect,
+		}
+		if err := b.handler.LogPublishedPost(logEntry); err != nil {
+			log.Printf("Error logging admin media group post for group %s: %v", groupID, err)
+		}
 
-func (b *Bot) setupCommands(ctx context.Context) error {
-	// Get commands from handler provider
-	// Assuming GetCommandHandler("") returns metadata for all commands or similar
-	// This logic needs clarification based on HandlerProvider implementation.
-	// For now, manually define commands:
-
-	defaultLang := locales.GetDefaultLanguageTag().String() // Use tag
-	localizer := locales.NewLocalizer(defaultLang)
-
-	cmds := []telego.BotCommand{
-		{
-			Command:     "start",
-			Description: locales.GetMessage(localizer, "CmdStartDescription", nil, nil),
-		},
-		{
-			Command:     "help",
-			Description: locales.GetMessage(localizer, "CmdHelpDescription", nil, nil),
-		},
-		{
-			Command:     "settings",
-			Description: locales.GetMessage(localizer, "CmdSettingsDescription", nil, nil),
-		},
-	}
-
-	// TODO: Add other commands as needed (feedback, stats, etc.)
-
-	// Set the commands using the correct parameters struct
-	params := &telego.SetMyCommandsParams{
-		Commands: cmds,
-		// Scope: telego.BotCommandScopeDefault{}, // Default scope
-		// LanguageCode: "", // Default language
-	}
-	err := b.bot.SetMyCommands(ctx, params) // Correct call, returns only error
-	if err != nil {
-		return fmt.Errorf("failed to set bot commands: %w", err)
-	}
+		b.handler.PromptRubricPost(ctx, chatID, channelMessageID)
+
+		// Record activity using b.handler.RecordUserActivity (assuming firstMessage is defined)
+		b.handler.RecordUserActivity(ctx, firstMessage.From, "send_media_group_to_channel", isAdmin, map[string]interface{}{
+			"chat_id":            chatID,
+			"media_group_id":     groupID,
+			"message_count":      len(messages),
+			"channel_message_id": channelMessageID,
+			"caption_used":       caption,
+		})
+		return nil
+	}, func(ctx context.Context, err error) {
+		if err != nil {
+			log.Printf("[AdminMediaGroup] Failed to send media group %s: %v", groupID, err)
+			sentry.CaptureException(fmt.Errorf("admin media group %s publish error: %w", groupID, err))
+		}
+		b.handler.ReportPublishResult(ctx, b.bot, localizer, chatID, err)
+	})
 
-	log.Println("Bot commands successfully set.")
-	return nil
+	return b.handler.SendPublishQueued(ctx, b.bot, localizer, chatID, position)
 }
@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// recentUpdatesCapacity is how many update IDs are remembered in memory for deduplication.
+	recentUpdatesCapacity = 2048
+	// updateClaimMargin is added to an update's processing timeout for the lease of its claim,
+	// covering the work done before and after the handler.
+	updateClaimMargin = time.Minute
+)
+
+// updateClaim is the deduplication state of an update ID.
+type updateClaim struct {
+	done  bool      // Processed; the update is never processed again
+	until time.Time // When the claim of an update still being processed expires
+}
+
+// recentUpdates remembers the most recently claimed update IDs in a ring buffer,
+// so updates Telegram resends while processing is slow are skipped. An update is claimed
+// before it is processed and marked done afterwards; if processing fails, or its claim
+// expires, a resent update is processed again.
+type recentUpdates struct {
+	mu    sync.Mutex
+	ring  []int
+	next  int
+	known map[int]updateClaim
+}
+
+// newRecentUpdates creates an empty update ID ring buffer of the given capacity.
+func newRecentUpdates(capacity int) *recentUpdates {
+	return &recentUpdates{
+		ring:  make([]int, 0, capacity),
+		known: make(map[int]updateClaim, capacity),
+	}
+}
+
+// claim claims an update ID for processing until the lease ends and reports whether it was
+// claimed. It isn't if the update was processed already or its claim hasn't expired yet.
+// When the buffer is full, the oldest ID is forgotten.
+func (r *recentUpdates) claim(updateID int, lease time.Duration) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if c, ok := r.known[updateID]; ok {
+		if c.done || now.Before(c.until) {
+			return false
+		}
+		r.known[updateID] = updateClaim{until: now.Add(lease)}
+		return true
+	}
+	if len(r.ring) < cap(r.ring) {
+		r.ring = append(r.ring, updateID)
+	} else {
+		delete(r.known, r.ring[r.next])
+		r.ring[r.next] = updateID
+		r.next = (r.next + 1) % len(r.ring)
+	}
+	r.known[updateID] = updateClaim{until: now.Add(lease)}
+	return true
+}
+
+// finish marks a claimed update ID as processed.
+func (r *recentUpdates) finish(updateID int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.known[updateID]; ok {
+		r.known[updateID] = updateClaim{done: true}
+	}
+}
+
+// release drops the claim of an update ID that wasn't processed, so it can be claimed again.
+func (r *recentUpdates) release(updateID int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c, ok := r.known[updateID]; ok && !c.done {
+		r.known[updateID] = updateClaim{}
+	}
+}
+
+// claimUpdate claims an update for processing and reports whether it should be processed, checking
+// the in-memory ring buffer first and then the persistent store if one is configured. It shouldn't
+// if it was already processed or is being processed. If the store fails, the update is processed
+// rather than dropped. A claimed update must be finished with finishUpdate.
+func (b *Bot) claimUpdate(ctx context.Context, updateID int, lease time.Duration) bool {
+	if !b.recentUpdates.claim(updateID, lease) {
+		return false
+	}
+	if b.updateStore == nil {
+		return true
+	}
+	claimed, err := b.updateStore.ClaimUpdate(ctx, updateID, lease)
+	if err != nil {
+		log.Printf("Error claiming update %d, processing anyway: %v", updateID, err)
+		return true
+	}
+	if !claimed {
+		// Processed before a restart, or by another instance that may still fail
+		b.recentUpdates.release(updateID)
+	}
+	return claimed
+}
+
+// finishUpdate records a claimed update as processed, or releases its claim if processing failed,
+// so the update is processed again if Telegram resends it.
+func (b *Bot) finishUpdate(ctx context.Context, updateID int, processed bool) {
+	if processed {
+		b.recentUpdates.finish(updateID)
+	} else {
+		b.recentUpdates.release(updateID)
+	}
+	if b.updateStore == nil {
+		return
+	}
+	var err error
+	if processed {
+		err = b.updateStore.CompleteUpdate(ctx, updateID)
+	} else {
+		err = b.updateStore.ReleaseUpdate(ctx, updateID)
+	}
+	if err != nil {
+		log.Printf("Error finishing update %d: %v", updateID, err)
+	}
+}
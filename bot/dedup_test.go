@@ -0,0 +1,144 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecentUpdatesClaimAndFinish(t *testing.T) {
+	r := newRecentUpdates(4)
+
+	require.True(t, r.claim(1, time.Minute))
+	assert.False(t, r.claim(1, time.Minute), "update being processed was claimed twice")
+	r.finish(1)
+	assert.False(t, r.claim(1, time.Minute), "processed update was claimed again")
+	// Releasing a processed update keeps it processed
+	r.release(1)
+	assert.False(t, r.claim(1, time.Minute))
+}
+
+func TestRecentUpdatesReleasedClaimCanBeRetaken(t *testing.T) {
+	r := newRecentUpdates(4)
+
+	require.True(t, r.claim(1, time.Minute))
+	r.release(1)
+	assert.True(t, r.claim(1, time.Minute), "update whose processing failed was not processed again")
+}
+
+func TestRecentUpdatesExpiredClaimCanBeRetaken(t *testing.T) {
+	r := newRecentUpdates(4)
+
+	require.True(t, r.claim(1, time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	assert.True(t, r.claim(1, time.Minute), "expired claim was not taken over")
+	assert.False(t, r.claim(1, time.Minute))
+}
+
+func TestRecentUpdatesForgetsOldestWhenFull(t *testing.T) {
+	r := newRecentUpdates(3)
+	for id := 1; id <= 4; id++ {
+		require.True(t, r.claim(id, time.Minute))
+		r.finish(id)
+	}
+
+	assert.True(t, r.claim(1, time.Minute), "oldest update was not forgotten")
+	for id := 3; id <= 4; id++ {
+		assert.False(t, r.claim(id, time.Minute), "update %d was forgotten", id)
+	}
+	assert.Len(t, r.known, 3)
+}
+
+// memoryUpdateStore is an in-memory database.ProcessedUpdateStore.
+type memoryUpdateStore struct {
+	claims map[int]updateClaim
+	err    error // Returned by every call while set
+}
+
+func (s *memoryUpdateStore) ClaimUpdate(_ context.Context, updateID int, lease time.Duration) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	if c, ok := s.claims[updateID]; ok && (c.done || time.Now().Before(c.until)) {
+		return false, nil
+	}
+	s.claims[updateID] = updateClaim{until: time.Now().Add(lease)}
+	return true, nil
+}
+
+func (s *memoryUpdateStore) CompleteUpdate(_ context.Context, updateID int) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.claims[updateID] = updateClaim{done: true}
+	return nil
+}
+
+func (s *memoryUpdateStore) ReleaseUpdate(_ context.Context, updateID int) error {
+	if s.err != nil {
+		return s.err
+	}
+	if !s.claims[updateID].done {
+		delete(s.claims, updateID)
+	}
+	return nil
+}
+
+func (s *memoryUpdateStore) EnsureRetention(context.Context, time.Duration) error { return nil }
+
+func TestUpdateProcessedBeforeRestartIsSkipped(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryUpdateStore{claims: map[int]updateClaim{}}
+	before := &Bot{recentUpdates: newRecentUpdates(4), updateStore: store}
+	require.True(t, before.claimUpdate(ctx, 1, time.Minute))
+	before.finishUpdate(ctx, 1, true)
+	assert.True(t, store.claims[1].done)
+
+	// After a restart only the store remembers the update
+	after := &Bot{recentUpdates: newRecentUpdates(4), updateStore: store}
+	assert.False(t, after.claimUpdate(ctx, 1, time.Minute))
+	assert.True(t, after.claimUpdate(ctx, 2, time.Minute))
+}
+
+func TestFailedUpdateIsProcessedAgain(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryUpdateStore{claims: map[int]updateClaim{}}
+	b := &Bot{recentUpdates: newRecentUpdates(4), updateStore: store}
+
+	require.True(t, b.claimUpdate(ctx, 1, time.Minute))
+	assert.False(t, b.claimUpdate(ctx, 1, time.Minute), "resent while processing")
+	b.finishUpdate(ctx, 1, false)
+	assert.NotContains(t, store.claims, 1)
+
+	assert.True(t, b.claimUpdate(ctx, 1, time.Minute), "resent after processing failed")
+	b.finishUpdate(ctx, 1, true)
+	assert.False(t, b.claimUpdate(ctx, 1, time.Minute))
+}
+
+func TestUpdateClaimedByAnotherInstanceIsRetriedLater(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryUpdateStore{claims: map[int]updateClaim{}}
+	other := &Bot{recentUpdates: newRecentUpdates(4), updateStore: store}
+	b := &Bot{recentUpdates: newRecentUpdates(4), updateStore: store}
+
+	require.True(t, other.claimUpdate(ctx, 1, time.Minute))
+	assert.False(t, b.claimUpdate(ctx, 1, time.Minute))
+	// The other instance failed, so the update is processed when resent
+	other.finishUpdate(ctx, 1, false)
+	assert.True(t, b.claimUpdate(ctx, 1, time.Minute))
+}
+
+func TestUpdateIsProcessedWhenStoreFails(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryUpdateStore{claims: map[int]updateClaim{}, err: errors.New("database down")}
+	b := &Bot{recentUpdates: newRecentUpdates(4), updateStore: store}
+
+	assert.True(t, b.claimUpdate(ctx, 1, time.Minute))
+	b.finishUpdate(ctx, 1, true)
+	// The ring buffer still deduplicates
+	assert.False(t, b.claimUpdate(ctx, 1, time.Minute))
+}
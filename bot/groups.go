@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// GroupPolicy says which groups the bot works in. Private chats and the channel's discussion
+// group are always allowed; messages from other groups are ignored unless listed or Open is set.
+type GroupPolicy struct {
+	Allowed []int64 // Groups the bot works in, e.g. the feedback, intake and alert chats
+	Open    bool    // Work in every group
+	Leave   bool    // Leave groups that aren't allowed instead of only ignoring them
+}
+
+// discussionGroup caches the discussion group linked to the channel, looked up once.
+type discussionGroup struct {
+	mu     sync.Mutex
+	chatID int64
+	known  bool
+}
+
+// groupAllowed reports whether the bot works in the chat a message was sent in. Messages from
+// groups that aren't allowed are dropped, and with GroupPolicy.Leave the bot leaves the group.
+func (b *Bot) groupAllowed(ctx context.Context, chat telego.Chat) bool {
+	if chat.Type != telego.ChatTypeGroup && chat.Type != telego.ChatTypeSupergroup {
+		return true
+	}
+	if b.groups.Open || slices.Contains(b.groups.Allowed, chat.ID) {
+		return true
+	}
+	linked, err := b.discussionGroupID(ctx)
+	if err != nil {
+		// Fail open, so the discussion group keeps working while Telegram is unreachable
+		log.Printf("[Groups Chat:%d] Error looking up the discussion group, allowing the message: %v", chat.ID, err)
+		return true
+	}
+	if linked == chat.ID {
+		return true
+	}
+	if !b.groups.Leave {
+		if b.debug.Enabled() {
+			log.Printf("[Groups Chat:%d] Ignoring message from group %q that isn't allowed", chat.ID, chat.Title)
+		}
+		return false
+	}
+	log.Printf("[Groups Chat:%d] Leaving group %q that isn't allowed", chat.ID, chat.Title)
+	if err := b.bot.LeaveChat(ctx, &telego.LeaveChatParams{ChatID: tu.ID(chat.ID)}); err != nil {
+		log.Printf("[Groups Chat:%d] Error leaving the group: %v", chat.ID, err)
+	}
+	return false
+}
+
+// discussionGroupID returns the discussion group linked to the channel, or 0 if it has none.
+func (b *Bot) discussionGroupID(ctx context.Context) (int64, error) {
+	b.discussion.mu.Lock()
+	defer b.discussion.mu.Unlock()
+	if b.discussion.known {
+		return b.discussion.chatID, nil
+	}
+	channel, err := b.bot.GetChat(ctx, &telego.GetChatParams{ChatID: tu.ID(b.channelID)})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the discussion group of channel %d: %w", b.channelID, err)
+	}
+	b.discussion.chatID, b.discussion.known = channel.LinkedChatID, true
+	return b.discussion.chatID, nil
+}
@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/errbudget"
+	"vrcmemes-bot/internal/incidents"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// incidentNoticeTimeout bounds telling the affected user about an incident.
+const incidentNoticeTimeout = 10 * time.Second
+
+// incidentFromUpdate describes where an update came from, for an incident report.
+// It returns the user who caused the update, if any.
+func incidentFromUpdate(update telego.Update) (models.Incident, *telego.User) {
+	incident := models.Incident{Type: models.IncidentTypePanic, UpdateID: update.UpdateID}
+	var user *telego.User
+	switch {
+	case update.Message != nil:
+		incident.UpdateKind = "message"
+		incident.ChatID = update.Message.Chat.ID
+		user = update.Message.From
+	case update.CallbackQuery != nil:
+		incident.UpdateKind = "callback_query"
+		user = &update.CallbackQuery.From
+		incident.ChatID = user.ID
+		if update.CallbackQuery.Message != nil {
+			incident.ChatID = update.CallbackQuery.Message.GetChat().ID
+		}
+	case update.ChatMember != nil:
+		incident.UpdateKind = "chat_member"
+		user = &update.ChatMember.From
+	case update.MessageReactionCount != nil:
+		incident.UpdateKind = "message_reaction_count"
+	default:
+		incident.UpdateKind = "other"
+	}
+	if user != nil {
+		incident.UserID = user.ID
+	}
+	return incident, user
+}
+
+// reportPanic handles a panic recovered while handling an update. It reports the panic to
+// Sentry and as an incident under a new incident ID and tells the affected user, if there is
+// a chat to tell them in, that something went wrong, quoting the ID. incident describes the
+// update; its ChatID is where the user is told, 0 for nowhere.
+func (b *Bot) reportPanic(recovered interface{}, stack []byte, incident models.Incident, user *telego.User) {
+	incident.Type = models.IncidentTypePanic
+	incident.ID = incidents.NewID()
+	incident.OccurredAt = time.Now()
+	incident.Summary = fmt.Sprint(recovered)
+	incident.Details = string(stack)
+	log.Printf("[Incident:%s] PANIC recovered while handling %s (update %d, user %d): %v\n%s",
+		incident.ID, incident.UpdateKind, incident.UpdateID, incident.UserID, recovered, stack)
+
+	hub := sentry.CurrentHub().Clone()
+	hub.ConfigureScope(func(scope *sentry.Scope) {
+		scope.SetTag("incident_id", incident.ID)
+		scope.SetTag("update_kind", incident.UpdateKind)
+		if incident.UserID != 0 {
+			scope.SetUser(sentry.User{ID: fmt.Sprint(incident.UserID)})
+		}
+	})
+	if eventID := hub.Recover(recovered); eventID != nil {
+		incident.SentryEventID = string(*eventID)
+	}
+	hub.Flush(2 * time.Second)
+	if b.errorBudget != nil {
+		b.errorBudget.Record(errbudget.SourcePanic)
+	}
+
+	if b.incidents != nil {
+		b.incidents.Record(incident)
+	}
+	if incident.ChatID == 0 {
+		return
+	}
+
+	// The update's context may be what ran out, so the notice gets its own
+	ctx, cancel := context.WithTimeout(context.Background(), incidentNoticeTimeout)
+	defer cancel()
+	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+	if user != nil {
+		localizer = b.handler.GetLocalizer(user)
+	}
+	text := locales.GetMessage(localizer, "MsgErrorIncident", map[string]interface{}{"IncidentID": incident.ID}, nil)
+	if _, err := b.bot.SendMessage(ctx, tu.Message(tu.ID(incident.ChatID), text)); err != nil {
+		log.Printf("[Incident:%s] Error telling chat %d about the incident: %v", incident.ID, incident.ChatID, err)
+	}
+}
@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"vrcmemes-bot/internal/auth"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// sentOnBehalfOfChat reports whether a message was sent on behalf of a chat rather than by a
+// user: by an anonymous group admin, on behalf of a channel, or without a sender at all. Telegram
+// fills From with a placeholder user (e.g. @GroupAnonymousBot) in the first two cases.
+func sentOnBehalfOfChat(message telego.Message) bool {
+	return message.From == nil || message.SenderChat != nil
+}
+
+// handleSenderChatMessage handles a message sent on behalf of a chat. Only commands are answered,
+// and channel posts copied into the discussion group get the comment of their rubric.
+// Commands sent on behalf of the channel run as an admin with the channel standing in for the
+// user; other senders, including anonymous admins of the discussion group, are asked to write as
+// themselves.
+func (b *Bot) handleSenderChatMessage(ctx context.Context, message telego.Message) {
+	senderChat := message.SenderChat
+	if senderChat == nil {
+		senderChat = &message.Chat
+	}
+	logPrefix := fmt.Sprintf("[SenderChat:%d Chat:%d]", senderChat.ID, message.Chat.ID)
+	if message.IsAutomaticForward {
+		// The discussion group copy of a channel post, which its rubric may have a comment for
+		if _, err := b.handler.HandleAutomaticForward(ctx, message); err != nil {
+			log.Printf("%s Error commenting under the channel post: %v", logPrefix, err)
+		}
+	}
+	// Channel posts copied into the discussion group and posts in channels aren't meant for the bot
+	if message.IsAutomaticForward || message.Chat.Type == telego.ChatTypeChannel || !strings.HasPrefix(message.Text, "/") {
+		if b.debug.Enabled() {
+			log.Printf("%s Ignoring message %d sent on behalf of a chat", logPrefix, message.MessageID)
+		}
+		return
+	}
+
+	// Only channel admins can post as the channel. Anonymous admins of the discussion group may
+	// not be channel admins, so they don't count.
+	if senderChat.ID != b.channelID {
+		log.Printf("%s Refusing command %q from a chat that isn't the channel", logPrefix, message.Text)
+		localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+		reply := tu.Message(tu.ID(message.Chat.ID), locales.GetMessage(localizer, "MsgErrorAnonymousSender", nil, nil)).
+			WithReplyParameters(&telego.ReplyParameters{MessageID: message.MessageID, AllowSendingWithoutReply: true})
+		if _, err := b.bot.SendMessage(ctx, reply); err != nil {
+			log.Printf("%s Error replying to the sender chat: %v", logPrefix, err)
+		}
+		return
+	}
+
+	log.Printf("%s Running command %q of an anonymous channel admin", logPrefix, message.Text)
+	message.From = &telego.User{ID: senderChat.ID, FirstName: senderChat.Title, Username: senderChat.Username}
+	b.handleCommandUpdate(auth.WithGrant(ctx, senderChat.ID), message)
+}
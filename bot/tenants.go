@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"context"
+	"log"
+
+	"github.com/mymmrac/telego"
+)
+
+// TenantRouter picks the channel an update is for in multi-tenant mode.
+type TenantRouter interface {
+	// Route returns the ID of the channel an update is for.
+	Route(ctx context.Context, update telego.Update) int64
+	// Allow counts an update for a channel and reports whether the channel is within its rate limit.
+	Allow(channelID int64) bool
+}
+
+// SetTenantRouter enables multi-tenant mode: updates the router assigns to a channel added with
+// AddTenant are processed by that channel's bot, all others by this one.
+func (b *Bot) SetTenantRouter(router TenantRouter) {
+	b.tenantRouter = router
+}
+
+// AddTenant adds the bot of a connected channel. Tenant bots aren't started: their updates come
+// from this bot's update loop, which also deduplicates them.
+func (b *Bot) AddTenant(channelID int64, tenant *Bot) {
+	b.tenantsMu.Lock()
+	defer b.tenantsMu.Unlock()
+	if b.tenants == nil {
+		b.tenants = make(map[int64]*Bot)
+	}
+	b.tenants[channelID] = tenant
+}
+
+// RemoveTenant removes the bot of a disconnected channel; its updates go to this bot again.
+func (b *Bot) RemoveTenant(channelID int64) {
+	b.tenantsMu.Lock()
+	defer b.tenantsMu.Unlock()
+	delete(b.tenants, channelID)
+}
+
+// tenantFor returns the bot that processes an update: the bot of the channel it is routed to,
+// or b itself. It returns nil if the channel is over its rate limit, and the update is dropped.
+func (b *Bot) tenantFor(ctx context.Context, update telego.Update) *Bot {
+	if b.tenantRouter == nil {
+		return b
+	}
+	channelID := b.tenantRouter.Route(ctx, update)
+	if channelID == b.channelID {
+		return b
+	}
+	b.tenantsMu.RLock()
+	tenant, ok := b.tenants[channelID]
+	b.tenantsMu.RUnlock()
+	if !ok {
+		return b
+	}
+	if !b.tenantRouter.Allow(channelID) {
+		log.Printf("[Tenants] Dropping update %d: channel %d is over its rate limit", update.UpdateID, channelID)
+		return nil
+	}
+	return tenant
+}
@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+	"vrcmemes-bot/internal/debugmode"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const (
+	// traceSendTimeout bounds mirroring one update, so tracing can't hold up processing it.
+	traceSendTimeout = 5 * time.Second
+	// maxTraceLength keeps a mirrored update within Telegram's message length limit.
+	maxTraceLength = 4000
+)
+
+// traceUpdate mirrors the raw update to the trace chat if its type is being traced.
+func (b *Bot) traceUpdate(ctx context.Context, update telego.Update) {
+	updateType := debugmode.UpdateType(update)
+	chatID, ok := b.debug.TraceChat(updateType, time.Now())
+	if !ok {
+		return
+	}
+	raw, err := json.MarshalIndent(update, "", "  ")
+	if err != nil {
+		log.Printf("[Trace] Error encoding update %d: %v", update.UpdateID, err)
+		return
+	}
+	text := string(raw)
+	if runes := []rune(text); len(runes) > maxTraceLength {
+		text = string(runes[:maxTraceLength]) + "…"
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, traceSendTimeout)
+	defer cancel()
+	_, err = b.bot.SendMessage(sendCtx, tu.Message(tu.ID(chatID), "#trace "+updateType+"\n"+text).
+		WithDisableNotification())
+	if err != nil {
+		log.Printf("[Trace] Error mirroring update %d to chat %d: %v", update.UpdateID, chatID, err)
+	}
+}
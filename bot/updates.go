@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"time"
+
+	"github.com/mymmrac/telego"
+)
+
+// UpdateCapabilities describes the optional update types the bot can process.
+// Message and callback query updates are always handled and need no flag.
+type UpdateCapabilities struct {
+	ChatMembers bool // Process chat_member updates (membership changes in chats where the bot is admin)
+	Reactions   bool // Process message_reaction_count updates (reaction counts of channel posts)
+}
+
+// AllowedUpdates returns the allowed_updates list for getUpdates/setWebhook,
+// derived from the update types processUpdate handles and the enabled capabilities.
+// If override is non-empty, it is returned as-is so operators can pin the list explicitly.
+func AllowedUpdates(caps UpdateCapabilities, override []string) []string {
+	if len(override) > 0 {
+		return override
+	}
+
+	allowed := []string{
+		telego.MessageUpdates,
+		telego.CallbackQueryUpdates,
+	}
+	if caps.ChatMembers {
+		allowed = append(allowed, telego.ChatMemberUpdates)
+	}
+	if caps.Reactions {
+		allowed = append(allowed, telego.MessageReactionCountUpdates)
+	}
+	return allowed
+}
+
+// Default processing timeouts per update type.
+const (
+	DefaultMessageTimeout       = 30 * time.Second
+	DefaultCallbackQueryTimeout = 30 * time.Second
+	DefaultChatMemberTimeout    = 10 * time.Second
+	DefaultMediaGroupTimeout    = 60 * time.Second
+)
+
+// UpdateTimeouts bounds how long processing a single update may take, per update type.
+// Long-running work such as channel publishing runs in background jobs and isn't covered by these.
+// Zero values fall back to the defaults.
+type UpdateTimeouts struct {
+	Message       time.Duration
+	CallbackQuery time.Duration
+	ChatMember    time.Duration
+	MediaGroup    time.Duration // Processing a collected album (media groups are handled after a delay, outside processUpdate)
+}
+
+// withDefaults returns the timeouts with zero values replaced by the defaults.
+func (t UpdateTimeouts) withDefaults() UpdateTimeouts {
+	if t.Message <= 0 {
+		t.Message = DefaultMessageTimeout
+	}
+	if t.CallbackQuery <= 0 {
+		t.CallbackQuery = DefaultCallbackQueryTimeout
+	}
+	if t.ChatMember <= 0 {
+		t.ChatMember = DefaultChatMemberTimeout
+	}
+	if t.MediaGroup <= 0 {
+		t.MediaGroup = DefaultMediaGroupTimeout
+	}
+	return t
+}
+
+// forUpdate returns the processing timeout for an update.
+func (t UpdateTimeouts) forUpdate(update telego.Update) time.Duration {
+	switch {
+	case update.CallbackQuery != nil:
+		return t.CallbackQuery
+	case update.ChatMember != nil:
+		return t.ChatMember
+	default:
+		return t.Message
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+	"vrcmemes-bot/internal/config"
+	"vrcmemes-bot/internal/locales"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// command is a subcommand of the bot binary.
+type command struct {
+	Name    string
+	Args    string // Arguments after the flags, for the usage line
+	Summary string
+	Run     func(cmd command, args []string) error
+}
+
+// commands lists the subcommands. The first one runs when no subcommand is given.
+var commands = []command{
+	{Name: "serve", Summary: "Run the bot (default)", Run: runServe},
+	{Name: "migrate", Summary: "Create the MongoDB indexes the bot relies on", Run: runMigrate},
+	{Name: "export", Args: "[collection...]", Summary: "Export collections as JSON lines, one file per collection", Run: runExport},
+	{Name: "import-history", Args: "<result.json>", Summary: "Log channel posts from a Telegram Desktop export", Run: runImportHistory},
+	{Name: "check-config", Summary: "Validate the configuration and print a summary", Run: runCheckConfig},
+	{Name: "set-webhook", Args: "[url]", Summary: "Set, delete or show the bot's webhook", Run: runSetWebhook},
+}
+
+func main() {
+	name, args := commands[0].Name, os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		printUsage(os.Stdout)
+		return
+	}
+
+	for _, cmd := range commands {
+		if cmd.Name != name {
+			continue
+		}
+		err := cmd.Run(cmd, args)
+		switch {
+		case err == nil:
+		case errors.Is(err, flag.ErrHelp):
+		case errors.As(err, new(usageError)):
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			os.Exit(2)
+		default:
+			log.Fatalf("%s: %v", name, err)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+	printUsage(os.Stderr)
+	os.Exit(2)
+}
+
+// printUsage lists the subcommands.
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s [command] [flags]\n\nCommands:\n", programName())
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-16s %s\n", cmd.Name, cmd.Summary)
+	}
+	fmt.Fprintf(w, "\nRun '%s <command> -h' for the flags of a command.\n", programName())
+}
+
+// usageError is returned by subcommands called with invalid arguments.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+// newFlagSet creates the flag set of a subcommand. Parse errors are returned rather than
+// exiting, and -h prints the usage line with the flags.
+func newFlagSet(cmd command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s [flags] %s\n\n%s.\n\nFlags:\n", programName(), cmd.Name, cmd.Args, cmd.Summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses the arguments of a subcommand. Invalid flags are reported as a usage error.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError(err.Error())
+	}
+	return nil
+}
+
+// loadConfig loads the configuration and initializes localization for a subcommand.
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("configuration error: %w", err)
+	}
+	locales.Init(cfg.DefaultLanguage)
+	return cfg, nil
+}
+
+// withDatabase connects to MongoDB for the duration of an operational task. The task's
+// context is cancelled on interrupt.
+func withDatabase(cfg *config.Config, task func(ctx context.Context, db *mongo.Database) error) error {
+	client, db, err := connectDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		disconnectCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := client.Disconnect(disconnectCtx); err != nil {
+			log.Printf("Error disconnecting from MongoDB: %v", err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return task(ctx, db)
+}
+
+// programName returns the name the binary was run with, for usage messages.
+func programName() string {
+	return filepath.Base(os.Args[0])
+}
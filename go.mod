@@ -8,6 +8,7 @@ require (
 	github.com/mymmrac/telego v1.0.2
 	github.com/nicksnyder/go-i18n/v2 v2.6.0
 	github.com/stretchr/testify v1.10.0
+	github.com/valyala/fasthttp v1.62.0
 	go.mongodb.org/mongo-driver v1.17.3
 	go.uber.org/ratelimit v0.3.1
 	golang.org/x/text v0.25.0
@@ -30,7 +31,6 @@ require (
 	github.com/stretchr/objx v0.5.2 // indirect
 	github.com/twitchyliquid64/golang-asm v0.15.1 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
-	github.com/valyala/fasthttp v1.62.0 // indirect
 	github.com/valyala/fastjson v1.6.4 // indirect
 	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
 	github.com/xdg-go/scram v1.1.2 // indirect
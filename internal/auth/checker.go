@@ -4,20 +4,52 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"strings"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"vrcmemes-bot/internal/cache"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
 
 	"github.com/mymmrac/telego"
 )
 
+// DefaultAdminCacheTTL is how long an admin status check is cached.
+const DefaultAdminCacheTTL = 5 * time.Minute
+
 // AdminCheckerInterface defines the interface for checking admin status.
 type AdminCheckerInterface interface {
 	IsAdmin(ctx context.Context, userID int64) (bool, error)
+	// IsSuperAdmin reports whether a user is a configured super admin.
+	IsSuperAdmin(userID int64) bool
+	// SuperAdminIDs returns the user IDs of the configured super admins.
+	SuperAdminIDs() []int64
+	// Refresh reloads the channel administrators and returns the user IDs of all admins.
+	Refresh(ctx context.Context) ([]int64, error)
+	// Invalidate drops the cached admin status of a user.
+	Invalidate(userID int64)
+}
+
+// adminKeyPrefix starts the cache keys of admin status checks.
+const adminKeyPrefix = "admin:"
+
+// adminKey returns the cache key of a user's admin status.
+func adminKey(userID int64) string {
+	return adminKeyPrefix + strconv.FormatInt(userID, 10)
 }
 
 // AdminChecker handles checking user admin status against a configured channel.
+// Results are cached per user for the cache TTL, in memory unless SetCache sets a shared cache;
+// Refresh and Invalidate update the cache early.
+// Super admins from the config are always admins, even if the channel can't be queried.
 type AdminChecker struct {
 	bot             *telego.Bot
 	targetChannelID int64
+	superAdmins     map[int64]struct{}
+
+	cache      cache.Cache
+	cacheMutex sync.RWMutex // Guards cacheTTL and superAdmins
+	cacheTTL   time.Duration
 }
 
 // NewAdminChecker creates a new AdminChecker.
@@ -32,13 +64,75 @@ func NewAdminChecker(bot *telego.Bot, channelID int64) (*AdminChecker, error) {
 	return &AdminChecker{
 		bot:             bot,
 		targetChannelID: channelID,
+		superAdmins:     make(map[int64]struct{}),
+		cache:           cache.NewMemory(),
+		cacheTTL:        DefaultAdminCacheTTL,
 	}, nil
 }
 
-// IsAdmin checks if a user is an administrator or creator in the target channel
-// configured in the AdminChecker.
+// SetSuperAdmins sets the users who are admins regardless of their channel status.
+func (ac *AdminChecker) SetSuperAdmins(userIDs []int64) {
+	superAdmins := make(map[int64]struct{}, len(userIDs))
+	for _, id := range userIDs {
+		superAdmins[id] = struct{}{}
+	}
+	ac.cacheMutex.Lock()
+	defer ac.cacheMutex.Unlock()
+	ac.superAdmins = superAdmins
+}
+
+// IsSuperAdmin reports whether a user is a configured super admin.
+func (ac *AdminChecker) IsSuperAdmin(userID int64) bool {
+	ac.cacheMutex.RLock()
+	defer ac.cacheMutex.RUnlock()
+	_, ok := ac.superAdmins[userID]
+	return ok
+}
+
+// SuperAdminIDs returns the user IDs of the configured super admins, in ascending order.
+func (ac *AdminChecker) SuperAdminIDs() []int64 {
+	ac.cacheMutex.RLock()
+	defer ac.cacheMutex.RUnlock()
+	ids := make([]int64, 0, len(ac.superAdmins))
+	for id := range ac.superAdmins {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// SetCache sets where admin status checks are cached. Defaults to an in-memory cache;
+// call it before the checker is used.
+func (ac *AdminChecker) SetCache(c cache.Cache) {
+	ac.cache = c
+}
+
+// SetCacheTTL configures how long admin status checks are cached. A zero duration disables caching.
+func (ac *AdminChecker) SetCacheTTL(ttl time.Duration) {
+	ac.cacheMutex.Lock()
+	ac.cacheTTL = ttl
+	ac.cacheMutex.Unlock()
+	ac.dropCache(context.Background())
+}
+
+// IsAdmin checks if a user is a super admin or an administrator or creator in the target channel
+// configured in the AdminChecker, or was granted the command being handled (see WithGrant).
 // This method satisfies the AdminCheckerInterface.
 func (ac *AdminChecker) IsAdmin(ctx context.Context, userID int64) (bool, error) {
+	if ac.IsSuperAdmin(userID) {
+		return true, nil // Checked first so API errors can't lock out the owner
+	}
+	if Granted(ctx, userID) {
+		return true, nil
+	}
+
+	var cached bool
+	if found, err := cache.GetJSON(ctx, ac.cache, adminKey(userID), &cached); err != nil {
+		log.Printf("[AdminCheck User:%d] Error reading cached admin status: %v", userID, err)
+	} else if found {
+		return cached, nil
+	}
+
 	member, err := ac.bot.GetChatMember(ctx, &telego.GetChatMemberParams{
 		ChatID: telego.ChatID{ID: ac.targetChannelID},
 		UserID: userID,
@@ -46,7 +140,8 @@ func (ac *AdminChecker) IsAdmin(ctx context.Context, userID int64) (bool, error)
 	if err != nil {
 		// A user not found in the channel is simply not an admin.
 		// API errors (network, permissions) should be returned.
-		if strings.Contains(strings.ToLower(err.Error()), "user not found") {
+		if telegoapi.IsUserNotFound(err) {
+			ac.store(ctx, userID, false)
 			return false, nil
 		}
 		// Log other potential errors but don't expose details unless necessary
@@ -54,7 +149,77 @@ func (ac *AdminChecker) IsAdmin(ctx context.Context, userID int64) (bool, error)
 		return false, fmt.Errorf("failed to get chat member info: %w", err)
 	}
 
-	status := member.MemberStatus()
-	isAdminStatus := status == telego.MemberStatusCreator || status == telego.MemberStatusAdministrator
+	isAdminStatus := IsAdminStatus(member.MemberStatus())
+	ac.store(ctx, userID, isAdminStatus)
 	return isAdminStatus, nil
 }
+
+// Refresh reloads the channel administrators, replacing the whole cache.
+// Users not in the list are checked again on their next IsAdmin call.
+// It returns the user IDs of the channel's human administrators followed by the super admins
+// who aren't channel administrators; bot accounts are left out.
+func (ac *AdminChecker) Refresh(ctx context.Context) ([]int64, error) {
+	admins, err := ac.bot.GetChatAdministrators(ctx, &telego.GetChatAdministratorsParams{
+		ChatID: telego.ChatID{ID: ac.targetChannelID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel administrators: %w", err)
+	}
+
+	ac.dropCache(ctx)
+	ac.cacheMutex.RLock()
+	defer ac.cacheMutex.RUnlock()
+	adminIDs := make([]int64, 0, len(admins)+len(ac.superAdmins))
+	seen := make(map[int64]struct{}, len(admins))
+	for _, admin := range admins {
+		user := admin.MemberUser()
+		if ac.cacheTTL > 0 {
+			if err := cache.SetJSON(ctx, ac.cache, adminKey(user.ID), true, ac.cacheTTL); err != nil {
+				log.Printf("[AdminCheck User:%d] Error caching admin status: %v", user.ID, err)
+			}
+		}
+		if !user.IsBot {
+			adminIDs = append(adminIDs, user.ID)
+			seen[user.ID] = struct{}{}
+		}
+	}
+	for id := range ac.superAdmins {
+		if _, ok := seen[id]; !ok {
+			adminIDs = append(adminIDs, id)
+		}
+	}
+	log.Printf("[AdminCheck Channel:%d] Refreshed admin cache: %d administrators", ac.targetChannelID, len(admins))
+	return adminIDs, nil
+}
+
+// Invalidate drops the cached admin status of a user, e.g. after a promotion or demotion.
+func (ac *AdminChecker) Invalidate(userID int64) {
+	if err := ac.cache.Delete(context.Background(), adminKey(userID)); err != nil {
+		log.Printf("[AdminCheck User:%d] Error dropping cached admin status: %v", userID, err)
+	}
+}
+
+// store caches an admin status check.
+func (ac *AdminChecker) store(ctx context.Context, userID int64, isAdmin bool) {
+	ac.cacheMutex.RLock()
+	ttl := ac.cacheTTL
+	ac.cacheMutex.RUnlock()
+	if ttl <= 0 {
+		return
+	}
+	if err := cache.SetJSON(ctx, ac.cache, adminKey(userID), isAdmin, ttl); err != nil {
+		log.Printf("[AdminCheck User:%d] Error caching admin status: %v", userID, err)
+	}
+}
+
+// dropCache drops all cached admin status checks.
+func (ac *AdminChecker) dropCache(ctx context.Context) {
+	if err := ac.cache.DeletePrefix(ctx, adminKeyPrefix); err != nil {
+		log.Printf("[AdminCheck Channel:%d] Error dropping cached admin statuses: %v", ac.targetChannelID, err)
+	}
+}
+
+// IsAdminStatus reports whether a Telegram member status is an admin status (creator or administrator).
+func IsAdminStatus(status string) bool {
+	return status == telego.MemberStatusCreator || status == telego.MemberStatusAdministrator
+}
@@ -0,0 +1,19 @@
+package auth
+
+import "context"
+
+// grantKey is the context key of a permission grant.
+type grantKey struct{}
+
+// WithGrant returns a context in which the user counts as an admin, for a user who isn't a
+// channel admin but was granted a command with /perm. The grant only lasts as long as the
+// handling of the command or button it was made for.
+func WithGrant(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, grantKey{}, userID)
+}
+
+// Granted reports whether the context grants the user admin rights, see WithGrant.
+func Granted(ctx context.Context, userID int64) bool {
+	granted, ok := ctx.Value(grantKey{}).(int64)
+	return ok && granted == userID
+}
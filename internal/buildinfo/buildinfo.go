@@ -0,0 +1,118 @@
+// Package buildinfo describes the running binary: its version, git commit and build time.
+//
+// The values are embedded at link time, e.g.:
+//
+//	go build -ldflags "-X vrcmemes-bot/internal/buildinfo.version=v1.2.3 \
+//	  -X vrcmemes-bot/internal/buildinfo.commit=$(git rev-parse HEAD) \
+//	  -X vrcmemes-bot/internal/buildinfo.date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Values that were not embedded fall back to the VCS information the Go toolchain
+// records when building inside a git checkout (the commit time stands in for the build time).
+package buildinfo
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Set by the linker with -X; empty when not embedded.
+var (
+	version string
+	commit  string
+	date    string // RFC 3339
+)
+
+// DefaultVersion is reported when no version was embedded.
+const DefaultVersion = "dev"
+
+// Info describes the running binary.
+type Info struct {
+	Version   string
+	Commit    string    // Full git commit hash; empty if unknown
+	Date      time.Time // Build time (or commit time, see the package docs); zero if unknown
+	Modified  bool      // The working tree had uncommitted changes
+	GoVersion string
+}
+
+var (
+	current     Info
+	currentOnce sync.Once
+)
+
+// Get returns the build info of the running binary.
+func Get() Info {
+	currentOnce.Do(func() {
+		current = load(version, commit, date)
+	})
+	return current
+}
+
+// load combines the linker-embedded values with the build info recorded by Go.
+func load(version, commit, date string) Info {
+	info := Info{Version: version, Commit: commit}
+	if parsed, err := time.Parse(time.RFC3339, date); err == nil {
+		info.Date = parsed
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info.GoVersion = build.GoVersion
+		if info.Version == "" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if parsed, err := time.Parse(time.RFC3339, setting.Value); err == nil && info.Date.IsZero() {
+					info.Date = parsed
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = DefaultVersion
+	}
+	return info
+}
+
+// ShortCommit returns the first 12 characters of the commit hash, or "unknown".
+func (i Info) ShortCommit() string {
+	switch {
+	case i.Commit == "":
+		return "unknown"
+	case len(i.Commit) > 12:
+		return i.Commit[:12]
+	default:
+		return i.Commit
+	}
+}
+
+// DateString returns the build time in UTC, or "unknown".
+func (i Info) DateString() string {
+	if i.Date.IsZero() {
+		return "unknown"
+	}
+	return i.Date.UTC().Format("2006-01-02 15:04 MST")
+}
+
+// String returns a one-line summary, e.g. "v1.2.3 (commit 0123456789ab, built 2026-10-15 03:55 UTC, go1.24.1)".
+func (i Info) String() string {
+	parts := []string{"commit " + i.ShortCommit()}
+	if i.Modified {
+		parts[0] += "+modified"
+	}
+	parts = append(parts, "built "+i.DateString())
+	if i.GoVersion != "" {
+		parts = append(parts, i.GoVersion)
+	}
+	return fmt.Sprintf("%s (%s)", i.Version, strings.Join(parts, ", "))
+}
@@ -0,0 +1,29 @@
+package buildinfo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadPrefersEmbeddedValues(t *testing.T) {
+	info := load("v1.2.3", "0123456789abcdef0123456789abcdef01234567", "2026-10-15T03:55:00Z")
+	assert.Equal(t, "v1.2.3", info.Version)
+	assert.Equal(t, "0123456789ab", info.ShortCommit())
+	assert.Equal(t, time.Date(2026, 10, 15, 3, 55, 0, 0, time.UTC), info.Date)
+	assert.Equal(t, "2026-10-15 03:55 UTC", info.DateString())
+}
+
+func TestLoadDefaults(t *testing.T) {
+	// Test binaries carry no VCS stamp or module version
+	info := load("", "", "not a date")
+	assert.Equal(t, DefaultVersion, info.Version)
+	assert.Equal(t, "unknown", info.ShortCommit())
+	assert.Equal(t, "unknown", info.DateString())
+}
+
+func TestString(t *testing.T) {
+	info := Info{Version: "v1.2.3", Commit: "abc", Modified: true, GoVersion: "go1.24.1"}
+	assert.Equal(t, "v1.2.3 (commit abc+modified, built unknown, go1.24.1)", info.String())
+}
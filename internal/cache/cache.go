@@ -0,0 +1,44 @@
+// Package cache stores hot data such as admin and subscription checks, user states and settings,
+// either in memory or, for deployments with several instances, in Redis.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Cache stores values by key for a limited time. Implementations are safe for concurrent use.
+// Callers treat errors like misses, so an unavailable cache only makes the bot slower.
+type Cache interface {
+	// Get returns the value of a key, and false if it isn't cached or has expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores a value. A non-positive TTL keeps it until it is deleted.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes keys. Missing keys are ignored.
+	Delete(ctx context.Context, keys ...string) error
+	// DeletePrefix removes all keys starting with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// GetJSON decodes the cached JSON value of a key into v and reports whether it was cached.
+func GetJSON(ctx context.Context, c Cache, key string, v interface{}) (bool, error) {
+	data, found, err := c.Get(ctx, key)
+	if err != nil || !found {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to decode cached %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// SetJSON caches v encoded as JSON.
+func SetJSON(ctx context.Context, c Cache, key string, v interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s for the cache: %w", key, err)
+	}
+	return c.Set(ctx, key, data, ttl)
+}
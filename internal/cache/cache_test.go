@@ -0,0 +1,269 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCache checks the behavior every Cache implementation shares.
+func testCache(t *testing.T, c Cache) {
+	ctx := context.Background()
+	require.NoError(t, c.Set(ctx, "admin:1", []byte("true"), time.Hour))
+	require.NoError(t, c.Set(ctx, "admin:2", []byte("false"), 0))
+	require.NoError(t, c.Set(ctx, "user_state:1", []byte(`{"state":"x"}`), time.Hour))
+
+	value, found, err := c.Get(ctx, "admin:1")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "true", string(value))
+
+	require.NoError(t, c.DeletePrefix(ctx, "admin:"))
+	_, found, err = c.Get(ctx, "admin:2")
+	require.NoError(t, err)
+	assert.False(t, found)
+	_, found, _ = c.Get(ctx, "user_state:1")
+	assert.True(t, found, "keys with another prefix were deleted")
+
+	require.NoError(t, c.Delete(ctx, "user_state:1", "missing"))
+	_, found, _ = c.Get(ctx, "user_state:1")
+	assert.False(t, found)
+}
+
+func TestMemory(t *testing.T) {
+	c := NewMemory()
+	testCache(t, c)
+
+	ctx := context.Background()
+	require.NoError(t, c.Set(ctx, "short", []byte("1"), time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	_, found, err := c.Get(ctx, "short")
+	require.NoError(t, err)
+	assert.False(t, found, "expired entry was returned")
+}
+
+func TestMemorySweepsExpiredEntries(t *testing.T) {
+	c := NewMemory()
+	ctx := context.Background()
+	require.NoError(t, c.Set(ctx, "subscription:1", []byte("true"), time.Millisecond))
+	require.NoError(t, c.Set(ctx, "subscription:2", []byte("false"), time.Hour))
+	require.NoError(t, c.Set(ctx, "user_state:1", []byte("{}"), 0))
+	time.Sleep(5 * time.Millisecond)
+
+	// The next Set after the sweep interval drops what has expired
+	c.mu.Lock()
+	c.lastSweep = time.Now().Add(-sweepInterval)
+	c.mu.Unlock()
+	require.NoError(t, c.Set(ctx, "subscription:3", []byte("true"), time.Hour))
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	assert.NotContains(t, c.entries, "subscription:1")
+	assert.Contains(t, c.entries, "subscription:2")
+	assert.Contains(t, c.entries, "user_state:1")
+	assert.Contains(t, c.entries, "subscription:3")
+}
+
+func TestRedis(t *testing.T) {
+	server := newFakeRedis(t)
+	c, err := NewRedis(context.Background(), "redis://:secret@"+server.addr+"/2", "bot:")
+	require.NoError(t, err)
+	defer c.Close()
+	testCache(t, c)
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, "secret", server.password)
+	assert.Equal(t, "2", server.db)
+	for key := range server.data {
+		assert.True(t, strings.HasPrefix(key, "bot:"), "key %q has no prefix", key)
+	}
+}
+
+func TestSettingsRepositoryCachesMissingSettings(t *testing.T) {
+	ctx := context.Background()
+	next := &countingSettingsRepo{}
+	repo := NewSettingsRepository(next, NewMemory(), time.Hour)
+
+	for range 2 {
+		setting, err := repo.GetSetting(ctx, models.SettingGreeting)
+		require.NoError(t, err)
+		assert.Nil(t, setting)
+	}
+	assert.Equal(t, 1, next.gets)
+
+	// Changes drop the cached value
+	require.NoError(t, repo.SetSetting(ctx, models.SettingGreeting, "hi", 1))
+	setting, err := repo.GetSetting(ctx, models.SettingGreeting)
+	require.NoError(t, err)
+	require.NotNil(t, setting)
+	assert.Equal(t, "hi", setting.Value)
+	assert.Equal(t, 2, next.gets)
+}
+
+// countingSettingsRepo is an in-memory SettingsRepository that counts reads.
+type countingSettingsRepo struct {
+	values map[string]string
+	gets   int
+}
+
+func (r *countingSettingsRepo) GetSetting(ctx context.Context, key string) (*models.Setting, error) {
+	r.gets++
+	value, ok := r.values[key]
+	if !ok {
+		return nil, nil
+	}
+	return &models.Setting{Key: key, Value: value}, nil
+}
+
+func (r *countingSettingsRepo) SetSetting(ctx context.Context, key, value string, updatedBy int64) error {
+	if r.values == nil {
+		r.values = make(map[string]string)
+	}
+	r.values[key] = value
+	return nil
+}
+
+func (r *countingSettingsRepo) DeleteSetting(ctx context.Context, key string) error {
+	delete(r.values, key)
+	return nil
+}
+
+// fakeRedis serves the commands the Redis cache uses from a map, ignoring expiry.
+type fakeRedis struct {
+	addr     string
+	mu       sync.Mutex
+	data     map[string]string
+	password string
+	db       string
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	server := &fakeRedis{addr: listener.Addr().String(), data: make(map[string]string)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		_, _ = io.WriteString(conn, s.execute(args))
+	}
+}
+
+func (s *fakeRedis) execute(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "AUTH":
+		s.password = args[len(args)-1]
+		return "+OK\r\n"
+	case "SELECT":
+		s.db = args[1]
+		return "+OK\r\n"
+	case "SET":
+		s.data[args[1]] = args[2]
+		return "+OK\r\n"
+	case "GET":
+		value, ok := s.data[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := s.data[key]; ok {
+				delete(s.data, key)
+				deleted++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", deleted)
+	case "SCAN":
+		prefix := strings.TrimSuffix(args[3], "*")
+		var b strings.Builder
+		var keys []string
+		for key := range s.data {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+			}
+		}
+		fmt.Fprintf(&b, "*2\r\n$1\r\n0\r\n*%d\r\n", len(keys))
+		for _, key := range keys {
+			fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(key), key)
+		}
+		return b.String()
+	default:
+		return "-ERR unknown command\r\n"
+	}
+}
+
+// readCommand reads a command sent as an array of bulk strings.
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if line, err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+func TestWithPrefix(t *testing.T) {
+	shared := NewMemory()
+	testCache(t, WithPrefix(shared, "tenant:-100:"))
+
+	ctx := context.Background()
+	tenant := WithPrefix(shared, "tenant:-100:")
+	require.NoError(t, tenant.Set(ctx, "admin:1", []byte("true"), time.Hour))
+	_, found, _ := shared.Get(ctx, "admin:1")
+	assert.False(t, found, "prefixed key visible without the prefix")
+	_, found, _ = shared.Get(ctx, "tenant:-100:admin:1")
+	assert.True(t, found)
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often Set removes expired entries from a memory cache.
+const sweepInterval = time.Minute
+
+// memoryEntry is a value in a memory cache.
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time // Zero if the entry doesn't expire
+}
+
+// expired reports whether the entry has expired at now.
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// Memory is a Cache kept in the process's memory. It is the default: the bot runs as a single
+// instance with long polling, so nothing needs to be shared.
+type Memory struct {
+	mu        sync.RWMutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+// NewMemory creates an empty memory cache.
+func NewMemory() *Memory {
+	return &Memory{entries: make(map[string]memoryEntry), lastSweep: time.Now()}
+}
+
+// Get returns the value of a key, and false if it isn't cached or has expired.
+func (m *Memory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entry, found := m.entries[key]
+	if !found || entry.expired(time.Now()) {
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+// Set stores a value. A non-positive TTL keeps it until it is deleted. Expired entries are
+// swept from time to time, so keys that are never read again don't pile up.
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	now := time.Now()
+	entry := memoryEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = now.Add(ttl)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = entry
+	if now.Sub(m.lastSweep) >= sweepInterval {
+		for k, e := range m.entries {
+			if e.expired(now) {
+				delete(m.entries, k)
+			}
+		}
+		m.lastSweep = now
+	}
+	return nil
+}
+
+// Delete removes keys.
+func (m *Memory) Delete(_ context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+// DeletePrefix removes all keys starting with prefix.
+func (m *Memory) DeletePrefix(_ context.Context, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.entries, key)
+		}
+	}
+	return nil
+}
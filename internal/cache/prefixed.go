@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// prefixed is a Cache whose keys are stored under a prefix in another cache.
+type prefixed struct {
+	next   Cache
+	prefix string
+}
+
+// WithPrefix returns a view of a cache that stores its keys under prefix, so components with the
+// same keys, such as those of the channels served in multi-tenant mode, can share one cache.
+func WithPrefix(c Cache, prefix string) Cache {
+	return &prefixed{next: c, prefix: prefix}
+}
+
+// Get returns the value of a key, and false if it isn't cached or has expired.
+func (p *prefixed) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return p.next.Get(ctx, p.prefix+key)
+}
+
+// Set stores a value. A non-positive TTL keeps it until it is deleted.
+func (p *prefixed) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return p.next.Set(ctx, p.prefix+key, value, ttl)
+}
+
+// Delete removes keys. Missing keys are ignored.
+func (p *prefixed) Delete(ctx context.Context, keys ...string) error {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = p.prefix + key
+	}
+	return p.next.Delete(ctx, prefixedKeys...)
+}
+
+// DeletePrefix removes all keys starting with prefix.
+func (p *prefixed) DeletePrefix(ctx context.Context, prefix string) error {
+	return p.next.DeletePrefix(ctx, p.prefix+prefix)
+}
@@ -0,0 +1,323 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// redisPoolSize is how many idle connections are kept open.
+	redisPoolSize = 8
+	// redisDialTimeout bounds connecting to Redis.
+	redisDialTimeout = 5 * time.Second
+	// redisCommandTimeout bounds a command when the context has no earlier deadline.
+	redisCommandTimeout = 2 * time.Second
+	// redisScanCount is the COUNT hint of the SCAN calls of DeletePrefix.
+	redisScanCount = 500
+)
+
+// errRedisNil is a nil reply, e.g. of GET for a missing key.
+var errRedisNil = errors.New("redis: nil reply")
+
+// Redis is a Cache stored in Redis, shared by all instances that use the same server and
+// key prefix. It speaks the Redis protocol (RESP) directly and needs no client library.
+type Redis struct {
+	network, addr string
+	tlsConfig     *tls.Config // Set for rediss:// URLs
+	username      string
+	password      string
+	db            int
+	prefix        string // Prepended to every key
+	idle          chan *redisConn
+}
+
+// redisConn is a connection to Redis with its buffered reader.
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewRedis creates a Redis cache from a URL such as redis://:password@host:6379/0 (rediss://
+// for TLS). Keys are stored with the given prefix, so several bots can share a server.
+// The connection is checked with PING.
+func NewRedis(ctx context.Context, rawURL, prefix string) (*Redis, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	r := &Redis{network: "tcp", addr: u.Host, prefix: prefix, idle: make(chan *redisConn, redisPoolSize)}
+	switch u.Scheme {
+	case "redis":
+	case "rediss":
+		r.tlsConfig = &tls.Config{ServerName: u.Hostname()}
+	default:
+		return nil, fmt.Errorf("invalid Redis URL: scheme must be redis or rediss, not %q", u.Scheme)
+	}
+	if u.Port() == "" {
+		r.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		r.username = u.User.Username()
+		r.password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if r.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid Redis URL: database %q is not a number", db)
+		}
+	}
+
+	if _, err := r.do(ctx, "PING"); err != nil {
+		return nil, fmt.Errorf("failed to reach Redis at %s: %w", r.addr, err)
+	}
+	return r, nil
+}
+
+// Get returns the value of a key, and false if it isn't cached or has expired.
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := r.do(ctx, "GET", r.prefix+key)
+	if errors.Is(err, errRedisNil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: unexpected GET reply %T", reply)
+	}
+	return value, true, nil
+}
+
+// Set stores a value. A non-positive TTL keeps it until it is deleted.
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", r.prefix + key, string(value)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(max(ttl.Milliseconds(), 1), 10))
+	}
+	_, err := r.do(ctx, args...)
+	return err
+}
+
+// Delete removes keys.
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	args := make([]string, 0, len(keys)+1)
+	args = append(args, "DEL")
+	for _, key := range keys {
+		args = append(args, r.prefix+key)
+	}
+	_, err := r.do(ctx, args...)
+	return err
+}
+
+// DeletePrefix removes all keys starting with prefix. It walks the keys with SCAN, so it
+// doesn't block the server, but keys written meanwhile may survive.
+func (r *Redis) DeletePrefix(ctx context.Context, prefix string) error {
+	pattern := escapeGlob(r.prefix+prefix) + "*"
+	cursor := "0"
+	for {
+		reply, err := r.do(ctx, "SCAN", cursor, "MATCH", pattern, "COUNT", strconv.Itoa(redisScanCount))
+		if err != nil {
+			return err
+		}
+		parts, ok := reply.([]interface{})
+		if !ok || len(parts) != 2 {
+			return fmt.Errorf("redis: unexpected SCAN reply %T", reply)
+		}
+		next, _ := parts[0].([]byte)
+		keys, _ := parts[1].([]interface{})
+		if len(keys) > 0 {
+			args := make([]string, 0, len(keys)+1)
+			args = append(args, "DEL")
+			for _, key := range keys {
+				if k, ok := key.([]byte); ok {
+					args = append(args, string(k))
+				}
+			}
+			if _, err := r.do(ctx, args...); err != nil {
+				return err
+			}
+		}
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return nil
+		}
+	}
+}
+
+// Close closes the idle connections.
+func (r *Redis) Close() error {
+	for {
+		select {
+		case c := <-r.idle:
+			_ = c.conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// escapeGlob escapes the characters SCAN MATCH patterns treat specially.
+func escapeGlob(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`).Replace(s)
+}
+
+// do sends a command on a pooled connection and reads its reply. Connections that fail are
+// closed rather than returned to the pool.
+func (r *Redis) do(ctx context.Context, args ...string) (interface{}, error) {
+	c, err := r.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > redisCommandTimeout {
+		deadline = time.Now().Add(redisCommandTimeout)
+	}
+	_ = c.conn.SetDeadline(deadline)
+
+	reply, err := c.roundTrip(args)
+	var serverErr redisError
+	if err != nil && !errors.Is(err, errRedisNil) && !errors.As(err, &serverErr) {
+		_ = c.conn.Close()
+		return nil, err
+	}
+	r.release(c)
+	return reply, err
+}
+
+// conn takes an idle connection or opens a new one, authenticated and with the database selected.
+func (r *Redis) conn(ctx context.Context) (*redisConn, error) {
+	select {
+	case c := <-r.idle:
+		return c, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Timeout: redisDialTimeout}
+	var conn net.Conn
+	var err error
+	if r.tlsConfig != nil {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: r.tlsConfig}).DialContext(ctx, r.network, r.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, r.network, r.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis: failed to connect: %w", err)
+	}
+	c := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+	_ = conn.SetDeadline(time.Now().Add(redisDialTimeout))
+
+	var setup [][]string
+	switch {
+	case r.username != "" && r.password != "":
+		setup = append(setup, []string{"AUTH", r.username, r.password})
+	case r.password != "":
+		setup = append(setup, []string{"AUTH", r.password})
+	}
+	if r.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(r.db)})
+	}
+	for _, args := range setup {
+		if _, err := c.roundTrip(args); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("redis: %s failed: %w", args[0], err)
+		}
+	}
+	return c, nil
+}
+
+// release returns a connection to the pool, or closes it if the pool is full.
+func (r *Redis) release(c *redisConn) {
+	select {
+	case r.idle <- c:
+	default:
+		_ = c.conn.Close()
+	}
+}
+
+// redisError is an error reply of the server. The connection stays usable after it.
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// roundTrip writes a command as an array of bulk strings and reads the reply.
+func (c *redisConn) roundTrip(args []string) (interface{}, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, fmt.Errorf("redis: failed to send %s: %w", args[0], err)
+	}
+	return c.readReply()
+}
+
+// readReply reads one reply: simple strings and bulk strings as []byte, integers as int64 and
+// arrays as []interface{}. Nil replies return errRedisNil, error replies a redisError.
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("redis: failed to read reply: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+	payload := line[1:]
+	switch line[0] {
+	case '+':
+		return []byte(payload), nil
+	case '-':
+		return nil, redisError(payload)
+	case ':':
+		n, err := strconv.ParseInt(payload, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed integer reply %q", payload)
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed bulk reply length %q", payload)
+		}
+		if n < 0 {
+			return nil, errRedisNil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, fmt.Errorf("redis: failed to read reply: %w", err)
+		}
+		return data[:n], nil
+	case '*':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed array reply length %q", payload)
+		}
+		if n < 0 {
+			return nil, errRedisNil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			item, err := c.readReply()
+			if err != nil && !errors.Is(err, errRedisNil) {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unknown reply type %q", line[0])
+	}
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"time"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+)
+
+// settingKeyPrefix starts the cache keys of settings.
+const settingKeyPrefix = "setting:"
+
+// DefaultSettingsTTL is how long a setting is cached. Changes made through the repository
+// drop the cached value right away; the TTL only bounds changes made directly in MongoDB.
+const DefaultSettingsTTL = 10 * time.Minute
+
+// cachedSetting is a cached GetSetting result; Setting is nil for settings that aren't set.
+type cachedSetting struct {
+	Setting *models.Setting `json:"setting"`
+}
+
+// SettingsRepository is a database.SettingsRepository that caches settings, which are read
+// far more often (e.g. the greeting on every /start) than they are changed.
+type SettingsRepository struct {
+	next  database.SettingsRepository
+	cache Cache
+	ttl   time.Duration
+}
+
+// NewSettingsRepository wraps a settings repository so settings are cached for ttl.
+func NewSettingsRepository(next database.SettingsRepository, cache Cache, ttl time.Duration) *SettingsRepository {
+	if next == nil {
+		log.Fatal("SettingsRepository: Settings repository dependency is nil")
+	}
+	if cache == nil {
+		log.Fatal("SettingsRepository: Cache dependency is nil")
+	}
+	return &SettingsRepository{next: next, cache: cache, ttl: ttl}
+}
+
+// GetSetting returns a setting, or nil if it is not set, from the cache if possible.
+func (r *SettingsRepository) GetSetting(ctx context.Context, key string) (*models.Setting, error) {
+	var cached cachedSetting
+	found, err := GetJSON(ctx, r.cache, settingKeyPrefix+key, &cached)
+	if err != nil {
+		log.Printf("[Cache] Error reading setting %s, using the database: %v", key, err)
+	} else if found {
+		return cached.Setting, nil
+	}
+
+	setting, err := r.next.GetSetting(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := SetJSON(ctx, r.cache, settingKeyPrefix+key, cachedSetting{Setting: setting}, r.ttl); err != nil {
+		log.Printf("[Cache] Error caching setting %s: %v", key, err)
+	}
+	return setting, nil
+}
+
+// SetSetting stores the value of a setting and drops its cached value.
+func (r *SettingsRepository) SetSetting(ctx context.Context, key, value string, updatedBy int64) error {
+	if err := r.next.SetSetting(ctx, key, value, updatedBy); err != nil {
+		return err
+	}
+	r.invalidate(ctx, key)
+	return nil
+}
+
+// DeleteSetting removes a setting and drops its cached value.
+func (r *SettingsRepository) DeleteSetting(ctx context.Context, key string) error {
+	if err := r.next.DeleteSetting(ctx, key); err != nil {
+		return err
+	}
+	r.invalidate(ctx, key)
+	return nil
+}
+
+// invalidate drops the cached value of a setting.
+func (r *SettingsRepository) invalidate(ctx context.Context, key string) {
+	if err := r.cache.Delete(ctx, settingKeyPrefix+key); err != nil {
+		log.Printf("[Cache] Error dropping cached setting %s: %v", key, err)
+	}
+}
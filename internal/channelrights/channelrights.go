@@ -0,0 +1,161 @@
+// Package channelrights checks, before each publication, that the bot can still post to the
+// channel, so publications fail with an explicit error instead of a cryptic API response when
+// the bot was demoted or removed, and operators are alerted as soon as that happens.
+package channelrights
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const (
+	// DefaultTTL is how long the bot's rights in the channel are trusted once checked.
+	DefaultTTL = 5 * time.Minute
+	// lostTTL is how long a check that found the rights missing is trusted, so restored
+	// rights are noticed soon.
+	lostTTL = 30 * time.Second
+	// checkTimeout bounds the GetChatMember call of a check.
+	checkTimeout = 10 * time.Second
+	// alertTimeout bounds sending an alert.
+	alertTimeout = 30 * time.Second
+)
+
+// Rights are the bot's rights in the channel that publishing relies on.
+type Rights struct {
+	Status    string // Member status of the bot, e.g. "administrator" or "left"
+	CanPost   bool   // can_post_messages
+	CanDelete bool   // can_delete_messages, needed to take posts down again
+}
+
+// AlertFunc is called when the bot loses the right to post to the channel (lost is true) and when
+// it has it again. It runs in the background.
+type AlertFunc func(ctx context.Context, lost bool, rights Rights)
+
+// Checker caches the bot's rights in the channel, looked up with GetChatMember of the bot itself.
+type Checker struct {
+	bot       telegoapi.BotAPI
+	channelID int64
+	ttl       time.Duration
+	alert     AlertFunc
+
+	mu        sync.Mutex
+	botID     int64
+	rights    Rights
+	checkedAt time.Time // Zero until the first successful lookup
+	lost      bool      // Whether the last lookup found the bot unable to post
+	now       func() time.Time
+}
+
+// NewChecker creates a rights checker for the channel. A non-positive ttl uses DefaultTTL.
+func NewChecker(bot telegoapi.BotAPI, channelID int64, ttl time.Duration) *Checker {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Checker{bot: bot, channelID: channelID, ttl: ttl, now: time.Now}
+}
+
+// SetAlertFunc sets the function called when posting rights are lost or restored.
+func (c *Checker) SetAlertFunc(alert AlertFunc) {
+	c.alert = alert
+}
+
+// Check returns an error wrapping telegoapi.ErrNoPostingRights if the bot can't post to the channel.
+// If the rights can't be looked up for another reason, such as a network error, the publication
+// is let through: its own API error is more telling than a failed lookup.
+func (c *Checker) Check(ctx context.Context) error {
+	rights, err := c.Rights(ctx)
+	if err != nil {
+		log.Printf("[ChannelRights] Could not check the bot's rights in channel %d, publishing anyway: %v", c.channelID, err)
+		return nil
+	}
+	if !rights.CanPost {
+		return fmt.Errorf("%w %d (bot status %q)", telegoapi.ErrNoPostingRights, c.channelID, rights.Status)
+	}
+	return nil
+}
+
+// Rights returns the bot's rights in the channel, from the cache while it is fresh.
+func (c *Checker) Rights(ctx context.Context) (Rights, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ttl := c.ttl
+	if c.lost {
+		ttl = min(ttl, lostTTL)
+	}
+	if !c.checkedAt.IsZero() && c.now().Sub(c.checkedAt) < ttl {
+		return c.rights, nil
+	}
+
+	rights, err := c.lookup(ctx)
+	if err != nil {
+		return Rights{}, err
+	}
+	c.rights, c.checkedAt = rights, c.now()
+	if lost := !rights.CanPost; lost != c.lost {
+		c.lost = lost
+		c.raiseAlert(lost, rights)
+	}
+	return rights, nil
+}
+
+// Invalidate drops the cached rights, e.g. after a publication was refused by Telegram, so the
+// next check looks them up again.
+func (c *Checker) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checkedAt = time.Time{}
+}
+
+// lookup asks Telegram for the bot's membership in the channel. Bad Request and Forbidden
+// responses mean the bot is no longer in the channel. The caller holds c.mu.
+func (c *Checker) lookup(ctx context.Context) (Rights, error) {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	if c.botID == 0 {
+		me, err := c.bot.GetMe(ctx)
+		if err != nil {
+			return Rights{}, fmt.Errorf("failed to get the bot user: %w", err)
+		}
+		c.botID = me.ID
+	}
+	member, err := c.bot.GetChatMember(ctx, &telego.GetChatMemberParams{ChatID: tu.ID(c.channelID), UserID: c.botID})
+	if err != nil {
+		if code := telegoapi.ErrorCode(err); code == http.StatusBadRequest || code == http.StatusForbidden {
+			return Rights{Status: telego.MemberStatusLeft}, nil
+		}
+		return Rights{}, fmt.Errorf("failed to get the bot's membership in channel %d: %w", c.channelID, err)
+	}
+	rights := Rights{Status: member.MemberStatus()}
+	switch m := member.(type) {
+	case *telego.ChatMemberOwner:
+		rights.CanPost, rights.CanDelete = true, true
+	case *telego.ChatMemberAdministrator:
+		rights.CanPost, rights.CanDelete = m.CanPostMessages, m.CanDeleteMessages
+	}
+	return rights, nil
+}
+
+// raiseAlert logs a change of the posting rights and calls the alert function in the background.
+func (c *Checker) raiseAlert(lost bool, rights Rights) {
+	if lost {
+		log.Printf("[ChannelRights] The bot lost the right to post to channel %d (status %q)", c.channelID, rights.Status)
+	} else {
+		log.Printf("[ChannelRights] The bot can post to channel %d again", c.channelID)
+	}
+	if c.alert == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), alertTimeout)
+		defer cancel()
+		c.alert(ctx, lost, rights)
+	}()
+}
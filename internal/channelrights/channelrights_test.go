@@ -0,0 +1,70 @@
+package channelrights
+
+import (
+	"context"
+	"testing"
+	"time"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+	"vrcmemes-bot/pkg/telegoapi/telegoapitest"
+
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testChannelID = -100123
+
+// memberBot answers GetChatMember with a configurable membership of the bot.
+type memberBot struct {
+	*telegoapitest.FakeBot
+	member telego.ChatMember
+	err    error
+	calls  int
+}
+
+func (b *memberBot) GetChatMember(ctx context.Context, params *telego.GetChatMemberParams) (telego.ChatMember, error) {
+	b.calls++
+	return b.member, b.err
+}
+
+func TestCheckerCachesRightsAndAlertsOnChange(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	bot := &memberBot{
+		FakeBot: telegoapitest.NewFakeBot(),
+		member:  &telego.ChatMemberAdministrator{Status: telego.MemberStatusAdministrator, CanPostMessages: true},
+	}
+	checker := NewChecker(bot, testChannelID, time.Minute)
+	checker.now = func() time.Time { return now }
+	alerts := make(chan bool, 2)
+	checker.SetAlertFunc(func(_ context.Context, lost bool, _ Rights) { alerts <- lost })
+
+	require.NoError(t, checker.Check(context.Background()))
+	require.NoError(t, checker.Check(context.Background()))
+	assert.Equal(t, 1, bot.calls, "rights should be cached")
+
+	// Demoted: publications are refused once the cache expires, and operators are alerted once
+	bot.member = &telego.ChatMemberAdministrator{Status: telego.MemberStatusAdministrator}
+	now = now.Add(2 * time.Minute)
+	assert.ErrorIs(t, checker.Check(context.Background()), telegoapi.ErrNoPostingRights)
+	assert.True(t, <-alerts)
+	assert.ErrorIs(t, checker.Check(context.Background()), telegoapi.ErrNoPostingRights)
+	assert.Empty(t, alerts)
+
+	// Removed from the channel: still refused, without a second alert
+	bot.err = telegoapitest.APIError(403, "Forbidden: bot is not a member of the channel chat", 0)
+	now = now.Add(lostTTL)
+	assert.ErrorIs(t, checker.Check(context.Background()), telegoapi.ErrNoPostingRights)
+	assert.Empty(t, alerts)
+
+	// Restored rights are noticed after the shorter TTL of missing rights
+	bot.member, bot.err = &telego.ChatMemberOwner{Status: telego.MemberStatusCreator}, nil
+	now = now.Add(lostTTL)
+	require.NoError(t, checker.Check(context.Background()))
+	assert.False(t, <-alerts)
+}
+
+func TestCheckerLetsPublicationsThroughWhenLookupFails(t *testing.T) {
+	bot := &memberBot{FakeBot: telegoapitest.NewFakeBot(), err: context.DeadlineExceeded}
+	checker := NewChecker(bot, testChannelID, time.Minute)
+	assert.NoError(t, checker.Check(context.Background()))
+}
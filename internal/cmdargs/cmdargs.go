@@ -0,0 +1,292 @@
+// Package cmdargs parses bot command arguments from a declarative spec.
+//
+// Arguments are split like a shell command line: whitespace separates them, and
+// "double", 'single', “curly” and «angle» quotes group words. Flags are written as
+// --name, named options as name=value, and "--" ends flag parsing. Parse errors are
+// *UsageError values that carry a localizable message and the generated usage line.
+package cmdargs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// Arg is a positional argument.
+type Arg struct {
+	Name     string   // Shown in the usage line
+	Required bool     // Parsing fails if the argument is missing
+	Rest     bool     // Takes the remaining text as typed, including line breaks; only valid as the last argument
+	Choices  []string // Allowed values, matched case-insensitively and with "-" and "_" alike; empty allows any value
+}
+
+// Spec declares the arguments a command accepts.
+type Spec struct {
+	Command string   // Command name without the slash
+	Args    []Arg    // Positional arguments, in order
+	Flags   []string // Boolean flags, written as --name
+	Options []string // Named values, written as name=value
+}
+
+// Parsed holds the arguments of a parsed command.
+type Parsed struct {
+	args    map[string]string
+	flags   map[string]bool
+	options map[string]string
+}
+
+// Arg returns the value of a positional argument, or "" if it was not given.
+// Values of arguments with choices are returned as the matching choice is declared.
+func (p *Parsed) Arg(name string) string {
+	return p.args[name]
+}
+
+// Flag reports whether a flag was given.
+func (p *Parsed) Flag(name string) bool {
+	return p.flags[name]
+}
+
+// Option returns the value of a named option and whether it was given.
+func (p *Parsed) Option(name string) (string, bool) {
+	value, ok := p.options[name]
+	return value, ok
+}
+
+// UsageError is returned by Parse for malformed arguments.
+type UsageError struct {
+	MessageID string                 // Locale message describing the problem
+	Data      map[string]interface{} // Template data for the message, including "Usage"
+}
+
+// Error implements error with the unlocalized problem and usage line.
+func (e *UsageError) Error() string {
+	return fmt.Sprintf("%s (usage: %v)", e.MessageID, e.Data["Usage"])
+}
+
+// Localize returns the problem and usage line in the localizer's language.
+func (e *UsageError) Localize(localizer *i18n.Localizer) string {
+	return locales.GetMessage(localizer, e.MessageID, e.Data, nil)
+}
+
+// Usage returns the usage line of the command, e.g. "/posturl [--silent] <url> [caption...]".
+func (s Spec) Usage() string {
+	parts := []string{"/" + s.Command}
+	for _, flag := range s.Flags {
+		parts = append(parts, "[--"+flag+"]")
+	}
+	for _, option := range s.Options {
+		parts = append(parts, "["+option+"=…]")
+	}
+	for _, arg := range s.Args {
+		name := arg.Name
+		if len(arg.Choices) > 0 {
+			name = strings.Join(arg.Choices, "|")
+		}
+		if arg.Rest {
+			name += "..."
+		}
+		if arg.Required {
+			parts = append(parts, "<"+name+">")
+		} else {
+			parts = append(parts, "["+name+"]")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// usageError builds a UsageError for the spec.
+func (s Spec) usageError(messageID string, data map[string]interface{}) *UsageError {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	data["Usage"] = s.Usage()
+	return &UsageError{MessageID: messageID, Data: data}
+}
+
+// Parse parses the full text of a command message, including the leading /command
+// (with or without @botname), according to the spec.
+func (s Spec) Parse(text string) (*Parsed, error) {
+	parsed := &Parsed{
+		args:    make(map[string]string),
+		flags:   make(map[string]bool),
+		options: make(map[string]string),
+	}
+
+	pos := 0
+	if tok, end, ok, err := scanToken(text, pos); err == nil && ok && !tok.quoted && strings.HasPrefix(tok.value, "/") {
+		pos = end // Skip the command itself
+	}
+
+	next := 0 // Index of the next positional argument
+	flagsEnded := false
+	for {
+		tok, end, ok, err := scanToken(text, pos)
+		if err != nil {
+			// Free text such as a caption may contain apostrophes; keep it as typed
+			if next < len(s.Args) && s.Args[next].Rest {
+				parsed.args[s.Args[next].Name] = strings.TrimSpace(text[pos:])
+				next++
+				break
+			}
+			return nil, s.usageError("MsgArgsUnterminatedQuote", nil)
+		}
+		if !ok {
+			break
+		}
+		pos = end
+
+		if !tok.quoted && !flagsEnded {
+			if tok.value == "--" {
+				flagsEnded = true
+				continue
+			}
+			if name, isFlag := strings.CutPrefix(tok.value, "--"); isFlag {
+				if !contains(s.Flags, name) {
+					return nil, s.usageError("MsgArgsUnknownFlag", map[string]interface{}{"Flag": tok.value})
+				}
+				parsed.flags[name] = true
+				continue
+			}
+			if name, value, isOption := strings.Cut(tok.value, "="); isOption && contains(s.Options, name) {
+				parsed.options[name] = value
+				continue
+			}
+		}
+
+		if next >= len(s.Args) {
+			return nil, s.usageError("MsgArgsTooMany", map[string]interface{}{"Arg": tok.value})
+		}
+		arg := s.Args[next]
+		next++
+
+		value := tok.value
+		if arg.Rest {
+			if rest := strings.TrimRightFunc(text[tok.start:], unicode.IsSpace); len(rest) > end-tok.start {
+				value = rest // More follows; keep the rest exactly as typed
+			}
+			pos = len(text)
+		}
+		if len(arg.Choices) > 0 {
+			choice, ok := matchChoice(arg.Choices, value)
+			if !ok {
+				return nil, s.usageError("MsgArgsInvalidChoice", map[string]interface{}{
+					"Arg":     arg.Name,
+					"Value":   tok.value,
+					"Choices": strings.Join(arg.Choices, ", "),
+				})
+			}
+			value = choice
+		}
+		parsed.args[arg.Name] = value
+	}
+
+	for _, arg := range s.Args[next:] {
+		if arg.Required {
+			return nil, s.usageError("MsgArgsMissing", map[string]interface{}{"Arg": arg.Name})
+		}
+	}
+	return parsed, nil
+}
+
+// token is one argument of a command line.
+type token struct {
+	value  string
+	start  int  // Byte offset of the token in the text
+	quoted bool // Any part of the token was quoted, so it is never a flag or option
+}
+
+// closingQuotes maps opening quote characters to their closing counterparts.
+var closingQuotes = map[rune]rune{
+	'"':  '"',
+	'\'': '\'',
+	'“':  '”',
+	'«':  '»',
+}
+
+// errUnterminatedQuote is returned by scanToken for an unclosed quote.
+var errUnterminatedQuote = errors.New("unterminated quote")
+
+// scanToken reads the shell-style token starting at or after pos and returns it with
+// the offset just past it. ok is false if only whitespace is left. A backslash
+// escapes the next character outside single quotes.
+func scanToken(text string, pos int) (tok token, end int, ok bool, err error) {
+	for pos < len(text) {
+		r, size := utf8.DecodeRuneInString(text[pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		pos += size
+	}
+	if pos >= len(text) {
+		return token{}, pos, false, nil
+	}
+
+	tok.start = pos
+	var value strings.Builder
+	var closing rune // Closing quote of the quoted section being read, 0 outside quotes
+	escaped := false
+	for pos < len(text) {
+		r, size := utf8.DecodeRuneInString(text[pos:])
+		switch {
+		case escaped:
+			value.WriteRune(r)
+			escaped = false
+		case r == '\\' && closing != '\'':
+			escaped = true
+		case closing != 0:
+			if r == closing {
+				closing = 0
+			} else {
+				value.WriteRune(r)
+			}
+		case unicode.IsSpace(r):
+			tok.value = value.String()
+			return tok, pos, true, nil
+		default:
+			if c, isQuote := closingQuotes[r]; isQuote {
+				closing = c
+				tok.quoted = true
+			} else {
+				value.WriteRune(r)
+			}
+		}
+		pos += size
+	}
+	if closing != 0 {
+		return token{}, pos, false, errUnterminatedQuote
+	}
+	if escaped {
+		value.WriteRune('\\') // A trailing backslash is kept as is
+	}
+	tok.value = value.String()
+	return tok, pos, true, nil
+}
+
+// contains reports whether list contains s.
+// matchChoice returns the choice a value stands for. Case is ignored and "-" matches "_", so
+// e.g. "In-Progress" matches "in_progress".
+func matchChoice(choices []string, value string) (string, bool) {
+	normalize := func(s string) string {
+		return strings.ReplaceAll(strings.ToLower(s), "-", "_")
+	}
+	for _, choice := range choices {
+		if normalize(choice) == normalize(value) {
+			return choice, true
+		}
+	}
+	return "", false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
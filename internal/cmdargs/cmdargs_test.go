@@ -0,0 +1,99 @@
+package cmdargs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSpec = Spec{
+	Command: "post",
+	Args: []Arg{
+		{Name: "url", Required: true},
+		{Name: "caption", Rest: true},
+	},
+	Flags:   []string{"silent"},
+	Options: []string{"delay"},
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		url     string
+		caption string
+		silent  bool
+		delay   string
+	}{
+		{"url only", "/post https://x.test/a.png", "https://x.test/a.png", "", false, ""},
+		{"bot mention", "/post@MemesBot https://x.test/a.png", "https://x.test/a.png", "", false, ""},
+		{"rest as typed", "/post u  two  words\nnext line ", "u", "two  words\nnext line", false, ""},
+		{"quoted url", `/post "a b" c`, "a b", "c", false, ""},
+		{"quoted rest", `/post u "one token"`, "u", "one token", false, ""},
+		{"curly quotes", "/post “a b” c", "a b", "c", false, ""},
+		{"angle quotes", "/post «a b»", "a b", "", false, ""},
+		{"escaped space", `/post a\ b`, "a b", "", false, ""},
+		{"apostrophe in caption", "/post u don't stop", "u", "don't stop", false, ""},
+		{"flag and option", "/post --silent delay=5m u cap", "u", "cap", true, "5m"},
+		{"double dash ends flags", "/post -- --silent", "--silent", "", false, ""},
+		{"quoted flag is positional", `/post "--silent"`, "--silent", "", false, ""},
+		{"undeclared option is positional", "/post a=b", "a=b", "", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := testSpec.Parse(tt.text)
+			require.NoError(t, err)
+			assert.Equal(t, tt.url, args.Arg("url"))
+			assert.Equal(t, tt.caption, args.Arg("caption"))
+			assert.Equal(t, tt.silent, args.Flag("silent"))
+			delay, _ := args.Option("delay")
+			assert.Equal(t, tt.delay, delay)
+		})
+	}
+}
+
+func TestParseUsageErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      Spec
+		text      string
+		messageID string
+	}{
+		{"missing required", testSpec, "/post", "MsgArgsMissing"},
+		{"missing after flag", testSpec, "/post --silent", "MsgArgsMissing"},
+		{"unknown flag", testSpec, "/post --loud u", "MsgArgsUnknownFlag"},
+		{"unterminated quote", testSpec, `/post "u`, "MsgArgsUnterminatedQuote"},
+		{"too many", Spec{Command: "x", Args: []Arg{{Name: "a"}}}, "/x a b", "MsgArgsTooMany"},
+		{"invalid choice", Spec{Command: "x", Args: []Arg{{Name: "a", Choices: []string{"on", "off"}}}}, "/x maybe", "MsgArgsInvalidChoice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.spec.Parse(tt.text)
+			var usageErr *UsageError
+			require.ErrorAs(t, err, &usageErr)
+			assert.Equal(t, tt.messageID, usageErr.MessageID)
+			assert.Equal(t, tt.spec.Usage(), usageErr.Data["Usage"])
+		})
+	}
+}
+
+func TestParseChoiceIsCaseInsensitive(t *testing.T) {
+	spec := Spec{Command: "x", Args: []Arg{{Name: "a", Choices: []string{"open"}}}}
+	args, err := spec.Parse("/x OPEN")
+	require.NoError(t, err)
+	assert.Equal(t, "open", args.Arg("a"))
+}
+
+func TestParseChoiceAcceptsDashForUnderscore(t *testing.T) {
+	spec := Spec{Command: "feedbacks", Args: []Arg{{Name: "filter", Choices: []string{"open", "in_progress"}}}}
+	args, err := spec.Parse("/feedbacks in-progress")
+	require.NoError(t, err)
+	assert.Equal(t, "in_progress", args.Arg("filter"))
+}
+
+func TestUsage(t *testing.T) {
+	assert.Equal(t, "/post [--silent] [delay=…] <url> [caption...]", testSpec.Usage())
+	spec := Spec{Command: "x", Args: []Arg{{Name: "filter", Choices: []string{"all", "open"}}}}
+	assert.Equal(t, "/x [all|open]", spec.Usage())
+}
@@ -4,22 +4,167 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/mediagroups"
+	"vrcmemes-bot/internal/videopolicy"
 
 	"github.com/joho/godotenv"
 )
 
+// Defaults of settings whose packages can't be imported here, as they depend on the database
+// package, which imports this one. Those packages use the same constants when unconfigured.
+const (
+	// DefaultSubscriptionCacheTTL is how long a positive subscription check is cached.
+	DefaultSubscriptionCacheTTL = 10 * time.Minute
+	// DefaultSubscriptionNegativeCacheTTL is how long a negative subscription check is cached.
+	DefaultSubscriptionNegativeCacheTTL = time.Minute
+	// DefaultMemberStatusTTL is how long a membership stored from chat_member updates is trusted
+	// before subscription checks ask Telegram again.
+	DefaultMemberStatusTTL = 24 * time.Hour
+
+	// Default feedback spam limits.
+	DefaultFeedbackRateLimit    = 3                // Feedback messages allowed per window
+	DefaultFeedbackRateWindow   = 10 * time.Minute // Window for the rate limit
+	DefaultFeedbackMinLength    = 10               // Minimum length of text-only feedback, in characters
+	DefaultFeedbackMuteAfter    = 3                // Violations within an hour before a mute
+	DefaultFeedbackMuteDuration = time.Hour        // How long repeat offenders can't send feedback
+)
+
 // Config holds the application configuration.
 type Config struct {
 	AppEnv          string
 	Debug           bool
-	Version         string
 	BotToken        string
 	ChannelID       int64
 	SentryDSN       string
 	MongoDBURI      string
 	MongoDBDatabase string
 	DefaultLanguage string
+	// AllowedUpdates overrides the update types requested from Telegram (comma-separated ALLOWED_UPDATES).
+	// When empty, the list is derived from the bot's capabilities.
+	AllowedUpdates   []string
+	TrackChatMembers bool
+	TrackReactions   bool // Receive reaction counts of channel posts, used by /bestof
+	// Subscription check cache TTLs (positive and negative results)
+	SubscriptionCacheTTL         time.Duration
+	SubscriptionNegativeCacheTTL time.Duration
+	MemberStatusTTL              time.Duration  // How long a stored channel membership is trusted by subscription checks
+	ReviewSessionTTL             time.Duration  // Inactivity after which a review session expires
+	ReviewOrder                  string         // Default order of /review: fifo, lifo, priority, random, round_robin or mine
+	ReviewAssignment             bool           // Assign new suggestions to available admins in turn, reviewed with /review mine
+	ReviewReassignAfter          time.Duration  // Untouched assignments are handed to the next admin after this; 0 disables
+	ReviewReminderAfter          time.Duration  // Inactivity after which an admin is reminded of their review session; 0 disables
+	SuggestionExpireAfter        time.Duration  // Pending suggestions older than this are rejected as expired; 0 disables
+	NotifyExpiredSuggestions     bool           // Tell suggesters when their suggestion expires
+	AdminCacheTTL                time.Duration  // How long admin status checks are cached
+	ChannelRightsCacheTTL        time.Duration  // How long the bot's posting rights in the channel are trusted before publishing
+	SettingsCacheTTL             time.Duration  // How long runtime settings are cached
+	PublishMinInterval           time.Duration  // Minimum pause between channel publications; 0 uses the publisher's default
+	CaptionTestWindow            time.Duration  // How long after publication /abtest posts are measured
+	SuperAdminIDs                []int64        // Users who are always admins, regardless of channel status
+	AcceptDirectSuggestions      bool           // Offer photos sent without /suggest as suggestions
+	PreviewSuggestions           bool           // Show users their suggestion with Confirm/Cancel buttons before submitting it
+	SuggestRulesChecklist        bool           // Confirm a rules checklist before /suggest waits for content
+	NewSuggestionPings           bool           // Notify admins in private chat about new suggestions, honoring their quiet hours
+	CreditForwardSource          bool           // Credit the source channel when publishing forwarded suggestions
+	CreditSuggesters             bool           // Name suggesters in published captions unless they chose to stay anonymous
+	CreditCoSuggesters           bool           // Also name the suggesters of duplicates merged into a published suggestion
+	ProtectContent               bool           // Publish with protected content, which can't be forwarded or saved, unless /setup changes it
+	SuggestionContentTypes       string         // Media types accepted in suggestions unless /contenttypes changes them, e.g. "photo,video"
+	SuggestionMinResolution      int            // Smallest shorter side of suggested photos, videos and GIFs in pixels; 0 disables
+	SuggestionMaxFileSize        int64          // Largest suggested file in bytes; 0 disables
+	SuggestionMediaWarnOnly      bool           // Accept media outside the limits with a warning to reviewers instead of rejecting it
+	VideoMaxDuration             time.Duration  // Longest suggested or posted video; 0 disables
+	VideoAspectRatios            string         // Allowed aspect ratios of videos, e.g. "9:16-16:9"; empty allows any
+	CustomEmoji                  bool           // Keep custom emoji in published captions; needs a bot username bought on Fragment
+	MediaPrivacyMode             bool           // Re-upload suggested media instead of reusing the file IDs users sent
+	OCRCommand                   string         // Command recognizing the text of photos read from stdin; empty disables OCR
+	FFmpegPath                   string         // ffmpeg binary extracting video frames as thumbnails; empty allows only image thumbnails
+	TranslateURL                 string         // LibreTranslate server translating suggestion captions; empty disables translation
+	TranslateAPIKey              string         // API key of the TranslateURL server, if it needs one
+	SuggestionTags               []string       // Tags reviewers can attach to suggestions, published as hashtags
+	HashtagFooters               []string       // Hashtag footers rotated across approved and staged posts
+	TimeZone                     *time.Location // Channel time zone for user-facing times, reports and schedules
+	// Cache of admin and subscription checks, user states and settings: "memory", or "redis"
+	// to share it between instances
+	CacheBackend   string
+	RedisURL       string
+	CacheKeyPrefix string
+	// Links filled into the {channel_link} and {rules_link} placeholders of the /setgreeting greeting
+	ChannelURL string
+	RulesURL   string
+	// Auto-reject rules evaluated on submission; zero values disable a rule
+	AutoRejectCaptionPattern     *regexp.Regexp
+	AutoRejectMaxMedia           int
+	AutoRejectMinAccountAge      time.Duration
+	AutoRejectMinSubscriptionAge time.Duration
+	// Feedback spam limits; zero values disable a check
+	FeedbackRateLimit    int
+	FeedbackRateWindow   time.Duration
+	FeedbackMinLength    int
+	FeedbackMuteAfter    int
+	FeedbackMuteDuration time.Duration
+	// Per-update-type processing timeouts
+	MessageTimeout       time.Duration
+	CallbackQueryTimeout time.Duration
+	ChatMemberTimeout    time.Duration
+	MediaGroupTimeout    time.Duration
+	// Persist processed update IDs so resent updates are skipped across restarts
+	PersistUpdateIDs  bool
+	UpdateIDRetention time.Duration
+	// Album aggregation: how long to wait for the rest of a media group, and its maximum size
+	MediaGroupDelay   time.Duration
+	MediaGroupMaxSize int
+	// Process an album early once no new item arrived for this long (0 always waits MediaGroupDelay)
+	MediaGroupIdleDelay time.Duration
+	// Posting from URLs (/posturl) and a local staging directory for automated pipelines
+	MaxPostFileSize     int64  // In bytes
+	StagingDir          string // Empty disables the staging directory
+	StagingPollInterval time.Duration
+	DryRun              bool // Log channel-changing calls instead of executing them
+	// Chat (and optional forum topic) where new feedback is forwarded; 0 only stores feedback
+	FeedbackChatID  int64
+	FeedbackTopicID int
+	// Group where every photo or album posted becomes a suggestion of the poster; 0 disables it
+	IntakeChatID int64
+	// Channel reviewers can post approved suggestions to with "Preview first" before promoting
+	// them to the channel; 0 disables previews
+	PreviewChannelID int64
+	// Groups the bot works in besides the channel's discussion group and the feedback, intake
+	// and alert chats, and what it does in other groups: "ignore" their messages, "leave" them,
+	// or "allow" every group
+	AllowedGroupIDs []int64
+	UnknownGroups   string
+	// Multi-tenant mode: channel owners can connect their own channels with /connect, each with its
+	// own admins, settings and suggestion queue; CHANNEL_ID is the primary channel
+	MultiTenant bool
+	// Default quotas of connected channels, changed per channel with /tenants: the pending
+	// suggestions they can hold and the updates they can receive per minute. Zero is unlimited
+	TenantMaxPendingSuggestions int
+	TenantUpdatesPerMinute      int
+	// Degraded mode: when MongoDB fails repeatedly, post logs and new suggestions are buffered
+	// (in memory, then in a spill file) and replayed once it is reachable again
+	DBHealthCheckInterval time.Duration
+	DBFailureThreshold    int
+	DBBufferSize          int    // Writes buffered in memory before spilling to disk
+	DBBufferSpillPath     string // Empty keeps buffered writes in memory only
+	DBBufferMaxSpilled    int
+	// Attempts at delivering a notification from the outbox before it is marked as failed
+	OutboxMaxAttempts int
+	// Error budget alerts: when more Telegram, database or panic errors than allowed happen
+	// within the window, the alert chat (or the super admins) is told, at most once per cooldown
+	AlertChatID         int64
+	ErrorBudgetWindow   time.Duration
+	ErrorBudgetCooldown time.Duration
+	ErrorBudgetTelegram int // 0 disables the alert
+	ErrorBudgetDatabase int // 0 disables the alert
+	ErrorBudgetPanics   int // 0 disables the alert
+	// Pinned dashboard in the admin chat, edited at most once per interval; 0 disables it
+	DashboardChatID   int64
+	DashboardInterval time.Duration
 }
 
 // LoadConfig loads configuration from environment variables.
@@ -32,6 +177,335 @@ func LoadConfig() (*Config, error) {
 	}
 
 	debug, _ := strconv.ParseBool(getEnv("DEBUG", "false"))
+	trackChatMembers, _ := strconv.ParseBool(getEnv("TRACK_CHAT_MEMBERS", "false"))
+	trackReactions, _ := strconv.ParseBool(getEnv("TRACK_REACTIONS", "false"))
+	acceptDirectSuggestions, _ := strconv.ParseBool(getEnv("ACCEPT_DIRECT_SUGGESTIONS", "false"))
+	previewSuggestions, _ := strconv.ParseBool(getEnv("PREVIEW_SUGGESTIONS", "true"))
+	multiTenant, _ := strconv.ParseBool(getEnv("MULTI_TENANT", "false"))
+	creditForwardSource, _ := strconv.ParseBool(getEnv("CREDIT_FORWARD_SOURCE", "false"))
+	creditSuggesters, _ := strconv.ParseBool(getEnv("CREDIT_SUGGESTERS", "false"))
+	creditCoSuggesters, _ := strconv.ParseBool(getEnv("CREDIT_MERGED_SUGGESTERS", "false"))
+	protectContent, _ := strconv.ParseBool(getEnv("PROTECT_CONTENT", "false"))
+	customEmoji, _ := strconv.ParseBool(getEnv("CUSTOM_EMOJI", "false"))
+	mediaPrivacyMode, _ := strconv.ParseBool(getEnv("MEDIA_PRIVACY_MODE", "false"))
+	suggestRulesChecklist, _ := strconv.ParseBool(getEnv("SUGGEST_RULES_CHECKLIST", "false"))
+	newSuggestionPings, _ := strconv.ParseBool(getEnv("ADMIN_NEW_SUGGESTION_PINGS", "false"))
+	reviewAssignment, _ := strconv.ParseBool(getEnv("REVIEW_ASSIGNMENT", "false"))
+
+	subscriptionTTL, err := getEnvDuration("SUBSCRIPTION_CACHE_TTL", DefaultSubscriptionCacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	subscriptionNegativeTTL, err := getEnvDuration("SUBSCRIPTION_NEGATIVE_CACHE_TTL", DefaultSubscriptionNegativeCacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	memberStatusTTL, err := getEnvDuration("MEMBER_STATUS_TTL", DefaultMemberStatusTTL)
+	if err != nil {
+		return nil, err
+	}
+	reviewSessionTTL, err := getEnvDuration("REVIEW_SESSION_TTL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	reviewReminderAfter, err := getEnvDuration("REVIEW_REMINDER_AFTER", 0)
+	if err != nil {
+		return nil, err
+	}
+	if reviewReminderAfter > 0 && reviewSessionTTL > 0 && reviewReminderAfter >= reviewSessionTTL {
+		return nil, fmt.Errorf("REVIEW_REMINDER_AFTER (%v) must be shorter than REVIEW_SESSION_TTL (%v)", reviewReminderAfter, reviewSessionTTL)
+	}
+	suggestionExpireAfter, err := getEnvDuration("SUGGESTION_EXPIRE_AFTER", 0)
+	if err != nil {
+		return nil, err
+	}
+	notifyExpiredSuggestions, _ := strconv.ParseBool(getEnv("NOTIFY_EXPIRED_SUGGESTIONS", "true"))
+	adminCacheTTL, err := getEnvDuration("ADMIN_CACHE_TTL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	channelRightsCacheTTL, err := getEnvDuration("CHANNEL_RIGHTS_CACHE_TTL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	settingsCacheTTL, err := getEnvDuration("SETTINGS_CACHE_TTL", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	reviewOrder := strings.ToLower(getEnv("REVIEW_ORDER", "fifo"))
+	switch reviewOrder {
+	case "fifo", "lifo", "priority", "random", "round_robin":
+	case "mine":
+		if !reviewAssignment {
+			return nil, fmt.Errorf("REVIEW_ORDER=mine requires REVIEW_ASSIGNMENT=true")
+		}
+	default:
+		return nil, fmt.Errorf("invalid REVIEW_ORDER %q: expected fifo, lifo, priority, random, round_robin or mine", reviewOrder)
+	}
+	suggestionContentTypes := strings.ToLower(getEnv("SUGGESTION_CONTENT_TYPES", "photo"))
+	if len(splitList(suggestionContentTypes)) == 0 {
+		suggestionContentTypes = "photo"
+	}
+	for _, contentType := range splitList(suggestionContentTypes) {
+		switch contentType {
+		case "photo", "video", "animation", "gif", "document":
+		default:
+			return nil, fmt.Errorf("invalid SUGGESTION_CONTENT_TYPES item %q: expected photo, video, animation (or gif) or document", contentType)
+		}
+	}
+	suggestionMinResolution, err := getEnvInt("SUGGESTION_MIN_RESOLUTION", 0)
+	if err != nil {
+		return nil, err
+	}
+	suggestionMaxFileSizeMB, err := getEnvInt("SUGGESTION_MAX_FILE_SIZE_MB", 0)
+	if err != nil {
+		return nil, err
+	}
+	suggestionMediaLimits := strings.ToLower(getEnv("SUGGESTION_MEDIA_LIMITS", "reject"))
+	switch suggestionMediaLimits {
+	case "reject", "warn":
+	default:
+		return nil, fmt.Errorf("invalid SUGGESTION_MEDIA_LIMITS %q: expected reject or warn", suggestionMediaLimits)
+	}
+	videoMaxDuration, err := getEnvDuration("VIDEO_MAX_DURATION", 0)
+	if err != nil {
+		return nil, err
+	}
+	videoAspectRatios := getEnv("VIDEO_ASPECT_RATIOS", "")
+	if _, _, err := videopolicy.ParseAspectRatios(videoAspectRatios); err != nil {
+		return nil, fmt.Errorf("invalid VIDEO_ASPECT_RATIOS: %w", err)
+	}
+	reviewReassignAfter, err := getEnvDuration("REVIEW_REASSIGN_AFTER", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	cacheBackend := strings.ToLower(getEnv("CACHE_BACKEND", "memory"))
+	redisURL := getEnv("REDIS_URL", "")
+	switch cacheBackend {
+	case "memory":
+	case "redis":
+		if redisURL == "" {
+			return nil, fmt.Errorf("REDIS_URL is required with CACHE_BACKEND=redis")
+		}
+	default:
+		return nil, fmt.Errorf("invalid CACHE_BACKEND %q: expected memory or redis", cacheBackend)
+	}
+	// 0 leaves the pause to publisher.DefaultMinInterval, which can't be imported here without a cycle
+	publishMinInterval, err := getEnvDuration("PUBLISH_MIN_INTERVAL", 0)
+	if err != nil {
+		return nil, err
+	}
+	captionTestWindow, err := getEnvDuration("CAPTION_TEST_WINDOW", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	if captionTestWindow <= 0 {
+		return nil, fmt.Errorf("CAPTION_TEST_WINDOW must be positive, got %v", captionTestWindow)
+	}
+	mediaGroupDelay, err := getEnvDuration("MEDIA_GROUP_DELAY", mediagroups.DefaultProcessDelay)
+	if err != nil {
+		return nil, err
+	}
+	mediaGroupIdleDelay, err := getEnvDuration("MEDIA_GROUP_IDLE_DELAY", mediagroups.DefaultIdleDelay)
+	if err != nil {
+		return nil, err
+	}
+	mediaGroupMaxSize, err := getEnvInt("MEDIA_GROUP_MAX_SIZE", mediagroups.DefaultMaxGroupSize)
+	if err != nil {
+		return nil, err
+	}
+	if mediaGroupMaxSize < 1 || mediaGroupMaxSize > mediagroups.DefaultMaxGroupSize {
+		return nil, fmt.Errorf("MEDIA_GROUP_MAX_SIZE must be between 1 and %d, got %d", mediagroups.DefaultMaxGroupSize, mediaGroupMaxSize)
+	}
+	tenantMaxPendingSuggestions, err := getEnvInt("TENANT_MAX_PENDING_SUGGESTIONS", 200)
+	if err != nil {
+		return nil, err
+	}
+	tenantUpdatesPerMinute, err := getEnvInt("TENANT_UPDATES_PER_MINUTE", 120)
+	if err != nil {
+		return nil, err
+	}
+	if tenantMaxPendingSuggestions < 0 || tenantUpdatesPerMinute < 0 {
+		return nil, fmt.Errorf("TENANT_MAX_PENDING_SUGGESTIONS and TENANT_UPDATES_PER_MINUTE must not be negative")
+	}
+	messageTimeout, err := getEnvDuration("MESSAGE_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	callbackQueryTimeout, err := getEnvDuration("CALLBACK_QUERY_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	chatMemberTimeout, err := getEnvDuration("CHAT_MEMBER_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	mediaGroupTimeout, err := getEnvDuration("MEDIA_GROUP_TIMEOUT", 60*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	persistUpdateIDs, _ := strconv.ParseBool(getEnv("PERSIST_UPDATE_IDS", "false"))
+	updateIDRetention, err := getEnvDuration("UPDATE_ID_RETENTION", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	dryRun, _ := strconv.ParseBool(getEnv("DRY_RUN", "false"))
+	maxPostFileSizeMB, err := getEnvInt("MAX_POST_FILE_SIZE_MB", 10)
+	if err != nil {
+		return nil, err
+	}
+	stagingPollInterval, err := getEnvDuration("STAGING_POLL_INTERVAL", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	var feedbackChatID int64
+	if s := getEnv("FEEDBACK_CHAT_ID", ""); s != "" {
+		if feedbackChatID, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid FEEDBACK_CHAT_ID: %w", err)
+		}
+	}
+	feedbackTopicID, err := getEnvInt("FEEDBACK_TOPIC_ID", 0)
+	if err != nil {
+		return nil, err
+	}
+	var intakeChatID int64
+	if s := getEnv("INTAKE_CHAT_ID", ""); s != "" {
+		if intakeChatID, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid INTAKE_CHAT_ID: %w", err)
+		}
+	}
+	var previewChannelID int64
+	if s := getEnv("PREVIEW_CHANNEL_ID", ""); s != "" {
+		if previewChannelID, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid PREVIEW_CHANNEL_ID: %w", err)
+		}
+	}
+	superAdminIDs, err := parseIDList(getEnv("SUPER_ADMIN_IDS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SUPER_ADMIN_IDS: %w", err)
+	}
+	allowedGroupIDs, err := parseIDList(getEnv("ALLOWED_GROUP_IDS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ALLOWED_GROUP_IDS: %w", err)
+	}
+	unknownGroups := strings.ToLower(getEnv("UNKNOWN_GROUPS", "ignore"))
+	switch unknownGroups {
+	case "ignore", "leave", "allow":
+	default:
+		return nil, fmt.Errorf("invalid UNKNOWN_GROUPS %q: expected ignore, leave or allow", unknownGroups)
+	}
+
+	timeZoneName := getEnv("TIMEZONE", "UTC")
+	timeZone, err := time.LoadLocation(timeZoneName)
+	if err != nil || timeZoneName == "" || timeZoneName == "Local" {
+		return nil, fmt.Errorf("invalid TIMEZONE %q: expected an IANA time zone name such as Europe/Moscow", timeZoneName)
+	}
+
+	var captionPattern *regexp.Regexp
+	if pattern := getEnv("AUTO_REJECT_CAPTION_REGEX", ""); pattern != "" {
+		captionPattern, err = regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AUTO_REJECT_CAPTION_REGEX: %w", err)
+		}
+	}
+	maxMedia, err := getEnvInt("AUTO_REJECT_MAX_MEDIA", 0)
+	if err != nil {
+		return nil, err
+	}
+	minAccountAgeDays, err := getEnvInt("AUTO_REJECT_MIN_ACCOUNT_AGE_DAYS", 0)
+	if err != nil {
+		return nil, err
+	}
+	minSubscriptionDays, err := getEnvInt("AUTO_REJECT_MIN_SUBSCRIPTION_DAYS", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	feedbackRateLimit, err := getEnvInt("FEEDBACK_RATE_LIMIT", DefaultFeedbackRateLimit)
+	if err != nil {
+		return nil, err
+	}
+	feedbackRateWindow, err := getEnvDuration("FEEDBACK_RATE_WINDOW", DefaultFeedbackRateWindow)
+	if err != nil {
+		return nil, err
+	}
+	feedbackMinLength, err := getEnvInt("FEEDBACK_MIN_LENGTH", DefaultFeedbackMinLength)
+	if err != nil {
+		return nil, err
+	}
+	feedbackMuteAfter, err := getEnvInt("FEEDBACK_MUTE_AFTER", DefaultFeedbackMuteAfter)
+	if err != nil {
+		return nil, err
+	}
+	feedbackMuteDuration, err := getEnvDuration("FEEDBACK_MUTE_DURATION", DefaultFeedbackMuteDuration)
+	if err != nil {
+		return nil, err
+	}
+	dbHealthCheckInterval, err := getEnvDuration("DB_HEALTH_CHECK_INTERVAL", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	dbFailureThreshold, err := getEnvInt("DB_FAILURE_THRESHOLD", 3)
+	if err != nil {
+		return nil, err
+	}
+	dbBufferSize, err := getEnvInt("DB_BUFFER_SIZE", 1000)
+	if err != nil {
+		return nil, err
+	}
+	dbBufferMaxSpilled, err := getEnvInt("DB_BUFFER_MAX_SPILLED", 10000)
+	if err != nil {
+		return nil, err
+	}
+	outboxMaxAttempts, err := getEnvInt("OUTBOX_MAX_ATTEMPTS", 5)
+	if err != nil {
+		return nil, err
+	}
+	if outboxMaxAttempts <= 0 {
+		return nil, fmt.Errorf("OUTBOX_MAX_ATTEMPTS must be positive, got %d", outboxMaxAttempts)
+	}
+	var alertChatID int64
+	if s := getEnv("ALERT_CHAT_ID", ""); s != "" {
+		if alertChatID, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid ALERT_CHAT_ID: %w", err)
+		}
+	}
+	errorBudgetWindow, err := getEnvDuration("ERROR_BUDGET_WINDOW", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	errorBudgetCooldown, err := getEnvDuration("ERROR_BUDGET_COOLDOWN", 30*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if errorBudgetWindow <= 0 || errorBudgetCooldown <= 0 {
+		return nil, fmt.Errorf("ERROR_BUDGET_WINDOW and ERROR_BUDGET_COOLDOWN must be positive")
+	}
+	errorBudgetTelegram, err := getEnvInt("ERROR_BUDGET_TELEGRAM", 20)
+	if err != nil {
+		return nil, err
+	}
+	errorBudgetDatabase, err := getEnvInt("ERROR_BUDGET_DATABASE", 10)
+	if err != nil {
+		return nil, err
+	}
+	errorBudgetPanics, err := getEnvInt("ERROR_BUDGET_PANICS", 1)
+	if err != nil {
+		return nil, err
+	}
+	var dashboardChatID int64
+	if s := getEnv("DASHBOARD_CHAT_ID", ""); s != "" {
+		if dashboardChatID, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid DASHBOARD_CHAT_ID: %w", err)
+		}
+	}
+	dashboardInterval, err := getEnvDuration("DASHBOARD_INTERVAL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if dashboardInterval < time.Minute {
+		return nil, fmt.Errorf("DASHBOARD_INTERVAL must be at least 1m, got %s", dashboardInterval)
+	}
 
 	channelIDStr := getEnv("CHANNEL_ID", "")
 	channelID, err := strconv.ParseInt(channelIDStr, 10, 64)
@@ -42,15 +516,114 @@ func LoadConfig() (*Config, error) {
 	}
 
 	cfg := &Config{
-		AppEnv:          getEnv("APP_ENV", "development"),
-		Debug:           debug,
-		Version:         getEnv("VERSION", "dev"),
-		BotToken:        getEnv("TELEGRAM_BOT_TOKEN", ""),
-		ChannelID:       channelID,
-		SentryDSN:       getEnv("SENTRY_DSN", ""),
-		MongoDBURI:      getEnv("MONGODB_URI", ""), // URI might be complex, handle validation carefully if needed
-		MongoDBDatabase: getEnv("MONGODB_DATABASE", ""),
-		DefaultLanguage: getEnv("BOT_DEFAULT_LANGUAGE", "en"),
+		AppEnv:           getEnv("APP_ENV", "development"),
+		Debug:            debug,
+		BotToken:         getEnv("TELEGRAM_BOT_TOKEN", ""),
+		ChannelID:        channelID,
+		SentryDSN:        getEnv("SENTRY_DSN", ""),
+		MongoDBURI:       getEnv("MONGODB_URI", ""), // URI might be complex, handle validation carefully if needed
+		MongoDBDatabase:  getEnv("MONGODB_DATABASE", ""),
+		DefaultLanguage:  getEnv("BOT_DEFAULT_LANGUAGE", "en"),
+		AllowedUpdates:   splitList(getEnv("ALLOWED_UPDATES", "")),
+		TrackChatMembers: trackChatMembers,
+		TrackReactions:   trackReactions,
+
+		SubscriptionCacheTTL:         subscriptionTTL,
+		SubscriptionNegativeCacheTTL: subscriptionNegativeTTL,
+		MemberStatusTTL:              memberStatusTTL,
+		ReviewSessionTTL:             reviewSessionTTL,
+		ReviewOrder:                  reviewOrder,
+		ReviewAssignment:             reviewAssignment,
+		ReviewReassignAfter:          reviewReassignAfter,
+		ReviewReminderAfter:          reviewReminderAfter,
+		SuggestionExpireAfter:        suggestionExpireAfter,
+		NotifyExpiredSuggestions:     notifyExpiredSuggestions,
+		AdminCacheTTL:                adminCacheTTL,
+		ChannelRightsCacheTTL:        channelRightsCacheTTL,
+		SettingsCacheTTL:             settingsCacheTTL,
+		CacheBackend:                 cacheBackend,
+		RedisURL:                     redisURL,
+		CacheKeyPrefix:               getEnv("CACHE_KEY_PREFIX", "vrcmemes:"),
+		PublishMinInterval:           publishMinInterval,
+		CaptionTestWindow:            captionTestWindow,
+		MessageTimeout:               messageTimeout,
+		CallbackQueryTimeout:         callbackQueryTimeout,
+		ChatMemberTimeout:            chatMemberTimeout,
+		MediaGroupTimeout:            mediaGroupTimeout,
+		PersistUpdateIDs:             persistUpdateIDs,
+		UpdateIDRetention:            updateIDRetention,
+		MediaGroupDelay:              mediaGroupDelay,
+		MediaGroupMaxSize:            mediaGroupMaxSize,
+		MediaGroupIdleDelay:          mediaGroupIdleDelay,
+		MaxPostFileSize:              int64(maxPostFileSizeMB) << 20,
+		StagingDir:                   getEnv("STAGING_DIR", ""),
+		StagingPollInterval:          stagingPollInterval,
+		DryRun:                       dryRun,
+		SuperAdminIDs:                superAdminIDs,
+		AcceptDirectSuggestions:      acceptDirectSuggestions,
+		PreviewSuggestions:           previewSuggestions,
+		SuggestRulesChecklist:        suggestRulesChecklist,
+		NewSuggestionPings:           newSuggestionPings,
+		CreditForwardSource:          creditForwardSource,
+		CreditSuggesters:             creditSuggesters,
+		CreditCoSuggesters:           creditCoSuggesters,
+		ProtectContent:               protectContent,
+		SuggestionContentTypes:       suggestionContentTypes,
+		SuggestionMinResolution:      suggestionMinResolution,
+		SuggestionMaxFileSize:        int64(suggestionMaxFileSizeMB) << 20,
+		SuggestionMediaWarnOnly:      suggestionMediaLimits == "warn",
+		VideoMaxDuration:             videoMaxDuration,
+		VideoAspectRatios:            videoAspectRatios,
+		CustomEmoji:                  customEmoji,
+		MediaPrivacyMode:             mediaPrivacyMode,
+		OCRCommand:                   strings.TrimSpace(getEnv("OCR_COMMAND", "")),
+		FFmpegPath:                   strings.TrimSpace(getEnv("FFMPEG_PATH", "")),
+		TranslateURL:                 strings.TrimSpace(getEnv("TRANSLATE_URL", "")),
+		TranslateAPIKey:              getEnv("TRANSLATE_API_KEY", ""),
+		SuggestionTags:               splitList(getEnv("SUGGESTION_TAGS", "")),
+		HashtagFooters:               splitList(getEnv("HASHTAG_FOOTERS", "")),
+		TimeZone:                     timeZone,
+		ChannelURL:                   getEnv("CHANNEL_URL", ""),
+		RulesURL:                     getEnv("RULES_URL", ""),
+
+		AutoRejectCaptionPattern:     captionPattern,
+		AutoRejectMaxMedia:           maxMedia,
+		AutoRejectMinAccountAge:      time.Duration(minAccountAgeDays) * 24 * time.Hour,
+		AutoRejectMinSubscriptionAge: time.Duration(minSubscriptionDays) * 24 * time.Hour,
+
+		FeedbackRateLimit:    feedbackRateLimit,
+		FeedbackRateWindow:   feedbackRateWindow,
+		FeedbackMinLength:    feedbackMinLength,
+		FeedbackMuteAfter:    feedbackMuteAfter,
+		FeedbackMuteDuration: feedbackMuteDuration,
+		FeedbackChatID:       feedbackChatID,
+		FeedbackTopicID:      feedbackTopicID,
+		IntakeChatID:         intakeChatID,
+		PreviewChannelID:     previewChannelID,
+		AllowedGroupIDs:      allowedGroupIDs,
+		UnknownGroups:        unknownGroups,
+		MultiTenant:          multiTenant,
+
+		TenantMaxPendingSuggestions: tenantMaxPendingSuggestions,
+		TenantUpdatesPerMinute:      tenantUpdatesPerMinute,
+
+		DBHealthCheckInterval: dbHealthCheckInterval,
+		DBFailureThreshold:    dbFailureThreshold,
+		DBBufferSize:          dbBufferSize,
+		DBBufferSpillPath:     getEnv("DB_BUFFER_SPILL_PATH", "data/pending_writes.jsonl"),
+		DBBufferMaxSpilled:    dbBufferMaxSpilled,
+
+		OutboxMaxAttempts: outboxMaxAttempts,
+
+		AlertChatID:         alertChatID,
+		ErrorBudgetWindow:   errorBudgetWindow,
+		ErrorBudgetCooldown: errorBudgetCooldown,
+		ErrorBudgetTelegram: errorBudgetTelegram,
+		ErrorBudgetDatabase: errorBudgetDatabase,
+		ErrorBudgetPanics:   errorBudgetPanics,
+
+		DashboardChatID:   dashboardChatID,
+		DashboardInterval: dashboardInterval,
 	}
 
 	// Basic validation for essential variables
@@ -60,6 +633,9 @@ func LoadConfig() (*Config, error) {
 	if cfg.ChannelID == 0 {
 		return nil, fmt.Errorf("CHANNEL_ID is required")
 	}
+	if cfg.PreviewChannelID != 0 && cfg.PreviewChannelID == cfg.ChannelID {
+		return nil, fmt.Errorf("PREVIEW_CHANNEL_ID must differ from CHANNEL_ID")
+	}
 	if cfg.SentryDSN == "" {
 		log.Println("Warning: SENTRY_DSN is not set. Error tracking disabled.")
 	}
@@ -80,3 +656,54 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvDuration retrieves an environment variable as a duration (e.g., "10m", "30s")
+// or returns the default value if it is not set.
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return d, nil
+}
+
+// getEnvInt parses a non-negative integer environment variable, returning the default if it is unset.
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s: must be a non-negative integer", key)
+	}
+	return n, nil
+}
+
+// splitList splits a comma-separated value into trimmed, non-empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseIDList parses a comma-separated list of Telegram user or chat IDs.
+func parseIDList(value string) ([]int64, error) {
+	var ids []int64
+	for _, item := range splitList(value) {
+		id, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an ID", item)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,130 @@
+// Package customemoji keeps the custom emoji of texts, such as those of Telegram Premium emoji
+// packs, when the texts become part of a caption assembled by the bot. Captions are stored and
+// built as plain strings, so the custom emoji are kept aside as models.CustomEmoji and placed
+// again by finding their text in the assembled caption.
+package customemoji
+
+import (
+	"strings"
+	"unicode/utf16"
+	"vrcmemes-bot/internal/database/models"
+
+	"github.com/mymmrac/telego"
+)
+
+// FromEntities returns the custom emoji among the entities of a text.
+func FromEntities(entities []telego.MessageEntity) []models.CustomEmoji {
+	var emoji []models.CustomEmoji
+	for _, entity := range entities {
+		if entity.Type == telego.EntityTypeCustomEmoji && entity.CustomEmojiID != "" {
+			emoji = append(emoji, models.CustomEmoji{Offset: entity.Offset, Length: entity.Length, ID: entity.CustomEmojiID})
+		}
+	}
+	return emoji
+}
+
+// Entities returns custom emoji as message entities to send with a text.
+func Entities(emoji []models.CustomEmoji) []telego.MessageEntity {
+	if len(emoji) == 0 {
+		return nil
+	}
+	entities := make([]telego.MessageEntity, len(emoji))
+	for i, e := range emoji {
+		entities[i] = telego.MessageEntity{
+			Type:          telego.EntityTypeCustomEmoji,
+			Offset:        e.Offset,
+			Length:        e.Length,
+			CustomEmojiID: e.ID,
+		}
+	}
+	return entities
+}
+
+// Within returns the custom emoji of text that lie in part, the first occurrence of a substring
+// of text, with offsets relative to part. It is used to keep the emoji of a command argument.
+func Within(emoji []models.CustomEmoji, text, part string) []models.CustomEmoji {
+	start := strings.Index(text, part)
+	if start < 0 || part == "" {
+		return nil
+	}
+	return slice(emoji, utf16Len(text[:start]), utf16Len(part))
+}
+
+// Relocate returns the custom emoji of text at their offsets in caption, a caption assembled
+// around text, e.g. with a rubric number or a caption style. If the caption doesn't contain
+// text, e.g. because the text was replaced meanwhile, there are none.
+func Relocate(emoji []models.CustomEmoji, text, caption string) []models.CustomEmoji {
+	relocated, _ := relocate(emoji, text, caption, 0)
+	return relocated
+}
+
+// Styled returns the custom emoji of a caption style's template at their offsets in caption,
+// a caption decorated with the style. The parts of the template around the caption placeholder
+// are looked up in turn, so the emoji of a part repeated in the caption itself aren't misplaced
+// unless the caption comes first.
+func Styled(style models.CaptionStyle, caption string) []models.CustomEmoji {
+	if len(style.Emoji) == 0 || caption == "" {
+		return nil
+	}
+	var (
+		emoji []models.CustomEmoji
+		from  int // Byte offset in caption where the next part is looked up
+		start int // UTF-16 offset of the part in the template
+	)
+	for i, part := range strings.Split(style.Template, models.CaptionStylePlaceholder) {
+		if i > 0 {
+			start += utf16Len(models.CaptionStylePlaceholder)
+		}
+		partEmoji := slice(style.Emoji, start, utf16Len(part))
+		start += utf16Len(part)
+		if len(partEmoji) == 0 {
+			continue
+		}
+		relocated, next := relocate(partEmoji, part, caption, from)
+		if next < 0 {
+			continue
+		}
+		emoji = append(emoji, relocated...)
+		from = next
+	}
+	return emoji
+}
+
+// relocate places the custom emoji of text at the first occurrence of text in caption at or
+// after the byte offset from. It returns the byte offset following the occurrence, or -1 if
+// there is none.
+func relocate(emoji []models.CustomEmoji, text, caption string, from int) ([]models.CustomEmoji, int) {
+	if text == "" || from > len(caption) {
+		return nil, -1
+	}
+	index := strings.Index(caption[from:], text)
+	if index < 0 {
+		return nil, -1
+	}
+	index += from
+	shift := utf16Len(caption[:index])
+	relocated := make([]models.CustomEmoji, 0, len(emoji))
+	for _, e := range emoji {
+		e.Offset += shift
+		relocated = append(relocated, e)
+	}
+	return relocated, index + len(text)
+}
+
+// slice returns the custom emoji lying in the UTF-16 range [start, start+length), with offsets
+// relative to start.
+func slice(emoji []models.CustomEmoji, start, length int) []models.CustomEmoji {
+	var sliced []models.CustomEmoji
+	for _, e := range emoji {
+		if e.Offset >= start && e.Offset+e.Length <= start+length {
+			e.Offset -= start
+			sliced = append(sliced, e)
+		}
+	}
+	return sliced
+}
+
+// utf16Len returns the length of s in UTF-16 code units, the unit of entity offsets.
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
@@ -0,0 +1,57 @@
+package customemoji
+
+import (
+	"testing"
+	"vrcmemes-bot/internal/database/models"
+
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromEntitiesKeepsOnlyCustomEmoji(t *testing.T) {
+	entities := []telego.MessageEntity{
+		{Type: telego.EntityTypeBold, Offset: 0, Length: 4},
+		{Type: telego.EntityTypeCustomEmoji, Offset: 5, Length: 2, CustomEmojiID: "42"},
+	}
+
+	emoji := FromEntities(entities)
+
+	assert.Equal(t, []models.CustomEmoji{{Offset: 5, Length: 2, ID: "42"}}, emoji)
+	assert.Equal(t, entities[1:], Entities(emoji))
+}
+
+func TestRelocateShiftsByUTF16Length(t *testing.T) {
+	// "🔥" takes two UTF-16 code units
+	emoji := []models.CustomEmoji{{Offset: 4, Length: 2, ID: "1"}}
+
+	assert.Equal(t, []models.CustomEmoji{{Offset: 11, Length: 2, ID: "1"}}, Relocate(emoji, "meh 😎", "#5 🔥\n\nmeh 😎"))
+	assert.Empty(t, Relocate(emoji, "meh 😎", "other caption"))
+}
+
+func TestWithinKeepsEmojiOfPart(t *testing.T) {
+	text := "/style add fire 🔥 {caption} 🔥"
+	emoji := FromEntities([]telego.MessageEntity{
+		{Type: telego.EntityTypeCustomEmoji, Offset: 16, Length: 2, CustomEmojiID: "1"},
+		{Type: telego.EntityTypeCustomEmoji, Offset: 29, Length: 2, CustomEmojiID: "2"},
+	})
+
+	assert.Equal(t, []models.CustomEmoji{
+		{Offset: 0, Length: 2, ID: "1"},
+		{Offset: 13, Length: 2, ID: "2"},
+	}, Within(emoji, text, "🔥 {caption} 🔥"))
+}
+
+func TestStyledPlacesTemplateEmojiAroundCaption(t *testing.T) {
+	style := models.CaptionStyle{
+		Name:     "fire",
+		Template: "🔥 {caption} 🔥",
+		Emoji:    []models.CustomEmoji{{Offset: 0, Length: 2, ID: "1"}, {Offset: 13, Length: 2, ID: "2"}},
+	}
+	caption := style.Apply("🔥 hot")
+
+	assert.Equal(t, []models.CustomEmoji{
+		{Offset: 0, Length: 2, ID: "1"},
+		{Offset: 10, Length: 2, ID: "2"},
+	}, Styled(style, caption))
+	assert.Empty(t, Styled(style, ""))
+}
@@ -0,0 +1,246 @@
+// Package dashboard keeps a pinned status message in the admin chat up to date: the review
+// queue, today's posts, the next publication, recent errors and the bot's uptime. The message is
+// edited in place, at most once per interval, so operators see the bot's state at a glance
+// without running commands.
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/errbudget"
+	"vrcmemes-bot/internal/format"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/publisher"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// DefaultInterval is the minimum pause between two edits of the dashboard.
+	DefaultInterval = 5 * time.Minute
+	// checkInterval is how often the dashboard is rendered to see whether it is due for an edit.
+	checkInterval = time.Minute
+	// loadTimeout bounds loading the figures of one update.
+	loadTimeout = 10 * time.Second
+)
+
+// errorSources are the error budget sources summed up on the dashboard.
+var errorSources = []errbudget.Source{errbudget.SourceTelegram, errbudget.SourceDatabase, errbudget.SourcePanic}
+
+// Updater edits the dashboard message in place. Its ID is stored in the settings, so the same
+// message keeps being edited after restarts.
+type Updater struct {
+	bot         telegoapi.BotAPI
+	chatID      int64
+	channelID   int64
+	settings    database.SettingsRepository
+	suggestions database.SuggestionRepository
+	stats       database.StatsRepository
+	queue       *publisher.Queue
+	errors      *errbudget.Tracker
+	location    *time.Location
+	interval    time.Duration
+	startedAt   time.Time
+
+	mu        sync.Mutex // Serializes updates
+	messageID int        // Zero until the dashboard message is known
+	lastText  string
+	lastEdit  time.Time
+}
+
+// NewUpdater creates the updater of the dashboard in chatID for the channel. Times are shown in
+// location, and a non-positive interval uses DefaultInterval. Uptime counts from its creation.
+func NewUpdater(bot telegoapi.BotAPI, chatID, channelID int64, settings database.SettingsRepository,
+	suggestions database.SuggestionRepository, stats database.StatsRepository, queue *publisher.Queue,
+	tracker *errbudget.Tracker, location *time.Location, interval time.Duration) *Updater {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	return &Updater{
+		bot:         bot,
+		chatID:      chatID,
+		channelID:   channelID,
+		settings:    settings,
+		suggestions: suggestions,
+		stats:       stats,
+		queue:       queue,
+		errors:      tracker,
+		location:    location,
+		interval:    interval,
+		startedAt:   time.Now(),
+	}
+}
+
+// Start updates the dashboard right away, then keeps it up to date until ctx is done.
+func (u *Updater) Start(ctx context.Context) {
+	go func() {
+		if err := u.Update(ctx, time.Now()); err != nil {
+			log.Printf("[Dashboard] %v", err)
+		}
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if err := u.Update(ctx, now); err != nil {
+					log.Printf("[Dashboard] %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Update renders the dashboard at now and edits the message if its text changed and the last
+// edit is at least an interval ago. Without a dashboard message, a new one is sent and pinned.
+func (u *Updater) Update(ctx context.Context, now time.Time) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.messageID == 0 {
+		u.messageID = u.storedMessageID(ctx)
+	}
+	if u.messageID != 0 && now.Sub(u.lastEdit) < u.interval {
+		return nil
+	}
+
+	loadCtx, cancel := context.WithTimeout(ctx, loadTimeout)
+	defer cancel()
+	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+	text := u.render(loadCtx, localizer, now)
+	if u.messageID == 0 {
+		return u.sendDashboard(ctx, text, now)
+	}
+	if text == u.lastText {
+		return nil
+	}
+
+	_, err := u.bot.EditMessageText(ctx, &telego.EditMessageTextParams{
+		ChatID:    tu.ID(u.chatID),
+		MessageID: u.messageID,
+		Text:      text,
+	})
+	switch {
+	case err == nil, telegoapi.IsMessageNotModified(err):
+	case telegoapi.IsMessageNotFound(err):
+		// The dashboard was deleted; the next update sends a new one
+		log.Printf("[Dashboard] Message %d in chat %d is gone, sending a new one next time", u.messageID, u.chatID)
+		u.messageID = 0
+		if err := u.settings.DeleteSetting(ctx, models.SettingDashboardMessage); err != nil {
+			return fmt.Errorf("failed to forget deleted dashboard message: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("failed to edit dashboard message %d: %w", u.messageID, err)
+	}
+	u.lastText, u.lastEdit = text, now
+	return nil
+}
+
+// sendDashboard sends a new dashboard message, pins it and stores its ID.
+func (u *Updater) sendDashboard(ctx context.Context, text string, now time.Time) error {
+	sent, err := u.bot.SendMessage(ctx, tu.Message(tu.ID(u.chatID), text).WithDisableNotification())
+	if err != nil {
+		return fmt.Errorf("failed to send dashboard message to chat %d: %w", u.chatID, err)
+	}
+	u.messageID, u.lastText, u.lastEdit = sent.MessageID, text, now
+	log.Printf("[Dashboard] Sent dashboard message %d to chat %d", sent.MessageID, u.chatID)
+
+	err = u.bot.PinChatMessage(ctx, &telego.PinChatMessageParams{
+		ChatID:              tu.ID(u.chatID),
+		MessageID:           sent.MessageID,
+		DisableNotification: true,
+	})
+	if err != nil {
+		log.Printf("[Dashboard] Error pinning dashboard message %d: %v", sent.MessageID, err)
+	}
+	value := strconv.FormatInt(u.chatID, 10) + ":" + strconv.Itoa(sent.MessageID)
+	if err := u.settings.SetSetting(ctx, models.SettingDashboardMessage, value, 0); err != nil {
+		log.Printf("[Dashboard] Error storing dashboard message ID: %v", err)
+	}
+	return nil
+}
+
+// storedMessageID returns the ID of the dashboard message stored for the chat, or 0 if there is
+// none, e.g. because the dashboard moved to another chat.
+func (u *Updater) storedMessageID(ctx context.Context) int {
+	setting, err := u.settings.GetSetting(ctx, models.SettingDashboardMessage)
+	if err != nil {
+		log.Printf("[Dashboard] Error loading dashboard message ID: %v", err)
+		return 0
+	}
+	if setting == nil {
+		return 0
+	}
+	chat, message, ok := strings.Cut(setting.Value, ":")
+	if !ok || chat != strconv.FormatInt(u.chatID, 10) {
+		return 0
+	}
+	id, err := strconv.Atoi(message)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// render builds the dashboard text at now. Figures that can't be loaded are shown as unknown.
+func (u *Updater) render(ctx context.Context, localizer *i18n.Localizer, now time.Time) string {
+	unknown := locales.GetMessage(localizer, "MsgDashboardUnknown", nil, nil)
+
+	pending := unknown
+	if _, total, err := u.suggestions.GetPendingSuggestions(ctx, 1, 0); err != nil {
+		log.Printf("[Dashboard] Error counting pending suggestions: %v", err)
+	} else {
+		pending = format.Number(localizer, total)
+	}
+
+	postsToday := unknown
+	year, month, day := now.In(u.location).Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, u.location)
+	if origins, err := u.stats.CountPostOrigins(ctx, u.channelID, midnight); err != nil {
+		log.Printf("[Dashboard] Error counting today's posts: %v", err)
+	} else {
+		var total int64
+		for _, origin := range origins {
+			total += origin.Count
+		}
+		postsToday = format.Number(localizer, total)
+	}
+
+	next := locales.GetMessage(localizer, "MsgDashboardNoNextPost", nil, nil)
+	if at := u.queue.NextAt(); !at.IsZero() {
+		next = locales.GetMessage(localizer, "MsgDashboardNextPost", map[string]interface{}{
+			"Time":   at.In(u.location).Format("15:04"),
+			"Queued": u.queue.Pending(),
+		}, nil)
+	}
+
+	errorCount := 0
+	for _, source := range errorSources {
+		errorCount += u.errors.Count(source)
+	}
+
+	return locales.GetMessage(localizer, "MsgDashboard", map[string]interface{}{
+		"Pending":     pending,
+		"PostsToday":  postsToday,
+		"Next":        next,
+		"Errors":      errorCount,
+		"ErrorWindow": format.Duration(localizer, u.errors.Window()),
+		"Uptime":      format.Duration(localizer, now.Sub(u.startedAt)),
+		"Updated":     now.In(u.location).Format("15:04"),
+	}, nil)
+}
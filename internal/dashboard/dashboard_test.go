@@ -0,0 +1,110 @@
+package dashboard
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/errbudget"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/publisher"
+	"vrcmemes-bot/pkg/telegoapi/telegoapitest"
+
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const adminChatID = -1001
+
+// memorySettingsRepo is an in-memory SettingsRepository.
+type memorySettingsRepo map[string]string
+
+func (r memorySettingsRepo) GetSetting(ctx context.Context, key string) (*models.Setting, error) {
+	value, ok := r[key]
+	if !ok {
+		return nil, nil
+	}
+	return &models.Setting{Key: key, Value: value}, nil
+}
+
+func (r memorySettingsRepo) SetSetting(ctx context.Context, key, value string, updatedBy int64) error {
+	r[key] = value
+	return nil
+}
+
+func (r memorySettingsRepo) DeleteSetting(ctx context.Context, key string) error {
+	delete(r, key)
+	return nil
+}
+
+// pendingCounter reports a fixed number of pending suggestions.
+type pendingCounter struct {
+	database.SuggestionRepository
+	pending int64
+}
+
+func (c *pendingCounter) GetPendingSuggestions(ctx context.Context, limit int, offset int) ([]models.Suggestion, int64, error) {
+	return nil, c.pending, nil
+}
+
+// originCounter reports fixed post counts per origin.
+type originCounter struct {
+	database.StatsRepository
+	origins []models.OriginCount
+}
+
+func (c originCounter) CountPostOrigins(ctx context.Context, channelID int64, since time.Time) ([]models.OriginCount, error) {
+	return c.origins, nil
+}
+
+func TestUpdaterEditsPinnedDashboardInPlace(t *testing.T) {
+	locales.Init("en")
+	ctx := context.Background()
+	bot := telegoapitest.NewFakeBot()
+	settings := memorySettingsRepo{}
+	suggestions := &pendingCounter{pending: 4}
+	stats := originCounter{origins: []models.OriginCount{{Origin: models.PostOriginSuggestion, Count: 2}, {Origin: models.PostOriginDirect, Count: 1}}}
+	tracker := errbudget.NewTracker(10*time.Minute, time.Hour)
+	newUpdater := func() *Updater {
+		return NewUpdater(bot, adminChatID, -100, settings, suggestions, stats, publisher.NewQueue(time.Second), tracker, time.UTC, 5*time.Minute)
+	}
+	updater := newUpdater()
+	start := time.Now()
+
+	// The first update sends and pins the dashboard
+	require.NoError(t, updater.Update(ctx, start))
+	sent := bot.CallsTo("SendMessage", adminChatID)
+	require.Len(t, sent, 1)
+	text := sent[0].Params.(*telego.SendMessageParams).Text
+	assert.Contains(t, text, "Suggestions waiting for review: 4")
+	assert.Contains(t, text, "Posts today: 3")
+	assert.Contains(t, text, "Next publication: nothing queued")
+	require.Len(t, bot.CallsTo("PinChatMessage", adminChatID), 1)
+	messageID := bot.CallsTo("PinChatMessage", adminChatID)[0].Params.(*telego.PinChatMessageParams).MessageID
+
+	// Changes are throttled to one edit per interval
+	suggestions.pending = 5
+	require.NoError(t, updater.Update(ctx, start.Add(time.Minute)))
+	assert.Empty(t, bot.CallsTo("EditMessageText", adminChatID))
+	require.NoError(t, updater.Update(ctx, start.Add(5*time.Minute)))
+	edits := bot.CallsTo("EditMessageText", adminChatID)
+	require.Len(t, edits, 1)
+	assert.Equal(t, messageID, edits[0].Params.(*telego.EditMessageTextParams).MessageID)
+	assert.Contains(t, edits[0].Params.(*telego.EditMessageTextParams).Text, "Suggestions waiting for review: 5")
+
+	// After a restart the stored message is edited instead of sending another one
+	require.NoError(t, newUpdater().Update(ctx, start.Add(6*time.Minute)))
+	assert.Len(t, bot.CallsTo("SendMessage", adminChatID), 1)
+	assert.Len(t, bot.CallsTo("EditMessageText", adminChatID), 2)
+
+	// A deleted dashboard is replaced by a new pinned one
+	updater = newUpdater()
+	bot.FailNext("EditMessageText", telegoapitest.APIError(http.StatusBadRequest, "Bad Request: message to edit not found", 0))
+	require.NoError(t, updater.Update(ctx, start.Add(7*time.Minute)))
+	require.NoError(t, updater.Update(ctx, start.Add(8*time.Minute)))
+	assert.Len(t, bot.CallsTo("SendMessage", adminChatID), 2)
+	assert.Len(t, bot.CallsTo("PinChatMessage", adminChatID), 2)
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ChannelScope restricts a repository to the documents of one channel in multi-tenant mode,
+// matched by their channel_id field, and stamps the channel on the documents it stores.
+// The zero value doesn't restrict anything.
+type ChannelScope struct {
+	channelID int64
+	filter    interface{} // Value matched against channel_id; nil matches all documents
+	keyPrefix string      // Prepended to keys chosen by callers, such as names, for connected channels
+}
+
+// NewChannelScope returns the scope of a channel. With includeUnscoped, documents stored without
+// a channel, before multi-tenant mode was enabled, are in scope too, and keys aren't prefixed;
+// this is meant for the primary channel.
+func NewChannelScope(channelID int64, includeUnscoped bool) ChannelScope {
+	if includeUnscoped {
+		return ChannelScope{channelID: channelID, filter: bson.M{"$in": bson.A{channelID, nil}}}
+	}
+	return ChannelScope{channelID: channelID, filter: channelID, keyPrefix: fmt.Sprintf("channel:%d:", channelID)}
+}
+
+// ChannelID returns the channel of the scope, or zero for the zero value.
+func (s ChannelScope) ChannelID() int64 {
+	return s.channelID
+}
+
+// apply adds the channel restriction to a filter and returns the filter.
+func (s ChannelScope) apply(filter bson.M) bson.M {
+	if s.filter != nil {
+		filter["channel_id"] = s.filter
+	}
+	return filter
+}
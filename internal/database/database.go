@@ -19,6 +19,44 @@ import (
 // ErrSuggestionNotFound is returned when a suggestion is not found.
 var ErrSuggestionNotFound = errors.New("suggestion not found")
 
+// ErrSuggestionNotEditable is returned when a suggestion can't be edited by its suggester
+// (it doesn't exist, belongs to someone else, is no longer pending, or is full).
+var ErrSuggestionNotEditable = errors.New("suggestion is not editable")
+
+// ErrRefCodeTaken is returned when a new suggestion's reference code is already used by another one.
+var ErrRefCodeTaken = errors.New("reference code already taken")
+
+// ErrFeedbackNotFound is returned when a feedback entry is not found.
+var ErrFeedbackNotFound = errors.New("feedback not found")
+
+// ErrRubricNotFound is returned when a rubric is not found.
+var ErrRubricNotFound = errors.New("rubric not found")
+
+// ErrRubricExists is returned when a rubric with the same name already exists.
+var ErrRubricExists = errors.New("rubric already exists")
+
+// ErrTenantExists is returned when a channel is already connected to the bot.
+var ErrTenantExists = errors.New("channel already connected")
+
+// ErrTenantNotFound is returned when a channel is not connected to the bot.
+var ErrTenantNotFound = errors.New("channel not connected")
+
+// ErrSuggestionQuotaExceeded is returned when a connected channel has as many pending
+// suggestions as its quota allows.
+var ErrSuggestionQuotaExceeded = errors.New("suggestion quota exceeded")
+
+// ErrPostNotFound is returned when a channel post was not logged by the bot.
+var ErrPostNotFound = errors.New("post not found")
+
+// ErrPinNotFound is returned when a post has no pending or active scheduled pin.
+var ErrPinNotFound = errors.New("scheduled pin not found")
+
+// ErrPermissionNotFound is returned when a command was not granted to a user or role.
+var ErrPermissionNotFound = errors.New("permission not found")
+
+// ErrFilteredWordNotFound is returned when a word isn't filtered.
+var ErrFilteredWordNotFound = errors.New("filtered word not found")
+
 func LogUserAction(userID int64, actionType string, details map[string]interface{}) error {
 	// Implementation of LogUserAction function
 	return nil
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// experimentRepository is a MongoDB implementation of ExperimentRepository.
+type experimentRepository struct {
+	experiments *mongo.Collection
+	postLogs    *mongo.Collection
+	scope       ChannelScope // Experiments of one channel in multi-tenant mode; the zero value sees all
+}
+
+// NewExperimentRepository creates a new instance of experimentRepository.
+func NewExperimentRepository(db *mongo.Database) ExperimentRepository {
+	return &experimentRepository{
+		experiments: db.Collection("experiments"),
+		postLogs:    db.Collection("post_logs"),
+	}
+}
+
+// NewChannelExperimentRepository creates an experiment repository that only measures and reports
+// the experiments of one channel, for multi-tenant mode.
+func NewChannelExperimentRepository(db *mongo.Database, scope ChannelScope) ExperimentRepository {
+	return &experimentRepository{
+		experiments: db.Collection("experiments"),
+		postLogs:    db.Collection("post_logs"),
+		scope:       scope,
+	}
+}
+
+// CreateExperiment stores a caption experiment whose variant A was published.
+func (r *experimentRepository) CreateExperiment(ctx context.Context, experiment *models.CaptionExperiment) error {
+	if experiment.PublishedAt.IsZero() {
+		experiment.PublishedAt = time.Now()
+	}
+	if _, err := r.experiments.InsertOne(ctx, experiment); err != nil {
+		return fmt.Errorf("failed to create caption experiment for post %d: %w", experiment.ChannelPostID, err)
+	}
+	return nil
+}
+
+// MeasureExperiments records the reactions of the posts of unmeasured experiments published
+// before the given time, taken from the post log, and returns how many were measured.
+func (r *experimentRepository) MeasureExperiments(ctx context.Context, publishedBefore time.Time) (int, error) {
+	cursor, err := r.experiments.Find(ctx, r.scope.apply(bson.M{
+		"measured_at":  bson.M{"$exists": false},
+		"published_at": bson.M{"$lt": publishedBefore},
+	}))
+	if err != nil {
+		return 0, fmt.Errorf("failed to find experiments to measure: %w", err)
+	}
+	var due []models.CaptionExperiment
+	if err := cursor.All(ctx, &due); err != nil {
+		return 0, fmt.Errorf("failed to decode experiments to measure: %w", err)
+	}
+
+	measured := 0
+	for _, experiment := range due {
+		var post models.PostLog
+		err := r.postLogs.FindOne(ctx, bson.M{
+			"channel_id":      experiment.ChannelID,
+			"channel_post_id": experiment.ChannelPostID,
+		}).Decode(&post)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return measured, fmt.Errorf("failed to get post %d of experiment %s: %w", experiment.ChannelPostID, experiment.ID.Hex(), err)
+		}
+		now := time.Now()
+		update := bson.M{"$set": bson.M{"measured_at": now, "reactions": post.ReactionCount}}
+		if _, err := r.experiments.UpdateByID(ctx, experiment.ID, update); err != nil {
+			return measured, fmt.Errorf("failed to save measurement of experiment %s: %w", experiment.ID.Hex(), err)
+		}
+		measured++
+	}
+	return measured, nil
+}
+
+// CaptionFamilyStats averages the reactions of measured experiments by the family of the
+// published caption, best first.
+func (r *experimentRepository) CaptionFamilyStats(ctx context.Context) ([]models.CaptionFamilyStat, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: r.scope.apply(bson.M{"measured_at": bson.M{"$exists": true}})}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           "$variant_a.family",
+			"posts":         bson.M{"$sum": 1},
+			"avg_reactions": bson.M{"$avg": "$reactions"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "avg_reactions", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+	cursor, err := r.experiments.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute caption family stats: %w", err)
+	}
+	var stats []models.CaptionFamilyStat
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, fmt.Errorf("failed to decode caption family stats: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ExportCollection writes every document of a collection to w as a line of relaxed
+// extended JSON, the format mongoimport reads. It returns how many documents were written.
+func ExportCollection(ctx context.Context, db *mongo.Database, collection string, w io.Writer) (int, error) {
+	cursor, err := db.Collection(collection).Find(ctx, bson.D{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", collection, err)
+	}
+	defer cursor.Close(ctx)
+
+	out := bufio.NewWriter(w)
+	count := 0
+	for cursor.Next(ctx) {
+		line, err := bson.MarshalExtJSON(cursor.Current, false, false)
+		if err != nil {
+			return count, fmt.Errorf("failed to encode document of %s: %w", collection, err)
+		}
+		if _, err := out.Write(append(line, '\n')); err != nil {
+			return count, fmt.Errorf("failed to write %s: %w", collection, err)
+		}
+		count++
+	}
+	if err := cursor.Err(); err != nil {
+		return count, fmt.Errorf("failed to read %s: %w", collection, err)
+	}
+	if err := out.Flush(); err != nil {
+		return count, fmt.Errorf("failed to write %s: %w", collection, err)
+	}
+	return count, nil
+}
@@ -7,13 +7,16 @@ import (
 	"time" // Needed for SubmittedAt
 	"vrcmemes-bot/internal/database/models"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive" // Needed for ObjectID
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // feedbackRepository is a MongoDB implementation of FeedbackRepository.
 type feedbackRepository struct {
 	collection *mongo.Collection
+	scope      ChannelScope // Feedback of one channel in multi-tenant mode; the zero value sees all
 }
 
 // NewFeedbackRepository creates a new instance of feedbackRepository.
@@ -25,6 +28,15 @@ func NewFeedbackRepository(db *mongo.Database) FeedbackRepository { // Return in
 	}
 }
 
+// NewChannelFeedbackRepository creates a feedback repository that only sees and stores the
+// feedback sent about one channel, for multi-tenant mode.
+func NewChannelFeedbackRepository(db *mongo.Database, scope ChannelScope) FeedbackRepository {
+	return &feedbackRepository{
+		collection: db.Collection("feedback"),
+		scope:      scope,
+	}
+}
+
 // AddFeedback saves a new feedback entry to the MongoDB collection.
 func (r *feedbackRepository) AddFeedback(ctx context.Context, feedback *models.Feedback) error {
 	// Generate ObjectID if it's not set
@@ -35,6 +47,12 @@ func (r *feedbackRepository) AddFeedback(ctx context.Context, feedback *models.F
 	if feedback.SubmittedAt.IsZero() {
 		feedback.SubmittedAt = time.Now()
 	}
+	if feedback.Status == "" {
+		feedback.Status = models.FeedbackStatusNew
+	}
+	if feedback.ChannelID == 0 {
+		feedback.ChannelID = r.scope.ChannelID()
+	}
 
 	_, err := r.collection.InsertOne(ctx, feedback)
 	if err != nil {
@@ -45,3 +63,83 @@ func (r *feedbackRepository) AddFeedback(ctx context.Context, feedback *models.F
 	log.Printf("Successfully inserted feedback (ID: %s) from user %d.", feedback.ID.Hex(), feedback.UserID)
 	return nil
 }
+
+// GetFeedbackByID retrieves a single feedback entry by its MongoDB ObjectID.
+// It returns ErrFeedbackNotFound if no feedback matches the ID.
+func (r *feedbackRepository) GetFeedbackByID(ctx context.Context, id primitive.ObjectID) (*models.Feedback, error) {
+	var feedback models.Feedback
+	err := r.collection.FindOne(ctx, r.scope.apply(bson.M{"_id": id})).Decode(&feedback)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrFeedbackNotFound
+		}
+		return nil, fmt.Errorf("failed to find feedback by ID %s: %w", id.Hex(), err)
+	}
+	return &feedback, nil
+}
+
+// SetFeedbackStatus changes the triage status of feedback, recording the admin who changed it.
+// It returns false without changes if the feedback is missing or already has the status.
+func (r *feedbackRepository) SetFeedbackStatus(ctx context.Context, id primitive.ObjectID, status string, adminID int64) (bool, error) {
+	filter := bson.M{"_id": id, "status": bson.M{"$ne": status}}
+	if status == models.FeedbackStatusNew {
+		// Feedback stored without a status is already new
+		filter["status"] = bson.M{"$nin": bson.A{status, nil}}
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":            status,
+			"status_changed_by": adminID,
+			"status_changed_at": time.Now(),
+		},
+	}
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), update)
+	if err != nil {
+		return false, fmt.Errorf("failed to set status of feedback %s: %w", id.Hex(), err)
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// SetFeedbackContactOptIn records whether the author of feedback agreed to be contacted about it.
+// It returns ErrFeedbackNotFound if the feedback is missing or wasn't sent by userID.
+func (r *feedbackRepository) SetFeedbackContactOptIn(ctx context.Context, id primitive.ObjectID, userID int64, optIn bool) error {
+	filter := bson.M{"_id": id, "user_id": userID}
+	update := bson.M{"$set": bson.M{"contact_opt_in": optIn}}
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), update)
+	if err != nil {
+		return fmt.Errorf("failed to set contact opt-in of feedback %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrFeedbackNotFound
+	}
+	return nil
+}
+
+// ListFeedback returns the most recent feedback with one of the given statuses, newest first.
+// Feedback stored without a status matches FeedbackStatusNew.
+func (r *feedbackRepository) ListFeedback(ctx context.Context, statuses []string, limit int) ([]models.Feedback, error) {
+	filter := bson.M{}
+	if len(statuses) > 0 {
+		values := bson.A{}
+		for _, status := range statuses {
+			values = append(values, status)
+			if status == models.FeedbackStatusNew {
+				values = append(values, nil) // Matches a missing status field as well
+			}
+		}
+		filter["status"] = bson.M{"$in": values}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "submitted_at", Value: -1}}).SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, r.scope.apply(filter), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feedback: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var feedback []models.Feedback
+	if err := cursor.All(ctx, &feedback); err != nil {
+		return nil, fmt.Errorf("failed to decode feedback list: %w", err)
+	}
+	return feedback, nil
+}
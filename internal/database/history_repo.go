@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"vrcmemes-bot/internal/database/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// historyRepository is a MongoDB implementation of HistoryRepository.
+type historyRepository struct {
+	postLogs *mongo.Collection
+}
+
+// NewHistoryRepository creates a new instance of historyRepository.
+func NewHistoryRepository(db *mongo.Database) HistoryRepository {
+	return &historyRepository{
+		postLogs: db.Collection("post_logs"),
+	}
+}
+
+// ImportPostLogs inserts the post logs of posts that aren't logged yet, matching them by
+// channel and channel post ID, and returns how many were inserted. Existing logs are kept
+// as they are, since they know more about the post than an import.
+func (r *historyRepository) ImportPostLogs(ctx context.Context, logs []models.PostLog) (int, error) {
+	if len(logs) == 0 {
+		return 0, nil
+	}
+	writes := make([]mongo.WriteModel, len(logs))
+	for i, entry := range logs {
+		writes[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"channel_id": entry.ChannelID, "channel_post_id": entry.ChannelPostID}).
+			SetUpdate(bson.M{"$setOnInsert": entry}).
+			SetUpsert(true)
+	}
+	result, err := r.postLogs.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return 0, fmt.Errorf("failed to import %d post logs: %w", len(logs), err)
+	}
+	return int(result.UpsertedCount), nil
+}
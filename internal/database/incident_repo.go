@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// incidentCollectionName is the collection of recorded incidents.
+const incidentCollectionName = "incidents"
+
+// incidentRepository is a MongoDB implementation of IncidentRepository.
+type incidentRepository struct {
+	collection *mongo.Collection
+}
+
+// NewIncidentRepository creates a new instance of incidentRepository.
+func NewIncidentRepository(db *mongo.Database) IncidentRepository {
+	return &incidentRepository{
+		collection: db.Collection(incidentCollectionName),
+	}
+}
+
+// LogIncident stores an incident. Incidents without a status are stored as open.
+func (r *incidentRepository) LogIncident(ctx context.Context, incident *models.Incident) error {
+	if incident.Status == "" {
+		incident.Status = models.IncidentStatusOpen
+	}
+	if _, err := r.collection.InsertOne(ctx, incident); err != nil {
+		return fmt.Errorf("failed to log incident %s: %w", incident.ID, err)
+	}
+	return nil
+}
+
+// GetIncident returns an incident by ID, or nil if there is none.
+func (r *incidentRepository) GetIncident(ctx context.Context, id string) (*models.Incident, error) {
+	var incident models.Incident
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&incident)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get incident %s: %w", id, err)
+	}
+	return &incident, nil
+}
+
+// RecentIncidents returns the latest incidents, newest first.
+func (r *incidentRepository) RecentIncidents(ctx context.Context, limit int, includeResolved bool) ([]models.Incident, error) {
+	filter := bson.M{}
+	if !includeResolved {
+		filter["status"] = bson.M{"$ne": models.IncidentStatusResolved}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "occurred_at", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find recent incidents: %w", err)
+	}
+	var incidents []models.Incident
+	if err := cursor.All(ctx, &incidents); err != nil {
+		return nil, fmt.Errorf("failed to decode recent incidents: %w", err)
+	}
+	return incidents, nil
+}
+
+// SetIncidentStatus changes the status of an incident and returns the updated incident,
+// or nil if there is none.
+func (r *incidentRepository) SetIncidentStatus(ctx context.Context, id, status string, changedBy int64) (*models.Incident, error) {
+	update := bson.M{"$set": bson.M{
+		"status":            status,
+		"status_changed_by": changedBy,
+		"status_changed_at": time.Now(),
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var incident models.Incident
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&incident)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set status of incident %s: %w", id, err)
+	}
+	return &incident, nil
+}
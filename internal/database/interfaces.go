@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"time"
 	"vrcmemes-bot/internal/database/models"
 	telegoapi "vrcmemes-bot/pkg/telegoapi" // Import telegoapi for BotAPI
 
@@ -23,19 +24,116 @@ type UserActionLogger interface {
 
 // UserRepository defines the interface for user data operations.
 type UserRepository interface {
-	// UpdateUser updates or creates a user record in the database.
-	UpdateUser(ctx context.Context, userID int64, username, firstName, lastName string, isAdmin bool, action string) error
+	// UpdateUser updates or creates a user record from the user's Telegram profile and records the action as their last one.
+	UpdateUser(ctx context.Context, user telego.User, isAdmin bool, action string) error
+	// GetUser returns the stored profile of a user, or nil if the user is unknown.
+	GetUser(ctx context.Context, userID int64) (*models.User, error)
+	// GetUserByUsername returns the stored profile of the user with the username, without the @,
+	// ignoring case, or nil if no known user has it.
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	// IncrementUserCounter increments one of the models.UserCounter* counters of a user.
+	IncrementUserCounter(ctx context.Context, userID int64, counter string) error
+	// SetUserConsent records when the user agreed to the privacy notice.
+	SetUserConsent(ctx context.Context, userID int64, at time.Time) error
+	// GetUserLanguage returns the user's saved language preference, or an empty string if none is saved.
+	GetUserLanguage(ctx context.Context, userID int64) (string, error)
+	// SetUserLanguage saves the user's language preference.
+	SetUserLanguage(ctx context.Context, userID int64, lang string) error
+	// GetUserFirstSeen returns when the bot first saw the user, or the zero time if the user is unknown.
+	GetUserFirstSeen(ctx context.Context, userID int64) (time.Time, error)
+	// SetNotificationMuted turns one of the models.NotificationCategories off (muted) or back on for a user.
+	SetNotificationMuted(ctx context.Context, userID int64, category string, muted bool) error
+	// DeleteUser removes the stored profile and logged actions of a user.
+	DeleteUser(ctx context.Context, userID int64) error
 }
 
+// PendingOrder is the order GetPendingSuggestionsInOrder returns pending suggestions in.
+type PendingOrder int
+
+const (
+	PendingOldestFirst   PendingOrder = iota
+	PendingNewestFirst                // Most recently submitted first
+	PendingPriorityFirst              // Highest priority first, oldest first among equal priorities
+)
+
 // SuggestionRepository defines the interface for suggestion data operations.
 // Actual definition is likely in mongo_suggestion_repo.go or similar.
 type SuggestionRepository interface {
 	CreateSuggestion(ctx context.Context, suggestion *models.Suggestion) error
 	GetSuggestionByID(ctx context.Context, id primitive.ObjectID) (*models.Suggestion, error)
+	// GetSuggestionByRefCode finds a suggestion by its reference code.
+	GetSuggestionByRefCode(ctx context.Context, refCode string) (*models.Suggestion, error)
 	UpdateSuggestionStatus(ctx context.Context, id primitive.ObjectID, status string, adminID int64, adminUsername string) error
 	GetPendingSuggestions(ctx context.Context, limit int, offset int) ([]models.Suggestion, int64, error)
+	// GetPendingSuggestionsInOrder returns up to limit pending suggestions in the given order and the total pending.
+	GetPendingSuggestionsInOrder(ctx context.Context, limit int, order PendingOrder) ([]models.Suggestion, int64, error)
 	DeleteSuggestion(ctx context.Context, id primitive.ObjectID) error
+	// DeleteTestSuggestions removes the test suggestions of /simulate and returns how many there were.
+	DeleteTestSuggestions(ctx context.Context) (int64, error)
 	ResetDailyLimits(ctx context.Context) error
+	// GetSuggestionsBySuggester returns the most recent suggestions submitted by a user with the given status.
+	GetSuggestionsBySuggester(ctx context.Context, suggesterID int64, status string, limit int) ([]models.Suggestion, error)
+	// UpdateSuggestionCaption replaces the caption of a pending suggestion owned by suggesterID.
+	UpdateSuggestionCaption(ctx context.Context, id primitive.ObjectID, suggesterID int64, caption string) error
+	// AddSuggestionFile attaches another file to a pending suggestion owned by suggesterID, up to maxFiles.
+	AddSuggestionFile(ctx context.Context, id primitive.ObjectID, suggesterID int64, fileID string, maxFiles int) error
+	// SetSuggestionTags replaces the tags of a pending suggestion.
+	SetSuggestionTags(ctx context.Context, id primitive.ObjectID, tags []string) error
+	// SetSuggestionRubric sets the rubric a pending suggestion is published in; "" clears it.
+	SetSuggestionRubric(ctx context.Context, id primitive.ObjectID, rubric string) error
+	// GetAssignedPendingSuggestions returns up to limit pending suggestions assigned to an admin,
+	// oldest first, and how many are assigned to them in total.
+	GetAssignedPendingSuggestions(ctx context.Context, adminID int64, limit int) ([]models.Suggestion, int64, error)
+	// AssignSuggestion assigns a pending suggestion to an admin for review.
+	AssignSuggestion(ctx context.Context, id primitive.ObjectID, adminID int64) error
+	// GetStaleAssignments returns up to limit pending suggestions assigned before the given time.
+	GetStaleAssignments(ctx context.Context, assignedBefore time.Time, limit int) ([]models.Suggestion, error)
+	// SetSuggestionStyle sets the caption style a pending suggestion is published with; "" clears it.
+	SetSuggestionStyle(ctx context.Context, id primitive.ObjectID, style string) error
+	// SetSuggestionPreview records where an approved suggestion was posted in the preview channel.
+	SetSuggestionPreview(ctx context.Context, id primitive.ObjectID, chatID int64, messageIDs []int) error
+	// SetSuggestionPublished records the channel message an approved suggestion was published as.
+	SetSuggestionPublished(ctx context.Context, id primitive.ObjectID, messageID int) error
+	// SetSuggestionCredit stores whether the suggester of a pending suggestion wants to be credited.
+	SetSuggestionCredit(ctx context.Context, id primitive.ObjectID, suggesterID int64, credit bool) error
+	// MarkSuggestionDuplicate rejects a pending suggestion as a duplicate of another one.
+	MarkSuggestionDuplicate(ctx context.Context, id, duplicateOf primitive.ObjectID, reviewerID int64, reviewerUsername string) error
+	// ExpireSuggestions rejects up to limit pending suggestions submitted (or reopened) before the
+	// given time, oldest first and except the excluded ones, as expired. It returns the expired suggestions.
+	ExpireSuggestions(ctx context.Context, submittedBefore time.Time, exclude []primitive.ObjectID, limit int) ([]models.Suggestion, error)
+	// ReopenSuggestion puts a rejected suggestion back in the queue as pending, clearing its review.
+	// It returns ErrSuggestionNotFound if there is no rejected suggestion with the ID.
+	ReopenSuggestion(ctx context.Context, id primitive.ObjectID, adminID int64) error
+	// AddCoSuggesters records the suggesters of duplicates merged into a pending suggestion.
+	AddCoSuggesters(ctx context.Context, id primitive.ObjectID, coSuggesters []models.CoSuggester) error
+	// SetSuggestionOCR stores the text recognized in a suggestion, its key and the earlier
+	// suggestion with the same key, if any.
+	SetSuggestionOCR(ctx context.Context, id primitive.ObjectID, text, key, similarTo string) error
+	// FindSuggestionByOCRKey returns the most recent pending or approved suggestion other than
+	// exclude with the given OCR key, or ErrSuggestionNotFound.
+	FindSuggestionByOCRKey(ctx context.Context, key string, exclude primitive.ObjectID) (*models.Suggestion, error)
+	// SearchSuggestions returns up to limit suggestions, most recent first, whose caption or
+	// recognized text contains query, ignoring case.
+	SearchSuggestions(ctx context.Context, query string, limit int) ([]models.Suggestion, error)
+	// SetSuggestionProtectContent sets whether a pending suggestion is published with protected content.
+	// It returns ErrSuggestionNotFound if no such pending suggestion exists.
+	SetSuggestionProtectContent(ctx context.Context, id primitive.ObjectID, protect bool) error
+	// SetSuggestionVideoPolicyOverride sets whether a pending suggestion may be published although
+	// its videos are outside the video policy.
+	// It returns ErrSuggestionNotFound if no such pending suggestion exists.
+	SetSuggestionVideoPolicyOverride(ctx context.Context, id primitive.ObjectID, override bool) error
+	// SetSuggestionThumbnail sets the thumbnail a pending suggestion's video is published with;
+	// nil removes it. It returns ErrSuggestionNotFound if no such pending suggestion exists.
+	SetSuggestionThumbnail(ctx context.Context, id primitive.ObjectID, thumbnail *models.VideoThumbnail) error
+	// SetSuggestionTranslation stores the translation of a suggestion's caption and the detected
+	// language of the caption.
+	SetSuggestionTranslation(ctx context.Context, id primitive.ObjectID, language, text string) error
+	// SetSuggestionCaptionChoice sets which version of its translated caption a pending suggestion
+	// is published with, see models.CaptionChoiceOriginal; empty publishes neither.
+	// It returns ErrSuggestionNotFound if no such pending suggestion exists.
+	SetSuggestionCaptionChoice(ctx context.Context, id primitive.ObjectID, choice string) error
+	// AddSuggestionComment appends a reviewer question or suggester answer to a pending suggestion.
+	AddSuggestionComment(ctx context.Context, id primitive.ObjectID, comment models.SuggestionComment) error
 	// Add other methods as needed
 }
 
@@ -62,6 +160,211 @@ type HandlerProvider interface {
 // FeedbackRepository defines the interface for feedback data operations.
 type FeedbackRepository interface {
 	AddFeedback(ctx context.Context, feedback *models.Feedback) error
+	// GetFeedbackByID returns a feedback entry, or ErrFeedbackNotFound.
+	GetFeedbackByID(ctx context.Context, id primitive.ObjectID) (*models.Feedback, error)
+	// SetFeedbackStatus changes the triage status of feedback and returns false if it already had that status.
+	SetFeedbackStatus(ctx context.Context, id primitive.ObjectID, status string, adminID int64) (bool, error)
+	// SetFeedbackContactOptIn records whether the author of feedback wants a reply, or returns ErrFeedbackNotFound
+	// if userID didn't send it.
+	SetFeedbackContactOptIn(ctx context.Context, id primitive.ObjectID, userID int64, optIn bool) error
+	// ListFeedback returns the most recent feedback with one of the given statuses (any status if none are given).
+	ListFeedback(ctx context.Context, statuses []string, limit int) ([]models.Feedback, error)
+}
+
+// MembershipRepository defines the interface for channel membership tracking.
+type MembershipRepository interface {
+	// SaveMemberStatus upserts the current membership status of a user in a chat.
+	SaveMemberStatus(ctx context.Context, member models.ChannelMember) error
+	// GetMemberStatus returns the stored membership of a user in a chat, or nil if it is unknown.
+	GetMemberStatus(ctx context.Context, chatID, userID int64) (*models.ChannelMember, error)
+	// RecordMembershipEvent stores a join or leave event for growth analytics.
+	RecordMembershipEvent(ctx context.Context, event *models.MembershipEvent) error
+	// GetMembershipGrowth counts joins and leaves in a chat since the given time.
+	GetMembershipGrowth(ctx context.Context, chatID int64, since time.Time) (joins, leaves int64, err error)
+}
+
+// ProcessedUpdateStore persists the IDs of processed Telegram updates, so updates resent
+// after a restart are recognized as duplicates. An update is claimed while it is processed, so
+// one whose processing failed or never finished is processed again if resent.
+type ProcessedUpdateStore interface {
+	// ClaimUpdate claims an update ID for processing until the lease ends. It returns false if the
+	// update was already processed, or is claimed and the claim hasn't expired.
+	ClaimUpdate(ctx context.Context, updateID int, lease time.Duration) (bool, error)
+	// CompleteUpdate records a claimed update as processed.
+	CompleteUpdate(ctx context.Context, updateID int) error
+	// ReleaseUpdate drops the claim of an update whose processing failed.
+	ReleaseUpdate(ctx context.Context, updateID int) error
+	// EnsureRetention makes recorded update IDs expire after the given duration.
+	EnsureRetention(ctx context.Context, retention time.Duration) error
+}
+
+// PublicationStore records channel publications by idempotency key, so content that was
+// already published is not sent again on retry or after a restart.
+type PublicationStore interface {
+	// BeginPublication records a publication as pending and reports whether it was already published.
+	BeginPublication(ctx context.Context, key string) (alreadyPublished bool, err error)
+	// CompletePublication marks a publication as successfully published.
+	CompletePublication(ctx context.Context, key string) error
+	// FailPublication marks a publication as failed with the given cause.
+	FailPublication(ctx context.Context, key string, cause error) error
+}
+
+// IncidentRepository stores significant runtime failures, for /incidents.
+type IncidentRepository interface {
+	// LogIncident stores an incident. Incidents without a status are stored as open.
+	LogIncident(ctx context.Context, incident *models.Incident) error
+	// GetIncident returns an incident by ID, or nil if there is none.
+	GetIncident(ctx context.Context, id string) (*models.Incident, error)
+	// RecentIncidents returns the latest incidents, newest first, leaving out resolved ones unless asked.
+	RecentIncidents(ctx context.Context, limit int, includeResolved bool) ([]models.Incident, error)
+	// SetIncidentStatus changes the status of an incident and returns it, or nil if there is none.
+	SetIncidentStatus(ctx context.Context, id, status string, changedBy int64) (*models.Incident, error)
+}
+
+// OutboxRepository stores user notifications until they are delivered; see the outbox package.
+type OutboxRepository interface {
+	// EnqueueNotification stores a new pending notification, due right away.
+	EnqueueNotification(ctx context.Context, notification *models.Notification) error
+	// ClaimDueNotification claims the oldest due notification for delivery until the lease
+	// expires, counting the attempt. It returns nil if none is due.
+	ClaimDueNotification(ctx context.Context, now time.Time, lease time.Duration) (*models.Notification, error)
+	// CompleteNotification marks a notification as sent.
+	CompleteNotification(ctx context.Context, id primitive.ObjectID) error
+	// RetryNotification makes a notification pending again at the given time, recording the cause.
+	RetryNotification(ctx context.Context, id primitive.ObjectID, at time.Time, cause error) error
+	// FailNotification gives up on a notification, recording the cause.
+	FailNotification(ctx context.Context, id primitive.ObjectID, cause error) error
+	// CountNotifications counts the notifications with any of the given statuses.
+	CountNotifications(ctx context.Context, statuses ...string) (int64, error)
+}
+
+// StatsRepository computes analytics over stored content for /stats.
+type StatsRepository interface {
+	// CountSuggestionTags counts the tags of suggestions approved since the given time, most used first.
+	CountSuggestionTags(ctx context.Context, since time.Time) ([]models.TagCount, error)
+	// SetPostReactionCount stores the current total of reactions to a channel post.
+	SetPostReactionCount(ctx context.Context, channelID int64, postID, count int) error
+	// SetPostOCRText stores the text recognized in the photo of a channel post.
+	SetPostOCRText(ctx context.Context, channelID int64, postID int, text string) error
+	// TopPosts returns the single photo and video posts published to the channel since the given
+	// time with the most reactions, up to limit. Posts without reactions are left out.
+	TopPosts(ctx context.Context, channelID int64, since time.Time, limit int) ([]models.PostLog, error)
+	// AdminActivity sums up the suggestions each admin reviewed and the posts they sent to the
+	// channel (user actions of the given types) since the given time, most active first.
+	AdminActivity(ctx context.Context, since time.Time, directPostActions []string) ([]models.AdminActivity, error)
+	// GetPost returns the log of a channel post, or ErrPostNotFound if the bot didn't log it.
+	GetPost(ctx context.Context, channelID int64, postID int) (*models.PostLog, error)
+	// CountSuggestSources counts, per deep-link source, the start actions (user actions of the
+	// given type with a source) and the suggestions sent since the given time, most opened first.
+	CountSuggestSources(ctx context.Context, since time.Time, startAction string) ([]models.SourceCount, error)
+	// CountPostOrigins counts the posts published to the channel since the given time by origin,
+	// most common first.
+	CountPostOrigins(ctx context.Context, channelID int64, since time.Time) ([]models.OriginCount, error)
+}
+
+// SettingsRepository stores bot settings changed by admins at runtime, keyed by the models.Setting* keys.
+type SettingsRepository interface {
+	// GetSetting returns a setting, or nil if it is not set.
+	GetSetting(ctx context.Context, key string) (*models.Setting, error)
+	// SetSetting stores the value of a setting and who changed it.
+	SetSetting(ctx context.Context, key, value string, updatedBy int64) error
+	// DeleteSetting removes a setting, so its default applies again.
+	DeleteSetting(ctx context.Context, key string) error
+}
+
+// RubricRepository stores rubrics, recurring series of numbered posts.
+type RubricRepository interface {
+	// CreateRubric stores a new rubric. It returns ErrRubricExists if the name is taken.
+	CreateRubric(ctx context.Context, rubric *models.Rubric) error
+	// GetRubric returns a rubric, or nil if it doesn't exist.
+	GetRubric(ctx context.Context, name string) (*models.Rubric, error)
+	// ListRubrics returns all rubrics sorted by name.
+	ListRubrics(ctx context.Context) ([]models.Rubric, error)
+	// DeleteRubric removes a rubric. It returns ErrRubricNotFound if it doesn't exist.
+	DeleteRubric(ctx context.Context, name string) error
+	// SetRubricCounter sets the number of the last post of a rubric.
+	SetRubricCounter(ctx context.Context, name string, counter int64) error
+	// SetRubricReaction sets the emoji the bot reacts with to the posts of a rubric; "" stops
+	// reacting. It returns ErrRubricNotFound if the rubric doesn't exist.
+	SetRubricReaction(ctx context.Context, name, emoji string) error
+	// SetRubricPrompt sets the comment posted under the posts of a rubric in the discussion
+	// group; "" stops commenting. It returns ErrRubricNotFound if the rubric doesn't exist.
+	SetRubricPrompt(ctx context.Context, name, prompt string) error
+	// NextRubricNumber atomically takes the next number of a rubric and returns the rubric
+	// with that number in Counter. It returns ErrRubricNotFound if the rubric doesn't exist.
+	NextRubricNumber(ctx context.Context, name string) (*models.Rubric, error)
+}
+
+// ThrowbackRepository picks archived channel posts for /throwback and logs their reposts.
+type ThrowbackRepository interface {
+	// RandomArchivedPost picks a random photo or video post published to the channel before
+	// publishedBefore that wasn't reposted since notRepostedSince. It returns nil if there is none.
+	RandomArchivedPost(ctx context.Context, channelID int64, publishedBefore, notRepostedSince time.Time) (*models.PostLog, error)
+	// RecordRepost stores a repost in the reposts log.
+	RecordRepost(ctx context.Context, repost *models.Repost) error
+}
+
+// HistoryRepository imports the logs of posts published before the bot logged them.
+type HistoryRepository interface {
+	// ImportPostLogs inserts the post logs of posts that aren't logged yet and returns how many were inserted.
+	ImportPostLogs(ctx context.Context, logs []models.PostLog) (int, error)
+}
+
+// ExperimentRepository stores A/B caption experiments and their measured results.
+type ExperimentRepository interface {
+	// CreateExperiment stores a caption experiment whose variant A was published.
+	CreateExperiment(ctx context.Context, experiment *models.CaptionExperiment) error
+	// MeasureExperiments records the reactions of the posts of unmeasured experiments published
+	// before the given time and returns how many were measured.
+	MeasureExperiments(ctx context.Context, publishedBefore time.Time) (int, error)
+	// CaptionFamilyStats averages the reactions of measured experiments by the family of the
+	// published caption, best first.
+	CaptionFamilyStats(ctx context.Context) ([]models.CaptionFamilyStat, error)
+}
+
+// PinRepository stores the channel posts pinned for a limited time with /pin.
+type PinRepository interface {
+	// CreatePin stores a queued or pinned post.
+	CreatePin(ctx context.Context, pin *models.ScheduledPin) error
+	// ListPins returns the pinned and queued posts, pinned first, then in the order they were queued.
+	ListPins(ctx context.Context) ([]models.ScheduledPin, error)
+	// DuePins returns the pinned posts whose time is up at now.
+	DuePins(ctx context.Context, now time.Time) ([]models.ScheduledPin, error)
+	// NextQueuedPin returns the queued post queued first, or nil if there is none.
+	NextQueuedPin(ctx context.Context) (*models.ScheduledPin, error)
+	// MarkPinned records that a queued post was pinned until unpinAt.
+	MarkPinned(ctx context.Context, id primitive.ObjectID, pinnedAt, unpinAt time.Time) error
+	// MarkUnpinned ends a pinned or queued post. It returns ErrPinNotFound if it already ended.
+	MarkUnpinned(ctx context.Context, id primitive.ObjectID, at time.Time) error
+}
+
+// PermissionRepository stores the admin commands granted to users who aren't channel admins
+// with /perm.
+type PermissionRepository interface {
+	// GrantPermission stores a permission. Granting a permission that exists already keeps it.
+	GrantPermission(ctx context.Context, permission *models.CommandPermission) error
+	// RevokePermission deletes the permission of a command granted to a user, or to a role if role
+	// isn't empty. It returns ErrPermissionNotFound if there is none.
+	RevokePermission(ctx context.Context, command string, userID int64, role string) error
+	// ListPermissions returns the granted permissions by command.
+	ListPermissions(ctx context.Context) ([]models.CommandPermission, error)
+	// HasPermission reports whether the user, or every user, was granted the command.
+	HasPermission(ctx context.Context, command string, userID int64) (bool, error)
+}
+
+// WordFilterRepository stores the words screened in suggestion captions and admin posts, managed
+// with /wordfilter, and logs the filtered words found.
+type WordFilterRepository interface {
+	// SetFilteredWord adds a word, or changes the severity of a word that is already filtered.
+	SetFilteredWord(ctx context.Context, word *models.FilteredWord) error
+	// DeleteFilteredWord stops filtering a word. It returns ErrFilteredWordNotFound if it isn't filtered.
+	DeleteFilteredWord(ctx context.Context, word string) error
+	// ListFilteredWords returns the filtered words in alphabetical order.
+	ListFilteredWords(ctx context.Context) ([]models.FilteredWord, error)
+	// LogFilterHits records filtered words found in a text.
+	LogFilterHits(ctx context.Context, hits []models.FilterHit) error
+	// FilterHitCounts counts the hits of each filtered word since the given time, most hits first.
+	FilterHitCounts(ctx context.Context, since time.Time) ([]models.FilterHitCount, error)
 }
 
 // CallbackProcessor defines the interface for processing callback queries.
@@ -77,3 +380,37 @@ type SuggestionManager interface {
 	HandleCallbackQuery(ctx context.Context, query telego.CallbackQuery) (processed bool, err error)
 }
 */
+
+// UnitOfWork runs a group of repository calls that must succeed or fail together,
+// e.g. writes to several collections.
+type UnitOfWork interface {
+	// Do runs fn. Repository calls made with the context passed to fn are committed together,
+	// or not at all if fn returns an error, when the database supports transactions.
+	// fn may be run more than once if a transaction is retried.
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Pinger checks that the database server is reachable.
+type Pinger interface {
+	// Ping sends a ping to the database server and waits for the reply.
+	Ping(ctx context.Context) error
+}
+
+// TenantRepository stores the channels connected to the bot in multi-tenant mode, and the channel
+// each user last picked with /channel.
+type TenantRepository interface {
+	// CreateTenant stores a connected channel. It returns ErrTenantExists if it is already connected.
+	CreateTenant(ctx context.Context, tenant *models.Tenant) error
+	// ListTenants returns all connected channels, oldest first.
+	ListTenants(ctx context.Context) ([]models.Tenant, error)
+	// SetTenantQuota replaces the quota of a connected channel. It returns ErrTenantNotFound if
+	// the channel isn't connected.
+	SetTenantQuota(ctx context.Context, channelID int64, quota models.TenantQuota) error
+	// DeleteTenant disconnects a channel; its data is kept. It returns ErrTenantNotFound if the
+	// channel isn't connected.
+	DeleteTenant(ctx context.Context, channelID int64) error
+	// GetUserTenant returns the channel a user picked, or zero if they haven't picked one.
+	GetUserTenant(ctx context.Context, userID int64) (int64, error)
+	// SetUserTenant saves the channel a user picked.
+	SetUserTenant(ctx context.Context, userID, channelID int64) error
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// membershipRepository is a MongoDB implementation of MembershipRepository.
+type membershipRepository struct {
+	members *mongo.Collection
+	events  *mongo.Collection
+}
+
+// NewMembershipRepository creates a new instance of membershipRepository.
+func NewMembershipRepository(db *mongo.Database) MembershipRepository {
+	return &membershipRepository{
+		members: db.Collection("channel_members"),
+		events:  db.Collection("membership_events"),
+	}
+}
+
+// SaveMemberStatus upserts the current membership status of a user in a chat.
+func (r *membershipRepository) SaveMemberStatus(ctx context.Context, member models.ChannelMember) error {
+	if member.UpdatedAt.IsZero() {
+		member.UpdatedAt = time.Now()
+	}
+	filter := bson.M{"chat_id": member.ChatID, "user_id": member.UserID}
+	update := bson.M{"$set": member}
+	_, err := r.members.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save member status for user %d in chat %d: %w", member.UserID, member.ChatID, err)
+	}
+	return nil
+}
+
+// GetMemberStatus returns the stored membership of a user in a chat, or nil if it is unknown.
+func (r *membershipRepository) GetMemberStatus(ctx context.Context, chatID, userID int64) (*models.ChannelMember, error) {
+	var member models.ChannelMember
+	err := r.members.FindOne(ctx, bson.M{"chat_id": chatID, "user_id": userID}).Decode(&member)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get member status for user %d in chat %d: %w", userID, chatID, err)
+	}
+	return &member, nil
+}
+
+// RecordMembershipEvent stores a join or leave event.
+func (r *membershipRepository) RecordMembershipEvent(ctx context.Context, event *models.MembershipEvent) error {
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if _, err := r.events.InsertOne(ctx, event); err != nil {
+		return fmt.Errorf("failed to insert membership event for user %d in chat %d: %w", event.UserID, event.ChatID, err)
+	}
+	return nil
+}
+
+// GetMembershipGrowth counts joins and leaves in a chat since the given time.
+func (r *membershipRepository) GetMembershipGrowth(ctx context.Context, chatID int64, since time.Time) (joins, leaves int64, err error) {
+	countEvents := func(joined bool) (int64, error) {
+		return r.events.CountDocuments(ctx, bson.M{
+			"chat_id":     chatID,
+			"joined":      joined,
+			"occurred_at": bson.M{"$gte": since},
+		})
+	}
+
+	joins, err = countEvents(true)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count joins for chat %d: %w", chatID, err)
+	}
+	leaves, err = countEvents(false)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count leaves for chat %d: %w", chatID, err)
+	}
+	return joins, leaves, nil
+}
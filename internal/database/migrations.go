@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"vrcmemes-bot/internal/database/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// indexSpec is an index created by EnsureIndexes.
+type indexSpec struct {
+	Collection string
+	Keys       bson.D
+}
+
+// indexes lists the indexes backing the queries of the repositories. They are not unique,
+// so creating them never fails on existing data.
+var indexes = []indexSpec{
+	{suggestionCollectionName, bson.D{{Key: "status", Value: 1}, {Key: "submitted_at", Value: 1}}},
+	{suggestionCollectionName, bson.D{{Key: "status", Value: 1}, {Key: "priority", Value: -1}, {Key: "submitted_at", Value: 1}}},
+	{suggestionCollectionName, bson.D{{Key: "status", Value: 1}, {Key: "assigned_to", Value: 1}, {Key: "submitted_at", Value: 1}}},
+	{suggestionCollectionName, bson.D{{Key: "status", Value: 1}, {Key: "assigned_at", Value: 1}}},
+	{suggestionCollectionName, bson.D{{Key: "suggester_id", Value: 1}, {Key: "status", Value: 1}, {Key: "submitted_at", Value: -1}}},
+	{suggestionCollectionName, bson.D{{Key: "ocr_key", Value: 1}, {Key: "submitted_at", Value: -1}}},
+	{"post_logs", bson.D{{Key: "channel_id", Value: 1}, {Key: "channel_post_id", Value: 1}}},
+	{"post_logs", bson.D{{Key: "published_at", Value: -1}}},
+	{"users", bson.D{{Key: "user_id", Value: 1}}},
+	{"feedback", bson.D{{Key: "status", Value: 1}, {Key: "submitted_at", Value: -1}}},
+	{"channel_members", bson.D{{Key: "chat_id", Value: 1}, {Key: "user_id", Value: 1}}},
+	{"membership_events", bson.D{{Key: "joined", Value: 1}, {Key: "occurred_at", Value: -1}}},
+	{incidentCollectionName, bson.D{{Key: "status", Value: 1}, {Key: "occurred_at", Value: -1}}},
+	{outboxCollectionName, bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+	{pinCollectionName, bson.D{{Key: "status", Value: 1}, {Key: "unpin_at", Value: 1}}},
+	{permissionCollectionName, bson.D{{Key: "command", Value: 1}, {Key: "user_id", Value: 1}}},
+	{filteredWordCollectionName, bson.D{{Key: "word", Value: 1}}},
+	{filterHitCollectionName, bson.D{{Key: "at", Value: -1}}},
+}
+
+// uniqueIndexes lists the unique indexes. They are sparse, so documents without the field don't
+// take part; suggestions stored before reference codes existed get one from BackfillRefCodes.
+var uniqueIndexes = []indexSpec{
+	{suggestionCollectionName, bson.D{{Key: "ref_code", Value: 1}}},
+}
+
+// maxRefCodeAttempts bounds how many random reference codes are tried for one suggestion.
+const maxRefCodeAttempts = 10
+
+// EnsureIndexes creates the indexes the repositories rely on and returns the names of the
+// created indexes. Indexes that already exist are left as they are, so it is safe to run
+// on every deployment.
+func EnsureIndexes(ctx context.Context, db *mongo.Database) ([]string, error) {
+	names := make([]string, 0, len(indexes)+len(uniqueIndexes))
+	for _, index := range indexes {
+		name, err := db.Collection(index.Collection).Indexes().CreateOne(ctx, mongo.IndexModel{Keys: index.Keys})
+		if err != nil {
+			return names, fmt.Errorf("failed to create index on %s: %w", index.Collection, err)
+		}
+		names = append(names, index.Collection+"."+name)
+	}
+	unique, err := EnsureUniqueIndexes(ctx, db)
+	return append(names, unique...), err
+}
+
+// EnsureUniqueIndexes creates the unique indexes and returns their names. Reference codes are
+// only unique with them in place, so the bot creates them at startup too.
+func EnsureUniqueIndexes(ctx context.Context, db *mongo.Database) ([]string, error) {
+	names := make([]string, 0, len(uniqueIndexes))
+	for _, index := range uniqueIndexes {
+		model := mongo.IndexModel{Keys: index.Keys, Options: options.Index().SetUnique(true).SetSparse(true)}
+		name, err := db.Collection(index.Collection).Indexes().CreateOne(ctx, model)
+		if err != nil {
+			return names, fmt.Errorf("failed to create unique index on %s: %w", index.Collection, err)
+		}
+		names = append(names, index.Collection+"."+name)
+	}
+	return names, nil
+}
+
+// BackfillRefCodes gives a reference code to the suggestions stored before codes were introduced
+// and returns how many were updated. It relies on the unique index of EnsureIndexes to refuse
+// codes that are taken.
+func BackfillRefCodes(ctx context.Context, db *mongo.Database) (int, error) {
+	collection := db.Collection(suggestionCollectionName)
+	cursor, err := collection.Find(ctx, bson.M{"ref_code": bson.M{"$exists": false}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return 0, fmt.Errorf("failed to find suggestions without a reference code: %w", err)
+	}
+	var docs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return 0, fmt.Errorf("failed to decode suggestions without a reference code: %w", err)
+	}
+
+	updated := 0
+	for _, doc := range docs {
+		for attempt := 1; ; attempt++ {
+			filter := bson.M{"_id": doc.ID, "ref_code": bson.M{"$exists": false}}
+			_, err := collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"ref_code": models.NewRefCode()}})
+			if err == nil {
+				updated++
+				break
+			}
+			if !mongo.IsDuplicateKeyError(err) || attempt == maxRefCodeAttempts {
+				return updated, fmt.Errorf("failed to set the reference code of suggestion %s: %w", doc.ID.Hex(), err)
+			}
+		}
+	}
+	return updated, nil
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CaptionVariant is one of the two captions of a caption experiment. The family names the
+// caption style, e.g. "question" or "short", so results add up across experiments.
+type CaptionVariant struct {
+	Family  string `bson:"family"`
+	Caption string `bson:"caption"`
+}
+
+// CaptionExperiment is an A/B caption test: variant A was published with a post, and its
+// reactions are measured once the measurement window has passed.
+type CaptionExperiment struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	VariantA      CaptionVariant     `bson:"variant_a"` // The published caption
+	VariantB      CaptionVariant     `bson:"variant_b"` // The alternative it is compared with
+	ChannelID     int64              `bson:"channel_id"`
+	ChannelPostID int                `bson:"channel_post_id"`
+	CreatedBy     int64              `bson:"created_by"`
+	PublishedAt   time.Time          `bson:"published_at"`
+	MeasuredAt    *time.Time         `bson:"measured_at,omitempty"` // Unset until the window has passed
+	Reactions     int                `bson:"reactions"`             // Reactions to the post when measured
+}
+
+// CaptionFamilyStat sums up the measured experiments whose published caption belongs to a family.
+type CaptionFamilyStat struct {
+	Family       string  `bson:"_id"`
+	Posts        int64   `bson:"posts"`
+	AvgReactions float64 `bson:"avg_reactions"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// CaptionStylePlaceholder is replaced with the caption in a caption style's template.
+	CaptionStylePlaceholder = "{caption}"
+	// MaxCaptionStyleNameLength bounds caption style names in bytes, so they fit into callback data.
+	MaxCaptionStyleNameLength = 24
+)
+
+// CaptionStyle is a preset of caption decorations, e.g. an emoji prefix and a separator line.
+// Caption styles are stored together as JSON in the SettingCaptionStyles setting.
+type CaptionStyle struct {
+	Name     string        `json:"name"`
+	Template string        `json:"template"`        // Decorations around CaptionStylePlaceholder
+	Emoji    []CustomEmoji `json:"emoji,omitempty"` // Custom emoji of the template
+}
+
+// Apply decorates a caption with the style. Empty captions stay empty, so media without
+// a caption isn't posted with bare decorations.
+func (s CaptionStyle) Apply(caption string) string {
+	if caption == "" {
+		return ""
+	}
+	return strings.TrimSpace(strings.ReplaceAll(s.Template, CaptionStylePlaceholder, caption))
+}
+
+// DecodeCaptionStyles parses the value of the SettingCaptionStyles setting. A nil setting has no styles.
+func DecodeCaptionStyles(setting *Setting) ([]CaptionStyle, error) {
+	if setting == nil || setting.Value == "" {
+		return nil, nil
+	}
+	var styles []CaptionStyle
+	if err := json.Unmarshal([]byte(setting.Value), &styles); err != nil {
+		return nil, fmt.Errorf("failed to decode caption styles: %w", err)
+	}
+	return styles, nil
+}
+
+// EncodeCaptionStyles formats caption styles as the value of the SettingCaptionStyles setting.
+func EncodeCaptionStyles(styles []CaptionStyle) (string, error) {
+	value, err := json.Marshal(styles)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode caption styles: %w", err)
+	}
+	return string(value), nil
+}
+
+// FindCaptionStyle returns the style with the given name, or false if there is none.
+func FindCaptionStyle(styles []CaptionStyle, name string) (CaptionStyle, bool) {
+	for _, style := range styles {
+		if style.Name == name {
+			return style, true
+		}
+	}
+	return CaptionStyle{}, false
+}
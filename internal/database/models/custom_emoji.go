@@ -0,0 +1,10 @@
+package models
+
+// CustomEmoji is a custom emoji of a text, such as an emoji of a Telegram Premium emoji pack.
+// Offset and Length are in UTF-16 code units, as in the Bot API, and cover the regular emoji
+// shown in its place by clients that can't display it.
+type CustomEmoji struct {
+	Offset int    `bson:"offset" json:"offset"`
+	Length int    `bson:"length" json:"length"`
+	ID     string `bson:"id" json:"id"`
+}
@@ -6,6 +6,27 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Feedback categories, chosen by the user before writing feedback.
+const (
+	FeedbackCategoryBug       = "bug"
+	FeedbackCategoryIdea      = "idea"
+	FeedbackCategoryComplaint = "complaint"
+	FeedbackCategoryOther     = "other"
+)
+
+// FeedbackCategories lists the feedback categories in the order they are offered.
+var FeedbackCategories = []string{FeedbackCategoryBug, FeedbackCategoryIdea, FeedbackCategoryComplaint, FeedbackCategoryOther}
+
+// Feedback triage statuses, changed by admins. Feedback stored without a status is new.
+const (
+	FeedbackStatusNew        = "new"
+	FeedbackStatusInProgress = "in_progress"
+	FeedbackStatusResolved   = "resolved"
+)
+
+// FeedbackStatuses lists the triage statuses in workflow order.
+var FeedbackStatuses = []string{FeedbackStatusNew, FeedbackStatusInProgress, FeedbackStatusResolved}
+
 // Feedback represents a feedback submission from a user.
 type Feedback struct {
 	ID             primitive.ObjectID `bson:"_id,omitempty"`
@@ -19,4 +40,23 @@ type Feedback struct {
 	SubmittedAt    time.Time          `bson:"submitted_at"`
 	OriginalChatID int64              `bson:"original_chat_id"`
 	MessageID      int                `bson:"message_id"`
+	Category       string             `bson:"category,omitempty"` // One of FeedbackCategories
+	// Triage status, changed by admins from the feedback chat
+	Status          string    `bson:"status,omitempty"`
+	StatusChangedBy int64     `bson:"status_changed_by,omitempty"`
+	StatusChangedAt time.Time `bson:"status_changed_at,omitempty"`
+	// ContactOptIn is set when the user asked for a reply and agreed admins may contact them;
+	// admins can only reply to such feedback
+	ContactOptIn bool `bson:"contact_opt_in,omitempty"`
+	// ChannelID is the channel the feedback was sent about in multi-tenant mode; zero for feedback
+	// stored before multi-tenant mode
+	ChannelID int64 `bson:"channel_id,omitempty"`
+}
+
+// CurrentStatus returns the triage status, treating feedback stored without one as new.
+func (f *Feedback) CurrentStatus() string {
+	if f.Status == "" {
+		return FeedbackStatusNew
+	}
+	return f.Status
 }
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Incident types.
+const (
+	IncidentTypePanic          = "panic"           // A handler panicked while handling an update
+	IncidentTypePublishFailure = "publish_failure" // A channel publication failed for good
+	IncidentTypeDatabaseOutage = "database_outage" // MongoDB was unreachable and the bot ran in degraded mode
+	IncidentTypeWebhookReset   = "webhook_reset"   // A webhook was found set at startup and removed
+)
+
+// Incident status values, changed by admins with /incidents.
+const (
+	IncidentStatusOpen         = "open"
+	IncidentStatusAcknowledged = "acknowledged" // Someone is looking into it
+	IncidentStatusResolved     = "resolved"
+)
+
+// Incident records a significant runtime failure for /incidents. The ID of a panic incident
+// is shown to the affected user and attached to the Sentry event, so a report can be traced back.
+type Incident struct {
+	ID         string    `bson:"_id"`
+	Type       string    `bson:"type"`
+	Status     string    `bson:"status"`
+	OccurredAt time.Time `bson:"occurred_at"`
+	Summary    string    `bson:"summary"`           // One line shown in lists, e.g. the panic value or the error
+	Details    string    `bson:"details,omitempty"` // e.g. the stack trace of a panic
+
+	// The update being handled, for panics
+	UpdateID      int    `bson:"update_id,omitempty"`
+	UpdateKind    string `bson:"update_kind,omitempty"` // e.g. "message", "callback_query", "media_group"
+	UserID        int64  `bson:"user_id,omitempty"`
+	ChatID        int64  `bson:"chat_id,omitempty"`
+	SentryEventID string `bson:"sentry_event_id,omitempty"`
+
+	StatusChangedBy int64     `bson:"status_changed_by,omitempty"`
+	StatusChangedAt time.Time `bson:"status_changed_at,omitempty"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChannelMember is the last known membership status of a user in a chat,
+// kept up to date from chat_member updates.
+type ChannelMember struct {
+	ChatID    int64     `bson:"chat_id"`
+	UserID    int64     `bson:"user_id"`
+	Username  string    `bson:"username,omitempty"`
+	Status    string    `bson:"status"` // Telegram member status (member, left, kicked, ...)
+	UpdatedAt time.Time `bson:"updated_at"`
+	JoinedAt  time.Time `bson:"joined_at,omitempty"` // Last time the user became a member; kept when unset on later updates
+}
+
+// MembershipEvent records a single join or leave in a chat, used for growth analytics.
+type MembershipEvent struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	ChatID     int64              `bson:"chat_id"`
+	UserID     int64              `bson:"user_id"`
+	Username   string             `bson:"username,omitempty"`
+	OldStatus  string             `bson:"old_status"`
+	NewStatus  string             `bson:"new_status"`
+	Joined     bool               `bson:"joined"` // True if the user became a member, false if they left or were removed
+	OccurredAt time.Time          `bson:"occurred_at"`
+}
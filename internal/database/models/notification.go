@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Notification status values.
+const (
+	NotificationStatusPending = "pending" // Waiting for its next delivery attempt
+	NotificationStatusSending = "sending" // Claimed by the dispatcher
+	NotificationStatusSent    = "sent"
+	NotificationStatusFailed  = "failed" // Given up on; see Error
+)
+
+// Notification kinds, recorded for diagnostics.
+const (
+	NotificationKindAdminPing         = "admin_ping"
+	NotificationKindQuietHoursSummary = "quiet_hours_summary"
+	NotificationKindIntakeReceipt     = "intake_receipt"
+	NotificationKindExpiry            = "suggestion_expired"
+	NotificationKindRefCodeChanged    = "ref_code_changed"
+)
+
+// NotificationKindCategory returns the NotificationCategories entry users turn notifications of a
+// kind on and off with, or "" for kinds users can't turn off, such as admin pings.
+func NotificationKindCategory(kind string) string {
+	switch kind {
+	case NotificationKindExpiry:
+		return NotificationCategoryDecisions
+	default:
+		return ""
+	}
+}
+
+// Notification is a message to a user stored in the outbox before it is sent, so it is
+// delivered even if the bot restarts or Telegram fails in between.
+type Notification struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	Kind          string             `bson:"kind"`
+	ChatID        int64              `bson:"chat_id"`
+	Text          string             `bson:"text"`
+	Status        string             `bson:"status"`
+	Attempts      int                `bson:"attempts"`
+	CreatedAt     time.Time          `bson:"created_at"`
+	NextAttemptAt time.Time          `bson:"next_attempt_at"` // When a pending notification is due, or when a claim lapses
+	SentAt        time.Time          `bson:"sent_at,omitempty"`
+	Error         string             `bson:"error,omitempty"` // Last failure
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PermissionRoleEveryone is the role of a permission granted to every user.
+const PermissionRoleEveryone = "everyone"
+
+// CommandPermission lets a user, or every user, run an admin command without being a channel
+// admin. It is granted with /perm.
+type CommandPermission struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	ChannelID int64              `bson:"channel_id,omitempty"`
+	Command   string             `bson:"command"`           // Command name without the slash, e.g. "review"
+	UserID    int64              `bson:"user_id,omitempty"` // Set for a permission granted to a user
+	Role      string             `bson:"role,omitempty"`    // Set for a permission granted to a role
+	GrantedBy int64              `bson:"granted_by"`
+	GrantedAt time.Time          `bson:"granted_at"`
+}
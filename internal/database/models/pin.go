@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Pin statuses.
+const (
+	PinStatusQueued   = "queued"   // Waits for the pin before it to end
+	PinStatusPinned   = "pinned"   // Pinned in the channel until UnpinAt
+	PinStatusUnpinned = "unpinned" // Unpinned when its time was up or canceled
+)
+
+// ScheduledPin is a channel post pinned for a limited time with /pin. A queued pin replaces the
+// pinned one when its time is up.
+type ScheduledPin struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	ChannelID     int64              `bson:"channel_id"`
+	ChannelPostID int                `bson:"channel_post_id"`
+	Duration      time.Duration      `bson:"duration"` // How long the post stays pinned
+	Status        string             `bson:"status"`
+	CreatedBy     int64              `bson:"created_by"`
+	CreatedAt     time.Time          `bson:"created_at"`
+	PinnedAt      *time.Time         `bson:"pinned_at,omitempty"`
+	UnpinAt       *time.Time         `bson:"unpin_at,omitempty"` // Set when pinned
+	UnpinnedAt    *time.Time         `bson:"unpinned_at,omitempty"`
+}
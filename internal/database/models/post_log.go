@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 // PostLog stores information about a post published to the channel.
 type PostLog struct {
@@ -14,4 +18,29 @@ type PostLog struct {
 	ChannelPostID        int       `bson:"channel_post_id"`
 	OriginalMessageID    int       `bson:"original_message_id,omitempty"`     // For single messages
 	OriginalMediaGroupID string    `bson:"original_media_group_id,omitempty"` // For media groups
+	SourceURL            string    `bson:"source_url,omitempty"`              // For posts published from a URL
+	SourceFile           string    `bson:"source_file,omitempty"`             // For posts published from the staging directory
+	FileID               string    `bson:"file_id,omitempty"`                 // Photo or video of single media posts, reused by /bestof
+	ReactionCount        int       `bson:"reaction_count,omitempty"`          // Total reactions, kept up to date with TRACK_REACTIONS
+	OCRText              string    `bson:"ocr_text,omitempty"`                // Text recognized in the photo when OCR is enabled
+	ProtectContent       bool      `bson:"protect_content,omitempty"`         // Published with protected content, which can't be forwarded or saved
+	// Origin is how the post came to be, one of the PostOrigin* constants; logs from before it was
+	// recorded have none
+	Origin string `bson:"origin,omitempty"`
+	// SuggestionID is the suggestion published as the post, for posts of PostOriginSuggestion
+	SuggestionID primitive.ObjectID `bson:"suggestion_id,omitempty"`
+}
+
+// Origins of channel posts, see PostLog.Origin. Scheduled posts are published without anyone
+// sending them at the time, from the staging directory; SourceFile names the file.
+const (
+	PostOriginDirect     = "direct"     // Sent to the bot by an admin
+	PostOriginSuggestion = "suggestion" // Suggested by a user and approved
+	PostOriginScheduled  = "scheduled"  // Published automatically
+)
+
+// OriginCount is how many posts have an origin, for /stats sources.
+type OriginCount struct {
+	Origin string `bson:"_id"`
+	Count  int64  `bson:"count"`
 }
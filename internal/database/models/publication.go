@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Publication status values.
+const (
+	PublicationStatusPending   = "pending"
+	PublicationStatusPublished = "published"
+	PublicationStatusFailed    = "failed"
+)
+
+// Publication records a channel publication under its idempotency key (e.g. a suggestion
+// or media group ID), so the same content is not posted twice after a retry or restart.
+type Publication struct {
+	Key         string    `bson:"_id"`
+	Status      string    `bson:"status"`
+	CreatedAt   time.Time `bson:"created_at"`
+	AttemptedAt time.Time `bson:"attempted_at"`
+	PublishedAt time.Time `bson:"published_at,omitempty"`
+	Error       string    `bson:"error,omitempty"` // Last failure, if the publication failed
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"crypto/rand"
+	"strings"
+)
+
+const (
+	// RefCodePrefix starts every suggestion reference code, e.g. "S-4F7K".
+	RefCodePrefix = "S-"
+	// refCodeAlphabet leaves out characters that are easily confused: 0/O, 1/I.
+	refCodeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+	refCodeLength   = 4
+)
+
+// NewRefCode returns a random reference code for a suggestion, e.g. "S-4F7K". Codes are short to be
+// easy to type; the unique index on them makes the repository refuse a code that is taken.
+func NewRefCode() string {
+	b := make([]byte, refCodeLength)
+	_, _ = rand.Read(b) // Never fails, see crypto/rand.Read
+	for i := range b {
+		b[i] = refCodeAlphabet[int(b[i])%len(refCodeAlphabet)]
+	}
+	return RefCodePrefix + string(b)
+}
+
+// IsRefCode reports whether s is a reference code in its stored form.
+func IsRefCode(s string) bool {
+	code, ok := strings.CutPrefix(s, RefCodePrefix)
+	if !ok || len(code) != refCodeLength {
+		return false
+	}
+	for _, r := range code {
+		if !strings.ContainsRune(refCodeAlphabet, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeRefCode turns a reference code as typed by a user ("s-4f7k", "4F7K") into its stored form.
+func NormalizeRefCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !strings.HasPrefix(code, RefCodePrefix) {
+		code = RefCodePrefix + code
+	}
+	return code
+}
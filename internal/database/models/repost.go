@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Repost records a channel post that was published again by /throwback, so the same post
+// isn't brought back too often.
+type Repost struct {
+	ChannelID     int64     `bson:"channel_id"`
+	ChannelPostID int       `bson:"channel_post_id"` // The original post
+	RepostPostID  int       `bson:"repost_post_id"`  // The new copy in the channel
+	RepostedBy    int64     `bson:"reposted_by"`
+	RepostedAt    time.Time `bson:"reposted_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// RubricNumberPlaceholder is replaced with the post number in a rubric's caption template.
+	RubricNumberPlaceholder = "{number}"
+	// MaxRubricNameLength bounds rubric names in bytes, so they fit into callback data.
+	MaxRubricNameLength = 24
+)
+
+// Rubric is a recurring series of posts, e.g. "#meme_of_the_day No.42".
+// Each post in the rubric takes the next number of its counter.
+type Rubric struct {
+	Name      string    `bson:"_id"`
+	Template  string    `bson:"template"` // Caption with RubricNumberPlaceholder
+	Counter   int64     `bson:"counter"`  // Number of the last post
+	CreatedBy int64     `bson:"created_by"`
+	CreatedAt time.Time `bson:"created_at"`
+	// Prompts for readers to engage with each post, set with /rubric react and /rubric prompt
+	Reaction string `bson:"reaction,omitempty"` // Emoji the bot reacts with, so readers only need to tap it
+	Prompt   string `bson:"prompt,omitempty"`   // Comment posted under the post in the discussion group
+	// ChannelID is the channel the rubric belongs to in multi-tenant mode; zero for rubrics stored
+	// before multi-tenant mode
+	ChannelID int64 `bson:"channel_id,omitempty"`
+}
+
+// Caption fills the number into the caption template.
+func (r Rubric) Caption(number int64) string {
+	return strings.ReplaceAll(r.Template, RubricNumberPlaceholder, strconv.FormatInt(number, 10))
+}
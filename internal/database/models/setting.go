@@ -0,0 +1,80 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Setting keys.
+const (
+	SettingGreeting      = "greeting"       // Telegram HTML of the /start message; unset uses the localized default
+	SettingCaptionStyles = "caption_styles" // JSON list of CaptionStyle presets managed by /style
+	// Chosen in /setup; they override the BOT_DEFAULT_LANGUAGE, CREDIT_SUGGESTERS and
+	// CREDIT_FORWARD_SOURCE variables
+	SettingDefaultLanguage     = "default_language"
+	SettingCreditSuggesters    = "credit_suggesters"     // "true" or "false"
+	SettingCreditForwardSource = "credit_forward_source" // "true" or "false"
+	SettingProtectContent      = "protect_content"       // "true" or "false", overrides PROTECT_CONTENT
+	SettingSetup               = "setup"                 // JSON SetupResult of the last /setup run
+	SettingFooterRotation      = "footer_rotation"       // Index of the next HASHTAG_FOOTERS footer
+	SettingDashboardMessage    = "dashboard_message"     // "<chat ID>:<message ID>" of the pinned dashboard
+	// Comma-separated media types accepted in suggestions, set by /contenttypes; overrides
+	// SUGGESTION_CONTENT_TYPES
+	SettingSuggestionContentTypes = "suggestion_content_types"
+	// JSON auto-reject rules set by /autoreject; overrides the AUTO_REJECT_* variables
+	SettingAutoRejectRules = "auto_reject_rules"
+	// JSON object of the admin notifications held back during quiet hours, by admin ID, so they
+	// survive restarts
+	SettingHeldNotifications = "held_notifications"
+)
+
+// QuietHoursSettingKey returns the key of an admin's quiet hours, stored as "HH:MM-HH:MM".
+func QuietHoursSettingKey(adminID int64) string {
+	return "quiet_hours:" + strconv.FormatInt(adminID, 10)
+}
+
+// DecodeHeldNotifications parses the value of the SettingHeldNotifications setting. A nil setting
+// has no notifications.
+func DecodeHeldNotifications(setting *Setting) (map[int64][]string, error) {
+	if setting == nil || setting.Value == "" {
+		return nil, nil
+	}
+	var held map[int64][]string
+	if err := json.Unmarshal([]byte(setting.Value), &held); err != nil {
+		return nil, fmt.Errorf("failed to decode held notifications: %w", err)
+	}
+	return held, nil
+}
+
+// EncodeHeldNotifications formats held notifications as the value of the SettingHeldNotifications setting.
+func EncodeHeldNotifications(held map[int64][]string) (string, error) {
+	value, err := json.Marshal(held)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode held notifications: %w", err)
+	}
+	return string(value), nil
+}
+
+// SetupResult records what the /setup wizard found when checking the channel and whether its
+// test message could be posted.
+type SetupResult struct {
+	ChannelTitle  string    `json:"channel_title,omitempty"`
+	BotStatus     string    `json:"bot_status,omitempty"` // Member status of the bot in the channel
+	CanPost       bool      `json:"can_post"`
+	CanDelete     bool      `json:"can_delete"`
+	CheckedAt     time.Time `json:"checked_at"`
+	TestMessageID int       `json:"test_message_id,omitempty"` // Channel message of the test post
+	TestError     string    `json:"test_error,omitempty"`      // Why the test post failed
+	CompletedBy   int64     `json:"completed_by,omitempty"`
+	CompletedAt   time.Time `json:"completed_at,omitempty"`
+}
+
+// Setting is a bot setting changed by admins at runtime.
+type Setting struct {
+	Key       string    `bson:"_id"`
+	Value     string    `bson:"value"`
+	UpdatedBy int64     `bson:"updated_by"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
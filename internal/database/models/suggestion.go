@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -8,18 +9,291 @@ import (
 
 // Suggestion represents a user suggestion stored in the database.
 type Suggestion struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty"` // MongoDB default ID
+	ID          primitive.ObjectID `bson:"_id,omitempty"`      // MongoDB default ID
+	RefCode     string             `bson:"ref_code,omitempty"` // Short reference code given to the suggester, see NewRefCode
 	SuggesterID int64              `bson:"suggester_id"`
 	Username    string             `bson:"username,omitempty"`
 	FirstName   string             `bson:"first_name,omitempty"`
-	MessageID   int                `bson:"message_id"`        // Original message ID in the bot chat
-	ChatID      int64              `bson:"chat_id"`           // Chat ID where the suggestion was sent (bot chat)
-	FileIDs     []string           `bson:"file_ids"`          // File IDs of the photos
-	Caption     string             `bson:"caption,omitempty"` // User-provided caption
-	Status      string             `bson:"status"`            // e.g., "pending", "approved", "rejected"
-	SubmittedAt time.Time          `bson:"submitted_at"`
-	ReviewedBy  int64              `bson:"reviewed_by,omitempty"` // Admin who reviewed it
-	ReviewedAt  time.Time          `bson:"reviewed_at,omitempty"`
+	MessageID   int                `bson:"message_id"` // Original message ID in the bot chat
+	ChatID      int64              `bson:"chat_id"`    // Chat ID where the suggestion was sent (bot chat)
+	// ChannelID is the channel the suggestion was sent to in multi-tenant mode; zero for suggestions
+	// stored before multi-tenant mode, which belong to the primary channel
+	ChannelID int64    `bson:"channel_id,omitempty"`
+	FileIDs   []string `bson:"file_ids"` // File IDs of the media
+	// MediaTypes holds the MediaType* of each file in FileIDs; files without an entry, such as
+	// all files of photo-only suggestions, are photos
+	MediaTypes  []string  `bson:"media_types,omitempty"`
+	Caption     string    `bson:"caption,omitempty"` // User-provided caption
+	Status      string    `bson:"status"`            // e.g., "pending", "approved", "rejected"
+	SubmittedAt time.Time `bson:"submitted_at"`
+	EditedAt    time.Time `bson:"edited_at,omitempty"`   // Last edit by the suggester before review
+	ReviewedBy  int64     `bson:"reviewed_by,omitempty"` // Admin who reviewed it
+	ReviewedAt  time.Time `bson:"reviewed_at,omitempty"`
+	// RejectionReason names the auto-reject rule that rejected the suggestion, if any, or is
+	// RejectionReasonDuplicate for a suggestion merged into another one and
+	// RejectionReasonExpired for one left pending too long
+	RejectionReason string `bson:"rejection_reason,omitempty"`
+	// DuplicateOf is the suggestion a reviewer merged this one into
+	DuplicateOf primitive.ObjectID `bson:"duplicate_of,omitempty"`
+	// ReopenedAt is when an admin last put the suggestion back in the queue after a rejection;
+	// expiry counts from it rather than from submission
+	ReopenedAt time.Time `bson:"reopened_at,omitempty"`
+	// CoSuggesters sent the same meme in suggestions a reviewer merged into this one
+	CoSuggesters []CoSuggester `bson:"co_suggesters,omitempty"`
+	// ForwardOrigin is set when the suggestion was forwarded from elsewhere
+	ForwardOrigin *ForwardOrigin `bson:"forward_origin,omitempty"`
+	// Tags picked by the reviewer; published as hashtags
+	Tags []string `bson:"tags,omitempty"`
+	// Rubric the reviewer picked; the post takes the rubric's next number when published
+	Rubric string `bson:"rubric,omitempty"`
+	// Caption style the reviewer picked to decorate the published caption
+	Style string `bson:"style,omitempty"`
+	// Credit is the suggester's choice whether to be named when the post is published;
+	// nil means they didn't choose, and the channel default applies
+	Credit *bool `bson:"credit,omitempty"`
+	// Comments is the thread of reviewer questions and suggester answers, oldest first
+	Comments []SuggestionComment `bson:"comments,omitempty"`
+	// Priority orders the review queue with the priority ordering, highest first: how many of
+	// the suggester's earlier suggestions had been approved when this one was submitted
+	Priority int `bson:"priority,omitempty"`
+	// AssignedTo is the admin the suggestion was assigned to for review, when review assignment is
+	// enabled; AssignedAt is when, so assignments left untouched can be handed to another admin
+	AssignedTo int64     `bson:"assigned_to,omitempty"`
+	AssignedAt time.Time `bson:"assigned_at,omitempty"`
+	// PreviewChatID and PreviewMessageIDs locate the post in the preview channel when the suggestion
+	// was approved with "Preview first"; promoting it copies these messages to the channel
+	PreviewChatID     int64 `bson:"preview_chat_id,omitempty"`
+	PreviewMessageIDs []int `bson:"preview_message_ids,omitempty"`
+	// PublishedMessageID is the (first) channel message of the published post, linked by /status
+	PublishedMessageID int `bson:"published_message_id,omitempty"`
+	// Source is where the suggester opened the bot from, taken from the "Suggest a meme" deep link
+	// (e.g. "channel" or "group"); empty when they started the suggestion themselves
+	Source string `bson:"source,omitempty"`
+	// OCRText is the text recognized in the photos when OCR is enabled, and OCRKey its normalized
+	// form matched against other suggestions, see ocr.Key
+	OCRText string `bson:"ocr_text,omitempty"`
+	OCRKey  string `bson:"ocr_key,omitempty"`
+	// SimilarTo is the reference code of an earlier suggestion with the same recognized text
+	SimilarTo string `bson:"similar_to,omitempty"`
+	// ProtectContent overrides for this post whether it is published with protected content, which
+	// can't be forwarded or saved; nil follows the channel setting
+	ProtectContent *bool `bson:"protect_content,omitempty"`
+	// TranslatedCaption is the caption translated into the channel language when translation is
+	// enabled and the caption is in another language, which CaptionLanguage names, e.g. "de"
+	TranslatedCaption string `bson:"translated_caption,omitempty"`
+	CaptionLanguage   string `bson:"caption_language,omitempty"`
+	// CaptionChoice is the version of a translated caption the reviewer publishes with the post,
+	// one of the CaptionChoice* constants; empty publishes neither
+	CaptionChoice string `bson:"caption_choice,omitempty"`
+	// FilteredWords are the words of /wordfilter found in the caption with the warn or censor
+	// severity, shown to reviewers
+	FilteredWords []string `bson:"filtered_words,omitempty"`
+	// MediaIssues are the files outside the media limits, which were accepted with a warning to
+	// reviewers rather than rejected
+	MediaIssues []MediaIssue `bson:"media_issues,omitempty"`
+	// VideoPolicyOverride lets a suggestion with videos outside the video policy be published,
+	// which a reviewer allows with the review keyboard
+	VideoPolicyOverride bool `bson:"video_policy_override,omitempty"`
+	// Thumbnail is the thumbnail a reviewer chose for the first video of the suggestion; nil
+	// publishes it with the thumbnail Telegram picks
+	Thumbnail *VideoThumbnail `bson:"thumbnail,omitempty"`
+	// History records the review decisions and assignments of the suggestion, oldest first
+	History []StatusChange `bson:"history,omitempty"`
+	// Test marks suggestions of the test user of /simulate, which are never published and are
+	// deleted by /purgetest
+	Test bool `bson:"test,omitempty"`
+}
+
+// StatusChange is an entry of the review history of a suggestion: a status transition, or an
+// assignment to an admin, which keeps the suggestion pending.
+type StatusChange struct {
+	From     string    `bson:"from"`               // Status before the change
+	To       string    `bson:"to"`                 // Status after the change
+	By       int64     `bson:"by,omitempty"`       // Admin who made the change; zero when the bot did
+	At       time.Time `bson:"at"`                 // When the change was made
+	Reason   string    `bson:"reason,omitempty"`   // Rejection reason, or HistoryReasonAssigned
+	Assignee int64     `bson:"assignee,omitempty"` // Admin the suggestion was assigned to, for assignments
+}
+
+// HistoryReasonAssigned marks assignments in the history of a suggestion.
+const HistoryReasonAssigned = "assigned"
+
+// RejectionReasonDuplicate is the rejection reason of suggestions merged into another one.
+const RejectionReasonDuplicate = "duplicate"
+
+// RejectionReasonExpired is the rejection reason of suggestions left pending longer than allowed.
+const RejectionReasonExpired = "expired"
+
+// Media types of suggested files, see Suggestion.MediaTypes.
+const (
+	MediaTypePhoto     = "photo"
+	MediaTypeVideo     = "video"
+	MediaTypeAnimation = "animation" // GIFs, which Telegram sends as soundless MP4 animations
+	MediaTypeDocument  = "document"
+)
+
+// MediaTypes lists the media types suggestions can be made of.
+var MediaTypes = []string{MediaTypePhoto, MediaTypeVideo, MediaTypeAnimation, MediaTypeDocument}
+
+// Kinds of media issues, see MediaIssue.
+const (
+	MediaIssueLowResolution = "low_resolution" // The shorter side is below the minimum resolution
+	MediaIssueFileTooLarge  = "file_too_large" // The file is larger than the maximum size
+	MediaIssueVideoTooLong  = "video_too_long" // The video is longer than the video policy allows
+	MediaIssueAspectRatio   = "aspect_ratio"   // The video's aspect ratio is outside the video policy
+)
+
+// MediaIssue is a suggested file outside the media limits or the video policy.
+type MediaIssue struct {
+	Index    int    `bson:"index"` // Position of the file in FileIDs
+	Kind     string `bson:"kind"`  // One of the MediaIssue* constants
+	Width    int    `bson:"width,omitempty"`
+	Height   int    `bson:"height,omitempty"`
+	FileSize int64  `bson:"file_size,omitempty"`
+	Duration int    `bson:"duration,omitempty"` // Length of videos in seconds
+	Range    string `bson:"range,omitempty"`    // Allowed aspect ratios, e.g. "9:16–16:9"
+	// Limit is the limit the file broke when it was submitted: pixels of the shorter side, bytes
+	// or seconds
+	Limit int64 `bson:"limit,omitempty"`
+}
+
+// VideoThumbnail is a custom thumbnail of a video: an image when FileID is set, and otherwise the
+// frame of the video At seconds in.
+type VideoThumbnail struct {
+	FileID string `bson:"file_id,omitempty"` // Photo size of at most 320×320 pixels
+	At     int    `bson:"at,omitempty"`
+}
+
+// IsVideoPolicy reports whether the issue is a violation of the video policy.
+func (i MediaIssue) IsVideoPolicy() bool {
+	return i.Kind == MediaIssueVideoTooLong || i.Kind == MediaIssueAspectRatio
+}
+
+// Versions of a translated caption a reviewer can publish, see Suggestion.CaptionChoice.
+const (
+	CaptionChoiceOriginal    = "original"
+	CaptionChoiceTranslation = "translation"
+)
+
+// CoSuggester is the suggester of a duplicate merged into a suggestion.
+type CoSuggester struct {
+	SuggesterID int64  `bson:"suggester_id"`
+	Username    string `bson:"username,omitempty"`
+	FirstName   string `bson:"first_name,omitempty"`
+	RefCode     string `bson:"ref_code,omitempty"` // Reference code of the merged duplicate
+	// Credit is the co-suggester's answer to the credit question, as for Suggestion.Credit
+	Credit *bool `bson:"credit,omitempty"`
+}
+
+// SourceCount is how the users who opened the bot from one deep-link source went on, for /stats sources.
+type SourceCount struct {
+	Source      string `bson:"_id"`
+	Opens       int64  `bson:"opens"`       // Times the link was opened
+	Users       int64  `bson:"users"`       // Distinct users who opened it
+	Suggestions int64  `bson:"suggestions"` // Suggestions sent after opening it
+}
+
+// Ref returns how the suggestion is referred to in button callbacks and messages: its reference code,
+// or the hex ObjectID for suggestions stored before reference codes were introduced.
+func (s *Suggestion) Ref() string {
+	if s.RefCode != "" {
+		return s.RefCode
+	}
+	return s.ID.Hex()
+}
+
+// MediaType returns the media type of the file at index i of FileIDs.
+func (s *Suggestion) MediaType(i int) string {
+	if i < len(s.MediaTypes) && s.MediaTypes[i] != "" {
+		return s.MediaTypes[i]
+	}
+	return MediaTypePhoto
+}
+
+// HasVideoPolicyIssues reports whether videos of the suggestion are outside the video policy.
+func (s *Suggestion) HasVideoPolicyIssues() bool {
+	for _, issue := range s.MediaIssues {
+		if issue.IsVideoPolicy() {
+			return true
+		}
+	}
+	return false
+}
+
+// VideoPolicyBlocked reports whether the suggestion can't be published because of the video
+// policy until a reviewer overrides it.
+func (s *Suggestion) VideoPolicyBlocked() bool {
+	return !s.VideoPolicyOverride && s.HasVideoPolicyIssues()
+}
+
+// SuggestionComment is a reviewer's question about a suggestion or the suggester's answer.
+type SuggestionComment struct {
+	AuthorID      int64     `bson:"author_id"`
+	FromSuggester bool      `bson:"from_suggester"` // False for reviewer questions
+	Text          string    `bson:"text"`
+	CreatedAt     time.Time `bson:"created_at"`
+}
+
+// TagCount is how many suggestions have a tag.
+type TagCount struct {
+	Tag   string `bson:"_id"`
+	Count int64  `bson:"count"`
+}
+
+// AdminActivity sums up what an admin did over a period, for /adminstats.
+type AdminActivity struct {
+	AdminID     int64  `bson:"_id"`
+	Username    string `bson:"username"` // Latest username the admin reviewed with; empty for direct posts only
+	Approved    int64  `bson:"approved"`
+	Rejected    int64  `bson:"rejected"`
+	DirectPosts int64  `bson:"-"` // Posts the admin sent to the channel through the bot
+	// AverageResponseMillis is the average time from submission to review of the reviewed suggestions
+	AverageResponseMillis float64 `bson:"average_response_ms"`
+}
+
+// Reviewed returns how many suggestions the admin reviewed.
+func (a AdminActivity) Reviewed() int64 {
+	return a.Approved + a.Rejected
+}
+
+// ForwardOrigin describes where a forwarded suggestion was originally posted.
+type ForwardOrigin struct {
+	Type         string    `bson:"type"`                    // user, hidden_user, chat, or channel
+	ChatID       int64     `bson:"chat_id,omitempty"`       // Source chat/channel (chat and channel origins)
+	ChatTitle    string    `bson:"chat_title,omitempty"`    // Title of the source chat/channel
+	ChatUsername string    `bson:"chat_username,omitempty"` // Public username of the source chat/channel, if any
+	MessageID    int       `bson:"message_id,omitempty"`    // Original post ID (channel origins)
+	SenderUserID int64     `bson:"sender_user_id,omitempty"`
+	SenderName   string    `bson:"sender_name,omitempty"` // User name, hidden user name, or author signature
+	Date         time.Time `bson:"date"`                  // When the original message was sent
+}
+
+// IsChannelRepost reports whether the suggestion is a repost from a channel or group chat.
+func (o *ForwardOrigin) IsChannelRepost() bool {
+	return o != nil && (o.Type == "channel" || o.Type == "chat")
+}
+
+// DisplayName returns a human-readable name of the source.
+func (o *ForwardOrigin) DisplayName() string {
+	switch {
+	case o.ChatUsername != "":
+		return "@" + o.ChatUsername
+	case o.ChatTitle != "":
+		return o.ChatTitle
+	default:
+		return o.SenderName
+	}
+}
+
+// Link returns a public t.me link to the original post, or "" if the source is not public.
+func (o *ForwardOrigin) Link() string {
+	if o.ChatUsername == "" {
+		return ""
+	}
+	if o.MessageID != 0 {
+		return fmt.Sprintf("https://t.me/%s/%d", o.ChatUsername, o.MessageID)
+	}
+	return "https://t.me/" + o.ChatUsername
 }
 
 // SuggestionStatus defines the possible states of a suggestion.
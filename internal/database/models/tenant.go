@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// TenantQuota limits what a connected channel may use. Zero fields use the defaults from the
+// configuration.
+type TenantQuota struct {
+	MaxPendingSuggestions int `bson:"max_pending_suggestions,omitempty"` // Suggestions waiting for review
+	UpdatesPerMinute      int `bson:"updates_per_minute,omitempty"`      // Updates processed; the rest are dropped
+}
+
+// Tenant is a channel connected to the bot by its owner in multi-tenant mode. Each tenant has its
+// own admins, settings, suggestion queue and post logs; the channel configured with CHANNEL_ID is
+// the primary tenant and isn't stored.
+type Tenant struct {
+	ChannelID int64       `bson:"_id"`
+	Title     string      `bson:"title,omitempty"`
+	OwnerID   int64       `bson:"owner_id"` // Creator of the channel, super admin of the tenant
+	CreatedAt time.Time   `bson:"created_at"`
+	Quota     TenantQuota `bson:"quota,omitempty"` // Set by the host operator with /tenants
+}
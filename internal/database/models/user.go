@@ -2,15 +2,56 @@ package models
 
 import "time"
 
+// Counters kept in the user profile, incremented with UserRepository.IncrementUserCounter.
+const (
+	UserCounterSuggestions = "suggestions_count" // Suggestions submitted
+	UserCounterApprovals   = "approvals_count"   // Suggestions approved by admins
+	UserCounterFeedback    = "feedback_count"    // Feedback messages sent
+)
+
+// Notification categories users can turn off with /notifications.
+const (
+	NotificationCategoryDecisions  = "decisions"  // Approval, rejection and expiry of their suggestions
+	NotificationCategoryDigests    = "digests"    // Digests of the channel's posts
+	NotificationCategoryBroadcasts = "broadcasts" // Announcements sent to all users
+)
+
+// NotificationCategories lists the notification categories in the order they are shown.
+var NotificationCategories = []string{NotificationCategoryDecisions, NotificationCategoryDigests, NotificationCategoryBroadcasts}
+
 // User represents a Telegram user with their activity information
 type User struct {
-	UserID       int64     `bson:"user_id"`
-	Username     string    `bson:"username,omitempty"`
-	FirstName    string    `bson:"first_name,omitempty"`
-	LastName     string    `bson:"last_name,omitempty"`
-	IsAdmin      bool      `bson:"is_admin"`
-	FirstSeen    time.Time `bson:"first_seen"`
-	LastSeen     time.Time `bson:"last_seen"`
-	ActionsCount int       `bson:"actions_count"`
-	LastAction   string    `bson:"last_action"`
+	UserID           int64     `bson:"user_id"`
+	Username         string    `bson:"username,omitempty"`
+	FirstName        string    `bson:"first_name,omitempty"`
+	LastName         string    `bson:"last_name,omitempty"`
+	IsAdmin          bool      `bson:"is_admin"`
+	IsBot            bool      `bson:"is_bot"`
+	IsPremium        bool      `bson:"is_premium"` // Only known when Telegram sends it, e.g. in private chats
+	FirstSeen        time.Time `bson:"first_seen"`
+	LastSeen         time.Time `bson:"last_seen"`
+	ActionsCount     int       `bson:"actions_count"`
+	LastAction       string    `bson:"last_action"`
+	Language         string    `bson:"language,omitempty"`     // Saved language preference (e.g., "en", "ru")
+	ClientLang       string    `bson:"client_lang,omitempty"`  // Language of the user's Telegram client, as last reported
+	SuggestionsCount int       `bson:"suggestions_count"`      // See UserCounterSuggestions
+	ApprovalsCount   int       `bson:"approvals_count"`        // See UserCounterApprovals
+	FeedbackCount    int       `bson:"feedback_count"`         // See UserCounterFeedback
+	ConsentedAt      time.Time `bson:"consented_at,omitempty"` // When the user agreed to the privacy notice; zero if they haven't
+	// MutedNotifications lists the NotificationCategories the user turned off
+	MutedNotifications []string `bson:"muted_notifications,omitempty"`
+}
+
+// NotificationsEnabled reports whether the user receives notifications of a category. Every
+// category is on until the user turns it off.
+func (u *User) NotificationsEnabled(category string) bool {
+	if u == nil {
+		return true
+	}
+	for _, muted := range u.MutedNotifications {
+		if muted == category {
+			return false
+		}
+	}
+	return true
 }
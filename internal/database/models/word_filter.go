@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FilteredWord is a word screened in suggestion captions and admin posts, managed with /wordfilter.
+type FilteredWord struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	ChannelID int64              `bson:"channel_id,omitempty"`
+	Word      string             `bson:"word"`     // Normalized, see wordfilter.Normalize; may end with "*"
+	Severity  string             `bson:"severity"` // "warn", "censor" or "block"
+	AddedBy   int64              `bson:"added_by"`
+	AddedAt   time.Time          `bson:"added_at"`
+}
+
+// Sources of filter hits.
+const (
+	FilterSourceSuggestion = "suggestion" // Caption of a suggestion
+	FilterSourcePost       = "post"       // Admin post sent to the channel through the bot
+)
+
+// FilterHit records a filtered word found in a text, so the word list can be tuned.
+type FilterHit struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	ChannelID int64              `bson:"channel_id,omitempty"`
+	Word      string             `bson:"word"`     // The entry that matched
+	Text      string             `bson:"text"`     // The word as written
+	Severity  string             `bson:"severity"` // Severity of the entry when it matched
+	Source    string             `bson:"source"`   // One of the FilterSource* constants
+	UserID    int64              `bson:"user_id"`
+	At        time.Time          `bson:"at"`
+}
+
+// FilterHitCount is how often a filtered word matched, for /wordfilter hits.
+type FilterHitCount struct {
+	Word  string `bson:"_id"`
+	Hits  int64  `bson:"hits"`
+	Users int64  `bson:"users"` // Distinct users whose texts matched
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 	"vrcmemes-bot/internal/database/models"
 
+	"github.com/mymmrac/telego"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -15,7 +16,8 @@ import (
 // MongoLogger implements logger interfaces using MongoDB.
 // It handles logging user actions, published posts, and updating user info.
 type MongoLogger struct {
-	db *mongo.Database
+	db        *mongo.Database
+	channelID int64 // Channel user actions are logged for in multi-tenant mode; zero if not set
 }
 
 // NewMongoLogger creates and returns a new MongoLogger instance.
@@ -24,6 +26,12 @@ func NewMongoLogger(db *mongo.Database) *MongoLogger {
 	return &MongoLogger{db: db}
 }
 
+// ForChannel returns a copy of the logger that records the channel of user actions, so /stats
+// of a channel in multi-tenant mode only counts its own. Users are shared by all channels.
+func (m *MongoLogger) ForChannel(scope ChannelScope) *MongoLogger {
+	return &MongoLogger{db: m.db, channelID: scope.ChannelID()}
+}
+
 // LogUserAction writes a user action log entry to the database.
 // It records the user ID, action type, additional details, and timestamp.
 func (m *MongoLogger) LogUserAction(userID int64, action string, details interface{}) error {
@@ -31,12 +39,16 @@ func (m *MongoLogger) LogUserAction(userID int64, action string, details interfa
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err := collection.InsertOne(ctx, map[string]interface{}{
+	entry := map[string]interface{}{
 		"user_id": userID,
 		"action":  action,
 		"details": details,
 		"time":    time.Now(),
-	})
+	}
+	if m.channelID != 0 {
+		entry["channel_id"] = m.channelID
+	}
+	_, err := collection.InsertOne(ctx, entry)
 
 	// Consider wrapping the error here as well for context
 	if err != nil {
@@ -64,21 +76,30 @@ func (m *MongoLogger) LogPublishedPost(logEntry models.PostLog) error {
 }
 
 // UpdateUser updates or inserts user information in the database.
-// It sets user details (username, names, admin status), timestamps, action counts,
-// and uses upsert to create the user if they don't exist.
-func (m *MongoLogger) UpdateUser(ctx context.Context, userID int64, username, firstName, lastName string, isAdmin bool, action string) error {
+// It sets user details (username, names, client language, bot/premium/admin flags), timestamps,
+// action counts, and uses upsert to create the user if they don't exist.
+func (m *MongoLogger) UpdateUser(ctx context.Context, user telego.User, isAdmin bool, action string) error {
 	collection := m.db.Collection("users")
+	userID := user.ID
 
 	now := time.Now()
+	set := bson.M{
+		"username":    user.Username,
+		"first_name":  user.FirstName,
+		"last_name":   user.LastName,
+		"is_admin":    isAdmin,
+		"is_bot":      user.IsBot,
+		"last_seen":   now,
+		"last_action": action,
+	}
+	if user.LanguageCode != "" {
+		set["client_lang"] = user.LanguageCode // Not always sent; keep the last known one
+	}
+	if user.IsPremium {
+		set["is_premium"] = true // Only sent when true, and not in every update
+	}
 	update := bson.M{
-		"$set": bson.M{
-			"username":    username,
-			"first_name":  firstName,
-			"last_name":   lastName,
-			"is_admin":    isAdmin,
-			"last_seen":   now,
-			"last_action": action,
-		},
+		"$set": set,
 		"$inc": bson.M{
 			"actions_count": 1,
 		},
@@ -101,3 +122,164 @@ func (m *MongoLogger) UpdateUser(ctx context.Context, userID int64, username, fi
 	}
 	return nil
 }
+
+// GetUser returns the stored profile of a user, or nil (and no error) if the user is unknown.
+func (m *MongoLogger) GetUser(ctx context.Context, userID int64) (*models.User, error) {
+	collection := m.db.Collection("users")
+
+	var user models.User
+	err := collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user %d: %w", userID, err)
+	}
+	return &user, nil
+}
+
+// GetUserByUsername retrieves a user by their username, ignoring case. It returns nil if no
+// known user has the username.
+func (m *MongoLogger) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	collection := m.db.Collection("users")
+
+	var user models.User
+	opts := options.FindOne().SetCollation(&options.Collation{Locale: "en", Strength: 2})
+	err := collection.FindOne(ctx, bson.M{"username": username}, opts).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user @%s: %w", username, err)
+	}
+	return &user, nil
+}
+
+// IncrementUserCounter increments a profile counter of the user, creating the user record if needed.
+func (m *MongoLogger) IncrementUserCounter(ctx context.Context, userID int64, counter string) error {
+	collection := m.db.Collection("users")
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"user_id": userID},
+		bson.M{
+			"$inc":         bson.M{counter: 1},
+			"$setOnInsert": bson.M{"user_id": userID, "first_seen": time.Now()},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to increment %s of user %d: %w", counter, userID, err)
+	}
+	return nil
+}
+
+// SetUserConsent records when the user agreed to the privacy notice, creating the user record if needed.
+func (m *MongoLogger) SetUserConsent(ctx context.Context, userID int64, at time.Time) error {
+	collection := m.db.Collection("users")
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"user_id": userID},
+		bson.M{
+			"$set":         bson.M{"consented_at": at},
+			"$setOnInsert": bson.M{"user_id": userID, "first_seen": at},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save consent of user %d: %w", userID, err)
+	}
+	return nil
+}
+
+// GetUserLanguage returns the language preference saved for the user.
+// It returns an empty string (and no error) if the user or the preference doesn't exist.
+func (m *MongoLogger) GetUserLanguage(ctx context.Context, userID int64) (string, error) {
+	collection := m.db.Collection("users")
+
+	var result struct {
+		Language string `bson:"language"`
+	}
+	err := collection.FindOne(
+		ctx,
+		bson.M{"user_id": userID},
+		options.FindOne().SetProjection(bson.M{"language": 1}),
+	).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get language for user %d: %w", userID, err)
+	}
+	return result.Language, nil
+}
+
+// SetUserLanguage saves the language preference for the user, creating the user record if needed.
+func (m *MongoLogger) SetUserLanguage(ctx context.Context, userID int64, lang string) error {
+	collection := m.db.Collection("users")
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"user_id": userID},
+		bson.M{
+			"$set":         bson.M{"language": lang},
+			"$setOnInsert": bson.M{"user_id": userID, "first_seen": time.Now()},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set language for user %d: %w", userID, err)
+	}
+	return nil
+}
+
+// SetNotificationMuted turns a notification category off or on for the user, creating the user
+// record if needed.
+func (m *MongoLogger) SetNotificationMuted(ctx context.Context, userID int64, category string, muted bool) error {
+	collection := m.db.Collection("users")
+
+	update := bson.M{"$setOnInsert": bson.M{"user_id": userID, "first_seen": time.Now()}}
+	if muted {
+		update["$addToSet"] = bson.M{"muted_notifications": category}
+	} else {
+		update["$pull"] = bson.M{"muted_notifications": category}
+	}
+	_, err := collection.UpdateOne(ctx, bson.M{"user_id": userID}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to set %s notifications for user %d: %w", category, userID, err)
+	}
+	return nil
+}
+
+// DeleteUser removes the stored profile of a user and the actions logged for them.
+func (m *MongoLogger) DeleteUser(ctx context.Context, userID int64) error {
+	if _, err := m.db.Collection("users").DeleteOne(ctx, bson.M{"user_id": userID}); err != nil {
+		return fmt.Errorf("failed to delete user %d: %w", userID, err)
+	}
+	if _, err := m.db.Collection("user_actions").DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+		return fmt.Errorf("failed to delete actions of user %d: %w", userID, err)
+	}
+	return nil
+}
+
+// GetUserFirstSeen returns when the user was first seen by the bot, or the zero time if the user is unknown.
+func (m *MongoLogger) GetUserFirstSeen(ctx context.Context, userID int64) (time.Time, error) {
+	collection := m.db.Collection("users")
+
+	var result struct {
+		FirstSeen time.Time `bson:"first_seen"`
+	}
+	err := collection.FindOne(
+		ctx,
+		bson.M{"user_id": userID},
+		options.FindOne().SetProjection(bson.M{"first_seen": 1}),
+	).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("failed to get first seen time for user %d: %w", userID, err)
+	}
+	return result.FirstSeen, nil
+}
@@ -2,7 +2,10 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 	"vrcmemes-bot/internal/database/models"
 
@@ -17,6 +20,7 @@ const suggestionCollectionName = "suggestions"
 // MongoSuggestionRepository implements SuggestionRepository for MongoDB.
 type MongoSuggestionRepository struct {
 	collection *mongo.Collection
+	scope      ChannelScope // Suggestions of one channel in multi-tenant mode; the zero value sees all
 }
 
 // NewMongoSuggestionRepository creates a new MongoDB suggestion repository.
@@ -26,24 +30,71 @@ func NewMongoSuggestionRepository(db *mongo.Database) *MongoSuggestionRepository
 	}
 }
 
+// ForChannel returns a copy of the repository that only sees and stores the suggestions of a
+// channel, for multi-tenant mode.
+func (r *MongoSuggestionRepository) ForChannel(scope ChannelScope) *MongoSuggestionRepository {
+	scoped := *r
+	scoped.scope = scope
+	return &scoped
+}
+
 // CreateSuggestion adds a new suggestion to the database.
+// It returns an error wrapping ErrRefCodeTaken if another suggestion has the same reference code.
 func (r *MongoSuggestionRepository) CreateSuggestion(ctx context.Context, suggestion *models.Suggestion) error {
-	suggestion.ID = primitive.NewObjectID() // Generate new ID if empty
-	suggestion.SubmittedAt = time.Now()
+	if suggestion.ID.IsZero() {
+		suggestion.ID = primitive.NewObjectID()
+	}
+	if suggestion.SubmittedAt.IsZero() {
+		suggestion.SubmittedAt = time.Now()
+	}
+	if suggestion.ChannelID == 0 {
+		suggestion.ChannelID = r.scope.ChannelID()
+	}
 
 	_, err := r.collection.InsertOne(ctx, suggestion)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) && strings.Contains(err.Error(), "ref_code") {
+			return fmt.Errorf("%w: %s: %w", ErrRefCodeTaken, suggestion.RefCode, err)
+		}
 		return fmt.Errorf("failed to insert suggestion: %w", err)
 	}
 	return nil
 }
 
-// GetPendingSuggestions retrieves a paginated list of suggestions with 'pending' status.
+// GetPendingSuggestions retrieves a paginated list of suggestions with 'pending' status, oldest first.
 func (r *MongoSuggestionRepository) GetPendingSuggestions(ctx context.Context, limit int, offset int) ([]models.Suggestion, int64, error) {
+	return r.findPendingSuggestions(ctx, nil, limit, offset, bson.D{{Key: "submitted_at", Value: 1}})
+}
+
+// GetPendingSuggestionsInOrder returns up to limit pending suggestions in the given order,
+// and how many suggestions are pending in total.
+func (r *MongoSuggestionRepository) GetPendingSuggestionsInOrder(ctx context.Context, limit int, order PendingOrder) ([]models.Suggestion, int64, error) {
+	sort := bson.D{{Key: "submitted_at", Value: 1}}
+	switch order {
+	case PendingNewestFirst:
+		sort = bson.D{{Key: "submitted_at", Value: -1}}
+	case PendingPriorityFirst:
+		sort = bson.D{{Key: "priority", Value: -1}, {Key: "submitted_at", Value: 1}}
+	}
+	return r.findPendingSuggestions(ctx, nil, limit, 0, sort)
+}
+
+// GetAssignedPendingSuggestions returns up to limit pending suggestions assigned to an admin,
+// oldest first, and how many are assigned to them in total.
+func (r *MongoSuggestionRepository) GetAssignedPendingSuggestions(ctx context.Context, adminID int64, limit int) ([]models.Suggestion, int64, error) {
+	return r.findPendingSuggestions(ctx, bson.M{"assigned_to": adminID}, limit, 0, bson.D{{Key: "submitted_at", Value: 1}})
+}
+
+// findPendingSuggestions returns a page of pending suggestions matching the extra filter (nil for
+// all) in the given sort order, and their total count.
+func (r *MongoSuggestionRepository) findPendingSuggestions(ctx context.Context, extraFilter bson.M, limit int, offset int, sort bson.D) ([]models.Suggestion, int64, error) {
 	filter := bson.M{"status": "pending"}
+	for key, value := range extraFilter {
+		filter[key] = value
+	}
 
 	// Get total count
-	totalCount, err := r.collection.CountDocuments(ctx, filter)
+	totalCount, err := r.collection.CountDocuments(ctx, r.scope.apply(filter))
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count pending suggestions: %w", err)
 	}
@@ -56,9 +107,9 @@ func (r *MongoSuggestionRepository) GetPendingSuggestions(ctx context.Context, l
 	findOptions := options.Find()
 	findOptions.SetLimit(int64(limit))
 	findOptions.SetSkip(int64(offset))
-	findOptions.SetSort(bson.D{{Key: "submitted_at", Value: 1}}) // Oldest first
+	findOptions.SetSort(sort)
 
-	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	cursor, err := r.collection.Find(ctx, r.scope.apply(filter), findOptions)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to find pending suggestions: %w", err)
 	}
@@ -78,7 +129,7 @@ func (r *MongoSuggestionRepository) GetSuggestionByID(ctx context.Context, id pr
 	var suggestion models.Suggestion
 	filter := bson.M{"_id": id}
 
-	err := r.collection.FindOne(ctx, filter).Decode(&suggestion)
+	err := r.collection.FindOne(ctx, r.scope.apply(filter)).Decode(&suggestion)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			// Return the specific error defined in the package
@@ -89,19 +140,39 @@ func (r *MongoSuggestionRepository) GetSuggestionByID(ctx context.Context, id pr
 	return &suggestion, nil
 }
 
+// GetSuggestionByRefCode retrieves a single suggestion by its reference code.
+// It returns ErrSuggestionNotFound if no suggestion matches the code.
+func (r *MongoSuggestionRepository) GetSuggestionByRefCode(ctx context.Context, refCode string) (*models.Suggestion, error) {
+	var suggestion models.Suggestion
+	filter := bson.M{"ref_code": refCode}
+
+	err := r.collection.FindOne(ctx, r.scope.apply(filter)).Decode(&suggestion)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrSuggestionNotFound
+		}
+		return nil, fmt.Errorf("failed to find suggestion by reference code %s: %w", refCode, err)
+	}
+	return &suggestion, nil
+}
+
 // UpdateSuggestionStatus updates the status, reviewer ID, and reviewer username of a suggestion.
 func (r *MongoSuggestionRepository) UpdateSuggestionStatus(ctx context.Context, id primitive.ObjectID, status string, reviewerID int64, reviewerUsername string) error {
 	filter := bson.M{"_id": id}
-	update := bson.M{
+	now := time.Now()
+	// An update pipeline, so the history entry can take the status it replaces
+	change := bson.M{"from": "$status", "to": bson.M{"$literal": status}, "by": reviewerID, "at": now}
+	update := bson.A{bson.M{
 		"$set": bson.M{
-			"status":            status,
+			"status":            bson.M{"$literal": status},
 			"reviewed_by":       reviewerID,
-			"reviewer_username": reviewerUsername,
-			"reviewed_at":       time.Now(),
+			"reviewer_username": bson.M{"$literal": reviewerUsername},
+			"reviewed_at":       now,
+			"history":           bson.M{"$concatArrays": bson.A{bson.M{"$ifNull": bson.A{"$history", bson.A{}}}, bson.A{change}}},
 		},
-	}
+	}}
 
-	result, err := r.collection.UpdateOne(ctx, filter, update)
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), update)
 	if err != nil {
 		return fmt.Errorf("failed to update suggestion status for ID %s: %w", id.Hex(), err)
 	}
@@ -116,7 +187,7 @@ func (r *MongoSuggestionRepository) UpdateSuggestionStatus(ctx context.Context,
 // DeleteSuggestion removes a suggestion from the database by ID.
 func (r *MongoSuggestionRepository) DeleteSuggestion(ctx context.Context, id primitive.ObjectID) error {
 	filter := bson.M{"_id": id}
-	result, err := r.collection.DeleteOne(ctx, filter)
+	result, err := r.collection.DeleteOne(ctx, r.scope.apply(filter))
 	if err != nil {
 		return fmt.Errorf("failed to delete suggestion with ID %s: %w", id.Hex(), err)
 	}
@@ -127,6 +198,533 @@ func (r *MongoSuggestionRepository) DeleteSuggestion(ctx context.Context, id pri
 	return nil
 }
 
+// GetSuggestionsBySuggester returns the most recent suggestions submitted by a user with the given status.
+func (r *MongoSuggestionRepository) GetSuggestionsBySuggester(ctx context.Context, suggesterID int64, status string, limit int) ([]models.Suggestion, error) {
+	filter := bson.M{"suggester_id": suggesterID, "status": status}
+	findOptions := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "submitted_at", Value: -1}}) // Newest first
+
+	cursor, err := r.collection.Find(ctx, r.scope.apply(filter), findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find suggestions of user %d: %w", suggesterID, err)
+	}
+	defer cursor.Close(ctx)
+
+	var suggestions []models.Suggestion
+	if err = cursor.All(ctx, &suggestions); err != nil {
+		return nil, fmt.Errorf("failed to decode suggestions of user %d: %w", suggesterID, err)
+	}
+	return suggestions, nil
+}
+
+// UpdateSuggestionCaption replaces the caption of a pending suggestion owned by suggesterID.
+// It returns ErrSuggestionNotEditable if no such pending suggestion exists.
+func (r *MongoSuggestionRepository) UpdateSuggestionCaption(ctx context.Context, id primitive.ObjectID, suggesterID int64, caption string) error {
+	filter := bson.M{"_id": id, "suggester_id": suggesterID, "status": string(models.StatusPending)}
+	update := bson.M{
+		"$set": bson.M{"caption": caption, "edited_at": time.Now()},
+		// The translation and filtered words were of the old caption
+		"$unset": bson.M{"translated_caption": "", "caption_language": "", "caption_choice": "", "filtered_words": ""},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), update)
+	if err != nil {
+		return fmt.Errorf("failed to update caption of suggestion %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotEditable
+	}
+	return nil
+}
+
+// AddSuggestionFile attaches another file to a pending suggestion owned by suggesterID.
+// It returns ErrSuggestionNotEditable if no such pending suggestion exists or it already has maxFiles files.
+func (r *MongoSuggestionRepository) AddSuggestionFile(ctx context.Context, id primitive.ObjectID, suggesterID int64, fileID string, maxFiles int) error {
+	filter := bson.M{
+		"_id":          id,
+		"suggester_id": suggesterID,
+		"status":       string(models.StatusPending),
+		// The array has fewer than maxFiles elements if index maxFiles-1 doesn't exist
+		fmt.Sprintf("file_ids.%d", maxFiles-1): bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$push": bson.M{"file_ids": fileID},
+		"$set":  bson.M{"edited_at": time.Now()},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), update)
+	if err != nil {
+		return fmt.Errorf("failed to add file to suggestion %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotEditable
+	}
+	return nil
+}
+
+// SetSuggestionTags replaces the tags of a pending suggestion.
+// It returns ErrSuggestionNotFound if no such pending suggestion exists.
+func (r *MongoSuggestionRepository) SetSuggestionTags(ctx context.Context, id primitive.ObjectID, tags []string) error {
+	filter := bson.M{"_id": id, "status": string(models.StatusPending)}
+	update := bson.M{"$set": bson.M{"tags": tags}}
+	if len(tags) == 0 {
+		update = bson.M{"$unset": bson.M{"tags": ""}}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), update)
+	if err != nil {
+		return fmt.Errorf("failed to set tags of suggestion %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotFound
+	}
+	return nil
+}
+
+// SetSuggestionCredit stores whether the suggester of a pending suggestion wants to be credited
+// when it is published. It returns ErrSuggestionNotEditable if the suggestion isn't theirs or isn't pending.
+func (r *MongoSuggestionRepository) SetSuggestionCredit(ctx context.Context, id primitive.ObjectID, suggesterID int64, credit bool) error {
+	filter := bson.M{"_id": id, "suggester_id": suggesterID, "status": string(models.StatusPending)}
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), bson.M{"$set": bson.M{"credit": credit}})
+	if err != nil {
+		return fmt.Errorf("failed to set credit of suggestion %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotEditable
+	}
+	return nil
+}
+
+// AddSuggestionComment appends a comment to the thread of a pending suggestion.
+// It returns ErrSuggestionNotFound if no such pending suggestion exists.
+func (r *MongoSuggestionRepository) AddSuggestionComment(ctx context.Context, id primitive.ObjectID, comment models.SuggestionComment) error {
+	filter := bson.M{"_id": id, "status": string(models.StatusPending)}
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), bson.M{"$push": bson.M{"comments": comment}})
+	if err != nil {
+		return fmt.Errorf("failed to add comment to suggestion %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotFound
+	}
+	return nil
+}
+
+// SetSuggestionRubric sets the rubric of a pending suggestion; an empty name clears it.
+// It returns ErrSuggestionNotFound if no such pending suggestion exists.
+func (r *MongoSuggestionRepository) SetSuggestionRubric(ctx context.Context, id primitive.ObjectID, rubric string) error {
+	filter := bson.M{"_id": id, "status": string(models.StatusPending)}
+	update := bson.M{"$set": bson.M{"rubric": rubric}}
+	if rubric == "" {
+		update = bson.M{"$unset": bson.M{"rubric": ""}}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), update)
+	if err != nil {
+		return fmt.Errorf("failed to set rubric of suggestion %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotFound
+	}
+	return nil
+}
+
+// SetSuggestionStyle sets the caption style of a pending suggestion; an empty name clears it.
+// It returns ErrSuggestionNotFound if no such pending suggestion exists.
+func (r *MongoSuggestionRepository) SetSuggestionStyle(ctx context.Context, id primitive.ObjectID, style string) error {
+	filter := bson.M{"_id": id, "status": string(models.StatusPending)}
+	update := bson.M{"$set": bson.M{"style": style}}
+	if style == "" {
+		update = bson.M{"$unset": bson.M{"style": ""}}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), update)
+	if err != nil {
+		return fmt.Errorf("failed to set style of suggestion %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotFound
+	}
+	return nil
+}
+
+// SetSuggestionProtectContent sets whether a pending suggestion is published with protected content.
+// It returns ErrSuggestionNotFound if no such pending suggestion exists.
+func (r *MongoSuggestionRepository) SetSuggestionProtectContent(ctx context.Context, id primitive.ObjectID, protect bool) error {
+	filter := bson.M{"_id": id, "status": string(models.StatusPending)}
+	update := bson.M{"$set": bson.M{"protect_content": protect}}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), update)
+	if err != nil {
+		return fmt.Errorf("failed to set content protection of suggestion %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotFound
+	}
+	return nil
+}
+
+// SetSuggestionVideoPolicyOverride sets whether a pending suggestion may be published although
+// its videos are outside the video policy.
+// It returns ErrSuggestionNotFound if no such pending suggestion exists.
+func (r *MongoSuggestionRepository) SetSuggestionVideoPolicyOverride(ctx context.Context, id primitive.ObjectID, override bool) error {
+	filter := bson.M{"_id": id, "status": string(models.StatusPending)}
+	update := bson.M{"$set": bson.M{"video_policy_override": override}}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), update)
+	if err != nil {
+		return fmt.Errorf("failed to set video policy override of suggestion %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotFound
+	}
+	return nil
+}
+
+// SetSuggestionThumbnail sets the thumbnail a pending suggestion's video is published with;
+// nil removes it. It returns ErrSuggestionNotFound if no such pending suggestion exists.
+func (r *MongoSuggestionRepository) SetSuggestionThumbnail(ctx context.Context, id primitive.ObjectID, thumbnail *models.VideoThumbnail) error {
+	filter := bson.M{"_id": id, "status": string(models.StatusPending)}
+	update := bson.M{"$set": bson.M{"thumbnail": thumbnail}}
+	if thumbnail == nil {
+		update = bson.M{"$unset": bson.M{"thumbnail": ""}}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), update)
+	if err != nil {
+		return fmt.Errorf("failed to set thumbnail of suggestion %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotFound
+	}
+	return nil
+}
+
+// SetSuggestionTranslation stores the translation of a suggestion's caption, whatever its status.
+func (r *MongoSuggestionRepository) SetSuggestionTranslation(ctx context.Context, id primitive.ObjectID, language, text string) error {
+	update := bson.M{"$set": bson.M{"translated_caption": text, "caption_language": language}}
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(bson.M{"_id": id}), update)
+	if err != nil {
+		return fmt.Errorf("failed to set caption translation of suggestion %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotFound
+	}
+	return nil
+}
+
+// SetSuggestionCaptionChoice sets which version of its translated caption a pending suggestion is
+// published with; empty publishes neither.
+// It returns ErrSuggestionNotFound if no such pending suggestion exists.
+func (r *MongoSuggestionRepository) SetSuggestionCaptionChoice(ctx context.Context, id primitive.ObjectID, choice string) error {
+	filter := bson.M{"_id": id, "status": string(models.StatusPending)}
+	update := bson.M{"$set": bson.M{"caption_choice": choice}}
+	if choice == "" {
+		update = bson.M{"$unset": bson.M{"caption_choice": ""}}
+	}
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), update)
+	if err != nil {
+		return fmt.Errorf("failed to set caption choice of suggestion %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotFound
+	}
+	return nil
+}
+
+// MarkSuggestionDuplicate rejects a pending suggestion as a duplicate of another one.
+// It returns ErrSuggestionNotFound if no such pending suggestion exists.
+func (r *MongoSuggestionRepository) MarkSuggestionDuplicate(ctx context.Context, id, duplicateOf primitive.ObjectID, reviewerID int64, reviewerUsername string) error {
+	filter := bson.M{"_id": id, "status": string(models.StatusPending)}
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"status":            string(models.StatusRejected),
+			"rejection_reason":  models.RejectionReasonDuplicate,
+			"duplicate_of":      duplicateOf,
+			"reviewed_by":       reviewerID,
+			"reviewer_username": reviewerUsername,
+			"reviewed_at":       now,
+		},
+		"$push": bson.M{"history": models.StatusChange{
+			From:   string(models.StatusPending),
+			To:     string(models.StatusRejected),
+			By:     reviewerID,
+			At:     now,
+			Reason: models.RejectionReasonDuplicate,
+		}},
+	}
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), update)
+	if err != nil {
+		return fmt.Errorf("failed to mark suggestion %s as duplicate: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotFound
+	}
+	return nil
+}
+
+// ReopenSuggestion puts a rejected suggestion back in the queue as pending, clearing its review.
+// It returns ErrSuggestionNotFound if there is no rejected suggestion with the ID.
+func (r *MongoSuggestionRepository) ReopenSuggestion(ctx context.Context, id primitive.ObjectID, adminID int64) error {
+	filter := bson.M{"_id": id, "status": string(models.StatusRejected)}
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"status":      string(models.StatusPending),
+			"reopened_at": now,
+		},
+		"$push": bson.M{"history": models.StatusChange{
+			From: string(models.StatusRejected),
+			To:   string(models.StatusPending),
+			By:   adminID,
+			At:   now,
+		}},
+		"$unset": bson.M{
+			"reviewed_by":       "",
+			"reviewer_username": "",
+			"reviewed_at":       "",
+			"rejection_reason":  "",
+			"duplicate_of":      "",
+		},
+	}
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), update)
+	if err != nil {
+		return fmt.Errorf("failed to reopen suggestion %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotFound
+	}
+	return nil
+}
+
+// ExpireSuggestions rejects up to limit pending suggestions submitted before the given time,
+// oldest first and except the excluded ones, as expired. It returns the expired suggestions.
+// Suggestions reopened after that time don't expire yet, and suggestions reviewed between
+// finding and expiring them are left alone and not returned.
+func (r *MongoSuggestionRepository) ExpireSuggestions(ctx context.Context, submittedBefore time.Time, exclude []primitive.ObjectID, limit int) ([]models.Suggestion, error) {
+	filter := bson.M{
+		"status":       string(models.StatusPending),
+		"submitted_at": bson.M{"$lt": submittedBefore},
+		"$or": bson.A{
+			bson.M{"reopened_at": bson.M{"$exists": false}},
+			bson.M{"reopened_at": bson.M{"$lt": submittedBefore}},
+		},
+	}
+	if len(exclude) > 0 {
+		filter["_id"] = bson.M{"$nin": exclude}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "submitted_at", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"_id": 1})
+	cursor, err := r.collection.Find(ctx, r.scope.apply(filter), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find expired suggestions: %w", err)
+	}
+	var found []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, fmt.Errorf("failed to decode expired suggestions: %w", err)
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+	ids := make([]primitive.ObjectID, len(found))
+	for i, f := range found {
+		ids[i] = f.ID
+	}
+
+	// The review time tells the suggestions expired by this call from those reviewed meanwhile
+	now := time.Now().Truncate(time.Millisecond)
+	update := bson.M{
+		"$set": bson.M{
+			"status":           string(models.StatusRejected),
+			"rejection_reason": models.RejectionReasonExpired,
+			"reviewed_at":      now,
+		},
+		"$push": bson.M{"history": models.StatusChange{
+			From:   string(models.StatusPending),
+			To:     string(models.StatusRejected),
+			At:     now,
+			Reason: models.RejectionReasonExpired,
+		}},
+	}
+	pending := bson.M{"_id": bson.M{"$in": ids}, "status": string(models.StatusPending)}
+	if _, err := r.collection.UpdateMany(ctx, r.scope.apply(pending), update); err != nil {
+		return nil, fmt.Errorf("failed to expire suggestions: %w", err)
+	}
+	expired := bson.M{"_id": bson.M{"$in": ids}, "rejection_reason": models.RejectionReasonExpired, "reviewed_at": now}
+	cursor, err = r.collection.Find(ctx, r.scope.apply(expired), options.Find().SetSort(bson.D{{Key: "submitted_at", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired suggestions: %w", err)
+	}
+	var suggestions []models.Suggestion
+	if err := cursor.All(ctx, &suggestions); err != nil {
+		return nil, fmt.Errorf("failed to decode expired suggestions: %w", err)
+	}
+	return suggestions, nil
+}
+
+// AddCoSuggesters appends the suggesters of merged duplicates to a pending suggestion.
+// It returns ErrSuggestionNotFound if no such pending suggestion exists.
+func (r *MongoSuggestionRepository) AddCoSuggesters(ctx context.Context, id primitive.ObjectID, coSuggesters []models.CoSuggester) error {
+	filter := bson.M{"_id": id, "status": string(models.StatusPending)}
+	update := bson.M{"$push": bson.M{"co_suggesters": bson.M{"$each": coSuggesters}}}
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), update)
+	if err != nil {
+		return fmt.Errorf("failed to add co-suggesters to suggestion %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotFound
+	}
+	return nil
+}
+
+// SetSuggestionOCR stores the text recognized in a suggestion's photos, whatever its status.
+func (r *MongoSuggestionRepository) SetSuggestionOCR(ctx context.Context, id primitive.ObjectID, text, key, similarTo string) error {
+	set := bson.M{"ocr_text": text}
+	if key != "" {
+		set["ocr_key"] = key
+	}
+	if similarTo != "" {
+		set["similar_to"] = similarTo
+	}
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(bson.M{"_id": id}), bson.M{"$set": set})
+	if err != nil {
+		return fmt.Errorf("failed to set recognized text of suggestion %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotFound
+	}
+	return nil
+}
+
+// FindSuggestionByOCRKey returns the most recent pending or approved suggestion other than exclude
+// whose recognized text has the given key. It returns ErrSuggestionNotFound if there is none.
+func (r *MongoSuggestionRepository) FindSuggestionByOCRKey(ctx context.Context, key string, exclude primitive.ObjectID) (*models.Suggestion, error) {
+	filter := bson.M{
+		"ocr_key": key,
+		"_id":     bson.M{"$ne": exclude},
+		"status":  bson.M{"$in": bson.A{string(models.StatusPending), string(models.StatusApproved)}},
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "submitted_at", Value: -1}})
+	var suggestion models.Suggestion
+	err := r.collection.FindOne(ctx, r.scope.apply(filter), opts).Decode(&suggestion)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrSuggestionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find suggestion by recognized text: %w", err)
+	}
+	return &suggestion, nil
+}
+
+// DeleteTestSuggestions removes the test suggestions of /simulate and returns how many there were.
+func (r *MongoSuggestionRepository) DeleteTestSuggestions(ctx context.Context) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, r.scope.apply(bson.M{"test": true}))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete test suggestions: %w", err)
+	}
+	return result.DeletedCount, nil
+}
+
+// SearchSuggestions returns up to limit suggestions, most recent first, whose caption or
+// recognized text contains query, ignoring case.
+func (r *MongoSuggestionRepository) SearchSuggestions(ctx context.Context, query string, limit int) ([]models.Suggestion, error) {
+	pattern := primitive.Regex{Pattern: regexp.QuoteMeta(query), Options: "i"}
+	filter := bson.M{"$or": bson.A{
+		bson.M{"caption": pattern},
+		bson.M{"ocr_text": pattern},
+	}}
+	opts := options.Find().SetSort(bson.D{{Key: "submitted_at", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, r.scope.apply(filter), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search suggestions: %w", err)
+	}
+	defer cursor.Close(ctx)
+	var suggestions []models.Suggestion
+	if err := cursor.All(ctx, &suggestions); err != nil {
+		return nil, fmt.Errorf("failed to decode found suggestions: %w", err)
+	}
+	return suggestions, nil
+}
+
+// SetSuggestionPreview records the preview channel messages of an approved suggestion.
+// It returns ErrSuggestionNotFound if no such approved suggestion exists.
+func (r *MongoSuggestionRepository) SetSuggestionPreview(ctx context.Context, id primitive.ObjectID, chatID int64, messageIDs []int) error {
+	filter := bson.M{"_id": id, "status": string(models.StatusApproved)}
+	update := bson.M{"$set": bson.M{"preview_chat_id": chatID, "preview_message_ids": messageIDs}}
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), update)
+	if err != nil {
+		return fmt.Errorf("failed to set preview of suggestion %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotFound
+	}
+	return nil
+}
+
+// SetSuggestionPublished records the channel message an approved suggestion was published as.
+// It returns ErrSuggestionNotFound if no such approved suggestion exists.
+func (r *MongoSuggestionRepository) SetSuggestionPublished(ctx context.Context, id primitive.ObjectID, messageID int) error {
+	filter := bson.M{"_id": id, "status": string(models.StatusApproved)}
+	update := bson.M{"$set": bson.M{"published_message_id": messageID}}
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), update)
+	if err != nil {
+		return fmt.Errorf("failed to set published message of suggestion %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotFound
+	}
+	return nil
+}
+
+// AssignSuggestion assigns a pending suggestion to an admin for review.
+// It returns ErrSuggestionNotFound if no such pending suggestion exists.
+func (r *MongoSuggestionRepository) AssignSuggestion(ctx context.Context, id primitive.ObjectID, adminID int64) error {
+	filter := bson.M{"_id": id, "status": string(models.StatusPending)}
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{"assigned_to": adminID, "assigned_at": now},
+		"$push": bson.M{"history": models.StatusChange{
+			From:     string(models.StatusPending),
+			To:       string(models.StatusPending),
+			At:       now,
+			Reason:   models.HistoryReasonAssigned,
+			Assignee: adminID,
+		}},
+	}
+	result, err := r.collection.UpdateOne(ctx, r.scope.apply(filter), update)
+	if err != nil {
+		return fmt.Errorf("failed to assign suggestion %s to admin %d: %w", id.Hex(), adminID, err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSuggestionNotFound
+	}
+	return nil
+}
+
+// GetStaleAssignments returns up to limit pending suggestions assigned before the given time, oldest assignment first.
+func (r *MongoSuggestionRepository) GetStaleAssignments(ctx context.Context, assignedBefore time.Time, limit int) ([]models.Suggestion, error) {
+	filter := bson.M{
+		"status":      string(models.StatusPending),
+		"assigned_to": bson.M{"$gt": 0},
+		"assigned_at": bson.M{"$lt": assignedBefore},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "assigned_at", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, r.scope.apply(filter), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stale assignments: %w", err)
+	}
+	var suggestions []models.Suggestion
+	if err := cursor.All(ctx, &suggestions); err != nil {
+		return nil, fmt.Errorf("failed to decode stale assignments: %w", err)
+	}
+	return suggestions, nil
+}
+
 // ResetDailyLimits is intended to reset daily submission counters if they exist in the Suggestion model.
 // Currently, the Suggestion model doesn't have daily limit fields, so this is a placeholder.
 func (r *MongoSuggestionRepository) ResetDailyLimits(ctx context.Context) error {
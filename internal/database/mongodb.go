@@ -18,11 +18,12 @@ import (
 
 // ConnectDB establishes a connection to the MongoDB database using the provided configuration.
 // It returns the MongoDB client, database object, and an error if connection fails.
-func ConnectDB(cfg *config.Config) (*mongo.Client, *mongo.Database, error) {
+// Extra client options, such as a command monitor, are applied after the configured ones.
+func ConnectDB(cfg *config.Config, extra ...*options.ClientOptions) (*mongo.Client, *mongo.Database, error) {
 	serverAPI := options.ServerAPI(options.ServerAPIVersion1)
 	opts := options.Client().ApplyURI(cfg.MongoDBURI).SetServerAPIOptions(serverAPI)
 
-	client, err := mongo.Connect(context.TODO(), opts)
+	client, err := mongo.Connect(context.TODO(), append([]*options.ClientOptions{opts}, extra...)...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
@@ -39,3 +40,18 @@ func ConnectDB(cfg *config.Config) (*mongo.Client, *mongo.Database, error) {
 
 	return client, db, nil
 }
+
+// mongoPinger pings the MongoDB server of a client.
+type mongoPinger struct {
+	client *mongo.Client
+}
+
+// NewPinger returns a Pinger for the MongoDB server the client is connected to.
+func NewPinger(client *mongo.Client) Pinger {
+	return &mongoPinger{client: client}
+}
+
+// Ping sends the same ping command as ConnectDB.
+func (p *mongoPinger) Ping(ctx context.Context) error {
+	return p.client.Database("admin").RunCommand(ctx, bson.D{{Key: "ping", Value: 1}}).Err()
+}
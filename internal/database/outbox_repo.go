@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// outboxCollectionName is the collection of notifications waiting for delivery.
+const outboxCollectionName = "outbox"
+
+// outboxRepository is a MongoDB implementation of OutboxRepository.
+type outboxRepository struct {
+	collection *mongo.Collection
+}
+
+// NewOutboxRepository creates a new instance of outboxRepository.
+func NewOutboxRepository(db *mongo.Database) OutboxRepository {
+	return &outboxRepository{
+		collection: db.Collection(outboxCollectionName),
+	}
+}
+
+// EnqueueNotification stores a new pending notification, due right away.
+func (r *outboxRepository) EnqueueNotification(ctx context.Context, notification *models.Notification) error {
+	now := time.Now()
+	if notification.ID.IsZero() {
+		notification.ID = primitive.NewObjectID()
+	}
+	notification.Status = models.NotificationStatusPending
+	notification.CreatedAt = now
+	notification.NextAttemptAt = now
+	if _, err := r.collection.InsertOne(ctx, notification); err != nil {
+		return fmt.Errorf("failed to enqueue %s notification for chat %d: %w", notification.Kind, notification.ChatID, err)
+	}
+	return nil
+}
+
+// ClaimDueNotification claims the oldest due notification. Notifications left in "sending" by a
+// dispatcher that stopped mid-delivery become due again once their lease expires.
+func (r *outboxRepository) ClaimDueNotification(ctx context.Context, now time.Time, lease time.Duration) (*models.Notification, error) {
+	filter := bson.M{
+		"status":          bson.M{"$in": bson.A{models.NotificationStatusPending, models.NotificationStatusSending}},
+		"next_attempt_at": bson.M{"$lte": now},
+	}
+	update := bson.M{
+		"$set": bson.M{"status": models.NotificationStatusSending, "next_attempt_at": now.Add(lease)},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var notification models.Notification
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&notification)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due notification: %w", err)
+	}
+	return &notification, nil
+}
+
+// CompleteNotification marks a notification as sent.
+func (r *outboxRepository) CompleteNotification(ctx context.Context, id primitive.ObjectID) error {
+	update := bson.M{
+		"$set":   bson.M{"status": models.NotificationStatusSent, "sent_at": time.Now()},
+		"$unset": bson.M{"error": ""},
+	}
+	if _, err := r.collection.UpdateByID(ctx, id, update); err != nil {
+		return fmt.Errorf("failed to complete notification %s: %w", id.Hex(), err)
+	}
+	return nil
+}
+
+// RetryNotification makes a notification pending again at the given time.
+func (r *outboxRepository) RetryNotification(ctx context.Context, id primitive.ObjectID, at time.Time, cause error) error {
+	update := bson.M{"$set": bson.M{
+		"status":          models.NotificationStatusPending,
+		"next_attempt_at": at,
+		"error":           cause.Error(),
+	}}
+	if _, err := r.collection.UpdateByID(ctx, id, update); err != nil {
+		return fmt.Errorf("failed to reschedule notification %s: %w", id.Hex(), err)
+	}
+	return nil
+}
+
+// FailNotification gives up on a notification.
+func (r *outboxRepository) FailNotification(ctx context.Context, id primitive.ObjectID, cause error) error {
+	update := bson.M{"$set": bson.M{"status": models.NotificationStatusFailed, "error": cause.Error()}}
+	if _, err := r.collection.UpdateByID(ctx, id, update); err != nil {
+		return fmt.Errorf("failed to record failed notification %s: %w", id.Hex(), err)
+	}
+	return nil
+}
+
+// CountNotifications counts the notifications with any of the given statuses.
+func (r *outboxRepository) CountNotifications(ctx context.Context, statuses ...string) (int64, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"status": bson.M{"$in": statuses}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// permissionCollectionName is the collection of per-command permissions granted with /perm.
+const permissionCollectionName = "permissions"
+
+// permissionRepository is a MongoDB implementation of PermissionRepository.
+type permissionRepository struct {
+	collection *mongo.Collection
+	scope      ChannelScope // Permissions of one channel in multi-tenant mode; the zero value sees all
+}
+
+// NewPermissionRepository creates a new instance of permissionRepository.
+func NewPermissionRepository(db *mongo.Database) PermissionRepository {
+	return &permissionRepository{
+		collection: db.Collection(permissionCollectionName),
+	}
+}
+
+// NewChannelPermissionRepository creates a permission repository that only sees the permissions
+// of one channel, for multi-tenant mode.
+func NewChannelPermissionRepository(db *mongo.Database, scope ChannelScope) PermissionRepository {
+	return &permissionRepository{
+		collection: db.Collection(permissionCollectionName),
+		scope:      scope,
+	}
+}
+
+// grantee returns the filter matching the permissions of a command granted to a user or a role.
+func (r *permissionRepository) grantee(command string, userID int64, role string) bson.M {
+	filter := bson.M{"command": command}
+	if role != "" {
+		filter["role"] = role
+	} else {
+		filter["user_id"] = userID
+	}
+	return r.scope.apply(filter)
+}
+
+// GrantPermission stores a permission. Granting a permission that exists already keeps it.
+func (r *permissionRepository) GrantPermission(ctx context.Context, permission *models.CommandPermission) error {
+	if permission.GrantedAt.IsZero() {
+		permission.GrantedAt = time.Now()
+	}
+	if permission.ChannelID == 0 {
+		permission.ChannelID = r.scope.ChannelID()
+	}
+	filter := r.grantee(permission.Command, permission.UserID, permission.Role)
+	update := bson.M{"$setOnInsert": permission}
+	if _, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true)); err != nil {
+		return fmt.Errorf("failed to grant %s: %w", permission.Command, err)
+	}
+	return nil
+}
+
+// RevokePermission deletes the permission of a command granted to a user, or to a role if role
+// isn't empty. It returns ErrPermissionNotFound if there is none.
+func (r *permissionRepository) RevokePermission(ctx context.Context, command string, userID int64, role string) error {
+	result, err := r.collection.DeleteMany(ctx, r.grantee(command, userID, role))
+	if err != nil {
+		return fmt.Errorf("failed to revoke %s: %w", command, err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrPermissionNotFound
+	}
+	return nil
+}
+
+// ListPermissions returns the granted permissions by command, in the order they were granted.
+func (r *permissionRepository) ListPermissions(ctx context.Context) ([]models.CommandPermission, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "command", Value: 1}, {Key: "granted_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, r.scope.apply(bson.M{}), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list permissions: %w", err)
+	}
+	var permissions []models.CommandPermission
+	if err := cursor.All(ctx, &permissions); err != nil {
+		return nil, fmt.Errorf("failed to decode permissions: %w", err)
+	}
+	return permissions, nil
+}
+
+// HasPermission reports whether the user, or every user, was granted the command.
+func (r *permissionRepository) HasPermission(ctx context.Context, command string, userID int64) (bool, error) {
+	filter := r.scope.apply(bson.M{
+		"command": command,
+		"$or":     bson.A{bson.M{"user_id": userID}, bson.M{"role": models.PermissionRoleEveryone}},
+	})
+	count, err := r.collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, fmt.Errorf("failed to check permission of %s: %w", command, err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// pinCollectionName is the collection of channel posts pinned for a limited time.
+const pinCollectionName = "pins"
+
+// pinRepository is a MongoDB implementation of PinRepository.
+type pinRepository struct {
+	collection *mongo.Collection
+	scope      ChannelScope // Pins of one channel in multi-tenant mode; the zero value sees all
+}
+
+// NewPinRepository creates a new instance of pinRepository.
+func NewPinRepository(db *mongo.Database) PinRepository {
+	return &pinRepository{
+		collection: db.Collection(pinCollectionName),
+	}
+}
+
+// NewChannelPinRepository creates a pin repository that only sees the pins of one channel, for
+// multi-tenant mode.
+func NewChannelPinRepository(db *mongo.Database, scope ChannelScope) PinRepository {
+	return &pinRepository{
+		collection: db.Collection(pinCollectionName),
+		scope:      scope,
+	}
+}
+
+// CreatePin stores a queued or pinned post.
+func (r *pinRepository) CreatePin(ctx context.Context, pin *models.ScheduledPin) error {
+	if pin.ID.IsZero() {
+		pin.ID = primitive.NewObjectID()
+	}
+	if pin.CreatedAt.IsZero() {
+		pin.CreatedAt = time.Now()
+	}
+	if _, err := r.collection.InsertOne(ctx, pin); err != nil {
+		return fmt.Errorf("failed to store pin of post %d: %w", pin.ChannelPostID, err)
+	}
+	return nil
+}
+
+// ListPins returns the pinned and queued posts. "pinned" sorts before "queued", so the pinned
+// post comes first.
+func (r *pinRepository) ListPins(ctx context.Context) ([]models.ScheduledPin, error) {
+	filter := r.scope.apply(bson.M{"status": bson.M{"$in": bson.A{models.PinStatusPinned, models.PinStatusQueued}}})
+	opts := options.Find().SetSort(bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pins: %w", err)
+	}
+	var pins []models.ScheduledPin
+	if err := cursor.All(ctx, &pins); err != nil {
+		return nil, fmt.Errorf("failed to decode pins: %w", err)
+	}
+	return pins, nil
+}
+
+// DuePins returns the pinned posts whose time is up at now.
+func (r *pinRepository) DuePins(ctx context.Context, now time.Time) ([]models.ScheduledPin, error) {
+	filter := r.scope.apply(bson.M{"status": models.PinStatusPinned, "unpin_at": bson.M{"$lte": now}})
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find due pins: %w", err)
+	}
+	var pins []models.ScheduledPin
+	if err := cursor.All(ctx, &pins); err != nil {
+		return nil, fmt.Errorf("failed to decode due pins: %w", err)
+	}
+	return pins, nil
+}
+
+// NextQueuedPin returns the queued post queued first, or nil if there is none.
+func (r *pinRepository) NextQueuedPin(ctx context.Context) (*models.ScheduledPin, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	var pin models.ScheduledPin
+	err := r.collection.FindOne(ctx, r.scope.apply(bson.M{"status": models.PinStatusQueued}), opts).Decode(&pin)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next queued pin: %w", err)
+	}
+	return &pin, nil
+}
+
+// MarkPinned records that a queued post was pinned until unpinAt.
+func (r *pinRepository) MarkPinned(ctx context.Context, id primitive.ObjectID, pinnedAt, unpinAt time.Time) error {
+	update := bson.M{"$set": bson.M{"status": models.PinStatusPinned, "pinned_at": pinnedAt, "unpin_at": unpinAt}}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "status": models.PinStatusQueued}, update)
+	if err != nil {
+		return fmt.Errorf("failed to mark pin %s as pinned: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrPinNotFound
+	}
+	return nil
+}
+
+// MarkUnpinned ends a pinned or queued post. It returns ErrPinNotFound if it already ended.
+func (r *pinRepository) MarkUnpinned(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	filter := bson.M{"_id": id, "status": bson.M{"$in": bson.A{models.PinStatusPinned, models.PinStatusQueued}}}
+	update := bson.M{"$set": bson.M{"status": models.PinStatusUnpinned, "unpinned_at": at}}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to mark pin %s as unpinned: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrPinNotFound
+	}
+	return nil
+}
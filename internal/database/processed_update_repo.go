@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// processedUpdateRepository is a MongoDB implementation of ProcessedUpdateStore.
+type processedUpdateRepository struct {
+	collection *mongo.Collection
+}
+
+// NewProcessedUpdateRepository creates a new instance of processedUpdateRepository.
+func NewProcessedUpdateRepository(db *mongo.Database) ProcessedUpdateStore {
+	return &processedUpdateRepository{
+		collection: db.Collection("processed_updates"),
+	}
+}
+
+// ClaimUpdate records an update ID as being processed, using it as the document ID so a resent
+// update fails with a duplicate key error. That update can still take over the claim once it has
+// expired. Records written before claims existed have no "done" field and count as processed.
+func (r *processedUpdateRepository) ClaimUpdate(ctx context.Context, updateID int, lease time.Duration) (bool, error) {
+	now := time.Now()
+	_, err := r.collection.InsertOne(ctx, bson.M{
+		"_id":           updateID,
+		"processed_at":  now,
+		"done":          false,
+		"claimed_until": now.Add(lease),
+	})
+	if err == nil {
+		return true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return false, fmt.Errorf("failed to claim update %d: %w", updateID, err)
+	}
+
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": updateID, "done": false, "claimed_until": bson.M{"$lte": now}},
+		bson.M{"$set": bson.M{"processed_at": now, "claimed_until": now.Add(lease)}},
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to take over the expired claim of update %d: %w", updateID, err)
+	}
+	return res.MatchedCount == 1, nil
+}
+
+// CompleteUpdate marks a claimed update as processed. Retention is counted from now on.
+func (r *processedUpdateRepository) CompleteUpdate(ctx context.Context, updateID int) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": updateID},
+		bson.M{"$set": bson.M{"done": true, "processed_at": time.Now()}, "$unset": bson.M{"claimed_until": ""}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark update %d as processed: %w", updateID, err)
+	}
+	return nil
+}
+
+// ReleaseUpdate deletes the claim of an update that wasn't processed.
+func (r *processedUpdateRepository) ReleaseUpdate(ctx context.Context, updateID int) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": updateID, "done": false}); err != nil {
+		return fmt.Errorf("failed to release update %d: %w", updateID, err)
+	}
+	return nil
+}
+
+// EnsureRetention creates a TTL index so processed update IDs are removed after the given duration.
+func (r *processedUpdateRepository) EnsureRetention(ctx context.Context, retention time.Duration) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "processed_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(retention.Seconds())),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create processed updates TTL index: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// publicationRepository is a MongoDB implementation of PublicationStore.
+type publicationRepository struct {
+	collection *mongo.Collection
+	scope      ChannelScope // Connected channel in multi-tenant mode; keys are prefixed with it
+}
+
+// NewPublicationRepository creates a new instance of publicationRepository.
+func NewPublicationRepository(db *mongo.Database) PublicationStore {
+	return &publicationRepository{
+		collection: db.Collection("publications"),
+	}
+}
+
+// NewChannelPublicationRepository creates a publication store for a channel in multi-tenant mode,
+// so content published to one channel can still be published to another.
+func NewChannelPublicationRepository(db *mongo.Database, scope ChannelScope) PublicationStore {
+	return &publicationRepository{
+		collection: db.Collection("publications"),
+		scope:      scope,
+	}
+}
+
+// BeginPublication marks a publication as pending before it is sent. The upsert only matches
+// records that are not yet published, so for a published key it fails with a duplicate key
+// error on _id, which is reported as alreadyPublished.
+func (r *publicationRepository) BeginPublication(ctx context.Context, key string) (bool, error) {
+	now := time.Now()
+	filter := bson.M{"_id": r.scope.keyPrefix + key, "status": bson.M{"$ne": models.PublicationStatusPublished}}
+	onInsert := bson.M{"created_at": now}
+	if channelID := r.scope.ChannelID(); channelID != 0 {
+		onInsert["channel_id"] = channelID
+	}
+	update := bson.M{
+		"$set":         bson.M{"status": models.PublicationStatusPending, "attempted_at": now},
+		"$setOnInsert": onInsert,
+	}
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to begin publication %s: %w", key, err)
+	}
+	return false, nil
+}
+
+// CompletePublication marks a publication as successfully published.
+func (r *publicationRepository) CompletePublication(ctx context.Context, key string) error {
+	update := bson.M{
+		"$set":   bson.M{"status": models.PublicationStatusPublished, "published_at": time.Now()},
+		"$unset": bson.M{"error": ""},
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": r.scope.keyPrefix + key}, update)
+	if err != nil {
+		return fmt.Errorf("failed to complete publication %s: %w", key, err)
+	}
+	return nil
+}
+
+// FailPublication marks a publication as failed, so a later attempt may send it again.
+func (r *publicationRepository) FailPublication(ctx context.Context, key string, cause error) error {
+	update := bson.M{"$set": bson.M{"status": models.PublicationStatusFailed, "error": cause.Error()}}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": r.scope.keyPrefix + key}, update)
+	if err != nil {
+		return fmt.Errorf("failed to record failed publication %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// rubricRepository is a MongoDB implementation of RubricRepository.
+type rubricRepository struct {
+	collection *mongo.Collection
+	scope      ChannelScope // Rubrics of one channel in multi-tenant mode; the zero value sees all
+}
+
+// NewRubricRepository creates a new instance of rubricRepository.
+func NewRubricRepository(db *mongo.Database) RubricRepository {
+	return &rubricRepository{
+		collection: db.Collection("rubrics"),
+	}
+}
+
+// NewChannelRubricRepository creates a rubric repository that only sees and stores the rubrics of
+// one channel, for multi-tenant mode. Connected channels can use the same names as the primary one.
+func NewChannelRubricRepository(db *mongo.Database, scope ChannelScope) RubricRepository {
+	return &rubricRepository{
+		collection: db.Collection("rubrics"),
+		scope:      scope,
+	}
+}
+
+// filter returns the filter matching the rubric with the given name in the repository's scope.
+func (r *rubricRepository) filter(name string) bson.M {
+	return r.scope.apply(bson.M{"_id": r.scope.keyPrefix + name})
+}
+
+// decode strips the channel prefix from the name of a rubric read from the database.
+func (r *rubricRepository) decode(rubric *models.Rubric) {
+	rubric.Name = strings.TrimPrefix(rubric.Name, r.scope.keyPrefix)
+}
+
+// CreateRubric stores a new rubric. It returns ErrRubricExists if the name is taken.
+func (r *rubricRepository) CreateRubric(ctx context.Context, rubric *models.Rubric) error {
+	if rubric.CreatedAt.IsZero() {
+		rubric.CreatedAt = time.Now()
+	}
+	stored := *rubric
+	stored.Name = r.scope.keyPrefix + rubric.Name
+	stored.ChannelID = r.scope.ChannelID()
+	if _, err := r.collection.InsertOne(ctx, stored); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrRubricExists
+		}
+		return fmt.Errorf("failed to create rubric %s: %w", rubric.Name, err)
+	}
+	return nil
+}
+
+// GetRubric returns a rubric, or nil if it doesn't exist.
+func (r *rubricRepository) GetRubric(ctx context.Context, name string) (*models.Rubric, error) {
+	var rubric models.Rubric
+	err := r.collection.FindOne(ctx, r.filter(name)).Decode(&rubric)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get rubric %s: %w", name, err)
+	}
+	r.decode(&rubric)
+	return &rubric, nil
+}
+
+// ListRubrics returns all rubrics sorted by name.
+func (r *rubricRepository) ListRubrics(ctx context.Context) ([]models.Rubric, error) {
+	cursor, err := r.collection.Find(ctx, r.scope.apply(bson.M{}), options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rubrics: %w", err)
+	}
+	var rubrics []models.Rubric
+	if err := cursor.All(ctx, &rubrics); err != nil {
+		return nil, fmt.Errorf("failed to decode rubrics: %w", err)
+	}
+	for i := range rubrics {
+		r.decode(&rubrics[i])
+	}
+	return rubrics, nil
+}
+
+// DeleteRubric removes a rubric. It returns ErrRubricNotFound if it doesn't exist.
+func (r *rubricRepository) DeleteRubric(ctx context.Context, name string) error {
+	result, err := r.collection.DeleteOne(ctx, r.filter(name))
+	if err != nil {
+		return fmt.Errorf("failed to delete rubric %s: %w", name, err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrRubricNotFound
+	}
+	return nil
+}
+
+// SetRubricCounter sets the number of the last post of a rubric, e.g. to continue an existing series.
+// It returns ErrRubricNotFound if the rubric doesn't exist.
+func (r *rubricRepository) SetRubricCounter(ctx context.Context, name string, counter int64) error {
+	result, err := r.collection.UpdateOne(ctx, r.filter(name), bson.M{"$set": bson.M{"counter": counter}})
+	if err != nil {
+		return fmt.Errorf("failed to set counter of rubric %s: %w", name, err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrRubricNotFound
+	}
+	return nil
+}
+
+// SetRubricReaction sets the emoji the bot reacts with to the posts of a rubric; "" stops reacting.
+func (r *rubricRepository) SetRubricReaction(ctx context.Context, name, emoji string) error {
+	return r.setOptional(ctx, name, "reaction", emoji)
+}
+
+// SetRubricPrompt sets the comment posted under the posts of a rubric; "" stops commenting.
+func (r *rubricRepository) SetRubricPrompt(ctx context.Context, name, prompt string) error {
+	return r.setOptional(ctx, name, "prompt", prompt)
+}
+
+// setOptional sets an optional field of a rubric, or unsets it if value is empty.
+func (r *rubricRepository) setOptional(ctx context.Context, name, field, value string) error {
+	update := bson.M{"$set": bson.M{field: value}}
+	if value == "" {
+		update = bson.M{"$unset": bson.M{field: ""}}
+	}
+	result, err := r.collection.UpdateOne(ctx, r.filter(name), update)
+	if err != nil {
+		return fmt.Errorf("failed to set %s of rubric %s: %w", field, name, err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrRubricNotFound
+	}
+	return nil
+}
+
+// NextRubricNumber atomically increments the counter of a rubric and returns the rubric with
+// the new number in Counter. It returns ErrRubricNotFound if the rubric doesn't exist.
+func (r *rubricRepository) NextRubricNumber(ctx context.Context, name string) (*models.Rubric, error) {
+	var rubric models.Rubric
+	err := r.collection.FindOneAndUpdate(ctx,
+		r.filter(name),
+		bson.M{"$inc": bson.M{"counter": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&rubric)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRubricNotFound
+		}
+		return nil, fmt.Errorf("failed to take the next number of rubric %s: %w", name, err)
+	}
+	r.decode(&rubric)
+	return &rubric, nil
+}
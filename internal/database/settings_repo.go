@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// settingsRepository is a MongoDB implementation of SettingsRepository.
+type settingsRepository struct {
+	collection *mongo.Collection
+	scope      ChannelScope // Connected channel in multi-tenant mode; settings keys are prefixed with it
+}
+
+// NewSettingsRepository creates a new instance of settingsRepository.
+func NewSettingsRepository(db *mongo.Database) SettingsRepository {
+	return &settingsRepository{
+		collection: db.Collection("settings"),
+	}
+}
+
+// NewChannelSettingsRepository creates a settings repository for a channel in multi-tenant mode.
+// The settings of connected channels are stored apart from those of the primary channel.
+func NewChannelSettingsRepository(db *mongo.Database, scope ChannelScope) SettingsRepository {
+	return &settingsRepository{
+		collection: db.Collection("settings"),
+		scope:      scope,
+	}
+}
+
+// GetSetting returns the value of a setting, or nil if it is not set.
+func (r *settingsRepository) GetSetting(ctx context.Context, key string) (*models.Setting, error) {
+	var setting models.Setting
+	err := r.collection.FindOne(ctx, bson.M{"_id": r.scope.keyPrefix + key}).Decode(&setting)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get setting %s: %w", key, err)
+	}
+	return &setting, nil
+}
+
+// SetSetting stores the value of a setting and who changed it.
+func (r *settingsRepository) SetSetting(ctx context.Context, key, value string, updatedBy int64) error {
+	set := bson.M{"value": value, "updated_by": updatedBy, "updated_at": time.Now()}
+	if channelID := r.scope.ChannelID(); channelID != 0 {
+		set["channel_id"] = channelID
+	}
+	update := bson.M{"$set": set}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": r.scope.keyPrefix + key}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to set setting %s: %w", key, err)
+	}
+	return nil
+}
+
+// DeleteSetting removes a setting, so its default applies again.
+func (r *settingsRepository) DeleteSetting(ctx context.Context, key string) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": r.scope.keyPrefix + key}); err != nil {
+		return fmt.Errorf("failed to delete setting %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// statsRepository is a MongoDB implementation of StatsRepository.
+type statsRepository struct {
+	suggestions *mongo.Collection
+	postLogs    *mongo.Collection
+	userActions *mongo.Collection
+	scope       ChannelScope // Suggestions and actions of one channel in multi-tenant mode; the zero value counts all
+}
+
+// NewStatsRepository creates a new instance of statsRepository.
+func NewStatsRepository(db *mongo.Database) StatsRepository {
+	return &statsRepository{
+		suggestions: db.Collection(suggestionCollectionName),
+		postLogs:    db.Collection("post_logs"),
+		userActions: db.Collection("user_actions"),
+	}
+}
+
+// NewChannelStatsRepository creates a stats repository that only counts the suggestions and
+// user actions of one channel, for multi-tenant mode.
+func NewChannelStatsRepository(db *mongo.Database, scope ChannelScope) StatsRepository {
+	return &statsRepository{
+		suggestions: db.Collection(suggestionCollectionName),
+		postLogs:    db.Collection("post_logs"),
+		userActions: db.Collection("user_actions"),
+		scope:       scope,
+	}
+}
+
+// CountSuggestionTags counts the tags of suggestions approved since the given time, most used first.
+func (r *statsRepository) CountSuggestionTags(ctx context.Context, since time.Time) ([]models.TagCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: r.scope.apply(bson.M{
+			"status":      string(models.StatusApproved),
+			"reviewed_at": bson.M{"$gte": since},
+			"tags.0":      bson.M{"$exists": true},
+		})}},
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+	cursor, err := r.suggestions.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count suggestion tags: %w", err)
+	}
+	var counts []models.TagCount
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, fmt.Errorf("failed to decode suggestion tag counts: %w", err)
+	}
+	return counts, nil
+}
+
+// CountSuggestSources counts, per deep-link source, the start actions (user actions of the
+// given type with a source) and the suggestions sent since the given time, most opened first.
+// Sources with suggestions but no recorded opens in the period are listed too.
+func (r *statsRepository) CountSuggestSources(ctx context.Context, since time.Time, startAction string) ([]models.SourceCount, error) {
+	opensPipeline := mongo.Pipeline{
+		{{Key: "$match", Value: r.scope.apply(bson.M{
+			"action":         startAction,
+			"time":           bson.M{"$gte": since},
+			"details.source": bson.M{"$nin": bson.A{nil, ""}},
+		})}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$details.source",
+			"opens": bson.M{"$sum": 1},
+			"users": bson.M{"$addToSet": "$user_id"},
+		}}},
+		{{Key: "$project", Value: bson.M{"opens": 1, "users": bson.M{"$size": "$users"}}}},
+	}
+	cursor, err := r.userActions.Aggregate(ctx, opensPipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count deep-link opens: %w", err)
+	}
+	var counts []models.SourceCount
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, fmt.Errorf("failed to decode deep-link opens: %w", err)
+	}
+
+	suggestionsPipeline := mongo.Pipeline{
+		{{Key: "$match", Value: r.scope.apply(bson.M{
+			"submitted_at": bson.M{"$gte": since},
+			"source":       bson.M{"$nin": bson.A{nil, ""}},
+		})}},
+		{{Key: "$group", Value: bson.M{"_id": "$source", "count": bson.M{"$sum": 1}}}},
+	}
+	cursor, err = r.suggestions.Aggregate(ctx, suggestionsPipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count suggestions by source: %w", err)
+	}
+	var suggestions []models.TagCount
+	if err := cursor.All(ctx, &suggestions); err != nil {
+		return nil, fmt.Errorf("failed to decode suggestions by source: %w", err)
+	}
+
+	bySource := make(map[string]int, len(counts))
+	for i, count := range counts {
+		bySource[count.Source] = i
+	}
+	for _, suggestion := range suggestions {
+		if i, ok := bySource[suggestion.Tag]; ok {
+			counts[i].Suggestions = suggestion.Count
+		} else {
+			counts = append(counts, models.SourceCount{Source: suggestion.Tag, Suggestions: suggestion.Count})
+		}
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Opens != counts[j].Opens {
+			return counts[i].Opens > counts[j].Opens
+		}
+		return counts[i].Source < counts[j].Source
+	})
+	return counts, nil
+}
+
+// CountPostOrigins counts the posts published to the channel since the given time by origin,
+// most common first. Posts logged before origins were recorded count as direct posts, or as
+// scheduled ones if they came from the staging directory.
+func (r *statsRepository) CountPostOrigins(ctx context.Context, channelID int64, since time.Time) ([]models.OriginCount, error) {
+	legacyOrigin := bson.M{"$cond": bson.A{
+		bson.M{"$gt": bson.A{"$source_file", nil}},
+		models.PostOriginScheduled,
+		models.PostOriginDirect,
+	}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"channel_id":   channelID,
+			"published_at": bson.M{"$gte": since},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"$ifNull": bson.A{"$origin", legacyOrigin}},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+	cursor, err := r.postLogs.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count posts by origin: %w", err)
+	}
+	var counts []models.OriginCount
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, fmt.Errorf("failed to decode post origin counts: %w", err)
+	}
+	return counts, nil
+}
+
+// GetPost returns the log of a channel post, or ErrPostNotFound if the bot didn't log it.
+func (r *statsRepository) GetPost(ctx context.Context, channelID int64, postID int) (*models.PostLog, error) {
+	var post models.PostLog
+	err := r.postLogs.FindOne(ctx, bson.M{"channel_id": channelID, "channel_post_id": postID}).Decode(&post)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrPostNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post %d of channel %d: %w", postID, channelID, err)
+	}
+	return &post, nil
+}
+
+// SetPostReactionCount stores the current total of reactions to a channel post.
+// Reactions to posts the bot didn't log are ignored.
+func (r *statsRepository) SetPostReactionCount(ctx context.Context, channelID int64, postID, count int) error {
+	filter := bson.M{"channel_id": channelID, "channel_post_id": postID}
+	update := bson.M{"$set": bson.M{"reaction_count": count}}
+	if _, err := r.postLogs.UpdateOne(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to set reaction count of post %d: %w", postID, err)
+	}
+	return nil
+}
+
+// SetPostOCRText stores the text recognized in the photo of a channel post.
+// Posts the bot didn't log are ignored.
+func (r *statsRepository) SetPostOCRText(ctx context.Context, channelID int64, postID int, text string) error {
+	filter := bson.M{"channel_id": channelID, "channel_post_id": postID}
+	update := bson.M{"$set": bson.M{"ocr_text": text}}
+	if _, err := r.postLogs.UpdateOne(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to set recognized text of post %d: %w", postID, err)
+	}
+	return nil
+}
+
+// TopPosts returns the single photo and video posts published to the channel since the given
+// time with the most reactions, up to limit. Posts without reactions or a stored file are left out.
+func (r *statsRepository) TopPosts(ctx context.Context, channelID int64, since time.Time, limit int) ([]models.PostLog, error) {
+	filter := bson.M{
+		"channel_id":     channelID,
+		"published_at":   bson.M{"$gte": since},
+		"message_type":   bson.M{"$in": bson.A{"photo", "video"}},
+		"file_id":        bson.M{"$exists": true, "$ne": ""},
+		"reaction_count": bson.M{"$gt": 0},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "reaction_count", Value: -1}, {Key: "published_at", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := r.postLogs.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find top posts: %w", err)
+	}
+	var posts []models.PostLog
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, fmt.Errorf("failed to decode top posts: %w", err)
+	}
+	return posts, nil
+}
+
+// AdminActivity sums up the suggestions each admin reviewed and the posts they sent to the
+// channel (user actions of the given types) since the given time, most active first.
+// Suggestions rejected automatically have no reviewer and are not counted.
+func (r *statsRepository) AdminActivity(ctx context.Context, since time.Time, directPostActions []string) ([]models.AdminActivity, error) {
+	reviewPipeline := mongo.Pipeline{
+		{{Key: "$match", Value: r.scope.apply(bson.M{
+			"status":      bson.M{"$in": bson.A{string(models.StatusApproved), string(models.StatusRejected)}},
+			"reviewed_at": bson.M{"$gte": since},
+			"reviewed_by": bson.M{"$gt": 0},
+		})}},
+		{{Key: "$sort", Value: bson.M{"reviewed_at": 1}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$reviewed_by",
+			"username": bson.M{"$last": "$reviewer_username"},
+			"approved": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", string(models.StatusApproved)}}, 1, 0}}},
+			"rejected": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", string(models.StatusRejected)}}, 1, 0}}},
+			// Subtracting dates gives milliseconds
+			"average_response_ms": bson.M{"$avg": bson.M{"$subtract": bson.A{"$reviewed_at", "$submitted_at"}}},
+		}}},
+	}
+	cursor, err := r.suggestions.Aggregate(ctx, reviewPipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate admin reviews: %w", err)
+	}
+	var activity []models.AdminActivity
+	if err := cursor.All(ctx, &activity); err != nil {
+		return nil, fmt.Errorf("failed to decode admin reviews: %w", err)
+	}
+
+	postPipeline := mongo.Pipeline{
+		{{Key: "$match", Value: r.scope.apply(bson.M{
+			"action": bson.M{"$in": directPostActions},
+			"time":   bson.M{"$gte": since},
+		})}},
+		{{Key: "$group", Value: bson.M{"_id": "$user_id", "count": bson.M{"$sum": 1}}}},
+	}
+	cursor, err = r.userActions.Aggregate(ctx, postPipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate admin posts: %w", err)
+	}
+	var posts []struct {
+		AdminID int64 `bson:"_id"`
+		Count   int64 `bson:"count"`
+	}
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, fmt.Errorf("failed to decode admin posts: %w", err)
+	}
+
+	byAdmin := make(map[int64]int, len(activity))
+	for i, admin := range activity {
+		byAdmin[admin.AdminID] = i
+	}
+	for _, post := range posts {
+		if i, ok := byAdmin[post.AdminID]; ok {
+			activity[i].DirectPosts = post.Count
+		} else {
+			activity = append(activity, models.AdminActivity{AdminID: post.AdminID, DirectPosts: post.Count})
+		}
+	}
+	sort.Slice(activity, func(i, j int) bool {
+		a, b := activity[i].Reviewed()+activity[i].DirectPosts, activity[j].Reviewed()+activity[j].DirectPosts
+		if a != b {
+			return a > b
+		}
+		return activity[i].AdminID < activity[j].AdminID
+	})
+	return activity, nil
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// tenantRepository is a MongoDB implementation of TenantRepository.
+type tenantRepository struct {
+	tenants *mongo.Collection
+	choices *mongo.Collection // Channel picked by each user, by user ID
+}
+
+// NewTenantRepository creates a new instance of tenantRepository.
+func NewTenantRepository(db *mongo.Database) TenantRepository {
+	return &tenantRepository{
+		tenants: db.Collection("tenants"),
+		choices: db.Collection("tenant_choices"),
+	}
+}
+
+// CreateTenant stores a connected channel. It returns ErrTenantExists if it is already connected.
+func (r *tenantRepository) CreateTenant(ctx context.Context, tenant *models.Tenant) error {
+	if tenant.CreatedAt.IsZero() {
+		tenant.CreatedAt = time.Now()
+	}
+	if _, err := r.tenants.InsertOne(ctx, tenant); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrTenantExists
+		}
+		return fmt.Errorf("failed to create tenant %d: %w", tenant.ChannelID, err)
+	}
+	return nil
+}
+
+// ListTenants returns all connected channels, oldest first.
+func (r *tenantRepository) ListTenants(ctx context.Context) ([]models.Tenant, error) {
+	cursor, err := r.tenants.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	var tenants []models.Tenant
+	if err := cursor.All(ctx, &tenants); err != nil {
+		return nil, fmt.Errorf("failed to decode tenants: %w", err)
+	}
+	return tenants, nil
+}
+
+// SetTenantQuota replaces the quota of a connected channel. It returns ErrTenantNotFound if
+// the channel isn't connected.
+func (r *tenantRepository) SetTenantQuota(ctx context.Context, channelID int64, quota models.TenantQuota) error {
+	result, err := r.tenants.UpdateOne(ctx, bson.M{"_id": channelID}, bson.M{"$set": bson.M{"quota": quota}})
+	if err != nil {
+		return fmt.Errorf("failed to set the quota of tenant %d: %w", channelID, err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrTenantNotFound
+	}
+	return nil
+}
+
+// DeleteTenant disconnects a channel; its data is kept. It returns ErrTenantNotFound if the
+// channel isn't connected.
+func (r *tenantRepository) DeleteTenant(ctx context.Context, channelID int64) error {
+	result, err := r.tenants.DeleteOne(ctx, bson.M{"_id": channelID})
+	if err != nil {
+		return fmt.Errorf("failed to delete tenant %d: %w", channelID, err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrTenantNotFound
+	}
+	return nil
+}
+
+// GetUserTenant returns the channel a user picked, or zero if they haven't picked one.
+func (r *tenantRepository) GetUserTenant(ctx context.Context, userID int64) (int64, error) {
+	var choice struct {
+		ChannelID int64 `bson:"channel_id"`
+	}
+	err := r.choices.FindOne(ctx, bson.M{"_id": userID}).Decode(&choice)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get the channel of user %d: %w", userID, err)
+	}
+	return choice.ChannelID, nil
+}
+
+// SetUserTenant saves the channel a user picked.
+func (r *tenantRepository) SetUserTenant(ctx context.Context, userID, channelID int64) error {
+	update := bson.M{"$set": bson.M{"channel_id": channelID, "updated_at": time.Now()}}
+	_, err := r.choices.UpdateOne(ctx, bson.M{"_id": userID}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to set the channel of user %d: %w", userID, err)
+	}
+	return nil
+}
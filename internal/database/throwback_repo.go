@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// throwbackMessageTypes are the post types /throwback can copy: single photos and videos.
+// Media groups are logged with their first message only, and text posts can't take the
+// "From the archive" caption.
+var throwbackMessageTypes = []string{"photo", "video"}
+
+// throwbackRepository is a MongoDB implementation of ThrowbackRepository.
+type throwbackRepository struct {
+	postLogs *mongo.Collection
+	reposts  *mongo.Collection
+}
+
+// NewThrowbackRepository creates a new instance of throwbackRepository.
+func NewThrowbackRepository(db *mongo.Database) ThrowbackRepository {
+	return &throwbackRepository{
+		postLogs: db.Collection("post_logs"),
+		reposts:  db.Collection("reposts"),
+	}
+}
+
+// RandomArchivedPost picks a random photo or video post published to the channel before the given
+// time that wasn't reposted since notRepostedSince. It returns nil if there is none.
+func (r *throwbackRepository) RandomArchivedPost(ctx context.Context, channelID int64, publishedBefore, notRepostedSince time.Time) (*models.PostLog, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"channel_id":      channelID,
+			"published_at":    bson.M{"$lt": publishedBefore},
+			"message_type":    bson.M{"$in": throwbackMessageTypes},
+			"channel_post_id": bson.M{"$gt": 0},
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": r.reposts.Name(),
+			"let":  bson.M{"post_id": "$channel_post_id", "channel_id": "$channel_id"},
+			"pipeline": mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{
+					"$expr": bson.M{"$and": bson.A{
+						bson.M{"$eq": bson.A{"$channel_post_id", "$$post_id"}},
+						bson.M{"$eq": bson.A{"$channel_id", "$$channel_id"}},
+						bson.M{"$gte": bson.A{"$reposted_at", notRepostedSince}},
+					}},
+				}}},
+				{{Key: "$limit", Value: 1}},
+			},
+			"as": "recent_reposts",
+		}}},
+		{{Key: "$match", Value: bson.M{"recent_reposts": bson.M{"$size": 0}}}},
+		{{Key: "$sample", Value: bson.M{"size": 1}}},
+	}
+	cursor, err := r.postLogs.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pick an archived post: %w", err)
+	}
+	var posts []models.PostLog
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, fmt.Errorf("failed to decode archived post: %w", err)
+	}
+	if len(posts) == 0 {
+		return nil, nil
+	}
+	return &posts[0], nil
+}
+
+// RecordRepost stores a repost in the reposts log.
+func (r *throwbackRepository) RecordRepost(ctx context.Context, repost *models.Repost) error {
+	if repost.RepostedAt.IsZero() {
+		repost.RepostedAt = time.Now()
+	}
+	if _, err := r.reposts.InsertOne(ctx, repost); err != nil {
+		return fmt.Errorf("failed to record repost of post %d: %w", repost.ChannelPostID, err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// NoTransaction is a UnitOfWork that runs the repository calls one by one, without a transaction.
+var NoTransaction UnitOfWork = noTransaction{}
+
+type noTransaction struct{}
+
+// Do runs fn with the given context.
+func (noTransaction) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// mongoUnitOfWork runs repository calls in a MongoDB transaction.
+// Transactions need a replica set or a sharded cluster; on a standalone server the calls
+// run without one, as with NoTransaction.
+type mongoUnitOfWork struct {
+	client *mongo.Client
+
+	mu        sync.Mutex
+	checked   bool // Whether the server topology is known
+	supported bool
+}
+
+// NewUnitOfWork returns a UnitOfWork that uses transactions of the client's MongoDB deployment
+// when it supports them. The deployment is checked on first use.
+func NewUnitOfWork(client *mongo.Client) UnitOfWork {
+	return &mongoUnitOfWork{client: client}
+}
+
+// Do runs fn in a transaction if the deployment supports transactions.
+func (u *mongoUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !u.transactionsSupported(ctx) {
+		return fn(ctx)
+	}
+	err := u.client.UseSession(ctx, func(sessionCtx mongo.SessionContext) error {
+		_, err := sessionCtx.WithTransaction(sessionCtx, func(txCtx mongo.SessionContext) (interface{}, error) {
+			return nil, fn(txCtx)
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("transaction failed: %w", err)
+	}
+	return nil
+}
+
+// transactionsSupported reports whether the server is a replica set member or a mongos router.
+// If the check fails, e.g. because the server is unreachable, it is repeated on the next call.
+func (u *mongoUnitOfWork) transactionsSupported(ctx context.Context) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.checked {
+		return u.supported
+	}
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := u.client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		log.Printf("[UnitOfWork] Error checking transaction support, running without a transaction: %v", err)
+		return false
+	}
+	u.checked = true
+	u.supported = hello.SetName != "" || hello.Msg == "isdbgrid"
+	if u.supported {
+		log.Println("[UnitOfWork] MongoDB supports transactions; multi-document operations run in transactions.")
+	} else {
+		log.Println("[UnitOfWork] MongoDB is a standalone server; multi-document operations run without transactions.")
+	}
+	return u.supported
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// filteredWordCollectionName is the collection of the words managed with /wordfilter.
+	filteredWordCollectionName = "filtered_words"
+	// filterHitCollectionName is the collection of filtered words found in texts.
+	filterHitCollectionName = "filter_hits"
+)
+
+// wordFilterRepository is a MongoDB implementation of WordFilterRepository.
+type wordFilterRepository struct {
+	words *mongo.Collection
+	hits  *mongo.Collection
+	scope ChannelScope // Words and hits of one channel in multi-tenant mode; the zero value sees all
+}
+
+// NewWordFilterRepository creates a new instance of wordFilterRepository.
+func NewWordFilterRepository(db *mongo.Database) WordFilterRepository {
+	return &wordFilterRepository{
+		words: db.Collection(filteredWordCollectionName),
+		hits:  db.Collection(filterHitCollectionName),
+	}
+}
+
+// NewChannelWordFilterRepository creates a word filter repository that only sees the words and
+// hits of one channel, for multi-tenant mode.
+func NewChannelWordFilterRepository(db *mongo.Database, scope ChannelScope) WordFilterRepository {
+	return &wordFilterRepository{
+		words: db.Collection(filteredWordCollectionName),
+		hits:  db.Collection(filterHitCollectionName),
+		scope: scope,
+	}
+}
+
+// SetFilteredWord adds a word, or changes the severity of a word that is already filtered.
+func (r *wordFilterRepository) SetFilteredWord(ctx context.Context, word *models.FilteredWord) error {
+	if word.AddedAt.IsZero() {
+		word.AddedAt = time.Now()
+	}
+	if word.ChannelID == 0 {
+		word.ChannelID = r.scope.ChannelID()
+	}
+	update := bson.M{
+		"$set":         bson.M{"severity": word.Severity, "added_by": word.AddedBy, "added_at": word.AddedAt},
+		"$setOnInsert": bson.M{"channel_id": word.ChannelID},
+	}
+	filter := r.scope.apply(bson.M{"word": word.Word})
+	if _, err := r.words.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true)); err != nil {
+		return fmt.Errorf("failed to filter word %q: %w", word.Word, err)
+	}
+	return nil
+}
+
+// DeleteFilteredWord stops filtering a word. It returns ErrFilteredWordNotFound if it isn't filtered.
+func (r *wordFilterRepository) DeleteFilteredWord(ctx context.Context, word string) error {
+	result, err := r.words.DeleteMany(ctx, r.scope.apply(bson.M{"word": word}))
+	if err != nil {
+		return fmt.Errorf("failed to delete filtered word %q: %w", word, err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrFilteredWordNotFound
+	}
+	return nil
+}
+
+// ListFilteredWords returns the filtered words in alphabetical order.
+func (r *wordFilterRepository) ListFilteredWords(ctx context.Context) ([]models.FilteredWord, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "word", Value: 1}})
+	cursor, err := r.words.Find(ctx, r.scope.apply(bson.M{}), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list filtered words: %w", err)
+	}
+	var words []models.FilteredWord
+	if err := cursor.All(ctx, &words); err != nil {
+		return nil, fmt.Errorf("failed to decode filtered words: %w", err)
+	}
+	return words, nil
+}
+
+// LogFilterHits records filtered words found in a text.
+func (r *wordFilterRepository) LogFilterHits(ctx context.Context, hits []models.FilterHit) error {
+	if len(hits) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(hits))
+	for i := range hits {
+		if hits[i].ChannelID == 0 {
+			hits[i].ChannelID = r.scope.ChannelID()
+		}
+		docs[i] = hits[i]
+	}
+	if _, err := r.hits.InsertMany(ctx, docs); err != nil {
+		return fmt.Errorf("failed to log filter hits: %w", err)
+	}
+	return nil
+}
+
+// FilterHitCounts counts the hits of each filtered word since the given time, most hits first.
+func (r *wordFilterRepository) FilterHitCounts(ctx context.Context, since time.Time) ([]models.FilterHitCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: r.scope.apply(bson.M{"at": bson.M{"$gte": since}})}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$word",
+			"hits":  bson.M{"$sum": 1},
+			"users": bson.M{"$addToSet": "$user_id"},
+		}}},
+		{{Key: "$project", Value: bson.M{"hits": 1, "users": bson.M{"$size": "$users"}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "hits", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+	cursor, err := r.hits.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count filter hits: %w", err)
+	}
+	var counts []models.FilterHitCount
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, fmt.Errorf("failed to decode filter hit counts: %w", err)
+	}
+	return counts, nil
+}
@@ -0,0 +1,143 @@
+// Package debugmode holds the bot's runtime troubleshooting switches: verbose logging of the bot
+// and of the Telegram client, which /debug turns on and off without a restart, and tracing, which
+// /trace uses to mirror raw updates of chosen types to the admin chat for a while.
+package debugmode
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mymmrac/telego"
+)
+
+// UpdateTypes are the update types that can be traced, named as in allowed_updates.
+var UpdateTypes = []string{
+	telego.MessageUpdates,
+	telego.EditedMessageUpdates,
+	telego.ChannelPostUpdates,
+	telego.EditedChannelPostUpdates,
+	telego.CallbackQueryUpdates,
+	telego.ChatMemberUpdates,
+	telego.MyChatMemberUpdates,
+	telego.MessageReactionCountUpdates,
+}
+
+// Switch is the shared debug state. The zero value and a nil Switch are off.
+type Switch struct {
+	enabled atomic.Bool
+
+	mu          sync.Mutex
+	traceTypes  []string
+	traceChatID int64
+	traceUntil  time.Time
+}
+
+// New creates a switch with debug logging initially enabled or not.
+func New(enabled bool) *Switch {
+	s := &Switch{}
+	s.enabled.Store(enabled)
+	return s
+}
+
+// Enabled reports whether debug logging is on.
+func (s *Switch) Enabled() bool {
+	return s != nil && s.enabled.Load()
+}
+
+// Set turns debug logging on or off.
+func (s *Switch) Set(enabled bool) {
+	s.enabled.Store(enabled)
+}
+
+// StartTrace mirrors updates of the given types to chatID until the given time, replacing any
+// running trace.
+func (s *Switch) StartTrace(chatID int64, types []string, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceChatID, s.traceTypes, s.traceUntil = chatID, slices.Clone(types), until
+}
+
+// StopTrace stops mirroring updates.
+func (s *Switch) StopTrace() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceChatID, s.traceTypes, s.traceUntil = 0, nil, time.Time{}
+}
+
+// Trace returns the running trace at now: the chat updates are mirrored to, their types and when
+// the trace ends. ok is false if no trace is running.
+func (s *Switch) Trace(now time.Time) (chatID int64, types []string, until time.Time, ok bool) {
+	if s == nil {
+		return 0, nil, time.Time{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.traceTypes) == 0 || !now.Before(s.traceUntil) {
+		return 0, nil, time.Time{}, false
+	}
+	return s.traceChatID, slices.Clone(s.traceTypes), s.traceUntil, true
+}
+
+// TraceChat returns the chat an update of the given type is mirrored to at now, or false if
+// updates of that type aren't traced.
+func (s *Switch) TraceChat(updateType string, now time.Time) (int64, bool) {
+	chatID, types, _, ok := s.Trace(now)
+	if !ok || !slices.Contains(types, updateType) {
+		return 0, false
+	}
+	return chatID, true
+}
+
+// UpdateType returns the type of an update as named in allowed_updates, or "" for types that
+// can't be traced.
+func UpdateType(update telego.Update) string {
+	switch {
+	case update.Message != nil:
+		return telego.MessageUpdates
+	case update.EditedMessage != nil:
+		return telego.EditedMessageUpdates
+	case update.ChannelPost != nil:
+		return telego.ChannelPostUpdates
+	case update.EditedChannelPost != nil:
+		return telego.EditedChannelPostUpdates
+	case update.CallbackQuery != nil:
+		return telego.CallbackQueryUpdates
+	case update.ChatMember != nil:
+		return telego.ChatMemberUpdates
+	case update.MyChatMember != nil:
+		return telego.MyChatMemberUpdates
+	case update.MessageReactionCount != nil:
+		return telego.MessageReactionCountUpdates
+	default:
+		return ""
+	}
+}
+
+// logger is a telego.Logger whose debug output follows the switch.
+type logger struct {
+	debug    *Switch
+	replacer *strings.Replacer
+}
+
+// Logger returns the Telegram client logger: errors are always logged, requests and responses
+// only while debug logging is on. Like telego's default logger, it hides the bot token.
+func (s *Switch) Logger(token string) telego.Logger {
+	return logger{debug: s, replacer: strings.NewReplacer(token, "BOT_TOKEN")}
+}
+
+// Debugf implements telego.Logger.
+func (l logger) Debugf(format string, args ...any) {
+	if l.debug.Enabled() {
+		log.Print("[Telego] DEBUG " + l.replacer.Replace(fmt.Sprintf(format, args...)))
+	}
+}
+
+// Errorf implements telego.Logger.
+func (l logger) Errorf(format string, args ...any) {
+	log.Print("[Telego] ERROR " + l.replacer.Replace(fmt.Sprintf(format, args...)))
+}
@@ -0,0 +1,50 @@
+package debugmode
+
+import (
+	"bytes"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceChatFollowsTypesAndDeadline(t *testing.T) {
+	s := New(false)
+	now := time.Now()
+	_, ok := s.TraceChat(telego.MessageUpdates, now)
+	assert.False(t, ok)
+
+	s.StartTrace(-1001, []string{telego.MessageUpdates}, now.Add(time.Minute))
+	chatID, ok := s.TraceChat(telego.MessageUpdates, now)
+	assert.True(t, ok)
+	assert.Equal(t, int64(-1001), chatID)
+	_, ok = s.TraceChat(telego.CallbackQueryUpdates, now)
+	assert.False(t, ok)
+	_, ok = s.TraceChat(telego.MessageUpdates, now.Add(time.Minute))
+	assert.False(t, ok, "the trace ends at its deadline")
+
+	s.StopTrace()
+	_, ok = s.TraceChat(telego.MessageUpdates, now)
+	assert.False(t, ok)
+}
+
+func TestLoggerFollowsSwitchAndHidesToken(t *testing.T) {
+	var out bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&out)
+
+	s := New(false)
+	logger := s.Logger("123:secret")
+	logger.Debugf("calling %s", "https://api.telegram.org/bot123:secret/getMe")
+	assert.Empty(t, out.String())
+
+	s.Set(true)
+	logger.Debugf("calling %s", "https://api.telegram.org/bot123:secret/getMe")
+	assert.Contains(t, out.String(), "botBOT_TOKEN/getMe")
+	assert.NotContains(t, out.String(), "secret")
+
+	var nilSwitch *Switch
+	assert.False(t, nilSwitch.Enabled())
+}
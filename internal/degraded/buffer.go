@@ -0,0 +1,252 @@
+package degraded
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// DefaultMaxMemory is how many writes are buffered in memory before spilling to disk.
+	DefaultMaxMemory = 1000
+	// DefaultMaxSpilled is how many writes the spill file holds before new writes are refused.
+	DefaultMaxSpilled = 10000
+	// maxEntrySize bounds a single line of the spill file.
+	maxEntrySize = 16 << 20
+)
+
+// ErrBufferFull is returned by Add when neither memory nor the spill file has room.
+var ErrBufferFull = errors.New("write buffer is full")
+
+// Entry is a buffered write: a BSON document and the kind of write that replays it.
+type Entry struct {
+	Kind     string    `json:"kind"`
+	Doc      []byte    `json:"doc"`
+	QueuedAt time.Time `json:"queued_at"`
+}
+
+// ReplayFunc writes a buffered document to the database.
+type ReplayFunc func(ctx context.Context, doc []byte) error
+
+// Buffer holds writes that couldn't reach the database until they are replayed.
+// Writes are kept in memory up to a limit and then appended to a spill file, which also
+// survives restarts: entries left in it are replayed once the database is reachable.
+type Buffer struct {
+	mu         sync.Mutex
+	memory     []Entry
+	maxMemory  int
+	spillPath  string // Empty disables spilling to disk
+	maxSpilled int
+	spilled    int // Entries in the spill file
+	replayers  map[string]ReplayFunc
+}
+
+// NewBuffer creates a write buffer. Non-positive limits use the defaults. If the spill file
+// exists from a previous run, its entries are counted as buffered.
+func NewBuffer(maxMemory int, spillPath string, maxSpilled int) (*Buffer, error) {
+	if maxMemory <= 0 {
+		maxMemory = DefaultMaxMemory
+	}
+	if maxSpilled <= 0 {
+		maxSpilled = DefaultMaxSpilled
+	}
+	b := &Buffer{
+		maxMemory:  maxMemory,
+		spillPath:  spillPath,
+		maxSpilled: maxSpilled,
+		replayers:  make(map[string]ReplayFunc),
+	}
+	if spillPath != "" {
+		entries, err := readEntries(spillPath)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read spill file %s: %w", spillPath, err)
+		}
+		b.spilled = len(entries)
+		if b.spilled > 0 {
+			log.Printf("[DegradedMode] %d buffered writes left in %s will be replayed", b.spilled, spillPath)
+		}
+	}
+	return b, nil
+}
+
+// Register sets how buffered writes of a kind are replayed.
+func (b *Buffer) Register(kind string, replay ReplayFunc) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.replayers[kind] = replay
+}
+
+// Add buffers a document for a later write of the given kind.
+func (b *Buffer) Add(kind string, v interface{}) error {
+	doc, err := bson.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode buffered %s: %w", kind, err)
+	}
+	entry := Entry{Kind: kind, Doc: doc, QueuedAt: time.Now()}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.memory) < b.maxMemory {
+		b.memory = append(b.memory, entry)
+		return nil
+	}
+	if b.spillPath == "" || b.spilled >= b.maxSpilled {
+		return ErrBufferFull
+	}
+	if err := appendEntries(b.spillPath, []Entry{entry}); err != nil {
+		return fmt.Errorf("failed to spill buffered %s to disk: %w", kind, err)
+	}
+	b.spilled++
+	return nil
+}
+
+// Len returns how many writes are buffered, in memory and on disk.
+func (b *Buffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.memory) + b.spilled
+}
+
+// Replay writes the buffered entries to the database: first those in memory, then those in
+// the spill file. It stops at the first failure, keeping the entries that weren't written,
+// and returns how many were written.
+func (b *Buffer) Replay(ctx context.Context) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	replayed := 0
+	for len(b.memory) > 0 {
+		written, err := b.replay(ctx, b.memory[0])
+		if err != nil {
+			return replayed, err
+		}
+		b.memory = b.memory[1:]
+		if written {
+			replayed++
+		}
+	}
+	if b.spilled == 0 {
+		return replayed, nil
+	}
+
+	entries, err := readEntries(b.spillPath)
+	if err != nil {
+		return replayed, fmt.Errorf("failed to read spill file: %w", err)
+	}
+	for i, entry := range entries {
+		written, err := b.replay(ctx, entry)
+		if err != nil {
+			if writeErr := writeEntries(b.spillPath, entries[i:]); writeErr != nil {
+				// The written entries stay in the file and will be written again
+				log.Printf("[DegradedMode] Error rewriting spill file: %v", writeErr)
+			} else {
+				b.spilled = len(entries) - i
+			}
+			return replayed, err
+		}
+		if written {
+			replayed++
+		}
+	}
+	if err := os.Remove(b.spillPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return replayed, fmt.Errorf("failed to remove replayed spill file: %w", err)
+	}
+	b.spilled = 0
+	return replayed, nil
+}
+
+// replay writes one entry and reports whether it was written. Entries of unknown kinds
+// are dropped, so they can't block the rest.
+func (b *Buffer) replay(ctx context.Context, entry Entry) (bool, error) {
+	replay, ok := b.replayers[entry.Kind]
+	if !ok {
+		log.Printf("[DegradedMode] Dropping buffered write of unknown kind %q queued at %s", entry.Kind, entry.QueuedAt.Format(time.RFC3339))
+		return false, nil
+	}
+	if err := replay(ctx, entry.Doc); err != nil {
+		return false, fmt.Errorf("failed to replay buffered %s: %w", entry.Kind, err)
+	}
+	return true, nil
+}
+
+// Spill moves the writes buffered in memory to the spill file, e.g. on shutdown, so they are
+// replayed after a restart. The spill file limit doesn't apply.
+func (b *Buffer) Spill() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.memory) == 0 {
+		return nil
+	}
+	if b.spillPath == "" {
+		return fmt.Errorf("%d buffered writes are lost: no spill file is configured", len(b.memory))
+	}
+	if err := appendEntries(b.spillPath, b.memory); err != nil {
+		return fmt.Errorf("failed to spill %d buffered writes: %w", len(b.memory), err)
+	}
+	b.spilled += len(b.memory)
+	b.memory = nil
+	return nil
+}
+
+// appendEntries appends entries to a spill file as JSON lines, creating it if needed.
+func appendEntries(path string, entries []Entry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+
+// writeEntries replaces the contents of a spill file.
+func writeEntries(path string, entries []Entry) error {
+	tmp := path + ".tmp"
+	_ = os.Remove(tmp)
+	if err := appendEntries(tmp, entries); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readEntries reads all entries of a spill file.
+func readEntries(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxEntrySize)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("malformed spill file entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,183 @@
+package degraded
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) Ping(ctx context.Context) error { return f(ctx) }
+
+type item struct {
+	N int `bson:"n"`
+}
+
+// recorder replays "item" entries into a slice, failing while err is set.
+type recorder struct {
+	got []int
+	err error
+}
+
+func (r *recorder) replay(_ context.Context, doc []byte) error {
+	if r.err != nil {
+		return r.err
+	}
+	var it item
+	if err := bson.Unmarshal(doc, &it); err != nil {
+		return err
+	}
+	r.got = append(r.got, it.N)
+	return nil
+}
+
+func TestBufferSpillsAndReplaysInOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spill", "pending.jsonl")
+	buffer, err := NewBuffer(2, path, 3)
+	require.NoError(t, err)
+	rec := &recorder{}
+	buffer.Register("item", rec.replay)
+
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, buffer.Add("item", item{N: i}))
+	}
+	assert.ErrorIs(t, buffer.Add("item", item{N: 6}), ErrBufferFull)
+	assert.Equal(t, 5, buffer.Len())
+
+	// A failed replay keeps everything
+	rec.err = errors.New("still down")
+	replayed, err := buffer.Replay(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 0, replayed)
+	assert.Equal(t, 5, buffer.Len())
+
+	rec.err = nil
+	replayed, err = buffer.Replay(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5, replayed)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, rec.got)
+	assert.Equal(t, 0, buffer.Len())
+	assert.NoFileExists(t, path)
+}
+
+func TestBufferSpillSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pending.jsonl")
+	buffer, err := NewBuffer(10, path, 10)
+	require.NoError(t, err)
+	require.NoError(t, buffer.Add("item", item{N: 1}))
+	require.NoError(t, buffer.Add("unknown", item{N: 2}))
+	require.NoError(t, buffer.Spill())
+
+	restarted, err := NewBuffer(10, path, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, restarted.Len())
+	rec := &recorder{}
+	restarted.Register("item", rec.replay)
+
+	// Entries of unknown kinds are dropped
+	replayed, err := restarted.Replay(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+	assert.Equal(t, []int{1}, rec.got)
+	assert.Equal(t, 0, restarted.Len())
+}
+
+func TestMonitorDegradedMode(t *testing.T) {
+	buffer, err := NewBuffer(10, "", 0)
+	require.NoError(t, err)
+	rec := &recorder{}
+	buffer.Register("item", rec.replay)
+
+	dbErr := fmt.Errorf("server selection: %w", context.DeadlineExceeded)
+	down := true
+	monitor := NewMonitor(pingerFunc(func(context.Context) error {
+		if down {
+			return dbErr
+		}
+		return nil
+	}), buffer)
+	monitor.SetFailureThreshold(2)
+	alerts := make(chan bool, 2)
+	monitor.SetAlertFunc(func(_ context.Context, degraded bool, _ int) { alerts <- degraded })
+
+	writes := 0
+	failingWrite := func() error { writes++; return dbErr }
+
+	// The first unavailability error is buffered, but the database is still tried
+	require.NoError(t, monitor.write("item", item{N: 1}, failingWrite))
+	assert.False(t, monitor.Degraded())
+	monitor.check(context.Background())
+	assert.True(t, monitor.Degraded())
+	assert.True(t, <-alerts)
+
+	// In degraded mode writes are buffered without trying the database
+	require.NoError(t, monitor.write("item", item{N: 2}, failingWrite))
+	assert.Equal(t, 1, writes)
+	assert.Equal(t, 2, monitor.Buffered())
+
+	down = false
+	monitor.check(context.Background())
+	assert.False(t, monitor.Degraded())
+	assert.False(t, <-alerts)
+	assert.Equal(t, []int{1, 2}, rec.got)
+	assert.Equal(t, 0, monitor.Buffered())
+
+	// Errors other than unavailability are returned as is
+	otherErr := errors.New("duplicate key")
+	assert.ErrorIs(t, monitor.write("item", item{N: 3}, func() error { return otherErr }), otherErr)
+	assert.Equal(t, 0, monitor.Buffered())
+}
+
+// refCodeRepo is a database.SuggestionRepository whose CreateSuggestion refuses taken reference codes.
+type refCodeRepo struct {
+	database.SuggestionRepository
+	taken   map[string]bool
+	created []models.Suggestion
+}
+
+func (r *refCodeRepo) CreateSuggestion(_ context.Context, suggestion *models.Suggestion) error {
+	if r.taken[suggestion.RefCode] {
+		return database.ErrRefCodeTaken
+	}
+	r.taken[suggestion.RefCode] = true
+	r.created = append(r.created, *suggestion)
+	return nil
+}
+
+func TestReplayedSuggestionWithTakenRefCodeIsRenamed(t *testing.T) {
+	buffer, err := NewBuffer(10, "", 0)
+	require.NoError(t, err)
+	monitor := NewMonitor(pingerFunc(func(context.Context) error { return nil }), buffer)
+	next := &refCodeRepo{taken: map[string]bool{}}
+	repo := NewSuggestionRepository(next, monitor)
+	type change struct{ oldCode, newCode string }
+	var changes []change
+	repo.SetRefCodeChangedFunc(func(_ context.Context, suggestion *models.Suggestion, oldCode string) {
+		changes = append(changes, change{oldCode, suggestion.RefCode})
+	})
+
+	// Both suggestions were buffered; another one got the first one's code in the meantime
+	require.NoError(t, buffer.Add(kindSuggestion, models.Suggestion{RefCode: "S-AAAA", SuggesterID: 1}))
+	require.NoError(t, buffer.Add(kindSuggestion, models.Suggestion{RefCode: "S-BBBB", SuggesterID: 2}))
+	next.taken["S-AAAA"] = true
+
+	replayed, err := buffer.Replay(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, replayed)
+	require.Len(t, next.created, 2)
+	renamed := next.created[0]
+	assert.NotEqual(t, "S-AAAA", renamed.RefCode)
+	assert.True(t, models.IsRefCode(renamed.RefCode))
+	assert.Equal(t, "S-BBBB", next.created[1].RefCode)
+	// Only the suggester of the renamed suggestion is told
+	assert.Equal(t, []change{{oldCode: "S-AAAA", newCode: renamed.RefCode}}, changes)
+}
@@ -0,0 +1,197 @@
+// Package degraded keeps the bot working while MongoDB is unavailable.
+//
+// A Monitor pings the database and switches to degraded mode after repeated failures.
+// In degraded mode, critical writes (post logs and new suggestions) go to a Buffer instead
+// of the database, and admins are alerted. Once the database answers again, the buffered
+// writes are replayed. Other operations keep failing as before while the database is down.
+package degraded
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+	"vrcmemes-bot/internal/database"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// DefaultCheckInterval is how often the database is pinged.
+	DefaultCheckInterval = 10 * time.Second
+	// DefaultFailureThreshold is how many consecutive failures switch to degraded mode.
+	DefaultFailureThreshold = 3
+	// pingTimeout bounds a single health check ping.
+	pingTimeout = 5 * time.Second
+	// alertTimeout bounds sending an alert.
+	alertTimeout = 30 * time.Second
+)
+
+// AlertFunc is called when degraded mode starts (degraded is true) or ends.
+// buffered is how many writes are waiting to be replayed.
+type AlertFunc func(ctx context.Context, degraded bool, buffered int)
+
+// Monitor tracks whether the database is reachable and replays buffered writes when it is.
+type Monitor struct {
+	pinger    database.Pinger
+	buffer    *Buffer
+	interval  time.Duration
+	threshold int
+	alert     AlertFunc
+
+	mu       sync.Mutex
+	failures int  // Consecutive failed pings and writes
+	degraded bool // Writes are buffered without trying the database
+}
+
+// NewMonitor creates a database monitor that buffers writes in buffer. Start must be called
+// to begin checking the database.
+func NewMonitor(pinger database.Pinger, buffer *Buffer) *Monitor {
+	if pinger == nil {
+		log.Fatal("Monitor: Database pinger dependency is nil")
+	}
+	if buffer == nil {
+		log.Fatal("Monitor: Write buffer dependency is nil")
+	}
+	return &Monitor{
+		pinger:    pinger,
+		buffer:    buffer,
+		interval:  DefaultCheckInterval,
+		threshold: DefaultFailureThreshold,
+	}
+}
+
+// SetCheckInterval sets how often the database is pinged. Non-positive values keep the default.
+func (m *Monitor) SetCheckInterval(interval time.Duration) {
+	if interval > 0 {
+		m.interval = interval
+	}
+}
+
+// SetFailureThreshold sets how many consecutive failures switch to degraded mode.
+// Non-positive values keep the default.
+func (m *Monitor) SetFailureThreshold(threshold int) {
+	if threshold > 0 {
+		m.threshold = threshold
+	}
+}
+
+// SetAlertFunc sets the function called when degraded mode starts or ends.
+func (m *Monitor) SetAlertFunc(alert AlertFunc) {
+	m.alert = alert
+}
+
+// Degraded reports whether the monitor is in degraded mode.
+func (m *Monitor) Degraded() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.degraded
+}
+
+// Buffered returns how many writes are waiting to be replayed.
+func (m *Monitor) Buffered() int {
+	return m.buffer.Len()
+}
+
+// Start pings the database in the background until ctx is done. The first check runs
+// immediately, so writes left in the spill file by a previous run are replayed at startup.
+func (m *Monitor) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			m.check(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// check pings the database, leaving degraded mode and replaying buffered writes if it answers.
+func (m *Monitor) check(ctx context.Context) {
+	pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+	err := m.pinger.Ping(pingCtx)
+	cancel()
+	if err != nil {
+		if ctx.Err() == nil {
+			m.recordFailure(err)
+		}
+		return
+	}
+
+	m.mu.Lock()
+	recovered := m.degraded
+	m.degraded = false
+	m.failures = 0
+	m.mu.Unlock()
+
+	if m.buffer.Len() > 0 {
+		replayed, err := m.buffer.Replay(ctx)
+		if err != nil {
+			log.Printf("[DegradedMode] Replayed %d buffered writes, then failed: %v", replayed, err)
+		} else {
+			log.Printf("[DegradedMode] Replayed %d buffered writes", replayed)
+		}
+	}
+	if recovered {
+		log.Printf("[DegradedMode] Database is reachable again, %d writes still buffered", m.buffer.Len())
+		m.notify(false)
+	}
+}
+
+// recordFailure counts a failed ping or write and switches to degraded mode once the
+// failure threshold is reached.
+func (m *Monitor) recordFailure(err error) {
+	m.mu.Lock()
+	m.failures++
+	entered := !m.degraded && m.failures >= m.threshold
+	if entered {
+		m.degraded = true
+	}
+	failures := m.failures
+	m.mu.Unlock()
+
+	if entered {
+		log.Printf("[DegradedMode] Database unavailable after %d consecutive failures, buffering writes: %v", failures, err)
+		m.notify(true)
+	}
+}
+
+// notify calls the alert function in the background, so alerts don't hold up writes.
+func (m *Monitor) notify(degraded bool) {
+	if m.alert == nil {
+		return
+	}
+	buffered := m.buffer.Len()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), alertTimeout)
+		defer cancel()
+		m.alert(ctx, degraded, buffered)
+	}()
+}
+
+// write runs a database write, or buffers doc as a write of the given kind if the monitor is
+// in degraded mode or the write fails because the database is unavailable. Other errors are returned.
+func (m *Monitor) write(kind string, doc interface{}, write func() error) error {
+	if !m.Degraded() {
+		err := write()
+		if err == nil || !isUnavailable(err) {
+			return err
+		}
+		m.recordFailure(err)
+	}
+	if err := m.buffer.Add(kind, doc); err != nil {
+		return err
+	}
+	log.Printf("[DegradedMode] Buffered %s, %d writes waiting", kind, m.buffer.Len())
+	return nil
+}
+
+// isUnavailable reports whether a database error means the server couldn't be reached,
+// as opposed to the operation itself failing.
+func isUnavailable(err error) bool {
+	return mongo.IsNetworkError(err) || mongo.IsTimeout(err) || err == mongo.ErrClientDisconnected
+}
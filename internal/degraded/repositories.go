@@ -0,0 +1,137 @@
+package degraded
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Kinds of buffered writes.
+const (
+	kindPostLog    = "post_log"
+	kindSuggestion = "suggestion"
+)
+
+// replayTimeout bounds a single replayed write.
+const replayTimeout = 5 * time.Second
+
+// PostLogger is a database.PostLogger that buffers post logs while the database is unavailable.
+type PostLogger struct {
+	next    database.PostLogger
+	monitor *Monitor
+}
+
+// NewPostLogger wraps a post logger so its writes are buffered by the monitor in degraded mode.
+func NewPostLogger(next database.PostLogger, monitor *Monitor) *PostLogger {
+	if next == nil {
+		log.Fatal("PostLogger: Post logger dependency is nil")
+	}
+	if monitor == nil {
+		log.Fatal("PostLogger: Monitor dependency is nil")
+	}
+	monitor.buffer.Register(kindPostLog, func(ctx context.Context, doc []byte) error {
+		var entry models.PostLog
+		if err := bson.Unmarshal(doc, &entry); err != nil {
+			log.Printf("[DegradedMode] Dropping undecodable buffered post log: %v", err)
+			return nil
+		}
+		return next.LogPublishedPost(entry)
+	})
+	return &PostLogger{next: next, monitor: monitor}
+}
+
+// LogPublishedPost logs a published post, or buffers the log if the database is unavailable.
+func (l *PostLogger) LogPublishedPost(entry models.PostLog) error {
+	return l.monitor.write(kindPostLog, entry, func() error {
+		return l.next.LogPublishedPost(entry)
+	})
+}
+
+// RefCodeChangedFunc is called when a buffered suggestion was replayed under a new reference code,
+// because its code was given to another suggestion in the meantime.
+type RefCodeChangedFunc func(ctx context.Context, suggestion *models.Suggestion, oldCode string)
+
+// SuggestionRepository is a database.SuggestionRepository that buffers new suggestions while
+// the database is unavailable. All other operations go straight to the wrapped repository.
+type SuggestionRepository struct {
+	database.SuggestionRepository
+	monitor         *Monitor
+	onRefCodeChange RefCodeChangedFunc // Optional
+}
+
+// NewSuggestionRepository wraps a suggestion repository so new suggestions are buffered by
+// the monitor in degraded mode.
+func NewSuggestionRepository(next database.SuggestionRepository, monitor *Monitor) *SuggestionRepository {
+	if next == nil {
+		log.Fatal("SuggestionRepository: Suggestion repository dependency is nil")
+	}
+	if monitor == nil {
+		log.Fatal("SuggestionRepository: Monitor dependency is nil")
+	}
+	r := &SuggestionRepository{SuggestionRepository: next, monitor: monitor}
+	monitor.buffer.Register(kindSuggestion, func(ctx context.Context, doc []byte) error {
+		var suggestion models.Suggestion
+		if err := bson.Unmarshal(doc, &suggestion); err != nil {
+			log.Printf("[DegradedMode] Dropping undecodable buffered suggestion: %v", err)
+			return nil
+		}
+		writeCtx, cancel := context.WithTimeout(ctx, replayTimeout)
+		defer cancel()
+		err := next.CreateSuggestion(writeCtx, &suggestion)
+		if !errors.Is(err, database.ErrRefCodeTaken) {
+			if mongo.IsDuplicateKeyError(err) {
+				return nil // Written before the previous replay attempt failed
+			}
+			return err
+		}
+
+		// The code was given to another suggestion while this one was buffered, and the suggester
+		// was told the old one; the replay is attempted again later if the new code is taken too
+		oldCode := suggestion.RefCode
+		suggestion.RefCode = models.NewRefCode()
+		log.Printf("[DegradedMode] Reference code %s of buffered suggestion %s is taken, giving it %s", oldCode, suggestion.ID.Hex(), suggestion.RefCode)
+		if err := next.CreateSuggestion(writeCtx, &suggestion); err != nil {
+			if mongo.IsDuplicateKeyError(err) && !errors.Is(err, database.ErrRefCodeTaken) {
+				return nil
+			}
+			return err
+		}
+		if r.onRefCodeChange != nil {
+			r.onRefCodeChange(ctx, &suggestion, oldCode)
+		}
+		return nil
+	})
+	return r
+}
+
+// SetRefCodeChangedFunc sets a function called for each buffered suggestion that was replayed
+// under a new reference code, e.g. to tell the suggester the code they were given has changed.
+func (r *SuggestionRepository) SetRefCodeChangedFunc(onChange RefCodeChangedFunc) {
+	r.onRefCodeChange = onChange
+}
+
+// CreateSuggestion stores a new suggestion, or buffers it if the database is unavailable.
+// The ID and submission time are assigned up front, so a buffered suggestion keeps them when replayed.
+func (r *SuggestionRepository) CreateSuggestion(ctx context.Context, suggestion *models.Suggestion) error {
+	if suggestion.ID.IsZero() {
+		suggestion.ID = primitive.NewObjectID()
+	}
+	if suggestion.SubmittedAt.IsZero() {
+		suggestion.SubmittedAt = time.Now()
+	}
+	err := r.monitor.write(kindSuggestion, suggestion, func() error {
+		return r.SuggestionRepository.CreateSuggestion(ctx, suggestion)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create suggestion: %w", err)
+	}
+	return nil
+}
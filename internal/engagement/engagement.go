@@ -0,0 +1,119 @@
+// Package engagement prompts readers to react to channel posts published in a rubric. Depending on
+// the rubric, the bot reacts to the post with an emoji, so readers only need to tap it, and
+// comments under the post in the channel's discussion group.
+package engagement
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"vrcmemes-bot/internal/database"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// commentWait is how long a comment waits for Telegram to copy its post into the discussion
+// group. Posts of channels without one never get copied.
+const commentWait = 10 * time.Minute
+
+// pendingComment is a comment waiting for the discussion group copy of its post.
+type pendingComment struct {
+	text   string
+	queued time.Time
+}
+
+// Prompter applies the engagement prompts of rubrics to channel posts.
+type Prompter struct {
+	bot       telegoapi.BotAPI
+	channelID int64
+	rubrics   database.RubricRepository
+
+	mu      sync.Mutex
+	pending map[int]pendingComment // By channel post ID
+}
+
+// NewPrompter creates a prompter for the posts of a channel, with the rubrics in repo.
+func NewPrompter(bot telegoapi.BotAPI, channelID int64, rubrics database.RubricRepository) *Prompter {
+	return &Prompter{
+		bot:       bot,
+		channelID: channelID,
+		rubrics:   rubrics,
+		pending:   make(map[int]pendingComment),
+	}
+}
+
+// PromptPost applies the prompts of a rubric to a post just published in the channel: it reacts
+// to the post and queues the comment until the post shows up in the discussion group. Errors are
+// logged, since the post is published either way. Nothing happens without a rubric.
+func (p *Prompter) PromptPost(ctx context.Context, postID int, rubricName string) {
+	if p == nil || rubricName == "" || postID == 0 {
+		return
+	}
+	rubric, err := p.rubrics.GetRubric(ctx, rubricName)
+	if err != nil {
+		log.Printf("[Engagement Post:%d] Error loading rubric %s: %v", postID, rubricName, err)
+		return
+	}
+	if rubric == nil {
+		return
+	}
+	if rubric.Prompt != "" {
+		p.queueComment(postID, rubric.Prompt, time.Now())
+	}
+	if rubric.Reaction != "" {
+		if err := p.react(ctx, postID, rubric.Reaction); err != nil {
+			log.Printf("[Engagement Post:%d] Error reacting with %s: %v", postID, rubric.Reaction, err)
+		}
+	}
+}
+
+// react sets the bot's reaction on a channel post.
+func (p *Prompter) react(ctx context.Context, postID int, emoji string) error {
+	return p.bot.SetMessageReaction(ctx, &telego.SetMessageReactionParams{
+		ChatID:    tu.ID(p.channelID),
+		MessageID: postID,
+		Reaction:  []telego.ReactionType{&telego.ReactionTypeEmoji{Type: telego.ReactionEmoji, Emoji: emoji}},
+	})
+}
+
+// queueComment stores the comment of a post and drops comments that waited too long.
+func (p *Prompter) queueComment(postID int, text string, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, comment := range p.pending {
+		if now.Sub(comment.queued) > commentWait {
+			delete(p.pending, id)
+		}
+	}
+	p.pending[postID] = pendingComment{text: text, queued: now}
+}
+
+// HandleAutomaticForward posts the pending comment of a channel post under its copy in the
+// discussion group. It reports whether message is the copy of a post with a comment.
+func (p *Prompter) HandleAutomaticForward(ctx context.Context, message telego.Message) (bool, error) {
+	if p == nil || !message.IsAutomaticForward {
+		return false, nil
+	}
+	origin, ok := message.ForwardOrigin.(*telego.MessageOriginChannel)
+	if !ok || origin.Chat.ID != p.channelID {
+		return false, nil
+	}
+	p.mu.Lock()
+	comment, ok := p.pending[origin.MessageID]
+	delete(p.pending, origin.MessageID)
+	p.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+
+	reply := tu.Message(tu.ID(message.Chat.ID), comment.text).
+		WithReplyParameters(&telego.ReplyParameters{MessageID: message.MessageID, AllowSendingWithoutReply: true})
+	if _, err := p.bot.SendMessage(ctx, reply); err != nil {
+		return true, fmt.Errorf("failed to comment under post %d: %w", origin.MessageID, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,187 @@
+// Package errbudget counts operational errors over a rolling window and raises an alert when
+// a source of errors goes over its budget, so operators hear about trouble in Telegram
+// without watching Sentry.
+package errbudget
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	ta "github.com/mymmrac/telego/telegoapi"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// Source is a kind of error counted against the budget.
+type Source string
+
+// Sources of counted errors.
+const (
+	SourceTelegram Source = "telegram" // Failed Bot API calls: network errors, rate limits and server errors
+	SourceDatabase Source = "database" // Failed MongoDB commands
+	SourcePanic    Source = "panic"    // Panics recovered while handling updates
+)
+
+const (
+	// DefaultWindow is how far back errors are counted.
+	DefaultWindow = 10 * time.Minute
+	// DefaultCooldown is the minimum pause between two alerts for the same source.
+	DefaultCooldown = 30 * time.Minute
+	// alertTimeout bounds sending an alert.
+	alertTimeout = 30 * time.Second
+)
+
+// AlertFunc is called when a source goes over its budget: count errors within window.
+type AlertFunc func(ctx context.Context, source Source, count int, window time.Duration)
+
+// Tracker counts errors per source over a rolling window. When the count of a source reaches
+// its threshold, the alert function is called, then not again for that source until the
+// cooldown has passed.
+type Tracker struct {
+	window   time.Duration
+	cooldown time.Duration
+	alert    AlertFunc
+
+	mu         sync.Mutex
+	thresholds map[Source]int         // Sources without a positive threshold are not tracked
+	events     map[Source][]time.Time // Error times within the window, oldest first
+	lastAlert  map[Source]time.Time
+	now        func() time.Time
+}
+
+// NewTracker creates an error tracker. Non-positive durations use the defaults.
+// No source is tracked until its threshold is set.
+func NewTracker(window, cooldown time.Duration) *Tracker {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultCooldown
+	}
+	return &Tracker{
+		window:     window,
+		cooldown:   cooldown,
+		thresholds: make(map[Source]int),
+		events:     make(map[Source][]time.Time),
+		lastAlert:  make(map[Source]time.Time),
+		now:        time.Now,
+	}
+}
+
+// SetThreshold sets how many errors of a source within the window raise an alert; 0 stops tracking it.
+func (t *Tracker) SetThreshold(source Source, threshold int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.thresholds[source] = threshold
+	if threshold <= 0 {
+		delete(t.events, source)
+	}
+}
+
+// Window returns how far back errors are counted.
+func (t *Tracker) Window() time.Duration {
+	return t.window
+}
+
+// Count returns how many errors of a source happened within the window. Sources that aren't
+// tracked always count 0.
+func (t *Tracker) Count(source Source) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cutoff := t.now().Add(-t.window)
+	count := 0
+	for _, at := range t.events[source] {
+		if at.After(cutoff) {
+			count++
+		}
+	}
+	return count
+}
+
+// SetAlertFunc sets the function called when a source goes over its budget.
+func (t *Tracker) SetAlertFunc(alert AlertFunc) {
+	t.alert = alert
+}
+
+// Record counts an error of a source and raises an alert if the source went over its budget.
+// The alert is sent in the background, so recording never blocks on it.
+func (t *Tracker) Record(source Source) {
+	t.mu.Lock()
+	threshold := t.thresholds[source]
+	if threshold <= 0 {
+		t.mu.Unlock()
+		return
+	}
+	now := t.now()
+	events := t.events[source]
+	cutoff := now.Add(-t.window)
+	for len(events) > 0 && !events[0].After(cutoff) {
+		events = events[1:]
+	}
+	events = append(events, now)
+	t.events[source] = events
+	count := len(events)
+	fire := count >= threshold && now.Sub(t.lastAlert[source]) >= t.cooldown
+	if fire {
+		t.lastAlert[source] = now
+	}
+	t.mu.Unlock()
+
+	if !fire {
+		return
+	}
+	log.Printf("[ErrorBudget] %d %s errors within %s, over the budget of %d", count, source, t.window, threshold)
+	if t.alert == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), alertTimeout)
+		defer cancel()
+		t.alert(ctx, source, count, t.window)
+	}()
+}
+
+// telegramCaller is a Bot API caller that counts failed calls.
+type telegramCaller struct {
+	next    ta.Caller
+	tracker *Tracker
+}
+
+// TelegramCaller wraps a Bot API caller (see telego.WithAPICaller) so failed calls are counted
+// as Telegram errors. Only failures that point at Telegram or the network count: transport
+// errors, 429 Too Many Requests and 5xx responses. Other error responses, such as a user
+// having blocked the bot, are part of normal operation.
+func (t *Tracker) TelegramCaller(next ta.Caller) ta.Caller {
+	return &telegramCaller{next: next, tracker: t}
+}
+
+// Call performs the API call and counts it if it failed.
+func (c *telegramCaller) Call(ctx context.Context, url string, data *ta.RequestData) (*ta.Response, error) {
+	resp, err := c.next.Call(ctx, url, data)
+	switch {
+	case err != nil:
+		if !errors.Is(err, context.Canceled) && ctx.Err() != context.Canceled {
+			c.tracker.Record(SourceTelegram)
+		}
+	case resp != nil && !resp.Ok && resp.Error != nil &&
+		(resp.ErrorCode == http.StatusTooManyRequests || resp.ErrorCode >= http.StatusInternalServerError):
+		c.tracker.Record(SourceTelegram)
+	}
+	return resp, err
+}
+
+// CommandMonitor returns a MongoDB command monitor that counts failed commands as database errors.
+// Commands that never start because no server is reachable are not seen by it; outages are
+// reported by degraded mode instead.
+func (t *Tracker) CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Failed: func(ctx context.Context, _ *event.CommandFailedEvent) {
+			if ctx.Err() != context.Canceled {
+				t.Record(SourceDatabase)
+			}
+		},
+	}
+}
@@ -0,0 +1,88 @@
+package errbudget
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ta "github.com/mymmrac/telego/telegoapi"
+	"github.com/stretchr/testify/assert"
+)
+
+type alertCall struct {
+	source Source
+	count  int
+}
+
+// callerFunc adapts a function to ta.Caller.
+type callerFunc func() (*ta.Response, error)
+
+func (f callerFunc) Call(context.Context, string, *ta.RequestData) (*ta.Response, error) { return f() }
+
+func newTestTracker(now *time.Time) (*Tracker, chan alertCall) {
+	tracker := NewTracker(10*time.Minute, 30*time.Minute)
+	tracker.now = func() time.Time { return *now }
+	alerts := make(chan alertCall, 10)
+	tracker.SetAlertFunc(func(_ context.Context, source Source, count int, _ time.Duration) {
+		alerts <- alertCall{source, count}
+	})
+	return tracker, alerts
+}
+
+func TestTrackerAlertsOncePerCooldown(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker, alerts := newTestTracker(&now)
+	tracker.SetThreshold(SourceDatabase, 3)
+
+	tracker.Record(SourceDatabase)
+	tracker.Record(SourceDatabase)
+	// Errors older than the window don't count
+	now = now.Add(11 * time.Minute)
+	tracker.Record(SourceDatabase)
+	tracker.Record(SourceDatabase)
+	assert.Empty(t, alerts)
+
+	tracker.Record(SourceDatabase)
+	assert.Equal(t, alertCall{SourceDatabase, 3}, <-alerts)
+
+	// Within the cooldown, errors are counted without alerting
+	now = now.Add(time.Minute)
+	tracker.Record(SourceDatabase)
+	now = now.Add(30 * time.Minute)
+	tracker.Record(SourceDatabase)
+	tracker.Record(SourceDatabase)
+	tracker.Record(SourceDatabase)
+	assert.Equal(t, alertCall{SourceDatabase, 3}, <-alerts)
+
+	// Sources without a threshold are ignored
+	tracker.Record(SourcePanic)
+	time.Sleep(10 * time.Millisecond)
+	assert.Empty(t, alerts)
+}
+
+func TestTelegramCallerCountsOnlyOperationalFailures(t *testing.T) {
+	now := time.Now()
+	tracker, alerts := newTestTracker(&now)
+	tracker.SetThreshold(SourceTelegram, 3)
+
+	responses := []func() (*ta.Response, error){
+		func() (*ta.Response, error) { return &ta.Response{Ok: true}, nil },
+		func() (*ta.Response, error) {
+			return &ta.Response{Error: &ta.Error{ErrorCode: 403, Description: "Forbidden: bot was blocked by the user"}}, nil
+		},
+		func() (*ta.Response, error) { return nil, context.Canceled },
+		func() (*ta.Response, error) { return nil, errors.New("connection reset") },
+		func() (*ta.Response, error) { return &ta.Response{Error: &ta.Error{ErrorCode: 429}}, nil },
+	}
+	for _, respond := range responses {
+		_, _ = tracker.TelegramCaller(callerFunc(respond)).Call(context.Background(), "", nil)
+	}
+	time.Sleep(10 * time.Millisecond)
+	assert.Empty(t, alerts)
+
+	_, _ = tracker.TelegramCaller(callerFunc(func() (*ta.Response, error) {
+		return &ta.Response{Error: &ta.Error{ErrorCode: 502}}, nil
+	})).Call(context.Background(), "", nil)
+	assert.Equal(t, alertCall{SourceTelegram, 3}, <-alerts)
+}
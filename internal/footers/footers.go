@@ -0,0 +1,76 @@
+// Package footers rotates a pool of hashtag footers across channel posts, so posts vary the
+// hashtags they can be discovered by. The position in the pool is stored in the bot settings and
+// survives restarts.
+package footers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+)
+
+// MaxCaptionLength is Telegram's caption limit for media messages. A footer that would push a
+// caption past it is left out.
+const MaxCaptionLength = 1024
+
+// Rotator hands out the footers of a pool in turn.
+type Rotator struct {
+	footers  []string
+	settings database.SettingsRepository
+
+	mu sync.Mutex // Serializes reading and advancing the rotation
+}
+
+// NewRotator creates a rotator over footers, storing its position in settings.
+func NewRotator(footers []string, settings database.SettingsRepository) *Rotator {
+	return &Rotator{footers: footers, settings: settings}
+}
+
+// Next returns the footer for the next post and advances the rotation. It returns "" if the pool
+// is empty. Without a stored position, or if it can't be read, rotation starts over at the first
+// footer.
+func (r *Rotator) Next(ctx context.Context) (string, error) {
+	if r == nil || len(r.footers) == 0 {
+		return "", nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index := 0
+	setting, err := r.settings.GetSetting(ctx, models.SettingFooterRotation)
+	if err != nil {
+		return r.footers[0], fmt.Errorf("failed to load footer rotation: %w", err)
+	}
+	if setting != nil {
+		if n, convErr := strconv.Atoi(setting.Value); convErr == nil && n >= 0 {
+			// The pool may have shrunk since the position was stored
+			index = n % len(r.footers)
+		}
+	}
+	next := strconv.Itoa((index + 1) % len(r.footers))
+	if err := r.settings.SetSetting(ctx, models.SettingFooterRotation, next, 0); err != nil {
+		return r.footers[index], fmt.Errorf("failed to store footer rotation: %w", err)
+	}
+	return r.footers[index], nil
+}
+
+// Append adds footer to caption after a blank line. The caption is returned unchanged if footer
+// is empty or the result would be too long for a caption.
+func Append(caption, footer string) string {
+	if footer == "" {
+		return caption
+	}
+	result := footer
+	if strings.TrimSpace(caption) != "" {
+		result = caption + "\n\n" + footer
+	}
+	if utf8.RuneCountInString(result) > MaxCaptionLength {
+		return caption
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+package footers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"vrcmemes-bot/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySettingsRepo is an in-memory SettingsRepository.
+type memorySettingsRepo map[string]string
+
+func (r memorySettingsRepo) GetSetting(ctx context.Context, key string) (*models.Setting, error) {
+	value, ok := r[key]
+	if !ok {
+		return nil, nil
+	}
+	return &models.Setting{Key: key, Value: value}, nil
+}
+
+func (r memorySettingsRepo) SetSetting(ctx context.Context, key, value string, updatedBy int64) error {
+	r[key] = value
+	return nil
+}
+
+func (r memorySettingsRepo) DeleteSetting(ctx context.Context, key string) error {
+	delete(r, key)
+	return nil
+}
+
+func TestRotatorCyclesThroughFooters(t *testing.T) {
+	ctx := context.Background()
+	settings := memorySettingsRepo{}
+	rotator := NewRotator([]string{"#vrchat", "#memes #vrc", "#vrcmemes"}, settings)
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		footer, err := rotator.Next(ctx)
+		require.NoError(t, err)
+		got = append(got, footer)
+	}
+	assert.Equal(t, []string{"#vrchat", "#memes #vrc", "#vrcmemes", "#vrchat"}, got)
+	assert.Equal(t, "1", settings[models.SettingFooterRotation])
+
+	// A new rotator, e.g. after a restart with a smaller pool, continues where the last one stopped
+	settings[models.SettingFooterRotation] = "2"
+	footer, err := NewRotator([]string{"#a", "#b"}, settings).Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "#a", footer)
+
+	var none *Rotator
+	footer, err = none.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "", footer)
+}
+
+func TestAppend(t *testing.T) {
+	assert.Equal(t, "Caption\n\n#vrchat", Append("Caption", "#vrchat"))
+	assert.Equal(t, "#vrchat", Append("", "#vrchat"))
+	assert.Equal(t, "Caption", Append("Caption", ""))
+	long := strings.Repeat("a", MaxCaptionLength-5)
+	assert.Equal(t, long, Append(long, "#vrchat"), "footers that don't fit are left out")
+}
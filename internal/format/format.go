@@ -0,0 +1,105 @@
+// Package format formats numbers, durations and relative times for users in their language,
+// using the Fmt* messages of the locale files.
+package format
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// unit is a unit of time with the messages that name an amount of it.
+type unit struct {
+	size time.Duration
+	name string // Plural message for a duration, e.g. "2 hours"
+	ago  string // Plural message for a past time, e.g. "2 hours ago"
+	in   string // Plural message for a future time, e.g. "in 2 hours"
+}
+
+// units are the units durations are shown in, largest first.
+var units = []unit{
+	{24 * time.Hour, "FmtDays", "FmtDaysAgo", "FmtInDays"},
+	{time.Hour, "FmtHours", "FmtHoursAgo", "FmtInHours"},
+	{time.Minute, "FmtMinutes", "FmtMinutesAgo", "FmtInMinutes"},
+	{time.Second, "FmtSeconds", "FmtSecondsAgo", "FmtInSeconds"},
+}
+
+// Number formats an integer with the thousands separator of the localizer's language,
+// e.g. "12,345" in English and "12 345" in Russian.
+func Number(localizer *i18n.Localizer, n int64) string {
+	digits := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if len(digits) <= 3 {
+		return sign + digits
+	}
+	separator := locales.GetMessage(localizer, "FmtThousandsSeparator", nil, nil)
+
+	var b strings.Builder
+	b.WriteString(sign)
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if i > 0 {
+			b.WriteString(separator)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+// Duration formats a duration in its two largest units, e.g. "2 hours 5 minutes" or "3 days".
+// Durations under a second are shown as "0 seconds"; negative durations as their absolute value.
+func Duration(localizer *i18n.Localizer, d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	var parts []string
+	for i, u := range units {
+		count := int(d / u.size)
+		if count == 0 {
+			continue
+		}
+		parts = append(parts, locales.GetPluralMessage(localizer, u.name, count, nil))
+		d -= time.Duration(count) * u.size
+		// The second part is only shown if it is the next smaller unit, so "1 day 30 seconds" isn't shown
+		if len(parts) == 2 || i+1 < len(units) && d < units[i+1].size {
+			break
+		}
+	}
+	if len(parts) == 0 {
+		return locales.GetPluralMessage(localizer, "FmtSeconds", 0, nil)
+	}
+	return strings.Join(parts, " ")
+}
+
+// RelativeTime formats t relative to now in its largest unit, e.g. "2 hours ago" or "in 3 days".
+// Times less than a minute away are "just now".
+func RelativeTime(localizer *i18n.Localizer, t, now time.Time) string {
+	d := now.Sub(t)
+	past := d >= 0
+	if !past {
+		d = -d
+	}
+	if d < time.Minute {
+		return locales.GetMessage(localizer, "FmtJustNow", nil, nil)
+	}
+	for _, u := range units {
+		if d < u.size {
+			continue
+		}
+		count := int(d / u.size)
+		if past {
+			return locales.GetPluralMessage(localizer, u.ago, count, nil)
+		}
+		return locales.GetPluralMessage(localizer, u.in, count, nil)
+	}
+	return locales.GetMessage(localizer, "FmtJustNow", nil, nil)
+}
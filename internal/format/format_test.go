@@ -0,0 +1,32 @@
+package format
+
+import (
+	"testing"
+	"time"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	locales.Init("en")
+	en := locales.NewLocalizer("en")
+	ru := locales.NewLocalizer("ru")
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "999", Number(en, 999))
+	assert.Equal(t, "12,345", Number(en, 12345))
+	assert.Equal(t, "-1,234,567", Number(en, -1234567))
+	assert.Equal(t, "12 345", Number(ru, 12345))
+
+	assert.Equal(t, "2 hours 5 minutes", Duration(en, 2*time.Hour+5*time.Minute+10*time.Second))
+	assert.Equal(t, "1 day", Duration(en, 24*time.Hour+30*time.Second))
+	assert.Equal(t, "0 seconds", Duration(en, 0))
+	assert.Equal(t, "21 минута", Duration(ru, 21*time.Minute))
+
+	assert.Equal(t, "just now", RelativeTime(en, now.Add(-30*time.Second), now))
+	assert.Equal(t, "3 days ago", RelativeTime(en, now.Add(-75*time.Hour), now))
+	assert.Equal(t, "in 1 hour", RelativeTime(en, now.Add(90*time.Minute), now))
+	assert.Equal(t, "2 часа назад", RelativeTime(ru, now.Add(-2*time.Hour), now))
+	assert.Equal(t, "через 5 минут", RelativeTime(ru, now.Add(5*time.Minute), now))
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// DefaultCaptionTestWindow is how long after publication the reactions of a caption experiment are measured.
+	DefaultCaptionTestWindow = 24 * time.Hour
+	// captionTestMeasureInterval is how often experiments whose window has passed are measured.
+	captionTestMeasureInterval = 10 * time.Minute
+	// maxCaptionFamilyLength bounds caption family names.
+	maxCaptionFamilyLength = 24
+	// captionVariantSeparator separates the two variants in /abtest.
+	captionVariantSeparator = "||"
+)
+
+// abTestArgs declares the arguments of /abtest. It is only used for the usage line:
+// the variants are taken from the message as typed.
+var abTestArgs = cmdargs.Spec{
+	Command: "abtest",
+	Args: []cmdargs.Arg{
+		{Name: "report|off|family: caption || family: caption", Rest: true},
+	},
+}
+
+// errCaptionVariantsMalformed is returned by parseCaptionVariants for text not in the /abtest format.
+var errCaptionVariantsMalformed = errors.New("malformed caption variants")
+
+// SetExperimentRepository sets where caption experiments are stored and how long after
+// publication their reactions are measured. Without it, /abtest is unavailable.
+func (h *MessageHandler) SetExperimentRepository(repo database.ExperimentRepository, window time.Duration) {
+	h.experimentRepo = repo
+	h.captionTestWindow = window
+}
+
+// StartCaptionExperimentMeasurements periodically measures the reactions of caption experiments
+// whose window has passed, until ctx is done.
+func (h *MessageHandler) StartCaptionExperimentMeasurements(ctx context.Context) {
+	if h.experimentRepo == nil {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(captionTestMeasureInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				measured, err := h.experimentRepo.MeasureExperiments(ctx, time.Now().Add(-h.captionTestWindow))
+				if err != nil {
+					log.Printf("[CaptionTest] Error measuring experiments: %v", err)
+				} else if measured > 0 {
+					log.Printf("[CaptionTest] Measured %d experiments", measured)
+				}
+			}
+		}
+	}()
+}
+
+// HandleABTest handles the /abtest command (admin only):
+//
+//	/abtest <family>: <caption> || <family>: <caption>  — test two captions on the next post
+//	/abtest off                                        — cancel the test of the next post
+//	/abtest report                                     — compare the caption families
+//
+// The next photo or video sent in the chat is published with variant A. Its reactions are
+// measured once the test window has passed, and the report averages them by the family of
+// the published caption, so caption styles can be compared over time.
+func (h *MessageHandler) HandleABTest(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:abtest User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:abtest User:%d] Non-admin user attempted to use /abtest.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	if h.experimentRepo == nil {
+		return h.sendError(ctx, bot, chatID, errors.New("experiment repository is not configured"))
+	}
+
+	text, _ := commandArgsText(message)
+	switch {
+	case text == "":
+		return h.sendUsageError(ctx, bot, chatID, localizer, &cmdargs.UsageError{
+			MessageID: "MsgArgsMissing",
+			Data:      map[string]interface{}{"Arg": "report|off|family: caption || family: caption", "Usage": abTestArgs.Usage()},
+		})
+
+	case strings.EqualFold(text, "report"):
+		stats, err := h.experimentRepo.CaptionFamilyStats(ctx)
+		if err != nil {
+			return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to get caption family stats: %w", err))
+		}
+		return h.sendSuccess(ctx, bot, chatID, formatCaptionFamilyStats(localizer, stats, h.captionTestWindow))
+
+	case strings.EqualFold(text, "off"):
+		if _, ok := h.pendingExperiments.LoadAndDelete(chatID); !ok {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgABTestNonePending", nil, nil))
+		}
+		log.Printf("[Cmd:abtest User:%d] Caption test of the next post cancelled.", userID)
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgABTestCancelled", nil, nil))
+	}
+
+	variantA, variantB, err := parseCaptionVariants(text)
+	if err != nil {
+		log.Printf("[Cmd:abtest User:%d] Invalid variants: %v", userID, err)
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgABTestInvalid", map[string]interface{}{
+			"Usage": abTestArgs.Usage(),
+			"Max":   maxCaptionFamilyLength,
+		}, nil))
+	}
+	h.pendingExperiments.Store(chatID, &models.CaptionExperiment{
+		VariantA:  variantA,
+		VariantB:  variantB,
+		CreatedBy: userID,
+	})
+	log.Printf("[Cmd:abtest User:%d] Caption test armed: %s vs %s", userID, variantA.Family, variantB.Family)
+	h.RecordUserActivity(ctx, message.From, ActionCommandABTest, isAdmin, map[string]interface{}{
+		"chat_id":  chatID,
+		"family_a": variantA.Family,
+		"family_b": variantB.Family,
+	})
+	return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgABTestArmed", map[string]interface{}{
+		"FamilyA": variantA.Family,
+		"FamilyB": variantB.Family,
+	}, nil))
+}
+
+// parseCaptionVariants parses "<family>: <caption> || <family>: <caption>". Families are
+// lowercased words of letters, digits and underscores, and must differ.
+func parseCaptionVariants(text string) (models.CaptionVariant, models.CaptionVariant, error) {
+	parts := strings.Split(text, captionVariantSeparator)
+	if len(parts) != 2 {
+		return models.CaptionVariant{}, models.CaptionVariant{}, fmt.Errorf("%w: expected two variants separated by %q", errCaptionVariantsMalformed, captionVariantSeparator)
+	}
+	var variants [2]models.CaptionVariant
+	for i, part := range parts {
+		family, caption, found := strings.Cut(part, ":")
+		family = strings.ToLower(strings.TrimSpace(family))
+		caption = strings.TrimSpace(caption)
+		if !found || caption == "" || !isCaptionFamily(family) {
+			return models.CaptionVariant{}, models.CaptionVariant{}, fmt.Errorf("%w: variant %d", errCaptionVariantsMalformed, i+1)
+		}
+		variants[i] = models.CaptionVariant{Family: family, Caption: caption}
+	}
+	if variants[0].Family == variants[1].Family {
+		return models.CaptionVariant{}, models.CaptionVariant{}, fmt.Errorf("%w: both variants are %q", errCaptionVariantsMalformed, variants[0].Family)
+	}
+	return variants[0], variants[1], nil
+}
+
+// isCaptionFamily reports whether a string is a valid caption family name.
+func isCaptionFamily(family string) bool {
+	if family == "" || len(family) > maxCaptionFamilyLength {
+		return false
+	}
+	for _, r := range family {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// captionExperimentCaption returns the caption the next post in a chat is published with:
+// variant A of a pending caption test, which is taken, or the given caption otherwise.
+func (h *MessageHandler) captionExperimentCaption(chatID int64, caption string) (string, *models.CaptionExperiment) {
+	pending, ok := h.pendingExperiments.LoadAndDelete(chatID)
+	if !ok {
+		return caption, nil
+	}
+	experiment := pending.(*models.CaptionExperiment)
+	return experiment.VariantA.Caption, experiment
+}
+
+// finishCaptionExperiment stores the experiment of a published post. If the post wasn't
+// published, the test is kept for the next post of the chat instead.
+func (h *MessageHandler) finishCaptionExperiment(ctx context.Context, chatID int64, experiment *models.CaptionExperiment, channelPostID int, publishErr error) {
+	if experiment == nil || h.experimentRepo == nil {
+		return
+	}
+	if publishErr != nil {
+		h.pendingExperiments.LoadOrStore(chatID, experiment)
+		return
+	}
+	experiment.ChannelID = h.channelID
+	experiment.ChannelPostID = channelPostID
+	if err := h.experimentRepo.CreateExperiment(ctx, experiment); err != nil {
+		log.Printf("[CaptionTest Chat:%d] Error saving experiment of post %d: %v", chatID, channelPostID, err)
+	}
+}
+
+// formatCaptionFamilyStats formats the /abtest report.
+func formatCaptionFamilyStats(localizer *i18n.Localizer, stats []models.CaptionFamilyStat, window time.Duration) string {
+	if len(stats) == 0 {
+		return locales.GetMessage(localizer, "MsgABTestReportEmpty", map[string]interface{}{
+			"Hours": int(window.Hours()),
+		}, nil)
+	}
+	var sb strings.Builder
+	sb.WriteString(locales.GetMessage(localizer, "MsgABTestReportHeader", map[string]interface{}{
+		"Hours": int(window.Hours()),
+	}, nil))
+	for _, stat := range stats {
+		sb.WriteString("\n")
+		sb.WriteString(locales.GetPluralMessage(localizer, "MsgABTestReportLine", int(stat.Posts), map[string]interface{}{
+			"Family":    stat.Family,
+			"Reactions": fmt.Sprintf("%.1f", stat.AvgReactions),
+		}))
+	}
+	if len(stats) > 1 {
+		sb.WriteString("\n\n")
+		sb.WriteString(locales.GetMessage(localizer, "MsgABTestReportLeader", map[string]interface{}{
+			"Family": stats[0].Family,
+		}, nil))
+	}
+	return sb.String()
+}
@@ -20,6 +20,46 @@ const (
 	ActionReviewAction            = "review_action"
 	ActionCommandFeedback         = "command_feedback"
 	ActionSendFeedback            = "send_feedback"
+	ActionCommandLanguage         = "command_language"
+	ActionCommandGrowth           = "command_growth"
+	ActionCommandMySuggestions    = "command_my_suggestions"
+	ActionCommandRefreshAdmins    = "command_refresh_admins"
+	ActionCommandPostURL          = "command_post_url"
+	ActionCommandFeedbacks        = "command_feedbacks"
+	ActionCommandDiag             = "command_diag"
+	ActionCommandUser             = "command_user"
+	ActionCommandSetGreeting      = "command_set_greeting"
+	ActionCommandRules            = "command_rules"
+	ActionCommandStats            = "command_stats"
+	ActionCommandAdminStats       = "command_admin_stats"
+	ActionCommandRubric           = "command_rubric"
+	ActionCommandStyle            = "command_style"
+	ActionCommandQuietHours       = "command_quiet_hours"
+	ActionCommandThrowback        = "command_throwback"
+	ActionCommandBestOf           = "command_best_of"
+	ActionCommandABTest           = "command_ab_test"
+	ActionCommandIncidents        = "command_incidents"
+	ActionCommandFind             = "command_find"
+	ActionCommandSetup            = "command_setup"
+	ActionCommandConnect          = "command_connect"
+	ActionCommandChannel          = "command_channel"
+	ActionCommandTenants          = "command_tenants"
+	ActionCommandSuggestButton    = "command_suggestbutton"
+	ActionCommandPin              = "command_pin"
+	ActionPinPost                 = "pin_post"
+	ActionUnpinPost               = "unpin_post"
+	ActionCommandQueueExport      = "command_queue_export"
+	ActionCommandApply            = "command_apply"
+	ActionCommandPerm             = "command_perm"
+	ActionCommandWordFilter       = "command_wordfilter"
+	ActionCommandReopen           = "command_reopen"
+	ActionCommandNotifications    = "command_notifications"
+	ActionCommandDebug            = "command_debug"
+	ActionCommandTrace            = "command_trace"
+	ActionCommandSimulate         = "command_simulate"
+	ActionCommandPurgeTest        = "command_purgetest"
+	ActionCommandContentTypes     = "command_contenttypes"
+	ActionCommandAutoReject       = "command_autoreject"
 )
 
 // Utility function to send a success message.
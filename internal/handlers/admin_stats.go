@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/format"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+	"vrcmemes-bot/pkg/utils"
+
+	"github.com/mymmrac/telego"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// adminStatsArgs declares the arguments of /adminstats.
+var adminStatsArgs = cmdargs.Spec{
+	Command: "adminstats",
+	Args: []cmdargs.Arg{
+		{Name: "days"},
+	},
+}
+
+// directPostActions are the user actions recorded when an admin sends a post to the channel through the bot.
+var directPostActions = []string{
+	ActionSendTextToChannel,
+	ActionSendPhotoToChannel,
+	ActionSendVideoToChannel,
+	ActionSendMediaGroupToChannel,
+	ActionCommandPostURL,
+}
+
+// HandleAdminStats handles the /adminstats [days] command (admin only): per admin, how many
+// suggestions they approved and rejected, how many posts they sent directly and how long
+// suggestions waited for their review on average, over the last days (30 by default).
+func (h *MessageHandler) HandleAdminStats(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:adminstats User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:adminstats User:%d] Non-admin user attempted to use /adminstats.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	if h.statsRepo == nil {
+		return h.sendError(ctx, bot, chatID, errors.New("stats repository is not configured"))
+	}
+
+	args, err := adminStatsArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	days := defaultStatsDays
+	if raw := args.Arg("days"); raw != "" {
+		days, err = strconv.Atoi(raw)
+		if err != nil || days < 1 || days > maxStatsDays {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgStatsInvalidDays", map[string]interface{}{
+				"Max": maxStatsDays,
+			}, nil))
+		}
+	}
+	since := utils.StartOfDay(time.Now(), h.location).AddDate(0, 0, 1-days)
+
+	activity, err := h.statsRepo.AdminActivity(ctx, since, directPostActions)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to get admin activity: %w", err))
+	}
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandAdminStats, isAdmin, map[string]interface{}{
+		"chat_id": chatID,
+		"days":    days,
+	})
+
+	if len(activity) == 0 {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgAdminStatsEmpty", map[string]interface{}{
+			"Days": days,
+		}, nil))
+	}
+	var text strings.Builder
+	text.WriteString(locales.GetMessage(localizer, "MsgAdminStatsHeader", map[string]interface{}{
+		"Days": days,
+	}, nil))
+	for _, admin := range activity {
+		text.WriteString("\n\n")
+		text.WriteString(h.formatAdminActivity(ctx, localizer, admin))
+	}
+	return h.sendSuccess(ctx, bot, chatID, text.String())
+}
+
+// formatAdminActivity formats the /adminstats entry of an admin. Admins who only posted directly
+// have no reviewer username stored with suggestions, so it is looked up in the users collection.
+func (h *MessageHandler) formatAdminActivity(ctx context.Context, localizer *i18n.Localizer, admin models.AdminActivity) string {
+	name := admin.Username
+	if name == "" && h.userRepo != nil {
+		if user, err := h.userRepo.GetUser(ctx, admin.AdminID); err == nil && user != nil {
+			name = user.Username
+		}
+	}
+	if name != "" {
+		name = "@" + name
+	} else {
+		name = strconv.FormatInt(admin.AdminID, 10)
+	}
+
+	response := "—"
+	if admin.Reviewed() > 0 {
+		response = format.Duration(localizer, time.Duration(admin.AverageResponseMillis)*time.Millisecond)
+	}
+	return locales.GetMessage(localizer, "MsgAdminStatsLine", map[string]interface{}{
+		"Name":        name,
+		"Approved":    format.Number(localizer, admin.Approved),
+		"Rejected":    format.Number(localizer, admin.Rejected),
+		"DirectPosts": format.Number(localizer, admin.DirectPosts),
+		"Response":    response,
+	}, nil)
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/suggestions"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// autoRejectArgs declares the arguments of /autoreject.
+var autoRejectArgs = cmdargs.Spec{
+	Command: "autoreject",
+	Args: []cmdargs.Arg{
+		{Name: "rule|default", Choices: []string{
+			suggestions.AutoRejectRuleCaption,
+			suggestions.AutoRejectRuleMedia,
+			suggestions.AutoRejectRuleAccount,
+			suggestions.AutoRejectRuleSubscription,
+			"default",
+		}},
+		{Name: "value|off", Rest: true},
+	},
+}
+
+// SetAutoRejectRulesDefault sets the auto-reject rules applied when /autoreject hasn't changed
+// them, i.e. the AUTO_REJECT_* variables.
+func (h *MessageHandler) SetAutoRejectRulesDefault(rules suggestions.AutoRejectRules) {
+	h.autoRejectRules = rules
+}
+
+// autoRejectRulesSetting returns the auto-reject rules set with /autoreject, or the default if
+// none were set or they can't be loaded.
+func (h *MessageHandler) autoRejectRulesSetting(ctx context.Context) suggestions.AutoRejectRules {
+	setting, err := h.settingsRepo.GetSetting(ctx, models.SettingAutoRejectRules)
+	if err != nil {
+		log.Printf("[Setup] Error loading setting %s: %v", models.SettingAutoRejectRules, err)
+		return h.autoRejectRules
+	}
+	if setting == nil {
+		return h.autoRejectRules
+	}
+	rules, err := suggestions.ParseAutoRejectRules(setting.Value)
+	if err != nil {
+		log.Printf("[Setup] Ignoring the stored auto-reject rules: %v", err)
+		return h.autoRejectRules
+	}
+	return rules
+}
+
+// HandleAutoReject handles the /autoreject [rule value|off|default] command (admin only).
+// Without arguments, it shows the rules suggestions are rejected by on submission. Otherwise it
+// changes one rule: "caption <regex>", "media <files>", "account <days>" or
+// "subscription <days>", where "off" disables the rule. The rules are stored as a setting and
+// take precedence over the AUTO_REJECT_* variables, which "default" goes back to.
+func (h *MessageHandler) HandleAutoReject(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:autoreject User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:autoreject User:%d] Non-admin user attempted to use /autoreject.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+
+	args, err := autoRejectArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	rule, value := args.Arg("rule|default"), args.Arg("value|off")
+	if rule == "" {
+		return h.sendSuccess(ctx, bot, chatID, describeAutoRejectRules(localizer, h.suggestionManager.AutoRejectRules()))
+	}
+	if h.settingsRepo == nil {
+		return h.sendError(ctx, bot, chatID, errors.New("settings repository is not configured"))
+	}
+
+	var rules suggestions.AutoRejectRules
+	if rule == "default" {
+		if err := h.settingsRepo.DeleteSetting(ctx, models.SettingAutoRejectRules); err != nil {
+			return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to reset auto-reject rules: %w", err))
+		}
+		rules = h.autoRejectRules
+	} else {
+		if value == "" {
+			return h.sendUsageError(ctx, bot, chatID, localizer, &cmdargs.UsageError{
+				MessageID: "MsgArgsMissing",
+				Data:      map[string]interface{}{"Arg": "value|off", "Usage": autoRejectArgs.Usage()},
+			})
+		}
+		rules, err = h.suggestionManager.AutoRejectRules().With(rule, value)
+		if err != nil {
+			log.Printf("[Cmd:autoreject User:%d] Invalid value for rule %s: %v", userID, rule, err)
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgAutoRejectInvalid", map[string]interface{}{"Rule": rule}, nil))
+		}
+		if err := h.settingsRepo.SetSetting(ctx, models.SettingAutoRejectRules, rules.String(), userID); err != nil {
+			return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to save auto-reject rules: %w", err))
+		}
+	}
+	h.suggestionManager.SetAutoRejectRules(rules)
+	log.Printf("[Cmd:autoreject User:%d] Auto-reject rules set to %s", userID, rules)
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandAutoReject, true, map[string]interface{}{
+		"chat_id": chatID,
+		"rule":    rule,
+		"value":   value,
+	})
+	return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgAutoRejectUpdated", nil, nil)+"\n\n"+describeAutoRejectRules(localizer, rules))
+}
+
+// describeAutoRejectRules lists the auto-reject rules for /autoreject.
+func describeAutoRejectRules(localizer *i18n.Localizer, rules suggestions.AutoRejectRules) string {
+	if rules.Empty() {
+		return locales.GetMessage(localizer, "MsgAutoRejectRulesNone", nil, nil)
+	}
+	return locales.GetMessage(localizer, "MsgAutoRejectRules", map[string]interface{}{"Rules": rules.Describe(localizer)}, nil)
+}
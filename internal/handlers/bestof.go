@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+	"vrcmemes-bot/pkg/utils"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const (
+	// defaultBestOfCount is how many posts /bestof puts into the album when no count is given.
+	defaultBestOfCount = 10
+	// minBestOfCount and maxBestOfCount are the album size limits of Telegram.
+	minBestOfCount = 2
+	maxBestOfCount = 10
+)
+
+// bestOfPeriods maps the /bestof periods to their length in days.
+var bestOfPeriods = map[string]int{"week": 7, "month": 30}
+
+// bestOfArgs declares the arguments of /bestof.
+var bestOfArgs = cmdargs.Spec{
+	Command: "bestof",
+	Args: []cmdargs.Arg{
+		{Name: "period", Required: true, Choices: []string{"week", "month"}},
+		{Name: "count"},
+	},
+}
+
+// RecordReactionCount stores the reaction total of a channel post from a message_reaction_count update.
+// Updates from other chats are ignored.
+func (h *MessageHandler) RecordReactionCount(ctx context.Context, update telego.MessageReactionCountUpdated) {
+	if update.Chat.ID != h.channelID || h.statsRepo == nil {
+		return
+	}
+	total := 0
+	for _, reaction := range update.Reactions {
+		total += reaction.TotalCount
+	}
+	if err := h.statsRepo.SetPostReactionCount(ctx, h.channelID, update.MessageID, total); err != nil {
+		log.Printf("[Reactions] Error storing reaction count of post %d: %v", update.MessageID, err)
+	}
+}
+
+// HandleBestOf handles the /bestof <week|month> [count] command (admin only).
+// It publishes an album of the photos and videos of the last week or month with the most
+// reactions (10 by default), counting today as the first day in the channel time zone.
+// Reactions are only known with TRACK_REACTIONS enabled. A compilation of a period is
+// published at most once a day.
+func (h *MessageHandler) HandleBestOf(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:bestof User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:bestof User:%d] Non-admin user attempted to use /bestof.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	if h.statsRepo == nil {
+		return h.sendError(ctx, bot, chatID, errors.New("stats repository is not configured"))
+	}
+
+	args, err := bestOfArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	period := args.Arg("period")
+	count := defaultBestOfCount
+	if raw := args.Arg("count"); raw != "" {
+		count, err = strconv.Atoi(raw)
+		if err != nil || count < minBestOfCount || count > maxBestOfCount {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgBestOfInvalidCount", map[string]interface{}{
+				"Min": minBestOfCount,
+				"Max": maxBestOfCount,
+			}, nil))
+		}
+	}
+
+	today := utils.StartOfDay(time.Now(), h.location)
+	since := today.AddDate(0, 0, 1-bestOfPeriods[period])
+	posts, err := h.statsRepo.TopPosts(ctx, h.channelID, since, count)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to get top posts: %w", err))
+	}
+	if len(posts) < minBestOfCount {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgBestOfTooFew", nil, nil))
+	}
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandBestOf, isAdmin, map[string]interface{}{
+		"chat_id": chatID,
+		"period":  period,
+		"count":   len(posts),
+	})
+
+	caption := bestOfCaption(period, since, today, len(posts))
+	media := make([]telego.InputMedia, 0, len(posts))
+	for i, post := range posts {
+		var item telego.InputMedia
+		if post.MessageType == "video" {
+			video := tu.MediaVideo(tu.FileFromID(post.FileID))
+			if i == 0 {
+				video.Caption = caption
+			}
+			item = video
+		} else {
+			photo := tu.MediaPhoto(tu.FileFromID(post.FileID))
+			if i == 0 {
+				photo.Caption = caption
+			}
+			item = photo
+		}
+		media = append(media, item)
+	}
+
+	key := fmt.Sprintf("bestof:%s:%s", period, today.Format(time.DateOnly))
+	protect := h.ProtectContent(ctx)
+	position := h.publishQueue.Submit(ctx, key, func(ctx context.Context) error {
+		params := tu.MediaGroup(tu.ID(h.channelID), media...)
+		params.ProtectContent = protect
+		sent, err := bot.SendMediaGroup(ctx, params)
+		if err != nil {
+			return err
+		}
+		log.Printf("[Cmd:bestof User:%d] Published the best of the %s with %d posts", userID, period, len(media))
+		logEntry := models.PostLog{
+			SenderID:       userID,
+			SenderUsername: message.From.Username,
+			Caption:        caption,
+			MessageType:    "best_of",
+			ProtectContent: protect,
+			ReceivedAt:     time.Unix(int64(message.Date), 0),
+			PublishedAt:    time.Now(),
+			ChannelID:      h.channelID,
+			Origin:         models.PostOriginDirect,
+		}
+		if len(sent) > 0 {
+			logEntry.ChannelPostID = sent[0].MessageID
+		}
+		if err := h.postLogger.LogPublishedPost(logEntry); err != nil {
+			log.Printf("[Cmd:bestof User:%d] Failed attempt to log best-of album to DB. Error: %v", userID, err)
+		}
+		return nil
+	}, func(ctx context.Context, err error) {
+		if err != nil {
+			log.Printf("[Cmd:bestof User:%d] Failed to publish the best of the %s: %v", userID, period, err)
+		}
+		h.ReportPublishResult(ctx, bot, localizer, chatID, err)
+	})
+	return h.SendPublishQueued(ctx, bot, localizer, chatID, position)
+}
+
+// bestOfCaption builds the album caption in the channel's language, e.g. "🏆 Best of the week".
+func bestOfCaption(period string, from, to time.Time, count int) string {
+	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+	msgID := "MsgBestOfWeekCaption"
+	if period == "month" {
+		msgID = "MsgBestOfMonthCaption"
+	}
+	return locales.GetPluralMessage(localizer, msgID, count, map[string]interface{}{
+		"From": from.Format("02.01"),
+		"To":   to.Format("02.01"),
+	})
+}
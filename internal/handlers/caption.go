@@ -167,6 +167,7 @@ func (h *MessageHandler) HandleClearCaption(ctx context.Context, bot telegoapi.B
 	// --- End Admin Check ---
 
 	_, exists := h.activeCaptions.LoadAndDelete(chatID)
+	h.activeCaptionEmoji.Delete(chatID)
 	h.waitingForCaption.Delete(chatID) // Also ensure waiting state is cleared
 
 	var responseMsg string
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"vrcmemes-bot/internal/cmdargs"
 	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/suggestions"
 	telegoapi "vrcmemes-bot/pkg/telegoapi" // Import for BotAPI
 	"vrcmemes-bot/pkg/utils"               // Import utils package
 
@@ -15,47 +17,65 @@ import (
 
 	"github.com/mymmrac/telego"
 	"github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
 )
 
 // HandleStart handles the /start command.
-// It sets up the bot commands, updates user info, logs the action, and sends a welcome message.
+// It updates user info, logs the action, and sends a welcome message (the greeting set with
+// /setgreeting, or the localized default), followed by the
+// privacy notice if the user hasn't agreed to it yet.
+// Deep links skip the greeting and take the user straight where the link points, see
+// parseStartPayload; the payload is logged with the action.
+// The command menus are registered at startup by SyncCommandMenus.
 func (h *MessageHandler) HandleStart(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
-	if err := h.setupCommands(ctx, bot); err != nil {
-		// Consider adding more context to the error before sending
-		// Maybe use locales for this error message too?
-		return h.sendError(ctx, bot, message.Chat.ID, fmt.Errorf("failed to set up commands: %w", err))
-	}
-
 	localizer := h.getLocalizer(message.From) // Use helper
 
 	// Placeholder: Determine if the user is an admin (requires implementation)
 	// Let's assume checkAdmin is the way to go, even if it's false for start now.
 	isAdmin, _ := h.adminChecker.IsAdmin(ctx, message.From.ID) // Use checker method
 
+	payload := parseStartPayload(message.Text)
+	details := payload.details()
+	details["chat_id"] = message.Chat.ID
 	// Record activity (UpdateUser + LogUserAction combined)
-	h.RecordUserActivity(ctx, message.From, ActionCommandStart, isAdmin, map[string]interface{}{
-		"chat_id": message.Chat.ID,
-	})
-
-	// Get the localized start message
-	startMsg := locales.GetMessage(localizer, "MsgStart", nil, nil)
+	h.RecordUserActivity(ctx, message.From, ActionCommandStart, isAdmin, details)
 
-	// Escape text for MarkdownV2 and send welcome message
-	params := &telego.SendMessageParams{
-		ChatID:    telegoutil.ID(message.Chat.ID),
-		Text:      utils.EscapeMarkdownV2(startMsg),
-		ParseMode: telego.ModeMarkdownV2,
+	if handled, err := h.followStartPayload(ctx, bot, message, localizer, payload); handled {
+		return err
 	}
-	_, err := bot.SendMessage(ctx, params)
-	if err != nil {
+
+	// Send the custom greeting set with /setgreeting, or the localized default
+	if err := h.sendStartGreeting(ctx, bot, localizer, message.Chat.ID, message.From); err != nil {
 		log.Printf("Error sending start message to chat %d: %v", message.Chat.ID, err)
 		return nil // Logged error, follow sendSuccess pattern
 	}
+
+	// On the first interaction, explain what is stored and ask for consent
+	if h.suggestionManager != nil {
+		if err := h.suggestionManager.ShowPrivacyNotice(ctx, message.From, message.Chat.ID); err != nil {
+			log.Printf("[Cmd:start User:%d] Error showing privacy notice: %v", message.From.ID, err)
+		}
+	}
 	return nil
 }
 
-// HandleHelp handles the /help command.
-// It generates a help message listing available commands, updates user info, logs the action, and sends the help text.
+// helpArgs declares the arguments of /help.
+var helpArgs = cmdargs.Spec{
+	Command: "help",
+	Args:    []cmdargs.Arg{{Name: "command"}},
+}
+
+// roleKeys maps command roles to the message IDs naming who may use them.
+var roleKeys = map[CommandRole]string{
+	RoleEveryone:   "MsgHelpRoleEveryone",
+	RoleUser:       "MsgHelpRoleUser",
+	RoleAdmin:      "MsgHelpRoleAdmin",
+	RoleSuperAdmin: "MsgHelpRoleSuperAdmin",
+}
+
+// HandleHelp handles the /help [command] command.
+// Without an argument it lists the commands available to the user; with a command name
+// it shows that command's details (see sendCommandHelp). Both update user info and log the action.
 func (h *MessageHandler) HandleHelp(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
 	userID := message.From.ID
 	localizer := h.getLocalizer(message.From) // Use helper
@@ -65,25 +85,20 @@ func (h *MessageHandler) HandleHelp(ctx context.Context, bot telegoapi.BotAPI, m
 	// Log admin status check result for debugging /help specifically
 	log.Printf("[Cmd:help User:%d] Admin status check result: %t", userID, isAdmin)
 
+	args, err := helpArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, message.Chat.ID, localizer, err)
+	}
+	if name := args.Arg("command"); name != "" {
+		return h.sendCommandHelp(ctx, bot, message, localizer, isAdmin, name)
+	}
+
 	var helpText strings.Builder
 	helpText.WriteString(locales.GetMessage(localizer, "MsgHelpHeader", nil, nil) + "\n") // Add a header key
 
 	// Filter commands based on admin status
 	for _, cmd := range h.commands {
-		showCommand := false
-		if isAdmin {
-			// Admins see all commands except /suggest and /feedback
-			if cmd.Command != "suggest" && cmd.Command != "feedback" {
-				showCommand = true
-			}
-		} else {
-			// Non-admins see only /start, /suggest, and /feedback
-			if cmd.Command == "start" || cmd.Command == "suggest" || cmd.Command == "feedback" {
-				showCommand = true
-			}
-		}
-
-		if showCommand {
+		if cmd.Role.availableTo(isAdmin) {
 			// Localize command description
 			// Use the Description field directly as it now holds the key
 			localizedDesc := locales.GetMessage(localizer, cmd.Description, nil, nil)
@@ -112,7 +127,7 @@ func (h *MessageHandler) HandleHelp(ctx context.Context, bot telegoapi.BotAPI, m
 		Text:      escapedHelpText,
 		ParseMode: telego.ModeMarkdownV2,
 	}
-	_, err := bot.SendMessage(ctx, params)
+	_, err = bot.SendMessage(ctx, params)
 	if err != nil {
 		log.Printf("Error sending help message to chat %d: %v", message.Chat.ID, err)
 		// Return nil to follow sendError/sendSuccess pattern (error is logged)
@@ -122,11 +137,85 @@ func (h *MessageHandler) HandleHelp(ctx context.Context, bot telegoapi.BotAPI, m
 	return nil
 }
 
-// HandleStatus handles the /status command.
-// It retrieves the current active caption, formats a status message, updates user info, logs the action, and sends the status.
+// sendCommandHelp sends the detailed help of one command: its description, usage line,
+// examples and who may use it, all taken from the command registry.
+func (h *MessageHandler) sendCommandHelp(ctx context.Context, bot telegoapi.BotAPI, message telego.Message, localizer *i18n.Localizer, isAdmin bool, name string) error {
+	chatID := message.Chat.ID
+	name = strings.ToLower(strings.TrimPrefix(name, "/"))
+	name, _, _ = strings.Cut(name, "@") // Allow "/help /posturl@MemesBot"
+
+	var cmd *Command
+	for i := range h.commands {
+		if h.commands[i].Command == name {
+			cmd = &h.commands[i]
+			break
+		}
+	}
+	if cmd == nil {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgHelpUnknownCommand", map[string]interface{}{
+			"Command": name,
+		}, nil))
+	}
+
+	usage := "/" + cmd.Command
+	if cmd.Args != nil {
+		usage = cmd.Args.Usage()
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("/%s - %s", cmd.Command, locales.GetMessage(localizer, cmd.Description, nil, nil)))
+	if cmd.Help != "" {
+		text.WriteString("\n\n" + locales.GetMessage(localizer, cmd.Help, nil, nil))
+	}
+	text.WriteString("\n\n" + locales.GetMessage(localizer, "MsgHelpUsage", map[string]interface{}{"Usage": usage}, nil))
+	if len(cmd.Examples) > 0 {
+		text.WriteString("\n" + locales.GetMessage(localizer, "MsgHelpExamples", nil, nil))
+		for _, example := range cmd.Examples {
+			text.WriteString("\n" + example)
+		}
+	}
+	text.WriteString("\n" + locales.GetMessage(localizer, "MsgHelpRole", map[string]interface{}{
+		"Role": locales.GetMessage(localizer, roleKeys[cmd.Role], nil, nil),
+	}, nil))
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandHelp, isAdmin, map[string]interface{}{
+		"chat_id": chatID,
+		"command": cmd.Command,
+	})
+	return h.sendSuccess(ctx, bot, chatID, text.String())
+}
+
+// statusArgs declares the arguments of /status.
+var statusArgs = cmdargs.Spec{
+	Command: "status",
+	Args: []cmdargs.Arg{
+		{Name: "code"},
+	},
+}
+
+// HandleStatus handles the /status [code] command.
+// With a reference code, it delegates to the suggestion manager, which tells the suggester the status of that suggestion.
+// Without one, it retrieves the current active caption, formats a status message, updates user info, logs the action,
+// and sends the status to admins; other users are told how to check their suggestions.
 func (h *MessageHandler) HandleStatus(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
-	caption, _ := h.GetActiveCaption(message.Chat.ID)
 	localizer := h.getLocalizer(message.From) // Use helper
+	args, err := statusArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, message.Chat.ID, localizer, err)
+	}
+	if code := args.Arg("code"); code != "" {
+		update := telego.Update{Message: &message}
+		if err := h.suggestionManager.HandleSuggestionStatusCommand(ctx, update, code); err != nil {
+			// The manager sends user-facing errors itself
+			log.Printf("[Cmd:status User:%d] Error from suggestionManager.HandleSuggestionStatusCommand: %v", message.From.ID, err)
+		}
+		h.RecordUserActivity(ctx, message.From, ActionCommandStatus, false, map[string]interface{}{
+			"chat_id": message.Chat.ID,
+			"code":    code,
+		})
+		return nil
+	}
+	caption, _ := h.GetActiveCaption(message.Chat.ID)
 
 	// Get localized status message
 	statusText := locales.GetMessage(localizer, "MsgStatus", map[string]interface{}{
@@ -134,8 +223,10 @@ func (h *MessageHandler) HandleStatus(ctx context.Context, bot telegoapi.BotAPI,
 		"Caption":   caption,
 	}, nil)
 
-	// Check admin status (even if not used by logic, good to record)
 	isAdmin, _ := h.adminChecker.IsAdmin(ctx, message.From.ID) // Use checker method
+	if !isAdmin {
+		return h.sendSuccess(ctx, bot, message.Chat.ID, locales.GetMessage(localizer, "MsgSuggestionStatusUsage", nil, nil))
+	}
 
 	// Record activity
 	h.RecordUserActivity(ctx, message.From, ActionCommandStatus, isAdmin, map[string]interface{}{
@@ -149,7 +240,7 @@ func (h *MessageHandler) HandleStatus(ctx context.Context, bot telegoapi.BotAPI,
 		Text:      utils.EscapeMarkdownV2(statusText), // Escape the status text
 		ParseMode: telego.ModeMarkdownV2,
 	}
-	_, err := bot.SendMessage(ctx, params)
+	_, err = bot.SendMessage(ctx, params)
 	if err != nil {
 		log.Printf("Error sending status message to chat %d: %v", message.Chat.ID, err)
 		// Return nil to follow sendError/sendSuccess pattern (error is logged)
@@ -160,20 +251,17 @@ func (h *MessageHandler) HandleStatus(ctx context.Context, bot telegoapi.BotAPI,
 }
 
 // HandleVersion handles the /version command.
-// It retrieves the application version, formats a version message, updates user info, logs the action, and sends the version.
+// It formats the embedded build info (version, commit and build time), updates user info, logs the action, and sends it.
 func (h *MessageHandler) HandleVersion(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
-	// Use the stored version
-	version := h.version
-	// Redundant check, but keeps original logic if h.version could be empty
-	if version == "" {
-		version = "dev"
-	}
+	version := h.build.Version
 
 	localizer := h.getLocalizer(message.From) // Use helper
 
 	// Get localized version message
 	versionText := locales.GetMessage(localizer, "MsgVersion", map[string]interface{}{
 		"Version": version,
+		"Commit":  h.build.ShortCommit(),
+		"Date":    h.build.DateString(),
 	}, nil)
 
 	// Check admin status (even if not used by logic, good to record)
@@ -183,6 +271,7 @@ func (h *MessageHandler) HandleVersion(ctx context.Context, bot telegoapi.BotAPI
 	h.RecordUserActivity(ctx, message.From, ActionCommandVersion, isAdmin, map[string]interface{}{
 		"chat_id": message.Chat.ID,
 		"version": version,
+		"commit":  h.build.Commit,
 	})
 
 	// Escape text for MarkdownV2 and send version message
@@ -223,6 +312,23 @@ func (h *MessageHandler) HandleSuggest(ctx context.Context, bot telegoapi.BotAPI
 	}
 }
 
+// reviewArgs declares the arguments of /review.
+var reviewArgs = cmdargs.Spec{
+	Command: "review",
+	Args: []cmdargs.Arg{
+		{Name: "order", Choices: reviewOrderChoices()},
+	},
+}
+
+// reviewOrderChoices returns the names of the review orders /review accepts.
+func reviewOrderChoices() []string {
+	choices := make([]string, len(suggestions.ReviewOrders))
+	for i, order := range suggestions.ReviewOrders {
+		choices[i] = string(order)
+	}
+	return choices
+}
+
 // HandleReview handles the /review command by delegating to the suggestion manager.
 func (h *MessageHandler) HandleReview(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
 	userID := message.From.ID
@@ -254,10 +360,15 @@ func (h *MessageHandler) HandleReview(ctx context.Context, bot telegoapi.BotAPI,
 	// User is admin, record activity (optional for review start?)
 	// h.recordUserActivity(ctx, message.From, ActionCommandReview, isAdmin, map[string]interface{}{"chat_id": message.Chat.ID})
 
+	args, err := reviewArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, message.Chat.ID, h.getLocalizer(message.From), err)
+	}
+
 	// Construct update for manager and delegate
 	update := telego.Update{Message: &message}
 	if h.suggestionManager != nil {
-		err = h.suggestionManager.HandleReviewCommand(ctx, update)
+		err = h.suggestionManager.HandleReviewCommand(ctx, update, suggestions.ReviewOrder(args.Arg("order")))
 		if err != nil {
 			// Log error from HandleReviewCommand. It should handle user feedback itself.
 			log.Printf("[Cmd:review User:%d] Error from suggestionManager.HandleReviewCommand: %v", userID, err)
@@ -295,38 +406,17 @@ func (h *MessageHandler) HandleFeedback(ctx context.Context, bot telegoapi.BotAP
 	}
 }
 
-// --- Helper Functions ---
-
-// setupCommands registers the bot's commands with Telegram.
-// It builds the list of commands from the handler's configuration, localizes their descriptions,
-// and uses the bot instance to set them.
-func (h *MessageHandler) setupCommands(ctx context.Context, bot telegoapi.BotAPI) error {
-	if len(h.commands) == 0 {
-		log.Println("No commands defined in handler, skipping SetMyCommands.")
-		return nil // No commands to set is not an error
-	}
-
-	// Create a localizer for the default language to translate descriptions
-	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
-
-	commands := make([]telego.BotCommand, 0, len(h.commands)) // Initialize with capacity
-	for _, cmd := range h.commands {
-		// Get the localized description using the key stored in cmd.Description
-		localizedDesc := locales.GetMessage(localizer, cmd.Description, nil, nil)
-		commands = append(commands, telego.BotCommand{
-			Command:     cmd.Command,
-			Description: localizedDesc, // Use the translated description
-		})
+// HandleMySuggestions delegates to the suggestion manager's HandleMySuggestionsCommand.
+func (h *MessageHandler) HandleMySuggestions(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	update := telego.Update{Message: &message}
+	if err := h.suggestionManager.HandleMySuggestionsCommand(ctx, update); err != nil {
+		// The manager sends user-facing errors itself
+		log.Printf("[Cmd:mysuggestions User:%d] Error from suggestionManager.HandleMySuggestionsCommand: %v", userID, err)
+		return nil
 	}
-
-	// Use the passed bot instance to set the commands
-	err := bot.SetMyCommands(ctx, &telego.SetMyCommandsParams{
-		Commands: commands,
+	h.RecordUserActivity(ctx, message.From, ActionCommandMySuggestions, false, map[string]interface{}{
+		"chat_id": message.Chat.ID,
 	})
-	if err != nil {
-		// Wrap the error with context before returning
-		return fmt.Errorf("failed to set bot commands: %w", err)
-	}
-	log.Printf("Successfully set %d bot commands.", len(commands))
 	return nil
 }
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"vrcmemes-bot/internal/auth"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// SyncCommandMenus registers the bot's command menus with Telegram using command scopes.
+// The default menu, shown in groups, lists the commands meant for everyone; private chats
+// also get the regular user commands; and the private chat of each admin and super admin gets
+// the admin commands. The admin list is reloaded to find the admins; if that fails, it is logged
+// and only the super admins get the admin menu, until the next /refreshadmins.
+func (h *MessageHandler) SyncCommandMenus(ctx context.Context, bot telegoapi.BotAPI) error {
+	if len(h.commands) == 0 {
+		log.Println("No commands defined in handler, skipping SetMyCommands.")
+		return nil // No commands to set is not an error
+	}
+
+	// Menus are shown in the default language
+	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+
+	// The user menus don't depend on each other or on the admin list, so a failure doesn't stop the others
+	var errs []error
+	err := bot.SetMyCommands(ctx, &telego.SetMyCommandsParams{
+		Commands: h.commandMenu(localizer, func(role CommandRole) bool { return role == RoleEveryone }),
+		Scope:    tu.ScopeDefault(),
+	})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to set default bot commands: %w", err))
+	}
+	err = bot.SetMyCommands(ctx, &telego.SetMyCommandsParams{
+		Commands: h.commandMenu(localizer, func(role CommandRole) bool { return role.availableTo(false) }),
+		Scope:    tu.ScopeAllPrivateChats(),
+	})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to set private chat bot commands: %w", err))
+	}
+
+	superAdminIDs := h.adminChecker.SuperAdminIDs()
+	adminIDs, err := h.adminChecker.Refresh(ctx)
+	if err != nil {
+		log.Printf("[CommandMenu] Error loading admins, only super admins get the admin command menu: %v", err)
+		h.muAdminMenus.Lock()
+		for _, id := range superAdminIDs {
+			if _, ok := h.adminMenus[id]; !ok {
+				h.setAdminMenu(ctx, bot, id)
+			}
+		}
+		h.muAdminMenus.Unlock()
+		return errors.Join(errs...)
+	}
+	for _, id := range superAdminIDs {
+		if !slices.Contains(adminIDs, id) {
+			adminIDs = append(adminIDs, id)
+		}
+	}
+	h.updateAdminMenus(ctx, bot, adminIDs)
+	log.Printf("Set bot command menus (%d admins).", len(adminIDs))
+	return errors.Join(errs...)
+}
+
+// UpdateAdminMenu adds or removes the admin command menu of a target channel member
+// who was promoted or demoted. Other chat member updates are ignored.
+func (h *MessageHandler) UpdateAdminMenu(ctx context.Context, bot telegoapi.BotAPI, update telego.ChatMemberUpdated) {
+	if update.Chat.ID != h.channelID || update.OldChatMember == nil || update.NewChatMember == nil {
+		return
+	}
+	if auth.IsAdminStatus(update.OldChatMember.MemberStatus()) == auth.IsAdminStatus(update.NewChatMember.MemberStatus()) {
+		return
+	}
+	user := update.NewChatMember.MemberUser()
+	if user.IsBot {
+		return
+	}
+
+	// Super admins keep their menu when demoted in the channel
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, user.ID)
+	if err != nil {
+		log.Printf("[CommandMenu User:%d] Error checking admin status: %v", user.ID, err)
+		return
+	}
+
+	h.muAdminMenus.Lock()
+	defer h.muAdminMenus.Unlock()
+	if isAdmin {
+		h.setAdminMenu(ctx, bot, user.ID)
+	} else {
+		h.deleteAdminMenu(ctx, bot, user.ID)
+	}
+}
+
+// updateAdminMenus gives the admin command menu to the private chats of the given admins
+// and removes it from users who are no longer admins. Admins who already have it are skipped.
+// Menus set before a restart are not tracked, so they are only removed by the next demotion update.
+func (h *MessageHandler) updateAdminMenus(ctx context.Context, bot telegoapi.BotAPI, adminIDs []int64) {
+	isAdmin := make(map[int64]struct{}, len(adminIDs))
+	for _, id := range adminIDs {
+		isAdmin[id] = struct{}{}
+	}
+
+	h.muAdminMenus.Lock()
+	defer h.muAdminMenus.Unlock()
+	for _, id := range adminIDs {
+		if _, ok := h.adminMenus[id]; !ok {
+			h.setAdminMenu(ctx, bot, id)
+		}
+	}
+	for id := range h.adminMenus {
+		if _, ok := isAdmin[id]; !ok {
+			h.deleteAdminMenu(ctx, bot, id)
+		}
+	}
+}
+
+// setAdminMenu sets the admin command menu for the private chat of an admin.
+// Telegram refuses it for admins who never started the bot; such errors are logged
+// and the menu is set again on the next refresh. The caller must hold muAdminMenus.
+func (h *MessageHandler) setAdminMenu(ctx context.Context, bot telegoapi.BotAPI, userID int64) {
+	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+	err := bot.SetMyCommands(ctx, &telego.SetMyCommandsParams{
+		Commands: h.commandMenu(localizer, func(role CommandRole) bool { return role.availableTo(true) }),
+		Scope:    tu.ScopeChat(tu.ID(userID)),
+	})
+	if err != nil {
+		log.Printf("[CommandMenu User:%d] Error setting admin command menu: %v", userID, err)
+		return
+	}
+	h.adminMenus[userID] = struct{}{}
+}
+
+// deleteAdminMenu removes the admin command menu from the private chat of a former admin,
+// who then sees the private chat menu again. The caller must hold muAdminMenus.
+func (h *MessageHandler) deleteAdminMenu(ctx context.Context, bot telegoapi.BotAPI, userID int64) {
+	err := bot.DeleteMyCommands(ctx, &telego.DeleteMyCommandsParams{
+		Scope: tu.ScopeChat(tu.ID(userID)),
+	})
+	if err != nil {
+		log.Printf("[CommandMenu User:%d] Error removing admin command menu: %v", userID, err)
+		return
+	}
+	delete(h.adminMenus, userID)
+}
+
+// commandMenu returns the localized menu entries of the commands whose role is included.
+func (h *MessageHandler) commandMenu(localizer *i18n.Localizer, include func(CommandRole) bool) []telego.BotCommand {
+	commands := make([]telego.BotCommand, 0, len(h.commands))
+	for _, cmd := range h.commands {
+		if include(cmd.Role) {
+			commands = append(commands, telego.BotCommand{
+				Command:     cmd.Command,
+				Description: locales.GetMessage(localizer, cmd.Description, nil, nil),
+			})
+		}
+	}
+	return commands
+}
@@ -2,14 +2,19 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
-	"sync"
 	"testing"
 	"time"
+	"vrcmemes-bot/internal/buildinfo"
 	"vrcmemes-bot/internal/database/models" // Add import for models
 	"vrcmemes-bot/internal/locales"         // Add mediagroups import
+	"vrcmemes-bot/internal/publisher"
 	"vrcmemes-bot/internal/suggestions"
+	"vrcmemes-bot/pkg/telegoapi"
 	"vrcmemes-bot/pkg/utils" // Import utils for escaping
 
 	// Import for BotAPI
@@ -17,6 +22,7 @@ import (
 	"github.com/mymmrac/telego/telegoutil" // Import for telegoutil
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -55,11 +61,54 @@ func (m *MockBot) CopyMessage(ctx context.Context, params *telego.CopyMessagePar
 	return nil, args.Error(1)
 }
 
+func (m *MockBot) CopyMessages(ctx context.Context, params *telego.CopyMessagesParams) ([]telego.MessageID, error) {
+	args := m.Called(ctx, params)
+	ids, _ := args.Get(0).([]telego.MessageID)
+	return ids, args.Error(1)
+}
+
 func (m *MockBot) SetMyCommands(ctx context.Context, params *telego.SetMyCommandsParams) error {
 	args := m.Called(ctx, params)
 	return args.Error(0)
 }
 
+func (m *MockBot) DeleteMyCommands(ctx context.Context, params *telego.DeleteMyCommandsParams) error {
+	args := m.Called(ctx, params)
+	return args.Error(0)
+}
+
+func (m *MockBot) GetWebhookInfo(ctx context.Context) (*telego.WebhookInfo, error) {
+	args := m.Called(ctx)
+	info, _ := args.Get(0).(*telego.WebhookInfo)
+	return info, args.Error(1)
+}
+
+func (m *MockBot) GetChat(ctx context.Context, params *telego.GetChatParams) (*telego.ChatFullInfo, error) {
+	args := m.Called(ctx, params)
+	chat, _ := args.Get(0).(*telego.ChatFullInfo)
+	return chat, args.Error(1)
+}
+
+func (m *MockBot) PinChatMessage(ctx context.Context, params *telego.PinChatMessageParams) error {
+	args := m.Called(ctx, params)
+	return args.Error(0)
+}
+
+func (m *MockBot) UnpinChatMessage(ctx context.Context, params *telego.UnpinChatMessageParams) error {
+	args := m.Called(ctx, params)
+	return args.Error(0)
+}
+
+func (m *MockBot) LeaveChat(ctx context.Context, params *telego.LeaveChatParams) error {
+	args := m.Called(ctx, params)
+	return args.Error(0)
+}
+
+func (m *MockBot) SetMessageReaction(ctx context.Context, params *telego.SetMessageReactionParams) error {
+	args := m.Called(ctx, params)
+	return args.Error(0)
+}
+
 func (m *MockBot) AnswerCallbackQuery(ctx context.Context, params *telego.AnswerCallbackQueryParams) error {
 	args := m.Called(ctx, params)
 	return args.Error(0)
@@ -98,6 +147,48 @@ func (m *MockBot) DeleteMessage(ctx context.Context, params *telego.DeleteMessag
 	return args.Error(0)
 }
 
+// Add SendVideo to satisfy telegoapi.BotAPI
+func (m *MockBot) SendVideo(ctx context.Context, params *telego.SendVideoParams) (*telego.Message, error) {
+	args := m.Called(ctx, params)
+	if msg, ok := args.Get(0).(*telego.Message); ok {
+		return msg, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBot) SendAnimation(ctx context.Context, params *telego.SendAnimationParams) (*telego.Message, error) {
+	args := m.Called(ctx, params)
+	if msg, ok := args.Get(0).(*telego.Message); ok {
+		return msg, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBot) SendDocument(ctx context.Context, params *telego.SendDocumentParams) (*telego.Message, error) {
+	args := m.Called(ctx, params)
+	if msg, ok := args.Get(0).(*telego.Message); ok {
+		return msg, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBot) EditMessageReplyMarkup(ctx context.Context, params *telego.EditMessageReplyMarkupParams) (*telego.Message, error) {
+	args := m.Called(ctx, params)
+	if msg, ok := args.Get(0).(*telego.Message); ok {
+		return msg, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// Add EditMessageText to satisfy telegoapi.BotAPI
+func (m *MockBot) EditMessageText(ctx context.Context, params *telego.EditMessageTextParams) (*telego.Message, error) {
+	args := m.Called(ctx, params)
+	if msg, ok := args.Get(0).(*telego.Message); ok {
+		return msg, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 // MockUserActionLogger is a mock for UserActionLogger
 type MockUserActionLogger struct {
 	mock.Mock
@@ -113,8 +204,55 @@ type MockUserRepository struct {
 	mock.Mock
 }
 
-func (m *MockUserRepository) UpdateUser(ctx context.Context, userID int64, username, firstName, lastName string, isAdmin bool, action string) error {
-	args := m.Called(ctx, userID, username, firstName, lastName, isAdmin, action)
+func (m *MockUserRepository) UpdateUser(ctx context.Context, user telego.User, isAdmin bool, action string) error {
+	args := m.Called(ctx, user, isAdmin, action)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUser(ctx context.Context, userID int64) (*models.User, error) {
+	args := m.Called(ctx, userID)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	args := m.Called(ctx, username)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserRepository) IncrementUserCounter(ctx context.Context, userID int64, counter string) error {
+	args := m.Called(ctx, userID, counter)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetUserConsent(ctx context.Context, userID int64, at time.Time) error {
+	args := m.Called(ctx, userID, at)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUserLanguage(ctx context.Context, userID int64) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUserRepository) SetUserLanguage(ctx context.Context, userID int64, lang string) error {
+	args := m.Called(ctx, userID, lang)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUserFirstSeen(ctx context.Context, userID int64) (time.Time, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockUserRepository) SetNotificationMuted(ctx context.Context, userID int64, category string, muted bool) error {
+	args := m.Called(ctx, userID, category, muted)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) DeleteUser(ctx context.Context, userID int64) error {
+	args := m.Called(ctx, userID)
 	return args.Error(0)
 }
 
@@ -128,6 +266,27 @@ func (m *MockAdminChecker) IsAdmin(ctx context.Context, userID int64) (bool, err
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockAdminChecker) Refresh(ctx context.Context) ([]int64, error) {
+	args := m.Called(ctx)
+	adminIDs, _ := args.Get(0).([]int64)
+	return adminIDs, args.Error(1)
+}
+
+func (m *MockAdminChecker) Invalidate(userID int64) {
+	m.Called(userID)
+}
+
+func (m *MockAdminChecker) IsSuperAdmin(userID int64) bool {
+	args := m.Called(userID)
+	return args.Bool(0)
+}
+
+func (m *MockAdminChecker) SuperAdminIDs() []int64 {
+	args := m.Called()
+	ids, _ := args.Get(0).([]int64)
+	return ids
+}
+
 // MockSuggestionRepository
 type MockSuggestionRepository struct {
 	mock.Mock
@@ -170,6 +329,53 @@ func (m *MockFeedbackRepository) AddFeedback(ctx context.Context, feedback *mode
 	return args.Error(0)
 }
 
+func (m *MockFeedbackRepository) GetFeedbackByID(ctx context.Context, id primitive.ObjectID) (*models.Feedback, error) {
+	args := m.Called(ctx, id)
+	if feedback, ok := args.Get(0).(*models.Feedback); ok {
+		return feedback, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockFeedbackRepository) SetFeedbackStatus(ctx context.Context, id primitive.ObjectID, status string, adminID int64) (bool, error) {
+	args := m.Called(ctx, id, status, adminID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFeedbackRepository) SetFeedbackContactOptIn(ctx context.Context, id primitive.ObjectID, userID int64, optIn bool) error {
+	args := m.Called(ctx, id, userID, optIn)
+	return args.Error(0)
+}
+
+func (m *MockFeedbackRepository) ListFeedback(ctx context.Context, statuses []string, limit int) ([]models.Feedback, error) {
+	args := m.Called(ctx, statuses, limit)
+	if feedback, ok := args.Get(0).([]models.Feedback); ok {
+		return feedback, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// memorySettingsRepository is an in-memory SettingsRepository.
+type memorySettingsRepository map[string]string
+
+func (r memorySettingsRepository) GetSetting(ctx context.Context, key string) (*models.Setting, error) {
+	value, ok := r[key]
+	if !ok {
+		return nil, nil
+	}
+	return &models.Setting{Key: key, Value: value}, nil
+}
+
+func (r memorySettingsRepository) SetSetting(ctx context.Context, key, value string, updatedBy int64) error {
+	r[key] = value
+	return nil
+}
+
+func (r memorySettingsRepository) DeleteSetting(ctx context.Context, key string) error {
+	delete(r, key)
+	return nil
+}
+
 // MockSuggestionManager is a mock implementing SuggestionManagerInterface
 type MockSuggestionManager struct {
 	mock.Mock
@@ -193,8 +399,8 @@ func (m *MockSuggestionManager) HandleSuggestCommand(ctx context.Context, update
 }
 
 // Add HandleReviewCommand to satisfy interface
-func (m *MockSuggestionManager) HandleReviewCommand(ctx context.Context, update telego.Update) error {
-	args := m.Called(ctx, update)
+func (m *MockSuggestionManager) HandleReviewCommand(ctx context.Context, update telego.Update, order suggestions.ReviewOrder) error {
+	args := m.Called(ctx, update, order)
 	return args.Error(0)
 }
 
@@ -220,11 +426,135 @@ func (m *MockSuggestionManager) HandleCombinedMediaGroup(ctx context.Context, gr
 	return args.Error(0)
 }
 
+// HandleMySuggestionsCommand mocks the method
+func (m *MockSuggestionManager) HandleMySuggestionsCommand(ctx context.Context, update telego.Update) error {
+	args := m.Called(ctx, update)
+	return args.Error(0)
+}
+
+// HandleSuggestionStatusCommand mocks the method
+func (m *MockSuggestionManager) HandleSuggestionStatusCommand(ctx context.Context, update telego.Update, code string) error {
+	args := m.Called(ctx, update, code)
+	return args.Error(0)
+}
+
+// HandleFindCommand mocks the method
+func (m *MockSuggestionManager) HandleFindCommand(ctx context.Context, update telego.Update, code string) error {
+	args := m.Called(ctx, update, code)
+	return args.Error(0)
+}
+
+// HandleReopenCommand mocks the method
+func (m *MockSuggestionManager) HandleReopenCommand(ctx context.Context, update telego.Update, code string) error {
+	args := m.Called(ctx, update, code)
+	return args.Error(0)
+}
+
+// HandleQueueExportCommand mocks the method
+func (m *MockSuggestionManager) HandleQueueExportCommand(ctx context.Context, update telego.Update) error {
+	args := m.Called(ctx, update)
+	return args.Error(0)
+}
+
+// HandleApplyCommand mocks the method
+func (m *MockSuggestionManager) HandleApplyCommand(ctx context.Context, update telego.Update, decisions string) error {
+	args := m.Called(ctx, update, decisions)
+	return args.Error(0)
+}
+
+// SetCreditSuggesters mocks the method
+func (m *MockSuggestionManager) SetCreditSuggesters(enabled bool) {
+	m.Called(enabled)
+}
+
+// SetCreditForwardSource mocks the method
+func (m *MockSuggestionManager) SetCreditForwardSource(enabled bool) {
+	m.Called(enabled)
+}
+
+func (m *MockSuggestionManager) SetProtectContent(enabled bool) {
+	m.Called(enabled)
+}
+
+func (m *MockSuggestionManager) SetContentPolicy(policy suggestions.ContentPolicy) {
+	m.Called(policy)
+}
+
+func (m *MockSuggestionManager) ContentPolicy() suggestions.ContentPolicy {
+	args := m.Called()
+	if policy, ok := args.Get(0).(suggestions.ContentPolicy); ok {
+		return policy
+	}
+	return nil
+}
+
+func (m *MockSuggestionManager) SetAutoRejectRules(rules suggestions.AutoRejectRules) {
+	m.Called(rules)
+}
+
+func (m *MockSuggestionManager) AutoRejectRules() suggestions.AutoRejectRules {
+	args := m.Called()
+	switch rules := args.Get(0).(type) {
+	case suggestions.AutoRejectRules:
+		return rules
+	case func() suggestions.AutoRejectRules: // Rules changed by SetAutoRejectRules during the test
+		return rules()
+	}
+	return suggestions.AutoRejectRules{}
+}
+
+func (m *MockSuggestionManager) RememberSuggestionSource(ctx context.Context, userID int64, source string) {
+	m.Called(ctx, userID, source)
+}
+
+// PurgeTestSuggestions mocks the method
+func (m *MockSuggestionManager) PurgeTestSuggestions(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// OfferDirectSuggestion mocks the method
+func (m *MockSuggestionManager) OfferDirectSuggestion(ctx context.Context, messages []telego.Message) (bool, error) {
+	args := m.Called(ctx, messages)
+	return args.Bool(0), args.Error(1)
+}
+
+// ShowPrivacyNotice mocks the method
+func (m *MockSuggestionManager) ShowPrivacyNotice(ctx context.Context, user *telego.User, chatID int64) error {
+	args := m.Called(ctx, user, chatID)
+	return args.Error(0)
+}
+
+// GetQuietHours mocks the method
+func (m *MockSuggestionManager) GetQuietHours(ctx context.Context, adminID int64) (*suggestions.QuietHours, error) {
+	args := m.Called(ctx, adminID)
+	quiet, _ := args.Get(0).(*suggestions.QuietHours)
+	return quiet, args.Error(1)
+}
+
+// SetQuietHours mocks the method
+func (m *MockSuggestionManager) SetQuietHours(ctx context.Context, adminID int64, quiet *suggestions.QuietHours) error {
+	args := m.Called(ctx, adminID, quiet)
+	return args.Error(0)
+}
+
+func (m *MockSuggestionManager) IsIntakeChat(chatID int64) bool {
+	args := m.Called(chatID)
+	return args.Bool(0)
+}
+
+// HandleChatMemberUpdate mocks the method
+func (m *MockSuggestionManager) HandleChatMemberUpdate(ctx context.Context, update telego.ChatMemberUpdated) error {
+	args := m.Called(ctx, update)
+	return args.Error(0)
+}
+
 // --- Test Suite Setup ---
 
 const (
 	testChannelID = int64(12345)
 	testVersion   = "v1.2.3-test"
+	testCommit    = "0123456789abcdef0123456789abcdef01234567"
 )
 
 type testHandlerSuite struct {
@@ -249,32 +579,32 @@ func setupTestHandlerSuite(t *testing.T) *testHandlerSuite {
 	mockSuggestionManager := new(MockSuggestionManager)
 	mockFeedbackRepo := new(MockFeedbackRepository)
 
-	var activeCaptionsMap sync.Map
-
 	handler := &MessageHandler{
 		channelID:         testChannelID,
+		adminMenus:        make(map[int64]struct{}),
 		postLogger:        nil,
 		actionLogger:      mockActionLogger,
 		userRepo:          mockUserRepo,
 		suggestionManager: mockSuggestionManager,
 		adminChecker:      mockAdminChecker,
 		feedbackRepo:      mockFeedbackRepo,
-		version:           testVersion,
-		activeCaptions:    activeCaptionsMap,
+		build:             buildinfo.Info{Version: testVersion, Commit: testCommit},
 	}
 
 	// Initialize the commands slice using the local Command type and localization KEYS
 	handler.commands = []Command{ // Use the local Command type
-		{Command: "start", Description: "CmdStartDesc", Handler: nil},
-		{Command: "help", Description: "CmdHelpDesc", Handler: nil},
-		{Command: "status", Description: "CmdStatusDesc", Handler: nil},
-		{Command: "version", Description: "CmdVersionDesc", Handler: nil},
-		{Command: "caption", Description: "CmdCaptionDesc", Handler: nil},
-		{Command: "showcaption", Description: "CmdShowCaptionDesc", Handler: nil},
-		{Command: "clearcaption", Description: "CmdClearCaptionDesc", Handler: nil},
-		{Command: "suggest", Description: "CmdSuggestDesc", Handler: nil},
-		{Command: "review", Description: "CmdReviewDesc", Handler: nil},
-		{Command: "feedback", Description: "CmdFeedbackDesc", Handler: nil},
+		{Command: "start", Description: "CmdStartDesc", Handler: nil, Role: RoleEveryone},
+		{Command: "help", Description: "CmdHelpDesc", Handler: nil, Role: RoleEveryone},
+		{Command: "status", Description: "CmdStatusDesc", Handler: nil, Role: RoleAdmin},
+		{Command: "version", Description: "CmdVersionDesc", Handler: nil, Role: RoleAdmin},
+		{Command: "caption", Description: "CmdCaptionDesc", Handler: nil, Role: RoleAdmin},
+		{Command: "showcaption", Description: "CmdShowCaptionDesc", Handler: nil, Role: RoleAdmin},
+		{Command: "clearcaption", Description: "CmdClearCaptionDesc", Handler: nil, Role: RoleAdmin},
+		{Command: "suggest", Description: "CmdSuggestDesc", Handler: nil, Role: RoleUser},
+		{Command: "review", Description: "CmdReviewDesc", Handler: nil, Role: RoleAdmin},
+		{Command: "feedback", Description: "CmdFeedbackDesc", Handler: nil, Role: RoleUser},
+		{Command: "posturl", Description: "CmdPostURLDesc", Handler: nil, Role: RoleAdmin,
+			Args: &postURLArgs, Help: "CmdPostURLHelp", Examples: []string{"/posturl https://example.com/meme.jpg"}},
 	}
 
 	return &testHandlerSuite{
@@ -329,10 +659,7 @@ func TestHandleStart(t *testing.T) {
 
 			// Expect logging & user update with correct Action constant
 			s.mockActionLogger.On("LogUserAction", testUserID, ActionCommandStart, mock.Anything).Return(nil).Once()
-			s.mockUserRepo.On("UpdateUser", ctx, testUserID, testUsername, testFirstName, testLastName, false, ActionCommandStart).Return(nil).Once() // Use ActionCommandStart
-
-			// Expect SetMyCommands call (part of HandleStart's setupCommands)
-			s.mockBot.On("SetMyCommands", ctx, mock.AnythingOfType("*telego.SetMyCommandsParams")).Return(nil).Once()
+			s.mockUserRepo.On("UpdateUser", ctx, *testMessage.From, false, ActionCommandStart).Return(nil).Once() // Use ActionCommandStart
 
 			// Expect SendMessage call, capture the params
 			var capturedParams *telego.SendMessageParams
@@ -343,6 +670,7 @@ func TestHandleStart(t *testing.T) {
 					}
 				}).
 				Return(&telego.Message{}, nil).Once() // Expect one call
+			s.mockSuggestionManager.On("ShowPrivacyNotice", ctx, testMessage.From, testChatID).Return(nil).Once()
 
 			// Act
 			err := s.handler.HandleStart(ctx, s.mockBot, testMessage)
@@ -361,9 +689,70 @@ func TestHandleStart(t *testing.T) {
 				assert.Equal(t, expectedEscapedText, capturedParams.Text)
 			}
 		})
+
+		t.Run("SuggestLink", func(t *testing.T) {
+			linkMessage := testMessage
+			linkMessage.Text = "/start suggest_channel"
+			s.mockAdminChecker.On("IsAdmin", ctx, testUserID).Return(false, nil).Once()
+			s.mockActionLogger.On("LogUserAction", testUserID, ActionCommandStart, map[string]interface{}{
+				"chat_id": testChatID,
+				"payload": "suggest",
+				"source":  "channel",
+			}).Return(nil).Once()
+			s.mockUserRepo.On("UpdateUser", ctx, *testMessage.From, false, ActionCommandStart).Return(nil).Once()
+			s.mockSuggestionManager.On("RememberSuggestionSource", ctx, testUserID, "channel").Once()
+			s.mockSuggestionManager.On("HandleSuggestCommand", ctx, telego.Update{Message: &linkMessage}).Return(nil).Once()
+
+			err := s.handler.HandleStart(ctx, s.mockBot, linkMessage)
+
+			assert.NoError(t, err)
+			s.mockActionLogger.AssertExpectations(t)
+			s.mockSuggestionManager.AssertExpectations(t)
+		})
 	})
 }
 
+func TestParseStartPayload(t *testing.T) {
+	tests := []struct {
+		text string
+		want startPayload
+	}{
+		{"/start", startPayload{Kind: startPayloadNone}},
+		{"/start suggest_group", startPayload{Kind: startPayloadSuggest, Raw: "suggest_group", Source: "group"}},
+		{"/start feedback", startPayload{Kind: startPayloadFeedback, Raw: "feedback"}},
+		{"/start post_42", startPayload{Kind: startPayloadPost, Raw: "post_42", PostID: 42}},
+		{"/start post_0", startPayload{Kind: startPayloadInvalid, Raw: "post_0"}},
+		{"/start post_abc", startPayload{Kind: startPayloadInvalid, Raw: "post_abc"}},
+		{"/start hello!", startPayload{Kind: startPayloadInvalid, Raw: "hello!"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, parseStartPayload(tt.text), tt.text)
+	}
+	assert.Equal(t, map[string]interface{}{"payload": "post", "post_id": 42}, parseStartPayload("/start post_42").details())
+	assert.Empty(t, parseStartPayload("/start").details())
+}
+
+func TestParseSuggestStartPayload(t *testing.T) {
+	tests := []struct {
+		payload string
+		source  string
+		ok      bool
+	}{
+		{"suggest", "", true},
+		{"suggest_channel", "channel", true},
+		{"Suggest_Group", "group", true},
+		{"suggest_bad-source!", "", true},
+		{"suggestion", "", false},
+		{"", "", false},
+		{"post_12", "", false},
+	}
+	for _, tt := range tests {
+		source, ok := parseSuggestStartPayload(tt.payload)
+		assert.Equal(t, tt.source, source, tt.payload)
+		assert.Equal(t, tt.ok, ok, tt.payload)
+	}
+}
+
 func TestHandleHelp(t *testing.T) {
 	locales.Init("en")
 	s := setupTestHandlerSuite(t) // Setup the suite
@@ -408,7 +797,7 @@ func TestHandleHelp(t *testing.T) {
 
 			s.mockAdminChecker.On("IsAdmin", ctx, testUserID).Return(true, nil).Once()
 			s.mockActionLogger.On("LogUserAction", testUserID, ActionCommandHelp, mock.Anything).Return(nil).Once()
-			s.mockUserRepo.On("UpdateUser", ctx, testUserID, testUsername, testFirstName, testLastName, true, ActionCommandHelp).Return(nil).Once() // Use ActionCommandHelp
+			s.mockUserRepo.On("UpdateUser", ctx, *testMessage.From, true, ActionCommandHelp).Return(nil).Once() // Use ActionCommandHelp
 
 			// Expect SendMessage call, capture the params
 			var capturedParams *telego.SendMessageParams
@@ -444,7 +833,7 @@ func TestHandleHelp(t *testing.T) {
 			helpTextBuilder.WriteString(locales.GetMessage(localizer, "MsgHelpHeader", nil, nil) + "\n")
 			// Use handler's commands for dynamic list building
 			for _, cmd := range s.handler.commands {
-				if cmd.Command == "start" || cmd.Command == "suggest" || cmd.Command == "feedback" { // Regular user filter
+				if cmd.Command == "start" || cmd.Command == "help" || cmd.Command == "suggest" || cmd.Command == "feedback" { // Regular user filter
 					localizedDesc := locales.GetMessage(localizer, cmd.Description, nil, nil)
 					helpTextBuilder.WriteString(fmt.Sprintf("/%s - %s\n", cmd.Command, localizedDesc))
 				}
@@ -455,7 +844,7 @@ func TestHandleHelp(t *testing.T) {
 
 			s.mockAdminChecker.On("IsAdmin", ctx, testUserID).Return(false, nil).Once()
 			s.mockActionLogger.On("LogUserAction", testUserID, ActionCommandHelp, mock.Anything).Return(nil).Once()
-			s.mockUserRepo.On("UpdateUser", ctx, testUserID, testUsername, testFirstName, testLastName, false, ActionCommandHelp).Return(nil).Once() // Use ActionCommandHelp
+			s.mockUserRepo.On("UpdateUser", ctx, *testMessage.From, false, ActionCommandHelp).Return(nil).Once() // Use ActionCommandHelp
 
 			// Expect SendMessage call, capture the params
 			var capturedParams *telego.SendMessageParams
@@ -483,6 +872,60 @@ func TestHandleHelp(t *testing.T) {
 				assert.Equal(t, expectedEscapedText, capturedParams.Text)
 			}
 		})
+
+		t.Run("CommandDetails", func(t *testing.T) {
+			// Arrange
+			detailMessage := testMessage
+			detailMessage.Text = "/help /posturl"
+
+			s.mockAdminChecker.On("IsAdmin", ctx, testUserID).Return(false, nil).Once()
+			s.mockActionLogger.On("LogUserAction", testUserID, ActionCommandHelp, mock.Anything).Return(nil).Once()
+			s.mockUserRepo.On("UpdateUser", ctx, *testMessage.From, false, ActionCommandHelp).Return(nil).Once()
+
+			var capturedParams *telego.SendMessageParams
+			s.mockBot.On("SendMessage", ctx, mock.AnythingOfType("*telego.SendMessageParams")).
+				Run(func(args mock.Arguments) {
+					capturedParams, _ = args.Get(1).(*telego.SendMessageParams)
+				}).
+				Return(&telego.Message{}, nil).Once()
+
+			// Act
+			err := s.handler.HandleHelp(ctx, s.mockBot, detailMessage)
+
+			// Assert
+			assert.NoError(t, err)
+			s.mockBot.AssertExpectations(t)
+			if assert.NotNil(t, capturedParams) {
+				assert.Contains(t, capturedParams.Text, "/posturl [--silent] [--protect] <url> [caption...]")
+				assert.Contains(t, capturedParams.Text, "/posturl https://example.com/meme.jpg")
+				assert.Contains(t, capturedParams.Text, "admins only")
+			}
+		})
+
+		t.Run("UnknownCommand", func(t *testing.T) {
+			// Arrange
+			unknownMessage := testMessage
+			unknownMessage.Text = "/help nosuchcommand"
+
+			s.mockAdminChecker.On("IsAdmin", ctx, testUserID).Return(false, nil).Once()
+
+			var capturedParams *telego.SendMessageParams
+			s.mockBot.On("SendMessage", ctx, mock.AnythingOfType("*telego.SendMessageParams")).
+				Run(func(args mock.Arguments) {
+					capturedParams, _ = args.Get(1).(*telego.SendMessageParams)
+				}).
+				Return(&telego.Message{}, nil).Once()
+
+			// Act
+			err := s.handler.HandleHelp(ctx, s.mockBot, unknownMessage)
+
+			// Assert
+			assert.NoError(t, err)
+			s.mockBot.AssertExpectations(t)
+			if assert.NotNil(t, capturedParams) {
+				assert.Contains(t, capturedParams.Text, "/nosuchcommand")
+			}
+		})
 	})
 }
 
@@ -529,8 +972,8 @@ func TestHandleStatus(t *testing.T) {
 			expectedEscapedText := utils.EscapeMarkdownV2(statusText)
 
 			s.mockActionLogger.On("LogUserAction", testUserID, ActionCommandStatus, mock.Anything).Return(nil).Once()
-			s.mockUserRepo.On("UpdateUser", ctx, testUserID, testUsername, testFirstName, testLastName, false, ActionCommandStatus).Return(nil).Once() // Use ActionCommandStatus
-			s.mockAdminChecker.On("IsAdmin", ctx, testUserID).Return(false, nil).Maybe()
+			s.mockUserRepo.On("UpdateUser", ctx, *testMessage.From, true, ActionCommandStatus).Return(nil).Once() // Use ActionCommandStatus
+			s.mockAdminChecker.On("IsAdmin", ctx, testUserID).Return(true, nil).Maybe()
 			// No mock needed for GetActiveCaption as it's internal to MessageHandler
 
 			// Expect SendMessage call, capture the params
@@ -573,8 +1016,8 @@ func TestHandleStatus(t *testing.T) {
 			expectedEscapedText := utils.EscapeMarkdownV2(statusTextWithEmptyCaption)
 
 			s.mockActionLogger.On("LogUserAction", testUserID, ActionCommandStatus, mock.Anything).Return(nil).Once()
-			s.mockUserRepo.On("UpdateUser", ctx, testUserID, testUsername, testFirstName, testLastName, false, ActionCommandStatus).Return(nil).Once() // Use ActionCommandStatus
-			s.mockAdminChecker.On("IsAdmin", ctx, testUserID).Return(false, nil).Maybe()
+			s.mockUserRepo.On("UpdateUser", ctx, *testMessage.From, true, ActionCommandStatus).Return(nil).Once() // Use ActionCommandStatus
+			s.mockAdminChecker.On("IsAdmin", ctx, testUserID).Return(true, nil).Maybe()
 			// No mock needed for GetActiveCaption
 
 			// Expect SendMessage call, capture the params
@@ -603,6 +1046,46 @@ func TestHandleStatus(t *testing.T) {
 				assert.Equal(t, expectedEscapedText, capturedParams.Text)
 			}
 		})
+
+		t.Run("NonAdminWithoutCode", func(t *testing.T) {
+			// Arrange
+			userMessage := testMessage
+			userMessage.From = &telego.User{ID: testUserID + 1, LanguageCode: "en"}
+			s.mockAdminChecker.On("IsAdmin", ctx, userMessage.From.ID).Return(false, nil).Once()
+			var capturedParams *telego.SendMessageParams
+			s.mockBot.On("SendMessage", ctx, mock.AnythingOfType("*telego.SendMessageParams")).
+				Run(func(args mock.Arguments) {
+					capturedParams, _ = args.Get(1).(*telego.SendMessageParams)
+				}).
+				Return(&telego.Message{}, nil).Once()
+
+			// Act
+			err := s.handler.HandleStatus(ctx, s.mockBot, userMessage)
+
+			// Assert
+			assert.NoError(t, err)
+			s.mockBot.AssertExpectations(t)
+			if assert.NotNil(t, capturedParams) {
+				assert.Equal(t, locales.GetMessage(locales.NewLocalizer("en"), "MsgSuggestionStatusUsage", nil, nil), capturedParams.Text)
+			}
+		})
+
+		t.Run("WithCode", func(t *testing.T) {
+			// Arrange
+			codeMessage := testMessage
+			codeMessage.Text = "/status s-4f7k"
+			s.mockSuggestionManager.On("HandleSuggestionStatusCommand", ctx, telego.Update{Message: &codeMessage}, "s-4f7k").Return(nil).Once()
+			s.mockActionLogger.On("LogUserAction", testUserID, ActionCommandStatus, mock.Anything).Return(nil).Once()
+			s.mockUserRepo.On("UpdateUser", ctx, *testMessage.From, false, ActionCommandStatus).Return(nil).Once()
+
+			// Act
+			err := s.handler.HandleStatus(ctx, s.mockBot, codeMessage)
+
+			// Assert
+			assert.NoError(t, err)
+			s.mockSuggestionManager.AssertExpectations(t)
+			s.mockUserRepo.AssertExpectations(t)
+		})
 	})
 }
 
@@ -636,12 +1119,14 @@ func TestHandleVersion(t *testing.T) {
 		localizer := locales.NewLocalizer("en")
 		// Call GetMessage WITH template data
 		versionText := locales.GetMessage(localizer, "MsgVersion", map[string]interface{}{
-			"Version": s.handler.version, // Use version from suite
+			"Version": testVersion,
+			"Commit":  "0123456789ab",
+			"Date":    "unknown",
 		}, nil)
 		expectedEscapedText := utils.EscapeMarkdownV2(versionText)
 
 		s.mockActionLogger.On("LogUserAction", testUserID, ActionCommandVersion, mock.Anything).Return(nil).Once()
-		s.mockUserRepo.On("UpdateUser", ctx, testUserID, testUsername, testFirstName, testLastName, false, ActionCommandVersion).Return(nil).Once() // Use ActionCommandVersion
+		s.mockUserRepo.On("UpdateUser", ctx, *testMessage.From, false, ActionCommandVersion).Return(nil).Once() // Use ActionCommandVersion
 		s.mockAdminChecker.On("IsAdmin", ctx, testUserID).Return(false, nil).Maybe()
 
 		// Expect SendMessage call, capture the params
@@ -673,3 +1158,474 @@ func TestHandleVersion(t *testing.T) {
 }
 
 // TODO: Add tests for HandleCaption, HandleShowCaption, HandleClearCaption if needed
+
+// recordCommandMenus records the command names of the menus set by SyncCommandMenus, by scope
+// type or chat ID. Setting the scopes in failing fails.
+func recordCommandMenus(s *testHandlerSuite, failing ...string) map[string][]string {
+	scopeOf := func(params *telego.SetMyCommandsParams) string {
+		if chatScope, ok := params.Scope.(*telego.BotCommandScopeChat); ok {
+			return fmt.Sprint(chatScope.ChatID.ID)
+		}
+		return params.Scope.ScopeType()
+	}
+	fails := func(params *telego.SetMyCommandsParams) bool { return slices.Contains(failing, scopeOf(params)) }
+
+	menus := make(map[string][]string)
+	s.mockBot.On("SetMyCommands", mock.Anything, mock.MatchedBy(fails)).Return(errors.New("setting the menu failed"))
+	s.mockBot.On("SetMyCommands", mock.Anything, mock.MatchedBy(func(params *telego.SetMyCommandsParams) bool { return !fails(params) })).
+		Run(func(args mock.Arguments) {
+			params := args.Get(1).(*telego.SetMyCommandsParams)
+			var names []string
+			for _, cmd := range params.Commands {
+				names = append(names, cmd.Command)
+			}
+			menus[scopeOf(params)] = names
+		}).
+		Return(nil)
+	return menus
+}
+
+// testAdminMenu is the admin command menu of setupTestHandlerSuite.
+var testAdminMenu = []string{"start", "help", "status", "version", "caption", "showcaption", "clearcaption", "review", "posturl"}
+
+func TestSyncCommandMenus(t *testing.T) {
+	locales.Init("en")
+	s := setupTestHandlerSuite(t)
+	ctx := context.Background()
+
+	menus := recordCommandMenus(s)
+	s.mockAdminChecker.On("Refresh", ctx).Return([]int64{1, 2}, nil).Once()
+	s.mockAdminChecker.On("SuperAdminIDs").Return([]int64{2, 3})
+
+	assert.NoError(t, s.handler.SyncCommandMenus(ctx, s.mockBot))
+
+	assert.Equal(t, []string{"start", "help"}, menus[telego.ScopeTypeDefault])
+	assert.Equal(t, []string{"start", "help", "suggest", "feedback"}, menus[telego.ScopeTypeAllPrivateChats])
+	assert.Equal(t, testAdminMenu, menus["1"])
+	assert.Equal(t, testAdminMenu, menus["2"])
+	assert.Equal(t, testAdminMenu, menus["3"], "super admin who isn't a channel admin has no admin menu")
+
+	// A demoted admin loses the admin menu; a remaining one isn't set again
+	s.mockBot.On("DeleteMyCommands", ctx, mock.AnythingOfType("*telego.DeleteMyCommandsParams")).Return(nil).Once()
+	s.handler.updateAdminMenus(ctx, s.mockBot, []int64{2, 3})
+
+	s.mockBot.AssertNumberOfCalls(t, "SetMyCommands", 5)
+	deleted := s.mockBot.Calls[len(s.mockBot.Calls)-1].Arguments.Get(1).(*telego.DeleteMyCommandsParams)
+	assert.Equal(t, telegoutil.ScopeChat(telegoutil.ID(1)), deleted.Scope)
+	assert.Equal(t, map[int64]struct{}{2: {}, 3: {}}, s.handler.adminMenus)
+}
+
+func TestSyncCommandMenusWithoutAdminList(t *testing.T) {
+	locales.Init("en")
+	s := setupTestHandlerSuite(t)
+	ctx := context.Background()
+
+	menus := recordCommandMenus(s, telego.ScopeTypeDefault)
+	s.mockAdminChecker.On("Refresh", ctx).Return(nil, errors.New("channel unavailable")).Once()
+	s.mockAdminChecker.On("SuperAdminIDs").Return([]int64{3})
+
+	// Failures are reported, but every other menu is still set
+	err := s.handler.SyncCommandMenus(ctx, s.mockBot)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to set default bot commands")
+	assert.NotContains(t, err.Error(), "channel unavailable", "failing to load the admins isn't an error")
+	assert.Equal(t, []string{"start", "help", "suggest", "feedback"}, menus[telego.ScopeTypeAllPrivateChats])
+	assert.Equal(t, testAdminMenu, menus["3"])
+	assert.Equal(t, map[int64]struct{}{3: {}}, s.handler.adminMenus)
+}
+
+// pingerFunc adapts a function to database.Pinger.
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) Ping(ctx context.Context) error { return f(ctx) }
+
+func TestHandleDiag(t *testing.T) {
+	locales.Init("en")
+	ctx := context.Background()
+	message := telego.Message{
+		From: &telego.User{ID: 42, Username: "owner", LanguageCode: "en"},
+		Chat: telego.Chat{ID: 42},
+		Text: "/diag",
+	}
+
+	t.Run("NotSuperAdmin", func(t *testing.T) {
+		s := setupTestHandlerSuite(t)
+		s.mockAdminChecker.On("IsSuperAdmin", int64(42)).Return(false).Once()
+		s.mockBot.On("SendMessage", ctx, mock.AnythingOfType("*telego.SendMessageParams")).Return(&telego.Message{}, nil).Once()
+
+		assert.Error(t, s.handler.HandleDiag(ctx, s.mockBot, message))
+		s.mockBot.AssertNotCalled(t, "GetMe", mock.Anything)
+	})
+
+	t.Run("Report", func(t *testing.T) {
+		s := setupTestHandlerSuite(t)
+		s.handler.publishQueue = publisher.NewQueue(time.Second)
+		s.handler.SetDatabasePinger(pingerFunc(func(ctx context.Context) error { return nil }))
+		s.mockAdminChecker.On("IsSuperAdmin", int64(42)).Return(true).Once()
+		s.mockBot.On("GetMe", mock.Anything).Return(&telego.User{ID: 7, Username: "MemesBot", IsBot: true}, nil).Once()
+		s.mockBot.On("GetChatMember", mock.Anything, mock.AnythingOfType("*telego.GetChatMemberParams")).
+			Return(&telego.ChatMemberAdministrator{Status: telego.MemberStatusAdministrator, CanPostMessages: false}, nil).Once()
+		s.mockBot.On("GetWebhookInfo", mock.Anything).Return(&telego.WebhookInfo{URL: "https://example.com/hook", PendingUpdateCount: 3}, nil).Once()
+		s.mockActionLogger.On("LogUserAction", int64(42), ActionCommandDiag, mock.Anything).Return(nil).Once()
+		s.mockUserRepo.On("UpdateUser", ctx, *message.From, true, ActionCommandDiag).Return(nil).Once()
+		var report string
+		s.mockBot.On("SendMessage", ctx, mock.AnythingOfType("*telego.SendMessageParams")).
+			Run(func(args mock.Arguments) {
+				report = args.Get(1).(*telego.SendMessageParams).Text
+			}).
+			Return(&telego.Message{}, nil).Once()
+
+		assert.NoError(t, s.handler.HandleDiag(ctx, s.mockBot, message))
+		assert.Contains(t, report, "✅ MongoDB")
+		assert.Contains(t, report, "✅ Telegram: @MemesBot (ID 7)")
+		assert.Contains(t, report, "❌ Channel")
+		assert.Contains(t, report, "❌ Webhook: set to https://example.com/hook")
+		assert.Contains(t, report, "Publish queue: 0 pending")
+		assert.Contains(t, report, testVersion)
+	})
+}
+
+func TestHandleUser(t *testing.T) {
+	locales.Init("en")
+	ctx := context.Background()
+	message := telego.Message{
+		From: &telego.User{ID: 42, Username: "admin", LanguageCode: "en"},
+		Chat: telego.Chat{ID: 42},
+		Text: "/user 1001",
+	}
+
+	t.Run("Profile", func(t *testing.T) {
+		s := setupTestHandlerSuite(t)
+		s.mockAdminChecker.On("IsAdmin", ctx, int64(42)).Return(true, nil).Once()
+		s.mockUserRepo.On("GetUser", ctx, int64(1001)).Return(&models.User{
+			UserID:           1001,
+			Username:         "memer",
+			FirstName:        "Meme",
+			IsPremium:        true,
+			ClientLang:       "ru",
+			FirstSeen:        time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC),
+			SuggestionsCount: 5,
+			ApprovalsCount:   2,
+		}, nil).Once()
+		s.mockActionLogger.On("LogUserAction", int64(42), ActionCommandUser, mock.Anything).Return(nil).Once()
+		s.mockUserRepo.On("UpdateUser", ctx, *message.From, true, ActionCommandUser).Return(nil).Once()
+		var profile string
+		s.mockBot.On("SendMessage", ctx, mock.AnythingOfType("*telego.SendMessageParams")).
+			Run(func(args mock.Arguments) {
+				profile = args.Get(1).(*telego.SendMessageParams).Text
+			}).
+			Return(&telego.Message{}, nil).Once()
+
+		assert.NoError(t, s.handler.HandleUser(ctx, s.mockBot, message))
+		assert.Contains(t, profile, "Username: @memer")
+		assert.Contains(t, profile, "Language: — (Telegram client: ru)")
+		assert.Contains(t, profile, "Flags: Telegram Premium")
+		assert.Contains(t, profile, "First seen: 2026-01-02 03:04 UTC")
+		assert.Contains(t, profile, "Suggestions: 5 (approved: 2)")
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		s := setupTestHandlerSuite(t)
+		invalid := message
+		invalid.Text = "/user @memer"
+		s.mockAdminChecker.On("IsAdmin", ctx, int64(42)).Return(true, nil).Once()
+		s.mockBot.On("SendMessage", ctx, mock.AnythingOfType("*telego.SendMessageParams")).Return(&telego.Message{}, nil).Once()
+
+		assert.NoError(t, s.handler.HandleUser(ctx, s.mockBot, invalid))
+		s.mockUserRepo.AssertNotCalled(t, "GetUser", mock.Anything, mock.Anything)
+	})
+}
+
+func TestNotificationsToggle(t *testing.T) {
+	locales.Init("en")
+	s := setupTestHandlerSuite(t)
+	ctx := context.Background()
+	query := telego.CallbackQuery{
+		ID:      "query",
+		From:    telego.User{ID: 42, Username: "memer"},
+		Message: &telego.Message{MessageID: 7, Chat: telego.Chat{ID: 42}},
+		Data:    notificationsCallbackPrefix + models.NotificationCategoryDecisions,
+	}
+
+	s.mockUserRepo.On("GetUser", ctx, int64(42)).Return(&models.User{
+		UserID:             42,
+		MutedNotifications: []string{models.NotificationCategoryDecisions, models.NotificationCategoryBroadcasts},
+	}, nil).Once()
+	s.mockUserRepo.On("SetNotificationMuted", ctx, int64(42), models.NotificationCategoryDecisions, false).Return(nil).Once()
+	s.mockBot.On("AnswerCallbackQuery", ctx, mock.AnythingOfType("*telego.AnswerCallbackQueryParams")).Return(nil).Once()
+	var panel string
+	s.mockBot.On("EditMessageText", ctx, mock.AnythingOfType("*telego.EditMessageTextParams")).
+		Run(func(args mock.Arguments) {
+			panel = args.Get(1).(*telego.EditMessageTextParams).Text
+		}).
+		Return(&telego.Message{}, nil).Once()
+
+	handled, err := s.handler.HandleCallback(ctx, s.mockBot, query)
+	require.NoError(t, err)
+	assert.True(t, handled)
+	assert.Contains(t, panel, "✅ Approval, rejection and expiry of your suggestions")
+	assert.Contains(t, panel, "✅ Channel digests")
+	assert.Contains(t, panel, "🔕 Announcements")
+	s.mockUserRepo.AssertExpectations(t)
+}
+
+func TestHandleSetGreeting(t *testing.T) {
+	locales.Init("en")
+	ctx := context.Background()
+	s := setupTestHandlerSuite(t)
+	settings := memorySettingsRepository{}
+	s.handler.SetSettingsRepository(settings)
+	s.handler.SetGreetingLinks("https://t.me/memes", "https://example.com/rules?a=1&b=2")
+
+	admin := &telego.User{ID: 42, FirstName: "Admin", LanguageCode: "en"}
+	message := telego.Message{
+		From: admin,
+		Chat: telego.Chat{ID: 42},
+		Text: "/setgreeting  👋 Hi, {first_name}! Rules: {rules_link} <3\n",
+		Entities: []telego.MessageEntity{
+			{Type: telego.EntityTypeBotCommand, Offset: 0, Length: 12},
+			{Type: telego.EntityTypeBold, Offset: 17, Length: 2}, // "Hi", after a two-unit emoji
+		},
+	}
+	var sent []*telego.SendMessageParams
+	s.mockBot.On("SendMessage", ctx, mock.AnythingOfType("*telego.SendMessageParams")).
+		Run(func(args mock.Arguments) {
+			sent = append(sent, args.Get(1).(*telego.SendMessageParams))
+		}).
+		Return(&telego.Message{}, nil)
+	s.mockAdminChecker.On("IsAdmin", ctx, mock.Anything).Return(true, nil)
+	s.mockActionLogger.On("LogUserAction", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	s.mockUserRepo.On("UpdateUser", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	s.mockSuggestionManager.On("ShowPrivacyNotice", ctx, mock.Anything, mock.Anything).Return(nil)
+
+	// The greeting is previewed for the admin, then saved as HTML
+	require.NoError(t, s.handler.HandleSetGreeting(ctx, s.mockBot, message))
+	require.Len(t, sent, 2)
+	assert.Equal(t, telego.ModeHTML, sent[0].ParseMode)
+	assert.Equal(t, "👋 <b>Hi</b>, Admin! Rules: https://example.com/rules?a=1&amp;b=2 &lt;3", sent[0].Text)
+	assert.Equal(t, "👋 <b>Hi</b>, {first_name}! Rules: {rules_link} &lt;3", settings[models.SettingGreeting])
+
+	// /start sends it with the user's placeholders filled in
+	sent = nil
+	start := telego.Message{From: &telego.User{ID: 7, FirstName: "<Bob>"}, Chat: telego.Chat{ID: 7}, Text: "/start"}
+	require.NoError(t, s.handler.HandleStart(ctx, s.mockBot, start))
+	require.NotEmpty(t, sent)
+	assert.Equal(t, "👋 <b>Hi</b>, &lt;Bob&gt;! Rules: https://example.com/rules?a=1&amp;b=2 &lt;3", sent[0].Text)
+
+	// After a reset, /start uses the default again
+	reset := message
+	reset.Text, reset.Entities = "/setgreeting reset", nil
+	require.NoError(t, s.handler.HandleSetGreeting(ctx, s.mockBot, reset))
+	assert.Empty(t, settings)
+	sent = nil
+	require.NoError(t, s.handler.HandleStart(ctx, s.mockBot, start))
+	require.NotEmpty(t, sent)
+	assert.Equal(t, telego.ModeMarkdownV2, sent[0].ParseMode)
+}
+
+func TestHandleSetup(t *testing.T) {
+	locales.Init("en")
+	defer locales.Init("en")
+	ctx := context.Background()
+	s := setupTestHandlerSuite(t)
+	settings := memorySettingsRepository{}
+	s.handler.SetSettingsRepository(settings)
+
+	owner := &telego.User{ID: 42, Username: "owner", LanguageCode: "en"}
+	message := telego.Message{From: owner, Chat: telego.Chat{ID: 42}, Text: "/setup"}
+	s.mockAdminChecker.On("IsSuperAdmin", int64(42)).Return(true)
+	s.mockActionLogger.On("LogUserAction", int64(42), ActionCommandSetup, mock.Anything).Return(nil).Once()
+	s.mockUserRepo.On("UpdateUser", ctx, *owner, true, ActionCommandSetup).Return(nil).Once()
+	s.mockBot.On("GetMe", mock.Anything).Return(&telego.User{ID: 7, IsBot: true}, nil).Once()
+	s.mockBot.On("GetChatMember", mock.Anything, mock.AnythingOfType("*telego.GetChatMemberParams")).
+		Return(&telego.ChatMemberAdministrator{Status: telego.MemberStatusAdministrator, CanPostMessages: true}, nil).Once()
+	var sent *telego.SendMessageParams
+	s.mockBot.On("SendMessage", ctx, mock.AnythingOfType("*telego.SendMessageParams")).
+		Run(func(args mock.Arguments) { sent = args.Get(1).(*telego.SendMessageParams) }).
+		Return(&telego.Message{MessageID: 100}, nil)
+	var edited *telego.EditMessageTextParams
+	s.mockBot.On("EditMessageText", ctx, mock.AnythingOfType("*telego.EditMessageTextParams")).
+		Run(func(args mock.Arguments) { edited = args.Get(1).(*telego.EditMessageTextParams) }).
+		Return(&telego.Message{}, nil)
+	s.mockBot.On("AnswerCallbackQuery", ctx, mock.AnythingOfType("*telego.AnswerCallbackQueryParams")).Return(nil)
+
+	// The wizard starts with the channel check, which is stored
+	require.NoError(t, s.handler.HandleSetup(ctx, s.mockBot, message))
+	require.NotNil(t, sent)
+	assert.Contains(t, sent.Text, "step 1 of 4")
+	assert.Contains(t, sent.Text, "Can post messages: ✅")
+	assert.Contains(t, settings[models.SettingSetup], `"can_post":true`)
+
+	press := func(data string) {
+		t.Helper()
+		query := telego.CallbackQuery{ID: "q", From: *owner, Data: data, Message: &telego.Message{MessageID: 100, Chat: telego.Chat{ID: 42}}}
+		handled, err := s.handler.HandleCallback(ctx, s.mockBot, query)
+		require.True(t, handled)
+		require.NoError(t, err)
+	}
+
+	// Choices are stored as settings and applied right away
+	press("setup:lang:ru")
+	assert.Equal(t, "ru", settings[models.SettingDefaultLanguage])
+	assert.Equal(t, "ru", locales.GetDefaultLanguageTag().String())
+	s.mockSuggestionManager.On("SetCreditSuggesters", true).Once()
+	press("setup:toggle:suggesters")
+	assert.Equal(t, "true", settings[models.SettingCreditSuggesters])
+	assert.Contains(t, edited.Text, "шаг 3 из 4")
+
+	press("setup:testpost")
+	assert.Contains(t, settings[models.SettingSetup], `"test_message_id":100`)
+	press("setup:done")
+	assert.Contains(t, settings[models.SettingSetup], `"completed_by":42`)
+	assert.Nil(t, edited.ReplyMarkup)
+	s.mockSuggestionManager.AssertExpectations(t)
+}
+
+func TestHandleAutoReject(t *testing.T) {
+	locales.Init("en")
+	ctx := context.Background()
+	admin := &telego.User{ID: 42, FirstName: "Admin", LanguageCode: "en"}
+	user := &telego.User{ID: 7, FirstName: "User", LanguageCode: "en"}
+	command := func(from *telego.User, text string) telego.Message {
+		return telego.Message{From: from, Chat: telego.Chat{ID: from.ID}, Text: text}
+	}
+	setup := func(t *testing.T) (*testHandlerSuite, memorySettingsRepository, *[]string) {
+		s := setupTestHandlerSuite(t)
+		settings := memorySettingsRepository{}
+		s.handler.SetSettingsRepository(settings)
+		var sent []string
+		s.mockBot.On("SendMessage", ctx, mock.AnythingOfType("*telego.SendMessageParams")).
+			Run(func(args mock.Arguments) {
+				sent = append(sent, args.Get(1).(*telego.SendMessageParams).Text)
+			}).
+			Return(&telego.Message{}, nil)
+		s.mockAdminChecker.On("IsAdmin", ctx, admin.ID).Return(true, nil)
+		s.mockAdminChecker.On("IsAdmin", ctx, user.ID).Return(false, nil)
+		s.mockActionLogger.On("LogUserAction", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		s.mockUserRepo.On("UpdateUser", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		return s, settings, &sent
+	}
+
+	t.Run("shows the rules", func(t *testing.T) {
+		s, settings, sent := setup(t)
+		s.mockSuggestionManager.On("AutoRejectRules").Return(suggestions.AutoRejectRules{
+			CaptionPattern: regexp.MustCompile("(?i)casino"),
+			MaxMediaCount:  1,
+			MinAccountAge:  3 * 24 * time.Hour,
+		})
+
+		require.NoError(t, s.handler.HandleAutoReject(ctx, s.mockBot, command(admin, "/autoreject")))
+		require.Len(t, *sent, 1)
+		assert.Equal(t, "🚫 Suggestions are rejected automatically:\n"+
+			"• if the caption matches (?i)casino\n"+
+			"• with more than 1 file\n"+
+			"• from users the bot first saw less than 3 days ago", (*sent)[0])
+		assert.Empty(t, settings)
+		s.mockSuggestionManager.AssertNotCalled(t, "SetAutoRejectRules", mock.Anything)
+	})
+
+	t.Run("no rules", func(t *testing.T) {
+		s, _, sent := setup(t)
+		s.mockSuggestionManager.On("AutoRejectRules").Return(suggestions.AutoRejectRules{})
+
+		require.NoError(t, s.handler.HandleAutoReject(ctx, s.mockBot, command(admin, "/autoreject")))
+		require.Len(t, *sent, 1)
+		assert.Equal(t, "✅ No auto-reject rules are set: every suggestion goes to review.", (*sent)[0])
+	})
+
+	t.Run("admins edit and reset the rules", func(t *testing.T) {
+		s, settings, sent := setup(t)
+		s.handler.SetAutoRejectRulesDefault(suggestions.AutoRejectRules{MaxMediaCount: 10})
+		current := suggestions.AutoRejectRules{MaxMediaCount: 10}
+		s.mockSuggestionManager.On("AutoRejectRules").Return(func() suggestions.AutoRejectRules { return current })
+		s.mockSuggestionManager.On("SetAutoRejectRules", mock.Anything).
+			Run(func(args mock.Arguments) { current = args.Get(0).(suggestions.AutoRejectRules) })
+
+		require.NoError(t, s.handler.HandleAutoReject(ctx, s.mockBot, command(admin, "/autoreject subscription 2")))
+		assert.Equal(t, 10, current.MaxMediaCount, "other rules were changed")
+		assert.Equal(t, 2*24*time.Hour, current.MinSubscriptionAge)
+		assert.JSONEq(t, `{"max_media":10,"min_subscription_days":2}`, settings[models.SettingAutoRejectRules])
+		assert.Contains(t, (*sent)[0], "✅ Auto-reject rules updated.")
+		assert.Contains(t, (*sent)[0], "• from users subscribed to the channel for less than 2 days")
+
+		require.NoError(t, s.handler.HandleAutoReject(ctx, s.mockBot, command(admin, "/autoreject media off")))
+		assert.Zero(t, current.MaxMediaCount)
+		assert.JSONEq(t, `{"min_subscription_days":2}`, settings[models.SettingAutoRejectRules])
+
+		// An invalid value changes nothing
+		require.NoError(t, s.handler.HandleAutoReject(ctx, s.mockBot, command(admin, "/autoreject caption (unclosed")))
+		assert.Contains(t, (*sent)[2], "Invalid value for the caption rule")
+		assert.Nil(t, current.CaptionPattern)
+
+		// The stored rules outlive a restart
+		s.mockSuggestionManager.On("SetCreditSuggesters", mock.Anything)
+		s.mockSuggestionManager.On("SetCreditForwardSource", mock.Anything)
+		s.mockSuggestionManager.On("SetProtectContent", mock.Anything)
+		s.mockSuggestionManager.On("SetContentPolicy", mock.Anything)
+		current = suggestions.AutoRejectRules{}
+		s.handler.ApplySetupSettings(ctx)
+		assert.Equal(t, 2*24*time.Hour, current.MinSubscriptionAge)
+
+		require.NoError(t, s.handler.HandleAutoReject(ctx, s.mockBot, command(admin, "/autoreject default")))
+		assert.Equal(t, suggestions.AutoRejectRules{MaxMediaCount: 10}, current)
+		assert.NotContains(t, settings, models.SettingAutoRejectRules)
+	})
+
+	t.Run("only admins can edit", func(t *testing.T) {
+		s, settings, sent := setup(t)
+
+		err := s.handler.HandleAutoReject(ctx, s.mockBot, command(user, "/autoreject media 1"))
+		require.EqualError(t, err, "⛔ This command is only available to administrators.")
+		require.Len(t, *sent, 1)
+		assert.Empty(t, settings)
+		s.mockSuggestionManager.AssertNotCalled(t, "SetAutoRejectRules", mock.Anything)
+	})
+}
+
+func TestPrivateOnlyCommandRedirectsFromGroups(t *testing.T) {
+	locales.Init("en")
+	ctx := context.Background()
+	s := setupTestHandlerSuite(t)
+	ran := 0
+	s.handler.commands = []Command{{Command: "suggest", Role: RoleUser, PrivateOnly: true,
+		Handler: func(context.Context, telegoapi.BotAPI, telego.Message) error { ran++; return nil }}}
+	var sent []*telego.SendMessageParams
+	s.mockBot.On("GetMe", ctx).Return(&telego.User{ID: 1, Username: "memes_bot"}, nil)
+	s.mockBot.On("SendMessage", ctx, mock.AnythingOfType("*telego.SendMessageParams")).
+		Run(func(args mock.Arguments) {
+			sent = append(sent, args.Get(1).(*telego.SendMessageParams))
+		}).
+		Return(&telego.Message{}, nil)
+
+	user := &telego.User{ID: 7, FirstName: "Bob", LanguageCode: "en"}
+	handler := s.handler.GetCommandHandler("suggest")
+	require.NoError(t, handler(ctx, s.mockBot, telego.Message{From: user, Chat: telego.Chat{ID: 7, Type: telego.ChatTypePrivate}, Text: "/suggest"}))
+	assert.Equal(t, 1, ran)
+	assert.Empty(t, sent)
+
+	require.NoError(t, handler(ctx, s.mockBot, telego.Message{MessageID: 5, From: user, Chat: telego.Chat{ID: -100, Type: telego.ChatTypeSupergroup}, Text: "/suggest"}))
+	assert.Equal(t, 1, ran, "the command must not run in groups")
+	require.Len(t, sent, 1)
+	assert.Equal(t, int64(-100), sent[0].ChatID.ID)
+	assert.Contains(t, sent[0].Text, "private chat")
+	keyboard := sent[0].ReplyMarkup.(*telego.InlineKeyboardMarkup)
+	assert.Equal(t, "https://t.me/memes_bot?start=suggest_group", keyboard.InlineKeyboard[0][0].URL)
+}
+
+func TestParseCaptionVariants(t *testing.T) {
+	a, b, err := parseCaptionVariants("Question: Who else does this? || plain:Friday mood")
+	require.NoError(t, err)
+	assert.Equal(t, models.CaptionVariant{Family: "question", Caption: "Who else does this?"}, a)
+	assert.Equal(t, models.CaptionVariant{Family: "plain", Caption: "Friday mood"}, b)
+
+	for _, invalid := range []string{
+		"question: Who else does this?",
+		"question: a || question: b",
+		"two words: a || plain: b",
+		"question: || plain: b",
+		"a: 1 || b: 2 || c: 3",
+	} {
+		_, _, err := parseCaptionVariants(invalid)
+		assert.ErrorIs(t, err, errCaptionVariantsMalformed, invalid)
+	}
+}
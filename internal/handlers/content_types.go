@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/suggestions"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+)
+
+// contentTypesArgs declares the arguments of /contenttypes.
+var contentTypesArgs = cmdargs.Spec{
+	Command: "contenttypes",
+	Args: []cmdargs.Arg{
+		{Name: "types|default", Rest: true},
+	},
+}
+
+// SetContentPolicyDefault sets the media types accepted in suggestions when /contenttypes hasn't
+// chosen otherwise, i.e. the SUGGESTION_CONTENT_TYPES variable.
+func (h *MessageHandler) SetContentPolicyDefault(policy suggestions.ContentPolicy) {
+	h.contentPolicy = policy
+}
+
+// contentPolicySetting returns the content types chosen with /contenttypes, or the default if
+// none were chosen or they can't be loaded.
+func (h *MessageHandler) contentPolicySetting(ctx context.Context) suggestions.ContentPolicy {
+	setting, err := h.settingsRepo.GetSetting(ctx, models.SettingSuggestionContentTypes)
+	if err != nil {
+		log.Printf("[Setup] Error loading setting %s: %v", models.SettingSuggestionContentTypes, err)
+		return h.contentPolicy
+	}
+	if setting == nil {
+		return h.contentPolicy
+	}
+	policy, err := suggestions.ParseContentPolicy(setting.Value)
+	if err != nil {
+		log.Printf("[Setup] Ignoring the stored suggestion content types: %v", err)
+		return h.contentPolicy
+	}
+	return policy
+}
+
+// HandleContentTypes handles the /contenttypes [types...|default] command (super admins only).
+// It sets which media suggestions may be made of: photos, videos, GIFs (animation) and documents.
+// The choice is stored as a setting and takes precedence over SUGGESTION_CONTENT_TYPES, which
+// "default" goes back to. Without arguments, the accepted types are shown.
+func (h *MessageHandler) HandleContentTypes(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	if !h.adminChecker.IsSuperAdmin(userID) {
+		log.Printf("[Cmd:contenttypes User:%d] Non-super-admin user attempted to use /contenttypes.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresSuperAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	if h.settingsRepo == nil {
+		return h.sendError(ctx, bot, chatID, errors.New("settings repository is not configured"))
+	}
+
+	args, err := contentTypesArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	value := args.Arg("types|default")
+	if value == "" {
+		policy := h.suggestionManager.ContentPolicy()
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgContentTypesCurrent", map[string]interface{}{
+			"Types":       policy.String(),
+			"Description": policy.Describe(localizer),
+		}, nil))
+	}
+
+	var policy suggestions.ContentPolicy
+	if strings.EqualFold(value, "default") {
+		if err := h.settingsRepo.DeleteSetting(ctx, models.SettingSuggestionContentTypes); err != nil {
+			return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to reset suggestion content types: %w", err))
+		}
+		policy = h.contentPolicy
+	} else {
+		policy, err = suggestions.ParseContentPolicy(value)
+		if err != nil {
+			log.Printf("[Cmd:contenttypes User:%d] Invalid content types %q: %v", userID, value, err)
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgContentTypesInvalid", nil, nil))
+		}
+		if err := h.settingsRepo.SetSetting(ctx, models.SettingSuggestionContentTypes, policy.String(), userID); err != nil {
+			return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to save suggestion content types: %w", err))
+		}
+	}
+	h.suggestionManager.SetContentPolicy(policy)
+	policy = h.suggestionManager.ContentPolicy()
+	log.Printf("[Cmd:contenttypes User:%d] Suggestion content types set to %s", userID, policy)
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandContentTypes, true, map[string]interface{}{
+		"chat_id": chatID,
+		"types":   policy.String(),
+	})
+	return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgContentTypesSet", map[string]interface{}{
+		"Types":       policy.String(),
+		"Description": policy.Describe(localizer),
+	}, nil))
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"vrcmemes-bot/internal/customemoji"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// SetCustomEmoji sets whether custom emoji, e.g. of Telegram Premium emoji packs, are kept in the
+// posts admins send: in their text, the active caption and the caption style. Bots can only send
+// custom emoji if their username was bought on Fragment, so by default they are published as the
+// regular emoji they stand for, and admins are warned when they use them.
+func (h *MessageHandler) SetCustomEmoji(enabled bool) {
+	h.customEmoji = enabled
+}
+
+// setActiveCaption stores the active caption of a chat with its custom emoji and reports whether
+// the chat had an active caption already.
+func (h *MessageHandler) setActiveCaption(chatID int64, caption string, entities []telego.MessageEntity) bool {
+	_, exists := h.activeCaptions.Load(chatID)
+	h.activeCaptions.Store(chatID, caption)
+	if emoji := customemoji.FromEntities(entities); len(emoji) > 0 {
+		h.activeCaptionEmoji.Store(chatID, emoji)
+	} else {
+		h.activeCaptionEmoji.Delete(chatID)
+	}
+	return exists
+}
+
+// CaptionEntities returns the custom emoji entities of a caption built for an admin post sent in
+// the chat: those of the active caption and of the active caption style, at their offsets in the
+// caption. There are none if custom emoji are disabled.
+func (h *MessageHandler) CaptionEntities(ctx context.Context, chatID int64, caption string) []telego.MessageEntity {
+	if !h.customEmoji || caption == "" {
+		return nil
+	}
+	var emoji []models.CustomEmoji
+	if active, ok := h.activeCaptionEmoji.Load(chatID); ok {
+		if text, ok := h.GetActiveCaption(chatID); ok {
+			emoji = append(emoji, customemoji.Relocate(active.([]models.CustomEmoji), text, caption)...)
+		}
+	}
+	if style, ok := h.activeStyle(ctx, chatID); ok {
+		emoji = append(emoji, customemoji.Styled(style, caption)...)
+	}
+	return customemoji.Entities(emoji)
+}
+
+// customEmojiWarning returns a warning that the custom emoji of a text will be published as
+// regular emoji, or "" if the text has none or they are kept.
+func (h *MessageHandler) customEmojiWarning(localizer *i18n.Localizer, entities []telego.MessageEntity) string {
+	if h.customEmoji || len(customemoji.FromEntities(entities)) == 0 {
+		return ""
+	}
+	return locales.GetMessage(localizer, "MsgCustomEmojiStripped", nil, nil)
+}
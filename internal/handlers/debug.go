@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/debugmode"
+	"vrcmemes-bot/internal/format"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+)
+
+const (
+	// defaultTraceDuration is how long /trace mirrors updates without for=.
+	defaultTraceDuration = 10 * time.Minute
+	// maxTraceDuration bounds a trace, so a forgotten one doesn't flood the admin chat.
+	maxTraceDuration = time.Hour
+)
+
+// debugArgs declares the arguments of /debug.
+var debugArgs = cmdargs.Spec{
+	Command: "debug",
+	Args: []cmdargs.Arg{
+		{Name: "mode", Choices: []string{"on", "off"}},
+	},
+}
+
+// traceArgs declares the arguments of /trace.
+var traceArgs = cmdargs.Spec{
+	Command: "trace",
+	Options: []string{"for"},
+	Args: []cmdargs.Arg{
+		{Name: "types|off", Rest: true},
+	},
+}
+
+// SetDebugSwitch sets the debug state flipped by /debug and /trace. Traced updates are mirrored
+// to traceChatID, or to the chat /trace was sent in if it is 0.
+func (h *MessageHandler) SetDebugSwitch(debug *debugmode.Switch, traceChatID int64) {
+	h.debug = debug
+	h.traceChatID = traceChatID
+}
+
+// HandleDebug handles the /debug [on|off] command (super admins only).
+// It turns verbose logging of the bot and of the Telegram client on or off until the next
+// restart, which goes back to DEBUG. Without arguments, the current state is shown.
+func (h *MessageHandler) HandleDebug(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	if !h.adminChecker.IsSuperAdmin(userID) {
+		log.Printf("[Cmd:debug User:%d] Non-super-admin user attempted to use /debug.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresSuperAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	if h.debug == nil {
+		return h.sendError(ctx, bot, chatID, errors.New("debug switch is not configured"))
+	}
+
+	args, err := debugArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	mode := args.Arg("mode")
+	if mode == "" {
+		messageID := "MsgDebugStatusOff"
+		if h.debug.Enabled() {
+			messageID = "MsgDebugStatusOn"
+		}
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, messageID, nil, nil))
+	}
+
+	enabled := mode == "on"
+	h.debug.Set(enabled)
+	log.Printf("[Cmd:debug User:%d] Debug logging turned %s", userID, mode)
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandDebug, true, map[string]interface{}{
+		"chat_id": chatID,
+		"enabled": enabled,
+	})
+	messageID := "MsgDebugTurnedOff"
+	if enabled {
+		messageID = "MsgDebugTurnedOn"
+	}
+	return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, messageID, nil, nil))
+}
+
+// HandleTrace handles the /trace [for=duration] <types...|off> command (super admins only).
+// For a while, raw updates of the given types are mirrored to the admin chat as JSON, replacing
+// any running trace. Without arguments, the running trace is shown.
+func (h *MessageHandler) HandleTrace(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	if !h.adminChecker.IsSuperAdmin(userID) {
+		log.Printf("[Cmd:trace User:%d] Non-super-admin user attempted to use /trace.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresSuperAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	if h.debug == nil {
+		return h.sendError(ctx, bot, chatID, errors.New("debug switch is not configured"))
+	}
+
+	args, err := traceArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	now := time.Now()
+	value := args.Arg("types|off")
+
+	switch {
+	case value == "":
+		_, types, until, ok := h.debug.Trace(now)
+		if !ok {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgTraceNone", nil, nil))
+		}
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgTraceRunning", map[string]interface{}{
+			"Types": strings.Join(types, ", "),
+			"Left":  format.Duration(localizer, until.Sub(now).Round(time.Second)),
+		}, nil))
+	case strings.EqualFold(value, "off"):
+		h.debug.StopTrace()
+		log.Printf("[Cmd:trace User:%d] Trace stopped", userID)
+		h.RecordUserActivity(ctx, message.From, ActionCommandTrace, true, map[string]interface{}{
+			"chat_id": chatID,
+			"types":   "off",
+		})
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgTraceStopped", nil, nil))
+	}
+
+	var types []string
+	for _, updateType := range strings.FieldsFunc(strings.ToLower(value), func(r rune) bool { return r == ',' || r == ' ' || r == '\n' }) {
+		if !slices.Contains(debugmode.UpdateTypes, updateType) {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgTraceUnknownType", map[string]interface{}{
+				"Type":  updateType,
+				"Types": strings.Join(debugmode.UpdateTypes, ", "),
+			}, nil))
+		}
+		if !slices.Contains(types, updateType) {
+			types = append(types, updateType)
+		}
+	}
+
+	duration := defaultTraceDuration
+	if raw, ok := args.Option("for"); ok {
+		duration, err = time.ParseDuration(raw)
+		if err != nil || duration <= 0 || duration > maxTraceDuration {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgTraceInvalidDuration", map[string]interface{}{
+				"Max": format.Duration(localizer, maxTraceDuration),
+			}, nil))
+		}
+	}
+
+	traceChatID := h.traceChatID
+	if traceChatID == 0 {
+		traceChatID = chatID
+	}
+	h.debug.StartTrace(traceChatID, types, now.Add(duration))
+	log.Printf("[Cmd:trace User:%d] Tracing %v to chat %d for %s", userID, types, traceChatID, duration)
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandTrace, true, map[string]interface{}{
+		"chat_id":  chatID,
+		"types":    strings.Join(types, ","),
+		"duration": duration.String(),
+	})
+	return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgTraceStarted", map[string]interface{}{
+		"Types":    strings.Join(types, ", "),
+		"Duration": format.Duration(localizer, duration),
+	}, nil))
+}
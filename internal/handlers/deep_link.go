@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+	"vrcmemes-bot/pkg/utils"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// startArgs declares the arguments of /start: the start parameter of a deep link.
+var startArgs = cmdargs.Spec{
+	Command: "start",
+	Args:    []cmdargs.Arg{{Name: "payload"}},
+}
+
+// startPayloadKind is where a deep link takes the user.
+type startPayloadKind string
+
+const (
+	startPayloadNone     startPayloadKind = ""         // Plain /start
+	startPayloadSuggest  startPayloadKind = "suggest"  // "suggest" or "suggest_<source>": starts /suggest
+	startPayloadFeedback startPayloadKind = "feedback" // "feedback": starts /feedback
+	startPayloadPost     startPayloadKind = "post"     // "post_<message ID>": shows a channel post
+	startPayloadInvalid  startPayloadKind = "invalid"  // Anything else; the user is greeted as usual
+)
+
+// startPayloadPattern matches the start parameters Telegram accepts in deep links.
+var startPayloadPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// startPayload is the parsed start parameter of a deep link.
+type startPayload struct {
+	Kind   startPayloadKind
+	Raw    string // As received, up to 64 characters, for the log
+	Source string // Source of a suggest link, if any
+	PostID int    // Channel post of a post link
+}
+
+// parseStartPayload parses the start parameter of a /start command. Payloads that don't match
+// a known link, or that Telegram wouldn't send, are invalid.
+func parseStartPayload(text string) startPayload {
+	args, err := startArgs.Parse(text)
+	if err != nil {
+		return startPayload{Kind: startPayloadInvalid}
+	}
+	raw := args.Arg("payload")
+	payload := startPayload{Kind: startPayloadInvalid, Raw: raw}
+	if runes := []rune(raw); len(runes) > 64 {
+		payload.Raw = string(runes[:64]) // Typed by hand; only the start is logged
+	}
+	if raw == "" {
+		payload.Kind = startPayloadNone
+		return payload
+	}
+	if !startPayloadPattern.MatchString(raw) {
+		return payload
+	}
+	if source, ok := parseSuggestStartPayload(raw); ok {
+		payload.Kind, payload.Source = startPayloadSuggest, source
+		return payload
+	}
+	lower := strings.ToLower(raw)
+	if lower == string(startPayloadFeedback) {
+		payload.Kind = startPayloadFeedback
+		return payload
+	}
+	if id, ok := strings.CutPrefix(lower, string(startPayloadPost)+"_"); ok {
+		if postID, err := strconv.Atoi(id); err == nil && postID > 0 {
+			payload.Kind, payload.PostID = startPayloadPost, postID
+		}
+	}
+	return payload
+}
+
+// details returns the payload's fields logged with the /start action. The "source" field of
+// suggest links is counted by /stats links.
+func (p startPayload) details() map[string]interface{} {
+	details := map[string]interface{}{}
+	if p.Kind == startPayloadNone {
+		return details
+	}
+	details["payload"] = string(p.Kind)
+	switch p.Kind {
+	case startPayloadSuggest:
+		if p.Source != "" {
+			details["source"] = p.Source
+		}
+	case startPayloadPost:
+		details["post_id"] = p.PostID
+	case startPayloadInvalid:
+		details["raw_payload"] = p.Raw
+	}
+	return details
+}
+
+// followStartPayload takes the user where a deep link points. It reports false for plain /start
+// and invalid payloads, which are greeted as usual.
+func (h *MessageHandler) followStartPayload(ctx context.Context, bot telegoapi.BotAPI, message telego.Message, localizer *i18n.Localizer, payload startPayload) (bool, error) {
+	userID := message.From.ID
+	switch payload.Kind {
+	case startPayloadSuggest:
+		if h.suggestionManager == nil {
+			return false, nil
+		}
+		log.Printf("[Cmd:start User:%d] Opened the suggest link from %q", userID, payload.Source)
+		if payload.Source != "" {
+			h.suggestionManager.RememberSuggestionSource(ctx, userID, payload.Source)
+		}
+		return true, h.HandleSuggest(ctx, bot, message)
+	case startPayloadFeedback:
+		if h.suggestionManager == nil {
+			return false, nil
+		}
+		log.Printf("[Cmd:start User:%d] Opened the feedback link", userID)
+		return true, h.HandleFeedback(ctx, bot, message)
+	case startPayloadPost:
+		log.Printf("[Cmd:start User:%d] Opened the link of post %d", userID, payload.PostID)
+		return true, h.showPostFromLink(ctx, bot, message.Chat.ID, localizer, payload.PostID)
+	case startPayloadInvalid:
+		log.Printf("[Cmd:start User:%d] Ignoring invalid start payload %q", userID, payload.Raw)
+	}
+	return false, nil
+}
+
+// showPostFromLink sends the user a copy of a channel post with when it was published and how
+// many reactions it got. Only posts the bot logged are shown.
+func (h *MessageHandler) showPostFromLink(ctx context.Context, bot telegoapi.BotAPI, chatID int64, localizer *i18n.Localizer, postID int) error {
+	if h.statsRepo == nil {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgStartPostNotFound", nil, nil))
+	}
+	post, err := h.statsRepo.GetPost(ctx, h.channelID, postID)
+	if errors.Is(err, database.ErrPostNotFound) {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgStartPostNotFound", nil, nil))
+	}
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, err)
+	}
+
+	if _, err := bot.CopyMessage(ctx, &telego.CopyMessageParams{
+		ChatID:     tu.ID(chatID),
+		FromChatID: tu.ID(h.channelID),
+		MessageID:  postID,
+	}); err != nil {
+		// The post may have been deleted; its context is still worth showing
+		log.Printf("[Cmd:start Chat:%d] Error copying post %d: %v", chatID, postID, err)
+	}
+	return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgStartPostContext", map[string]interface{}{
+		"Date":      utils.FormatTime(post.PublishedAt, h.location),
+		"Reactions": post.ReactionCount,
+	}, nil))
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/outbox"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+	"vrcmemes-bot/pkg/utils"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// diagCheckTimeout bounds each /diag check, so a hanging dependency doesn't block the report.
+const diagCheckTimeout = 5 * time.Second
+
+// SetDatabasePinger sets the database connection checked by /diag.
+func (h *MessageHandler) SetDatabasePinger(pinger database.Pinger) {
+	h.dbPinger = pinger
+}
+
+// SetOutbox sets the notification outbox whose backlog /diag reports.
+func (h *MessageHandler) SetOutbox(o *outbox.Outbox) {
+	h.outbox = o
+}
+
+// HandleDiag handles the /diag command (super admins only).
+// It checks the database, the Telegram API, the bot's rights in the channel and the webhook
+// state, and reports them together with the publish queue size, the notification outbox
+// backlog and the embedded build info.
+func (h *MessageHandler) HandleDiag(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	if !h.adminChecker.IsSuperAdmin(userID) {
+		log.Printf("[Cmd:diag User:%d] Non-super-admin user attempted to use /diag.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresSuperAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+
+	lines := []string{locales.GetMessage(localizer, "MsgDiagHeader", nil, nil)}
+	lines = append(lines, h.diagDatabase(ctx, localizer))
+	me, line := h.diagTelegram(ctx, bot, localizer)
+	lines = append(lines, line)
+	if me != nil {
+		lines = append(lines, h.diagChannel(ctx, bot, localizer, me.ID))
+	}
+	lines = append(lines, h.diagWebhook(ctx, bot, localizer)...)
+	lines = append(lines, locales.GetMessage(localizer, "MsgDiagQueue", map[string]interface{}{"Pending": h.publishQueue.Pending()}, nil))
+	if h.outbox != nil {
+		lines = append(lines, h.diagOutbox(ctx, localizer))
+	}
+	lines = append(lines, locales.GetMessage(localizer, "MsgDiagVersion", map[string]interface{}{"Build": h.build.String()}, nil))
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandDiag, true, map[string]interface{}{
+		"chat_id": chatID,
+	})
+	return h.sendSuccess(ctx, bot, chatID, strings.Join(lines, "\n"))
+}
+
+// diagDatabase pings the database and reports the round trip time.
+func (h *MessageHandler) diagDatabase(ctx context.Context, localizer *i18n.Localizer) string {
+	if h.dbPinger == nil {
+		return locales.GetMessage(localizer, "MsgDiagDatabaseUnknown", nil, nil)
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, diagCheckTimeout)
+	defer cancel()
+	started := time.Now()
+	if err := h.dbPinger.Ping(checkCtx); err != nil {
+		return locales.GetMessage(localizer, "MsgDiagDatabaseFailed", map[string]interface{}{"Error": err.Error()}, nil)
+	}
+	return locales.GetMessage(localizer, "MsgDiagDatabaseOK", map[string]interface{}{
+		"Latency": time.Since(started).Round(time.Millisecond).String(),
+	}, nil)
+}
+
+// diagOutbox reports how many notifications wait for delivery and how many were given up on.
+func (h *MessageHandler) diagOutbox(ctx context.Context, localizer *i18n.Localizer) string {
+	checkCtx, cancel := context.WithTimeout(ctx, diagCheckTimeout)
+	defer cancel()
+	pending, failed, err := h.outbox.Counts(checkCtx)
+	if err != nil {
+		return locales.GetMessage(localizer, "MsgDiagOutboxFailed", map[string]interface{}{"Error": err.Error()}, nil)
+	}
+	return locales.GetMessage(localizer, "MsgDiagOutbox", map[string]interface{}{"Pending": pending, "Failed": failed}, nil)
+}
+
+// diagTelegram calls GetMe and reports the bot account and the round trip time.
+// It returns the bot user, or nil if the call failed.
+func (h *MessageHandler) diagTelegram(ctx context.Context, bot telegoapi.BotAPI, localizer *i18n.Localizer) (*telego.User, string) {
+	checkCtx, cancel := context.WithTimeout(ctx, diagCheckTimeout)
+	defer cancel()
+	started := time.Now()
+	me, err := bot.GetMe(checkCtx)
+	if err != nil {
+		return nil, locales.GetMessage(localizer, "MsgDiagTelegramFailed", map[string]interface{}{"Error": err.Error()}, nil)
+	}
+	return me, locales.GetMessage(localizer, "MsgDiagTelegramOK", map[string]interface{}{
+		"Username": me.Username,
+		"ID":       me.ID,
+		"Latency":  time.Since(started).Round(time.Millisecond).String(),
+	}, nil)
+}
+
+// diagChannel reports whether the bot can post to the target channel.
+func (h *MessageHandler) diagChannel(ctx context.Context, bot telegoapi.BotAPI, localizer *i18n.Localizer, botID int64) string {
+	checkCtx, cancel := context.WithTimeout(ctx, diagCheckTimeout)
+	defer cancel()
+	member, err := bot.GetChatMember(checkCtx, &telego.GetChatMemberParams{
+		ChatID: tu.ID(h.channelID),
+		UserID: botID,
+	})
+	if err != nil {
+		return locales.GetMessage(localizer, "MsgDiagChannelFailed", map[string]interface{}{"Error": err.Error()}, nil)
+	}
+
+	canPost := false
+	switch m := member.(type) {
+	case *telego.ChatMemberOwner:
+		canPost = true
+	case *telego.ChatMemberAdministrator:
+		canPost = m.CanPostMessages
+	}
+	data := map[string]interface{}{"ChannelID": h.channelID, "Status": member.MemberStatus()}
+	if !canPost {
+		return locales.GetMessage(localizer, "MsgDiagChannelCannotPost", data, nil)
+	}
+	return locales.GetMessage(localizer, "MsgDiagChannelOK", data, nil)
+}
+
+// diagWebhook reports the webhook state. The bot uses long polling, so a set webhook is a problem:
+// Telegram refuses getUpdates while one is set.
+func (h *MessageHandler) diagWebhook(ctx context.Context, bot telegoapi.BotAPI, localizer *i18n.Localizer) []string {
+	checkCtx, cancel := context.WithTimeout(ctx, diagCheckTimeout)
+	defer cancel()
+	info, err := bot.GetWebhookInfo(checkCtx)
+	if err != nil {
+		return []string{locales.GetMessage(localizer, "MsgDiagWebhookFailed", map[string]interface{}{"Error": err.Error()}, nil)}
+	}
+
+	data := map[string]interface{}{"URL": info.URL, "Pending": info.PendingUpdateCount}
+	var lines []string
+	if info.URL == "" {
+		lines = append(lines, locales.GetMessage(localizer, "MsgDiagWebhookNone", data, nil))
+	} else {
+		lines = append(lines, locales.GetMessage(localizer, "MsgDiagWebhookSet", data, nil))
+	}
+	if info.LastErrorMessage != "" {
+		lines = append(lines, locales.GetMessage(localizer, "MsgDiagWebhookLastError", map[string]interface{}{
+			"Date":  utils.FormatTime(time.Unix(info.LastErrorDate, 0), h.location),
+			"Error": info.LastErrorMessage,
+		}, nil))
+	}
+	return lines
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/suggestions"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+	"vrcmemes-bot/pkg/utils"
+
+	"github.com/mymmrac/telego"
+)
+
+const (
+	// maxListedFeedback is how many feedback entries /feedbacks shows.
+	maxListedFeedback = 20
+	// maxListedFeedbackText is how much of each feedback text /feedbacks shows.
+	maxListedFeedbackText = 80
+)
+
+// feedbackFilters maps the /feedbacks argument to the triage statuses it lists.
+var feedbackFilters = map[string][]string{
+	"all":         nil,
+	"open":        {models.FeedbackStatusNew, models.FeedbackStatusInProgress},
+	"new":         {models.FeedbackStatusNew},
+	"in_progress": {models.FeedbackStatusInProgress},
+	"resolved":    {models.FeedbackStatusResolved},
+}
+
+// feedbacksArgs declares the arguments of /feedbacks.
+var feedbacksArgs = cmdargs.Spec{
+	Command: "feedbacks",
+	Args: []cmdargs.Arg{
+		{Name: "filter", Choices: []string{"all", "open", "new", "in_progress", "resolved"}},
+	},
+}
+
+// HandleFeedbacks handles the /feedbacks [all|open|new|in_progress|resolved] command (admin only).
+// It lists the most recent feedback, optionally filtered by triage status; without an argument all feedback is listed.
+func (h *MessageHandler) HandleFeedbacks(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:feedbacks User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:feedbacks User:%d] Non-admin user attempted to use /feedbacks.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+
+	args, err := feedbacksArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	filter := args.Arg("filter")
+	if filter == "" {
+		filter = "all"
+	}
+	statuses := feedbackFilters[filter]
+
+	feedback, err := h.feedbackRepo.ListFeedback(ctx, statuses, maxListedFeedback)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to list feedback: %w", err))
+	}
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandFeedbacks, isAdmin, map[string]interface{}{
+		"chat_id": chatID,
+		"filter":  filter,
+	})
+
+	if len(feedback) == 0 {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgFeedbacksEmpty", nil, nil))
+	}
+
+	var text strings.Builder
+	text.WriteString(locales.GetMessage(localizer, "MsgFeedbacksHeader", map[string]interface{}{
+		"Count":  len(feedback),
+		"Filter": filter,
+	}, nil))
+	for _, f := range feedback {
+		sender := f.FirstName
+		if f.Username != "" {
+			sender = "@" + f.Username
+		}
+		body := f.Text
+		if len([]rune(body)) > maxListedFeedbackText {
+			body = string([]rune(body)[:maxListedFeedbackText]) + "…"
+		}
+		text.WriteString("\n\n")
+		text.WriteString(locales.GetMessage(localizer, "MsgFeedbacksItem", map[string]interface{}{
+			"Date":     utils.FormatTime(f.SubmittedAt, h.location),
+			"Category": suggestions.FeedbackCategoryLabel(localizer, f.Category),
+			"Status":   suggestions.FeedbackStatusLabel(localizer, f.CurrentStatus()),
+			"User":     sender,
+			"UserID":   f.UserID,
+			"Text":     body,
+		}, nil))
+	}
+	return h.sendSuccess(ctx, bot, chatID, text.String())
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+)
+
+// findArgs declares the arguments of /find.
+var findArgs = cmdargs.Spec{
+	Command: "find",
+	Args: []cmdargs.Arg{
+		{Name: "code|text", Required: true, Rest: true},
+	},
+}
+
+// HandleFind handles the /find <code|text> command (admin only).
+// It shows the suggestion with the given reference code, whoever sent it, or lists the
+// suggestions whose caption or recognized text contains the text.
+func (h *MessageHandler) HandleFind(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:find User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:find User:%d] Non-admin user attempted to use /find.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+
+	args, err := findArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	code := args.Arg("code|text")
+	update := telego.Update{Message: &message}
+	if err := h.suggestionManager.HandleFindCommand(ctx, update, code); err != nil {
+		// The manager sends user-facing errors itself
+		log.Printf("[Cmd:find User:%d] Error from suggestionManager.HandleFindCommand: %v", userID, err)
+	}
+	h.RecordUserActivity(ctx, message.From, ActionCommandFind, isAdmin, map[string]interface{}{
+		"chat_id": chatID,
+		"code":    code,
+	})
+	return nil
+}
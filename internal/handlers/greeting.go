@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+	"vrcmemes-bot/pkg/utils"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// setGreetingArgs declares the arguments of /setgreeting. It is only used for the usage line:
+// the greeting is taken from the message as typed, together with its formatting.
+var setGreetingArgs = cmdargs.Spec{
+	Command: "setgreeting",
+	Args: []cmdargs.Arg{
+		{Name: "text|reset", Rest: true},
+	},
+}
+
+// Greeting placeholders, replaced when the greeting is sent.
+const (
+	placeholderFirstName   = "{first_name}"
+	placeholderChannelLink = "{channel_link}"
+	placeholderRulesLink   = "{rules_link}"
+)
+
+// SetSettingsRepository sets where runtime settings such as the custom greeting are stored.
+// Without it, /start always uses the default greeting.
+func (h *MessageHandler) SetSettingsRepository(repo database.SettingsRepository) {
+	h.settingsRepo = repo
+}
+
+// SetGreetingLinks sets the values of the {channel_link} and {rules_link} greeting placeholders.
+// The rules link is also shown by /rules.
+func (h *MessageHandler) SetGreetingLinks(channelURL, rulesURL string) {
+	h.channelURL = channelURL
+	h.rulesURL = rulesURL
+}
+
+// HandleSetGreeting handles the /setgreeting [text|reset] command (admin only).
+// With text, the formatted text becomes the /start message after a preview is sent successfully.
+// "reset" restores the default greeting, and without arguments the current greeting is shown.
+func (h *MessageHandler) HandleSetGreeting(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:setgreeting User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:setgreeting User:%d] Non-admin user attempted to use /setgreeting.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	if h.settingsRepo == nil {
+		return h.sendError(ctx, bot, chatID, errors.New("settings repository is not configured"))
+	}
+
+	text, entities := commandArgsText(message)
+	placeholders := map[string]interface{}{
+		"FirstName":   placeholderFirstName,
+		"ChannelLink": placeholderChannelLink,
+		"RulesLink":   placeholderRulesLink,
+	}
+
+	switch {
+	case text == "":
+		setting, err := h.settingsRepo.GetSetting(ctx, models.SettingGreeting)
+		if err != nil {
+			return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to get greeting: %w", err))
+		}
+		if setting == nil {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgGreetingDefault", placeholders, nil))
+		}
+		if err := h.sendGreeting(ctx, bot, chatID, setting.Value, message.From); err != nil {
+			log.Printf("[Cmd:setgreeting User:%d] Error sending greeting preview: %v", userID, err)
+		}
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgGreetingCurrent", placeholders, nil))
+
+	case strings.EqualFold(text, "reset"):
+		if err := h.settingsRepo.DeleteSetting(ctx, models.SettingGreeting); err != nil {
+			return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to reset greeting: %w", err))
+		}
+		log.Printf("[Cmd:setgreeting User:%d] Greeting reset to the default.", userID)
+		h.RecordUserActivity(ctx, message.From, ActionCommandSetGreeting, isAdmin, map[string]interface{}{
+			"chat_id": chatID,
+			"reset":   true,
+		})
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgGreetingReset", nil, nil))
+	}
+
+	// Send the preview first, so a greeting Telegram can't display is never stored
+	greeting := utils.EntitiesToHTML(text, entities)
+	if err := h.sendGreeting(ctx, bot, chatID, greeting, message.From); err != nil {
+		log.Printf("[Cmd:setgreeting User:%d] Error sending greeting preview: %v", userID, err)
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgGreetingInvalid", map[string]interface{}{
+			"Error": err.Error(),
+		}, nil))
+	}
+	if err := h.settingsRepo.SetSetting(ctx, models.SettingGreeting, greeting, userID); err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to save greeting: %w", err))
+	}
+	log.Printf("[Cmd:setgreeting User:%d] Greeting updated.", userID)
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandSetGreeting, isAdmin, map[string]interface{}{
+		"chat_id": chatID,
+		"length":  len(text),
+	})
+	return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgGreetingSaved", nil, nil))
+}
+
+// sendStartGreeting sends the custom greeting if one is set, and the localized default otherwise.
+func (h *MessageHandler) sendStartGreeting(ctx context.Context, bot telegoapi.BotAPI, localizer *i18n.Localizer, chatID int64, user *telego.User) error {
+	if h.settingsRepo != nil {
+		setting, err := h.settingsRepo.GetSetting(ctx, models.SettingGreeting)
+		if err != nil {
+			log.Printf("[Cmd:start User:%d] Error getting custom greeting, using the default: %v", user.ID, err)
+		} else if setting != nil {
+			return h.sendGreeting(ctx, bot, chatID, setting.Value, user)
+		}
+	}
+
+	// Escape text for MarkdownV2 and send the default welcome message
+	startMsg := locales.GetMessage(localizer, "MsgStart", nil, nil)
+	_, err := bot.SendMessage(ctx, &telego.SendMessageParams{
+		ChatID:    tu.ID(chatID),
+		Text:      utils.EscapeMarkdownV2(startMsg),
+		ParseMode: telego.ModeMarkdownV2,
+	})
+	return err
+}
+
+// sendGreeting fills in the placeholders of a custom greeting for the user and sends it.
+func (h *MessageHandler) sendGreeting(ctx context.Context, bot telegoapi.BotAPI, chatID int64, greeting string, user *telego.User) error {
+	replacer := strings.NewReplacer(
+		placeholderFirstName, utils.EscapeHTML(user.FirstName),
+		placeholderChannelLink, utils.EscapeHTML(h.channelURL),
+		placeholderRulesLink, utils.EscapeHTML(h.rulesURL),
+	)
+	_, err := bot.SendMessage(ctx, &telego.SendMessageParams{
+		ChatID:    tu.ID(chatID),
+		Text:      replacer.Replace(greeting),
+		ParseMode: telego.ModeHTML,
+	})
+	return err
+}
+
+// commandArgsText returns the text following the command of a message as typed, with the
+// message entities that format it. Entity offsets are made relative to the returned text.
+func commandArgsText(message telego.Message) (string, []telego.MessageEntity) {
+	full := message.Text
+	start := strings.IndexFunc(full, unicode.IsSpace)
+	if start < 0 {
+		return "", nil
+	}
+	rest := strings.TrimLeftFunc(full[start:], unicode.IsSpace)
+	start = len(full) - len(rest)
+	text := strings.TrimRightFunc(rest, unicode.IsSpace)
+
+	offset := len(utf16.Encode([]rune(full[:start])))
+	length := len(utf16.Encode([]rune(text)))
+	var entities []telego.MessageEntity
+	for _, entity := range message.Entities {
+		entity.Offset -= offset
+		if entity.Offset < 0 || entity.Offset >= length {
+			continue
+		}
+		entity.Length = min(entity.Length, length-entity.Offset)
+		entities = append(entities, entity)
+	}
+	return text, entities
+}
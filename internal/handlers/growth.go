@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"vrcmemes-bot/internal/format"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+)
+
+// HandleGrowth handles the /growth command (admin only).
+// It reports channel joins and leaves over the last day and week,
+// based on membership events recorded from chat_member updates.
+func (h *MessageHandler) HandleGrowth(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:growth User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:growth User:%d] Non-admin user attempted to use /growth.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+
+	now := time.Now()
+	dayJoins, dayLeaves, err := h.membershipRepo.GetMembershipGrowth(ctx, h.channelID, now.Add(-24*time.Hour))
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to get daily growth: %w", err))
+	}
+	weekJoins, weekLeaves, err := h.membershipRepo.GetMembershipGrowth(ctx, h.channelID, now.Add(-7*24*time.Hour))
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to get weekly growth: %w", err))
+	}
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandGrowth, isAdmin, map[string]interface{}{
+		"chat_id": chatID,
+	})
+
+	msg := locales.GetMessage(localizer, "MsgGrowthReport", map[string]interface{}{
+		"DayJoins":   format.Number(localizer, dayJoins),
+		"DayLeaves":  format.Number(localizer, dayLeaves),
+		"DayNet":     format.Number(localizer, dayJoins-dayLeaves),
+		"WeekJoins":  format.Number(localizer, weekJoins),
+		"WeekLeaves": format.Number(localizer, weekLeaves),
+		"WeekNet":    format.Number(localizer, weekJoins-weekLeaves),
+	}, nil)
+	return h.sendSuccess(ctx, bot, chatID, msg)
+}
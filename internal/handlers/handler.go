@@ -4,9 +4,20 @@ import (
 	"context"
 	"log"
 	"sync"
+	"time"
 	"vrcmemes-bot/internal/auth" // Import auth for AdminCheckerInterface
+	"vrcmemes-bot/internal/buildinfo"
+	"vrcmemes-bot/internal/channelrights"
+	"vrcmemes-bot/internal/cmdargs"
 	"vrcmemes-bot/internal/database"
 	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/debugmode"
+	"vrcmemes-bot/internal/engagement"
+	"vrcmemes-bot/internal/outbox"
+	"vrcmemes-bot/internal/publisher"
+	"vrcmemes-bot/internal/simulation"
+	"vrcmemes-bot/internal/suggestions"
+	"vrcmemes-bot/internal/videopolicy"
 	telegoapi "vrcmemes-bot/pkg/telegoapi" // Import telegoapi for BotAPI
 
 	"github.com/mymmrac/telego"
@@ -14,10 +25,40 @@ import (
 )
 
 // Command represents a bot command, mapping the command string to its description and handler function.
+// The remaining fields describe the command for /help and /help <command>.
 type Command struct {
 	Command     string                                                        // The command string (e.g., "start").
 	Description string                                                        // A short description of the command for /help.
 	Handler     func(context.Context, telegoapi.BotAPI, telego.Message) error // Use telegoapi.BotAPI
+	Role        CommandRole                                                   // Who the command is meant for.
+	Args        *cmdargs.Spec                                                 // Declared arguments, shown as the usage line; nil if the command takes none.
+	Help        string                                                        // Locale key of the detailed description; empty to show only Description.
+	Examples    []string                                                      // Example invocations.
+	PrivateOnly bool                                                          // Only runs in private chats; in groups the user is sent to the bot instead.
+}
+
+// CommandRole says who a command is meant for. /help only lists a command to users in its role.
+type CommandRole int
+
+const (
+	RoleEveryone   CommandRole = iota // Admins and regular users
+	RoleUser                          // Regular users only; admins publish directly instead
+	RoleAdmin                         // Channel admins only
+	RoleSuperAdmin                    // Super admins from the config only; never listed
+)
+
+// availableTo reports whether a command with this role is listed for a user with the given admin status.
+func (r CommandRole) availableTo(isAdmin bool) bool {
+	switch r {
+	case RoleUser:
+		return !isAdmin
+	case RoleAdmin:
+		return isAdmin
+	case RoleSuperAdmin:
+		return false
+	default:
+		return true
+	}
 }
 
 // MessageHandler handles incoming Telegram messages and callbacks.
@@ -32,22 +73,84 @@ type MessageHandler struct {
 	// activeCaptions stores the currently active caption for each chat.
 	// Key: chatID (int64), Value: caption (string)
 	activeCaptions sync.Map
+	// activeCaptionEmoji stores the custom emoji of the active captions, see custom_emoji.go.
+	// Key: chatID (int64), Value: []models.CustomEmoji
+	activeCaptionEmoji sync.Map
 	// mediaGroupCaptions temporarily stores captions associated with a media group ID, usually set by a preceding text message.
 	// Key: mediaGroupID (string), Value: caption (string)
 	mediaGroupCaptions sync.Map
 
 	// commands holds the list of available bot commands.
 	commands []Command
+	// adminMenus holds the admins whose private chats have the admin command menu.
+	adminMenus   map[int64]struct{}
+	muAdminMenus sync.Mutex
 
-	version string // Added version field
+	build buildinfo.Info // Version, commit and build time shown by /version and /diag
 
 	// Dependencies for database interactions and suggestion management.
-	postLogger        database.PostLogger         // Interface for logging published posts.
-	actionLogger      database.UserActionLogger   // Interface for logging user actions.
-	userRepo          database.UserRepository     // Interface for updating user information.
-	suggestionManager SuggestionManagerInterface  // Use SuggestionManagerInterface
-	adminChecker      auth.AdminCheckerInterface  // Use auth.AdminCheckerInterface
-	feedbackRepo      database.FeedbackRepository // Interface for saving feedback
+	postLogger        database.PostLogger           // Interface for logging published posts.
+	actionLogger      database.UserActionLogger     // Interface for logging user actions.
+	userRepo          database.UserRepository       // Interface for updating user information.
+	suggestionManager SuggestionManagerInterface    // Use SuggestionManagerInterface
+	adminChecker      auth.AdminCheckerInterface    // Use auth.AdminCheckerInterface
+	feedbackRepo      database.FeedbackRepository   // Interface for saving feedback
+	membershipRepo    database.MembershipRepository // Interface for channel membership analytics
+	publishQueue      *publisher.Queue              // Paces all channel publications
+	maxPostFileSize   int64                         // Size limit for /posturl images
+	dbPinger          database.Pinger               // Checked by /diag; nil if not configured
+	outbox            *outbox.Outbox                // Notification backlog reported by /diag; nil if not configured
+	incidentRepo      database.IncidentRepository   // Incidents listed by /incidents
+	settingsRepo      database.SettingsRepository   // Custom greeting and caption styles; nil always uses the default greeting
+	channelURL        string                        // Value of the {channel_link} greeting placeholder
+	rulesURL          string                        // Linked by /rules and the {rules_link} greeting placeholder
+	statsRepo         database.StatsRepository      // Reports of /stats; nil if not configured
+	rubricRepo        database.RubricRepository     // Rubrics managed by /rubric; nil if not configured
+	engagement        *engagement.Prompter          // Reactions and comments of rubric posts; nil if not configured
+	throwbackRepo     database.ThrowbackRepository  // Archived posts and reposts of /throwback; nil if not configured
+	experimentRepo    database.ExperimentRepository // Caption experiments of /abtest; nil if not configured
+	captionTestWindow time.Duration                 // How long after publication /abtest posts are measured
+	pinRepo           database.PinRepository        // Posts pinned for a limited time with /pin; nil if not configured
+	permissionRepo    database.PermissionRepository // Admin commands granted with /perm; nil if not configured
+	wordFilterRepo    database.WordFilterRepository // Words screened in admin posts, managed by /wordfilter; nil if not configured
+	location          *time.Location                // Channel time zone for user-facing times and reports
+	channelRights     *channelrights.Checker        // Bot's rights in the channel, rechecked by /setup; nil looks them up directly
+	tenants           TenantDirectory               // Channels connected with /connect; nil if multi-tenant mode is off
+	textRecognizer    suggestions.TextRecognizer    // Reads the text of posted photos; nil disables OCR, see ocr.go
+	debug             *debugmode.Switch             // Flipped by /debug and /trace; nil if not configured
+	traceChatID       int64                         // Admin chat /trace mirrors updates to; 0 uses the chat of the command
+	simulator         *simulation.Simulator         // Injects the test user's updates for /simulate; nil if not configured
+	videoPolicy       videopolicy.Policy            // Duration and aspect ratios of posted videos, see video_policy.go
+	// defaultLanguageShared hides the language choice of /setup in channels connected with /connect
+	defaultLanguageShared bool
+	// Credit settings used until /setup changes them (CREDIT_SUGGESTERS and CREDIT_FORWARD_SOURCE)
+	creditSuggesters    bool
+	creditForwardSource bool
+	customEmoji         bool // Keep custom emoji in captions (CUSTOM_EMOJI), see custom_emoji.go
+	protectContent      bool // Protected content setting used until /setup changes it (PROTECT_CONTENT)
+	// contentPolicy is the accepted suggestion content used until /contenttypes changes it
+	// (SUGGESTION_CONTENT_TYPES)
+	contentPolicy suggestions.ContentPolicy
+	// autoRejectRules are the auto-reject rules used until /autoreject changes them (AUTO_REJECT_*)
+	autoRejectRules suggestions.AutoRejectRules
+	// activeRubrics stores the rubric admin posts sent in a chat are numbered in, set by /rubric use.
+	// Key: chatID (int64), Value: rubric name (string)
+	activeRubrics sync.Map
+	// activeStyles stores the caption style admin posts sent in a chat are decorated with, set by /style use.
+	// Key: chatID (int64), Value: style name (string)
+	activeStyles sync.Map
+	// pendingExperiments stores the caption test set by /abtest for the next post sent in a chat.
+	// Key: chatID (int64), Value: *models.CaptionExperiment
+	pendingExperiments sync.Map
+	// pendingVideoPosts stores the video outside the video policy an admin may still post anyway.
+	// Key: chatID (int64), Value: *telego.Message
+	pendingVideoPosts sync.Map
+}
+
+// SetLocation sets the channel time zone, in which user-facing times are shown and report
+// periods such as the days of /stats are counted. Defaults to UTC.
+func (h *MessageHandler) SetLocation(loc *time.Location) {
+	h.location = loc
 }
 
 // NewMessageHandler creates and initializes a new MessageHandler instance.
@@ -60,7 +163,9 @@ func NewMessageHandler(
 	suggestionManager SuggestionManagerInterface, // Use interface
 	adminChecker auth.AdminCheckerInterface, // Use auth.AdminCheckerInterface
 	feedbackRepo database.FeedbackRepository, // Accept FeedbackRepository
-	version string, // Added version parameter
+	membershipRepo database.MembershipRepository,
+	publishQueue *publisher.Queue,
+	build buildinfo.Info,
 ) *MessageHandler {
 	if adminChecker == nil {
 		// If AdminChecker is essential, consider logging a fatal error or returning an error
@@ -72,28 +177,122 @@ func NewMessageHandler(
 	if feedbackRepo == nil {
 		log.Fatal("MessageHandler: Feedback repository dependency is nil")
 	}
+	if membershipRepo == nil {
+		log.Fatal("MessageHandler: Membership repository dependency is nil")
+	}
+	if publishQueue == nil {
+		log.Fatal("MessageHandler: Publish queue dependency is nil")
+	}
 	h := &MessageHandler{
 		channelID:         channelID,
+		adminMenus:        make(map[int64]struct{}),
 		postLogger:        postLogger,
 		actionLogger:      actionLogger,
 		userRepo:          userRepo,
 		suggestionManager: suggestionManager,
 		adminChecker:      adminChecker,
 		feedbackRepo:      feedbackRepo,
-		version:           version, // Assign version
+		membershipRepo:    membershipRepo,
+		publishQueue:      publishQueue,
+		maxPostFileSize:   DefaultMaxPostFileSize,
+		location:          time.UTC,
+		build:             build,
 	}
 	// Initialize commands - Handler signatures already use telegoapi.BotAPI
 	h.commands = []Command{
-		{Command: "start", Description: "CmdStartDesc", Handler: h.HandleStart},
-		{Command: "help", Description: "CmdHelpDesc", Handler: h.HandleHelp},
-		{Command: "status", Description: "CmdStatusDesc", Handler: h.HandleStatus},
-		{Command: "version", Description: "CmdVersionDesc", Handler: h.HandleVersion},
-		{Command: "caption", Description: "CmdCaptionDesc", Handler: h.HandleCaption},
-		{Command: "showcaption", Description: "CmdShowCaptionDesc", Handler: h.HandleShowCaption},
-		{Command: "clearcaption", Description: "CmdClearCaptionDesc", Handler: h.HandleClearCaption},
-		{Command: "suggest", Description: "CmdSuggestDesc", Handler: h.HandleSuggest},
-		{Command: "review", Description: "CmdReviewDesc", Handler: h.HandleReview},
-		{Command: "feedback", Description: "CmdFeedbackDesc", Handler: h.HandleFeedback},
+		{Command: "start", Description: "CmdStartDesc", Handler: h.HandleStart, Role: RoleEveryone},
+		{Command: "help", Description: "CmdHelpDesc", Handler: h.HandleHelp, Role: RoleEveryone,
+			Args: &helpArgs, Help: "CmdHelpHelp", Examples: []string{"/help posturl"}},
+		{Command: "status", Description: "CmdStatusDesc", Handler: h.HandleStatus, Role: RoleEveryone,
+			Args: &statusArgs, Help: "CmdStatusHelp", Examples: []string{"/status S-4F7K"}},
+		{Command: "version", Description: "CmdVersionDesc", Handler: h.HandleVersion, Role: RoleAdmin},
+		{Command: "caption", Description: "CmdCaptionDesc", Handler: h.HandleCaption, Role: RoleAdmin, Help: "CmdCaptionHelp"},
+		{Command: "showcaption", Description: "CmdShowCaptionDesc", Handler: h.HandleShowCaption, Role: RoleAdmin},
+		{Command: "clearcaption", Description: "CmdClearCaptionDesc", Handler: h.HandleClearCaption, Role: RoleAdmin},
+		{Command: "rules", Description: "CmdRulesDesc", Handler: h.HandleRules, Role: RoleEveryone},
+		{Command: "suggest", Description: "CmdSuggestDesc", Handler: h.HandleSuggest, Role: RoleUser, Help: "CmdSuggestHelp", PrivateOnly: true},
+		{Command: "mysuggestions", Description: "CmdMySuggestionsDesc", Handler: h.HandleMySuggestions, Role: RoleEveryone, Help: "CmdMySuggestionsHelp"},
+		{Command: "review", Description: "CmdReviewDesc", Handler: h.HandleReview, Role: RoleAdmin,
+			Args: &reviewArgs, Help: "CmdReviewHelp", Examples: []string{"/review", "/review round_robin", "/review mine"}},
+		{Command: "growth", Description: "CmdGrowthDesc", Handler: h.HandleGrowth, Role: RoleAdmin},
+		{Command: "stats", Description: "CmdStatsDesc", Handler: h.HandleStats, Role: RoleAdmin,
+			Args: &statsArgs, Help: "CmdStatsHelp", Examples: []string{"/stats tags", "/stats tags 7", "/stats links", "/stats sources"}},
+		{Command: "suggestbutton", Description: "CmdSuggestButtonDesc", Handler: h.HandleSuggestButton, Role: RoleAdmin,
+			Args: &suggestButtonArgs, Help: "CmdSuggestButtonHelp", Examples: []string{"/suggestbutton --pin", "/suggestbutton group", "/suggestbutton post=123"}},
+		{Command: "adminstats", Description: "CmdAdminStatsDesc", Handler: h.HandleAdminStats, Role: RoleAdmin,
+			Args: &adminStatsArgs, Help: "CmdAdminStatsHelp", Examples: []string{"/adminstats", "/adminstats 7"}},
+		{Command: "find", Description: "CmdFindDesc", Handler: h.HandleFind, Role: RoleAdmin,
+			Args: &findArgs, Help: "CmdFindHelp", Examples: []string{"/find S-4F7K", "/find when the code compiles"}},
+		{Command: "reopen", Description: "CmdReopenDesc", Handler: h.HandleReopen, Role: RoleAdmin,
+			Args: &reopenArgs, Help: "CmdReopenHelp", Examples: []string{"/reopen S-4F7K"}},
+		{Command: "queue", Description: "CmdQueueDesc", Handler: h.HandleQueue, Role: RoleAdmin,
+			Args: &queueArgs, Help: "CmdQueueHelp", Examples: []string{"/queue export"}},
+		{Command: "apply", Description: "CmdApplyDesc", Handler: h.HandleApply, Role: RoleAdmin,
+			Args: &applyArgs, Help: "CmdApplyHelp", Examples: []string{"/apply S-4F7K:a S-9Q2M:r"}},
+		{Command: "user", Description: "CmdUserDesc", Handler: h.HandleUser, Role: RoleAdmin,
+			Args: &userArgs, Help: "CmdUserHelp", Examples: []string{"/user 123456789"}},
+		{Command: "rubric", Description: "CmdRubricDesc", Handler: h.HandleRubric, Role: RoleAdmin,
+			Args: &rubricArgs, Help: "CmdRubricHelp", Examples: []string{
+				"/rubric add meme_of_the_day #meme_of_the_day No.{number}",
+				"/rubric counter meme_of_the_day 41",
+				"/rubric use meme_of_the_day",
+			}},
+		{Command: "style", Description: "CmdStyleDesc", Handler: h.HandleStyle, Role: RoleAdmin,
+			Args: &styleArgs, Help: "CmdStyleHelp", Examples: []string{
+				"/style add fire 🔥 {caption} 🔥",
+				"/style use fire",
+				"/style delete fire",
+			}},
+		{Command: "abtest", Description: "CmdABTestDesc", Handler: h.HandleABTest, Role: RoleAdmin,
+			Args: &abTestArgs, Help: "CmdABTestHelp", Examples: []string{
+				"/abtest question: Who else does this? || plain: Friday mood",
+				"/abtest report",
+			}},
+		{Command: "bestof", Description: "CmdBestOfDesc", Handler: h.HandleBestOf, Role: RoleAdmin,
+			Args: &bestOfArgs, Help: "CmdBestOfHelp", Examples: []string{"/bestof week", "/bestof month 5"}},
+		{Command: "throwback", Description: "CmdThrowbackDesc", Handler: h.HandleThrowback, Role: RoleAdmin,
+			Args: &throwbackArgs, Help: "CmdThrowbackHelp", Examples: []string{"/throwback", "/throwback 12"}},
+		{Command: "pin", Description: "CmdPinDesc", Handler: h.HandlePin, Role: RoleAdmin,
+			Args: &pinArgs, Help: "CmdPinHelp", Examples: []string{"/pin 1234 24", "/pin 1240 12 --queue", "/pin list", "/pin cancel 1234"}},
+		{Command: "quiethours", Description: "CmdQuietHoursDesc", Handler: h.HandleQuietHours, Role: RoleAdmin,
+			Args: &quietHoursArgs, Help: "CmdQuietHoursHelp", Examples: []string{"/quiethours 23:00-08:00", "/quiethours off"}},
+		{Command: "setgreeting", Description: "CmdSetGreetingDesc", Handler: h.HandleSetGreeting, Role: RoleAdmin,
+			Args: &setGreetingArgs, Help: "CmdSetGreetingHelp", Examples: []string{
+				"/setgreeting Hi, {first_name}! Read the rules first: {rules_link}",
+				"/setgreeting reset",
+			}},
+		{Command: "refreshadmins", Description: "CmdRefreshAdminsDesc", Handler: h.HandleRefreshAdmins, Role: RoleAdmin},
+		{Command: "posturl", Description: "CmdPostURLDesc", Handler: h.HandlePostURL, Role: RoleAdmin,
+			Args: &postURLArgs, Help: "CmdPostURLHelp", Examples: []string{
+				"/posturl https://example.com/meme.jpg",
+				`/posturl --silent https://example.com/meme.jpg "Friday mood"`,
+			}},
+		{Command: "feedback", Description: "CmdFeedbackDesc", Handler: h.HandleFeedback, Role: RoleUser, Help: "CmdFeedbackHelp", PrivateOnly: true},
+		{Command: "feedbacks", Description: "CmdFeedbacksDesc", Handler: h.HandleFeedbacks, Role: RoleAdmin,
+			Args: &feedbacksArgs, Help: "CmdFeedbacksHelp", Examples: []string{"/feedbacks", "/feedbacks open"}},
+		{Command: "language", Description: "CmdLanguageDesc", Handler: h.HandleLanguage, Role: RoleEveryone,
+			Args: &languageArgs, Help: "CmdLanguageHelp", Examples: []string{"/language", "/language ru"}},
+		{Command: "notifications", Description: "CmdNotificationsDesc", Handler: h.HandleNotifications, Role: RoleEveryone,
+			Help: "CmdNotificationsHelp", PrivateOnly: true},
+		{Command: "diag", Description: "CmdDiagDesc", Handler: h.HandleDiag, Role: RoleSuperAdmin, Help: "CmdDiagHelp"},
+		{Command: "debug", Description: "CmdDebugDesc", Handler: h.HandleDebug, Role: RoleSuperAdmin,
+			Args: &debugArgs, Help: "CmdDebugHelp", Examples: []string{"/debug", "/debug on", "/debug off"}},
+		{Command: "trace", Description: "CmdTraceDesc", Handler: h.HandleTrace, Role: RoleSuperAdmin,
+			Args: &traceArgs, Help: "CmdTraceHelp", Examples: []string{"/trace message callback_query", "/trace for=30m chat_member", "/trace off"}},
+		{Command: "simulate", Description: "CmdSimulateDesc", Handler: h.HandleSimulate, Role: RoleSuperAdmin,
+			Args: &simulateArgs, Help: "CmdSimulateHelp", Examples: []string{"/simulate suggestion", "/simulate suggestion Friday mood"}},
+		{Command: "purgetest", Description: "CmdPurgeTestDesc", Handler: h.HandlePurgeTest, Role: RoleSuperAdmin, Help: "CmdPurgeTestHelp"},
+		{Command: "contenttypes", Description: "CmdContentTypesDesc", Handler: h.HandleContentTypes, Role: RoleSuperAdmin,
+			Args: &contentTypesArgs, Help: "CmdContentTypesHelp", Examples: []string{"/contenttypes", "/contenttypes photo video", "/contenttypes default"}},
+		{Command: "setup", Description: "CmdSetupDesc", Handler: h.HandleSetup, Role: RoleSuperAdmin, Help: "CmdSetupHelp"},
+		{Command: "incidents", Description: "CmdIncidentsDesc", Handler: h.HandleIncidents, Role: RoleSuperAdmin,
+			Args: &incidentsArgs, Help: "CmdIncidentsHelp", Examples: []string{"/incidents", "/incidents all", "/incidents 3FA94C1B"}},
+		{Command: "perm", Description: "CmdPermDesc", Handler: h.HandlePerm, Role: RoleSuperAdmin,
+			Args: &permArgs, Help: "CmdPermHelp", Examples: []string{"/perm grant @helper review", "/perm revoke 123456789 review", "/perm list"}},
+		{Command: "wordfilter", Description: "CmdWordFilterDesc", Handler: h.HandleWordFilter, Role: RoleAdmin,
+			Args: &wordFilterArgs, Help: "CmdWordFilterHelp", Examples: []string{"/wordfilter add censor dumb*", "/wordfilter remove dumb*", "/wordfilter hits 7"}},
+		{Command: "autoreject", Description: "CmdAutoRejectDesc", Handler: h.HandleAutoReject, Role: RoleAdmin,
+			Args: &autoRejectArgs, Help: "CmdAutoRejectHelp", Examples: []string{"/autoreject", "/autoreject media 5", "/autoreject caption (?i)crypto|casino", "/autoreject account off"}},
 		// TODO: Add other admin commands here if needed
 	}
 	return h
@@ -105,11 +304,16 @@ func (h *MessageHandler) GetChannelID() int64 {
 }
 
 // GetCommandHandler retrieves the handler function associated with a specific command string (e.g., "start").
+// Admin commands run as an admin for users granted them with /perm, see authorized, and
+// private-only commands sent in groups point the user to the bot, see privateOnly.
 // It returns nil if the command is not found.
 func (h *MessageHandler) GetCommandHandler(command string) func(context.Context, telegoapi.BotAPI, telego.Message) error { // Use telegoapi.BotAPI
 	for _, cmd := range h.commands {
 		if cmd.Command == command {
-			return cmd.Handler
+			if cmd.PrivateOnly {
+				return h.privateOnly(cmd.Command, h.authorized(cmd))
+			}
+			return h.authorized(cmd)
 		}
 	}
 	return nil
@@ -2,8 +2,12 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"vrcmemes-bot/internal/cmdargs"
 	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/publisher"
 	telegoapi "vrcmemes-bot/pkg/telegoapi" // Import for BotAPI
 
 	"github.com/mymmrac/telego"
@@ -29,7 +33,7 @@ func (h *MessageHandler) sendError(ctx context.Context, bot telegoapi.BotAPI, ch
 
 	// Attempt to send a generic, localized error message using the default language
 	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
-	errMsg := locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil)
+	errMsg := locales.GetMessage(localizer, telegoapi.UserMessageID(originalErr, "MsgErrorGeneral"), nil, nil)
 
 	_, sendErr := bot.SendMessage(ctx, tu.Message(tu.ID(chatID), errMsg))
 	if sendErr != nil {
@@ -40,6 +44,16 @@ func (h *MessageHandler) sendError(ctx context.Context, bot telegoapi.BotAPI, ch
 	return originalErr
 }
 
+// sendUsageError tells the user how to call a command whose arguments failed to parse.
+// Errors other than *cmdargs.UsageError are reported like sendError.
+func (h *MessageHandler) sendUsageError(ctx context.Context, bot telegoapi.BotAPI, chatID int64, localizer *i18n.Localizer, err error) error {
+	var usageErr *cmdargs.UsageError
+	if errors.As(err, &usageErr) {
+		return h.sendSuccess(ctx, bot, chatID, usageErr.Localize(localizer))
+	}
+	return h.sendError(ctx, bot, chatID, err)
+}
+
 // getLocalizer returns a localizer configured *only* for the default language
 // specified by the BOT_DEFAULT_LANGUAGE environment variable.
 // It ignores the user's Telegram client language setting.
@@ -59,7 +73,7 @@ func (h *MessageHandler) RecordUserActivity(ctx context.Context, user *telego.Us
 	}
 
 	// Update user info in the database
-	if err := h.userRepo.UpdateUser(ctx, user.ID, user.Username, user.FirstName, user.LastName, isAdmin, action); err != nil {
+	if err := h.userRepo.UpdateUser(ctx, *user, isAdmin, action); err != nil {
 		log.Printf("Error updating user %d (%s) in DB during action %s: %v", user.ID, user.Username, action, err)
 		// Continue to log the action even if DB update fails
 	}
@@ -95,6 +109,7 @@ func (h *MessageHandler) HandleCallbackQuery(ctx context.Context, bot telegoapi.
 
 	// Delegate to suggestion manager if available
 	if h.suggestionManager != nil {
+		ctx = h.GrantedCallbackContext(ctx, query)
 		processed, processingErr = h.suggestionManager.HandleCallbackQuery(ctx, query)
 		if processingErr != nil {
 			// Log the error from the suggestion manager
@@ -115,3 +130,33 @@ func (h *MessageHandler) HandleCallbackQuery(ctx context.Context, bot telegoapi.
 
 	return nil // Return nil if processed or handled gracefully
 }
+
+// SendPublishQueued tells an admin that their post is waiting in the publish queue.
+// Nothing is sent when the post is next in line; the result follows shortly via ReportPublishResult.
+func (h *MessageHandler) SendPublishQueued(ctx context.Context, bot telegoapi.BotAPI, localizer *i18n.Localizer, chatID int64, position int) error {
+	if position <= 1 {
+		return nil
+	}
+	msg := locales.GetPluralMessage(localizer, "MsgPostQueued", position-1, nil)
+	_, err := bot.SendMessage(ctx, tu.Message(tu.ID(chatID), msg))
+	return err
+}
+
+// ReportPublishResult tells an admin whether their background publication reached the channel.
+func (h *MessageHandler) ReportPublishResult(ctx context.Context, bot telegoapi.BotAPI, localizer *i18n.Localizer, chatID int64, publishErr error) {
+	msgID := "MsgPostSentToChannel"
+	if errors.Is(publishErr, publisher.ErrAlreadyPublished) {
+		msgID = "MsgPostAlreadyPublished"
+	} else if publishErr != nil {
+		msgID = telegoapi.UserMessageID(publishErr, "MsgErrorSendToChannel")
+	}
+	msg := locales.GetMessage(localizer, msgID, nil, nil)
+	if _, err := bot.SendMessage(ctx, tu.Message(tu.ID(chatID), msg)); err != nil {
+		log.Printf("Error sending publish result to chat %d: %v", chatID, err)
+	}
+}
+
+// messagePublishKey returns the idempotency key for publishing a single message to the channel.
+func messagePublishKey(message telego.Message) string {
+	return fmt.Sprintf("message:%d:%d", message.Chat.ID, message.MessageID)
+}
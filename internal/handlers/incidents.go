@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/tenants"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+	"vrcmemes-bot/pkg/utils"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// recentIncidentsLimit is how many incidents /incidents lists.
+	recentIncidentsLimit = 10
+	// maxListedIncidentSummary bounds the summary of an incident in the /incidents list.
+	maxListedIncidentSummary = 100
+	// maxIncidentDetailsLength bounds the details (e.g. a stack trace) /incidents <id> shows,
+	// so the reply fits one message.
+	maxIncidentDetailsLength = 3000
+	// incidentCallbackPrefix starts the callback data of the incident status buttons: incident:<id>:<status>.
+	incidentCallbackPrefix = "incident:"
+)
+
+// incidentsArgs declares the arguments of /incidents.
+var incidentsArgs = cmdargs.Spec{
+	Command: "incidents",
+	Args: []cmdargs.Arg{
+		{Name: "id|all"},
+	},
+}
+
+// incidentStatusEmoji marks the status of an incident in lists.
+var incidentStatusEmoji = map[string]string{
+	models.IncidentStatusOpen:         "🔴",
+	models.IncidentStatusAcknowledged: "🟡",
+	models.IncidentStatusResolved:     "🟢",
+}
+
+// incidentStatusButtons lists the status buttons under an incident: the locale message of
+// the button and the status it sets. The button of the current status is left out.
+var incidentStatusButtons = []struct {
+	MessageID string
+	Status    string
+}{
+	{"BtnIncidentAcknowledge", models.IncidentStatusAcknowledged},
+	{"BtnIncidentResolve", models.IncidentStatusResolved},
+	{"BtnIncidentReopen", models.IncidentStatusOpen},
+}
+
+// SetIncidentRepository sets where incidents such as recovered panics and failed publications
+// are recorded. Without it, /incidents is unavailable.
+func (h *MessageHandler) SetIncidentRepository(repo database.IncidentRepository) {
+	h.incidentRepo = repo
+}
+
+// HandleIncidents handles the /incidents [id|all] command (super admins only).
+// Without arguments it lists the latest incidents that are not resolved, and with "all" the
+// resolved ones too. With an incident ID, as quoted to a user after a panic, it shows the
+// details of the incident with buttons to acknowledge, resolve or reopen it.
+func (h *MessageHandler) HandleIncidents(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	if !h.adminChecker.IsSuperAdmin(userID) {
+		log.Printf("[Cmd:incidents User:%d] Non-super-admin user attempted to use /incidents.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresSuperAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	if h.incidentRepo == nil {
+		return h.sendError(ctx, bot, chatID, errors.New("incident repository is not configured"))
+	}
+	args, err := incidentsArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	arg := args.Arg("id|all")
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandIncidents, true, map[string]interface{}{
+		"chat_id": chatID,
+		"arg":     arg,
+	})
+
+	if arg != "" && !strings.EqualFold(arg, "all") {
+		id := strings.ToUpper(arg)
+		incident, err := h.incidentRepo.GetIncident(ctx, id)
+		if err != nil {
+			return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to get incident: %w", err))
+		}
+		if incident == nil {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgIncidentNotFound", map[string]interface{}{"ID": id}, nil))
+		}
+		_, err = bot.SendMessage(ctx, tu.Message(tu.ID(chatID), h.incidentDetails(localizer, incident)).
+			WithReplyMarkup(incidentKeyboard(localizer, incident)))
+		if err != nil {
+			return fmt.Errorf("failed to send incident %s: %w", incident.ID, err)
+		}
+		return nil
+	}
+
+	includeResolved := arg != ""
+	list, err := h.incidentRepo.RecentIncidents(ctx, recentIncidentsLimit, includeResolved)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to get recent incidents: %w", err))
+	}
+	if len(list) == 0 {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgIncidentsNone", nil, nil))
+	}
+	lines := []string{locales.GetMessage(localizer, "MsgIncidentsHeader", nil, nil)}
+	for _, incident := range list {
+		summary := incident.Summary
+		if len([]rune(summary)) > maxListedIncidentSummary {
+			summary = string([]rune(summary)[:maxListedIncidentSummary]) + "…"
+		}
+		lines = append(lines, locales.GetMessage(localizer, "MsgIncidentsItem", map[string]interface{}{
+			"Status":  incidentStatusEmoji[incident.Status],
+			"ID":      incident.ID,
+			"Time":    utils.FormatTime(incident.OccurredAt, h.location),
+			"Type":    incidentTypeName(localizer, incident.Type),
+			"Summary": summary,
+		}, nil))
+	}
+	return h.sendSuccess(ctx, bot, chatID, strings.Join(lines, "\n\n"))
+}
+
+// incidentTypeName returns the localized name of an incident type.
+func incidentTypeName(localizer *i18n.Localizer, incidentType string) string {
+	switch incidentType {
+	case models.IncidentTypePanic:
+		return locales.GetMessage(localizer, "MsgIncidentTypePanic", nil, nil)
+	case models.IncidentTypePublishFailure:
+		return locales.GetMessage(localizer, "MsgIncidentTypePublishFailure", nil, nil)
+	case models.IncidentTypeDatabaseOutage:
+		return locales.GetMessage(localizer, "MsgIncidentTypeDatabaseOutage", nil, nil)
+	case models.IncidentTypeWebhookReset:
+		return locales.GetMessage(localizer, "MsgIncidentTypeWebhookReset", nil, nil)
+	default:
+		return incidentType
+	}
+}
+
+// incidentDetails formats an incident for /incidents <id>.
+func (h *MessageHandler) incidentDetails(localizer *i18n.Localizer, incident *models.Incident) string {
+	lines := []string{locales.GetMessage(localizer, "MsgIncidentDetails", map[string]interface{}{
+		"ID":      incident.ID,
+		"Type":    incidentTypeName(localizer, incident.Type),
+		"Status":  incidentStatusEmoji[incident.Status] + " " + incident.Status,
+		"Time":    utils.FormatTime(incident.OccurredAt, h.location),
+		"Summary": incident.Summary,
+	}, nil)}
+	if incident.UpdateKind != "" {
+		lines = append(lines, locales.GetMessage(localizer, "MsgIncidentUpdate", map[string]interface{}{
+			"Kind":     incident.UpdateKind,
+			"UpdateID": incident.UpdateID,
+			"UserID":   incident.UserID,
+			"ChatID":   incident.ChatID,
+		}, nil))
+	}
+	if incident.SentryEventID != "" {
+		lines = append(lines, locales.GetMessage(localizer, "MsgIncidentSentryEvent", map[string]interface{}{"EventID": incident.SentryEventID}, nil))
+	}
+	if incident.StatusChangedBy != 0 {
+		lines = append(lines, locales.GetMessage(localizer, "MsgIncidentStatusChanged", map[string]interface{}{
+			"UserID": incident.StatusChangedBy,
+			"Time":   utils.FormatTime(incident.StatusChangedAt, h.location),
+		}, nil))
+	}
+	if incident.Details != "" {
+		details := incident.Details
+		if len(details) > maxIncidentDetailsLength {
+			details = details[:maxIncidentDetailsLength] + "…"
+		}
+		lines = append(lines, "", details)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// incidentKeyboard builds the status buttons of an incident.
+func incidentKeyboard(localizer *i18n.Localizer, incident *models.Incident) *telego.InlineKeyboardMarkup {
+	var row []telego.InlineKeyboardButton
+	for _, button := range incidentStatusButtons {
+		if button.Status == incident.Status {
+			continue
+		}
+		data := incidentCallbackPrefix + incident.ID + ":" + button.Status
+		row = append(row, tu.InlineKeyboardButton(locales.GetMessage(localizer, button.MessageID, nil, nil)).WithCallbackData(data))
+	}
+	return tu.InlineKeyboard(row)
+}
+
+// HandleCallback handles the callback queries of buttons sent by the handler's commands.
+// It reports whether the query was one of them.
+func (h *MessageHandler) HandleCallback(ctx context.Context, bot telegoapi.BotAPI, query telego.CallbackQuery) (bool, error) {
+	if strings.HasPrefix(query.Data, incidentCallbackPrefix) {
+		return true, h.handleIncidentCallback(ctx, bot, query)
+	}
+	if strings.HasPrefix(query.Data, setupCallbackPrefix) {
+		return true, h.handleSetupCallback(ctx, bot, query)
+	}
+	if strings.HasPrefix(query.Data, tenants.CallbackPrefix) {
+		return true, h.handleTenantCallback(ctx, bot, query)
+	}
+	if strings.HasPrefix(query.Data, notificationsCallbackPrefix) {
+		return true, h.handleNotificationsCallback(ctx, bot, query)
+	}
+	if strings.HasPrefix(query.Data, videoPostCallbackPrefix) {
+		return true, h.handleVideoPostCallback(ctx, bot, query)
+	}
+	return false, nil
+}
+
+// handleIncidentCallback sets the status of an incident from its status buttons and updates
+// the incident message.
+func (h *MessageHandler) handleIncidentCallback(ctx context.Context, bot telegoapi.BotAPI, query telego.CallbackQuery) error {
+	userID := query.From.ID
+	localizer := h.getLocalizer(&query.From)
+	answer := func(text string) {
+		_ = bot.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID, Text: text, ShowAlert: text != ""})
+	}
+
+	if !h.adminChecker.IsSuperAdmin(userID) {
+		log.Printf("[IncidentCallback User:%d] Non-super-admin user attempted to change an incident.", userID)
+		answer(locales.GetMessage(localizer, "MsgErrorRequiresSuperAdmin", nil, nil))
+		return nil
+	}
+	id, status, ok := strings.Cut(strings.TrimPrefix(query.Data, incidentCallbackPrefix), ":")
+	if _, known := incidentStatusEmoji[status]; !ok || !known || h.incidentRepo == nil || query.Message == nil {
+		answer(locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil))
+		return fmt.Errorf("malformed incident callback data %q", query.Data)
+	}
+
+	incident, err := h.incidentRepo.SetIncidentStatus(ctx, id, status, userID)
+	if err != nil {
+		answer(locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil))
+		return err
+	}
+	if incident == nil {
+		answer(locales.GetMessage(localizer, "MsgIncidentNotFound", map[string]interface{}{"ID": id}, nil))
+		return nil
+	}
+	log.Printf("[IncidentCallback User:%d] Incident %s is now %s", userID, id, status)
+	answer("")
+
+	_, err = bot.EditMessageText(ctx, &telego.EditMessageTextParams{
+		ChatID:      tu.ID(query.Message.GetChat().ID),
+		MessageID:   query.Message.GetMessageID(),
+		Text:        h.incidentDetails(localizer, incident),
+		ReplyMarkup: incidentKeyboard(localizer, incident),
+	})
+	if err != nil {
+		log.Printf("[IncidentCallback User:%d] Error updating incident message: %v", userID, err)
+	}
+	return nil
+}
@@ -18,11 +18,32 @@ type SuggestionManagerInterface interface {
 	GetUserState(userID int64) suggestions.UserState
 	SetUserState(userID int64, state suggestions.UserState) // Used internally? Check if needed here or only in mock. Let's include for now.
 	HandleSuggestCommand(ctx context.Context, update telego.Update) error
-	HandleReviewCommand(ctx context.Context, update telego.Update) error   // Assuming this method exists
+	HandleReviewCommand(ctx context.Context, update telego.Update, order suggestions.ReviewOrder) error
 	HandleFeedbackCommand(ctx context.Context, update telego.Update) error // Assuming this method exists
+	HandleMySuggestionsCommand(ctx context.Context, update telego.Update) error
+	HandleSuggestionStatusCommand(ctx context.Context, update telego.Update, code string) error // Status of a suggestion by its reference code
+	HandleFindCommand(ctx context.Context, update telego.Update, code string) error             // Shows admins any suggestion by its reference code
+	HandleQueueExportCommand(ctx context.Context, update telego.Update) error                   // Sends admins a snapshot of the pending suggestions
+	HandleApplyCommand(ctx context.Context, update telego.Update, decisions string) error       // Applies review decisions made offline
+	HandleReopenCommand(ctx context.Context, update telego.Update, code string) error           // Puts a rejected suggestion back in the review queue
 	HandleMessage(ctx context.Context, update telego.Update) (processed bool, err error)
 	HandleCallbackQuery(ctx context.Context, query telego.CallbackQuery) (processed bool, err error) // Renamed from ProcessSuggestionCallback for consistency
 	HandleCombinedMediaGroup(ctx context.Context, groupID string, messages []telego.Message) error   // Added based on usage in bot/bot.go
+	HandleChatMemberUpdate(ctx context.Context, update telego.ChatMemberUpdated) error               // Tracks channel joins/leaves
+	OfferDirectSuggestion(ctx context.Context, messages []telego.Message) (bool, error)              // Prompts to submit media sent without /suggest
+	ShowPrivacyNotice(ctx context.Context, user *telego.User, chatID int64) error                    // Asks users who haven't agreed yet to accept the privacy notice
+	GetQuietHours(ctx context.Context, adminID int64) (*suggestions.QuietHours, error)               // Quiet hours of an admin's notifications; nil if none
+	SetQuietHours(ctx context.Context, adminID int64, quiet *suggestions.QuietHours) error           // Sets or (with nil) clears an admin's quiet hours
+	IsIntakeChat(chatID int64) bool                                                                  // Whether photos posted in a chat become suggestions
+	SetCreditSuggesters(enabled bool)                                                                // Changed by /setup
+	SetCreditForwardSource(enabled bool)                                                             // Changed by /setup
+	SetProtectContent(enabled bool)                                                                  // Changed by /setup
+	SetContentPolicy(policy suggestions.ContentPolicy)                                               // Changed by /contenttypes
+	ContentPolicy() suggestions.ContentPolicy                                                        // Media types accepted in suggestions
+	SetAutoRejectRules(rules suggestions.AutoRejectRules)                                            // Changed by /autoreject
+	AutoRejectRules() suggestions.AutoRejectRules                                                    // Rules suggestions are rejected by on submission
+	RememberSuggestionSource(ctx context.Context, userID int64, source string)                       // Attributes the user's next suggestion to a deep-link source
+	PurgeTestSuggestions(ctx context.Context) (int64, error)                                         // Deletes the test suggestions of /simulate
 
 	// Add other methods like StartReviewSession etc. if called directly by MessageHandler
 }
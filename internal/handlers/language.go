@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+)
+
+// languageArgs declares the arguments of /language.
+var languageArgs = cmdargs.Spec{
+	Command: "language",
+	Args:    []cmdargs.Arg{{Name: "code"}},
+}
+
+// HandleLanguage handles the /language command.
+// Without arguments it shows the user's current language and the available ones.
+// With a language code (e.g., "/language ru") it saves the user's preference,
+// which is then used by the suggestion and feedback flows.
+func (h *MessageHandler) HandleLanguage(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+
+	savedLang, err := h.userRepo.GetUserLanguage(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:language User:%d] Error loading saved language: %v", userID, err)
+		savedLang = ""
+	}
+	currentLang := locales.ResolveLanguage(savedLang, message.From.LanguageCode)
+
+	args, err := languageArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, locales.NewLocalizer(currentLang), err)
+	}
+	if args.Arg("code") == "" {
+		localizer := locales.NewLocalizer(currentLang)
+		msg := locales.GetMessage(localizer, "MsgLanguageCurrent", map[string]interface{}{
+			"Language":  currentLang,
+			"Available": strings.Join(locales.SupportedLanguages(), ", "),
+		}, nil)
+		return h.sendSuccess(ctx, bot, chatID, msg)
+	}
+
+	requested := strings.ToLower(args.Arg("code"))
+	if !locales.IsSupported(requested) {
+		localizer := locales.NewLocalizer(currentLang)
+		msg := locales.GetMessage(localizer, "MsgLanguageUnsupported", map[string]interface{}{
+			"Language":  requested,
+			"Available": strings.Join(locales.SupportedLanguages(), ", "),
+		}, nil)
+		return h.sendSuccess(ctx, bot, chatID, msg)
+	}
+
+	newLang := locales.ResolveLanguage(requested)
+	if err := h.userRepo.SetUserLanguage(ctx, userID, newLang); err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to save language for user %d: %w", userID, err))
+	}
+
+	isAdmin, _ := h.adminChecker.IsAdmin(ctx, userID)
+	h.RecordUserActivity(ctx, message.From, ActionCommandLanguage, isAdmin, map[string]interface{}{
+		"chat_id":       chatID,
+		"language":      newLang,
+		"previous_lang": currentLang,
+	})
+
+	// Confirm in the newly selected language
+	localizer := locales.NewLocalizer(newLang)
+	msg := locales.GetMessage(localizer, "MsgLanguageSet", map[string]interface{}{"Language": newLang}, nil)
+	return h.sendSuccess(ctx, bot, chatID, msg)
+}
@@ -6,13 +6,16 @@ import (
 	"fmt"
 	"log"
 	"time"
+	"vrcmemes-bot/internal/customemoji"
 	"vrcmemes-bot/internal/database/models"
 	"vrcmemes-bot/internal/locales"
 	telegoapi "vrcmemes-bot/pkg/telegoapi" // Import for BotAPI
+	"vrcmemes-bot/pkg/utils"
 
 	"github.com/mymmrac/telego"
 	// th "github.com/mymmrac/telego/telegohandler" // No longer needed
 	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
 )
 
 // HandleText handles incoming text messages (excluding commands).
@@ -27,9 +30,8 @@ func (h *MessageHandler) HandleText(ctx context.Context, bot telegoapi.BotAPI, m
 		captionText := message.Text
 		log.Printf("[Cmd:CaptionReply User:%d Chat:%d] Received caption text \"%s\"", userID, chatID, captionText)
 
-		// Store the caption
-		_, exists := h.activeCaptions.Load(chatID)
-		h.activeCaptions.Store(chatID, captionText)
+		// Store the caption with its custom emoji
+		exists := h.setActiveCaption(chatID, captionText, message.Entities)
 		h.waitingForCaption.Delete(chatID) // Clear the waiting state
 
 		var confirmationMsg string
@@ -38,6 +40,9 @@ func (h *MessageHandler) HandleText(ctx context.Context, bot telegoapi.BotAPI, m
 		} else {
 			confirmationMsg = locales.GetMessage(localizer, "MsgCaptionSetConfirmation", nil, nil)
 		}
+		if warning := h.customEmojiWarning(localizer, message.Entities); warning != "" {
+			confirmationMsg += "\n\n" + warning
+		}
 
 		// Record activity (isAdmin assumed false here, as this is caption reply)
 		h.RecordUserActivity(ctx, message.From, ActionSetCaptionReply, false, map[string]interface{}{
@@ -72,50 +77,71 @@ func (h *MessageHandler) HandleText(ctx context.Context, bot telegoapi.BotAPI, m
 
 	// Admin is sending text directly for publishing
 	log.Printf("[HandleText Admin:%d] Sending text message to channel %d", userID, h.channelID)
-	textToPublish := message.Text
+	textToPublish, allowed := h.ScreenPost(ctx, bot, localizer, userID, chatID, message.Text)
+	if !allowed {
+		return nil
+	}
+	var entities []telego.MessageEntity
+	if textToPublish != message.Text {
+		// Censoring shifted the text, so the offsets of custom emoji no longer match
+	} else if h.customEmoji {
+		entities = customemoji.Entities(customemoji.FromEntities(message.Entities))
+	} else if warning := h.customEmojiWarning(localizer, message.Entities); warning != "" {
+		if err := h.sendSuccess(ctx, bot, chatID, warning); err != nil {
+			log.Printf("[HandleText Admin:%d] Error sending the custom emoji warning: %v", userID, err)
+		}
+	}
+
+	protect := h.ProtectContent(ctx)
 
 	// TODO: Consider if admins should be able to set caption with simple text? Unlikely.
 
-	sentMsg, err := bot.SendMessage(ctx, tu.Message(tu.ID(h.channelID), textToPublish))
-	if err != nil {
-		// Error sending to channel - report back to admin
-		// Log the specific error
-		log.Printf("[HandleText Admin:%d] Failed to send text to channel %d: %v", userID, h.channelID, err)
-		errorMsg := locales.GetMessage(localizer, "MsgErrorSendToChannel", nil, nil)
-		// Don't use h.sendError directly, as it returns the original error. We want to show the localized message.
-		_, _ = bot.SendMessage(ctx, tu.Message(tu.ID(chatID), errorMsg))
-		return err // Return original error for Sentry etc.
-	}
+	// Publishing runs as a background job so the update handler returns immediately
+	position := h.publishQueue.Submit(ctx, messagePublishKey(message), func(ctx context.Context) error {
+		params := tu.Message(tu.ID(h.channelID), textToPublish).WithEntities(entities...)
+		params.ProtectContent = protect
+		sentMsg, err := bot.SendMessage(ctx, params)
+		if err != nil {
+			return err
+		}
 
-	// Log the successful post
-	log.Printf("[HandleText Admin:%d] Successfully sent text message %d to channel %d", userID, sentMsg.MessageID, h.channelID)
-
-	// Create log entry for the text message post
-	logEntry := models.PostLog{
-		SenderID:       userID,
-		SenderUsername: message.From.Username,
-		Caption:        message.Text, // For text messages, caption is the text itself
-		MessageType:    "text",
-		ReceivedAt:     time.Unix(int64(message.Date), 0),
-		PublishedAt:    time.Unix(int64(sentMsg.Date), 0),
-		ChannelID:      h.channelID,
-		ChannelPostID:  sentMsg.MessageID,
-	}
-	if err := h.postLogger.LogPublishedPost(logEntry); err != nil {
-		log.Printf("[HandleText Admin:%d] Failed attempt to log text post to DB. Error: %v", userID, err)
-		// Log only, don't fail the operation for the user
-	}
+		// Log the successful post
+		log.Printf("[HandleText Admin:%d] Successfully sent text message %d to channel %d", userID, sentMsg.MessageID, h.channelID)
+
+		// Create log entry for the text message post
+		logEntry := models.PostLog{
+			SenderID:       userID,
+			SenderUsername: message.From.Username,
+			Caption:        textToPublish, // For text messages, caption is the text itself
+			MessageType:    "text",
+			ProtectContent: protect,
+			ReceivedAt:     time.Unix(int64(message.Date), 0),
+			PublishedAt:    time.Unix(int64(sentMsg.Date), 0),
+			ChannelID:      h.channelID,
+			ChannelPostID:  sentMsg.MessageID,
+			Origin:         models.PostOriginDirect,
+		}
+		if err := h.postLogger.LogPublishedPost(logEntry); err != nil {
+			log.Printf("[HandleText Admin:%d] Failed attempt to log text post to DB. Error: %v", userID, err)
+			// Log only, don't fail the operation for the user
+		}
 
-	// Record activity
-	h.RecordUserActivity(ctx, message.From, ActionSendTextToChannel, isAdmin, map[string]interface{}{
-		"chat_id":            chatID,
-		"text":               message.Text,
-		"channel_message_id": sentMsg.MessageID,
+		// Record activity
+		h.RecordUserActivity(ctx, message.From, ActionSendTextToChannel, isAdmin, map[string]interface{}{
+			"chat_id":            chatID,
+			"text":               message.Text,
+			"channel_message_id": sentMsg.MessageID,
+		})
+		return nil
+	}, func(ctx context.Context, err error) {
+		if err != nil {
+			// Error sending to channel - report back to admin
+			log.Printf("[HandleText Admin:%d] Failed to send text to channel %d: %v", userID, h.channelID, err)
+		}
+		h.ReportPublishResult(ctx, bot, localizer, chatID, err)
 	})
 
-	// Send confirmation using localized message
-	msg := locales.GetMessage(localizer, "MsgPostSentToChannel", nil, nil)
-	return h.sendSuccess(ctx, bot, chatID, msg)
+	return h.SendPublishQueued(ctx, bot, localizer, chatID, position)
 }
 
 // HandlePhoto handles incoming photo messages.
@@ -146,61 +172,95 @@ func (h *MessageHandler) HandlePhoto(ctx context.Context, bot telegoapi.BotAPI,
 	}
 
 	if !isAdmin {
+		// Offer to submit the photo as a suggestion instead of just refusing
+		offered, offerErr := h.suggestionManager.OfferDirectSuggestion(ctx, []telego.Message{message})
+		if offerErr != nil {
+			log.Printf("[HandlePhoto User:%d] Error offering direct suggestion: %v", userID, offerErr)
+		}
+		if offered {
+			return nil
+		}
 		log.Printf("[HandlePhoto User:%d] Non-admin attempted to send photo directly.", userID)
 		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
 		return h.sendError(ctx, bot, message.Chat.ID, errors.New(msg))
 	}
 
 	// Get the currently active caption for this user/chat (if any)
-	caption, _ := h.GetActiveCaption(message.Chat.ID) // Assuming GetActiveCaption returns empty string if none
-
-	// Copy the photo message to the target channel
-	sentMsgID, err := bot.CopyMessage(ctx, &telego.CopyMessageParams{
-		ChatID:     tu.ID(h.channelID),
-		FromChatID: tu.ID(message.Chat.ID),
-		MessageID:  message.MessageID,
-		Caption:    caption, // Apply the active caption
-	})
-	if err != nil {
-		// Error sending to channel - report back to admin
-		log.Printf("[HandlePhoto Admin:%d] Failed to copy photo message %d to channel %d: %v", userID, message.MessageID, h.channelID, err)
-		errorMsg := locales.GetMessage(localizer, "MsgErrorSendToChannel", nil, nil)
-		_, _ = bot.SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), errorMsg))
-		return err // Return original error for Sentry etc.
+	activeCaption, _ := h.GetActiveCaption(message.Chat.ID) // Assuming GetActiveCaption returns empty string if none
+	activeCaption, allowed := h.screenMediaCaption(ctx, bot, localizer, message, activeCaption)
+	if !allowed {
+		return nil
 	}
+	activeCaption, experiment := h.captionExperimentCaption(message.Chat.ID, activeCaption)
+	buildCaption := h.StyledCaption(message.Chat.ID, h.RubricCaption(message.Chat.ID, activeCaption))
+	protect := h.ProtectContent(ctx)
+	var channelPostID int
+
+	// Copy the photo message to the target channel as a background job
+	position := h.publishQueue.Submit(ctx, messagePublishKey(message), func(ctx context.Context) error {
+		caption, err := buildCaption(ctx)
+		if err != nil {
+			return err
+		}
+		sentMsgID, err := bot.CopyMessage(ctx, &telego.CopyMessageParams{
+			ChatID:     tu.ID(h.channelID),
+			FromChatID: tu.ID(message.Chat.ID),
+			MessageID:  message.MessageID,
+			Caption:    caption, // Apply the active caption
+			// CopyMessage drops the entities of the original caption when it's replaced
+			CaptionEntities: h.CaptionEntities(ctx, message.Chat.ID, caption),
+			ProtectContent:  protect,
+		})
+		if err != nil {
+			return err
+		}
 
-	publishedTime := time.Now()
-
-	// Create log entry for the photo post
-	logEntry := models.PostLog{
-		SenderID:       userID,
-		SenderUsername: message.From.Username,
-		Caption:        caption,
-		MessageType:    "photo",
-		ReceivedAt:     time.Unix(int64(message.Date), 0),
-		PublishedAt:    publishedTime,
-		ChannelID:      h.channelID,
-		// Use the MessageID from the result of CopyMessage which is the ID in the destination channel
-		ChannelPostID: sentMsgID.MessageID,
-	}
+		channelPostID = sentMsgID.MessageID
+		publishedTime := time.Now()
+
+		// Create log entry for the photo post
+		logEntry := models.PostLog{
+			SenderID:       userID,
+			SenderUsername: message.From.Username,
+			Caption:        caption,
+			MessageType:    "photo",
+			ProtectContent: protect,
+			FileID:         utils.MediaFileID(&message),
+			ReceivedAt:     time.Unix(int64(message.Date), 0),
+			PublishedAt:    publishedTime,
+			ChannelID:      h.channelID,
+			// Use the MessageID from the result of CopyMessage which is the ID in the destination channel
+			ChannelPostID: sentMsgID.MessageID,
+			Origin:        models.PostOriginDirect,
+		}
 
-	// Log the post to the database
-	if err := h.postLogger.LogPublishedPost(logEntry); err != nil {
-		log.Printf("[HandlePhoto Admin:%d] Failed attempt to log photo post to DB. Error: %v", userID, err)
-		// Log only, don't fail the operation for the user
-	}
+		// Log the post to the database
+		if err := h.postLogger.LogPublishedPost(logEntry); err != nil {
+			log.Printf("[HandlePhoto Admin:%d] Failed attempt to log photo post to DB. Error: %v", userID, err)
+			// Log only, don't fail the operation for the user
+		} else {
+			h.recognizePostText(logEntry.FileID, logEntry.ChannelPostID)
+		}
+
+		h.PromptRubricPost(ctx, message.Chat.ID, sentMsgID.MessageID)
 
-	// Record activity
-	h.RecordUserActivity(ctx, message.From, ActionSendPhotoToChannel, isAdmin, map[string]interface{}{
-		"chat_id":             message.Chat.ID,
-		"original_message_id": message.MessageID,
-		"channel_message_id":  sentMsgID.MessageID,
-		"caption_used":        caption,
+		// Record activity
+		h.RecordUserActivity(ctx, message.From, ActionSendPhotoToChannel, isAdmin, map[string]interface{}{
+			"chat_id":             message.Chat.ID,
+			"original_message_id": message.MessageID,
+			"channel_message_id":  sentMsgID.MessageID,
+			"caption_used":        caption,
+		})
+		return nil
+	}, func(ctx context.Context, err error) {
+		if err != nil {
+			log.Printf("[HandlePhoto Admin:%d] Failed to copy photo message %d to channel %d: %v", userID, message.MessageID, h.channelID, err)
+		}
+		h.finishCaptionExperiment(ctx, message.Chat.ID, experiment, channelPostID, err)
+		h.ReportPublishResult(ctx, bot, localizer, message.Chat.ID, err)
 	})
 
-	// Send confirmation using localized message
-	msg := locales.GetMessage(localizer, "MsgPostSentToChannel", nil, nil)
-	return h.sendSuccess(ctx, bot, message.Chat.ID, msg)
+	return h.SendPublishQueued(ctx, bot, localizer, message.Chat.ID, position)
 }
 
 // ProcessSuggestionMessage checks if the message is part of the suggestion workflow
@@ -250,54 +310,89 @@ func (h *MessageHandler) HandleVideo(ctx context.Context, bot telegoapi.BotAPI,
 	}
 	// --- End Admin Check ---
 
-	// Get active caption
-	caption, _ := h.GetActiveCaption(message.Chat.ID)
-
-	// Copy the video message to the target channel
-	sentMsgID, err := bot.CopyMessage(ctx, &telego.CopyMessageParams{
-		ChatID:     tu.ID(h.channelID),
-		FromChatID: tu.ID(message.Chat.ID),
-		MessageID:  message.MessageID,
-		Caption:    caption, // Apply the active caption
-	})
-	if err != nil {
-		log.Printf("[HandleVideo Admin:%d] Failed to copy video message %d to channel %d: %v", userID, message.MessageID, h.channelID, err)
-		errorMsg := locales.GetMessage(localizer, "MsgErrorSendToChannel", nil, nil) // Assuming a generic send error message exists
-		_, _ = bot.SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), errorMsg))
-		return err // Return original error
+	if issues := h.checkVideoPolicy(message.Video); len(issues) > 0 {
+		return h.holdVideoPost(ctx, bot, localizer, message, issues)
 	}
+	return h.postVideo(ctx, bot, localizer, message)
+}
 
-	publishedTime := time.Now()
-
-	// Create log entry for the video post
-	logEntry := models.PostLog{
-		SenderID:       userID,
-		SenderUsername: message.From.Username,
-		Caption:        caption,
-		MessageType:    "video", // Log type as video
-		ReceivedAt:     time.Unix(int64(message.Date), 0),
-		PublishedAt:    publishedTime,
-		ChannelID:      h.channelID,
-		ChannelPostID:  sentMsgID.MessageID,
-	}
+// postVideo copies an admin's video message to the channel with the active caption.
+func (h *MessageHandler) postVideo(ctx context.Context, bot telegoapi.BotAPI, localizer *i18n.Localizer, message telego.Message) error {
+	userID := message.From.ID
 
-	// Log the post to the database
-	if err := h.postLogger.LogPublishedPost(logEntry); err != nil {
-		log.Printf("[HandleVideo Admin:%d] Failed attempt to log video post to DB. Error: %v", userID, err)
-		// Log only, don't fail the operation for the user
+	// Get active caption
+	activeCaption, _ := h.GetActiveCaption(message.Chat.ID)
+	activeCaption, allowed := h.screenMediaCaption(ctx, bot, localizer, message, activeCaption)
+	if !allowed {
+		return nil
 	}
+	activeCaption, experiment := h.captionExperimentCaption(message.Chat.ID, activeCaption)
+	buildCaption := h.StyledCaption(message.Chat.ID, h.RubricCaption(message.Chat.ID, activeCaption))
+	protect := h.ProtectContent(ctx)
+	var channelPostID int
+
+	// Copy the video message to the target channel as a background job
+	position := h.publishQueue.Submit(ctx, messagePublishKey(message), func(ctx context.Context) error {
+		caption, err := buildCaption(ctx)
+		if err != nil {
+			return err
+		}
+		sentMsgID, err := bot.CopyMessage(ctx, &telego.CopyMessageParams{
+			ChatID:     tu.ID(h.channelID),
+			FromChatID: tu.ID(message.Chat.ID),
+			MessageID:  message.MessageID,
+			Caption:    caption, // Apply the active caption
+			// CopyMessage drops the entities of the original caption when it's replaced
+			CaptionEntities: h.CaptionEntities(ctx, message.Chat.ID, caption),
+			ProtectContent:  protect,
+		})
+		if err != nil {
+			return err
+		}
+
+		channelPostID = sentMsgID.MessageID
+		publishedTime := time.Now()
+
+		// Create log entry for the video post
+		logEntry := models.PostLog{
+			SenderID:       userID,
+			SenderUsername: message.From.Username,
+			Caption:        caption,
+			MessageType:    "video", // Log type as video
+			ProtectContent: protect,
+			FileID:         utils.MediaFileID(&message),
+			ReceivedAt:     time.Unix(int64(message.Date), 0),
+			PublishedAt:    publishedTime,
+			ChannelID:      h.channelID,
+			ChannelPostID:  sentMsgID.MessageID,
+			Origin:         models.PostOriginDirect,
+		}
 
-	// Record activity
-	h.RecordUserActivity(ctx, message.From, "send_video_to_channel", isAdmin, map[string]interface{}{
-		"chat_id":             message.Chat.ID,
-		"original_message_id": message.MessageID,
-		"channel_message_id":  sentMsgID.MessageID,
-		"caption_used":        caption,
+		// Log the post to the database
+		if err := h.postLogger.LogPublishedPost(logEntry); err != nil {
+			log.Printf("[HandleVideo Admin:%d] Failed attempt to log video post to DB. Error: %v", userID, err)
+			// Log only, don't fail the operation for the user
+		}
+
+		h.PromptRubricPost(ctx, message.Chat.ID, sentMsgID.MessageID)
+
+		// Record activity
+		h.RecordUserActivity(ctx, message.From, ActionSendVideoToChannel, true, map[string]interface{}{
+			"chat_id":             message.Chat.ID,
+			"original_message_id": message.MessageID,
+			"channel_message_id":  sentMsgID.MessageID,
+			"caption_used":        caption,
+		})
+		return nil
+	}, func(ctx context.Context, err error) {
+		if err != nil {
+			log.Printf("[HandleVideo Admin:%d] Failed to copy video message %d to channel %d: %v", userID, message.MessageID, h.channelID, err)
+		}
+		h.finishCaptionExperiment(ctx, message.Chat.ID, experiment, channelPostID, err)
+		h.ReportPublishResult(ctx, bot, localizer, message.Chat.ID, err)
 	})
 
-	// Send confirmation
-	msg := locales.GetMessage(localizer, "MsgPostSentToChannel", nil, nil)
-	return h.sendSuccess(ctx, bot, message.Chat.ID, msg)
+	return h.SendPublishQueued(ctx, bot, localizer, message.Chat.ID, position)
 }
 
 // HandleMediaGroup is commented out as media group logic is likely handled in bot.go
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// notificationsCallbackPrefix starts the callback data of the /notifications toggle buttons:
+// notif:<category>.
+const notificationsCallbackPrefix = "notif:"
+
+// notificationCategoryKeys maps the notification categories to the message IDs of their names.
+var notificationCategoryKeys = map[string]string{
+	models.NotificationCategoryDecisions:  "MsgNotificationsDecisions",
+	models.NotificationCategoryDigests:    "MsgNotificationsDigests",
+	models.NotificationCategoryBroadcasts: "MsgNotificationsBroadcasts",
+}
+
+// HandleNotifications handles the /notifications command. It shows which notifications the user
+// receives, with a button per category to turn it off or back on.
+func (h *MessageHandler) HandleNotifications(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	user, err := h.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to load notification preferences of user %d: %w", userID, err))
+	}
+
+	isAdmin, _ := h.adminChecker.IsAdmin(ctx, userID)
+	h.RecordUserActivity(ctx, message.From, ActionCommandNotifications, isAdmin, map[string]interface{}{
+		"chat_id": chatID,
+	})
+
+	text, keyboard := notificationsPanel(localizer, user)
+	_, err = bot.SendMessage(ctx, tu.Message(tu.ID(chatID), text).WithReplyMarkup(keyboard))
+	return err
+}
+
+// notificationsPanel builds the /notifications message: each category with whether it is on,
+// and the buttons toggling them.
+func notificationsPanel(localizer *i18n.Localizer, user *models.User) (string, *telego.InlineKeyboardMarkup) {
+	var text strings.Builder
+	text.WriteString(locales.GetMessage(localizer, "MsgNotificationsHeader", nil, nil))
+	rows := make([][]telego.InlineKeyboardButton, 0, len(models.NotificationCategories))
+	for _, category := range models.NotificationCategories {
+		name := locales.GetMessage(localizer, notificationCategoryKeys[category], nil, nil)
+		stateKey, buttonKey := "MsgNotificationsOn", "BtnNotificationsTurnOff"
+		if !user.NotificationsEnabled(category) {
+			stateKey, buttonKey = "MsgNotificationsOff", "BtnNotificationsTurnOn"
+		}
+		text.WriteString("\n")
+		text.WriteString(locales.GetMessage(localizer, stateKey, map[string]interface{}{"Category": name}, nil))
+		rows = append(rows, tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton(locales.GetMessage(localizer, buttonKey, map[string]interface{}{"Category": name}, nil)).
+				WithCallbackData(notificationsCallbackPrefix+category),
+		))
+	}
+	return text.String(), tu.InlineKeyboard(rows...)
+}
+
+// handleNotificationsCallback turns the category of a /notifications button off or back on for
+// the user who pressed it and updates the message.
+func (h *MessageHandler) handleNotificationsCallback(ctx context.Context, bot telegoapi.BotAPI, query telego.CallbackQuery) error {
+	userID := query.From.ID
+	localizer := h.getLocalizer(&query.From)
+	answer := func(text string) {
+		_ = bot.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID, Text: text, ShowAlert: text != ""})
+	}
+
+	category := strings.TrimPrefix(query.Data, notificationsCallbackPrefix)
+	if !slices.Contains(models.NotificationCategories, category) || query.Message == nil {
+		answer(locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil))
+		return fmt.Errorf("malformed notifications callback data %q", query.Data)
+	}
+
+	user, err := h.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		answer(locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil))
+		return fmt.Errorf("failed to load notification preferences of user %d: %w", userID, err)
+	}
+	muted := user.NotificationsEnabled(category)
+	if err := h.userRepo.SetNotificationMuted(ctx, userID, category, muted); err != nil {
+		answer(locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil))
+		return err
+	}
+	log.Printf("[NotificationsCallback User:%d] %s notifications muted: %t", userID, category, muted)
+	answer("")
+
+	if user == nil {
+		user = &models.User{UserID: userID}
+	}
+	if muted {
+		user.MutedNotifications = append(user.MutedNotifications, category)
+	} else {
+		user.MutedNotifications = slices.DeleteFunc(user.MutedNotifications, func(c string) bool { return c == category })
+	}
+	text, keyboard := notificationsPanel(localizer, user)
+	_, err = bot.EditMessageText(ctx, &telego.EditMessageTextParams{
+		ChatID:      tu.ID(query.Message.GetChat().ID),
+		MessageID:   query.Message.GetMessageID(),
+		Text:        text,
+		ReplyMarkup: keyboard,
+	})
+	if err != nil {
+		log.Printf("[NotificationsCallback User:%d] Error updating the notifications message: %v", userID, err)
+	}
+	return nil
+}
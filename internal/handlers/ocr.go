@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"vrcmemes-bot/internal/suggestions"
+)
+
+// SetTextRecognizer enables OCR of the photos admins post to the channel: the recognized text is
+// stored in the post log. nil disables it.
+func (h *MessageHandler) SetTextRecognizer(recognizer suggestions.TextRecognizer) {
+	h.textRecognizer = recognizer
+}
+
+// recognizePostText reads the text of a posted photo in the background and stores it in the post
+// log. Errors are only logged.
+func (h *MessageHandler) recognizePostText(fileID string, postID int) {
+	if h.textRecognizer == nil || h.statsRepo == nil || fileID == "" {
+		return
+	}
+	go func() {
+		ctx := context.Background()
+		text, err := h.textRecognizer.ReadFile(ctx, fileID)
+		if err != nil {
+			log.Printf("[OCR Post:%d] Error recognizing text: %v", postID, err)
+			return
+		}
+		if text == "" {
+			return
+		}
+		if err := h.statsRepo.SetPostOCRText(ctx, h.channelID, postID, text); err != nil {
+			log.Printf("[OCR Post:%d] Error saving recognized text: %v", postID, err)
+		}
+	}()
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"vrcmemes-bot/internal/auth"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/suggestions"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// reviewCommand is the command whose permission also lets a user press the buttons of the
+// review messages.
+const reviewCommand = "review"
+
+// permArgs declares the arguments of /perm.
+var permArgs = cmdargs.Spec{
+	Command: "perm",
+	Args: []cmdargs.Arg{
+		{Name: "action", Required: true, Choices: []string{"list", "grant", "revoke"}},
+		{Name: "user"},
+		{Name: "command"},
+	},
+}
+
+// SetPermissionRepository sets where the permissions granted with /perm are stored. Without it,
+// only channel admins can run admin commands and /perm is unavailable.
+func (h *MessageHandler) SetPermissionRepository(repo database.PermissionRepository) {
+	h.permissionRepo = repo
+}
+
+// authorized wraps the handler of a command so a user who was granted an admin command with
+// /perm runs it as an admin: the command's own admin checks see the grant in the context.
+func (h *MessageHandler) authorized(cmd Command) func(context.Context, telegoapi.BotAPI, telego.Message) error {
+	if cmd.Role != RoleAdmin || cmd.Handler == nil {
+		return cmd.Handler
+	}
+	return func(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+		if message.From != nil && h.commandGranted(ctx, cmd.Command, message.From.ID) {
+			log.Printf("[Cmd:%s User:%d] Running with a /perm grant", cmd.Command, message.From.ID)
+			ctx = auth.WithGrant(ctx, message.From.ID)
+		}
+		return cmd.Handler(ctx, bot, message)
+	}
+}
+
+// commandGranted reports whether a user who isn't an admin was granted the command with /perm.
+func (h *MessageHandler) commandGranted(ctx context.Context, command string, userID int64) bool {
+	if h.permissionRepo == nil {
+		return false
+	}
+	if isAdmin, err := h.adminChecker.IsAdmin(ctx, userID); err == nil && isAdmin {
+		return false
+	}
+	granted, err := h.permissionRepo.HasPermission(ctx, command, userID)
+	if err != nil {
+		log.Printf("[Perm User:%d] Error checking permission of /%s: %v", userID, command, err)
+		return false
+	}
+	return granted
+}
+
+// GrantedCallbackContext returns the context a button press is handled in: a user who was
+// granted /review can approve, reject and page through the review messages as an admin, see
+// suggestions.IsGrantableReviewCallback. Other buttons are handled without a grant.
+func (h *MessageHandler) GrantedCallbackContext(ctx context.Context, query telego.CallbackQuery) context.Context {
+	if suggestions.IsGrantableReviewCallback(query.Data) && h.commandGranted(ctx, reviewCommand, query.From.ID) {
+		return auth.WithGrant(ctx, query.From.ID)
+	}
+	return ctx
+}
+
+// HandlePerm handles the /perm command (super admins only):
+//
+//	/perm list                                         — show the granted commands
+//	/perm grant <@user|user ID|everyone> <command>     — let a user, or everyone, run an admin command
+//	/perm revoke <@user|user ID|everyone> <command>    — take the permission back
+//
+// Only admin commands can be granted. A granted user runs the command as an admin would; a
+// /review grant also covers the buttons of the review messages.
+func (h *MessageHandler) HandlePerm(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	if !h.adminChecker.IsSuperAdmin(userID) {
+		log.Printf("[Cmd:perm User:%d] Non-super-admin user attempted to use /perm.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresSuperAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	if h.permissionRepo == nil {
+		return h.sendError(ctx, bot, chatID, errors.New("permission repository is not configured"))
+	}
+	args, err := permArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	action := args.Arg("action")
+	if action == "list" {
+		return h.listPermissions(ctx, bot, localizer, chatID)
+	}
+
+	grantee, command := args.Arg("user"), strings.ToLower(strings.TrimPrefix(args.Arg("command"), "/"))
+	if grantee == "" || command == "" {
+		return h.sendUsageError(ctx, bot, chatID, localizer, &cmdargs.UsageError{
+			MessageID: "MsgArgsMissing",
+			Data:      map[string]interface{}{"Arg": "user command", "Usage": permArgs.Usage()},
+		})
+	}
+	if !h.grantable(command) {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgPermNotGrantable", map[string]interface{}{
+			"Command": command,
+		}, nil))
+	}
+	granteeID, role, err := h.resolveGrantee(ctx, grantee)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, err)
+	}
+	if granteeID == 0 && role == "" {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgPermUnknownUser", map[string]interface{}{
+			"User": grantee,
+		}, nil))
+	}
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandPerm, true, map[string]interface{}{
+		"chat_id":    chatID,
+		"action":     action,
+		"command":    command,
+		"grantee_id": granteeID,
+		"role":       role,
+	})
+	data := map[string]interface{}{"Command": command, "User": grantee}
+
+	if action == "revoke" {
+		err := h.permissionRepo.RevokePermission(ctx, command, granteeID, role)
+		if errors.Is(err, database.ErrPermissionNotFound) {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgPermNotGranted", data, nil))
+		}
+		if err != nil {
+			return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to revoke /%s: %w", command, err))
+		}
+		log.Printf("[Cmd:perm User:%d] Revoked /%s from %s", userID, command, grantee)
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgPermRevoked", data, nil))
+	}
+
+	if err := h.permissionRepo.GrantPermission(ctx, &models.CommandPermission{
+		ChannelID: h.channelID,
+		Command:   command,
+		UserID:    granteeID,
+		Role:      role,
+		GrantedBy: userID,
+	}); err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to grant /%s: %w", command, err))
+	}
+	log.Printf("[Cmd:perm User:%d] Granted /%s to %s", userID, command, grantee)
+	return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgPermGranted", data, nil))
+}
+
+// grantable reports whether a command can be granted with /perm, which only admin commands can.
+func (h *MessageHandler) grantable(command string) bool {
+	for _, cmd := range h.commands {
+		if cmd.Command == command {
+			return cmd.Role == RoleAdmin
+		}
+	}
+	return false
+}
+
+// resolveGrantee resolves the user of /perm grant and /perm revoke: "everyone", a user ID, or
+// the @username of a user the bot knows. It returns a zero ID and an empty role for an unknown user.
+func (h *MessageHandler) resolveGrantee(ctx context.Context, grantee string) (int64, string, error) {
+	if strings.EqualFold(grantee, models.PermissionRoleEveryone) {
+		return 0, models.PermissionRoleEveryone, nil
+	}
+	if id, err := strconv.ParseInt(grantee, 10, 64); err == nil && id > 0 {
+		return id, "", nil
+	}
+	if !strings.HasPrefix(grantee, "@") {
+		return 0, "", nil
+	}
+	user, err := h.userRepo.GetUserByUsername(ctx, strings.TrimPrefix(grantee, "@"))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to look up %s: %w", grantee, err)
+	}
+	if user == nil {
+		return 0, "", nil
+	}
+	return user.UserID, "", nil
+}
+
+// listPermissions shows the granted commands.
+func (h *MessageHandler) listPermissions(ctx context.Context, bot telegoapi.BotAPI, localizer *i18n.Localizer, chatID int64) error {
+	permissions, err := h.permissionRepo.ListPermissions(ctx)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to list permissions: %w", err))
+	}
+	if len(permissions) == 0 {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgPermListEmpty", nil, nil))
+	}
+	var text strings.Builder
+	text.WriteString(locales.GetMessage(localizer, "MsgPermListHeader", nil, nil))
+	for _, permission := range permissions {
+		grantee := strconv.FormatInt(permission.UserID, 10)
+		if permission.Role != "" {
+			grantee = permission.Role
+		} else if user, err := h.userRepo.GetUser(ctx, permission.UserID); err == nil && user != nil && user.Username != "" {
+			grantee = "@" + user.Username
+		}
+		text.WriteString("\n")
+		text.WriteString(locales.GetMessage(localizer, "MsgPermListItem", map[string]interface{}{
+			"Command": permission.Command,
+			"User":    grantee,
+		}, nil))
+	}
+	return h.sendSuccess(ctx, bot, chatID, text.String())
+}
@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+	"vrcmemes-bot/pkg/utils"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// maxPinHours bounds how long /pin keeps a post pinned.
+	maxPinHours = 168
+	// pinScheduleInterval is how often pins whose time is up are unpinned.
+	pinScheduleInterval = time.Minute
+)
+
+// Reasons recorded in the audit log when a post is unpinned.
+const (
+	unpinReasonExpired  = "expired"
+	unpinReasonCanceled = "canceled"
+	unpinReasonFailed   = "pin_failed"
+)
+
+// pinArgs declares the arguments of /pin.
+var pinArgs = cmdargs.Spec{
+	Command: "pin",
+	Args: []cmdargs.Arg{
+		{Name: "post|list|cancel", Required: true},
+		{Name: "hours|post"},
+	},
+	Flags: []string{"queue"},
+}
+
+// SetPinRepository sets where the posts pinned with /pin are stored. Without it, /pin is unavailable.
+func (h *MessageHandler) SetPinRepository(repo database.PinRepository) {
+	h.pinRepo = repo
+}
+
+// StartPinScheduler periodically unpins the posts whose time is up and pins the next queued
+// post in their place, until ctx is done.
+func (h *MessageHandler) StartPinScheduler(ctx context.Context, bot telegoapi.BotAPI) {
+	if h.pinRepo == nil {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(pinScheduleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if err := h.runPinSchedule(ctx, bot, now); err != nil {
+					log.Printf("[PinScheduler] %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// runPinSchedule unpins the posts whose time is up at now and, once no post is pinned, pins the
+// next queued post.
+func (h *MessageHandler) runPinSchedule(ctx context.Context, bot telegoapi.BotAPI, now time.Time) error {
+	due, err := h.pinRepo.DuePins(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to get due pins: %w", err)
+	}
+	for i := range due {
+		h.unpinPost(ctx, bot, &due[i], unpinReasonExpired, now)
+	}
+	return h.pinNextQueued(ctx, bot, now)
+}
+
+// pinNextQueued pins the next queued post unless a post is pinned already. A queued post that
+// can't be pinned, e.g. because it was deleted, is dropped and the one after it is tried.
+func (h *MessageHandler) pinNextQueued(ctx context.Context, bot telegoapi.BotAPI, now time.Time) error {
+	pins, err := h.pinRepo.ListPins(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list pins: %w", err)
+	}
+	if len(pins) > 0 && pins[0].Status == models.PinStatusPinned {
+		return nil
+	}
+	for {
+		next, err := h.pinRepo.NextQueuedPin(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get next queued pin: %w", err)
+		}
+		if next == nil {
+			return nil
+		}
+		if err := h.pinPost(ctx, bot, next, now); err != nil {
+			log.Printf("[PinScheduler] Failed to pin queued post %d: %v", next.ChannelPostID, err)
+			h.unpinPost(ctx, bot, next, unpinReasonFailed, now)
+			continue
+		}
+		if err := h.pinRepo.MarkPinned(ctx, next.ID, now, now.Add(next.Duration)); err != nil {
+			return fmt.Errorf("failed to mark post %d as pinned: %w", next.ChannelPostID, err)
+		}
+		return nil
+	}
+}
+
+// pinPost pins a post in the channel without notifying members and records it in the audit log.
+func (h *MessageHandler) pinPost(ctx context.Context, bot telegoapi.BotAPI, pin *models.ScheduledPin, now time.Time) error {
+	if err := bot.PinChatMessage(ctx, &telego.PinChatMessageParams{
+		ChatID:              tu.ID(h.channelID),
+		MessageID:           pin.ChannelPostID,
+		DisableNotification: true,
+	}); err != nil {
+		return err
+	}
+	log.Printf("[PinScheduler] Pinned channel post %d for %s", pin.ChannelPostID, pin.Duration)
+	if err := h.actionLogger.LogUserAction(pin.CreatedBy, ActionPinPost, map[string]interface{}{
+		"channel_id":      h.channelID,
+		"channel_post_id": pin.ChannelPostID,
+		"unpin_at":        now.Add(pin.Duration),
+	}); err != nil {
+		log.Printf("[PinScheduler] Failed to log pin of post %d: %v", pin.ChannelPostID, err)
+	}
+	return nil
+}
+
+// unpinPost ends a pinned or queued post and records it in the audit log. A pinned post is
+// unpinned in the channel first; if that fails, e.g. because the post was deleted, the error is
+// logged and the pin ends all the same, so it isn't retried forever.
+func (h *MessageHandler) unpinPost(ctx context.Context, bot telegoapi.BotAPI, pin *models.ScheduledPin, reason string, now time.Time) {
+	if pin.Status == models.PinStatusPinned {
+		if err := bot.UnpinChatMessage(ctx, &telego.UnpinChatMessageParams{
+			ChatID:    tu.ID(h.channelID),
+			MessageID: pin.ChannelPostID,
+		}); err != nil {
+			log.Printf("[PinScheduler] Failed to unpin channel post %d: %v", pin.ChannelPostID, err)
+		}
+	}
+	if err := h.pinRepo.MarkUnpinned(ctx, pin.ID, now); err != nil {
+		if !errors.Is(err, database.ErrPinNotFound) {
+			log.Printf("[PinScheduler] Failed to end pin of post %d: %v", pin.ChannelPostID, err)
+		}
+		return
+	}
+	log.Printf("[PinScheduler] Ended %s pin of channel post %d: %s", pin.Status, pin.ChannelPostID, reason)
+	if pin.Status != models.PinStatusPinned {
+		return
+	}
+	if err := h.actionLogger.LogUserAction(pin.CreatedBy, ActionUnpinPost, map[string]interface{}{
+		"channel_id":      h.channelID,
+		"channel_post_id": pin.ChannelPostID,
+		"reason":          reason,
+	}); err != nil {
+		log.Printf("[PinScheduler] Failed to log unpin of post %d: %v", pin.ChannelPostID, err)
+	}
+}
+
+// HandlePin handles the /pin command (admin only):
+//
+//	/pin <post ID> <hours> [--queue]  — pin a channel post for some hours
+//	/pin list                         — show the pinned and queued posts
+//	/pin cancel <post ID>             — unpin a post now, or drop it from the queue
+//
+// With --queue, the post waits until the pinned post's time is up and replaces it; without it,
+// the post is pinned right away. Posts are unpinned by the pin scheduler, which checks every minute.
+func (h *MessageHandler) HandlePin(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:pin User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:pin User:%d] Non-admin user attempted to use /pin.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	if h.pinRepo == nil {
+		return h.sendError(ctx, bot, chatID, errors.New("pin repository is not configured"))
+	}
+
+	args, err := pinArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	first, second := args.Arg("post|list|cancel"), args.Arg("hours|post")
+	switch strings.ToLower(first) {
+	case "list":
+		return h.listPins(ctx, bot, localizer, chatID)
+	case "cancel":
+		postID, err := strconv.Atoi(second)
+		if err != nil || postID <= 0 {
+			return h.sendUsageError(ctx, bot, chatID, localizer, &cmdargs.UsageError{
+				MessageID: "MsgArgsMissing",
+				Data:      map[string]interface{}{"Arg": "post", "Usage": pinArgs.Usage()},
+			})
+		}
+		return h.cancelPin(ctx, bot, localizer, message, postID)
+	}
+
+	postID, err := strconv.Atoi(first)
+	if err != nil || postID <= 0 {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgPinInvalidPost", map[string]interface{}{
+			"Usage": pinArgs.Usage(),
+		}, nil))
+	}
+	hours, err := strconv.Atoi(second)
+	if err != nil || hours < 1 || hours > maxPinHours {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgPinInvalidHours", map[string]interface{}{
+			"Max": maxPinHours,
+		}, nil))
+	}
+
+	pins, err := h.pinRepo.ListPins(ctx)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to list pins: %w", err))
+	}
+	for _, pin := range pins {
+		if pin.ChannelPostID == postID {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgPinAlreadyScheduled", map[string]interface{}{
+				"PostID": postID,
+			}, nil))
+		}
+	}
+
+	now := time.Now()
+	pin := &models.ScheduledPin{
+		ChannelID:     h.channelID,
+		ChannelPostID: postID,
+		Duration:      time.Duration(hours) * time.Hour,
+		Status:        models.PinStatusQueued,
+		CreatedBy:     userID,
+		CreatedAt:     now,
+	}
+	h.RecordUserActivity(ctx, message.From, ActionCommandPin, isAdmin, map[string]interface{}{
+		"chat_id":         chatID,
+		"channel_post_id": postID,
+		"hours":           hours,
+		"queue":           args.Flag("queue"),
+	})
+
+	if args.Flag("queue") && len(pins) > 0 && pins[0].Status == models.PinStatusPinned {
+		if err := h.pinRepo.CreatePin(ctx, pin); err != nil {
+			return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to queue pin of post %d: %w", postID, err))
+		}
+		log.Printf("[Cmd:pin User:%d] Queued channel post %d for %d hours", userID, postID, hours)
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgPinQueued", map[string]interface{}{
+			"PostID":   postID,
+			"Hours":    hours,
+			"Position": len(pins),
+		}, nil))
+	}
+
+	if err := h.pinPost(ctx, bot, pin, now); err != nil {
+		log.Printf("[Cmd:pin User:%d] Failed to pin channel post %d: %v", userID, postID, err)
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgPinFailed", map[string]interface{}{
+			"PostID": postID,
+		}, nil))
+	}
+	unpinAt := now.Add(pin.Duration)
+	pin.Status = models.PinStatusPinned
+	pin.PinnedAt = &now
+	pin.UnpinAt = &unpinAt
+	if err := h.pinRepo.CreatePin(ctx, pin); err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to store pin of post %d: %w", postID, err))
+	}
+	return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgPinPinned", map[string]interface{}{
+		"PostID": postID,
+		"Until":  utils.FormatTime(unpinAt, h.location),
+	}, nil))
+}
+
+// listPins shows the pinned and queued posts.
+func (h *MessageHandler) listPins(ctx context.Context, bot telegoapi.BotAPI, localizer *i18n.Localizer, chatID int64) error {
+	pins, err := h.pinRepo.ListPins(ctx)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to list pins: %w", err))
+	}
+	if len(pins) == 0 {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgPinListEmpty", nil, nil))
+	}
+	var text strings.Builder
+	text.WriteString(locales.GetMessage(localizer, "MsgPinListHeader", nil, nil))
+	for _, pin := range pins {
+		text.WriteString("\n")
+		if pin.Status == models.PinStatusPinned && pin.UnpinAt != nil {
+			text.WriteString(locales.GetMessage(localizer, "MsgPinListPinned", map[string]interface{}{
+				"PostID": pin.ChannelPostID,
+				"Until":  utils.FormatTime(*pin.UnpinAt, h.location),
+			}, nil))
+			continue
+		}
+		text.WriteString(locales.GetMessage(localizer, "MsgPinListQueued", map[string]interface{}{
+			"PostID": pin.ChannelPostID,
+			"Hours":  int(pin.Duration / time.Hour),
+		}, nil))
+	}
+	return h.sendSuccess(ctx, bot, chatID, text.String())
+}
+
+// cancelPin unpins a post now, pinning the next queued post in its place, or drops it from the queue.
+func (h *MessageHandler) cancelPin(ctx context.Context, bot telegoapi.BotAPI, localizer *i18n.Localizer, message telego.Message, postID int) error {
+	chatID := message.Chat.ID
+	pins, err := h.pinRepo.ListPins(ctx)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to list pins: %w", err))
+	}
+	for i := range pins {
+		if pins[i].ChannelPostID != postID {
+			continue
+		}
+		now := time.Now()
+		h.unpinPost(ctx, bot, &pins[i], unpinReasonCanceled, now)
+		log.Printf("[Cmd:pin User:%d] Canceled %s pin of channel post %d", message.From.ID, pins[i].Status, postID)
+		if err := h.pinNextQueued(ctx, bot, now); err != nil {
+			log.Printf("[Cmd:pin User:%d] %v", message.From.ID, err)
+		}
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgPinCanceled", map[string]interface{}{
+			"PostID": postID,
+		}, nil))
+	}
+	return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgPinNotFound", map[string]interface{}{
+		"PostID": postID,
+	}, nil))
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+	"vrcmemes-bot/pkg/utils"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// DefaultMaxPostFileSize is the default size limit for files posted by URL or from the staging directory.
+const DefaultMaxPostFileSize = 10 << 20 // 10 MB, Telegram's photo upload limit
+
+// Errors returned by checkImageURL, each mapped to a message for the admin.
+var (
+	errPostURLInvalid     = errors.New("not a valid http(s) URL")
+	errPostURLUnreachable = errors.New("URL could not be fetched")
+	errPostURLNotImage    = errors.New("URL does not point to an image")
+	errPostURLTooLarge    = errors.New("image is too large")
+)
+
+// postURLArgs declares the arguments of /posturl.
+var postURLArgs = cmdargs.Spec{
+	Command: "posturl",
+	Args: []cmdargs.Arg{
+		{Name: "url", Required: true},
+		{Name: "caption", Rest: true},
+	},
+	Flags: []string{"silent", "protect"},
+}
+
+// urlCheckClient is used to inspect URLs before handing them to Telegram.
+var urlCheckClient = &http.Client{Timeout: 10 * time.Second}
+
+// SetMaxPostFileSize sets the size limit for images posted with /posturl. Non-positive values keep the default.
+func (h *MessageHandler) SetMaxPostFileSize(size int64) {
+	if size > 0 {
+		h.maxPostFileSize = size
+	}
+}
+
+// HandlePostURL handles the /posturl [--silent] <image-url> [caption] command (admin only).
+// The URL is validated and then passed to Telegram, which downloads the image itself.
+// Without a caption argument, the active caption is used.
+func (h *MessageHandler) HandlePostURL(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:posturl User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:posturl User:%d] Non-admin user attempted to use /posturl.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+
+	args, err := postURLArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	imageURL := args.Arg("url")
+	caption := args.Arg("caption") // Kept as typed, including line breaks
+	if caption == "" {
+		caption, _ = h.GetActiveCaption(chatID)
+	}
+
+	maxSize := h.maxPostFileSize
+	if err := checkImageURL(ctx, imageURL, maxSize); err != nil {
+		log.Printf("[Cmd:posturl User:%d] Rejected URL %q: %v", userID, imageURL, err)
+		msgID := "MsgPostURLUnreachable"
+		switch {
+		case errors.Is(err, errPostURLInvalid):
+			msgID = "MsgPostURLInvalid"
+		case errors.Is(err, errPostURLNotImage):
+			msgID = "MsgPostURLNotImage"
+		case errors.Is(err, errPostURLTooLarge):
+			msgID = "MsgPostURLTooLarge"
+		}
+		msg := locales.GetMessage(localizer, msgID, map[string]interface{}{"MaxMB": maxSize >> 20}, nil)
+		return h.sendSuccess(ctx, bot, chatID, msg)
+	}
+
+	buildCaption := h.StyledCaption(chatID, h.RubricCaption(chatID, caption))
+	protect := args.Flag("protect") || h.ProtectContent(ctx)
+	position := h.publishQueue.Submit(ctx, messagePublishKey(message), func(ctx context.Context) error {
+		caption, err := buildCaption(ctx)
+		if err != nil {
+			return err
+		}
+		sentMsg, err := bot.SendPhoto(ctx, &telego.SendPhotoParams{
+			ChatID:              tu.ID(h.channelID),
+			Photo:               tu.FileFromURL(imageURL),
+			Caption:             caption,
+			CaptionEntities:     h.CaptionEntities(ctx, chatID, caption),
+			DisableNotification: args.Flag("silent"),
+			ProtectContent:      protect,
+		})
+		if err != nil {
+			return err
+		}
+
+		logEntry := models.PostLog{
+			SenderID:       userID,
+			SenderUsername: message.From.Username,
+			Caption:        caption,
+			MessageType:    "photo",
+			FileID:         utils.MediaFileID(sentMsg),
+			ReceivedAt:     time.Unix(int64(message.Date), 0),
+			PublishedAt:    time.Unix(int64(sentMsg.Date), 0),
+			ChannelID:      h.channelID,
+			ChannelPostID:  sentMsg.MessageID,
+			SourceURL:      imageURL,
+			ProtectContent: protect,
+			Origin:         models.PostOriginDirect,
+		}
+		if err := h.postLogger.LogPublishedPost(logEntry); err != nil {
+			log.Printf("[Cmd:posturl Admin:%d] Failed attempt to log URL post to DB. Error: %v", userID, err)
+		}
+
+		h.PromptRubricPost(ctx, chatID, sentMsg.MessageID)
+
+		h.RecordUserActivity(ctx, message.From, ActionCommandPostURL, isAdmin, map[string]interface{}{
+			"chat_id":            chatID,
+			"url":                imageURL,
+			"channel_message_id": sentMsg.MessageID,
+			"caption_used":       caption,
+		})
+		return nil
+	}, func(ctx context.Context, err error) {
+		if err != nil {
+			log.Printf("[Cmd:posturl Admin:%d] Failed to post %q to channel %d: %v", userID, imageURL, h.channelID, err)
+		}
+		h.ReportPublishResult(ctx, bot, localizer, chatID, err)
+	})
+
+	return h.SendPublishQueued(ctx, bot, localizer, chatID, position)
+}
+
+// checkImageURL verifies that rawURL is an http(s) URL serving an image no larger than maxSize.
+// Servers that don't support HEAD requests are given the benefit of the doubt; Telegram
+// rejects the URL itself if it turns out not to be an image.
+func checkImageURL(ctx context.Context, rawURL string, maxSize int64) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errPostURLInvalid
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, nil)
+	if err != nil {
+		return errPostURLInvalid
+	}
+	resp, err := urlCheckClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errPostURLUnreachable, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusMethodNotAllowed {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: status %d", errPostURLUnreachable, resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !strings.HasPrefix(contentType, "image/") {
+		return fmt.Errorf("%w: content type %s", errPostURLNotImage, contentType)
+	}
+	if resp.ContentLength > maxSize {
+		return fmt.Errorf("%w: %d bytes", errPostURLTooLarge, resp.ContentLength)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// privateStartPayloads are the deep link payloads that start a private-only command in the
+// private chat with the bot. Suggestions started from a group are attributed to it in /stats.
+var privateStartPayloads = map[string]string{
+	"suggest":  suggestStartPayload + "_" + suggestSourceGroup,
+	"feedback": string(startPayloadFeedback),
+}
+
+// privateOnly wraps the handler of a command that only works in private chats. Sent in a group,
+// the command gets a reply with a button opening the private chat with the bot instead.
+func (h *MessageHandler) privateOnly(command string, handler func(context.Context, telegoapi.BotAPI, telego.Message) error) func(context.Context, telegoapi.BotAPI, telego.Message) error {
+	return func(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+		if message.Chat.Type == telego.ChatTypePrivate {
+			return handler(ctx, bot, message)
+		}
+		log.Printf("[Cmd:%s User:%d] Redirecting from chat %d to the private chat", command, message.From.ID, message.Chat.ID)
+		return h.redirectToPrivateChat(ctx, bot, message, command)
+	}
+}
+
+// redirectToPrivateChat replies to a command sent in a group with a button that opens the
+// private chat with the bot and, for commands with a deep link, starts the command there.
+func (h *MessageHandler) redirectToPrivateChat(ctx context.Context, bot telegoapi.BotAPI, message telego.Message, command string) error {
+	localizer := h.getLocalizer(message.From)
+	me, err := bot.GetMe(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the bot's username: %w", err)
+	}
+	link := "https://t.me/" + me.Username
+	if payload := privateStartPayloads[command]; payload != "" {
+		link += "?start=" + payload
+	}
+	text := locales.GetMessage(localizer, "MsgCommandPrivateOnly", map[string]interface{}{"Command": command}, nil)
+	keyboard := tu.InlineKeyboard(tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnOpenPrivateChat", nil, nil)).WithURL(link),
+	))
+	_, err = bot.SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), text).
+		WithReplyMarkup(keyboard).
+		WithReplyParameters(&telego.ReplyParameters{MessageID: message.MessageID, AllowSendingWithoutReply: true}))
+	return err
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+)
+
+// queueArgs declares the arguments of /queue.
+var queueArgs = cmdargs.Spec{
+	Command: "queue",
+	Args: []cmdargs.Arg{
+		{Name: "action", Required: true, Choices: []string{"export"}},
+	},
+}
+
+// applyArgs declares the arguments of /apply.
+var applyArgs = cmdargs.Spec{
+	Command: "apply",
+	Args: []cmdargs.Arg{
+		{Name: "code:a|r ...", Required: true, Rest: true},
+	},
+}
+
+// HandleQueue handles the /queue export command (admin only).
+// It sends a zipped HTML snapshot of the pending suggestions with their thumbnails, to go
+// through offline; decisions are sent back with /apply.
+func (h *MessageHandler) HandleQueue(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:queue User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:queue User:%d] Non-admin user attempted to use /queue.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+
+	if _, err := queueArgs.Parse(message.Text); err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	update := telego.Update{Message: &message}
+	if err := h.suggestionManager.HandleQueueExportCommand(ctx, update); err != nil {
+		// The manager sends user-facing errors itself
+		log.Printf("[Cmd:queue User:%d] Error from suggestionManager.HandleQueueExportCommand: %v", userID, err)
+	}
+	h.RecordUserActivity(ctx, message.From, ActionCommandQueueExport, isAdmin, map[string]interface{}{
+		"chat_id": chatID,
+	})
+	return nil
+}
+
+// HandleApply handles the /apply <code>:<a|r> ... command (admin only).
+// It approves (a) or rejects (r) the pending suggestions with the given reference codes, e.g.
+// as decided offline from a /queue export snapshot, and reports the outcome of each.
+func (h *MessageHandler) HandleApply(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:apply User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:apply User:%d] Non-admin user attempted to use /apply.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+
+	args, err := applyArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	decisions := args.Arg("code:a|r ...")
+	update := telego.Update{Message: &message}
+	if err := h.suggestionManager.HandleApplyCommand(ctx, update, decisions); err != nil {
+		// The manager sends user-facing errors itself
+		log.Printf("[Cmd:apply User:%d] Error from suggestionManager.HandleApplyCommand: %v", userID, err)
+	}
+	h.RecordUserActivity(ctx, message.From, ActionCommandApply, isAdmin, map[string]interface{}{
+		"chat_id":   chatID,
+		"decisions": decisions,
+	})
+	return nil
+}
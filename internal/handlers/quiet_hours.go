@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/suggestions"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+)
+
+// quietHoursArgs declares the arguments of /quiethours.
+var quietHoursArgs = cmdargs.Spec{
+	Command: "quiethours",
+	Args: []cmdargs.Arg{
+		{Name: "HH:MM-HH:MM|off"},
+	},
+}
+
+// HandleQuietHours handles the /quiethours [HH:MM-HH:MM|off] command (admin only).
+// During their quiet hours, admins get no non-critical notifications such as new suggestion
+// pings; those are sent as one summary afterwards. Without arguments, the current quiet hours are shown.
+func (h *MessageHandler) HandleQuietHours(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:quiethours User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:quiethours User:%d] Non-admin user attempted to use /quiethours.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+
+	args, err := quietHoursArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	value := args.Arg("HH:MM-HH:MM|off")
+	zone := h.location.String()
+
+	if value == "" {
+		quiet, err := h.suggestionManager.GetQuietHours(ctx, userID)
+		if err != nil {
+			return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to get quiet hours: %w", err))
+		}
+		if quiet == nil {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgQuietHoursNone", nil, nil))
+		}
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgQuietHoursCurrent", map[string]interface{}{
+			"Hours": quiet.String(),
+			"Zone":  zone,
+		}, nil))
+	}
+
+	var quiet *suggestions.QuietHours
+	if !strings.EqualFold(value, "off") {
+		parsed, err := suggestions.ParseQuietHours(value)
+		if err != nil {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgQuietHoursInvalid", nil, nil))
+		}
+		quiet = &parsed
+	}
+	if err := h.suggestionManager.SetQuietHours(ctx, userID, quiet); err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to set quiet hours: %w", err))
+	}
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandQuietHours, isAdmin, map[string]interface{}{
+		"chat_id": chatID,
+		"value":   value,
+	})
+	if quiet == nil {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgQuietHoursOff", nil, nil))
+	}
+	return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgQuietHoursSet", map[string]interface{}{
+		"Hours": quiet.String(),
+		"Zone":  zone,
+	}, nil))
+}
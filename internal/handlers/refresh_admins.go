@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+)
+
+// HandleRefreshAdmins handles the /refreshadmins command (admin only).
+// It reloads the channel administrators so promotions and demotions take effect
+// without waiting for the admin cache to expire, and updates the admins' command menus.
+func (h *MessageHandler) HandleRefreshAdmins(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	// Check the caller without the cache, so a freshly promoted admin can refresh
+	h.adminChecker.Invalidate(userID)
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:refreshadmins User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:refreshadmins User:%d] Non-admin user attempted to use /refreshadmins.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+
+	adminIDs, err := h.adminChecker.Refresh(ctx)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to refresh admins: %w", err))
+	}
+	h.updateAdminMenus(ctx, bot, adminIDs)
+	count := len(adminIDs)
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandRefreshAdmins, isAdmin, map[string]interface{}{
+		"chat_id":     chatID,
+		"admin_count": count,
+	})
+
+	msg := locales.GetPluralMessage(localizer, "MsgAdminsRefreshed", count, nil)
+	return h.sendSuccess(ctx, bot, chatID, msg)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+)
+
+// reopenArgs declares the arguments of /reopen.
+var reopenArgs = cmdargs.Spec{
+	Command: "reopen",
+	Args: []cmdargs.Arg{
+		{Name: "code", Required: true},
+	},
+}
+
+// HandleReopen handles the /reopen <code> command (admin only).
+// It puts a rejected or expired suggestion back in the review queue, e.g. after an accidental rejection.
+func (h *MessageHandler) HandleReopen(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:reopen User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:reopen User:%d] Non-admin user attempted to use /reopen.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+
+	args, err := reopenArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	code := args.Arg("code")
+	update := telego.Update{Message: &message}
+	if err := h.suggestionManager.HandleReopenCommand(ctx, update, code); err != nil {
+		// The manager sends user-facing errors itself
+		log.Printf("[Cmd:reopen User:%d] Error from suggestionManager.HandleReopenCommand: %v", userID, err)
+	}
+	h.RecordUserActivity(ctx, message.From, ActionCommandReopen, isAdmin, map[string]interface{}{
+		"chat_id": chatID,
+		"code":    code,
+	})
+	return nil
+}
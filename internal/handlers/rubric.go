@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"unicode"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/engagement"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// rubricArgs declares the arguments of /rubric.
+var rubricArgs = cmdargs.Spec{
+	Command: "rubric",
+	Args: []cmdargs.Arg{
+		{Name: "action", Required: true, Choices: []string{"list", "add", "delete", "use", "counter", "react", "prompt"}},
+		{Name: "name"},
+		{Name: "value", Rest: true},
+	},
+}
+
+// rubricOff is the /rubric use argument that stops numbering admin posts.
+const rubricOff = "off"
+
+// SetRubricRepository sets where rubrics are stored. Without it, /rubric is unavailable.
+func (h *MessageHandler) SetRubricRepository(repo database.RubricRepository) {
+	h.rubricRepo = repo
+}
+
+// SetEngagementPrompter sets what reacts to and comments under the posts of rubrics set up with
+// /rubric react and /rubric prompt. Without it, posts get neither.
+func (h *MessageHandler) SetEngagementPrompter(prompter *engagement.Prompter) {
+	h.engagement = prompter
+}
+
+// HandleRubric handles the /rubric command (admin only):
+//
+//	/rubric list                      — list the rubrics
+//	/rubric add <name> [template]     — add a rubric; the template must contain {number}
+//	/rubric delete <name>             — delete a rubric
+//	/rubric use <name|off>            — number the posts sent in this chat in the rubric
+//	/rubric counter <name> <number>   — set the number of the last post, to continue a series
+//	/rubric react <name> <emoji|off>  — react to each post with an emoji readers can tap
+//	/rubric prompt <name> <text|off>  — comment under each post in the discussion group
+func (h *MessageHandler) HandleRubric(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:rubric User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:rubric User:%d] Non-admin user attempted to use /rubric.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	if h.rubricRepo == nil {
+		return h.sendError(ctx, bot, chatID, errors.New("rubric repository is not configured"))
+	}
+
+	args, err := rubricArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	action := args.Arg("action")
+	rawName, value := args.Arg("name"), args.Arg("value")
+	if action != "list" && rawName == "" {
+		return h.sendUsageError(ctx, bot, chatID, localizer, &cmdargs.UsageError{
+			MessageID: "MsgArgsMissing",
+			Data:      map[string]interface{}{"Arg": "name", "Usage": rubricArgs.Usage()},
+		})
+	}
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandRubric, isAdmin, map[string]interface{}{
+		"chat_id": chatID,
+		"action":  action,
+		"rubric":  rawName,
+	})
+
+	if action == "list" {
+		return h.listRubrics(ctx, bot, localizer, chatID)
+	}
+	if action == "use" && strings.EqualFold(rawName, rubricOff) {
+		h.activeRubrics.Delete(chatID)
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgRubricUseOff", nil, nil))
+	}
+
+	name := normalizeRubricName(rawName)
+	if name == "" {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgRubricInvalidName", map[string]interface{}{
+			"Name":      rawName,
+			"MaxLength": models.MaxRubricNameLength,
+		}, nil))
+	}
+	notFound := locales.GetMessage(localizer, "MsgRubricNotFound", map[string]interface{}{"Name": name}, nil)
+
+	switch action {
+	case "add":
+		template := value
+		if template == "" {
+			template = "#" + name + " No." + models.RubricNumberPlaceholder
+		}
+		if !strings.Contains(template, models.RubricNumberPlaceholder) {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgRubricTemplateInvalid", map[string]interface{}{
+				"Placeholder": models.RubricNumberPlaceholder,
+			}, nil))
+		}
+		err := h.rubricRepo.CreateRubric(ctx, &models.Rubric{Name: name, Template: template, CreatedBy: userID})
+		if errors.Is(err, database.ErrRubricExists) {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgRubricExists", map[string]interface{}{"Name": name}, nil))
+		}
+		if err != nil {
+			return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to add rubric: %w", err))
+		}
+		log.Printf("[Cmd:rubric User:%d] Added rubric %s with template %q", userID, name, template)
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgRubricAdded", map[string]interface{}{
+			"Name":    name,
+			"Example": models.Rubric{Template: template}.Caption(1),
+		}, nil))
+
+	case "delete":
+		err := h.rubricRepo.DeleteRubric(ctx, name)
+		if errors.Is(err, database.ErrRubricNotFound) {
+			return h.sendSuccess(ctx, bot, chatID, notFound)
+		}
+		if err != nil {
+			return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to delete rubric: %w", err))
+		}
+		h.activeRubrics.Range(func(key, active any) bool {
+			if active == name {
+				h.activeRubrics.Delete(key)
+			}
+			return true
+		})
+		log.Printf("[Cmd:rubric User:%d] Deleted rubric %s", userID, name)
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgRubricDeleted", map[string]interface{}{"Name": name}, nil))
+
+	case "use":
+		rubric, err := h.rubricRepo.GetRubric(ctx, name)
+		if err != nil {
+			return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to get rubric: %w", err))
+		}
+		if rubric == nil {
+			return h.sendSuccess(ctx, bot, chatID, notFound)
+		}
+		h.activeRubrics.Store(chatID, name)
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgRubricUseOn", map[string]interface{}{
+			"Name":    name,
+			"Example": rubric.Caption(rubric.Counter + 1),
+		}, nil))
+
+	case "counter":
+		counter, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || counter < 0 {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgRubricInvalidCounter", nil, nil))
+		}
+		err = h.rubricRepo.SetRubricCounter(ctx, name, counter)
+		if errors.Is(err, database.ErrRubricNotFound) {
+			return h.sendSuccess(ctx, bot, chatID, notFound)
+		}
+		if err != nil {
+			return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to set rubric counter: %w", err))
+		}
+		log.Printf("[Cmd:rubric User:%d] Set counter of rubric %s to %d", userID, name, counter)
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgRubricCounterSet", map[string]interface{}{
+			"Name": name,
+			"Next": counter + 1,
+		}, nil))
+
+	case "react", "prompt":
+		if value == "" {
+			return h.sendUsageError(ctx, bot, chatID, localizer, &cmdargs.UsageError{
+				MessageID: "MsgArgsMissing",
+				Data:      map[string]interface{}{"Arg": "value", "Usage": rubricArgs.Usage()},
+			})
+		}
+		if strings.EqualFold(value, rubricOff) {
+			value = ""
+		}
+		setter, messageID := h.rubricRepo.SetRubricPrompt, "MsgRubricPromptSet"
+		if action == "react" {
+			if strings.ContainsAny(value, " \t\n") {
+				return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgRubricInvalidReaction", nil, nil))
+			}
+			setter, messageID = h.rubricRepo.SetRubricReaction, "MsgRubricReactionSet"
+		}
+		if value == "" {
+			messageID += "Off"
+		}
+		err := setter(ctx, name, value)
+		if errors.Is(err, database.ErrRubricNotFound) {
+			return h.sendSuccess(ctx, bot, chatID, notFound)
+		}
+		if err != nil {
+			return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to set %s of rubric: %w", action, err))
+		}
+		log.Printf("[Cmd:rubric User:%d] Set %s of rubric %s to %q", userID, action, name, value)
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, messageID, map[string]interface{}{
+			"Name":  name,
+			"Value": value,
+		}, nil))
+	}
+	return nil
+}
+
+// PromptRubricPost reacts to and comments under a channel post sent from the chat, as set up for
+// the chat's active rubric. It is meant to be called once the post is published.
+func (h *MessageHandler) PromptRubricPost(ctx context.Context, chatID int64, postID int) {
+	if active, ok := h.activeRubrics.Load(chatID); ok {
+		h.engagement.PromptPost(ctx, postID, active.(string))
+	}
+}
+
+// HandleAutomaticForward comments under the discussion group copy of a channel post, if its
+// rubric asks for a comment. It reports whether message was such a copy.
+func (h *MessageHandler) HandleAutomaticForward(ctx context.Context, message telego.Message) (bool, error) {
+	return h.engagement.HandleAutomaticForward(ctx, message)
+}
+
+// listRubrics sends the rubrics with their last numbers, marking the one active in the chat.
+func (h *MessageHandler) listRubrics(ctx context.Context, bot telegoapi.BotAPI, localizer *i18n.Localizer, chatID int64) error {
+	rubrics, err := h.rubricRepo.ListRubrics(ctx)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to list rubrics: %w", err))
+	}
+	if len(rubrics) == 0 {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgRubricsNone", nil, nil))
+	}
+	active, _ := h.activeRubrics.Load(chatID)
+
+	var text strings.Builder
+	text.WriteString(locales.GetMessage(localizer, "MsgRubricsHeader", nil, nil))
+	for _, rubric := range rubrics {
+		text.WriteString("\n")
+		text.WriteString(locales.GetMessage(localizer, "MsgRubricsLine", map[string]interface{}{
+			"Name":     rubric.Name,
+			"Template": rubric.Template,
+			"Counter":  rubric.Counter,
+		}, nil))
+		if rubric.Reaction != "" {
+			text.WriteString(" ")
+			text.WriteString(locales.GetMessage(localizer, "MsgRubricsReaction", map[string]interface{}{"Emoji": rubric.Reaction}, nil))
+		}
+		if rubric.Prompt != "" {
+			text.WriteString(" ")
+			text.WriteString(locales.GetMessage(localizer, "MsgRubricsPrompt", nil, nil))
+		}
+		if active == rubric.Name {
+			text.WriteString(" ")
+			text.WriteString(locales.GetMessage(localizer, "MsgRubricsActive", nil, nil))
+		}
+	}
+	return h.sendSuccess(ctx, bot, chatID, text.String())
+}
+
+// normalizeRubricName lowercases a rubric name and strips a leading "#". It returns "" if the
+// name contains anything but letters, digits and underscores or is too long.
+func normalizeRubricName(name string) string {
+	name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "#"))
+	if name == "" || len(name) > models.MaxRubricNameLength {
+		return ""
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return ""
+		}
+	}
+	return name
+}
+
+// RubricCaption returns a function that builds the caption of an admin post sent in the chat:
+// the numbered caption of the chat's active rubric followed by the given caption. It is meant to
+// be called in the publish job, so posts are numbered in publication order. The number is taken
+// on the first call only, so a retried job keeps it.
+func (h *MessageHandler) RubricCaption(chatID int64, caption string) func(ctx context.Context) (string, error) {
+	active, ok := h.activeRubrics.Load(chatID)
+	if !ok || h.rubricRepo == nil {
+		return func(context.Context) (string, error) { return caption, nil }
+	}
+	name := active.(string)
+	numbered := false
+	return func(ctx context.Context) (string, error) {
+		if numbered {
+			return caption, nil
+		}
+		rubric, err := h.rubricRepo.NextRubricNumber(ctx, name)
+		if errors.Is(err, database.ErrRubricNotFound) {
+			log.Printf("[Rubric Chat:%d] Active rubric %s was deleted, publishing without it", chatID, name)
+			numbered = true
+			return caption, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to number post in rubric %s: %w", name, err)
+		}
+		numbered = true
+		if caption == "" {
+			caption = rubric.Caption(rubric.Counter)
+		} else {
+			caption = rubric.Caption(rubric.Counter) + "\n\n" + caption
+		}
+		return caption, nil
+	}
+}
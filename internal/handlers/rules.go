@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"context"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+)
+
+// HandleRules handles the /rules command.
+// It shows the submission rules, with a link to the full rules if RULES_URL is set.
+func (h *MessageHandler) HandleRules(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, _ := h.adminChecker.IsAdmin(ctx, message.From.ID)
+	h.RecordUserActivity(ctx, message.From, ActionCommandRules, isAdmin, map[string]interface{}{
+		"chat_id": chatID,
+	})
+
+	rules := locales.GetMessage(localizer, "MsgRules", nil, nil)
+	if h.rulesURL != "" {
+		rules += "\n\n" + locales.GetMessage(localizer, "MsgRulesFullLink", map[string]interface{}{"URL": h.rulesURL}, nil)
+	}
+	return h.sendSuccess(ctx, bot, chatID, rules)
+}
@@ -0,0 +1,440 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/channelrights"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// setupCallbackPrefix starts the callback data of the /setup wizard buttons: setup:<step>,
+// setup:recheck, setup:lang:<code>, setup:toggle:<suggesters|forward> and setup:testpost.
+const setupCallbackPrefix = "setup:"
+
+// Steps of the /setup wizard, in order.
+const (
+	setupStepChannel  = "channel"
+	setupStepLanguage = "language"
+	setupStepCredit   = "credit"
+	setupStepTest     = "test"
+	setupStepDone     = "done"
+)
+
+// setupSteps lists the steps of the /setup wizard that have a number.
+var setupSteps = []string{setupStepChannel, setupStepLanguage, setupStepCredit, setupStepTest}
+
+// SetChannelRights sets the checker of the bot's rights in the channel, which /setup rechecks.
+// Without it, /setup looks the rights up itself.
+func (h *MessageHandler) SetChannelRights(checker *channelrights.Checker) {
+	h.channelRights = checker
+}
+
+// SetCreditDefaults sets whether suggesters and forward sources are credited when /setup hasn't
+// chosen otherwise, i.e. the CREDIT_SUGGESTERS and CREDIT_FORWARD_SOURCE variables.
+func (h *MessageHandler) SetCreditDefaults(suggesters, forwardSource bool) {
+	h.creditSuggesters = suggesters
+	h.creditForwardSource = forwardSource
+}
+
+// SetProtectContentDefault sets whether posts are published with protected content, which channel
+// members can't forward or save, when /setup hasn't chosen otherwise, i.e. the PROTECT_CONTENT variable.
+func (h *MessageHandler) SetProtectContentDefault(enabled bool) {
+	h.protectContent = enabled
+}
+
+// SetDefaultLanguageShared makes /setup show the default language without letting it be changed.
+// Channels connected in multi-tenant mode share the default language of the primary channel.
+func (h *MessageHandler) SetDefaultLanguageShared(shared bool) {
+	h.defaultLanguageShared = shared
+}
+
+// ApplySetupSettings applies the default language and credit settings chosen in /setup, the
+// suggestion content types chosen with /contenttypes and the rules set with /autoreject, which
+// take precedence over the environment. It is called once the settings repository is set.
+func (h *MessageHandler) ApplySetupSettings(ctx context.Context) {
+	if h.settingsRepo == nil {
+		return
+	}
+	setting, err := h.settingsRepo.GetSetting(ctx, models.SettingDefaultLanguage)
+	if h.defaultLanguageShared {
+		setting, err = nil, nil
+	}
+	if err != nil {
+		log.Printf("[Setup] Error loading the default language: %v", err)
+	} else if setting != nil {
+		if err := locales.SetDefaultLanguage(setting.Value); err != nil {
+			log.Printf("[Setup] Ignoring the stored default language: %v", err)
+		}
+	}
+	h.suggestionManager.SetCreditSuggesters(h.boolSetting(ctx, models.SettingCreditSuggesters, h.creditSuggesters))
+	h.suggestionManager.SetCreditForwardSource(h.boolSetting(ctx, models.SettingCreditForwardSource, h.creditForwardSource))
+	h.suggestionManager.SetProtectContent(h.boolSetting(ctx, models.SettingProtectContent, h.protectContent))
+	h.suggestionManager.SetContentPolicy(h.contentPolicySetting(ctx))
+	h.suggestionManager.SetAutoRejectRules(h.autoRejectRulesSetting(ctx))
+}
+
+// ProtectContent reports whether posts are published with protected content, as chosen in
+// /setup or else by PROTECT_CONTENT.
+func (h *MessageHandler) ProtectContent(ctx context.Context) bool {
+	if h.settingsRepo == nil {
+		return h.protectContent
+	}
+	return h.boolSetting(ctx, models.SettingProtectContent, h.protectContent)
+}
+
+// boolSetting returns a "true"/"false" setting, or def if it is not set or can't be loaded.
+func (h *MessageHandler) boolSetting(ctx context.Context, key string, def bool) bool {
+	setting, err := h.settingsRepo.GetSetting(ctx, key)
+	if err != nil {
+		log.Printf("[Setup] Error loading setting %s: %v", key, err)
+		return def
+	}
+	if setting == nil {
+		return def
+	}
+	value, err := strconv.ParseBool(setting.Value)
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// HandleSetup handles the /setup command (super admins only).
+// It starts a wizard that checks the bot's rights in the channel, then lets the owner choose the
+// default language and the credit settings and post a test message. Choices are stored as
+// settings, so they don't require changing the environment.
+func (h *MessageHandler) HandleSetup(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	if !h.adminChecker.IsSuperAdmin(userID) {
+		log.Printf("[Cmd:setup User:%d] Non-super-admin user attempted to use /setup.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresSuperAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	if h.settingsRepo == nil {
+		return h.sendError(ctx, bot, chatID, errors.New("settings repository is not configured"))
+	}
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandSetup, true, map[string]interface{}{
+		"chat_id": chatID,
+	})
+
+	result, err := h.checkSetupChannel(ctx, bot, userID)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, err)
+	}
+	text, keyboard := h.setupStep(ctx, localizer, setupStepChannel, result)
+	_, err = bot.SendMessage(ctx, tu.Message(tu.ID(chatID), text).WithReplyMarkup(keyboard))
+	return err
+}
+
+// handleSetupCallback handles the buttons of the /setup wizard and moves the wizard message to
+// the step they lead to.
+func (h *MessageHandler) handleSetupCallback(ctx context.Context, bot telegoapi.BotAPI, query telego.CallbackQuery) error {
+	userID := query.From.ID
+	localizer := h.getLocalizer(&query.From)
+	answer := func(text string) {
+		_ = bot.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID, Text: text, ShowAlert: text != ""})
+	}
+
+	if !h.adminChecker.IsSuperAdmin(userID) {
+		log.Printf("[SetupCallback User:%d] Non-super-admin user pressed a /setup button.", userID)
+		answer(locales.GetMessage(localizer, "MsgErrorRequiresSuperAdmin", nil, nil))
+		return nil
+	}
+	if h.settingsRepo == nil || query.Message == nil {
+		answer(locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil))
+		return errors.New("setup callback without settings repository or message")
+	}
+
+	action, arg, _ := strings.Cut(strings.TrimPrefix(query.Data, setupCallbackPrefix), ":")
+	step := action
+	var err error
+	switch action {
+	case "recheck":
+		step = setupStepChannel
+	case "lang":
+		step = setupStepLanguage
+		if !locales.IsSupported(arg) || h.defaultLanguageShared {
+			answer(locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil))
+			return fmt.Errorf("unsupported language in setup callback data %q", query.Data)
+		}
+		err = h.setSetupLanguage(ctx, arg, userID)
+		localizer = h.getLocalizer(&query.From) // Answer in the new default language
+	case "toggle":
+		step = setupStepCredit
+		err = h.toggleSetupCredit(ctx, arg, userID)
+	case "testpost":
+		step = setupStepTest
+		err = h.postSetupTestMessage(ctx, bot, localizer, userID)
+	case setupStepChannel, setupStepLanguage, setupStepCredit, setupStepTest:
+	case setupStepDone:
+		err = h.completeSetup(ctx, userID)
+	default:
+		answer(locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil))
+		return fmt.Errorf("malformed setup callback data %q", query.Data)
+	}
+	if err != nil {
+		answer(locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil))
+		return err
+	}
+
+	var result models.SetupResult
+	if action == "recheck" {
+		result, err = h.checkSetupChannel(ctx, bot, userID)
+	} else {
+		result, err = h.loadSetupResult(ctx)
+	}
+	if err != nil {
+		answer(locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil))
+		return err
+	}
+	answer("")
+	log.Printf("[SetupCallback User:%d] %s, showing step %s", userID, query.Data, step)
+
+	text, keyboard := h.setupStep(ctx, localizer, step, result)
+	_, err = bot.EditMessageText(ctx, &telego.EditMessageTextParams{
+		ChatID:      tu.ID(query.Message.GetChat().ID),
+		MessageID:   query.Message.GetMessageID(),
+		Text:        text,
+		ReplyMarkup: keyboard,
+	})
+	if err != nil {
+		log.Printf("[SetupCallback User:%d] Error updating the setup message: %v", userID, err)
+	}
+	return nil
+}
+
+// checkSetupChannel looks up the bot's rights in the channel, bypassing the cache, and stores them
+// in the setup result.
+func (h *MessageHandler) checkSetupChannel(ctx context.Context, bot telegoapi.BotAPI, userID int64) (models.SetupResult, error) {
+	result, err := h.loadSetupResult(ctx)
+	if err != nil {
+		return result, err
+	}
+	checker := h.channelRights
+	if checker == nil {
+		checker = channelrights.NewChecker(bot, h.channelID, 0)
+	}
+	checker.Invalidate()
+	rights, err := checker.Rights(ctx)
+	if err != nil {
+		// Shown as unknown; the owner can check again
+		log.Printf("[Setup User:%d] Error checking the bot's rights in channel %d: %v", userID, h.channelID, err)
+		rights = channelrights.Rights{}
+	}
+	result.BotStatus, result.CanPost, result.CanDelete = rights.Status, rights.CanPost, rights.CanDelete
+	result.CheckedAt = time.Now()
+	return result, h.saveSetupResult(ctx, result, userID)
+}
+
+// setSetupLanguage stores and applies the default language picked in /setup.
+func (h *MessageHandler) setSetupLanguage(ctx context.Context, lang string, userID int64) error {
+	lang = locales.ResolveLanguage(lang)
+	if err := h.settingsRepo.SetSetting(ctx, models.SettingDefaultLanguage, lang, userID); err != nil {
+		return err
+	}
+	log.Printf("[Setup User:%d] Default language set to %s", userID, lang)
+	return locales.SetDefaultLanguage(lang)
+}
+
+// toggleSetupCredit flips one of the settings of the credit step, "suggesters", "forward" or
+// "protect", and applies it.
+func (h *MessageHandler) toggleSetupCredit(ctx context.Context, which string, userID int64) error {
+	key, def, apply := models.SettingCreditSuggesters, h.creditSuggesters, h.suggestionManager.SetCreditSuggesters
+	switch which {
+	case "suggesters":
+	case "forward":
+		key, def, apply = models.SettingCreditForwardSource, h.creditForwardSource, h.suggestionManager.SetCreditForwardSource
+	case "protect":
+		key, def, apply = models.SettingProtectContent, h.protectContent, h.suggestionManager.SetProtectContent
+	default:
+		return fmt.Errorf("unknown credit setting %q", which)
+	}
+	enabled := !h.boolSetting(ctx, key, def)
+	if err := h.settingsRepo.SetSetting(ctx, key, strconv.FormatBool(enabled), userID); err != nil {
+		return err
+	}
+	apply(enabled)
+	log.Printf("[Setup User:%d] %s set to %t", userID, key, enabled)
+	return nil
+}
+
+// postSetupTestMessage posts the test message of /setup to the channel and records the outcome.
+// A failure is recorded rather than returned, so the wizard can show it.
+func (h *MessageHandler) postSetupTestMessage(ctx context.Context, bot telegoapi.BotAPI, localizer *i18n.Localizer, userID int64) error {
+	result, err := h.loadSetupResult(ctx)
+	if err != nil {
+		return err
+	}
+	text := locales.GetMessage(localizer, "MsgSetupTestPost", nil, nil)
+	msg, err := bot.SendMessage(ctx, tu.Message(tu.ID(h.channelID), text))
+	if err != nil {
+		log.Printf("[Setup User:%d] Error posting the test message to channel %d: %v", userID, h.channelID, err)
+		result.TestMessageID, result.TestError = 0, err.Error()
+	} else {
+		log.Printf("[Setup User:%d] Posted test message %d to channel %d", userID, msg.MessageID, h.channelID)
+		result.TestMessageID, result.TestError = msg.MessageID, ""
+	}
+	return h.saveSetupResult(ctx, result, userID)
+}
+
+// completeSetup records that the owner went through the wizard.
+func (h *MessageHandler) completeSetup(ctx context.Context, userID int64) error {
+	result, err := h.loadSetupResult(ctx)
+	if err != nil {
+		return err
+	}
+	result.CompletedBy, result.CompletedAt = userID, time.Now()
+	log.Printf("[Setup User:%d] Setup completed", userID)
+	return h.saveSetupResult(ctx, result, userID)
+}
+
+// loadSetupResult returns the stored setup result, or an empty one if /setup never ran.
+func (h *MessageHandler) loadSetupResult(ctx context.Context) (models.SetupResult, error) {
+	var result models.SetupResult
+	setting, err := h.settingsRepo.GetSetting(ctx, models.SettingSetup)
+	if err != nil || setting == nil {
+		return result, err
+	}
+	if err := json.Unmarshal([]byte(setting.Value), &result); err != nil {
+		log.Printf("[Setup] Ignoring malformed setup result: %v", err)
+		return models.SetupResult{}, nil
+	}
+	return result, nil
+}
+
+// saveSetupResult stores the setup result.
+func (h *MessageHandler) saveSetupResult(ctx context.Context, result models.SetupResult, userID int64) error {
+	value, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode setup result: %w", err)
+	}
+	return h.settingsRepo.SetSetting(ctx, models.SettingSetup, string(value), userID)
+}
+
+// setupStep returns the text and buttons of a step of the /setup wizard.
+func (h *MessageHandler) setupStep(ctx context.Context, localizer *i18n.Localizer, step string, result models.SetupResult) (string, *telego.InlineKeyboardMarkup) {
+	yesNo := func(ok bool) string {
+		if ok {
+			return "✅"
+		}
+		return "❌"
+	}
+	button := func(messageID string, data string, templateData map[string]interface{}) telego.InlineKeyboardButton {
+		return tu.InlineKeyboardButton(locales.GetMessage(localizer, messageID, templateData, nil)).WithCallbackData(setupCallbackPrefix + data)
+	}
+	var text strings.Builder
+	for i, s := range setupSteps {
+		if s == step {
+			text.WriteString(locales.GetMessage(localizer, "MsgSetupStep", map[string]interface{}{
+				"Step":  i + 1,
+				"Total": len(setupSteps),
+			}, nil))
+			text.WriteString("\n\n")
+		}
+	}
+
+	var rows [][]telego.InlineKeyboardButton
+	switch step {
+	case setupStepChannel:
+		status := result.BotStatus
+		if status == "" {
+			status = "?"
+		}
+		text.WriteString(locales.GetMessage(localizer, "MsgSetupChannel", map[string]interface{}{
+			"ChannelID": h.channelID,
+			"Status":    status,
+			"CanPost":   yesNo(result.CanPost),
+			"CanDelete": yesNo(result.CanDelete),
+		}, nil))
+		if !result.CanPost {
+			text.WriteString("\n\n")
+			text.WriteString(locales.GetMessage(localizer, "MsgSetupChannelNoRights", nil, nil))
+		}
+		rows = append(rows, tu.InlineKeyboardRow(
+			button("BtnSetupRecheck", "recheck", nil),
+			button("BtnSetupNext", setupStepLanguage, nil),
+		))
+	case setupStepLanguage:
+		current := locales.GetDefaultLanguageTag().String()
+		if h.defaultLanguageShared {
+			text.WriteString(locales.GetMessage(localizer, "MsgSetupLanguageShared", map[string]interface{}{"Language": current}, nil))
+			rows = append(rows, tu.InlineKeyboardRow(
+				button("BtnSetupBack", setupStepChannel, nil),
+				button("BtnSetupNext", setupStepCredit, nil),
+			))
+			break
+		}
+		text.WriteString(locales.GetMessage(localizer, "MsgSetupLanguage", map[string]interface{}{"Language": current}, nil))
+		var languages []telego.InlineKeyboardButton
+		for _, lang := range locales.SupportedLanguages() {
+			label := lang
+			if lang == current {
+				label = "✅ " + lang
+			}
+			languages = append(languages, tu.InlineKeyboardButton(label).WithCallbackData(setupCallbackPrefix+"lang:"+lang))
+		}
+		rows = append(rows, languages, tu.InlineKeyboardRow(
+			button("BtnSetupBack", setupStepChannel, nil),
+			button("BtnSetupNext", setupStepCredit, nil),
+		))
+	case setupStepCredit:
+		text.WriteString(locales.GetMessage(localizer, "MsgSetupCredit", nil, nil))
+		rows = append(rows,
+			tu.InlineKeyboardRow(button("BtnSetupCreditSuggesters", "toggle:suggesters", map[string]interface{}{
+				"State": yesNo(h.boolSetting(ctx, models.SettingCreditSuggesters, h.creditSuggesters)),
+			})),
+			tu.InlineKeyboardRow(button("BtnSetupCreditForward", "toggle:forward", map[string]interface{}{
+				"State": yesNo(h.boolSetting(ctx, models.SettingCreditForwardSource, h.creditForwardSource)),
+			})),
+			tu.InlineKeyboardRow(button("BtnSetupProtectContent", "toggle:protect", map[string]interface{}{
+				"State": yesNo(h.boolSetting(ctx, models.SettingProtectContent, h.protectContent)),
+			})),
+			tu.InlineKeyboardRow(
+				button("BtnSetupBack", setupStepLanguage, nil),
+				button("BtnSetupNext", setupStepTest, nil),
+			))
+	case setupStepTest:
+		text.WriteString(locales.GetMessage(localizer, "MsgSetupTest", nil, nil))
+		switch {
+		case result.TestError != "":
+			text.WriteString("\n\n")
+			text.WriteString(locales.GetMessage(localizer, "MsgSetupTestFailed", map[string]interface{}{"Error": result.TestError}, nil))
+		case result.TestMessageID != 0:
+			text.WriteString("\n\n")
+			text.WriteString(locales.GetMessage(localizer, "MsgSetupTestPosted", map[string]interface{}{"MessageID": result.TestMessageID}, nil))
+		}
+		rows = append(rows,
+			tu.InlineKeyboardRow(button("BtnSetupTestPost", "testpost", nil)),
+			tu.InlineKeyboardRow(
+				button("BtnSetupBack", setupStepCredit, nil),
+				button("BtnSetupFinish", setupStepDone, nil),
+			))
+	default: // setupStepDone
+		text.WriteString(locales.GetMessage(localizer, "MsgSetupDone", map[string]interface{}{
+			"CanPost":       yesNo(result.CanPost),
+			"Language":      locales.GetDefaultLanguageTag().String(),
+			"CreditUsers":   yesNo(h.boolSetting(ctx, models.SettingCreditSuggesters, h.creditSuggesters)),
+			"CreditForward": yesNo(h.boolSetting(ctx, models.SettingCreditForwardSource, h.creditForwardSource)),
+			"Protect":       yesNo(h.boolSetting(ctx, models.SettingProtectContent, h.protectContent)),
+			"TestPost":      yesNo(result.TestMessageID != 0),
+		}, nil))
+		return text.String(), nil
+	}
+	return text.String(), tu.InlineKeyboard(rows...)
+}
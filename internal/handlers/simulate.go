@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/simulation"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+)
+
+// simulateArgs declares the arguments of /simulate.
+var simulateArgs = cmdargs.Spec{
+	Command: "simulate",
+	Args: []cmdargs.Arg{
+		{Name: "flow", Required: true, Choices: []string{"suggestion"}},
+		{Name: "caption", Rest: true},
+	},
+}
+
+// SetSimulator sets the simulator /simulate injects updates from the test user with.
+func (h *MessageHandler) SetSimulator(simulator *simulation.Simulator) {
+	h.simulator = simulator
+}
+
+// HandleSimulate handles the /simulate suggestion [caption] command (super admins only), sent
+// as a reply to a photo. The test user suggests the photo like a real user would, so the flow can
+// be checked in production: what the bot sends the test user is shown in this chat, and the
+// suggestion reaches the review queue as a test record that is never published.
+func (h *MessageHandler) HandleSimulate(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	if !h.adminChecker.IsSuperAdmin(userID) {
+		log.Printf("[Cmd:simulate User:%d] Non-super-admin user attempted to use /simulate.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresSuperAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	if h.simulator == nil {
+		return h.sendError(ctx, bot, chatID, errors.New("simulator is not configured"))
+	}
+
+	args, err := simulateArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	reply := message.ReplyToMessage
+	if reply == nil || len(reply.Photo) == 0 {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgSimulateNeedsPhoto", nil, nil))
+	}
+	fileID := reply.Photo[len(reply.Photo)-1].FileID
+
+	log.Printf("[Cmd:simulate User:%d] Simulating a suggestion from the test user", userID)
+	if err := h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgSimulateStarted", nil, nil)); err != nil {
+		return err
+	}
+	h.simulator.Suggestion(ctx, chatID, fileID, args.Arg("caption"))
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandSimulate, true, map[string]interface{}{
+		"chat_id": chatID,
+		"flow":    args.Arg("flow"),
+	})
+	return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgSimulateFinished", nil, nil))
+}
+
+// HandlePurgeTest handles the /purgetest command (super admins only).
+// It deletes the test suggestions of /simulate and the test user's profile and logged actions.
+func (h *MessageHandler) HandlePurgeTest(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	if !h.adminChecker.IsSuperAdmin(userID) {
+		log.Printf("[Cmd:purgetest User:%d] Non-super-admin user attempted to use /purgetest.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresSuperAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+
+	deleted, err := h.suggestionManager.PurgeTestSuggestions(ctx)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to delete test suggestions: %w", err))
+	}
+	if err := h.userRepo.DeleteUser(ctx, simulation.TestUserID); err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to delete the test user: %w", err))
+	}
+	log.Printf("[Cmd:purgetest User:%d] Deleted %d test suggestions and the test user", userID, deleted)
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandPurgeTest, true, map[string]interface{}{
+		"chat_id": chatID,
+		"deleted": deleted,
+	})
+	return h.sendSuccess(ctx, bot, chatID, locales.GetPluralMessage(localizer, "MsgPurgeTestDone", int(deleted), nil))
+}
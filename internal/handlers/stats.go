@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/format"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+	"vrcmemes-bot/pkg/utils"
+
+	"github.com/mymmrac/telego"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// defaultStatsDays is the period /stats covers when no days are given.
+	defaultStatsDays = 30
+	// maxStatsDays bounds the period /stats covers.
+	maxStatsDays = 365
+)
+
+// statsArgs declares the arguments of /stats.
+var statsArgs = cmdargs.Spec{
+	Command: "stats",
+	Args: []cmdargs.Arg{
+		{Name: "report", Required: true, Choices: []string{"tags", "links", "sources"}},
+		{Name: "days"},
+	},
+}
+
+// SetStatsRepository sets where /stats reads its reports from. Without it, /stats is unavailable.
+func (h *MessageHandler) SetStatsRepository(repo database.StatsRepository) {
+	h.statsRepo = repo
+}
+
+// HandleStats handles the /stats <report> [days] command (admin only).
+// The "tags" report counts the tags of suggestions approved in the last days (30 by default),
+// counting today as the first day in the channel time zone. The "links" report counts, per
+// source, the users who opened a "Suggest a meme" deep link and the suggestions they sent. The
+// "sources" report breaks the posts published to the channel down by origin.
+func (h *MessageHandler) HandleStats(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:stats User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:stats User:%d] Non-admin user attempted to use /stats.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	if h.statsRepo == nil {
+		return h.sendError(ctx, bot, chatID, errors.New("stats repository is not configured"))
+	}
+
+	args, err := statsArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	days := defaultStatsDays
+	if raw := args.Arg("days"); raw != "" {
+		days, err = strconv.Atoi(raw)
+		if err != nil || days < 1 || days > maxStatsDays {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgStatsInvalidDays", map[string]interface{}{
+				"Max": maxStatsDays,
+			}, nil))
+		}
+	}
+	// Whole days in the channel time zone: today and the days before it
+	since := utils.StartOfDay(time.Now(), h.location).AddDate(0, 0, 1-days)
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandStats, isAdmin, map[string]interface{}{
+		"chat_id": chatID,
+		"report":  args.Arg("report"),
+		"days":    days,
+	})
+	switch args.Arg("report") {
+	case "links":
+		return h.sendSuggestSourcesReport(ctx, bot, chatID, localizer, since, days)
+	case "sources":
+		return h.sendPostOriginsReport(ctx, bot, chatID, localizer, since, days)
+	}
+
+	counts, err := h.statsRepo.CountSuggestionTags(ctx, since)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to count suggestion tags: %w", err))
+	}
+
+	if len(counts) == 0 {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgStatsTagsEmpty", map[string]interface{}{
+			"Days": days,
+		}, nil))
+	}
+	var text strings.Builder
+	text.WriteString(locales.GetMessage(localizer, "MsgStatsTagsHeader", map[string]interface{}{
+		"Days": days,
+	}, nil))
+	for _, count := range counts {
+		text.WriteString("\n")
+		text.WriteString(locales.GetMessage(localizer, "MsgStatsTagsLine", map[string]interface{}{
+			"Tag":   count.Tag,
+			"Count": format.Number(localizer, count.Count),
+		}, nil))
+	}
+	return h.sendSuccess(ctx, bot, chatID, text.String())
+}
+
+// sendSuggestSourcesReport sends the "links" report of /stats: per deep-link source, how often
+// the link was opened, by how many users, and how many suggestions followed.
+func (h *MessageHandler) sendSuggestSourcesReport(ctx context.Context, bot telegoapi.BotAPI, chatID int64, localizer *i18n.Localizer, since time.Time, days int) error {
+	counts, err := h.statsRepo.CountSuggestSources(ctx, since, ActionCommandStart)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to count deep-link sources: %w", err))
+	}
+	if len(counts) == 0 {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgStatsLinksEmpty", map[string]interface{}{
+			"Days": days,
+		}, nil))
+	}
+	var text strings.Builder
+	text.WriteString(locales.GetMessage(localizer, "MsgStatsLinksHeader", map[string]interface{}{
+		"Days": days,
+	}, nil))
+	for _, count := range counts {
+		text.WriteString("\n")
+		text.WriteString(locales.GetMessage(localizer, "MsgStatsLinksLine", map[string]interface{}{
+			"Source":      count.Source,
+			"Opens":       format.Number(localizer, count.Opens),
+			"Users":       format.Number(localizer, count.Users),
+			"Suggestions": format.Number(localizer, count.Suggestions),
+		}, nil))
+	}
+	return h.sendSuccess(ctx, bot, chatID, text.String())
+}
+
+// postOriginMessages names the origins of posts in the "sources" report of /stats.
+var postOriginMessages = map[string]string{
+	models.PostOriginDirect:     "MsgPostOriginDirect",
+	models.PostOriginSuggestion: "MsgPostOriginSuggestion",
+	models.PostOriginScheduled:  "MsgPostOriginScheduled",
+}
+
+// sendPostOriginsReport sends the "sources" report of /stats: how many of the posts published
+// in the period the admins sent themselves, came from suggestions or were scheduled.
+func (h *MessageHandler) sendPostOriginsReport(ctx context.Context, bot telegoapi.BotAPI, chatID int64, localizer *i18n.Localizer, since time.Time, days int) error {
+	counts, err := h.statsRepo.CountPostOrigins(ctx, h.channelID, since)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to count posts by origin: %w", err))
+	}
+	var total int64
+	for _, count := range counts {
+		total += count.Count
+	}
+	if total == 0 {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgStatsSourcesEmpty", map[string]interface{}{
+			"Days": days,
+		}, nil))
+	}
+	var text strings.Builder
+	text.WriteString(locales.GetMessage(localizer, "MsgStatsSourcesHeader", map[string]interface{}{
+		"Days":  days,
+		"Total": format.Number(localizer, total),
+	}, nil))
+	for _, count := range counts {
+		origin := count.Origin
+		if messageID, ok := postOriginMessages[origin]; ok {
+			origin = locales.GetMessage(localizer, messageID, nil, nil)
+		}
+		text.WriteString("\n")
+		text.WriteString(locales.GetMessage(localizer, "MsgStatsSourcesLine", map[string]interface{}{
+			"Origin":  origin,
+			"Count":   format.Number(localizer, count.Count),
+			"Percent": count.Count * 100 / total,
+		}, nil))
+	}
+	return h.sendSuccess(ctx, bot, chatID, text.String())
+}
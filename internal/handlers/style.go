@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/customemoji"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// styleArgs declares the arguments of /style.
+var styleArgs = cmdargs.Spec{
+	Command: "style",
+	Args: []cmdargs.Arg{
+		{Name: "action", Required: true, Choices: []string{"list", "add", "delete", "use"}},
+		{Name: "name"},
+		{Name: "template", Rest: true},
+	},
+}
+
+// styleOff is the /style use argument that stops decorating admin posts.
+const styleOff = "off"
+
+// styleExampleCaption is the caption shown in style previews.
+const styleExampleCaption = "…"
+
+// HandleStyle handles the /style command (admin only). Caption styles are presets of caption
+// decorations kept in the settings, so the channel keeps a consistent look:
+//
+//	/style list                  — list the styles
+//	/style add <name> <template> — add or replace a style; the template must contain {caption}
+//	/style delete <name>         — delete a style
+//	/style use <name|off>        — decorate the posts sent in this chat with the style
+//
+// Reviewers pick the style of a suggestion with the "Style" review button.
+func (h *MessageHandler) HandleStyle(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:style User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:style User:%d] Non-admin user attempted to use /style.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	if h.settingsRepo == nil {
+		return h.sendError(ctx, bot, chatID, errors.New("settings repository is not configured"))
+	}
+
+	args, err := styleArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	action := args.Arg("action")
+	rawName, template := args.Arg("name"), strings.TrimSpace(args.Arg("template"))
+	if action != "list" && rawName == "" {
+		return h.sendUsageError(ctx, bot, chatID, localizer, &cmdargs.UsageError{
+			MessageID: "MsgArgsMissing",
+			Data:      map[string]interface{}{"Arg": "name", "Usage": styleArgs.Usage()},
+		})
+	}
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandStyle, isAdmin, map[string]interface{}{
+		"chat_id": chatID,
+		"action":  action,
+		"style":   rawName,
+	})
+
+	if action == "use" && strings.EqualFold(rawName, styleOff) {
+		h.activeStyles.Delete(chatID)
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgStyleUseOff", nil, nil))
+	}
+
+	styles, err := h.captionStyles(ctx)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, err)
+	}
+	if action == "list" {
+		return h.listStyles(ctx, bot, localizer, chatID, styles)
+	}
+
+	// Style names follow the rules of rubric names, so they fit into callback data as well
+	name := normalizeRubricName(rawName)
+	if name == "" || len(name) > models.MaxCaptionStyleNameLength {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgStyleInvalidName", map[string]interface{}{
+			"Name":      rawName,
+			"MaxLength": models.MaxCaptionStyleNameLength,
+		}, nil))
+	}
+	style, exists := models.FindCaptionStyle(styles, name)
+	if !exists && action != "add" {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgStyleNotFound", map[string]interface{}{"Name": name}, nil))
+	}
+
+	switch action {
+	case "add":
+		if !strings.Contains(template, models.CaptionStylePlaceholder) {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgStyleTemplateInvalid", map[string]interface{}{
+				"Placeholder": models.CaptionStylePlaceholder,
+			}, nil))
+		}
+		style = models.CaptionStyle{
+			Name:     name,
+			Template: template,
+			Emoji:    customemoji.Within(customemoji.FromEntities(message.Entities), message.Text, template),
+		}
+		replaced := false
+		for i := range styles {
+			if styles[i].Name == name {
+				styles[i], replaced = style, true
+			}
+		}
+		if !replaced {
+			styles = append(styles, style)
+		}
+		if err := h.saveCaptionStyles(ctx, styles, userID); err != nil {
+			return h.sendError(ctx, bot, chatID, err)
+		}
+		log.Printf("[Cmd:style User:%d] Saved caption style %s with template %q", userID, name, template)
+		msg := locales.GetMessage(localizer, "MsgStyleSaved", map[string]interface{}{
+			"Name":    name,
+			"Example": style.Apply(styleExampleCaption),
+		}, nil)
+		if warning := h.customEmojiWarning(localizer, message.Entities); warning != "" {
+			msg += "\n\n" + warning
+		}
+		return h.sendSuccess(ctx, bot, chatID, msg)
+
+	case "delete":
+		kept := styles[:0]
+		for _, s := range styles {
+			if s.Name != name {
+				kept = append(kept, s)
+			}
+		}
+		if err := h.saveCaptionStyles(ctx, kept, userID); err != nil {
+			return h.sendError(ctx, bot, chatID, err)
+		}
+		h.activeStyles.Range(func(key, active any) bool {
+			if active == name {
+				h.activeStyles.Delete(key)
+			}
+			return true
+		})
+		log.Printf("[Cmd:style User:%d] Deleted caption style %s", userID, name)
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgStyleDeleted", map[string]interface{}{"Name": name}, nil))
+
+	case "use":
+		h.activeStyles.Store(chatID, name)
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgStyleUseOn", map[string]interface{}{
+			"Name":    name,
+			"Example": style.Apply(styleExampleCaption),
+		}, nil))
+	}
+	return nil
+}
+
+// captionStyles returns the caption styles stored in the settings.
+func (h *MessageHandler) captionStyles(ctx context.Context) ([]models.CaptionStyle, error) {
+	setting, err := h.settingsRepo.GetSetting(ctx, models.SettingCaptionStyles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caption styles: %w", err)
+	}
+	return models.DecodeCaptionStyles(setting)
+}
+
+// saveCaptionStyles replaces the caption styles stored in the settings.
+func (h *MessageHandler) saveCaptionStyles(ctx context.Context, styles []models.CaptionStyle, updatedBy int64) error {
+	if len(styles) == 0 {
+		if err := h.settingsRepo.DeleteSetting(ctx, models.SettingCaptionStyles); err != nil {
+			return fmt.Errorf("failed to delete caption styles: %w", err)
+		}
+		return nil
+	}
+	value, err := models.EncodeCaptionStyles(styles)
+	if err != nil {
+		return err
+	}
+	if err := h.settingsRepo.SetSetting(ctx, models.SettingCaptionStyles, value, updatedBy); err != nil {
+		return fmt.Errorf("failed to save caption styles: %w", err)
+	}
+	return nil
+}
+
+// listStyles sends the caption styles with a preview each, marking the one active in the chat.
+func (h *MessageHandler) listStyles(ctx context.Context, bot telegoapi.BotAPI, localizer *i18n.Localizer, chatID int64, styles []models.CaptionStyle) error {
+	if len(styles) == 0 {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgStylesNone", nil, nil))
+	}
+	active, _ := h.activeStyles.Load(chatID)
+
+	var text strings.Builder
+	text.WriteString(locales.GetMessage(localizer, "MsgStylesHeader", nil, nil))
+	for _, style := range styles {
+		text.WriteString("\n\n")
+		text.WriteString(locales.GetMessage(localizer, "MsgStylesLine", map[string]interface{}{
+			"Name":    style.Name,
+			"Example": style.Apply(styleExampleCaption),
+		}, nil))
+		if active == style.Name {
+			text.WriteString(" ")
+			text.WriteString(locales.GetMessage(localizer, "MsgStylesActive", nil, nil))
+		}
+	}
+	return h.sendSuccess(ctx, bot, chatID, text.String())
+}
+
+// StyledCaption wraps a caption builder of an admin post sent in the chat, decorating the caption
+// with the chat's active caption style. If the style was deleted or the styles can't be loaded,
+// the caption is published undecorated.
+func (h *MessageHandler) StyledCaption(chatID int64, buildCaption func(ctx context.Context) (string, error)) func(ctx context.Context) (string, error) {
+	active, ok := h.activeStyles.Load(chatID)
+	if !ok || h.settingsRepo == nil {
+		return buildCaption
+	}
+	name := active.(string)
+	return func(ctx context.Context) (string, error) {
+		caption, err := buildCaption(ctx)
+		if err != nil {
+			return "", err
+		}
+		styles, err := h.captionStyles(ctx)
+		if err != nil {
+			log.Printf("[Style Chat:%d] Error loading caption styles, publishing without style: %v", chatID, err)
+			return caption, nil
+		}
+		style, ok := models.FindCaptionStyle(styles, name)
+		if !ok {
+			log.Printf("[Style Chat:%d] Active caption style %s was deleted, publishing without it", chatID, name)
+			return caption, nil
+		}
+		return style.Apply(caption), nil
+	}
+}
+
+// activeStyle returns the caption style admin posts sent in the chat are decorated with, or false
+// if there is none or the styles can't be loaded.
+func (h *MessageHandler) activeStyle(ctx context.Context, chatID int64) (models.CaptionStyle, bool) {
+	active, ok := h.activeStyles.Load(chatID)
+	if !ok || h.settingsRepo == nil {
+		return models.CaptionStyle{}, false
+	}
+	styles, err := h.captionStyles(ctx)
+	if err != nil {
+		return models.CaptionStyle{}, false
+	}
+	return models.FindCaptionStyle(styles, active.(string))
+}
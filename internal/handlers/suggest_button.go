@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const (
+	// suggestStartPayload starts the start parameter of "Suggest a meme" deep links:
+	// "suggest", or "suggest_<source>" to tell where the link was opened from.
+	suggestStartPayload = "suggest"
+	// Sources of the links posted with /suggestbutton.
+	suggestSourceChannel = "channel"
+	suggestSourceGroup   = "group"
+)
+
+// suggestSourcePattern matches the sources accepted in deep links. Telegram allows up to 64
+// characters in start parameters; sources are kept short and lowercase so /stats links groups them.
+var suggestSourcePattern = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// suggestButtonArgs declares the arguments of /suggestbutton.
+var suggestButtonArgs = cmdargs.Spec{
+	Command: "suggestbutton",
+	Args: []cmdargs.Arg{
+		{Name: "where", Choices: []string{suggestSourceChannel, suggestSourceGroup}},
+	},
+	Flags:   []string{"pin"},
+	Options: []string{"post"},
+}
+
+// parseSuggestStartPayload reports whether a /start payload is a "Suggest a meme" deep link and
+// returns the source it names, or "" if it names none or an invalid one.
+func parseSuggestStartPayload(payload string) (source string, ok bool) {
+	payload = strings.ToLower(payload)
+	if payload == suggestStartPayload {
+		return "", true
+	}
+	source, ok = strings.CutPrefix(payload, suggestStartPayload+"_")
+	if !ok {
+		return "", false
+	}
+	if !suggestSourcePattern.MatchString(source) {
+		return "", true
+	}
+	return source, true
+}
+
+// suggestLink returns the deep link that opens a private chat with the bot and starts a
+// suggestion, attributed to source.
+func suggestLink(botUsername, source string) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s_%s", botUsername, suggestStartPayload, source)
+}
+
+// HandleSuggestButton handles the /suggestbutton [channel|group] [--pin] [post=<message ID>]
+// command (admin only).
+// It posts a message with a "Suggest a meme" button to the channel or its discussion group; the
+// button opens a private chat with the bot and starts /suggest, so /stats links can tell how
+// many suggesters came from it. With --pin the message is pinned; with post=<message ID> the
+// button is attached to an existing channel post instead.
+func (h *MessageHandler) HandleSuggestButton(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:suggestbutton User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:suggestbutton User:%d] Non-admin user attempted to use /suggestbutton.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	args, err := suggestButtonArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	source := args.Arg("where")
+	if source == "" {
+		source = suggestSourceChannel
+	}
+	postID := 0
+	if raw, ok := args.Option("post"); ok {
+		if postID, err = strconv.Atoi(raw); err != nil || postID <= 0 || source != suggestSourceChannel {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgSuggestButtonInvalidPost", nil, nil))
+		}
+	}
+
+	targetChatID := h.channelID
+	if source == suggestSourceGroup {
+		channel, err := bot.GetChat(ctx, &telego.GetChatParams{ChatID: tu.ID(h.channelID)})
+		if err != nil {
+			return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to get the discussion group of channel %d: %w", h.channelID, err))
+		}
+		if channel.LinkedChatID == 0 {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgSuggestButtonNoGroup", nil, nil))
+		}
+		targetChatID = channel.LinkedChatID
+	}
+	me, err := bot.GetMe(ctx)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to get the bot's username: %w", err))
+	}
+
+	// Posts in the channel are read in its language, whatever the admin's
+	channelLocalizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+	keyboard := tu.InlineKeyboard(tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(locales.GetMessage(channelLocalizer, "BtnSuggestMeme", nil, nil)).WithURL(suggestLink(me.Username, source)),
+	))
+	if postID != 0 {
+		_, err = bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+			ChatID:      tu.ID(targetChatID),
+			MessageID:   postID,
+			ReplyMarkup: keyboard,
+		})
+	} else {
+		var sent *telego.Message
+		text := locales.GetMessage(channelLocalizer, "MsgSuggestButtonPost", nil, nil)
+		sent, err = bot.SendMessage(ctx, tu.Message(tu.ID(targetChatID), text).WithReplyMarkup(keyboard))
+		if err == nil {
+			postID = sent.MessageID
+		}
+	}
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to post the suggest button to chat %d: %w", targetChatID, err))
+	}
+	pinned := false
+	if args.Flag("pin") {
+		err = bot.PinChatMessage(ctx, &telego.PinChatMessageParams{
+			ChatID:              tu.ID(targetChatID),
+			MessageID:           postID,
+			DisableNotification: true,
+		})
+		if err != nil {
+			log.Printf("[Cmd:suggestbutton User:%d] Error pinning message %d in chat %d: %v", userID, postID, targetChatID, err)
+		}
+		pinned = err == nil
+	}
+	log.Printf("[Cmd:suggestbutton User:%d] Posted the suggest button for %q as message %d in chat %d", userID, source, postID, targetChatID)
+	h.RecordUserActivity(ctx, message.From, ActionCommandSuggestButton, isAdmin, map[string]interface{}{
+		"chat_id":   chatID,
+		"source":    source,
+		"target_id": targetChatID,
+		"post_id":   postID,
+		"pinned":    pinned,
+	})
+
+	msgID := "MsgSuggestButtonDone"
+	if args.Flag("pin") && !pinned {
+		msgID = "MsgSuggestButtonNotPinned"
+	}
+	return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, msgID, map[string]interface{}{"Source": source}, nil))
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"vrcmemes-bot/internal/channelrights"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/tenants"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+	"vrcmemes-bot/pkg/utils"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// TenantDirectory connects channels in multi-tenant mode, enforces their quotas and remembers
+// the channel each user picked. It is implemented by tenants.Registry.
+type TenantDirectory interface {
+	PrimaryID() int64
+	Tenants() []models.Tenant
+	Connect(ctx context.Context, tenant models.Tenant) error
+	Disconnect(ctx context.Context, channelID int64) error
+	Quota(channelID int64) models.TenantQuota
+	SetQuota(ctx context.Context, channelID int64, quota models.TenantQuota) error
+	Usage(ctx context.Context, channelID int64) tenants.Usage
+	UserTenant(ctx context.Context, userID int64) int64
+	SetUserTenant(ctx context.Context, userID, channelID int64) error
+}
+
+// connectArgs declares the arguments of /connect.
+var connectArgs = cmdargs.Spec{
+	Command: "connect",
+	Args: []cmdargs.Arg{
+		{Name: "channel_id", Required: true},
+		{Name: "title", Rest: true},
+	},
+}
+
+// tenantsArgs declares the arguments of /tenants.
+var tenantsArgs = cmdargs.Spec{
+	Command: "tenants",
+	Args: []cmdargs.Arg{
+		{Name: "action", Choices: []string{"list", "quota", "remove"}},
+		{Name: "channel_id"},
+	},
+	Options: []string{"suggestions", "rate"},
+}
+
+// SetTenants enables multi-tenant mode: channel owners can connect their channels with /connect,
+// everyone picks the channel their suggestions, commands and reviews are for with /channel, and
+// the host operator manages connected channels with /tenants. These commands are only
+// registered in multi-tenant mode.
+func (h *MessageHandler) SetTenants(directory TenantDirectory) {
+	h.tenants = directory
+	h.commands = append(h.commands,
+		Command{Command: "channel", Description: "CmdChannelDesc", Handler: h.HandleChannel, Role: RoleEveryone, Help: "CmdChannelHelp"},
+		Command{Command: "connect", Description: "CmdConnectDesc", Handler: h.HandleConnect, Role: RoleEveryone,
+			Args: &connectArgs, Help: "CmdConnectHelp", Examples: []string{"/connect -1001234567890", "/connect -1001234567890 Cat memes"}},
+		Command{Command: "tenants", Description: "CmdTenantsDesc", Handler: h.HandleTenants, Role: RoleSuperAdmin,
+			Args: &tenantsArgs, Help: "CmdTenantsHelp", Examples: []string{"/tenants", "/tenants quota -1001234567890 suggestions=50 rate=60", "/tenants remove -1001234567890"}},
+	)
+}
+
+// HandleConnect handles the /connect <channel ID> [title] command.
+// The bot must be an admin of the channel who can post, and the user its creator, who becomes
+// the super admin of the channel in the bot.
+func (h *MessageHandler) HandleConnect(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	args, err := connectArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	channelID, err := strconv.ParseInt(args.Arg("channel_id"), 10, 64)
+	if err != nil || channelID >= 0 {
+		msg := locales.GetMessage(localizer, "MsgConnectInvalidChannel", nil, nil)
+		return h.sendSuccess(ctx, bot, chatID, msg)
+	}
+
+	rights, err := channelrights.NewChecker(bot, channelID, 0).Rights(ctx)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, err)
+	}
+	if !rights.CanPost {
+		msg := locales.GetMessage(localizer, "MsgConnectBotNotAdmin", nil, nil)
+		return h.sendSuccess(ctx, bot, chatID, msg)
+	}
+	member, err := bot.GetChatMember(ctx, &telego.GetChatMemberParams{ChatID: tu.ID(channelID), UserID: userID})
+	if err != nil || member.MemberStatus() != telego.MemberStatusCreator {
+		log.Printf("[Cmd:connect User:%d] User is not the creator of channel %d (err: %v)", userID, channelID, err)
+		msg := locales.GetMessage(localizer, "MsgConnectNotOwner", nil, nil)
+		return h.sendSuccess(ctx, bot, chatID, msg)
+	}
+
+	tenant := models.Tenant{ChannelID: channelID, Title: args.Arg("title"), OwnerID: userID}
+	if err := h.tenants.Connect(ctx, tenant); err != nil {
+		if errors.Is(err, database.ErrTenantExists) {
+			msg := locales.GetMessage(localizer, "MsgConnectExists", nil, nil)
+			return h.sendSuccess(ctx, bot, chatID, msg)
+		}
+		return h.sendError(ctx, bot, chatID, err)
+	}
+	// The owner goes on to set the channel up with /setup
+	if err := h.tenants.SetUserTenant(ctx, userID, channelID); err != nil {
+		log.Printf("[Cmd:connect User:%d] Error picking channel %d for its owner: %v", userID, channelID, err)
+	}
+	h.RecordUserActivity(ctx, message.From, ActionCommandConnect, false, map[string]interface{}{
+		"chat_id":    chatID,
+		"channel_id": channelID,
+	})
+
+	msg := locales.GetMessage(localizer, "MsgConnectDone", map[string]interface{}{
+		"Channel": tenantLabel(localizer, tenant, 0),
+	}, nil)
+	_, err = bot.SendMessage(ctx, tu.Message(tu.ID(chatID), msg))
+	return err
+}
+
+// HandleTenants handles the /tenants [list|quota|remove] [channel ID] command (super admins of
+// the primary channel only).
+// It lists the connected channels with their usage and quotas, changes the quota of a channel
+// with suggestions=<pending suggestions> and rate=<updates per minute>, or disconnects a channel.
+func (h *MessageHandler) HandleTenants(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	if !h.adminChecker.IsSuperAdmin(userID) {
+		log.Printf("[Cmd:tenants User:%d] Non-super-admin user attempted to use /tenants.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresSuperAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	args, err := tenantsArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	action := args.Arg("action")
+	if action == "" {
+		action = "list"
+	}
+	h.RecordUserActivity(ctx, message.From, ActionCommandTenants, false, map[string]interface{}{
+		"chat_id": chatID,
+		"action":  action,
+	})
+	if action == "list" {
+		_, err = bot.SendMessage(ctx, tu.Message(tu.ID(chatID), h.tenantsList(ctx, localizer)))
+		return err
+	}
+
+	channelID, err := strconv.ParseInt(args.Arg("channel_id"), 10, 64)
+	if err != nil || channelID >= 0 {
+		msg := locales.GetMessage(localizer, "MsgConnectInvalidChannel", nil, nil)
+		return h.sendSuccess(ctx, bot, chatID, msg)
+	}
+	var msgID string
+	switch action {
+	case "remove":
+		err = h.tenants.Disconnect(ctx, channelID)
+		msgID = "MsgTenantsRemoved"
+	case "quota":
+		quota := h.tenants.Quota(channelID)
+		for option, limit := range map[string]*int{"suggestions": &quota.MaxPendingSuggestions, "rate": &quota.UpdatesPerMinute} {
+			value, ok := args.Option(option)
+			if !ok {
+				continue
+			}
+			if *limit, err = strconv.Atoi(value); err != nil || *limit < 0 {
+				msg := locales.GetMessage(localizer, "MsgTenantsInvalidQuota", map[string]interface{}{"Option": option}, nil)
+				return h.sendSuccess(ctx, bot, chatID, msg)
+			}
+		}
+		err = h.tenants.SetQuota(ctx, channelID, quota)
+		msgID = "MsgTenantsQuotaSet"
+	}
+	if errors.Is(err, database.ErrTenantNotFound) {
+		msg := locales.GetMessage(localizer, "MsgTenantsNotFound", nil, nil)
+		return h.sendSuccess(ctx, bot, chatID, msg)
+	}
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, err)
+	}
+	log.Printf("[Cmd:tenants User:%d] %s channel %d", userID, action, channelID)
+
+	msg := locales.GetMessage(localizer, msgID, map[string]interface{}{"ChannelID": channelID}, nil)
+	_, err = bot.SendMessage(ctx, tu.Message(tu.ID(chatID), msg))
+	return err
+}
+
+// tenantsList describes the connected channels: their owner, when they were connected, and
+// their usage against their quotas.
+func (h *MessageHandler) tenantsList(ctx context.Context, localizer *i18n.Localizer) string {
+	connected := h.tenants.Tenants()
+	if len(connected) == 0 {
+		return locales.GetMessage(localizer, "MsgTenantsEmpty", nil, nil)
+	}
+	lines := []string{locales.GetMessage(localizer, "MsgTenantsHeader", map[string]interface{}{"Count": len(connected)}, nil)}
+	for _, tenant := range connected {
+		quota := h.tenants.Quota(tenant.ChannelID)
+		usage := h.tenants.Usage(ctx, tenant.ChannelID)
+		pending := "?"
+		if usage.PendingSuggestions >= 0 {
+			pending = strconv.FormatInt(usage.PendingSuggestions, 10)
+		}
+		lines = append(lines, locales.GetMessage(localizer, "MsgTenantsEntry", map[string]interface{}{
+			"Channel":    tenantLabel(localizer, tenant, 0),
+			"ChannelID":  tenant.ChannelID,
+			"OwnerID":    tenant.OwnerID,
+			"Date":       utils.FormatTime(tenant.CreatedAt, h.location),
+			"Pending":    pending,
+			"MaxPending": quotaLimit(localizer, quota.MaxPendingSuggestions),
+			"Updates":    usage.UpdatesThisMinute,
+			"MaxUpdates": quotaLimit(localizer, quota.UpdatesPerMinute),
+		}, nil))
+	}
+	return strings.Join(lines, "\n\n")
+}
+
+// quotaLimit formats a quota limit, zero being unlimited.
+func quotaLimit(localizer *i18n.Localizer, limit int) string {
+	if limit <= 0 {
+		return locales.GetMessage(localizer, "MsgTenantsUnlimited", nil, nil)
+	}
+	return strconv.Itoa(limit)
+}
+
+// HandleChannel handles the /channel command.
+// It lists the channels served by the bot, so users can pick the one their suggestions, commands
+// and reviews are for.
+func (h *MessageHandler) HandleChannel(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandChannel, false, map[string]interface{}{
+		"chat_id": chatID,
+	})
+
+	text := locales.GetMessage(localizer, "MsgChannelPick", nil, nil)
+	keyboard := h.channelKeyboard(localizer, h.tenants.UserTenant(ctx, userID))
+	_, err := bot.SendMessage(ctx, tu.Message(tu.ID(chatID), text).WithReplyMarkup(keyboard))
+	return err
+}
+
+// handleTenantCallback saves the channel picked with a /channel button and marks it in the list.
+func (h *MessageHandler) handleTenantCallback(ctx context.Context, bot telegoapi.BotAPI, query telego.CallbackQuery) error {
+	userID := query.From.ID
+	localizer := h.getLocalizer(&query.From)
+
+	channelID, err := strconv.ParseInt(strings.TrimPrefix(query.Data, tenants.CallbackPrefix), 10, 64)
+	if err == nil && h.tenants == nil {
+		err = errors.New("multi-tenant mode is disabled")
+	}
+	if err == nil {
+		err = h.tenants.SetUserTenant(ctx, userID, channelID)
+	}
+	if err != nil {
+		_ = bot.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{
+			CallbackQueryID: query.ID,
+			Text:            locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil),
+			ShowAlert:       true,
+		})
+		return fmt.Errorf("failed to pick channel from callback data %q: %w", query.Data, err)
+	}
+	log.Printf("[TenantCallback User:%d] Picked channel %d", userID, channelID)
+	_ = bot.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{
+		CallbackQueryID: query.ID,
+		Text:            locales.GetMessage(localizer, "MsgChannelPicked", nil, nil),
+	})
+
+	if query.Message == nil {
+		return nil
+	}
+	_, err = bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+		ChatID:      tu.ID(query.Message.GetChat().ID),
+		MessageID:   query.Message.GetMessageID(),
+		ReplyMarkup: h.channelKeyboard(localizer, channelID),
+	})
+	if err != nil {
+		log.Printf("[TenantCallback User:%d] Error updating the channel list: %v", userID, err)
+	}
+	return nil
+}
+
+// channelKeyboard lists the primary and connected channels as buttons, the picked one checked.
+func (h *MessageHandler) channelKeyboard(localizer *i18n.Localizer, picked int64) *telego.InlineKeyboardMarkup {
+	primaryID := h.tenants.PrimaryID()
+	channels := append([]models.Tenant{{ChannelID: primaryID}}, h.tenants.Tenants()...)
+	rows := make([][]telego.InlineKeyboardButton, 0, len(channels))
+	for _, tenant := range channels {
+		label := tenantLabel(localizer, tenant, primaryID)
+		if tenant.ChannelID == picked {
+			label = "✅ " + label
+		}
+		rows = append(rows, tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton(label).WithCallbackData(tenants.CallbackPrefix+strconv.FormatInt(tenant.ChannelID, 10)),
+		))
+	}
+	return tu.InlineKeyboard(rows...)
+}
+
+// tenantLabel names a channel in messages and buttons: its title, or its ID if it has none.
+func tenantLabel(localizer *i18n.Localizer, tenant models.Tenant, primaryID int64) string {
+	switch {
+	case tenant.ChannelID == primaryID:
+		return locales.GetMessage(localizer, "BtnChannelPrimary", nil, nil)
+	case tenant.Title != "":
+		return tenant.Title
+	default:
+		return strconv.FormatInt(tenant.ChannelID, 10)
+	}
+}
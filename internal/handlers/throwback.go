@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const (
+	// defaultThrowbackMonths is how old a post /throwback brings back must be when no months are given.
+	defaultThrowbackMonths = 6
+	// maxThrowbackMonths bounds the age /throwback accepts.
+	maxThrowbackMonths = 120
+	// throwbackRepostCooldown is how long a reposted post isn't picked by /throwback again.
+	throwbackRepostCooldown = 90 * 24 * time.Hour
+)
+
+// throwbackArgs declares the arguments of /throwback.
+var throwbackArgs = cmdargs.Spec{
+	Command: "throwback",
+	Args: []cmdargs.Arg{
+		{Name: "months"},
+	},
+}
+
+// SetThrowbackRepository sets where /throwback picks archived posts from and logs reposts.
+// Without it, /throwback is unavailable.
+func (h *MessageHandler) SetThrowbackRepository(repo database.ThrowbackRepository) {
+	h.throwbackRepo = repo
+}
+
+// HandleThrowback handles the /throwback [months] command (admin only).
+// It picks a random photo or video post published more than the given months ago (6 by default)
+// and copies it to the channel again with a "From the archive" caption. Posts reposted in the
+// last 90 days are skipped.
+func (h *MessageHandler) HandleThrowback(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:throwback User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:throwback User:%d] Non-admin user attempted to use /throwback.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	if h.throwbackRepo == nil {
+		return h.sendError(ctx, bot, chatID, errors.New("throwback repository is not configured"))
+	}
+
+	args, err := throwbackArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	months := defaultThrowbackMonths
+	if raw := args.Arg("months"); raw != "" {
+		months, err = strconv.Atoi(raw)
+		if err != nil || months < 1 || months > maxThrowbackMonths {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgThrowbackInvalidMonths", map[string]interface{}{
+				"Max": maxThrowbackMonths,
+			}, nil))
+		}
+	}
+
+	now := time.Now()
+	post, err := h.throwbackRepo.RandomArchivedPost(ctx, h.channelID, now.AddDate(0, -months, 0), now.Add(-throwbackRepostCooldown))
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to pick an archived post: %w", err))
+	}
+	if post == nil {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetPluralMessage(localizer, "MsgThrowbackNone", months, nil))
+	}
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandThrowback, isAdmin, map[string]interface{}{
+		"chat_id":         chatID,
+		"months":          months,
+		"channel_post_id": post.ChannelPostID,
+	})
+
+	caption := h.throwbackCaption(post)
+	key := fmt.Sprintf("throwback:%d:%d", post.ChannelPostID, now.Unix())
+	position := h.publishQueue.Submit(ctx, key, func(ctx context.Context) error {
+		sent, err := bot.CopyMessage(ctx, &telego.CopyMessageParams{
+			ChatID:     tu.ID(h.channelID),
+			FromChatID: tu.ID(post.ChannelID),
+			MessageID:  post.ChannelPostID,
+			Caption:    caption,
+		})
+		if err != nil {
+			return err
+		}
+		log.Printf("[Cmd:throwback User:%d] Reposted channel post %d from %s as %d", userID, post.ChannelPostID,
+			post.PublishedAt.Format(time.DateOnly), sent.MessageID)
+		if err := h.throwbackRepo.RecordRepost(ctx, &models.Repost{
+			ChannelID:     h.channelID,
+			ChannelPostID: post.ChannelPostID,
+			RepostPostID:  sent.MessageID,
+			RepostedBy:    userID,
+		}); err != nil {
+			log.Printf("[Cmd:throwback User:%d] Failed to record repost of post %d: %v", userID, post.ChannelPostID, err)
+		}
+		return nil
+	}, func(ctx context.Context, err error) {
+		if err != nil {
+			log.Printf("[Cmd:throwback User:%d] Failed to repost channel post %d: %v", userID, post.ChannelPostID, err)
+		}
+		h.ReportPublishResult(ctx, bot, localizer, chatID, err)
+	})
+	return h.SendPublishQueued(ctx, bot, localizer, chatID, position)
+}
+
+// throwbackCaption puts the "From the archive" line, in the channel's language and with the
+// original date in the channel time zone, above the original caption of a post.
+func (h *MessageHandler) throwbackCaption(post *models.PostLog) string {
+	loc := h.location
+	if loc == nil {
+		loc = time.UTC
+	}
+	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+	header := locales.GetMessage(localizer, "MsgThrowbackCaption", map[string]interface{}{
+		"Date": post.PublishedAt.In(loc).Format(time.DateOnly),
+	}, nil)
+	if post.Caption == "" {
+		return header
+	}
+	return header + "\n\n" + post.Caption
+}
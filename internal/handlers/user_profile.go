@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+	"vrcmemes-bot/pkg/utils"
+
+	"github.com/mymmrac/telego"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// userArgs declares the arguments of /user.
+var userArgs = cmdargs.Spec{
+	Command: "user",
+	Args: []cmdargs.Arg{
+		{Name: "id", Required: true},
+	},
+}
+
+// HandleUser handles the /user <id> command (admin only).
+// It shows the stored profile of a user: names, language, flags, first and last activity and counters.
+func (h *MessageHandler) HandleUser(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:user User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:user User:%d] Non-admin user attempted to use /user.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+
+	args, err := userArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	targetID, err := strconv.ParseInt(args.Arg("id"), 10, 64)
+	if err != nil {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgUserInvalidID", map[string]interface{}{
+			"ID": args.Arg("id"),
+		}, nil))
+	}
+
+	user, err := h.userRepo.GetUser(ctx, targetID)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to get user profile: %w", err))
+	}
+
+	h.RecordUserActivity(ctx, message.From, ActionCommandUser, isAdmin, map[string]interface{}{
+		"chat_id":        chatID,
+		"target_user_id": targetID,
+	})
+
+	if user == nil {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgUserNotFound", map[string]interface{}{
+			"UserID": targetID,
+		}, nil))
+	}
+	return h.sendSuccess(ctx, bot, chatID, formatUserProfile(localizer, user, h.location))
+}
+
+// formatUserProfile renders a stored user profile as plain text.
+func formatUserProfile(localizer *i18n.Localizer, user *models.User, loc *time.Location) string {
+	var flags []string
+	if user.IsAdmin {
+		flags = append(flags, locales.GetMessage(localizer, "MsgUserFlagAdmin", nil, nil))
+	}
+	if user.IsBot {
+		flags = append(flags, locales.GetMessage(localizer, "MsgUserFlagBot", nil, nil))
+	}
+	if user.IsPremium {
+		flags = append(flags, locales.GetMessage(localizer, "MsgUserFlagPremium", nil, nil))
+	}
+	username := ""
+	if user.Username != "" {
+		username = "@" + user.Username
+	}
+
+	return locales.GetMessage(localizer, "MsgUserProfile", map[string]interface{}{
+		"UserID":      user.UserID,
+		"Name":        orDash(strings.TrimSpace(user.FirstName + " " + user.LastName)),
+		"Username":    orDash(username),
+		"Language":    orDash(user.Language),
+		"ClientLang":  orDash(user.ClientLang),
+		"Flags":       orDash(strings.Join(flags, ", ")),
+		"FirstSeen":   formatProfileTime(user.FirstSeen, loc),
+		"LastSeen":    formatProfileTime(user.LastSeen, loc),
+		"LastAction":  orDash(user.LastAction),
+		"Actions":     user.ActionsCount,
+		"Suggestions": user.SuggestionsCount,
+		"Approvals":   user.ApprovalsCount,
+		"Feedback":    user.FeedbackCount,
+		"Consent":     formatProfileConsent(localizer, user.ConsentedAt, loc),
+	}, nil)
+}
+
+// formatProfileConsent describes whether the user agreed to the privacy notice.
+func formatProfileConsent(localizer *i18n.Localizer, consentedAt time.Time, loc *time.Location) string {
+	if consentedAt.IsZero() {
+		return locales.GetMessage(localizer, "MsgUserConsentMissing", nil, nil)
+	}
+	return locales.GetMessage(localizer, "MsgUserConsentGiven", map[string]interface{}{
+		"Date": formatProfileTime(consentedAt, loc),
+	}, nil)
+}
+
+// formatProfileTime formats a profile timestamp in the channel time zone, or a dash if it is unset.
+func formatProfileTime(t time.Time, loc *time.Location) string {
+	if t.IsZero() {
+		return "—"
+	}
+	return utils.FormatTime(t, loc)
+}
+
+// orDash returns s, or a dash if s is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "—"
+	}
+	return s
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/suggestions"
+	"vrcmemes-bot/internal/videopolicy"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// videoPostCallbackPrefix starts the callback data of the button posting a video outside the
+// video policy anyway: videopost:<messageID>.
+const videoPostCallbackPrefix = "videopost:"
+
+// SetVideoPolicy sets the duration and aspect ratios videos posted by admins must keep to
+// (VIDEO_MAX_DURATION and VIDEO_ASPECT_RATIOS). The zero policy allows any video.
+func (h *MessageHandler) SetVideoPolicy(policy videopolicy.Policy) {
+	h.videoPolicy = policy
+}
+
+// checkVideoPolicy returns the issues of a posted video with the video policy.
+func (h *MessageHandler) checkVideoPolicy(video *telego.Video) []models.MediaIssue {
+	return h.videoPolicy.Check(0, video.Width, video.Height, time.Duration(video.Duration)*time.Second)
+}
+
+// holdVideoPost keeps a video outside the video policy from being posted and tells the admin why,
+// with a button to post it anyway. Only the latest such video of a chat can be posted.
+func (h *MessageHandler) holdVideoPost(ctx context.Context, bot telegoapi.BotAPI, localizer *i18n.Localizer, message telego.Message, issues []models.MediaIssue) error {
+	log.Printf("[HandleVideo Admin:%d] Video %d is outside the video policy: %+v", message.From.ID, message.MessageID, issues)
+	h.pendingVideoPosts.Store(message.Chat.ID, &message)
+
+	text := locales.GetMessage(localizer, "MsgVideoPostRejected", map[string]interface{}{
+		"Issues": suggestions.DescribeMediaIssues(localizer, issues, false),
+	}, nil)
+	button := tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnPostVideoAnyway", nil, nil)).
+		WithCallbackData(videoPostCallbackPrefix + strconv.Itoa(message.MessageID))
+	_, err := bot.SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), text).
+		WithReplyParameters(&telego.ReplyParameters{MessageID: message.MessageID}).
+		WithReplyMarkup(tu.InlineKeyboard(tu.InlineKeyboardRow(button))))
+	if err != nil {
+		return fmt.Errorf("failed to send video policy rejection: %w", err)
+	}
+	return nil
+}
+
+// handleVideoPostCallback posts a video held back by the video policy when an admin overrides it.
+func (h *MessageHandler) handleVideoPostCallback(ctx context.Context, bot telegoapi.BotAPI, query telego.CallbackQuery) error {
+	userID := query.From.ID
+	localizer := h.getLocalizer(&query.From)
+	answer := func(text string) {
+		_ = bot.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID, Text: text, ShowAlert: text != ""})
+	}
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		answer(locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil))
+		return fmt.Errorf("video post admin check failed for user %d: %w", userID, err)
+	}
+	if !isAdmin {
+		log.Printf("[VideoPostCallback User:%d] Non-admin attempted to post a video.", userID)
+		answer(locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil))
+		return nil
+	}
+	messageID, err := strconv.Atoi(strings.TrimPrefix(query.Data, videoPostCallbackPrefix))
+	if err != nil || query.Message == nil {
+		answer(locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil))
+		return fmt.Errorf("malformed video post callback data %q", query.Data)
+	}
+
+	chatID := query.Message.GetChat().ID
+	value, ok := h.pendingVideoPosts.Load(chatID)
+	if !ok || value.(*telego.Message).MessageID != messageID || !h.pendingVideoPosts.CompareAndDelete(chatID, value) {
+		answer(locales.GetMessage(localizer, "MsgVideoPostExpired", nil, nil))
+		return nil
+	}
+	message := value.(*telego.Message)
+	log.Printf("[VideoPostCallback User:%d] Posting video %d despite the video policy", userID, messageID)
+	answer("")
+
+	if _, err := bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+		ChatID:    tu.ID(chatID),
+		MessageID: query.Message.GetMessageID(),
+	}); err != nil {
+		log.Printf("[VideoPostCallback User:%d] Error removing the post anyway button: %v", userID, err)
+	}
+	return h.postVideo(ctx, bot, h.getLocalizer(message.From), *message)
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/cmdargs"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/wordfilter"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// defaultFilterHitDays is the period /wordfilter hits counts over without an argument.
+	defaultFilterHitDays = 30
+	// maxFilterHitDays bounds the period of /wordfilter hits.
+	maxFilterHitDays = 365
+)
+
+// wordFilterArgs declares the arguments of /wordfilter.
+var wordFilterArgs = cmdargs.Spec{
+	Command: "wordfilter",
+	Args: []cmdargs.Arg{
+		{Name: "action", Required: true, Choices: []string{"list", "add", "remove", "hits"}},
+		{Name: "severity|word|days"},
+		{Name: "word"},
+	},
+}
+
+// SetWordFilterRepository sets where the words screened in admin posts are stored. Without it,
+// posts aren't screened and /wordfilter is unavailable.
+func (h *MessageHandler) SetWordFilterRepository(repo database.WordFilterRepository) {
+	h.wordFilterRepo = repo
+}
+
+// HandleWordFilter handles the /wordfilter command (admin only):
+//
+//	/wordfilter list                              — show the filtered words
+//	/wordfilter add <warn|censor|block> <word>    — filter a word, or change its severity
+//	/wordfilter remove <word>                     — stop filtering a word
+//	/wordfilter hits [days]                       — count the filtered words found lately
+//
+// Suggestion captions and admin posts are screened: "warn" flags the text, "censor" replaces the
+// word by asterisks and "block" refuses the text. A word ending with "*" matches every word
+// starting with it.
+func (h *MessageHandler) HandleWordFilter(ctx context.Context, bot telegoapi.BotAPI, message telego.Message) error {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := h.getLocalizer(message.From)
+
+	isAdmin, err := h.adminChecker.IsAdmin(ctx, userID)
+	if err != nil {
+		log.Printf("[Cmd:wordfilter User:%d] Error checking admin status: %v. Assuming non-admin.", userID, err)
+		isAdmin = false
+	}
+	if !isAdmin {
+		log.Printf("[Cmd:wordfilter User:%d] Non-admin user attempted to use /wordfilter.", userID)
+		msg := locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil)
+		return h.sendError(ctx, bot, chatID, errors.New(msg))
+	}
+	if h.wordFilterRepo == nil {
+		return h.sendError(ctx, bot, chatID, errors.New("word filter repository is not configured"))
+	}
+
+	args, err := wordFilterArgs.Parse(message.Text)
+	if err != nil {
+		return h.sendUsageError(ctx, bot, chatID, localizer, err)
+	}
+	action, first, second := args.Arg("action"), args.Arg("severity|word|days"), args.Arg("word")
+	h.RecordUserActivity(ctx, message.From, ActionCommandWordFilter, isAdmin, map[string]interface{}{
+		"chat_id": chatID,
+		"action":  action,
+		"args":    strings.TrimSpace(first + " " + second),
+	})
+
+	switch action {
+	case "list":
+		return h.listFilteredWords(ctx, bot, localizer, chatID)
+	case "hits":
+		days := defaultFilterHitDays
+		if first != "" {
+			days, err = strconv.Atoi(first)
+			if err != nil || days < 1 || days > maxFilterHitDays {
+				return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgWordFilterInvalidDays", map[string]interface{}{
+					"Max": maxFilterHitDays,
+				}, nil))
+			}
+		}
+		return h.listFilterHits(ctx, bot, localizer, chatID, days)
+	case "remove":
+		word := wordfilter.Normalize(first)
+		if word == "" {
+			return h.sendUsageError(ctx, bot, chatID, localizer, &cmdargs.UsageError{
+				MessageID: "MsgArgsMissing",
+				Data:      map[string]interface{}{"Arg": "word", "Usage": wordFilterArgs.Usage()},
+			})
+		}
+		err := h.wordFilterRepo.DeleteFilteredWord(ctx, word)
+		if errors.Is(err, database.ErrFilteredWordNotFound) {
+			return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgWordFilterNotFound", map[string]interface{}{"Word": word}, nil))
+		}
+		if err != nil {
+			return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to remove filtered word: %w", err))
+		}
+		log.Printf("[Cmd:wordfilter User:%d] Removed filtered word %q", userID, word)
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgWordFilterRemoved", map[string]interface{}{"Word": word}, nil))
+	}
+
+	severity, ok := wordfilter.ParseSeverity(first)
+	if !ok {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgWordFilterInvalidSeverity", map[string]interface{}{
+			"Severities": strings.Join(wordfilter.Names(), ", "),
+		}, nil))
+	}
+	word := wordfilter.Normalize(second)
+	if word == "" {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgWordFilterInvalidWord", map[string]interface{}{
+			"Word": second,
+		}, nil))
+	}
+	if err := h.wordFilterRepo.SetFilteredWord(ctx, &models.FilteredWord{
+		ChannelID: h.channelID,
+		Word:      word,
+		Severity:  severity.String(),
+		AddedBy:   userID,
+	}); err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to add filtered word: %w", err))
+	}
+	log.Printf("[Cmd:wordfilter User:%d] Filtering %q with severity %s", userID, word, severity)
+	return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgWordFilterAdded", map[string]interface{}{
+		"Word":     word,
+		"Severity": severity.String(),
+	}, nil))
+}
+
+// listFilteredWords shows the filtered words by severity.
+func (h *MessageHandler) listFilteredWords(ctx context.Context, bot telegoapi.BotAPI, localizer *i18n.Localizer, chatID int64) error {
+	words, err := h.wordFilterRepo.ListFilteredWords(ctx)
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to list filtered words: %w", err))
+	}
+	if len(words) == 0 {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, "MsgWordFilterListEmpty", nil, nil))
+	}
+	bySeverity := make(map[string][]string)
+	for _, word := range words {
+		bySeverity[word.Severity] = append(bySeverity[word.Severity], word.Word)
+	}
+	var text strings.Builder
+	text.WriteString(locales.GetMessage(localizer, "MsgWordFilterListHeader", nil, nil))
+	for _, severity := range wordfilter.Names() {
+		if len(bySeverity[severity]) == 0 {
+			continue
+		}
+		text.WriteString("\n")
+		text.WriteString(locales.GetMessage(localizer, "MsgWordFilterListItem", map[string]interface{}{
+			"Severity": severity,
+			"Words":    strings.Join(bySeverity[severity], ", "),
+		}, nil))
+	}
+	return h.sendSuccess(ctx, bot, chatID, text.String())
+}
+
+// listFilterHits shows how often each filtered word was found over the last days.
+func (h *MessageHandler) listFilterHits(ctx context.Context, bot telegoapi.BotAPI, localizer *i18n.Localizer, chatID int64, days int) error {
+	counts, err := h.wordFilterRepo.FilterHitCounts(ctx, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return h.sendError(ctx, bot, chatID, fmt.Errorf("failed to count filter hits: %w", err))
+	}
+	if len(counts) == 0 {
+		return h.sendSuccess(ctx, bot, chatID, locales.GetPluralMessage(localizer, "MsgWordFilterHitsEmpty", days, nil))
+	}
+	var text strings.Builder
+	text.WriteString(locales.GetPluralMessage(localizer, "MsgWordFilterHitsHeader", days, nil))
+	for _, count := range counts {
+		text.WriteString("\n")
+		text.WriteString(locales.GetMessage(localizer, "MsgWordFilterHitsItem", map[string]interface{}{
+			"Word":  count.Word,
+			"Hits":  count.Hits,
+			"Users": count.Users,
+		}, nil))
+	}
+	return h.sendSuccess(ctx, bot, chatID, text.String())
+}
+
+// ScreenPost checks the text or caption of an admin post against the word filter and returns the
+// text to publish, with censored words masked, and whether the post may be published. The admin
+// is told in chatID when the post is blocked, censored or contains words to be careful with.
+// Posts pass unchanged when the filter isn't configured or can't be loaded.
+func (h *MessageHandler) ScreenPost(ctx context.Context, bot telegoapi.BotAPI, localizer *i18n.Localizer, userID, chatID int64, text string) (string, bool) {
+	if h.wordFilterRepo == nil || strings.TrimSpace(text) == "" {
+		return text, true
+	}
+	result, filter, err := wordfilter.Screen(ctx, h.wordFilterRepo, models.FilterSourcePost, userID, text)
+	if err != nil {
+		log.Printf("[WordFilter Admin:%d] %v", userID, err)
+	}
+	if filter == nil || result.Severity == wordfilter.None {
+		return text, true
+	}
+	words := strings.Join(result.Words(), ", ")
+	log.Printf("[WordFilter Admin:%d] Post matched filtered words (%s): %s", userID, result.Severity, words)
+
+	messageID := "MsgWordFilterPostWarning"
+	switch result.Severity {
+	case wordfilter.Block:
+		messageID = "MsgWordFilterPostBlocked"
+	case wordfilter.Censor:
+		messageID = "MsgWordFilterPostCensored"
+		text = filter.Censor(text)
+	}
+	if err := h.sendSuccess(ctx, bot, chatID, locales.GetMessage(localizer, messageID, map[string]interface{}{"Words": words}, nil)); err != nil {
+		log.Printf("[WordFilter Admin:%d] Error telling the admin about filtered words: %v", userID, err)
+	}
+	return text, result.Severity != wordfilter.Block
+}
+
+// screenMediaCaption screens the caption an admin photo or video is published with: the active
+// caption, or the caption of the message itself when none is set. A censored caption is returned
+// to replace the active caption.
+func (h *MessageHandler) screenMediaCaption(ctx context.Context, bot telegoapi.BotAPI, localizer *i18n.Localizer, message telego.Message, activeCaption string) (string, bool) {
+	caption := activeCaption
+	if caption == "" {
+		caption = message.Caption
+	}
+	screened, allowed := h.ScreenPost(ctx, bot, localizer, message.From.ID, message.Chat.ID, caption)
+	if screened != caption {
+		return screened, allowed
+	}
+	return activeCaption, allowed
+}
@@ -0,0 +1,118 @@
+// Package history reads channel history exported with Telegram Desktop ("Export chat history"
+// in JSON format), so posts published before the bot kept post logs can be imported.
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+)
+
+// exportedChat is the part of a Telegram Desktop result.json that is imported.
+type exportedChat struct {
+	Name     string            `json:"name"`
+	Type     string            `json:"type"`
+	Messages []exportedMessage `json:"messages"`
+}
+
+// exportedMessage is a message of a Telegram Desktop export.
+type exportedMessage struct {
+	ID           int             `json:"id"`
+	Type         string          `json:"type"` // "message" or "service"
+	DateUnixTime string          `json:"date_unixtime"`
+	Photo        string          `json:"photo"`
+	MediaType    string          `json:"media_type"`
+	Text         json.RawMessage `json:"text"` // A string, or a list of strings and formatted parts
+}
+
+// mediaTypes maps the media types of exported messages to post log message types.
+var mediaTypes = map[string]string{
+	"video_file": "video",
+	"animation":  "animation",
+}
+
+// ReadExport parses a Telegram Desktop channel export and returns a post log for every
+// message, logged as published to channelID. Service messages are skipped. The export
+// doesn't group albums, so each photo of an album becomes its own post log.
+func ReadExport(r io.Reader, channelID int64) ([]models.PostLog, error) {
+	var chat exportedChat
+	if err := json.NewDecoder(r).Decode(&chat); err != nil {
+		return nil, fmt.Errorf("failed to decode export: %w", err)
+	}
+	if !strings.HasSuffix(chat.Type, "channel") {
+		return nil, fmt.Errorf("export of %q is a %s, not a channel", chat.Name, chat.Type)
+	}
+
+	logs := make([]models.PostLog, 0, len(chat.Messages))
+	for _, message := range chat.Messages {
+		if message.Type != "message" {
+			continue
+		}
+		unix, err := strconv.ParseInt(message.DateUnixTime, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("message %d has an invalid date %q: %w", message.ID, message.DateUnixTime, err)
+		}
+		caption, err := messageText(message.Text)
+		if err != nil {
+			return nil, fmt.Errorf("message %d has malformed text: %w", message.ID, err)
+		}
+		logs = append(logs, models.PostLog{
+			Caption:       caption,
+			MessageType:   messageType(message),
+			PublishedAt:   time.Unix(unix, 0),
+			ChannelID:     channelID,
+			ChannelPostID: message.ID,
+		})
+	}
+	return logs, nil
+}
+
+// messageType returns the post log message type of an exported message.
+func messageType(message exportedMessage) string {
+	switch {
+	case message.Photo != "":
+		return "photo"
+	case message.MediaType == "":
+		return "text"
+	case mediaTypes[message.MediaType] != "":
+		return mediaTypes[message.MediaType]
+	default:
+		return message.MediaType
+	}
+}
+
+// messageText flattens the text of an exported message, which is either a string or a list
+// of strings and formatted parts with their own text, into plain text.
+func messageText(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, nil
+	}
+	var parts []json.RawMessage
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, part := range parts {
+		var plain string
+		if err := json.Unmarshal(part, &plain); err == nil {
+			b.WriteString(plain)
+			continue
+		}
+		var formatted struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(part, &formatted); err != nil {
+			return "", err
+		}
+		b.WriteString(formatted.Text)
+	}
+	return b.String(), nil
+}
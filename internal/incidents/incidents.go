@@ -0,0 +1,66 @@
+// Package incidents records significant runtime failures (handler panics, failed
+// publications, database outages, webhook resets) so admins can browse them with /incidents.
+package incidents
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+)
+
+const (
+	// idBytes is the length of an incident ID in random bytes; it is shown as hex.
+	idBytes = 4
+	// recordTimeout bounds storing an incident.
+	recordTimeout = 10 * time.Second
+)
+
+// NewID returns a short random incident ID users can quote when reporting a problem.
+func NewID() string {
+	b := make([]byte, idBytes)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%08X", time.Now().UnixNano()&0xFFFFFFFF)
+	}
+	return strings.ToUpper(hex.EncodeToString(b))
+}
+
+// Recorder stores incidents.
+type Recorder struct {
+	repo database.IncidentRepository
+}
+
+// NewRecorder creates an incident recorder.
+func NewRecorder(repo database.IncidentRepository) *Recorder {
+	if repo == nil {
+		log.Fatal("Incident Recorder: Incident repository is nil")
+	}
+	return &Recorder{repo: repo}
+}
+
+// Record stores an open incident and returns its ID. An incident without an ID or time gets
+// a new ID and the current time. Failures to store it are logged, not returned: the
+// incident is in the log either way, and the caller is usually handling a failure already.
+func (r *Recorder) Record(incident models.Incident) string {
+	if incident.ID == "" {
+		incident.ID = NewID()
+	}
+	if incident.OccurredAt.IsZero() {
+		incident.OccurredAt = time.Now()
+	}
+	incident.Status = models.IncidentStatusOpen
+	log.Printf("[Incident:%s] %s: %s", incident.ID, incident.Type, incident.Summary)
+
+	// Incidents are often recorded while the caller's context is what ran out
+	ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+	if err := r.repo.LogIncident(ctx, &incident); err != nil {
+		log.Printf("[Incident:%s] Error storing incident: %v", incident.ID, err)
+	}
+	return incident.ID
+}
@@ -3,8 +3,11 @@ package locales
 import (
 	"embed"
 	"encoding/json"
+	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"golang.org/x/text/language"
@@ -14,20 +17,26 @@ import (
 var localeFS embed.FS
 
 var (
-	bundle          *i18n.Bundle
-	defaultLanguage language.Tag // Store the parsed default language tag
+	bundle              *i18n.Bundle
+	defaultLanguage     language.Tag        // Store the parsed default language tag
+	defaultLanguageMu   sync.RWMutex        // Guards defaultLanguage, which /setup can change at runtime
+	missingTranslations map[string][]string // Message IDs missing from each message file
+	loadedLanguages     []language.Tag      // Languages with a loaded message file
 )
 
 // Init initializes the i18n bundle by loading language files and setting the default language.
 func Init(defaultLangCode string) {
-	var err error
-	defaultLanguage, err = language.Parse(defaultLangCode)
+	defaultTag, err := language.Parse(defaultLangCode)
 	if err != nil {
 		log.Printf("WARN: Failed to parse default language code '%s': %v. Falling back to English.", defaultLangCode, err)
-		defaultLanguage = language.English // Changed fallback to English
+		defaultTag = language.English // Changed fallback to English
 	}
+	defaultLanguageMu.Lock()
+	defaultLanguage = defaultTag
+	defaultLanguageMu.Unlock()
 
-	bundle = i18n.NewBundle(defaultLanguage) // Use the parsed default language
+	bundle = i18n.NewBundle(defaultTag) // Use the parsed default language
+	loadedLanguages = nil
 	// Register the unmarshal function for JSON files
 	bundle.RegisterUnmarshalFunc("json", json.Unmarshal)
 
@@ -39,23 +48,129 @@ func Init(defaultLangCode string) {
 	}
 
 	loadedFiles := 0
+	messageIDsByFile := make(map[string]map[string]struct{})
 	for _, file := range fs {
 		// Check if it's a JSON file
 		if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
 			filePath := file.Name() // Path is relative to the embed root
-			_, err := bundle.LoadMessageFileFS(localeFS, filePath)
+			messageFile, err := bundle.LoadMessageFileFS(localeFS, filePath)
 			if err != nil {
 				log.Printf("WARN: Failed to load message file '%s': %v", filePath, err)
 			} else {
 				log.Printf("Successfully loaded message file: %s", filePath)
 				loadedFiles++
+				loadedLanguages = append(loadedLanguages, messageFile.Tag)
+				messageIDsByFile[filePath] = readMessageIDs(filePath)
 			}
 		}
 	}
 	if loadedFiles == 0 {
 		log.Fatalf("No message files loaded from locales/")
 	}
-	log.Printf("i18n bundle initialized with %d file(s). Default language: %s", loadedFiles, defaultLanguage.String())
+	missingTranslations = findMissingTranslations(messageIDsByFile)
+	for file, missing := range missingTranslations {
+		log.Printf("WARN: Message file '%s' is missing %d translation(s): %s", file, len(missing), strings.Join(missing, ", "))
+	}
+	log.Printf("i18n bundle initialized with %d file(s). Default language: %s", loadedFiles, defaultTag.String())
+}
+
+// readMessageIDs returns the set of message IDs declared in an embedded message file.
+func readMessageIDs(filePath string) map[string]struct{} {
+	ids := make(map[string]struct{})
+	data, err := localeFS.ReadFile(filePath)
+	if err != nil {
+		return ids
+	}
+	var entries []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		log.Printf("WARN: Failed to read message IDs from '%s': %v", filePath, err)
+		return ids
+	}
+	for _, e := range entries {
+		ids[e.ID] = struct{}{}
+	}
+	return ids
+}
+
+// findMissingTranslations returns, by message file, the message IDs that exist in some locale
+// files but not in it, so gaps in locale coverage are visible at startup instead of silently
+// falling back to English.
+func findMissingTranslations(messageIDsByFile map[string]map[string]struct{}) map[string][]string {
+	result := make(map[string][]string)
+	allIDs := make(map[string]struct{})
+	for _, ids := range messageIDsByFile {
+		for id := range ids {
+			allIDs[id] = struct{}{}
+		}
+	}
+	for file, ids := range messageIDsByFile {
+		missing := make([]string, 0)
+		for id := range allIDs {
+			if _, ok := ids[id]; !ok {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			result[file] = missing
+		}
+	}
+	return result
+}
+
+// MissingTranslations returns, by message file, the message IDs other message files have
+// but it lacks. Files without gaps are not included.
+func MissingTranslations() map[string][]string {
+	return missingTranslations
+}
+
+// SupportedLanguages returns the base language codes (e.g., "en", "ru") that have loaded message files.
+func SupportedLanguages() []string {
+	if bundle == nil {
+		log.Panicln("Attempted to get supported languages before i18n bundle initialization.")
+	}
+	// Not bundle.LanguageTags: it also lists the default language, which may have no message file
+	langs := make([]string, 0, len(loadedLanguages))
+	for _, tag := range loadedLanguages {
+		base, _ := tag.Base()
+		langs = append(langs, base.String())
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// IsSupported reports whether the given language code (e.g., "en", "ru-RU") has a loaded message file.
+func IsSupported(lang string) bool {
+	if lang == "" {
+		return false
+	}
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return false
+	}
+	base, _ := tag.Base()
+	for _, supported := range SupportedLanguages() {
+		if supported == base.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveLanguage returns the base code of the first supported language among the candidates,
+// in priority order (e.g., saved preference, then Telegram client language).
+// It falls back to the default language if none of the candidates is supported.
+func ResolveLanguage(candidates ...string) string {
+	for _, candidate := range candidates {
+		if IsSupported(candidate) {
+			tag, _ := language.Parse(candidate)
+			base, _ := tag.Base()
+			return base.String()
+		}
+	}
+	return GetDefaultLanguageTag().String()
 }
 
 // GetDefaultLanguageTag returns the configured default language tag.
@@ -63,9 +178,26 @@ func GetDefaultLanguageTag() language.Tag {
 	if bundle == nil { // Ensure Init was called
 		log.Panicln("Attempted to get default language tag before i18n bundle initialization.")
 	}
+	defaultLanguageMu.RLock()
+	defer defaultLanguageMu.RUnlock()
 	return defaultLanguage
 }
 
+// SetDefaultLanguage changes the language used for users without a supported language, e.g. when
+// the owner picks one in /setup. Messages missing from a file still fall back to the language Init
+// was called with.
+func SetDefaultLanguage(lang string) error {
+	if !IsSupported(lang) {
+		return fmt.Errorf("language %q has no message file", lang)
+	}
+	tag, _ := language.Parse(lang)
+	base, _ := tag.Base()
+	defaultLanguageMu.Lock()
+	defer defaultLanguageMu.Unlock()
+	defaultLanguage = language.Make(base.String())
+	return nil
+}
+
 // NewLocalizer creates a localizer for the given language preferences.
 // It takes language tags (e.g., "en", "ru") or Accept-Language header string.
 func NewLocalizer(langPrefs ...string) *i18n.Localizer {
@@ -109,3 +241,15 @@ func GetMessage(localizer *i18n.Localizer, msgID string, templateData map[string
 	}
 	return localizedMsg
 }
+
+// GetPluralMessage retrieves a message that has CLDR plural forms (one/few/many/other)
+// and selects the correct form for count. The count is also exposed to the template
+// as {{.Count}}, so callers pass the number instead of a pre-formatted string.
+func GetPluralMessage(localizer *i18n.Localizer, msgID string, count int, templateData map[string]interface{}) string {
+	data := make(map[string]interface{}, len(templateData)+1)
+	for k, v := range templateData {
+		data[k] = v
+	}
+	data["Count"] = count
+	return GetMessage(localizer, msgID, data, &count)
+}
@@ -0,0 +1,78 @@
+package locales
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPluralMessage(t *testing.T) {
+	Init("en")
+
+	tests := []struct {
+		lang  string
+		count int
+		want  string
+	}{
+		{"en", 0, "📥 0 suggestions are pending review."},
+		{"en", 1, "📥 1 suggestion is pending review."},
+		{"en", 2, "📥 2 suggestions are pending review."},
+		{"ru", 1, "📥 На рассмотрении 1 предложение."},
+		{"ru", 21, "📥 На рассмотрении 21 предложение."},
+		{"ru", 3, "📥 На рассмотрении 3 предложения."},
+		{"ru", 24, "📥 На рассмотрении 24 предложения."},
+		{"ru", 5, "📥 На рассмотрении 5 предложений."},
+		{"ru", 11, "📥 На рассмотрении 11 предложений."},
+		{"ru", 0, "📥 На рассмотрении 0 предложений."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			got := GetPluralMessage(NewLocalizer(tt.lang), "MsgReviewPendingCount", tt.count, nil)
+			assert.Equal(t, tt.want, got, "count %d", tt.count)
+		})
+	}
+}
+
+func TestGetPluralMessageKeepsTemplateData(t *testing.T) {
+	Init("en")
+	data := map[string]interface{}{"Other": "kept"}
+
+	got := GetPluralMessage(NewLocalizer("en"), "MsgReviewPendingCount", 2, data)
+	assert.Equal(t, "📥 2 suggestions are pending review.", got)
+	assert.NotContains(t, data, "Count", "caller's template data was modified")
+}
+
+// TestPluralMessagesHaveEveryForm checks that each plural message defines all CLDR forms
+// its language uses, since go-i18n fails to localize a count whose form is missing.
+func TestPluralMessagesHaveEveryForm(t *testing.T) {
+	forms := map[string][]string{
+		"en.json": {"one", "other"},
+		"ru.json": {"one", "few", "many", "other"},
+	}
+	for file, want := range forms {
+		t.Run(file, func(t *testing.T) {
+			raw, err := localeFS.ReadFile(file)
+			require.NoError(t, err)
+			var messages []struct {
+				ID          string          `json:"id"`
+				Translation json.RawMessage `json:"translation"`
+			}
+			require.NoError(t, json.Unmarshal(raw, &messages))
+
+			plurals := 0
+			for _, msg := range messages {
+				var translation map[string]string
+				if json.Unmarshal(msg.Translation, &translation) != nil {
+					continue // Not a plural message
+				}
+				plurals++
+				for _, form := range want {
+					assert.NotEmpty(t, translation[form], "%s has no %q form", msg.ID, form)
+				}
+			}
+			assert.Positive(t, plurals)
+		})
+	}
+}
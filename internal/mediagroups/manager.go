@@ -11,9 +11,14 @@ import (
 )
 
 const (
-	// DefaultProcessDelay specifies the default time to wait before processing a group.
+	// DefaultProcessDelay specifies the default maximum time to wait before processing a group.
 	DefaultProcessDelay = 2 * time.Second
+	// DefaultIdleDelay specifies how long to wait for the next album item before processing early.
+	// Telegram delivers album items in quick succession, so a short gap means the album is complete.
+	// It stays below DefaultProcessDelay, which still bounds the wait for slowly arriving albums.
+	DefaultIdleDelay = 800 * time.Millisecond
 	// DefaultMaxGroupSize limits the number of messages stored per group.
+	// Telegram albums hold at most 10 items.
 	DefaultMaxGroupSize = 10
 )
 
@@ -25,34 +30,65 @@ type ProcessFunc func(ctx context.Context, groupID string, messages []telego.Mes
 type mediaGroupState struct {
 	messages []telego.Message
 	timer    *time.Timer
+	firstAt  time.Time // When the first message of the group arrived
 	mu       sync.Mutex
 	// firstMsg flag might not be needed if LoadOrStore handles initialization correctly
 }
 
 // Manager handles the collection and processing of media groups.
 type Manager struct {
-	groups sync.Map // map[string]*mediaGroupState
+	groups       sync.Map      // map[string]*mediaGroupState
+	processDelay time.Duration // Maximum time to wait for the rest of an album before processing it
+	idleDelay    time.Duration // Process early once no new item arrived for this long (0 disables)
+	maxGroupSize int           // Maximum number of messages stored per group
 }
 
-// NewManager creates a new media group manager.
+// NewManager creates a new media group manager using the default delay and group size.
 func NewManager() *Manager {
-	return &Manager{}
+	return &Manager{
+		processDelay: DefaultProcessDelay,
+		idleDelay:    DefaultIdleDelay,
+		maxGroupSize: DefaultMaxGroupSize,
+	}
+}
+
+// SetLimits overrides the processing delay and maximum group size.
+// Zero values keep the defaults.
+func (m *Manager) SetLimits(delay time.Duration, maxSize int) {
+	if delay > 0 {
+		m.processDelay = delay
+	}
+	if maxSize > 0 {
+		m.maxGroupSize = maxSize
+	}
+}
+
+// SetIdleDelay sets how long to wait for the next album item before processing the group early.
+// A non-positive value disables early processing, so groups always wait the full delay.
+func (m *Manager) SetIdleDelay(delay time.Duration) {
+	m.idleDelay = delay
+}
+
+// MaxGroupSize returns the maximum number of messages stored per group.
+func (m *Manager) MaxGroupSize() int {
+	return m.maxGroupSize
 }
 
-// HandleMessage adds a message to its group and schedules processing if it's the first message.
-// It takes the message, the function to process the group, the delay before processing,
-// the maximum size for the group, and an optional parent context for the processor.
+// HandleMessage adds a message to its group and (re)schedules processing of the group.
+// The group is processed by handler as soon as it reaches the maximum group size, once no new
+// item has arrived for the idle delay, or at the latest when the manager's delay has passed
+// since its first message. Messages beyond the maximum group size are dropped.
 func (m *Manager) HandleMessage(
 	parentCtx context.Context, // Context from the update handler, for potential cancellation
 	message telego.Message,
 	handler ProcessFunc,
-	delay time.Duration,
-	maxSize int,
 ) error {
 	if message.MediaGroupID == "" {
 		return nil // Not a media group message
 	}
 
+	maxSize := m.maxGroupSize
+
 	groupID := message.MediaGroupID
 
 	// Get or create the state for this group ID
@@ -65,64 +101,75 @@ func (m *Manager) HandleMessage(
 
 	// Lock the specific group state for modification
 	state.mu.Lock()
+	defer state.mu.Unlock()
 
 	// Add message if not already present and within size limit
-	found := false
 	for _, msg := range state.messages {
 		if msg.MessageID == message.MessageID {
-			found = true
-			break
+			return nil // Duplicate delivery of a message we already have
 		}
 	}
-
-	wasEmpty := len(state.messages) == 0
-	messageAdded := false
-
-	if !found && len(state.messages) < maxSize {
-		state.messages = append(state.messages, message)
-		sort.Slice(state.messages, func(i, j int) bool {
-			return state.messages[i].MessageID < state.messages[j].MessageID
-		})
-		messageAdded = true
-		log.Printf("[MediaGroupManager Group:%s] Added message %d. Total: %d", groupID, message.MessageID, len(state.messages))
-	} else if !found {
+	if len(state.messages) >= maxSize {
 		log.Printf("[MediaGroupManager Group:%s] Group limit (%d) reached, message %d dropped.", groupID, maxSize, message.MessageID)
+		return nil
+	}
+
+	state.messages = append(state.messages, message)
+	sort.Slice(state.messages, func(i, j int) bool {
+		return state.messages[i].MessageID < state.messages[j].MessageID
+	})
+	log.Printf("[MediaGroupManager Group:%s] Added message %d. Total: %d", groupID, message.MessageID, len(state.messages))
+
+	m.scheduleLocked(groupID, state, handler)
+	return nil
+}
+
+// scheduleLocked (re)arms the group's processing timer after a message was added.
+// The caller must hold state.mu.
+func (m *Manager) scheduleLocked(groupID string, state *mediaGroupState, handler ProcessFunc) {
+	now := time.Now()
+	if state.firstAt.IsZero() {
+		state.firstAt = now
 	}
 
-	// Schedule processing only if the group was previously empty and a message was successfully added
-	shouldSetTimer := wasEmpty && messageAdded
+	// Never wait past the maximum delay counted from the first message
+	wait := time.Until(state.firstAt.Add(m.processDelay))
+	if m.idleDelay > 0 && m.idleDelay < wait {
+		wait = m.idleDelay
+	}
+	if len(state.messages) >= m.maxGroupSize {
+		wait = 0 // The album can't grow any further
+	}
+	wait = max(wait, 0)
 
-	state.mu.Unlock() // Unlock before setting the timer
+	if state.timer != nil {
+		// If the timer already fired, this schedules a second run that finds the group removed
+		state.timer.Reset(wait)
+		return
+	}
+	log.Printf("[MediaGroupManager Group:%s] First message stored. Scheduling processing in %v.", groupID, wait)
+	state.timer = time.AfterFunc(wait, func() {
+		m.processGroup(groupID, handler)
+	})
+}
 
-	if shouldSetTimer {
-		log.Printf("[MediaGroupManager Group:%s] First message stored. Scheduling processing in %v.", groupID, delay)
+// processGroup removes a group and passes its messages to handler.
+func (m *Manager) processGroup(groupID string, handler ProcessFunc) {
+	// Use background context for processing for now.
+	// Passing parentCtx requires more complex management (e.g., linking it to timer cancellation).
+	processCtx := context.Background()
 
-		// Re-lock state to safely set the timer
-		state.mu.Lock()
-		// Check again if timer already exists (rare race condition, but possible)
-		if state.timer == nil {
-			state.timer = time.AfterFunc(delay, func() {
-				// Use background context for processing for now.
-				// Passing parentCtx requires more complex management (e.g., linking it to timer cancellation).
-				processCtx := context.Background()
-
-				finalMessages := m.getAndRemoveGroup(groupID)
-				if finalMessages == nil || len(finalMessages) == 0 {
-					log.Printf("[MediaGroupManager Group:%s] Timer fired, but group was empty or already removed.", groupID)
-					return
-				}
-
-				log.Printf("[MediaGroupManager Group:%s] Timer fired. Processing %d messages.", groupID, len(finalMessages))
-				if err := handler(processCtx, groupID, finalMessages); err != nil {
-					log.Printf("[MediaGroupManager Group:%s] Error processing group: %v", groupID, err)
-					// Consider adding Sentry reporting here
-				}
-			})
-		}
-		state.mu.Unlock()
+	finalMessages := m.getAndRemoveGroup(groupID)
+	if len(finalMessages) == 0 {
+		log.Printf("[MediaGroupManager Group:%s] Timer fired, but group was empty or already removed.", groupID)
+		return
 	}
 
-	return nil
+	log.Printf("[MediaGroupManager Group:%s] Timer fired. Processing %d messages.", groupID, len(finalMessages))
+	if err := handler(processCtx, groupID, finalMessages); err != nil {
+		log.Printf("[MediaGroupManager Group:%s] Error processing group: %v", groupID, err)
+		// Consider adding Sentry reporting here
+	}
 }
 
 // getAndRemoveGroup atomically retrieves messages and removes the group state.
@@ -176,5 +223,3 @@ func (m *Manager) Shutdown() {
 	})
 	log.Printf("[MediaGroupManager] Shutdown complete. Stopped %d active timer(s).", stoppedCount)
 }
-
-// TODO: Add a Shutdown method to gracefully stop all active timers.
@@ -0,0 +1,138 @@
+package mediagroups
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const groupID = "album"
+
+// processedGroup is one call of a ProcessFunc.
+type processedGroup struct {
+	messages []telego.Message
+	at       time.Time
+}
+
+// collect returns a ProcessFunc reporting the processed groups on the returned channel.
+func collect() (ProcessFunc, <-chan processedGroup) {
+	processed := make(chan processedGroup, 10)
+	return func(_ context.Context, _ string, messages []telego.Message) error {
+		processed <- processedGroup{messages: messages, at: time.Now()}
+		return nil
+	}, processed
+}
+
+func albumItem(id int) telego.Message {
+	return telego.Message{MessageID: id, MediaGroupID: groupID}
+}
+
+func waitForGroup(t *testing.T, processed <-chan processedGroup) processedGroup {
+	t.Helper()
+	select {
+	case group := <-processed:
+		return group
+	case <-time.After(5 * time.Second):
+		t.Fatal("group was not processed")
+		return processedGroup{}
+	}
+}
+
+func messageIDs(messages []telego.Message) []int {
+	ids := make([]int, 0, len(messages))
+	for _, msg := range messages {
+		ids = append(ids, msg.MessageID)
+	}
+	return ids
+}
+
+func TestDefaultIdleDelayIsBelowProcessDelay(t *testing.T) {
+	assert.Positive(t, DefaultIdleDelay)
+	assert.Less(t, DefaultIdleDelay, DefaultProcessDelay)
+}
+
+func TestIdleAlbumIsProcessedEarly(t *testing.T) {
+	m := NewManager()
+	m.SetLimits(time.Minute, 0)
+	m.SetIdleDelay(20 * time.Millisecond)
+	handler, processed := collect()
+
+	start := time.Now()
+	require.NoError(t, m.HandleMessage(context.Background(), albumItem(2), handler))
+	require.NoError(t, m.HandleMessage(context.Background(), albumItem(1), handler))
+
+	group := waitForGroup(t, processed)
+	assert.Equal(t, []int{1, 2}, messageIDs(group.messages), "items are processed in message order")
+	assert.Less(t, group.at.Sub(start), time.Minute/2, "album waited for the full delay")
+}
+
+func TestIdleDelayNeverExceedsProcessDelay(t *testing.T) {
+	m := NewManager()
+	m.SetLimits(100*time.Millisecond, 0)
+	m.SetIdleDelay(time.Minute)
+	handler, processed := collect()
+
+	require.NoError(t, m.HandleMessage(context.Background(), albumItem(1), handler))
+	group := waitForGroup(t, processed)
+	assert.Equal(t, []int{1}, messageIDs(group.messages))
+}
+
+func TestFullAlbumIsProcessedImmediately(t *testing.T) {
+	m := NewManager()
+	m.SetLimits(time.Minute, 3)
+	m.SetIdleDelay(time.Minute)
+	handler, processed := collect()
+
+	for id := 1; id <= 3; id++ {
+		require.NoError(t, m.HandleMessage(context.Background(), albumItem(id), handler))
+	}
+	group := waitForGroup(t, processed)
+	assert.Equal(t, []int{1, 2, 3}, messageIDs(group.messages))
+}
+
+func TestDuplicateDeliveryIsDropped(t *testing.T) {
+	m := NewManager()
+	m.SetLimits(time.Minute, 0)
+	m.SetIdleDelay(20 * time.Millisecond)
+	handler, processed := collect()
+
+	require.NoError(t, m.HandleMessage(context.Background(), albumItem(1), handler))
+	require.NoError(t, m.HandleMessage(context.Background(), albumItem(1), handler))
+	require.NoError(t, m.HandleMessage(context.Background(), albumItem(2), handler))
+
+	group := waitForGroup(t, processed)
+	assert.Equal(t, []int{1, 2}, messageIDs(group.messages))
+}
+
+func TestLateItemStartsNewGroup(t *testing.T) {
+	m := NewManager()
+	m.SetLimits(time.Minute, 0)
+	m.SetIdleDelay(20 * time.Millisecond)
+	handler, processed := collect()
+
+	require.NoError(t, m.HandleMessage(context.Background(), albumItem(1), handler))
+	require.NoError(t, m.HandleMessage(context.Background(), albumItem(2), handler))
+	first := waitForGroup(t, processed)
+	assert.Equal(t, []int{1, 2}, messageIDs(first.messages))
+
+	// An item arriving after its album was processed is processed on its own
+	require.NoError(t, m.HandleMessage(context.Background(), albumItem(3), handler))
+	late := waitForGroup(t, processed)
+	assert.Equal(t, []int{3}, messageIDs(late.messages))
+}
+
+func TestMessagesWithoutGroupAreIgnored(t *testing.T) {
+	m := NewManager()
+	handler, processed := collect()
+
+	require.NoError(t, m.HandleMessage(context.Background(), telego.Message{MessageID: 1}, handler))
+	select {
+	case <-processed:
+		t.Fatal("message without a media group was processed")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,126 @@
+// Package mediaproxy re-uploads media through the bot, so messages made from files users sent
+// don't share file IDs with the originals and can't be traced back to them by file ID.
+package mediaproxy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const (
+	// MaxFileSize is the largest file the Bot API lets bots download.
+	MaxFileSize = 20 << 20
+	// downloadTimeout bounds a single download.
+	downloadTimeout = time.Minute
+	// maxRemembered bounds how many uploads are remembered; the oldest half is dropped when it's reached.
+	maxRemembered = 1000
+)
+
+// FileGetter is the part of the bot API used to download files. *telego.Bot implements it.
+type FileGetter interface {
+	GetFile(ctx context.Context, params *telego.GetFileParams) (*telego.File, error)
+	FileDownloadURL(filepath string) string
+}
+
+// Proxy downloads files by file ID and hands them out for upload. Once a file has been
+// uploaded, the file ID of the bot's own copy is remembered and used instead.
+type Proxy struct {
+	bot    FileGetter
+	client *http.Client
+
+	mu       sync.Mutex
+	uploaded map[string]string // Original file ID -> file ID of the bot's upload
+	order    []string          // Original file IDs in the order they were remembered
+}
+
+// New creates a media proxy that downloads files through the bot.
+func New(bot FileGetter) *Proxy {
+	return &Proxy{
+		bot:      bot,
+		client:   &http.Client{Timeout: downloadTimeout},
+		uploaded: make(map[string]string),
+	}
+}
+
+// InputFile returns the file to send in place of the original: the bot's earlier upload of it
+// if there is one, and otherwise its downloaded contents, uploaded under the given name.
+func (p *Proxy) InputFile(ctx context.Context, fileID, name string) (telego.InputFile, error) {
+	p.mu.Lock()
+	uploaded, ok := p.uploaded[fileID]
+	p.mu.Unlock()
+	if ok {
+		return tu.FileFromID(uploaded), nil
+	}
+
+	data, err := p.Download(ctx, fileID)
+	if err != nil {
+		return telego.InputFile{}, err
+	}
+	return tu.File(tu.NameReader(bytes.NewReader(data), name)), nil
+}
+
+// Remember records the file ID Telegram assigned to an upload of the original file,
+// so the upload is reused instead of downloading the original again.
+func (p *Proxy) Remember(original, uploaded string) {
+	if original == "" || uploaded == "" || original == uploaded {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.uploaded[original]; !ok {
+		if len(p.order) >= maxRemembered {
+			dropped := p.order[:len(p.order)/2]
+			for _, id := range dropped {
+				delete(p.uploaded, id)
+			}
+			p.order = append([]string(nil), p.order[len(dropped):]...)
+		}
+		p.order = append(p.order, original)
+	}
+	p.uploaded[original] = uploaded
+}
+
+// Download fetches the contents of a file, up to MaxFileSize. The bot's uploads aren't used, so
+// the original file is returned.
+func (p *Proxy) Download(ctx context.Context, fileID string) ([]byte, error) {
+	file, err := p.bot.GetFile(ctx, &telego.GetFileParams{FileID: fileID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file %s: %w", fileID, err)
+	}
+	if file.FilePath == "" {
+		return nil, fmt.Errorf("file %s can't be downloaded", fileID)
+	}
+	if file.FileSize > MaxFileSize {
+		return nil, fmt.Errorf("file %s is too large to download (%d bytes)", fileID, file.FileSize)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.bot.FileDownloadURL(file.FilePath), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download request for file %s: %w", fileID, err)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		// The error contains the download URL, and with it the bot token
+		return nil, fmt.Errorf("failed to download file %s", fileID)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file %s: status %d", fileID, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", fileID, err)
+	}
+	if len(data) > MaxFileSize {
+		return nil, fmt.Errorf("file %s is too large to download", fileID)
+	}
+	return data, nil
+}
@@ -0,0 +1,58 @@
+package mediaproxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFiles struct {
+	server *httptest.Server
+	gets   int
+}
+
+func (f *fakeFiles) GetFile(_ context.Context, params *telego.GetFileParams) (*telego.File, error) {
+	f.gets++
+	return &telego.File{FileID: params.FileID, FilePath: "photos/" + params.FileID + ".jpg"}, nil
+}
+
+func (f *fakeFiles) FileDownloadURL(filepath string) string {
+	return f.server.URL + "/file/" + filepath
+}
+
+func TestProxyDownloadsUntilUploadIsRemembered(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/file/photos/orig.jpg" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("image bytes"))
+	}))
+	defer server.Close()
+	files := &fakeFiles{server: server}
+	proxy := New(files)
+
+	file, err := proxy.InputFile(context.Background(), "orig", "photo.jpg")
+	require.NoError(t, err)
+	assert.Empty(t, file.FileID)
+	require.NotNil(t, file.File)
+	assert.Equal(t, "photo.jpg", file.File.Name())
+	data, err := io.ReadAll(file.File)
+	require.NoError(t, err)
+	assert.Equal(t, "image bytes", string(data))
+
+	proxy.Remember("orig", "bot-copy")
+	file, err = proxy.InputFile(context.Background(), "orig", "photo.jpg")
+	require.NoError(t, err)
+	assert.Equal(t, "bot-copy", file.FileID)
+	assert.Equal(t, 1, files.gets)
+
+	_, err = proxy.InputFile(context.Background(), "missing", "photo.jpg")
+	assert.ErrorContains(t, err, "status 404")
+}
@@ -0,0 +1,112 @@
+// Package ocr extracts the text embedded in meme images, so suggestions and posts can be found by
+// it and duplicates noticed even when they were sent without a caption. The recognition itself is
+// done by a pluggable Engine, such as an external command like tesseract.
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// DefaultTimeout bounds downloading and recognizing one image.
+	DefaultTimeout = 30 * time.Second
+	// minKeyLength is the shortest normalized text used as a Key; shorter texts, such as a
+	// watermark or a single word, are too common to tell memes apart.
+	minKeyLength = 12
+	// maxTextLength bounds the stored text of an image, in runes.
+	maxTextLength = 2000
+)
+
+// Engine recognizes the text in an image.
+type Engine interface {
+	// Recognize returns the text found in an encoded image, or "" if there is none.
+	Recognize(ctx context.Context, image []byte) (string, error)
+}
+
+// Downloader fetches the contents of a Telegram file. *mediaproxy.Proxy implements it.
+type Downloader interface {
+	Download(ctx context.Context, fileID string) ([]byte, error)
+}
+
+// CommandEngine recognizes text by running a command that reads the image from standard input
+// and writes the text to standard output, e.g. "tesseract stdin stdout -l eng+rus".
+type CommandEngine struct {
+	name string
+	args []string
+}
+
+// NewCommandEngine creates an engine running a command line; arguments are separated by spaces.
+func NewCommandEngine(commandLine string) (*CommandEngine, error) {
+	fields := strings.Fields(commandLine)
+	if len(fields) == 0 {
+		return nil, errors.New("OCR command is empty")
+	}
+	if _, err := exec.LookPath(fields[0]); err != nil {
+		return nil, fmt.Errorf("OCR command not found: %w", err)
+	}
+	return &CommandEngine{name: fields[0], args: fields[1:]}, nil
+}
+
+// Recognize runs the command on an image.
+func (e *CommandEngine) Recognize(ctx context.Context, image []byte) (string, error) {
+	cmd := exec.CommandContext(ctx, e.name, e.args...)
+	cmd.Stdin = bytes.NewReader(image)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("OCR command %s failed: %w: %s", e.name, err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// Reader recognizes the text of Telegram photos.
+type Reader struct {
+	downloader Downloader
+	engine     Engine
+	timeout    time.Duration
+}
+
+// NewReader creates a reader that downloads photos with downloader and recognizes them with engine.
+func NewReader(downloader Downloader, engine Engine) *Reader {
+	return &Reader{downloader: downloader, engine: engine, timeout: DefaultTimeout}
+}
+
+// ReadFile returns the text of a photo, with whitespace collapsed and cut to a reasonable length.
+func (r *Reader) ReadFile(ctx context.Context, fileID string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	image, err := r.downloader.Download(ctx, fileID)
+	if err != nil {
+		return "", err
+	}
+	text, err := r.engine.Recognize(ctx, image)
+	if err != nil {
+		return "", err
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > maxTextLength {
+		text = string(runes[:maxTextLength])
+	}
+	return text, nil
+}
+
+// Key normalizes recognized text for duplicate detection: letters and digits only, lowercased,
+// words separated by single spaces. It returns "" for texts too short to tell memes apart.
+func Key(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	key := strings.Join(words, " ")
+	if len([]rune(key)) < minKeyLength {
+		return ""
+	}
+	return key
+}
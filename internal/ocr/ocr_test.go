@@ -0,0 +1,38 @@
+package ocr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "when the code compiles", Key("  When the CODE\ncompiles!!! "))
+	assert.Equal(t, Key("Мем — про КОТА, который спит"), Key("мем про кота который спит"))
+	assert.Empty(t, Key("@vrcmemes"), "short texts are too common to compare")
+}
+
+// downloaderFunc adapts a function to Downloader.
+type downloaderFunc func(ctx context.Context, fileID string) ([]byte, error)
+
+func (f downloaderFunc) Download(ctx context.Context, fileID string) ([]byte, error) {
+	return f(ctx, fileID)
+}
+
+func TestReaderRunsCommandOnDownloadedImage(t *testing.T) {
+	// cat prints the "image" it is given, standing in for an OCR engine
+	engine, err := NewCommandEngine("cat")
+	require.NoError(t, err)
+	reader := NewReader(downloaderFunc(func(ctx context.Context, fileID string) ([]byte, error) {
+		return []byte("text of " + fileID + "\n\n  in   two lines\n"), nil
+	}), engine)
+
+	text, err := reader.ReadFile(context.Background(), "photo-1")
+	require.NoError(t, err)
+	assert.Equal(t, "text of photo-1 in two lines", text)
+
+	_, err = NewCommandEngine("no-such-ocr-command")
+	assert.Error(t, err)
+}
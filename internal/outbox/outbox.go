@@ -0,0 +1,178 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const (
+	// DefaultMaxAttempts is how many times a notification is tried before it is marked as failed.
+	DefaultMaxAttempts = 5
+	// pollInterval is how often the outbox is checked for notifications that became due.
+	pollInterval = 5 * time.Second
+	// claimLease is how long a claimed notification is reserved for one delivery attempt.
+	// If the bot stops mid-delivery, the notification is retried after the lease.
+	claimLease = time.Minute
+	// sendTimeout bounds a single delivery attempt.
+	sendTimeout = 30 * time.Second
+	// baseBackoff is the pause after the first failed attempt; it doubles with each attempt.
+	baseBackoff = 30 * time.Second
+	// maxBackoff caps the pause between attempts.
+	maxBackoff = time.Hour
+)
+
+// Outbox delivers user notifications reliably: each one is stored before it is sent, and a
+// dispatcher sends the stored notifications, retrying failures with backoff, so a crash or a
+// Telegram outage between deciding to notify and sending doesn't lose the notification.
+type Outbox struct {
+	repo        database.OutboxRepository
+	bot         telegoapi.BotAPI
+	maxAttempts int
+	wake        chan struct{} // Signals the dispatcher that a notification was enqueued
+}
+
+// New creates an outbox. Start must be called before notifications are delivered.
+func New(repo database.OutboxRepository, bot telegoapi.BotAPI) *Outbox {
+	if repo == nil {
+		log.Fatal("Outbox: Outbox repository is nil")
+	}
+	if bot == nil {
+		log.Fatal("Outbox: BotAPI instance is nil")
+	}
+	return &Outbox{
+		repo:        repo,
+		bot:         bot,
+		maxAttempts: DefaultMaxAttempts,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// SetMaxAttempts sets how many times a notification is tried. Non-positive values use the default.
+func (o *Outbox) SetMaxAttempts(n int) {
+	if n <= 0 {
+		n = DefaultMaxAttempts
+	}
+	o.maxAttempts = n
+}
+
+// Notify stores a plain text notification for a chat and wakes the dispatcher. An error means
+// the notification was not stored, so the caller may send it directly instead.
+func (o *Outbox) Notify(ctx context.Context, kind string, chatID int64, text string) error {
+	notification := &models.Notification{Kind: kind, ChatID: chatID, Text: text}
+	if err := o.repo.EnqueueNotification(ctx, notification); err != nil {
+		return err
+	}
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start runs the dispatcher until ctx is done. Notifications left over from a previous run
+// are delivered first.
+func (o *Outbox) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			o.dispatch(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-o.wake:
+			}
+		}
+	}()
+}
+
+// dispatch delivers the notifications that are due, one at a time, until none is left.
+func (o *Outbox) dispatch(ctx context.Context) {
+	for ctx.Err() == nil {
+		notification, err := o.repo.ClaimDueNotification(ctx, time.Now(), claimLease)
+		if err != nil {
+			log.Printf("[Outbox] Error claiming due notification: %v", err)
+			return
+		}
+		if notification == nil {
+			return
+		}
+		o.deliver(ctx, notification)
+	}
+}
+
+// deliver sends a claimed notification and records the outcome: sent, due again after a
+// backoff, or failed for good if the error is permanent or the attempts are used up.
+func (o *Outbox) deliver(ctx context.Context, notification *models.Notification) {
+	id := notification.ID.Hex()
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	_, sendErr := o.bot.SendMessage(sendCtx, tu.Message(tu.ID(notification.ChatID), notification.Text))
+	cancel()
+
+	var err error
+	switch {
+	case sendErr == nil:
+		err = o.repo.CompleteNotification(ctx, notification.ID)
+	case isPermanent(sendErr):
+		log.Printf("[Outbox] Giving up on %s notification %s for chat %d: %v", notification.Kind, id, notification.ChatID, sendErr)
+		err = o.repo.FailNotification(ctx, notification.ID, sendErr)
+	case notification.Attempts >= o.maxAttempts:
+		log.Printf("[Outbox] Giving up on %s notification %s for chat %d after %d attempts: %v",
+			notification.Kind, id, notification.ChatID, notification.Attempts, sendErr)
+		err = o.repo.FailNotification(ctx, notification.ID, fmt.Errorf("after %d attempts: %w", notification.Attempts, sendErr))
+	default:
+		delay := backoff(notification.Attempts)
+		if retryAfter, ok := telegoapi.RetryAfter(sendErr); ok {
+			delay = retryAfter
+		}
+		log.Printf("[Outbox] Attempt %d of %s notification %s failed, retrying in %s: %v",
+			notification.Attempts, notification.Kind, id, delay, sendErr)
+		err = o.repo.RetryNotification(ctx, notification.ID, time.Now().Add(delay), sendErr)
+	}
+	if err != nil {
+		// The claim lapses, so the notification is tried again
+		log.Printf("[Outbox] Error recording the outcome of notification %s: %v", id, err)
+	}
+}
+
+// isPermanent reports whether a send error won't go away by retrying, such as the user
+// having blocked the bot or a malformed message.
+func isPermanent(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	code := telegoapi.ErrorCode(err)
+	return code == http.StatusForbidden || code == http.StatusBadRequest
+}
+
+// backoff returns the pause after the given number of failed attempts.
+func backoff(attempts int) time.Duration {
+	delay := baseBackoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
+
+// Counts returns how many notifications are waiting for delivery and how many failed for good.
+func (o *Outbox) Counts(ctx context.Context) (pending, failed int64, err error) {
+	pending, err = o.repo.CountNotifications(ctx, models.NotificationStatusPending, models.NotificationStatusSending)
+	if err != nil {
+		return 0, 0, err
+	}
+	failed, err = o.repo.CountNotifications(ctx, models.NotificationStatusFailed)
+	if err != nil {
+		return 0, 0, err
+	}
+	return pending, failed, nil
+}
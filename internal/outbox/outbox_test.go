@@ -0,0 +1,162 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/pkg/telegoapi/telegoapitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryRepo is an in-memory database.OutboxRepository.
+type memoryRepo struct {
+	mu            sync.Mutex
+	notifications []*models.Notification
+}
+
+func (r *memoryRepo) EnqueueNotification(_ context.Context, n *models.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n.ID = primitive.NewObjectID()
+	n.Status = models.NotificationStatusPending
+	n.NextAttemptAt = time.Now()
+	r.notifications = append(r.notifications, n)
+	return nil
+}
+
+func (r *memoryRepo) ClaimDueNotification(_ context.Context, now time.Time, lease time.Duration) (*models.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, n := range r.notifications {
+		due := n.Status == models.NotificationStatusPending || n.Status == models.NotificationStatusSending
+		if due && !n.NextAttemptAt.After(now) {
+			n.Status = models.NotificationStatusSending
+			n.NextAttemptAt = now.Add(lease)
+			n.Attempts++
+			claimed := *n
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryRepo) find(id primitive.ObjectID) *models.Notification {
+	for _, n := range r.notifications {
+		if n.ID == id {
+			return n
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepo) CompleteNotification(_ context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.find(id).Status = models.NotificationStatusSent
+	return nil
+}
+
+func (r *memoryRepo) RetryNotification(_ context.Context, id primitive.ObjectID, at time.Time, cause error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := r.find(id)
+	n.Status, n.NextAttemptAt, n.Error = models.NotificationStatusPending, at, cause.Error()
+	return nil
+}
+
+func (r *memoryRepo) FailNotification(_ context.Context, id primitive.ObjectID, cause error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := r.find(id)
+	n.Status, n.Error = models.NotificationStatusFailed, cause.Error()
+	return nil
+}
+
+func (r *memoryRepo) CountNotifications(_ context.Context, statuses ...string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for _, n := range r.notifications {
+		for _, status := range statuses {
+			if n.Status == status {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+// makeDue makes all waiting notifications due, as if their backoff had passed.
+func (r *memoryRepo) makeDue() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, n := range r.notifications {
+		n.NextAttemptAt = time.Time{}
+	}
+}
+
+func TestOutboxRetriesAndGivesUp(t *testing.T) {
+	ctx := context.Background()
+	bot := telegoapitest.NewFakeBot()
+	repo := &memoryRepo{}
+	o := New(repo, bot)
+	o.SetMaxAttempts(2)
+
+	require.NoError(t, o.Notify(ctx, models.NotificationKindAdminPing, 1, "rate limited once"))
+	bot.RateLimitNext("SendMessage", 10*time.Second)
+	o.dispatch(ctx)
+	assert.Equal(t, models.NotificationStatusPending, repo.notifications[0].Status)
+	assert.WithinDuration(t, time.Now().Add(10*time.Second), repo.notifications[0].NextAttemptAt, 2*time.Second)
+
+	repo.makeDue()
+	o.dispatch(ctx)
+	assert.Equal(t, models.NotificationStatusSent, repo.notifications[0].Status)
+	assert.Len(t, bot.CallsTo("SendMessage", 1), 2)
+
+	// The user blocked the bot: no retries
+	require.NoError(t, o.Notify(ctx, models.NotificationKindAdminPing, 2, "blocked"))
+	bot.FailNext("SendMessage", telegoapitest.APIError(http.StatusForbidden, "Forbidden: bot was blocked by the user", 0))
+	o.dispatch(ctx)
+	assert.Equal(t, models.NotificationStatusFailed, repo.notifications[1].Status)
+
+	// Temporary failures are retried until the attempts are used up
+	require.NoError(t, o.Notify(ctx, models.NotificationKindAdminPing, 3, "flaky"))
+	bot.FailNext("SendMessage", errors.New("connection reset"))
+	bot.FailNext("SendMessage", errors.New("connection reset"))
+	o.dispatch(ctx)
+	assert.Equal(t, models.NotificationStatusPending, repo.notifications[2].Status)
+	repo.makeDue()
+	o.dispatch(ctx)
+	assert.Equal(t, models.NotificationStatusFailed, repo.notifications[2].Status)
+	assert.Equal(t, 2, repo.notifications[2].Attempts)
+
+	pending, failed, err := o.Counts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), pending)
+	assert.Equal(t, int64(2), failed)
+}
+
+func TestOutboxDeliversLeftoverNotificationsOnStart(t *testing.T) {
+	bot := telegoapitest.NewFakeBot()
+	repo := &memoryRepo{}
+	require.NoError(t, New(repo, bot).Notify(context.Background(), models.NotificationKindIntakeReceipt, 5, "left over"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	New(repo, bot).Start(ctx)
+	_, ok := bot.WaitForCall("SendMessage", 5, time.Second)
+	assert.True(t, ok)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, baseBackoff, backoff(1))
+	assert.Equal(t, 4*baseBackoff, backoff(3))
+	assert.Equal(t, maxBackoff, backoff(20))
+}
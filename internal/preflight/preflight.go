@@ -0,0 +1,202 @@
+// Package preflight checks that the bot can run with its configuration: that the token, the
+// channel, MongoDB, Sentry and the locale files work. check-config prints the report, and
+// serve refuses to start on errors.
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/config"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	sentry "github.com/getsentry/sentry-go"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// checkTimeout bounds each network check.
+const checkTimeout = 10 * time.Second
+
+// Severity is the outcome of a check.
+type Severity int
+
+const (
+	// OK means the check passed.
+	OK Severity = iota
+	// Warning means the bot runs, but some feature won't work as configured.
+	Warning
+	// Error means the bot can't run until it is fixed.
+	Error
+)
+
+// String returns the label of the severity in the printed report.
+func (s Severity) String() string {
+	switch s {
+	case Warning:
+		return "WARN"
+	case Error:
+		return "FAIL"
+	default:
+		return "OK"
+	}
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	Check    string // What was checked, e.g. "Bot token"
+	Severity Severity
+	Message  string
+	Hint     string // What to do about a warning or an error
+}
+
+// Report is the outcome of all checks, in the order they ran.
+type Report struct {
+	Results []Result
+}
+
+// Count returns how many results have the given severity.
+func (r Report) Count(severity Severity) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// Print writes the report, one line per check followed by the hint of warnings and errors.
+func (r Report) Print(w io.Writer) {
+	for _, result := range r.Results {
+		fmt.Fprintf(w, "[%s] %s: %s\n", result.Severity, result.Check, result.Message)
+		if result.Severity != OK && result.Hint != "" {
+			fmt.Fprintf(w, "       %s\n", result.Hint)
+		}
+	}
+}
+
+// add appends a result.
+func (r *Report) add(check string, severity Severity, message, hint string) {
+	r.Results = append(r.Results, Result{Check: check, Severity: severity, Message: message, Hint: hint})
+}
+
+// Run checks the configuration. The bot and the pinger may be nil to skip the checks that
+// need Telegram or MongoDB.
+func Run(ctx context.Context, cfg *config.Config, bot telegoapi.BotAPI, pinger database.Pinger) Report {
+	var report Report
+	if bot != nil {
+		if me := checkBotToken(ctx, &report, bot); me != nil {
+			checkChannel(ctx, &report, cfg, bot, me)
+		}
+	}
+	if pinger != nil {
+		checkDatabase(ctx, &report, pinger)
+	}
+	checkSentry(&report, cfg)
+	checkLocales(&report, cfg)
+	return report
+}
+
+// checkBotToken checks the token with GetMe and returns the bot, or nil if the token doesn't work.
+func checkBotToken(ctx context.Context, report *Report, bot telegoapi.BotAPI) *telego.User {
+	const check = "Bot token"
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	me, err := bot.GetMe(ctx)
+	if err != nil {
+		report.add(check, Error, fmt.Sprintf("GetMe failed: %v", err),
+			"Check TELEGRAM_BOT_TOKEN: copy the token of the bot from @BotFather, and make sure Telegram is reachable.")
+		return nil
+	}
+	report.add(check, OK, fmt.Sprintf("authorized as @%s (ID %d)", me.Username, me.ID), "")
+	return me
+}
+
+// checkChannel checks that the bot is an administrator of the channel who can post.
+func checkChannel(ctx context.Context, report *Report, cfg *config.Config, bot telegoapi.BotAPI, me *telego.User) {
+	const check = "Channel permissions"
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	member, err := bot.GetChatMember(ctx, &telego.GetChatMemberParams{ChatID: tu.ID(cfg.ChannelID), UserID: me.ID})
+	if err != nil {
+		report.add(check, Error, fmt.Sprintf("can't access channel %d: %v", cfg.ChannelID, err),
+			"Check CHANNEL_ID (channel IDs start with -100) and add the bot to the channel as an administrator.")
+		return
+	}
+	admin, ok := member.(*telego.ChatMemberAdministrator)
+	if !ok {
+		report.add(check, Error, fmt.Sprintf("the bot is %q in channel %d, not an administrator", member.MemberStatus(), cfg.ChannelID),
+			"Promote the bot to administrator of the channel with the \"Post messages\" right.")
+		return
+	}
+	if !admin.CanPostMessages {
+		report.add(check, Error, "the bot is an administrator but can't post messages",
+			"Give the bot the \"Post messages\" right in the channel's administrator settings.")
+		return
+	}
+	if !admin.CanDeleteMessages {
+		report.add(check, Warning, fmt.Sprintf("the bot can post to channel %d but can't delete messages", cfg.ChannelID),
+			"Give the bot the \"Delete messages\" right, so posts can be taken down again.")
+		return
+	}
+	report.add(check, OK, fmt.Sprintf("the bot can post to channel %d", cfg.ChannelID), "")
+}
+
+// checkDatabase checks that MongoDB answers a ping.
+func checkDatabase(ctx context.Context, report *Report, pinger database.Pinger) {
+	const check = "MongoDB"
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	if err := pinger.Ping(ctx); err != nil {
+		report.add(check, Error, fmt.Sprintf("ping failed: %v", err),
+			"Check MONGODB_URI (host, port and credentials) and that MongoDB is running.")
+		return
+	}
+	report.add(check, OK, "reachable", "")
+}
+
+// checkSentry checks that the Sentry DSN is well-formed. Sentry itself isn't contacted.
+func checkSentry(report *Report, cfg *config.Config) {
+	const check = "Sentry"
+	if cfg.SentryDSN == "" {
+		report.add(check, Warning, "SENTRY_DSN is not set, so errors are only logged",
+			"Set SENTRY_DSN to the DSN from the Sentry project settings to track errors.")
+		return
+	}
+	if _, err := sentry.NewDsn(cfg.SentryDSN); err != nil {
+		report.add(check, Error, fmt.Sprintf("SENTRY_DSN is malformed: %v", err),
+			"Copy the DSN from the Sentry project settings; it looks like https://<key>@<host>/<project ID>.")
+		return
+	}
+	report.add(check, OK, "DSN is well-formed", "")
+}
+
+// checkLocales checks that the default language has a message file and that no message file
+// lacks translations others have. Locales must be initialized.
+func checkLocales(report *Report, cfg *config.Config) {
+	const check = "Locales"
+	supported := locales.SupportedLanguages()
+	if !locales.IsSupported(cfg.DefaultLanguage) {
+		report.add(check, Warning, fmt.Sprintf("there is no message file for BOT_DEFAULT_LANGUAGE %q", cfg.DefaultLanguage),
+			fmt.Sprintf("Set BOT_DEFAULT_LANGUAGE to one of: %s.", strings.Join(supported, ", ")))
+		return
+	}
+	missing := locales.MissingTranslations()
+	if len(missing) > 0 {
+		files := make([]string, 0, len(missing))
+		for file, ids := range missing {
+			files = append(files, fmt.Sprintf("%s lacks %d", file, len(ids)))
+		}
+		sort.Strings(files)
+		report.add(check, Warning, fmt.Sprintf("some translations are missing (%s)", strings.Join(files, ", ")),
+			"Add the missing message IDs listed in the startup log to the message files in internal/locales.")
+		return
+	}
+	report.add(check, OK, fmt.Sprintf("default language %s, available: %s", cfg.DefaultLanguage, strings.Join(supported, ", ")), "")
+}
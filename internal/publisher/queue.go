@@ -0,0 +1,324 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"vrcmemes-bot/internal/database"
+
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+)
+
+const (
+	// DefaultMinInterval is the minimum pause between two channel publications.
+	DefaultMinInterval = time.Second
+	// maxInterval caps the pause after repeated rate limiting.
+	maxInterval = time.Minute
+	// maxAttempts is how many times a publication is tried when rate limited.
+	maxAttempts = 5
+	// queueCapacity is how many publications can wait before Enqueue blocks.
+	queueCapacity = 256
+	// doneTimeout bounds the follow-up work (logging, notifying the sender) run after a background publication.
+	doneTimeout = 30 * time.Second
+	// drainPoll is how often the stopped worker checks for publications still being enqueued.
+	drainPoll = 10 * time.Millisecond
+)
+
+// ErrAlreadyPublished is returned for a publication whose key was already published successfully.
+var ErrAlreadyPublished = errors.New("already published")
+
+// SendFunc performs a single publication (one Bot API call to the channel).
+type SendFunc func(ctx context.Context) error
+
+// job is a queued publication.
+type job struct {
+	key    string // Idempotency key, also used in logs
+	send   SendFunc
+	result chan error
+}
+
+// Queue routes all channel publications through a single worker so bursts (e.g. bulk approvals)
+// don't trigger Telegram flood limits. The pause between publications adapts: it grows when
+// Telegram responds with 429 Too Many Requests (after waiting the requested retry_after) and
+// shrinks back towards the minimum after successful sends.
+type Queue struct {
+	jobs        chan *job
+	minInterval time.Duration
+	ctx         context.Context                 // Lifecycle of the worker, set by Start
+	store       database.PublicationStore       // Optional record of publications by key
+	onFailure   func(key string, err error)     // Optional, called for publications that failed for good
+	preflight   func(ctx context.Context) error // Optional, checked before each publication
+
+	mu       sync.Mutex
+	pending  int           // Queued jobs, including the one being sent
+	interval time.Duration // Current pause between publications
+	nextAt   time.Time     // When the job taken by the worker is sent; zero while idle
+	stopErr  error         // Why the worker stopped; publications are refused with it
+}
+
+// NewQueue creates a publishing queue. Start must be called before publications are sent.
+func NewQueue(minInterval time.Duration) *Queue {
+	if minInterval <= 0 {
+		minInterval = DefaultMinInterval
+	}
+	return &Queue{
+		jobs:        make(chan *job, queueCapacity),
+		minInterval: minInterval,
+		interval:    minInterval,
+	}
+}
+
+// SetStore enables idempotent publishing: each publication is recorded under its key before
+// sending, and keys that were already published successfully are skipped.
+func (q *Queue) SetStore(store database.PublicationStore) {
+	q.store = store
+}
+
+// SetFailureFunc sets a function called for each publication that failed for good, i.e. after
+// the rate-limited retries or on an error that isn't retried. It runs on the worker, so it should be quick.
+func (q *Queue) SetFailureFunc(onFailure func(key string, err error)) {
+	q.onFailure = onFailure
+}
+
+// SetPreflight sets a check run before each publication, such as whether the bot can still post
+// to the channel. A publication whose check fails is not sent and fails with the check's error.
+func (q *Queue) SetPreflight(preflight func(ctx context.Context) error) {
+	q.preflight = preflight
+}
+
+// Start runs the publishing worker until ctx is cancelled. Publications still queued then,
+// and any enqueued later, fail with ctx's error.
+func (q *Queue) Start(ctx context.Context) {
+	q.mu.Lock()
+	q.ctx = ctx
+	q.mu.Unlock()
+	go q.run(ctx)
+}
+
+// Pending returns the number of queued publications, including the one being sent.
+func (q *Queue) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.pending
+}
+
+// NextAt returns when the next queued publication is sent, or the zero time if none is queued.
+// Publications behind it follow at least one interval apart.
+func (q *Queue) NextAt() time.Time {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.nextAt
+}
+
+// Enqueue adds a publication to the queue without waiting for it to be sent. The key identifies
+// the content (e.g. "suggestion:<id>") and is used as its idempotency key.
+// It returns the publication's position (1 means it is next) and a channel
+// that receives the final result once it has been sent or has failed.
+// If the queue is full, Enqueue waits for room until ctx is done; a publication that couldn't
+// be queued, because of ctx or because the worker has stopped, has position 0 and its error as result.
+func (q *Queue) Enqueue(ctx context.Context, key string, send SendFunc) (position int, result <-chan error) {
+	j := &job{key: key, send: send, result: make(chan error, 1)}
+	q.mu.Lock()
+	if err := q.stopErr; err != nil {
+		q.mu.Unlock()
+		log.Printf("[PublishQueue] Not queueing %s, the queue has stopped: %v", key, err)
+		j.result <- err
+		return 0, j.result
+	}
+	q.pending++
+	position = q.pending
+	q.mu.Unlock()
+
+	select {
+	case q.jobs <- j:
+	case <-ctx.Done():
+		q.mu.Lock()
+		q.pending--
+		q.mu.Unlock()
+		log.Printf("[PublishQueue] Gave up queueing %s: %v", key, ctx.Err())
+		j.result <- ctx.Err()
+		return 0, j.result
+	}
+	if position > 1 {
+		log.Printf("[PublishQueue] Queued %s at position %d", key, position)
+	}
+	return position, j.result
+}
+
+// Submit adds a publication to the queue as a background job and returns its position, like Enqueue.
+// ctx only bounds the wait for room in the queue. done runs once the publication has been sent or
+// has failed, with a context tied to the queue's lifecycle rather than the caller's, so update
+// handlers can return right away.
+func (q *Queue) Submit(ctx context.Context, key string, send SendFunc, done func(ctx context.Context, err error)) int {
+	position, result := q.Enqueue(ctx, key, send)
+	go func() {
+		err := <-result
+		q.mu.Lock()
+		parent := q.ctx
+		q.mu.Unlock()
+		if parent == nil {
+			parent = context.Background()
+		}
+		ctx, cancel := context.WithTimeout(parent, doneTimeout)
+		defer cancel()
+		done(ctx, err)
+	}()
+	return position
+}
+
+// run sends queued publications one at a time, pacing them by the current interval.
+func (q *Queue) run(ctx context.Context) {
+	defer q.drain(ctx)
+	var lastSent time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case j := <-q.jobs:
+			if ctx.Err() != nil {
+				q.finish(j, ctx.Err())
+				return
+			}
+			sendAt := lastSent.Add(q.currentInterval())
+			if now := time.Now(); sendAt.Before(now) {
+				sendAt = now
+			}
+			q.mu.Lock()
+			q.nextAt = sendAt
+			q.mu.Unlock()
+			if wait := time.Until(sendAt); wait > 0 {
+				if !sleep(ctx, wait) {
+					q.finish(j, ctx.Err())
+					return
+				}
+			}
+			err := q.publish(ctx, j)
+			if !errors.Is(err, ErrAlreadyPublished) {
+				lastSent = time.Now()
+			}
+			if err != nil && !errors.Is(err, ErrAlreadyPublished) && ctx.Err() == nil && q.onFailure != nil {
+				q.onFailure(j.key, err)
+			}
+			q.finish(j, err)
+		}
+	}
+}
+
+// finish takes a publication off the queue and reports its result.
+func (q *Queue) finish(j *job, err error) {
+	q.mu.Lock()
+	q.pending--
+	q.nextAt = time.Time{}
+	q.mu.Unlock()
+	j.result <- err
+}
+
+// drain fails the publications left in the queue once the worker has stopped, including those
+// still being enqueued, and makes Enqueue refuse new ones.
+func (q *Queue) drain(ctx context.Context) {
+	q.mu.Lock()
+	q.stopErr = ctx.Err()
+	q.mu.Unlock()
+	drained := 0
+	for q.Pending() > 0 {
+		select {
+		case j := <-q.jobs:
+			q.finish(j, ctx.Err())
+			drained++
+		case <-time.After(drainPoll):
+		}
+	}
+	if drained > 0 {
+		log.Printf("[PublishQueue] Stopped, %d queued publication(s) not sent", drained)
+	}
+}
+
+// publish runs the preflight check and sends a publication, recording it in the store first if one is set.
+// If the store is unavailable the publication is sent anyway, since losing a post
+// is worse than the small chance of a duplicate.
+func (q *Queue) publish(ctx context.Context, j *job) error {
+	if q.preflight != nil {
+		if err := q.preflight(ctx); err != nil {
+			log.Printf("[PublishQueue] Not publishing %s: %v", j.key, err)
+			return err
+		}
+	}
+	if q.store == nil {
+		return q.sendWithRetry(ctx, j)
+	}
+
+	alreadyPublished, err := q.store.BeginPublication(ctx, j.key)
+	if err != nil {
+		log.Printf("[PublishQueue] Error recording publication %s, sending anyway: %v", j.key, err)
+	} else if alreadyPublished {
+		log.Printf("[PublishQueue] Skipping %s: already published", j.key)
+		return ErrAlreadyPublished
+	}
+
+	sendErr := q.sendWithRetry(ctx, j)
+	if sendErr != nil {
+		err = q.store.FailPublication(ctx, j.key, sendErr)
+	} else {
+		err = q.store.CompletePublication(ctx, j.key)
+	}
+	if err != nil {
+		log.Printf("[PublishQueue] Error updating publication record %s: %v", j.key, err)
+	}
+	return sendErr
+}
+
+// sendWithRetry sends a publication, waiting and retrying when rate limited.
+func (q *Queue) sendWithRetry(ctx context.Context, j *job) error {
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = j.send(ctx)
+		if err == nil {
+			q.adjustInterval(false)
+			return nil
+		}
+
+		retryAfter, limited := telegoapi.RetryAfter(err)
+		if !limited {
+			return err
+		}
+		q.adjustInterval(true)
+		log.Printf("[PublishQueue] Rate limited publishing %s (attempt %d/%d), retrying in %s", j.key, attempt, maxAttempts, retryAfter)
+		if !sleep(ctx, retryAfter) {
+			return ctx.Err()
+		}
+	}
+	return fmt.Errorf("giving up on %s after %d rate-limited attempts: %w", j.key, maxAttempts, err)
+}
+
+// adjustInterval doubles the pause after a rate limit and eases it back towards the minimum after a success.
+func (q *Queue) adjustInterval(rateLimited bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if rateLimited {
+		q.interval = min(q.interval*2, maxInterval)
+		return
+	}
+	q.interval = max(q.interval*3/4, q.minInterval)
+}
+
+// currentInterval returns the current pause between publications.
+func (q *Queue) currentInterval() time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.interval
+}
+
+// sleep waits for d or until ctx is cancelled, reporting whether the full duration passed.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
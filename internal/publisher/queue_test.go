@@ -0,0 +1,256 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"vrcmemes-bot/pkg/telegoapi/telegoapitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryPublicationStore is an in-memory database.PublicationStore.
+type memoryPublicationStore struct {
+	mu        sync.Mutex
+	published map[string]bool
+	failed    map[string]error
+}
+
+func newMemoryPublicationStore() *memoryPublicationStore {
+	return &memoryPublicationStore{published: map[string]bool{}, failed: map[string]error{}}
+}
+
+func (s *memoryPublicationStore) BeginPublication(_ context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.published[key], nil
+}
+
+func (s *memoryPublicationStore) CompletePublication(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.published[key] = true
+	delete(s.failed, key)
+	return nil
+}
+
+func (s *memoryPublicationStore) FailPublication(_ context.Context, key string, cause error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed[key] = cause
+	return nil
+}
+
+// sendLog records when publications were sent.
+type sendLog struct {
+	mu    sync.Mutex
+	times []time.Time
+}
+
+func (l *sendLog) send(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.times = append(l.times, time.Now())
+	return nil
+}
+
+func (l *sendLog) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.times)
+}
+
+func waitForResult(t *testing.T, result <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("publication has no result")
+		return nil
+	}
+}
+
+func TestQueuePacesPublications(t *testing.T) {
+	tests := []struct {
+		name        string
+		minInterval time.Duration
+		jobs        int
+	}{
+		{name: "short interval", minInterval: 10 * time.Millisecond, jobs: 3},
+		{name: "longer interval", minInterval: 40 * time.Millisecond, jobs: 3},
+		{name: "single publication", minInterval: 40 * time.Millisecond, jobs: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			q := NewQueue(tt.minInterval)
+			q.Start(ctx)
+
+			sent := &sendLog{}
+			var results []<-chan error
+			for i := 0; i < tt.jobs; i++ {
+				position, result := q.Enqueue(ctx, "job", sent.send)
+				assert.Positive(t, position)
+				results = append(results, result)
+			}
+			for _, result := range results {
+				require.NoError(t, waitForResult(t, result))
+			}
+
+			require.Len(t, sent.times, tt.jobs)
+			for i := 1; i < len(sent.times); i++ {
+				assert.GreaterOrEqual(t, sent.times[i].Sub(sent.times[i-1]), tt.minInterval, "publications %d and %d", i-1, i)
+			}
+			assert.Zero(t, q.Pending())
+			assert.True(t, q.NextAt().IsZero())
+		})
+	}
+}
+
+func TestNewQueueDefaultsMinInterval(t *testing.T) {
+	assert.Equal(t, DefaultMinInterval, NewQueue(0).minInterval)
+	assert.Equal(t, DefaultMinInterval, NewQueue(-time.Second).minInterval)
+}
+
+func TestQueueRetriesRateLimitedPublication(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := NewQueue(time.Millisecond)
+	q.Start(ctx)
+
+	attempts := 0
+	start := time.Now()
+	_, result := q.Enqueue(ctx, "job", func(ctx context.Context) error {
+		attempts++
+		if attempts == 1 {
+			return telegoapitest.APIError(429, "Too Many Requests: retry after 1", 1)
+		}
+		return nil
+	})
+
+	require.NoError(t, waitForResult(t, result))
+	assert.Equal(t, 2, attempts)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second, "retry didn't wait for retry_after")
+	assert.Greater(t, q.currentInterval(), time.Millisecond, "interval didn't grow after rate limiting")
+}
+
+func TestQueueDoesNotRetryOtherErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := NewQueue(time.Millisecond)
+	var failedKey string
+	q.SetFailureFunc(func(key string, err error) { failedKey = key })
+	q.Start(ctx)
+
+	sendErr := telegoapitest.APIError(400, "Bad Request: wrong file identifier", 0)
+	attempts := 0
+	_, result := q.Enqueue(ctx, "job", func(ctx context.Context) error {
+		attempts++
+		return sendErr
+	})
+
+	assert.ErrorIs(t, waitForResult(t, result), sendErr)
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, "job", failedKey)
+}
+
+func TestQueueSkipsAlreadyPublishedKeys(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := NewQueue(time.Millisecond)
+	store := newMemoryPublicationStore()
+	q.SetStore(store)
+	q.Start(ctx)
+
+	sent := &sendLog{}
+	_, first := q.Enqueue(ctx, "suggestion:1", sent.send)
+	require.NoError(t, waitForResult(t, first))
+	_, again := q.Enqueue(ctx, "suggestion:1", sent.send)
+	assert.ErrorIs(t, waitForResult(t, again), ErrAlreadyPublished)
+	_, other := q.Enqueue(ctx, "suggestion:2", sent.send)
+	require.NoError(t, waitForResult(t, other))
+
+	assert.Equal(t, 2, sent.count())
+	assert.True(t, store.published["suggestion:1"])
+	assert.True(t, store.published["suggestion:2"])
+}
+
+func TestQueueRetriesFailedKeys(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := NewQueue(time.Millisecond)
+	store := newMemoryPublicationStore()
+	q.SetStore(store)
+	q.Start(ctx)
+
+	sendErr := errors.New("network down")
+	_, failed := q.Enqueue(ctx, "suggestion:1", func(ctx context.Context) error { return sendErr })
+	assert.ErrorIs(t, waitForResult(t, failed), sendErr)
+	assert.ErrorIs(t, store.failed["suggestion:1"], sendErr)
+
+	// A failed publication isn't published, so it can be sent again
+	sent := &sendLog{}
+	_, retried := q.Enqueue(ctx, "suggestion:1", sent.send)
+	require.NoError(t, waitForResult(t, retried))
+	assert.Equal(t, 1, sent.count())
+}
+
+func TestQueueFailsPendingPublicationsWhenStopped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	q := NewQueue(time.Minute)
+	q.Start(ctx)
+
+	sent := &sendLog{}
+	_, first := q.Enqueue(context.Background(), "first", sent.send)
+	require.NoError(t, waitForResult(t, first))
+	// Both wait a minute for their turn
+	_, second := q.Enqueue(context.Background(), "second", sent.send)
+	_, third := q.Enqueue(context.Background(), "third", sent.send)
+
+	cancel()
+	assert.ErrorIs(t, waitForResult(t, second), context.Canceled)
+	assert.ErrorIs(t, waitForResult(t, third), context.Canceled)
+	assert.Eventually(t, func() bool { return q.Pending() == 0 }, time.Second, 5*time.Millisecond)
+
+	// The stopped queue refuses new publications right away
+	var doneErr error
+	done := make(chan struct{})
+	position := q.Submit(context.Background(), "late", sent.send, func(ctx context.Context, err error) {
+		doneErr = err
+		close(done)
+	})
+	<-done
+	assert.Zero(t, position)
+	assert.ErrorIs(t, doneErr, context.Canceled)
+	assert.Equal(t, 1, sent.count())
+}
+
+func TestSubmitGivesUpWhenCallerContextIsDone(t *testing.T) {
+	q := NewQueue(time.Millisecond) // Not started, so the queue fills up
+	for i := 0; i < queueCapacity; i++ {
+		q.Enqueue(context.Background(), "filler", func(ctx context.Context) error { return nil })
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	var doneErr error
+	done := make(chan struct{})
+	position := q.Submit(ctx, "job", func(ctx context.Context) error { return nil }, func(ctx context.Context, err error) {
+		doneErr = err
+		close(done)
+	})
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("done was not called")
+	}
+	assert.Zero(t, position)
+	assert.ErrorIs(t, doneErr, context.DeadlineExceeded)
+	assert.Equal(t, queueCapacity, q.Pending())
+}
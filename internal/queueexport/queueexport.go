@@ -0,0 +1,115 @@
+// Package queueexport renders a snapshot of the pending suggestion queue as a zipped HTML page
+// with the suggestions' thumbnails, so admins can go through the queue offline and send their
+// decisions back later with /apply.
+package queueexport
+
+import (
+	"archive/zip"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Item is a pending suggestion in the snapshot.
+type Item struct {
+	Code      string // Reference code decisions are applied by
+	Suggester string
+	Submitted string // Submission time, formatted for the reader
+	Caption   string
+	Media     int    // Number of photos and videos in the suggestion
+	Thumbnail []byte // First photo of the suggestion; nil if it couldn't be downloaded
+}
+
+// Page is the snapshot of the queue. Its texts are already localized.
+type Page struct {
+	Title     string
+	Intro     string // Explains how to send decisions back, e.g. with /apply
+	NoPreview string // Shown in place of a missing thumbnail
+	Items     []Item
+}
+
+// card is an item as rendered in the page.
+type card struct {
+	Item
+	Image string // Path of the thumbnail in the archive; empty if there is none
+	More  int    // Media not shown in the thumbnail
+}
+
+var pageTemplate = template.Must(template.New("queue").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 1em; background: #f4f4f4; }
+.card { background: #fff; border-radius: 8px; padding: 0.8em; margin-bottom: 1em; }
+.card img { max-width: 100%; max-height: 60vh; display: block; margin: 0.5em 0; }
+.code { font-family: monospace; font-size: 1.3em; font-weight: bold; }
+.meta { color: #666; font-size: 0.9em; }
+.caption { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Intro}}</p>
+{{range .Cards}}<div class="card">
+<div class="code">{{.Code}}</div>
+<div class="meta">{{.Suggester}} · {{.Submitted}}{{if gt .More 0}} · +{{.More}}{{end}}</div>
+{{if .Image}}<img src="{{.Image}}" alt="{{.Code}}">{{else}}<p class="meta">{{$.NoPreview}}</p>{{end}}
+{{if .Caption}}<div class="caption">{{.Caption}}</div>{{end}}
+</div>
+{{end}}</body>
+</html>
+`))
+
+// Write writes the snapshot to w as a zip archive of index.html and the thumbnails it shows.
+// Thumbnails that aren't images, e.g. because the suggestion is a video, are left out.
+func Write(w io.Writer, page Page) error {
+	archive := zip.NewWriter(w)
+	cards := make([]card, len(page.Items))
+	for i, item := range page.Items {
+		cards[i] = card{Item: item, More: item.Media - 1}
+		ext := imageExtension(item.Thumbnail)
+		if ext == "" {
+			continue
+		}
+		cards[i].Image = fmt.Sprintf("images/%s%s", item.Code, ext)
+		file, err := archive.Create(cards[i].Image)
+		if err != nil {
+			return fmt.Errorf("failed to add thumbnail of %s: %w", item.Code, err)
+		}
+		if _, err := file.Write(item.Thumbnail); err != nil {
+			return fmt.Errorf("failed to write thumbnail of %s: %w", item.Code, err)
+		}
+	}
+
+	index, err := archive.Create("index.html")
+	if err != nil {
+		return fmt.Errorf("failed to add the page: %w", err)
+	}
+	if err := pageTemplate.Execute(index, struct {
+		Page
+		Cards []card
+	}{page, cards}); err != nil {
+		return fmt.Errorf("failed to render the page: %w", err)
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish the archive: %w", err)
+	}
+	return nil
+}
+
+// imageExtension returns the file extension of an encoded image, or "" if data isn't an image.
+func imageExtension(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	contentType := http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, "image/") {
+		return ""
+	}
+	return "." + strings.TrimPrefix(contentType, "image/")
+}
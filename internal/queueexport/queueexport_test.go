@@ -0,0 +1,45 @@
+package queueexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteZipsPageWithThumbnails(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+	var buf bytes.Buffer
+	err := Write(&buf, Page{
+		Title:     "Queue",
+		Intro:     "Send /apply",
+		NoPreview: "no preview",
+		Items: []Item{
+			{Code: "ABC123", Suggester: "@alice", Caption: "<b>hi</b>", Media: 3, Thumbnail: png},
+			{Code: "DEF456", Suggester: "Bob", Media: 1, Thumbnail: []byte("not an image")},
+		},
+	})
+	require.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, file := range archive.File {
+		r, err := file.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		files[file.Name] = string(data)
+	}
+
+	assert.Equal(t, string(png), files["images/ABC123.png"])
+	assert.NotContains(t, files, "images/DEF456.png")
+	page := files["index.html"]
+	assert.Contains(t, page, `<img src="images/ABC123.png"`)
+	assert.Contains(t, page, "+2")
+	assert.Contains(t, page, "&lt;b&gt;hi&lt;/b&gt;")
+	assert.Contains(t, page, "no preview")
+}
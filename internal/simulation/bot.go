@@ -0,0 +1,193 @@
+package simulation
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// Bot wraps a BotAPI and keeps calls for the test user's chat away from Telegram, which doesn't
+// know the test user. Messages to the test user are shown in the mirror chat instead, and sends
+// return placeholder messages with negative IDs. The test user counts as a channel member.
+// Calls for other chats go through unchanged.
+type Bot struct {
+	telegoapi.BotAPI
+	mirrorChatID atomic.Int64
+	lastID       atomic.Int64
+}
+
+// NewBot creates a BotAPI that handles the test user's chat itself.
+func NewBot(bot telegoapi.BotAPI) *Bot {
+	return &Bot{BotAPI: bot}
+}
+
+// SetMirrorChat sets the chat messages to the test user are shown in; 0 only logs them.
+func (b *Bot) SetMirrorChat(chatID int64) {
+	b.mirrorChatID.Store(chatID)
+}
+
+// isTestChat reports whether a call targets the test user's chat.
+func isTestChat(chatID telego.ChatID) bool {
+	return chatID.ID == TestUserID
+}
+
+// placeholder returns a fake message standing in for one sent to the test user.
+func (b *Bot) placeholder() telego.Message {
+	return telego.Message{
+		MessageID: int(b.lastID.Add(-1)),
+		Date:      time.Now().Unix(),
+		Chat:      telego.Chat{ID: TestUserID, Type: telego.ChatTypePrivate},
+	}
+}
+
+// mirror shows what the bot sent the test user in the mirror chat.
+func (b *Bot) mirror(ctx context.Context, text string, markup telego.ReplyMarkup) {
+	if keyboard, ok := markup.(*telego.InlineKeyboardMarkup); ok && keyboard != nil {
+		for _, row := range keyboard.InlineKeyboard {
+			labels := make([]string, 0, len(row))
+			for _, button := range row {
+				labels = append(labels, "["+button.Text+"]")
+			}
+			text += "\n" + strings.Join(labels, " ")
+		}
+	}
+	chatID := b.mirrorChatID.Load()
+	if chatID == 0 {
+		log.Printf("[Simulate] To the test user: %s", text)
+		return
+	}
+	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+	msg := locales.GetMessage(localizer, "MsgSimulateMirror", map[string]interface{}{"Text": text}, nil)
+	if _, err := b.BotAPI.SendMessage(ctx, tu.Message(tu.ID(chatID), msg)); err != nil {
+		log.Printf("[Simulate] Error showing a message to the test user in chat %d: %v", chatID, err)
+	}
+}
+
+// SendMessage shows text messages to the test user in the mirror chat.
+func (b *Bot) SendMessage(ctx context.Context, params *telego.SendMessageParams) (*telego.Message, error) {
+	if !isTestChat(params.ChatID) {
+		return b.BotAPI.SendMessage(ctx, params)
+	}
+	b.mirror(ctx, params.Text, params.ReplyMarkup)
+	msg := b.placeholder()
+	return &msg, nil
+}
+
+// SendPhoto shows photos sent to the test user in the mirror chat.
+func (b *Bot) SendPhoto(ctx context.Context, params *telego.SendPhotoParams) (*telego.Message, error) {
+	if !isTestChat(params.ChatID) {
+		return b.BotAPI.SendPhoto(ctx, params)
+	}
+	b.mirror(ctx, strings.TrimSpace("[photo] "+params.Caption), params.ReplyMarkup)
+	msg := b.placeholder()
+	return &msg, nil
+}
+
+// SendVideo shows videos sent to the test user in the mirror chat.
+func (b *Bot) SendVideo(ctx context.Context, params *telego.SendVideoParams) (*telego.Message, error) {
+	if !isTestChat(params.ChatID) {
+		return b.BotAPI.SendVideo(ctx, params)
+	}
+	b.mirror(ctx, strings.TrimSpace("[video] "+params.Caption), params.ReplyMarkup)
+	msg := b.placeholder()
+	return &msg, nil
+}
+
+// SendAnimation shows GIFs sent to the test user in the mirror chat.
+func (b *Bot) SendAnimation(ctx context.Context, params *telego.SendAnimationParams) (*telego.Message, error) {
+	if !isTestChat(params.ChatID) {
+		return b.BotAPI.SendAnimation(ctx, params)
+	}
+	b.mirror(ctx, strings.TrimSpace("[GIF] "+params.Caption), params.ReplyMarkup)
+	msg := b.placeholder()
+	return &msg, nil
+}
+
+// SendDocument shows documents sent to the test user in the mirror chat.
+func (b *Bot) SendDocument(ctx context.Context, params *telego.SendDocumentParams) (*telego.Message, error) {
+	if !isTestChat(params.ChatID) {
+		return b.BotAPI.SendDocument(ctx, params)
+	}
+	b.mirror(ctx, strings.TrimSpace("[document] "+params.Caption), params.ReplyMarkup)
+	msg := b.placeholder()
+	return &msg, nil
+}
+
+// SendMediaGroup shows albums sent to the test user in the mirror chat.
+func (b *Bot) SendMediaGroup(ctx context.Context, params *telego.SendMediaGroupParams) ([]telego.Message, error) {
+	if !isTestChat(params.ChatID) {
+		return b.BotAPI.SendMediaGroup(ctx, params)
+	}
+	b.mirror(ctx, "[album]", nil)
+	messages := make([]telego.Message, len(params.Media))
+	for i := range messages {
+		messages[i] = b.placeholder()
+	}
+	return messages, nil
+}
+
+// CopyMessage shows messages copied to the test user in the mirror chat.
+func (b *Bot) CopyMessage(ctx context.Context, params *telego.CopyMessageParams) (*telego.MessageID, error) {
+	if !isTestChat(params.ChatID) {
+		return b.BotAPI.CopyMessage(ctx, params)
+	}
+	b.mirror(ctx, strings.TrimSpace("[copy] "+params.Caption), params.ReplyMarkup)
+	return &telego.MessageID{MessageID: b.placeholder().MessageID}, nil
+}
+
+// CopyMessages shows messages copied to the test user in the mirror chat.
+func (b *Bot) CopyMessages(ctx context.Context, params *telego.CopyMessagesParams) ([]telego.MessageID, error) {
+	if !isTestChat(params.ChatID) {
+		return b.BotAPI.CopyMessages(ctx, params)
+	}
+	b.mirror(ctx, "[copy]", nil)
+	ids := make([]telego.MessageID, len(params.MessageIDs))
+	for i := range ids {
+		ids[i] = telego.MessageID{MessageID: b.placeholder().MessageID}
+	}
+	return ids, nil
+}
+
+// EditMessageText shows edits of the test user's messages in the mirror chat.
+func (b *Bot) EditMessageText(ctx context.Context, params *telego.EditMessageTextParams) (*telego.Message, error) {
+	if !isTestChat(params.ChatID) {
+		return b.BotAPI.EditMessageText(ctx, params)
+	}
+	b.mirror(ctx, params.Text, params.ReplyMarkup)
+	msg := b.placeholder()
+	msg.MessageID = params.MessageID
+	return &msg, nil
+}
+
+// EditMessageReplyMarkup skips button changes in the test user's chat.
+func (b *Bot) EditMessageReplyMarkup(ctx context.Context, params *telego.EditMessageReplyMarkupParams) (*telego.Message, error) {
+	if !isTestChat(params.ChatID) {
+		return b.BotAPI.EditMessageReplyMarkup(ctx, params)
+	}
+	msg := b.placeholder()
+	msg.MessageID = params.MessageID
+	return &msg, nil
+}
+
+// DeleteMessage skips deleting messages in the test user's chat.
+func (b *Bot) DeleteMessage(ctx context.Context, params *telego.DeleteMessageParams) error {
+	if !isTestChat(params.ChatID) {
+		return b.BotAPI.DeleteMessage(ctx, params)
+	}
+	return nil
+}
+
+// GetChatMember reports the test user as a member of every chat, so they pass subscription checks.
+func (b *Bot) GetChatMember(ctx context.Context, params *telego.GetChatMemberParams) (telego.ChatMember, error) {
+	if params.UserID != TestUserID {
+		return b.BotAPI.GetChatMember(ctx, params)
+	}
+	return &telego.ChatMemberMember{Status: telego.MemberStatusMember, User: TestUser()}, nil
+}
@@ -0,0 +1,81 @@
+// Package simulation lets super admins verify flows in production with /simulate: synthetic
+// updates from a test user are injected into the bot's update processing, what the bot sends the
+// test user is shown in the admin's chat instead, and the records the flow creates are marked as
+// test records, which /purgetest deletes.
+package simulation
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/mymmrac/telego"
+)
+
+// TestUserID is the user simulated updates come from. Telegram IDs fit in 52 bits, so it can't
+// belong to a real user.
+const TestUserID int64 = 9_000_000_000_000_000_001
+
+// testUsername is the username of the test user, shown to reviewers.
+const testUsername = "simulated_test_user"
+
+// simulatedKey marks contexts of simulated updates.
+type simulatedKey struct{}
+
+// With returns a context in which updates are processed as simulated ones.
+func With(ctx context.Context) context.Context {
+	return context.WithValue(ctx, simulatedKey{}, true)
+}
+
+// Active reports whether ctx belongs to a simulated update. Records created in it are test records.
+func Active(ctx context.Context) bool {
+	simulated, _ := ctx.Value(simulatedKey{}).(bool)
+	return simulated
+}
+
+// TestUser returns the Telegram profile of the test user.
+func TestUser() telego.User {
+	return telego.User{ID: TestUserID, FirstName: "Test user", Username: testUsername}
+}
+
+// Simulator injects synthetic updates from the test user.
+type Simulator struct {
+	bot           *Bot
+	dispatch      func(ctx context.Context, update telego.Update) // Processes an update like the bot's update loop
+	lastMessageID atomic.Int64
+}
+
+// NewSimulator creates a simulator that passes updates to dispatch and shows what the bot sends
+// the test user through bot.
+func NewSimulator(bot *Bot, dispatch func(ctx context.Context, update telego.Update)) *Simulator {
+	return &Simulator{bot: bot, dispatch: dispatch}
+}
+
+// Suggestion makes the test user suggest a photo the way a real user does: /suggest, then the
+// photo with the caption. What the bot sends the test user is shown in chatID. The privacy notice,
+// the rules checklist and the preview, which need the test user to press buttons, are skipped.
+func (s *Simulator) Suggestion(ctx context.Context, chatID int64, fileID, caption string) {
+	s.bot.SetMirrorChat(chatID)
+	ctx = With(ctx)
+
+	command := s.newMessage()
+	command.Text = "/suggest"
+	command.Entities = []telego.MessageEntity{{Type: telego.EntityTypeBotCommand, Offset: 0, Length: len(command.Text)}}
+	s.dispatch(ctx, telego.Update{Message: &command})
+
+	photo := s.newMessage()
+	photo.Photo = []telego.PhotoSize{{FileID: fileID}}
+	photo.Caption = caption
+	s.dispatch(ctx, telego.Update{Message: &photo})
+}
+
+// newMessage returns a message from the test user in their private chat.
+func (s *Simulator) newMessage() telego.Message {
+	user := TestUser()
+	return telego.Message{
+		MessageID: int(s.lastMessageID.Add(1)),
+		Date:      time.Now().Unix(),
+		Chat:      telego.Chat{ID: TestUserID, Type: telego.ChatTypePrivate, FirstName: user.FirstName, Username: user.Username},
+		From:      &user,
+	}
+}
@@ -0,0 +1,301 @@
+package staging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/footers"
+	"vrcmemes-bot/internal/publisher"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+	"vrcmemes-bot/pkg/utils"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const (
+	// DefaultPollInterval is how often the staging directory is scanned.
+	DefaultPollInterval = 30 * time.Second
+	// settleTime is how long a file must be unmodified before it is posted, so files still being written are skipped.
+	settleTime = 5 * time.Second
+	// maxCaptionLength is Telegram's caption limit for media messages.
+	maxCaptionLength = 1024
+	// publishedDir and rejectedDir are subdirectories that processed files are moved to.
+	publishedDir = "published"
+	rejectedDir  = "rejected"
+)
+
+// mediaTypes maps supported file extensions to the kind of post they become.
+var mediaTypes = map[string]string{
+	".jpg":  "photo",
+	".jpeg": "photo",
+	".png":  "photo",
+	".webp": "photo",
+	".mp4":  "video",
+}
+
+// Watcher publishes media files dropped into a staging directory, so automated pipelines
+// can post to the channel without going through Telegram. An optional "<name>.txt" file
+// next to a media file (e.g. "a.jpg.txt") provides its caption. Published files are moved to
+// "published/", files that fail validation (or that Telegram rejects) to "rejected/".
+type Watcher struct {
+	dir          string
+	pollInterval time.Duration
+	maxFileSize  int64
+	bot          telegoapi.BotAPI
+	channelID    int64
+	publishQueue *publisher.Queue
+	postLogger   database.PostLogger
+	footers      *footers.Rotator // Hashtag footers added under captions; nil adds none
+
+	mu       sync.Mutex
+	inFlight map[string]bool // Files queued for publishing but not yet finished
+}
+
+// NewWatcher creates a staging directory watcher. Start must be called to begin scanning.
+func NewWatcher(
+	dir string,
+	pollInterval time.Duration,
+	maxFileSize int64,
+	bot telegoapi.BotAPI,
+	channelID int64,
+	publishQueue *publisher.Queue,
+	postLogger database.PostLogger,
+) *Watcher {
+	if bot == nil {
+		log.Fatal("Staging watcher: Bot dependency is nil")
+	}
+	if publishQueue == nil {
+		log.Fatal("Staging watcher: Publish queue dependency is nil")
+	}
+	if postLogger == nil {
+		log.Fatal("Staging watcher: Post logger dependency is nil")
+	}
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+	return &Watcher{
+		dir:          dir,
+		pollInterval: pollInterval,
+		maxFileSize:  maxFileSize,
+		bot:          bot,
+		channelID:    channelID,
+		publishQueue: publishQueue,
+		postLogger:   postLogger,
+		inFlight:     make(map[string]bool),
+	}
+}
+
+// SetFooterRotator sets the pool of hashtag footers added under the captions of staged posts,
+// one after the other.
+func (w *Watcher) SetFooterRotator(rotator *footers.Rotator) {
+	w.footers = rotator
+}
+
+// Start creates the staging subdirectories and scans the directory until ctx is cancelled.
+func (w *Watcher) Start(ctx context.Context) error {
+	for _, sub := range []string{publishedDir, rejectedDir} {
+		if err := os.MkdirAll(filepath.Join(w.dir, sub), 0o755); err != nil {
+			return fmt.Errorf("failed to create staging directory %s: %w", sub, err)
+		}
+	}
+	log.Printf("[Staging] Watching %s every %s", w.dir, w.pollInterval)
+
+	go func() {
+		ticker := time.NewTicker(w.pollInterval)
+		defer ticker.Stop()
+		for {
+			w.scan(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// scan queues every settled media file in the staging directory that isn't already queued.
+func (w *Watcher) scan(ctx context.Context) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		log.Printf("[Staging] Error reading %s: %v", w.dir, err)
+		return
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || strings.EqualFold(filepath.Ext(name), ".txt") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < settleTime {
+			continue // Vanished or still being written
+		}
+
+		w.mu.Lock()
+		queued := w.inFlight[name]
+		if !queued {
+			w.inFlight[name] = true
+		}
+		w.mu.Unlock()
+		if queued {
+			continue
+		}
+
+		if err := w.stage(ctx, name, info); err != nil {
+			log.Printf("[Staging] Rejecting %s: %v", name, err)
+			w.finish(name, rejectedDir)
+		}
+	}
+}
+
+// stage validates a file and submits it to the publish queue.
+func (w *Watcher) stage(ctx context.Context, name string, info os.FileInfo) error {
+	kind, ok := mediaTypes[strings.ToLower(filepath.Ext(name))]
+	if !ok {
+		return errors.New("unsupported file type")
+	}
+	if w.maxFileSize > 0 && info.Size() > w.maxFileSize {
+		return fmt.Errorf("file is %d bytes, limit is %d", info.Size(), w.maxFileSize)
+	}
+	caption, err := w.readCaption(name)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(w.dir, name)
+	var channelPostID int
+	var fileID string
+	key := fmt.Sprintf("staged:%s:%d:%d", name, info.Size(), info.ModTime().Unix())
+	footed := false
+	w.publishQueue.Submit(ctx, key, func(ctx context.Context) error {
+		// The footer is picked once, so a rate-limited send that is retried keeps its place in
+		// the rotation
+		if !footed {
+			footer, err := w.footers.Next(ctx)
+			if err != nil {
+				log.Printf("[Staging] Hashtag footer rotation for %s: %v", name, err)
+			}
+			caption = footers.Append(caption, footer)
+			footed = true
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", name, err)
+		}
+		defer file.Close()
+
+		var sentMsg *telego.Message
+		if kind == "video" {
+			sentMsg, err = w.bot.SendVideo(ctx, &telego.SendVideoParams{ChatID: tu.ID(w.channelID), Video: tu.File(file), Caption: caption})
+		} else {
+			sentMsg, err = w.bot.SendPhoto(ctx, &telego.SendPhotoParams{ChatID: tu.ID(w.channelID), Photo: tu.File(file), Caption: caption})
+		}
+		if err != nil {
+			return err
+		}
+		channelPostID = sentMsg.MessageID
+		fileID = utils.MediaFileID(sentMsg)
+		return nil
+	}, func(ctx context.Context, err error) {
+		switch {
+		case err == nil:
+			log.Printf("[Staging] Published %s as channel message %d", name, channelPostID)
+			logEntry := models.PostLog{
+				Caption:       caption,
+				MessageType:   kind,
+				ReceivedAt:    info.ModTime(),
+				PublishedAt:   time.Now(),
+				ChannelID:     w.channelID,
+				ChannelPostID: channelPostID,
+				SourceFile:    name,
+				FileID:        fileID,
+				Origin:        models.PostOriginScheduled,
+			}
+			if logErr := w.postLogger.LogPublishedPost(logEntry); logErr != nil {
+				log.Printf("[Staging] Failed attempt to log staged post %s to DB. Error: %v", name, logErr)
+			}
+			w.finish(name, publishedDir)
+		case errors.Is(err, publisher.ErrAlreadyPublished):
+			w.finish(name, publishedDir)
+		case telegoapi.ErrorCode(err) == 400:
+			// Telegram refused the file itself; retrying won't help
+			log.Printf("[Staging] Telegram rejected %s: %v", name, err)
+			w.finish(name, rejectedDir)
+		default:
+			// Leave the file in place to retry on a later scan
+			log.Printf("[Staging] Failed to publish %s, will retry: %v", name, err)
+			w.mu.Lock()
+			delete(w.inFlight, name)
+			w.mu.Unlock()
+		}
+	})
+	return nil
+}
+
+// readCaption returns the caption from the file's "<name>.txt" companion, if there is one.
+func (w *Watcher) readCaption(name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(w.dir, captionFileName(name)))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read caption: %w", err)
+	}
+	caption := strings.TrimSpace(string(data))
+	if utf8.RuneCountInString(caption) > maxCaptionLength {
+		return "", fmt.Errorf("caption is longer than %d characters", maxCaptionLength)
+	}
+	return caption, nil
+}
+
+// finish moves a processed file and its caption file into the given subdirectory. Files already
+// there are kept: if the name is taken, the file is moved under a numbered name, e.g. "a-2.jpg".
+func (w *Watcher) finish(name, subdir string) {
+	target := availableName(filepath.Join(w.dir, subdir), name)
+	for _, move := range [][2]string{{name, target}, {captionFileName(name), captionFileName(target)}} {
+		err := os.Rename(filepath.Join(w.dir, move[0]), filepath.Join(w.dir, subdir, move[1]))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("[Staging] Error moving %s to %s: %v", move[0], subdir, err)
+		}
+	}
+	w.mu.Lock()
+	delete(w.inFlight, name)
+	w.mu.Unlock()
+}
+
+// captionFileName returns the name of the caption file for a media file. It keeps the media
+// file's extension, so "a.jpg" and "a.mp4" have captions of their own.
+func captionFileName(name string) string {
+	return name + ".txt"
+}
+
+// availableName returns name, or a numbered variant of it, such that neither the file nor its
+// caption file exists in dir.
+func availableName(dir, name string) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 2; exists(filepath.Join(dir, candidate)) || exists(filepath.Join(dir, captionFileName(candidate))); i++ {
+		candidate = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	return candidate
+}
+
+// exists reports whether a file exists. Errors other than a missing file count as existing,
+// so nothing is overwritten.
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return !errors.Is(err, os.ErrNotExist)
+}
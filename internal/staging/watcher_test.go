@@ -0,0 +1,43 @@
+package staging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinishKeepsFilesOfTheSameName(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, publishedDir), 0o755))
+	w := &Watcher{dir: dir, inFlight: make(map[string]bool)}
+	stageFile := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	stageFile("a.jpg", "first")
+	stageFile("a.jpg.txt", "first caption")
+	w.finish("a.jpg", publishedDir)
+	stageFile("a.jpg", "second")
+	w.finish("a.jpg", publishedDir)
+	stageFile("a.jpg", "third")
+	w.finish("a.jpg", publishedDir)
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, publishedDir, name))
+		require.NoError(t, err)
+		return string(data)
+	}
+	assert.Equal(t, "first", read("a.jpg"))
+	assert.Equal(t, "first caption", read("a.jpg.txt"))
+	assert.Equal(t, "second", read("a-2.jpg"))
+	assert.Equal(t, "third", read("a-3.jpg"))
+	assert.NoFileExists(t, filepath.Join(dir, "a.jpg"))
+}
+
+func TestCaptionFileKeepsTheExtension(t *testing.T) {
+	assert.Equal(t, "a.jpg.txt", captionFileName("a.jpg"))
+	assert.NotEqual(t, captionFileName("a.jpg"), captionFileName("a.mp4"))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 	"vrcmemes-bot/internal/locales"
 
 	"github.com/mymmrac/telego"
@@ -12,18 +13,20 @@ import (
 
 // IsAdmin is now defined correctly in manager.go
 
-// HandleReviewCommand handles the /review command by initiating a review session.
-func (m *Manager) HandleReviewCommand(ctx context.Context, update telego.Update) error {
+// HandleReviewCommand handles the /review command by initiating a review session that hands out
+// suggestions in the given order, or in the default order if it is empty.
+func (m *Manager) HandleReviewCommand(ctx context.Context, update telego.Update, order ReviewOrder) error {
 	chatID := update.Message.Chat.ID
 	adminID := update.Message.From.ID
 	log.Printf("[/review] command received from admin %d in chat %d", adminID, chatID)
 
-	// Determine language (use default for now)
-	lang := locales.GetDefaultLanguageTag().String()
-	if update.Message.From != nil && update.Message.From.LanguageCode != "" {
-		// lang = update.Message.From.LanguageCode // TODO: Use admin preference
+	localizer := m.localizerForUser(ctx, update.Message.From)
+
+	// Don't open overlapping sessions; offer to resume the unfinished one instead
+	if session, active := m.activeReviewSession(adminID); active {
+		log.Printf("[/review] Admin %d already has an active session, prompting to resume", adminID)
+		return m.promptResumeReviewSession(ctx, adminID, chatID, session)
 	}
-	localizer := locales.NewLocalizer(lang)
 
 	// Send confirmation message
 	startMsg := locales.GetMessage(localizer, "MsgReviewSessionStarting", nil, nil)
@@ -33,7 +36,7 @@ func (m *Manager) HandleReviewCommand(ctx context.Context, update telego.Update)
 		// Don't return here, try starting the session anyway
 	}
 
-	err = m.startReviewSession(ctx, adminID, chatID)
+	err = m.startReviewSession(ctx, adminID, chatID, order)
 	if err != nil {
 		log.Printf("Error starting review session for admin %d: %v", adminID, err)
 		// Send localized error message to the admin
@@ -46,16 +49,42 @@ func (m *Manager) HandleReviewCommand(ctx context.Context, update telego.Update)
 	return err // Return the error from startReviewSession (or nil if successful)
 }
 
-// startReviewSession starts a new review session for an admin.
-func (m *Manager) startReviewSession(ctx context.Context, adminID, chatID int64) error {
-	const batchSize = 5                                               // Number of suggestions to review at once
-	suggestions, _, err := m.GetPendingSuggestions(ctx, batchSize, 0) // Fetch first batch
+// startReviewSession starts a new review session for an admin, taking suggestions in the given
+// review order. Suggestions already claimed by other admins' sessions are skipped.
+func (m *Manager) startReviewSession(ctx context.Context, adminID, chatID int64, order ReviewOrder) error {
+	const batchSize = 5 // Number of suggestions to review at once
+
+	m.reviewSessionsMutex.RLock()
+	claimedCount := len(m.claimedSuggestions)
+	m.reviewSessionsMutex.RUnlock()
+
+	// Over-fetch by the number of claimed suggestions so filtering still leaves a full batch
+	candidates, totalPending, err := m.pendingInReviewOrder(ctx, adminID, order, batchSize+claimedCount)
 	if err != nil {
 		return fmt.Errorf("failed to get pending suggestions: %w", err)
 	}
 
-	// TODO: Determine language from adminID/chatID preferences?
-	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+	localizer := m.localizerForUserID(ctx, adminID)
+
+	now := time.Now()
+	m.reviewSessionsMutex.Lock()
+	previous := m.removeReviewSessionLocked(adminID) // Release anything left over from a previous session
+	suggestions := m.claimUnclaimedLocked(adminID, candidates, batchSize)
+	if len(suggestions) > 0 {
+		m.reviewSessions[adminID] = &ReviewSession{
+			AdminID:        adminID, // Store the admin ID
+			ReviewChatID:   chatID,  // Store the chat ID where the review started
+			Suggestions:    suggestions,
+			CurrentIndex:   0,
+			StartedAt:      now,
+			LastActivityAt: now,
+		}
+	}
+	m.reviewSessionsMutex.Unlock()
+
+	if previous != nil {
+		go m.deleteReviewMessages(context.Background(), previous.ReviewChatID, previous.CurrentMediaMessageIDs, previous.CurrentControlMessageID)
+	}
 
 	if len(suggestions) == 0 {
 		queueEmptyMsg := locales.GetMessage(localizer, "MsgReviewQueueIsEmpty", nil, nil)
@@ -63,17 +92,12 @@ func (m *Manager) startReviewSession(ctx context.Context, adminID, chatID int64)
 		return err
 	}
 
-	session := &ReviewSession{
-		AdminID:      adminID, // Store the admin ID
-		ReviewChatID: chatID,  // Store the chat ID where the review started
-		Suggestions:  suggestions,
-		CurrentIndex: 0,
+	// Tell the admin how many suggestions are waiting in total (not just in this batch)
+	pendingMsg := locales.GetPluralMessage(localizer, "MsgReviewPendingCount", int(totalPending), nil)
+	if _, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), pendingMsg)); err != nil {
+		log.Printf("Error sending pending count message to %d: %v", chatID, err)
 	}
 
-	m.reviewSessionsMutex.Lock()
-	m.reviewSessions[adminID] = session
-	m.reviewSessionsMutex.Unlock()
-
 	// Send the first suggestion for review
 	return m.SendReviewMessage(ctx, chatID, adminID, 0)
 }
@@ -84,7 +108,7 @@ func (m *Manager) handleResetDailyStats(ctx context.Context, chatID int64, userI
 	// Perform reset
 	if err := m.repo.ResetDailyLimits(ctx); err != nil {
 		// Send error message
-		localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+		localizer := m.localizerForUserID(ctx, userID)
 		errMsg := locales.GetMessage(localizer, "MsgErrorResettingStats", nil, nil)
 		_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), errMsg))
 		return fmt.Errorf("failed to reset daily stats: %w", err)
@@ -0,0 +1,356 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/auth"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const (
+	// quietHoursCheckInterval is how often notifications held back during quiet hours are checked.
+	quietHoursCheckInterval = time.Minute
+	// adminListTTL is how long the admin list of the last refresh is reused for pings and assignments.
+	adminListTTL = auth.DefaultAdminCacheTTL
+)
+
+// ErrQuietHoursInvalid is returned by ParseQuietHours for malformed quiet hours.
+var ErrQuietHoursInvalid = errors.New("quiet hours must look like 23:00-08:00")
+
+// QuietHours is a daily period in the channel time zone during which an admin only gets
+// critical notifications. It may wrap around midnight.
+type QuietHours struct {
+	Start time.Duration // Since midnight
+	End   time.Duration // Since midnight
+}
+
+// ParseQuietHours parses quiet hours written as "HH:MM-HH:MM".
+func ParseQuietHours(s string) (QuietHours, error) {
+	startText, endText, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return QuietHours{}, ErrQuietHoursInvalid
+	}
+	start, err := parseClock(startText)
+	if err != nil {
+		return QuietHours{}, err
+	}
+	end, err := parseClock(endText)
+	if err != nil {
+		return QuietHours{}, err
+	}
+	if start == end {
+		return QuietHours{}, ErrQuietHoursInvalid
+	}
+	return QuietHours{Start: start, End: end}, nil
+}
+
+// parseClock parses a time of day written as "HH:MM".
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, ErrQuietHoursInvalid
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// String formats the quiet hours as "HH:MM-HH:MM".
+func (q QuietHours) String() string {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return clock(q.Start) + "-" + clock(q.End)
+}
+
+// Contains reports whether t falls into the quiet hours in the given time zone.
+func (q QuietHours) Contains(t time.Time, loc *time.Location) bool {
+	t = t.In(loc)
+	sinceMidnight := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if q.Start < q.End {
+		return sinceMidnight >= q.Start && sinceMidnight < q.End
+	}
+	return sinceMidnight >= q.Start || sinceMidnight < q.End
+}
+
+// Notifier delivers notifications to users reliably, e.g. *outbox.Outbox.
+type Notifier interface {
+	// Notify stores a notification for delivery; an error means it was not stored.
+	Notify(ctx context.Context, kind string, chatID int64, text string) error
+}
+
+// SetNotifier routes notifications that aren't direct replies (admin pings, quiet hours
+// summaries, intake receipts) through a notifier, so they survive restarts and Telegram errors.
+// Without it, they are sent once, directly.
+func (m *Manager) SetNotifier(notifier Notifier) {
+	m.notifier = notifier
+}
+
+// sendNotification sends a notification through the notifier, falling back to sending it
+// directly if the notifier is not set or can't store it. Notifications of a category the user
+// turned off with /notifications are dropped.
+func (m *Manager) sendNotification(ctx context.Context, kind string, chatID int64, text string) error {
+	if category := models.NotificationKindCategory(kind); category != "" && !m.notificationsEnabled(ctx, chatID, category) {
+		log.Printf("[Notify Chat:%d] User turned off %s notifications, dropping %s notification", chatID, category, kind)
+		return nil
+	}
+	if m.notifier != nil {
+		err := m.notifier.Notify(ctx, kind, chatID, text)
+		if err == nil {
+			return nil
+		}
+		log.Printf("[Notify Chat:%d] Error storing %s notification, sending it directly: %v", chatID, kind, err)
+	}
+	_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), text))
+	return err
+}
+
+// notificationsEnabled reports whether a user receives notifications of a category. If their
+// preferences can't be loaded, the notification is sent.
+func (m *Manager) notificationsEnabled(ctx context.Context, userID int64, category string) bool {
+	user, err := m.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		log.Printf("[Notify User:%d] Error loading notification preferences: %v", userID, err)
+		return true
+	}
+	return user.NotificationsEnabled(category)
+}
+
+// SetSettingsRepository sets where settings such as quiet hours and caption styles are stored.
+// Without it, quiet hours can't be set and reviewers can't pick caption styles.
+func (m *Manager) SetSettingsRepository(repo database.SettingsRepository) {
+	m.settingsRepo = repo
+}
+
+// SetNewSuggestionPings enables notifying the admins in private chat about each new suggestion.
+func (m *Manager) SetNewSuggestionPings(enabled bool) {
+	m.newSuggestionPings = enabled
+}
+
+// GetQuietHours returns the quiet hours of an admin, or nil if they have none.
+func (m *Manager) GetQuietHours(ctx context.Context, adminID int64) (*QuietHours, error) {
+	m.notifyMutex.Lock()
+	quiet, cached := m.quietHours[adminID]
+	m.notifyMutex.Unlock()
+	if cached || m.settingsRepo == nil {
+		return quiet, nil
+	}
+
+	setting, err := m.settingsRepo.GetSetting(ctx, models.QuietHoursSettingKey(adminID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quiet hours of admin %d: %w", adminID, err)
+	}
+	if setting != nil {
+		parsed, err := ParseQuietHours(setting.Value)
+		if err != nil {
+			log.Printf("[QuietHours Admin:%d] Ignoring malformed stored quiet hours %q", adminID, setting.Value)
+		} else {
+			quiet = &parsed
+		}
+	}
+	m.notifyMutex.Lock()
+	m.quietHours[adminID] = quiet
+	m.notifyMutex.Unlock()
+	return quiet, nil
+}
+
+// SetQuietHours stores the quiet hours of an admin; nil turns them off. Notifications held back
+// so far are sent with the next summary check.
+func (m *Manager) SetQuietHours(ctx context.Context, adminID int64, quiet *QuietHours) error {
+	if m.settingsRepo == nil {
+		return errors.New("settings repository is not configured")
+	}
+	key := models.QuietHoursSettingKey(adminID)
+	var err error
+	if quiet == nil {
+		err = m.settingsRepo.DeleteSetting(ctx, key)
+	} else {
+		err = m.settingsRepo.SetSetting(ctx, key, quiet.String(), adminID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save quiet hours of admin %d: %w", adminID, err)
+	}
+	m.notifyMutex.Lock()
+	m.quietHours[adminID] = quiet
+	m.notifyMutex.Unlock()
+	return nil
+}
+
+// notifyAdmin sends a notification to an admin in private chat. Non-critical notifications
+// sent during the admin's quiet hours are held back and sent as one summary afterwards.
+func (m *Manager) notifyAdmin(ctx context.Context, adminID int64, text string, critical bool) {
+	if !critical {
+		quiet, err := m.GetQuietHours(ctx, adminID)
+		if err != nil {
+			log.Printf("[AdminNotify Admin:%d] Error getting quiet hours, notifying anyway: %v", adminID, err)
+		}
+		if quiet != nil && quiet.Contains(time.Now(), m.location) {
+			m.notifyMutex.Lock()
+			m.deferredNotifications[adminID] = append(m.deferredNotifications[adminID], text)
+			m.notifyMutex.Unlock()
+			m.saveHeldNotifications(ctx)
+			return
+		}
+	}
+	if err := m.sendNotification(ctx, models.NotificationKindAdminPing, adminID, text); err != nil {
+		log.Printf("[AdminNotify Admin:%d] Error sending notification: %v", adminID, err)
+	}
+}
+
+// StartQuietHoursSummaries periodically sends admins whose quiet hours ended a summary of the
+// notifications held back, until ctx is done. Notifications held back before a restart are
+// loaded first.
+func (m *Manager) StartQuietHoursSummaries(ctx context.Context) {
+	m.loadHeldNotifications(ctx)
+	go func() {
+		ticker := time.NewTicker(quietHoursCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.sendQuietHoursSummaries(ctx, time.Now())
+			}
+		}
+	}()
+}
+
+// sendQuietHoursSummaries sends the held back notifications of admins who are out of quiet hours at now.
+func (m *Manager) sendQuietHoursSummaries(ctx context.Context, now time.Time) {
+	m.notifyMutex.Lock()
+	due := make(map[int64][]string)
+	for adminID, texts := range m.deferredNotifications {
+		if quiet := m.quietHours[adminID]; quiet != nil && quiet.Contains(now, m.location) {
+			continue
+		}
+		due[adminID] = texts
+		delete(m.deferredNotifications, adminID)
+	}
+	m.notifyMutex.Unlock()
+	if len(due) > 0 {
+		m.saveHeldNotifications(ctx)
+	}
+
+	for adminID, texts := range due {
+		localizer := m.localizerForUserID(ctx, adminID)
+		summary := locales.GetPluralMessage(localizer, "MsgQuietHoursSummary", len(texts), nil) +
+			"\n\n" + strings.Join(texts, "\n\n")
+		if err := m.sendNotification(ctx, models.NotificationKindQuietHoursSummary, adminID, summary); err != nil {
+			log.Printf("[AdminNotify Admin:%d] Error sending quiet hours summary of %d notifications: %v", adminID, len(texts), err)
+		}
+	}
+}
+
+// pingAdminsAboutSuggestion tells the channel admins about a new suggestion, if enabled.
+// The admin it was assigned to has already been told.
+func (m *Manager) pingAdminsAboutSuggestion(ctx context.Context, suggestion *models.Suggestion) {
+	if !m.newSuggestionPings {
+		return
+	}
+	adminIDs, err := m.channelAdmins(ctx)
+	if err != nil {
+		log.Printf("[AdminNotify] Error getting admins to ping about suggestion %s: %v", suggestion.ID.Hex(), err)
+		return
+	}
+	for _, adminID := range adminIDs {
+		if adminID == suggestion.SuggesterID || adminID == suggestion.AssignedTo {
+			continue
+		}
+		localizer := m.localizerForUserID(ctx, adminID)
+		text := locales.GetMessage(localizer, "MsgAdminNewSuggestion", map[string]interface{}{
+			"Files": len(suggestion.FileIDs),
+			"Code":  suggestion.Ref(),
+		}, nil)
+		m.notifyAdmin(ctx, adminID, text, false)
+	}
+}
+
+// channelAdmins returns the admin IDs of the last admin checker refresh, refreshing them when
+// they are older than adminListTTL, so a burst of suggestions doesn't query Telegram for each.
+func (m *Manager) channelAdmins(ctx context.Context) ([]int64, error) {
+	m.notifyMutex.Lock()
+	if m.adminIDs != nil && time.Since(m.adminIDsAt) < adminListTTL {
+		adminIDs := slices.Clone(m.adminIDs)
+		m.notifyMutex.Unlock()
+		return adminIDs, nil
+	}
+	m.notifyMutex.Unlock()
+
+	adminIDs, err := m.adminChecker.Refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m.notifyMutex.Lock()
+	m.adminIDs, m.adminIDsAt = slices.Clone(adminIDs), time.Now()
+	m.notifyMutex.Unlock()
+	return adminIDs, nil
+}
+
+// forgetChannelAdmins drops the admin list, so the next ping or assignment refreshes it.
+func (m *Manager) forgetChannelAdmins() {
+	m.notifyMutex.Lock()
+	m.adminIDs = nil
+	m.notifyMutex.Unlock()
+}
+
+// saveHeldNotifications stores the notifications held back during quiet hours, so they are
+// still summarized after a restart. Without a settings repository they are kept in memory only.
+func (m *Manager) saveHeldNotifications(ctx context.Context) {
+	if m.settingsRepo == nil {
+		return
+	}
+	m.heldSaveMutex.Lock()
+	defer m.heldSaveMutex.Unlock()
+
+	m.notifyMutex.Lock()
+	held := len(m.deferredNotifications)
+	value, err := models.EncodeHeldNotifications(m.deferredNotifications)
+	m.notifyMutex.Unlock()
+	if err == nil {
+		if held == 0 {
+			err = m.settingsRepo.DeleteSetting(ctx, models.SettingHeldNotifications)
+		} else {
+			err = m.settingsRepo.SetSetting(ctx, models.SettingHeldNotifications, value, 0)
+		}
+	}
+	if err != nil {
+		log.Printf("[AdminNotify] Error saving notifications held back during quiet hours: %v", err)
+	}
+}
+
+// loadHeldNotifications restores the notifications held back before a restart, along with the
+// quiet hours of their admins, which decide when the summaries are sent.
+func (m *Manager) loadHeldNotifications(ctx context.Context) {
+	if m.settingsRepo == nil {
+		return
+	}
+	setting, err := m.settingsRepo.GetSetting(ctx, models.SettingHeldNotifications)
+	if err != nil {
+		log.Printf("[AdminNotify] Error loading notifications held back during quiet hours: %v", err)
+		return
+	}
+	held, err := models.DecodeHeldNotifications(setting)
+	if err != nil {
+		log.Printf("[AdminNotify] Ignoring stored held back notifications: %v", err)
+		return
+	}
+	for adminID, texts := range held {
+		if _, err := m.GetQuietHours(ctx, adminID); err != nil {
+			log.Printf("[AdminNotify Admin:%d] Error getting quiet hours of held back notifications: %v", adminID, err)
+		}
+		m.notifyMutex.Lock()
+		m.deferredNotifications[adminID] = append(texts, m.deferredNotifications[adminID]...)
+		m.notifyMutex.Unlock()
+	}
+	if len(held) > 0 {
+		log.Printf("[AdminNotify] Loaded held back notifications of %d admins", len(held))
+	}
+}
@@ -0,0 +1,148 @@
+package suggestions
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/format"
+	"vrcmemes-bot/internal/locales"
+)
+
+const (
+	// assignmentCheckInterval is how often assignments left untouched are looked for.
+	assignmentCheckInterval = 10 * time.Minute
+	// staleAssignmentBatch bounds how many assignments are handed over per check.
+	staleAssignmentBatch = 50
+)
+
+// SetReviewAssignment enables assigning each new suggestion to the next available admin in turn.
+// Admins are told about their assignments and review them with /review mine. Suggestions left
+// untouched for reassignAfter are handed to the next admin; 0 never reassigns them.
+func (m *Manager) SetReviewAssignment(enabled bool, reassignAfter time.Duration) {
+	m.reviewAssignment = enabled
+	m.reassignAfter = reassignAfter
+}
+
+// nextAssignee returns the admin after the last assigned one, in ID order, who isn't excluded
+// and isn't in their quiet hours, or 0 if there is none.
+func (m *Manager) nextAssignee(ctx context.Context, exclude ...int64) (int64, error) {
+	adminIDs, err := m.channelAdmins(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get admins: %w", err)
+	}
+	slices.Sort(adminIDs)
+
+	now := time.Now()
+	var available []int64
+	for _, adminID := range adminIDs {
+		if slices.Contains(exclude, adminID) {
+			continue
+		}
+		quiet, err := m.GetQuietHours(ctx, adminID)
+		if err != nil {
+			log.Printf("[Assignment Admin:%d] Error getting quiet hours, treating admin as available: %v", adminID, err)
+		}
+		if quiet != nil && quiet.Contains(now, m.location) {
+			continue
+		}
+		available = append(available, adminID)
+	}
+	if len(available) == 0 {
+		return 0, nil
+	}
+
+	m.assignMutex.Lock()
+	defer m.assignMutex.Unlock()
+	next := available[0]
+	for _, adminID := range available {
+		if adminID > m.lastAssignee {
+			next = adminID
+			break
+		}
+	}
+	m.lastAssignee = next
+	return next, nil
+}
+
+// assignSuggestion assigns a new suggestion to the next available admin and tells them, if
+// review assignment is enabled. Errors are logged: the suggestion stays in the common queue.
+func (m *Manager) assignSuggestion(ctx context.Context, suggestion *models.Suggestion) {
+	if !m.reviewAssignment {
+		return
+	}
+	assignee, err := m.nextAssignee(ctx, suggestion.SuggesterID)
+	if err != nil || assignee == 0 {
+		log.Printf("[Assignment] Suggestion %s left unassigned (err: %v)", suggestion.ID.Hex(), err)
+		return
+	}
+	if err := m.repo.AssignSuggestion(ctx, suggestion.ID, assignee); err != nil {
+		log.Printf("[Assignment] Error assigning suggestion %s to admin %d: %v", suggestion.ID.Hex(), assignee, err)
+		return
+	}
+	suggestion.AssignedTo = assignee
+	log.Printf("[Assignment] Suggestion %s assigned to admin %d", suggestion.ID.Hex(), assignee)
+
+	localizer := m.localizerForUserID(ctx, assignee)
+	m.notifyAdmin(ctx, assignee, locales.GetMessage(localizer, "MsgSuggestionAssigned", map[string]interface{}{
+		"Code": suggestion.Ref(),
+	}, nil), false)
+}
+
+// StartAssignmentReassigner periodically hands suggestions whose assignee left them untouched
+// to the next available admin, until ctx is done. It does nothing unless review assignment
+// and reassignment are enabled.
+func (m *Manager) StartAssignmentReassigner(ctx context.Context) {
+	if !m.reviewAssignment || m.reassignAfter <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(assignmentCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.reassignStale(ctx, time.Now())
+			}
+		}
+	}()
+}
+
+// reassignStale hands the suggestions assigned before now minus the reassignment delay to the
+// next available admin. Suggestions in a review session count as touched and are left alone,
+// as are suggestions no other admin is available for.
+func (m *Manager) reassignStale(ctx context.Context, now time.Time) {
+	stale, err := m.repo.GetStaleAssignments(ctx, now.Add(-m.reassignAfter), staleAssignmentBatch)
+	if err != nil {
+		log.Printf("[Assignment] Error getting stale assignments: %v", err)
+		return
+	}
+	for _, suggestion := range stale {
+		if m.isSuggestionClaimed(suggestion.ID) {
+			continue
+		}
+		assignee, err := m.nextAssignee(ctx, suggestion.SuggesterID, suggestion.AssignedTo)
+		if err != nil {
+			log.Printf("[Assignment] Error picking a new assignee for suggestion %s: %v", suggestion.ID.Hex(), err)
+			return
+		}
+		if assignee == 0 {
+			continue
+		}
+		if err := m.repo.AssignSuggestion(ctx, suggestion.ID, assignee); err != nil {
+			log.Printf("[Assignment] Error reassigning suggestion %s to admin %d: %v", suggestion.ID.Hex(), assignee, err)
+			continue
+		}
+		log.Printf("[Assignment] Suggestion %s reassigned from admin %d to admin %d", suggestion.ID.Hex(), suggestion.AssignedTo, assignee)
+
+		localizer := m.localizerForUserID(ctx, assignee)
+		m.notifyAdmin(ctx, assignee, locales.GetMessage(localizer, "MsgSuggestionReassigned", map[string]interface{}{
+			"Duration": format.Duration(localizer, m.reassignAfter),
+			"Code":     suggestion.Ref(),
+		}, nil), false)
+	}
+}
@@ -4,12 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"strconv"
 	"strings"
 	"vrcmemes-bot/internal/locales"
 
 	"github.com/mymmrac/telego"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // HandleCallbackQuery handles callback queries for suggestion review.
@@ -19,41 +17,70 @@ func (m *Manager) HandleCallbackQuery(ctx context.Context, query telego.Callback
 	adminUsername := query.From.Username
 	callbackData := query.Data
 
-	if !strings.HasPrefix(callbackData, "review:") {
-		return false, nil
+	if strings.HasPrefix(callbackData, consentCallbackPrefix) {
+		return true, m.handleConsentCallback(ctx, query)
 	}
-
-	parts := strings.Split(query.Data, ":")
-	lang := locales.GetDefaultLanguageTag().String()
-	localizer := locales.NewLocalizer(lang)
-
-	if len(parts) != 4 {
-		log.Printf("[CallbackQuery] Invalid data format: %s", callbackData)
-		errorMsg := locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil)
-		_ = m.answerCallbackQuery(ctx, query.ID, errorMsg, true)
-		return true, fmt.Errorf("invalid callback data format")
+	if strings.HasPrefix(callbackData, tagCallbackPrefix) {
+		return true, m.handleTagCallback(ctx, query)
 	}
-
-	suggestionIDHex := parts[1]
-	action := parts[2]
-	indexStr := parts[3]
-	currentIndex, err := strconv.Atoi(indexStr)
-	if err != nil {
-		log.Printf("[CallbackQuery] Invalid index in data: %s, err: %v", callbackData, err)
-		errorMsg := locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil)
-		_ = m.answerCallbackQuery(ctx, query.ID, errorMsg, true)
-		return true, fmt.Errorf("invalid index in callback data")
+	if strings.HasPrefix(callbackData, rubricCallbackPrefix) {
+		return true, m.handleRubricCallback(ctx, query)
+	}
+	if strings.HasPrefix(callbackData, styleCallbackPrefix) {
+		return true, m.handleStyleCallback(ctx, query)
+	}
+	if strings.HasPrefix(callbackData, mergeCallbackPrefix) {
+		return true, m.handleMergeCallback(ctx, query)
+	}
+	if strings.HasPrefix(callbackData, promoteCallbackPrefix) {
+		return true, m.handlePromoteCallback(ctx, query)
+	}
+	if strings.HasPrefix(callbackData, reopenCallbackPrefix) {
+		return true, m.handleReopenCallback(ctx, query)
 	}
+	if strings.HasPrefix(callbackData, creditCallbackPrefix) {
+		return true, m.handleCreditCallback(ctx, query)
+	}
+	if strings.HasPrefix(callbackData, rulesCallbackPrefix) {
+		return true, m.handleRulesCallback(ctx, query)
+	}
+	if strings.HasPrefix(callbackData, submitCallbackPrefix) {
+		return true, m.handleSubmitCallback(ctx, query)
+	}
+	if strings.HasPrefix(callbackData, directSuggestionCallbackPrefix) {
+		return true, m.handleDirectSuggestionCallback(ctx, query)
+	}
+	if strings.HasPrefix(callbackData, mySuggestionsCallbackPrefix) {
+		return true, m.handleMySuggestionsCallback(ctx, query)
+	}
+	if strings.HasPrefix(callbackData, reviewSessionCallbackPrefix) {
+		return true, m.handleReviewSessionCallback(ctx, query)
+	}
+	if strings.HasPrefix(callbackData, feedbackCallbackPrefix) {
+		return true, m.handleFeedbackCallback(ctx, query)
+	}
+	if strings.HasPrefix(callbackData, feedbackContactCallbackPrefix) {
+		return true, m.handleFeedbackContactCallback(ctx, query)
+	}
+	if strings.HasPrefix(callbackData, feedbackCategoryCallbackPrefix) {
+		return true, m.handleFeedbackCategoryCallback(ctx, query)
+	}
+	if !strings.HasPrefix(callbackData, reviewCallbackPrefix) {
+		return false, nil
+	}
+
+	localizer := m.localizerForUser(ctx, &query.From)
 
-	suggestionID, err := primitive.ObjectIDFromHex(suggestionIDHex)
+	parsed, err := parseReviewCallback(callbackData)
 	if err != nil {
-		log.Printf("[CallbackQuery] Invalid suggestion ID hex in data: %s, err: %v", callbackData, err)
+		log.Printf("[CallbackQuery] Rejected callback data %q: %v", callbackData, err)
 		errorMsg := locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil)
 		_ = m.answerCallbackQuery(ctx, query.ID, errorMsg, true)
-		return true, fmt.Errorf("invalid suggestion ID in callback data")
+		return true, err
 	}
+	ref, action, currentIndex := parsed.Ref, parsed.Action, parsed.Index
 
-	log.Printf("[CallbackQuery] Parsed: Admin=%d, SugID=%s, Action=%s, Index=%d", adminID, suggestionIDHex, action, currentIndex)
+	log.Printf("[CallbackQuery] Parsed: Admin=%d, Ref=%s, Action=%s, Index=%d", adminID, ref, action, currentIndex)
 
 	isAdmin, err := m.adminChecker.IsAdmin(ctx, adminID)
 	if err != nil {
@@ -69,19 +96,20 @@ func (m *Manager) HandleCallbackQuery(ctx context.Context, query telego.Callback
 		return true, nil
 	}
 
-	m.reviewSessionsMutex.RLock()
-	session, sessionExists := m.reviewSessions[adminID]
-	m.reviewSessionsMutex.RUnlock()
+	session, sessionExists := m.activeReviewSession(adminID)
 
-	if !sessionExists || currentIndex < 0 || currentIndex >= len(session.Suggestions) || session.Suggestions[currentIndex].ID != suggestionID {
-		log.Printf("[CallbackQuery] Invalid session or suggestion mismatch for admin %d, index %d, ID %s", adminID, currentIndex, suggestionIDHex)
+	if !sessionExists || currentIndex < 0 || currentIndex >= len(session.Suggestions) || session.Suggestions[currentIndex].Ref() != ref {
+		log.Printf("[CallbackQuery] Invalid session or suggestion mismatch for admin %d, index %d, ref %s", adminID, currentIndex, ref)
 		expiredMsg := locales.GetMessage(localizer, "MsgReviewSessionExpired", nil, nil)
 		_ = m.answerCallbackQuery(ctx, query.ID, expiredMsg, true)
 		m.reviewSessionsMutex.Lock()
-		delete(m.reviewSessions, adminID)
+		m.removeReviewSessionLocked(adminID)
 		m.reviewSessionsMutex.Unlock()
 		return true, nil
 	}
+	suggestionID := session.Suggestions[currentIndex].ID
+	suggestionIDHex := suggestionID.Hex()
+	m.touchReviewSession(adminID)
 
 	var originalReviewMessageID int
 	if query.Message != nil {
@@ -93,34 +121,113 @@ func (m *Manager) HandleCallbackQuery(ctx context.Context, query telego.Callback
 	}
 
 	switch action {
-	case "approve":
+	case ReviewActionApprove:
 		log.Printf("[CallbackQuery] Action: Approve for SugID %s by Admin %d (%s)", suggestionIDHex, adminID, adminUsername)
-		err := m.handleApproveAction(ctx, query.ID, adminID, adminUsername, session, currentIndex, originalReviewMessageID, suggestionID)
+		if m.videoPolicyBlocks(ctx, query.ID, localizer, &session.Suggestions[currentIndex]) {
+			return true, nil
+		}
+		err := m.handleApproveAction(ctx, query.ID, adminID, adminUsername, session, currentIndex, originalReviewMessageID, suggestionID, false)
 		if err != nil {
 			log.Printf("[CallbackQuery] Error handling approve action: %v", err)
 			return true, err
 		}
-	case "reject":
+	case ReviewActionPreview:
+		log.Printf("[CallbackQuery] Action: Preview for SugID %s by Admin %d (%s)", suggestionIDHex, adminID, adminUsername)
+		if m.previewChannelID == 0 {
+			_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgPreviewChannelDisabled", nil, nil), true)
+			return true, nil
+		}
+		if m.videoPolicyBlocks(ctx, query.ID, localizer, &session.Suggestions[currentIndex]) {
+			return true, nil
+		}
+		err := m.handleApproveAction(ctx, query.ID, adminID, adminUsername, session, currentIndex, originalReviewMessageID, suggestionID, true)
+		if err != nil {
+			log.Printf("[CallbackQuery] Error handling preview action: %v", err)
+			return true, err
+		}
+	case ReviewActionReject:
 		log.Printf("[CallbackQuery] Action: Reject for SugID %s by Admin %d (%s)", suggestionIDHex, adminID, adminUsername)
 		err := m.handleRejectAction(ctx, query.ID, adminID, adminUsername, session, currentIndex, originalReviewMessageID, suggestionID)
 		if err != nil {
 			log.Printf("[CallbackQuery] Error handling reject action: %v", err)
 			return true, err
 		}
-	case "next":
+	case ReviewActionNext:
 		log.Printf("[CallbackQuery] Action: Next for SugID %s by Admin %d", suggestionIDHex, adminID)
 		err := m.handleNextAction(ctx, query.ID, adminID, session, currentIndex)
 		if err != nil {
 			log.Printf("[CallbackQuery] Error handling next action: %v", err)
 			return true, err
 		}
-	case "previous":
+	case ReviewActionPrevious:
 		log.Printf("[CallbackQuery] Action: Previous for SugID %s by Admin %d", suggestionIDHex, adminID)
 		err := m.handlePreviousAction(ctx, query.ID, adminID, session, currentIndex)
 		if err != nil {
 			log.Printf("[CallbackQuery] Error handling previous action: %v", err)
 			return true, err
 		}
+	case ReviewActionTags:
+		log.Printf("[CallbackQuery] Action: Tags for SugID %s by Admin %d", suggestionIDHex, adminID)
+		if err := m.showTagPicker(ctx, query.ID, adminID, session, currentIndex); err != nil {
+			log.Printf("[CallbackQuery] Error showing tag picker: %v", err)
+			return true, err
+		}
+	case ReviewActionRubric:
+		log.Printf("[CallbackQuery] Action: Rubric for SugID %s by Admin %d", suggestionIDHex, adminID)
+		if m.rubricRepo == nil {
+			return true, nil
+		}
+		if err := m.showRubricPicker(ctx, query.ID, adminID, session, currentIndex); err != nil {
+			log.Printf("[CallbackQuery] Error showing rubric picker: %v", err)
+			return true, err
+		}
+	case ReviewActionStyle:
+		log.Printf("[CallbackQuery] Action: Style for SugID %s by Admin %d", suggestionIDHex, adminID)
+		if m.settingsRepo == nil {
+			return true, nil
+		}
+		if err := m.showStylePicker(ctx, query.ID, adminID, session, currentIndex); err != nil {
+			log.Printf("[CallbackQuery] Error showing style picker: %v", err)
+			return true, err
+		}
+	case ReviewActionAsk:
+		log.Printf("[CallbackQuery] Action: Ask for SugID %s by Admin %d", suggestionIDHex, adminID)
+		if err := m.startSuggesterQuestion(ctx, query, session, currentIndex); err != nil {
+			log.Printf("[CallbackQuery] Error starting question to suggester: %v", err)
+			return true, err
+		}
+	case ReviewActionMerge:
+		log.Printf("[CallbackQuery] Action: Merge for SugID %s by Admin %d", suggestionIDHex, adminID)
+		if err := m.showMergePicker(ctx, query.ID, adminID, session, currentIndex); err != nil {
+			log.Printf("[CallbackQuery] Error showing merge picker: %v", err)
+			return true, err
+		}
+	case ReviewActionCaption:
+		log.Printf("[CallbackQuery] Action: Caption for SugID %s by Admin %d", suggestionIDHex, adminID)
+		if err := m.toggleCaptionChoice(ctx, query.ID, adminID, session, currentIndex); err != nil {
+			log.Printf("[CallbackQuery] Error switching caption choice: %v", err)
+		}
+	case ReviewActionProtect:
+		log.Printf("[CallbackQuery] Action: Protect for SugID %s by Admin %d", suggestionIDHex, adminID)
+		if err := m.toggleProtectContent(ctx, query.ID, adminID, session, currentIndex); err != nil {
+			log.Printf("[CallbackQuery] Error switching content protection: %v", err)
+			return true, err
+		}
+	case ReviewActionVideo:
+		log.Printf("[CallbackQuery] Action: Video policy for SugID %s by Admin %d", suggestionIDHex, adminID)
+		if err := m.toggleVideoPolicyOverride(ctx, query.ID, adminID, session, currentIndex); err != nil {
+			log.Printf("[CallbackQuery] Error switching video policy override: %v", err)
+			return true, err
+		}
+	case ReviewActionThumb:
+		log.Printf("[CallbackQuery] Action: Thumbnail for SugID %s by Admin %d", suggestionIDHex, adminID)
+		if m.thumbnails == nil {
+			return true, nil
+		}
+		if err := m.startThumbnailChoice(ctx, query, session, currentIndex); err != nil {
+			log.Printf("[CallbackQuery] Error starting thumbnail choice: %v", err)
+			return true, err
+		}
 	default:
 		log.Printf("[CallbackQuery] Unknown action: %s", action)
 		errorMsg := locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil)
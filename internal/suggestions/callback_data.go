@@ -0,0 +1,118 @@
+package suggestions
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// reviewCallbackPrefix starts the callback data of the review buttons.
+const reviewCallbackPrefix = "review:"
+
+const (
+	// maxCallbackDataLength is Telegram's limit for inline button callback data, in bytes.
+	maxCallbackDataLength = 64
+	// maxReviewIndexDigits bounds the suggestion index; review batches are far smaller.
+	maxReviewIndexDigits = 4
+)
+
+// ReviewAction is an action on a review button.
+type ReviewAction string
+
+const (
+	ReviewActionApprove  ReviewAction = "approve"
+	ReviewActionReject   ReviewAction = "reject"
+	ReviewActionPreview  ReviewAction = "preview" // Approves to the preview channel first, see preview.go
+	ReviewActionNext     ReviewAction = "next"
+	ReviewActionPrevious ReviewAction = "previous"
+	ReviewActionTags     ReviewAction = "tags"   // Opens the tag picker, see tags.go
+	ReviewActionRubric   ReviewAction = "rubric" // Opens the rubric picker, see rubrics.go
+	ReviewActionStyle    ReviewAction = "style"  // Opens the caption style picker, see styles.go
+	ReviewActionAsk      ReviewAction = "ask"    // Asks the suggester a question, see comments.go
+	ReviewActionMerge    ReviewAction = "merge"  // Opens the duplicate merge picker, see merge.go
+	ReviewActionProtect  ReviewAction = "lock"   // Switches protected content for the post, see protect.go
+	ReviewActionCaption  ReviewAction = "lang"   // Switches the published caption version, see translation.go
+	ReviewActionVideo    ReviewAction = "vid"    // Overrides the video policy for the post, see video_policy.go
+	ReviewActionThumb    ReviewAction = "thumb"  // Chooses the thumbnail of the video, see thumbnail.go
+)
+
+// IsGrantableReviewCallback reports whether callback data is of a button a /review grant covers:
+// approving, rejecting and moving through the suggestions of a review session. The other review
+// buttons (tags, rubrics, merging, ...) stay with channel admins.
+func IsGrantableReviewCallback(data string) bool {
+	parsed, err := parseReviewCallback(data)
+	if err != nil {
+		return false
+	}
+	switch parsed.Action {
+	case ReviewActionApprove, ReviewActionReject, ReviewActionNext, ReviewActionPrevious:
+		return true
+	}
+	return false
+}
+
+// reviewCallback is the parsed callback data of a review button: review:<ref>:<action>:<index>.
+type reviewCallback struct {
+	Ref    string // Reference of the suggestion, see models.Suggestion.Ref
+	Action ReviewAction
+	Index  int // Position of the suggestion in the admin's review batch
+}
+
+// String formats the callback data for an inline button.
+func (c reviewCallback) String() string {
+	return fmt.Sprintf("%s%s:%s:%d", reviewCallbackPrefix, c.Ref, c.Action, c.Index)
+}
+
+// Errors returned by parseReviewCallback.
+var (
+	errCallbackNotReview     = errors.New("not review callback data")
+	errCallbackMalformed     = errors.New("malformed review callback data")
+	errCallbackSuggestionRef = errors.New("invalid suggestion reference in review callback data")
+	errCallbackUnknownAction = errors.New("unknown review action")
+	errCallbackIndex         = errors.New("invalid index in review callback data")
+)
+
+// parseReviewCallback parses and validates review button callback data.
+// Only data produced by reviewCallback.String is accepted: exactly four fields, a
+// suggestion reference code (or a lowercase 24-digit hex ID of an older suggestion),
+// a known action and an unsigned decimal index without leading zeros.
+func parseReviewCallback(data string) (reviewCallback, error) {
+	if !strings.HasPrefix(data, reviewCallbackPrefix) {
+		return reviewCallback{}, errCallbackNotReview
+	}
+	if len(data) > maxCallbackDataLength {
+		return reviewCallback{}, fmt.Errorf("%w: longer than %d bytes", errCallbackMalformed, maxCallbackDataLength)
+	}
+
+	parts := strings.Split(data, ":")
+	if len(parts) != 4 {
+		return reviewCallback{}, fmt.Errorf("%w: expected 4 fields, got %d", errCallbackMalformed, len(parts))
+	}
+	ref, action, indexStr := parts[1], ReviewAction(parts[2]), parts[3]
+
+	if !isSuggestionRef(ref) {
+		return reviewCallback{}, fmt.Errorf("%w: %q", errCallbackSuggestionRef, ref)
+	}
+
+	switch action {
+	case ReviewActionApprove, ReviewActionReject, ReviewActionPreview, ReviewActionNext, ReviewActionPrevious, ReviewActionTags, ReviewActionRubric, ReviewActionStyle, ReviewActionAsk, ReviewActionMerge, ReviewActionProtect, ReviewActionCaption, ReviewActionVideo, ReviewActionThumb:
+	default:
+		return reviewCallback{}, fmt.Errorf("%w: %q", errCallbackUnknownAction, action)
+	}
+
+	if indexStr == "" || len(indexStr) > maxReviewIndexDigits || (len(indexStr) > 1 && indexStr[0] == '0') {
+		return reviewCallback{}, fmt.Errorf("%w: %q", errCallbackIndex, indexStr)
+	}
+	for _, r := range indexStr {
+		if r < '0' || r > '9' {
+			return reviewCallback{}, fmt.Errorf("%w: %q", errCallbackIndex, indexStr)
+		}
+	}
+	index, err := strconv.Atoi(indexStr)
+	if err != nil {
+		return reviewCallback{}, fmt.Errorf("%w: %q", errCallbackIndex, indexStr)
+	}
+
+	return reviewCallback{Ref: ref, Action: action, Index: index}, nil
+}
@@ -0,0 +1,153 @@
+package suggestions
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	goldenSuggestionCode = "S-4F7K"
+	// goldenSuggestionHex is the reference of suggestions created before reference codes.
+	goldenSuggestionHex = "65f1a2b3c4d5e6f708192a3b"
+)
+
+// reviewCallbackGolden is the callback data of review buttons already sent to admins,
+// which must keep parsing after an upgrade.
+var reviewCallbackGolden = []struct {
+	ref    string
+	action ReviewAction
+	index  int
+	data   string
+}{
+	{goldenSuggestionCode, ReviewActionApprove, 0, "review:S-4F7K:approve:0"},
+	{goldenSuggestionCode, ReviewActionReject, 3, "review:S-4F7K:reject:3"},
+	{goldenSuggestionCode, ReviewActionPreview, 1, "review:S-4F7K:preview:1"},
+	{goldenSuggestionCode, ReviewActionNext, 12, "review:S-4F7K:next:12"},
+	{goldenSuggestionCode, ReviewActionPrevious, 9999, "review:S-4F7K:previous:9999"},
+	{goldenSuggestionCode, ReviewActionTags, 2, "review:S-4F7K:tags:2"},
+	{goldenSuggestionCode, ReviewActionRubric, 2, "review:S-4F7K:rubric:2"},
+	{goldenSuggestionCode, ReviewActionStyle, 2, "review:S-4F7K:style:2"},
+	{goldenSuggestionCode, ReviewActionAsk, 5, "review:S-4F7K:ask:5"},
+	{goldenSuggestionCode, ReviewActionMerge, 5, "review:S-4F7K:merge:5"},
+	{goldenSuggestionCode, ReviewActionProtect, 7, "review:S-4F7K:lock:7"},
+	{goldenSuggestionCode, ReviewActionCaption, 7, "review:S-4F7K:lang:7"},
+	{goldenSuggestionCode, ReviewActionVideo, 8, "review:S-4F7K:vid:8"},
+	{goldenSuggestionCode, ReviewActionThumb, 8, "review:S-4F7K:thumb:8"},
+	{goldenSuggestionHex, ReviewActionApprove, 0, "review:65f1a2b3c4d5e6f708192a3b:approve:0"},
+	{goldenSuggestionHex, ReviewActionThumb, 9999, "review:65f1a2b3c4d5e6f708192a3b:thumb:9999"},
+}
+
+func TestReviewCallbackGolden(t *testing.T) {
+	for _, tt := range reviewCallbackGolden {
+		t.Run(tt.data, func(t *testing.T) {
+			cb := reviewCallback{Ref: tt.ref, Action: tt.action, Index: tt.index}
+			assert.Equal(t, tt.data, cb.String())
+			assert.LessOrEqual(t, len(cb.String()), maxCallbackDataLength)
+
+			parsed, err := parseReviewCallback(tt.data)
+			require.NoError(t, err)
+			assert.Equal(t, cb, parsed)
+		})
+	}
+}
+
+// TestReviewCallbackGoldenCoversEveryAction fails when a ReviewAction constant is declared
+// without a golden row, so new buttons get their callback data pinned too.
+func TestReviewCallbackGoldenCoversEveryAction(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "callback_data.go", nil, 0)
+	require.NoError(t, err)
+
+	covered := make(map[string]bool)
+	for _, tt := range reviewCallbackGolden {
+		covered[string(tt.action)] = true
+	}
+	var actions int
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			if typ, ok := value.Type.(*ast.Ident); !ok || typ.Name != "ReviewAction" {
+				continue
+			}
+			for i, name := range value.Names {
+				lit := value.Values[i].(*ast.BasicLit)
+				action := strings.Trim(lit.Value, `"`)
+				actions++
+				assert.True(t, covered[action], "%s (%q) has no golden callback data", name.Name, action)
+			}
+		}
+	}
+	assert.Positive(t, actions, "no ReviewAction constants found")
+}
+
+func TestParseReviewCallbackRejectsMalformedData(t *testing.T) {
+	const id = goldenSuggestionCode
+	tests := []struct {
+		name    string
+		data    string
+		wantErr error
+	}{
+		{"other callback", "reviewsession:resume", errCallbackNotReview},
+		{"empty", "", errCallbackNotReview},
+		{"prefix only", "review:", errCallbackMalformed},
+		{"missing index", "review:" + id + ":approve", errCallbackMalformed},
+		{"extra field", "review:" + id + ":approve:0:1", errCallbackMalformed},
+		{"too long", "review:" + id + ":approve:" + strings.Repeat("1", 60), errCallbackMalformed},
+		{"short id", "review:65f1a2:approve:0", errCallbackSuggestionRef},
+		{"uppercase id", "review:65F1A2B3C4D5E6F708192A3B:approve:0", errCallbackSuggestionRef},
+		{"non-hex id", "review:zzf1a2b3c4d5e6f708192a3b:approve:0", errCallbackSuggestionRef},
+		{"lowercase code", "review:s-4f7k:approve:0", errCallbackSuggestionRef},
+		{"confusable code", "review:S-0O1I:approve:0", errCallbackSuggestionRef},
+		{"long code", "review:S-4F7KA:approve:0", errCallbackSuggestionRef},
+		{"unknown action", "review:" + id + ":publish:0", errCallbackUnknownAction},
+		{"uppercase action", "review:" + id + ":Approve:0", errCallbackUnknownAction},
+		{"empty action", "review:" + id + "::0", errCallbackUnknownAction},
+		{"empty index", "review:" + id + ":next:", errCallbackIndex},
+		{"negative index", "review:" + id + ":next:-1", errCallbackIndex},
+		{"signed index", "review:" + id + ":next:+1", errCallbackIndex},
+		{"leading zero", "review:" + id + ":next:01", errCallbackIndex},
+		{"index too large", "review:" + id + ":next:10000", errCallbackIndex},
+		{"non-digit index", "review:" + id + ":next:1a", errCallbackIndex},
+		{"spaced index", "review:" + id + ":next: 1", errCallbackIndex},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseReviewCallback(tt.data)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+// FuzzParseReviewCallback checks that the parser never panics and only accepts data
+// that it would produce itself, with a valid index.
+func FuzzParseReviewCallback(f *testing.F) {
+	f.Add("review:" + goldenSuggestionCode + ":approve:0")
+	f.Add("review:" + goldenSuggestionHex + ":approve:0")
+	f.Add("review:" + goldenSuggestionHex + ":previous:42")
+	f.Add("review:" + goldenSuggestionHex + ":next:01")
+	f.Add("review:" + goldenSuggestionHex + ":reject:-1")
+	f.Add("review:::")
+	f.Add("reviewsession:resume")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		parsed, err := parseReviewCallback(data)
+		if err != nil {
+			return
+		}
+		if got := parsed.String(); got != data {
+			t.Fatalf("parsed %q but formats back as %q", data, got)
+		}
+		if parsed.Index < 0 || parsed.Index > 9999 {
+			t.Fatalf("parsed %q with out-of-range index %d", data, parsed.Index)
+		}
+	})
+}
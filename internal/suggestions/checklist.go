@@ -0,0 +1,64 @@
+package suggestions
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// rulesCallbackPrefix prefixes callback data of the pre-submission checklist button.
+	rulesCallbackPrefix = "rules:"
+	rulesReadData       = rulesCallbackPrefix + "read"
+)
+
+// SetRulesChecklist enables the pre-submission checklist: /suggest first shows a short summary
+// of the rules with an "I've read the rules" button, and only waits for content once it is pressed.
+func (m *Manager) SetRulesChecklist(enabled bool) {
+	m.rulesChecklist = enabled
+}
+
+// sendRulesChecklist sends the pre-submission checklist with the "I've read the rules" button.
+func (m *Manager) sendRulesChecklist(ctx context.Context, localizer *i18n.Localizer, chatID int64) error {
+	keyboard := tu.InlineKeyboard(tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnRulesRead", nil, nil)).WithCallbackData(rulesReadData),
+	))
+	checklist := tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgSuggestChecklist", nil, nil)).WithReplyMarkup(keyboard)
+	if _, err := m.bot.SendMessage(ctx, checklist); err != nil {
+		return fmt.Errorf("failed to send rules checklist: %w", err)
+	}
+	return nil
+}
+
+// handleRulesCallback handles the "I've read the rules" button of the checklist and starts the suggestion.
+// Consent and subscription were checked by /suggest when the checklist was sent.
+func (m *Manager) handleRulesCallback(ctx context.Context, query telego.CallbackQuery) error {
+	userID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+	_ = m.answerCallbackQuery(ctx, query.ID, "", false)
+
+	chatID := userID // The checklist is sent in the private chat with the user
+	if msg, ok := query.Message.(*telego.Message); ok && msg != nil {
+		chatID = msg.Chat.ID
+		// Replace the button with the confirmation, so it can't be pressed twice
+		_, err := m.bot.EditMessageText(ctx, &telego.EditMessageTextParams{
+			ChatID:    tu.ID(chatID),
+			MessageID: msg.MessageID,
+			Text:      msg.Text + "\n\n" + locales.GetMessage(localizer, "MsgRulesConfirmed", nil, nil),
+		})
+		if err != nil {
+			log.Printf("[Checklist User:%d] Error updating rules checklist: %v", userID, err)
+		}
+	}
+
+	if m.GetUserState(userID) == StateAwaitingSuggestion {
+		return nil
+	}
+	log.Printf("[Checklist User:%d] User confirmed the rules", userID)
+	return m.startSuggestion(ctx, localizer, userID, chatID)
+}
@@ -0,0 +1,188 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// maxCommentLength bounds a question or answer, in characters.
+	maxCommentLength = 500
+	// maxShownComments is how many of the latest comments the review message shows.
+	maxShownComments = 4
+	// maxShownCommentLength is how much of each comment the review message shows, keeping
+	// the review caption within Telegram's limit.
+	maxShownCommentLength = 150
+)
+
+// startSuggesterQuestion asks the reviewer, in their private chat, for a question to send to the
+// suggester of the suggestion under review.
+func (m *Manager) startSuggesterQuestion(ctx context.Context, query telego.CallbackQuery, session *ReviewSession, index int) error {
+	adminID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	m.reviewSessionsMutex.RLock()
+	suggestion := session.Suggestions[index]
+	m.reviewSessionsMutex.RUnlock()
+
+	m.setEditTarget(adminID, StateAskingSuggester, suggestion.ID)
+	prompt := locales.GetMessage(localizer, "MsgAskSuggesterPrompt", map[string]interface{}{
+		"User": suggesterDisplayName(&suggestion),
+	}, nil)
+	if _, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(adminID), prompt)); err != nil {
+		// The admin never started a private chat with the bot
+		m.SetUserState(adminID, StateIdle)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgFeedbackReplyStartBot", nil, nil), true)
+		return fmt.Errorf("failed to send question prompt to admin %d: %w", adminID, err)
+	}
+	return m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgFeedbackReplyPromptSent", nil, nil), false)
+}
+
+// handleSuggesterQuestionContent sends a reviewer's question to the suggester, records it on the
+// suggestion and waits for the suggester's answer. Commands cancel the question and are passed on.
+func (m *Manager) handleSuggesterQuestionContent(ctx context.Context, message *telego.Message) (processed bool, err error) {
+	adminID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := m.localizerForUser(ctx, message.From)
+
+	if strings.HasPrefix(message.Text, "/") {
+		m.SetUserState(adminID, StateIdle)
+		return false, nil
+	}
+	suggestionID, ok := m.getEditTarget(adminID)
+	if !ok {
+		m.SetUserState(adminID, StateIdle)
+		return false, nil
+	}
+	if message.Text == "" || len([]rune(message.Text)) > maxCommentLength {
+		_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgCommentRequiresText", map[string]interface{}{
+			"Max": maxCommentLength,
+		}, nil)))
+		return true, err // Let the admin try again
+	}
+
+	m.SetUserState(adminID, StateIdle)
+	suggestion, err := m.repo.GetSuggestionByID(ctx, suggestionID)
+	if err != nil || suggestion == nil || suggestion.Status != string(models.StatusPending) {
+		_, sendErr := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgCommentSuggestionReviewed", nil, nil)))
+		if err != nil {
+			return true, fmt.Errorf("failed to load suggestion %s: %w", suggestionID.Hex(), err)
+		}
+		return true, sendErr
+	}
+
+	comment := models.SuggestionComment{AuthorID: adminID, Text: message.Text, CreatedAt: time.Now()}
+	if err := m.repo.AddSuggestionComment(ctx, suggestionID, comment); err != nil {
+		_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil)))
+		return true, err
+	}
+
+	userLocalizer := m.localizerForUserID(ctx, suggestion.SuggesterID)
+	question := locales.GetMessage(userLocalizer, "MsgSuggesterQuestion", map[string]interface{}{
+		"Question": message.Text,
+		"Code":     suggestion.Ref(),
+	}, nil)
+	questionMsg := tu.Message(tu.ID(suggestion.ChatID), question).WithReplyParameters(&telego.ReplyParameters{
+		MessageID:                suggestion.MessageID,
+		AllowSendingWithoutReply: true,
+	})
+	if _, err := m.bot.SendMessage(ctx, questionMsg); err != nil {
+		log.Printf("[SuggesterQuestion Admin:%d Suggestion:%s] Error sending question to user %d: %v", adminID, suggestionID.Hex(), suggestion.SuggesterID, err)
+		_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgFeedbackReplyFailed", nil, nil)))
+		return true, err
+	}
+	// Don't interrupt something the suggester is in the middle of; their next free message answers
+	if m.GetUserState(suggestion.SuggesterID) == StateIdle {
+		m.setEditTarget(suggestion.SuggesterID, StateAnsweringReviewer, suggestionID)
+	} else {
+		log.Printf("[SuggesterQuestion Suggestion:%s] User %d is busy, not waiting for their answer", suggestionID.Hex(), suggestion.SuggesterID)
+	}
+
+	log.Printf("[SuggesterQuestion Admin:%d Suggestion:%s] Sent question to user %d", adminID, suggestionID.Hex(), suggestion.SuggesterID)
+	_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgAskSuggesterSent", nil, nil)))
+	return true, err
+}
+
+// handleReviewerAnswerContent attaches the suggester's answer to the suggestion, where reviewers see it
+// on the next review. Commands skip the answer and are passed on.
+func (m *Manager) handleReviewerAnswerContent(ctx context.Context, message *telego.Message) (processed bool, err error) {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := m.localizerForUser(ctx, message.From)
+
+	if strings.HasPrefix(message.Text, "/") {
+		m.SetUserState(userID, StateIdle)
+		return false, nil
+	}
+	suggestionID, ok := m.getEditTarget(userID)
+	if !ok {
+		m.SetUserState(userID, StateIdle)
+		return false, nil
+	}
+	if message.Text == "" || len([]rune(message.Text)) > maxCommentLength {
+		_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgCommentRequiresText", map[string]interface{}{
+			"Max": maxCommentLength,
+		}, nil)))
+		return true, err // Let the user try again
+	}
+
+	m.SetUserState(userID, StateIdle)
+	comment := models.SuggestionComment{AuthorID: userID, FromSuggester: true, Text: message.Text, CreatedAt: time.Now()}
+	err = m.repo.AddSuggestionComment(ctx, suggestionID, comment)
+	if errors.Is(err, database.ErrSuggestionNotFound) {
+		_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgCommentSuggestionReviewed", nil, nil)))
+		return true, err
+	}
+	if err != nil {
+		_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil)))
+		return true, err
+	}
+
+	log.Printf("[ReviewerAnswer User:%d Suggestion:%s] Answer attached", userID, suggestionID.Hex())
+	_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgSuggesterAnswerSaved", nil, nil)))
+	return true, err
+}
+
+// commentThread formats the latest comments of a suggestion for the review message, or returns "".
+func commentThread(localizer *i18n.Localizer, comments []models.SuggestionComment) string {
+	if len(comments) == 0 {
+		return ""
+	}
+	if len(comments) > maxShownComments {
+		comments = comments[len(comments)-maxShownComments:]
+	}
+	lines := []string{locales.GetMessage(localizer, "MsgReviewComments", nil, nil)}
+	for _, comment := range comments {
+		key := "MsgReviewCommentQuestion"
+		if comment.FromSuggester {
+			key = "MsgReviewCommentAnswer"
+		}
+		text := []rune(comment.Text)
+		if len(text) > maxShownCommentLength {
+			text = append(text[:maxShownCommentLength-1], '…')
+		}
+		lines = append(lines, locales.GetMessage(localizer, key, map[string]interface{}{
+			"Text": string(text),
+		}, nil))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// suggesterDisplayName returns how the suggester of a suggestion is shown to admins.
+func suggesterDisplayName(suggestion *models.Suggestion) string {
+	if suggestion.Username != "" {
+		return "@" + suggestion.Username
+	}
+	return suggestion.FirstName
+}
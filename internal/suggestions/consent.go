@@ -0,0 +1,119 @@
+package suggestions
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/simulation"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// consentCallbackPrefix prefixes callback data of the privacy notice button.
+	consentCallbackPrefix = "consent:"
+	consentAgreeData      = consentCallbackPrefix + "agree"
+)
+
+// HasConsent reports whether the user agreed to the privacy notice.
+// Agreements are cached, so users who agreed keep access while the database is unavailable.
+func (m *Manager) HasConsent(ctx context.Context, userID int64) (bool, error) {
+	m.consentMutex.Lock()
+	_, ok := m.consented[userID]
+	m.consentMutex.Unlock()
+	if ok {
+		return true, nil
+	}
+
+	user, err := m.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check consent of user %d: %w", userID, err)
+	}
+	if user == nil || user.ConsentedAt.IsZero() {
+		return false, nil
+	}
+	m.rememberConsent(userID)
+	return true, nil
+}
+
+// ShowPrivacyNotice sends the privacy notice with the "I agree" button to a user who hasn't agreed yet.
+// It is sent on /start, so users see what is stored before they suggest anything.
+func (m *Manager) ShowPrivacyNotice(ctx context.Context, user *telego.User, chatID int64) error {
+	consented, err := m.HasConsent(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if consented {
+		return nil
+	}
+	return m.sendPrivacyNotice(ctx, m.localizerForUser(ctx, user), chatID)
+}
+
+// requireConsent checks that the user agreed to the privacy notice before submitting content.
+// If not, it sends the notice and returns false.
+func (m *Manager) requireConsent(ctx context.Context, localizer *i18n.Localizer, userID, chatID int64) (bool, error) {
+	if simulation.Active(ctx) {
+		return true, nil // The test user of /simulate can't press the notice's button
+	}
+	consented, err := m.HasConsent(ctx, userID)
+	if err != nil {
+		errorMsg := locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil)
+		_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), errorMsg))
+		return false, err
+	}
+	if consented {
+		return true, nil
+	}
+	log.Printf("[Consent User:%d] Content submission requires consent, sending privacy notice", userID)
+	return false, m.sendPrivacyNotice(ctx, localizer, chatID)
+}
+
+// sendPrivacyNotice sends the privacy notice with the "I agree" button.
+func (m *Manager) sendPrivacyNotice(ctx context.Context, localizer *i18n.Localizer, chatID int64) error {
+	keyboard := tu.InlineKeyboard(tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnConsentAgree", nil, nil)).WithCallbackData(consentAgreeData),
+	))
+	notice := tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgPrivacyNotice", nil, nil)).WithReplyMarkup(keyboard)
+	if _, err := m.bot.SendMessage(ctx, notice); err != nil {
+		return fmt.Errorf("failed to send privacy notice: %w", err)
+	}
+	return nil
+}
+
+// handleConsentCallback handles the "I agree" button of the privacy notice.
+func (m *Manager) handleConsentCallback(ctx context.Context, query telego.CallbackQuery) error {
+	userID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	if err := m.userRepo.SetUserConsent(ctx, userID, time.Now()); err != nil {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("failed to save consent of user %d: %w", userID, err)
+	}
+	m.rememberConsent(userID)
+	log.Printf("[Consent User:%d] User agreed to the privacy notice", userID)
+
+	_ = m.answerCallbackQuery(ctx, query.ID, "", false)
+	if msg, ok := query.Message.(*telego.Message); ok && msg != nil {
+		// Replace the button with the confirmation
+		_, err := m.bot.EditMessageText(ctx, &telego.EditMessageTextParams{
+			ChatID:    tu.ID(msg.Chat.ID),
+			MessageID: msg.MessageID,
+			Text:      msg.Text + "\n\n" + locales.GetMessage(localizer, "MsgConsentAccepted", nil, nil),
+		})
+		if err != nil {
+			log.Printf("[Consent User:%d] Error updating privacy notice: %v", userID, err)
+		}
+	}
+	return nil
+}
+
+// rememberConsent caches that the user agreed to the privacy notice.
+func (m *Manager) rememberConsent(userID int64) {
+	m.consentMutex.Lock()
+	defer m.consentMutex.Unlock()
+	m.consented[userID] = struct{}{}
+}
@@ -0,0 +1,137 @@
+package suggestions
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// ContentPolicy lists the media types accepted in suggestions, in the order of models.MediaTypes.
+type ContentPolicy []string
+
+// DefaultContentPolicy accepts photos only.
+var DefaultContentPolicy = ContentPolicy{models.MediaTypePhoto}
+
+// contentTypeMessages are the message IDs of the localized names of media types.
+var contentTypeMessages = map[string]string{
+	models.MediaTypePhoto:     "ContentTypePhoto",
+	models.MediaTypeVideo:     "ContentTypeVideo",
+	models.MediaTypeAnimation: "ContentTypeAnimation",
+	models.MediaTypeDocument:  "ContentTypeDocument",
+}
+
+// ParseContentPolicy parses a list of media types separated by commas or spaces: photo, video,
+// animation (GIFs) and document. "gif" is accepted for animation.
+func ParseContentPolicy(value string) (ContentPolicy, error) {
+	var policy ContentPolicy
+	for _, item := range strings.FieldsFunc(strings.ToLower(value), func(r rune) bool { return r == ',' || r == ' ' }) {
+		if item == "gif" {
+			item = models.MediaTypeAnimation
+		}
+		if !slices.Contains(models.MediaTypes, item) {
+			return nil, fmt.Errorf("unknown content type %q: expected photo, video, animation or document", item)
+		}
+		if !slices.Contains(policy, item) {
+			policy = append(policy, item)
+		}
+	}
+	if len(policy) == 0 {
+		return nil, fmt.Errorf("no content types given")
+	}
+	slices.SortFunc(policy, func(a, b string) int {
+		return slices.Index(models.MediaTypes, a) - slices.Index(models.MediaTypes, b)
+	})
+	return policy, nil
+}
+
+// Accepts reports whether suggestions may contain media of the type.
+func (p ContentPolicy) Accepts(mediaType string) bool {
+	return mediaType != "" && slices.Contains(p, mediaType)
+}
+
+// String returns the policy as stored in the settings, e.g. "photo,video".
+func (p ContentPolicy) String() string {
+	return strings.Join(p, ",")
+}
+
+// Describe lists the accepted media types by their localized names, e.g. "photos, videos or GIFs".
+func (p ContentPolicy) Describe(localizer *i18n.Localizer) string {
+	names := make([]string, len(p))
+	for i, mediaType := range p {
+		names[i] = locales.GetMessage(localizer, contentTypeMessages[mediaType], nil, nil)
+	}
+	if len(names) == 1 {
+		return names[0]
+	}
+	or := locales.GetMessage(localizer, "ContentTypeListOr", nil, nil)
+	return strings.Join(names[:len(names)-1], ", ") + " " + or + " " + names[len(names)-1]
+}
+
+// SetContentPolicy sets the media types accepted in suggestions. It is chosen with /contenttypes
+// and defaults to SUGGESTION_CONTENT_TYPES; an empty policy accepts photos only.
+func (m *Manager) SetContentPolicy(policy ContentPolicy) {
+	m.contentPolicy = policy
+}
+
+// ContentPolicy returns the media types accepted in suggestions.
+func (m *Manager) ContentPolicy() ContentPolicy {
+	if len(m.contentPolicy) == 0 {
+		return DefaultContentPolicy
+	}
+	return m.contentPolicy
+}
+
+// suggestedMedia is the media of a message sent as a suggestion, with the properties checked
+// against the media limits and the video policy. Zero properties are unknown.
+type suggestedMedia struct {
+	FileID   string
+	Type     string // One of models.MediaTypes
+	Width    int
+	Height   int
+	FileSize int64
+	Duration time.Duration // Length of videos
+}
+
+// suggestionMedia returns the media of a message, whose type is empty if it has none. GIFs come
+// with a document too, so they are told apart first.
+func suggestionMedia(message *telego.Message) suggestedMedia {
+	switch {
+	case len(message.Photo) > 0:
+		photo := message.Photo[len(message.Photo)-1]
+		return suggestedMedia{FileID: photo.FileID, Type: models.MediaTypePhoto, Width: photo.Width, Height: photo.Height, FileSize: int64(photo.FileSize)}
+	case message.Animation != nil:
+		animation := message.Animation
+		return suggestedMedia{FileID: animation.FileID, Type: models.MediaTypeAnimation, Width: animation.Width, Height: animation.Height, FileSize: animation.FileSize}
+	case message.Video != nil:
+		video := message.Video
+		return suggestedMedia{FileID: video.FileID, Type: models.MediaTypeVideo, Width: video.Width, Height: video.Height, FileSize: video.FileSize,
+			Duration: time.Duration(video.Duration) * time.Second}
+	case message.Document != nil:
+		return suggestedMedia{FileID: message.Document.FileID, Type: models.MediaTypeDocument, FileSize: message.Document.FileSize}
+	}
+	return suggestedMedia{}
+}
+
+// storedMediaTypes returns the media types to store with a suggestion's files: nil if they are all
+// photos, so photo suggestions are stored as before media types were recorded.
+func storedMediaTypes(mediaTypes []string) []string {
+	for _, mediaType := range mediaTypes {
+		if mediaType != models.MediaTypePhoto {
+			return mediaTypes
+		}
+	}
+	return nil
+}
+
+// contentRequiredMessage tells a suggester which media the suggestion must be made of.
+func (m *Manager) contentRequiredMessage(localizer *i18n.Localizer) string {
+	return locales.GetMessage(localizer, "MsgSuggestionRequiresContent", map[string]interface{}{
+		"Types": m.ContentPolicy().Describe(localizer),
+	}, nil)
+}
@@ -0,0 +1,143 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// creditCallbackPrefix prefixes callback data of the credit question buttons ("credit:<hex>:yes|no").
+	creditCallbackPrefix = "credit:"
+	creditYes            = "yes"
+	creditNo             = "no"
+)
+
+// SetCreditSuggesters sets whether suggesters are named in the caption of their published posts
+// when they didn't answer the credit question. Their own answer always takes precedence.
+func (m *Manager) SetCreditSuggesters(enabled bool) {
+	m.creditSuggesters = enabled
+}
+
+// sendSuggestionReceived confirms a new suggestion to its suggester and asks whether they want
+// to be credited if it is published.
+func (m *Manager) sendSuggestionReceived(ctx context.Context, localizer *i18n.Localizer, chatID int64, suggestion *models.Suggestion) error {
+	text := suggestionReceivedText(localizer, suggestion)
+	ref := suggestion.Ref()
+	keyboard := tu.InlineKeyboard(tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnCreditYes", nil, nil)).WithCallbackData(creditCallbackPrefix+ref+":"+creditYes),
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnCreditNo", nil, nil)).WithCallbackData(creditCallbackPrefix+ref+":"+creditNo),
+	))
+	_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), text).WithReplyMarkup(keyboard))
+	return err
+}
+
+// suggestionReceivedText is the confirmation of a new suggestion, with its reference code and the
+// credit question.
+func suggestionReceivedText(localizer *i18n.Localizer, suggestion *models.Suggestion) string {
+	text := locales.GetMessage(localizer, "MsgSuggestionReceivedConfirmation", nil, nil)
+	if refCode := refCodeText(localizer, suggestion); refCode != "" {
+		text += "\n" + refCode
+	}
+	return text + "\n\n" + locales.GetMessage(localizer, "MsgSuggestCreditQuestion", nil, nil)
+}
+
+// handleCreditCallback stores the suggester's answer to the credit question. The answer can be
+// changed until the suggestion is reviewed.
+func (m *Manager) handleCreditCallback(ctx context.Context, query telego.CallbackQuery) error {
+	userID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	parts := strings.Split(strings.TrimPrefix(query.Data, creditCallbackPrefix), ":")
+	if len(parts) != 2 || (parts[1] != creditYes && parts[1] != creditNo) || !isSuggestionRef(parts[0]) {
+		log.Printf("[Credit User:%d] Rejected callback data %q", userID, query.Data)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("malformed credit callback data %q", query.Data)
+	}
+	credit := parts[1] == creditYes
+
+	suggestion, err := m.suggestionByRef(ctx, parts[0])
+	if errors.Is(err, database.ErrSuggestionNotFound) {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgSuggestCreditTooLate", nil, nil), true)
+		return nil
+	}
+	if err != nil {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("failed to load suggestion %s: %w", parts[0], err)
+	}
+	suggestionID := suggestion.ID
+
+	if err := m.repo.SetSuggestionCredit(ctx, suggestionID, userID, credit); err != nil {
+		if errors.Is(err, database.ErrSuggestionNotEditable) {
+			_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgSuggestCreditTooLate", nil, nil), true)
+			return nil
+		}
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("failed to save credit choice of suggestion %s: %w", suggestionID.Hex(), err)
+	}
+	log.Printf("[Credit User:%d] Suggestion %s credited: %t", userID, suggestionID.Hex(), credit)
+
+	answerKey := "MsgSuggestCreditAnonymous"
+	if credit {
+		answerKey = "MsgSuggestCreditNamed"
+	}
+	answer := locales.GetMessage(localizer, answerKey, nil, nil)
+	_ = m.answerCallbackQuery(ctx, query.ID, answer, false)
+
+	// Keep the buttons, so the answer can be changed, and show the current one under the question
+	if msg, ok := query.Message.(*telego.Message); ok && msg != nil {
+		text := suggestionReceivedText(localizer, suggestion) + "\n" + answer
+		if text != msg.Text {
+			_, err := m.bot.EditMessageText(ctx, &telego.EditMessageTextParams{
+				ChatID:      tu.ID(msg.Chat.ID),
+				MessageID:   msg.MessageID,
+				Text:        text,
+				ReplyMarkup: msg.ReplyMarkup,
+			})
+			if err != nil {
+				log.Printf("[Credit User:%d] Error updating credit question: %v", userID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// suggesterCredit returns the caption line naming the suggester, or "" if they aren't credited.
+// With merged suggesters credited, the co-suggesters who want to be are named too.
+// The channel audience is shared, so the default language is used.
+func (m *Manager) suggesterCredit(suggestion *models.Suggestion) string {
+	var names []string
+	if name := suggesterDisplayName(suggestion); name != "" && m.credited(suggestion.Credit) {
+		names = append(names, name)
+	}
+	if m.creditCoSuggesters {
+		for _, co := range suggestion.CoSuggesters {
+			if name := coSuggesterDisplayName(co); name != "" && m.credited(co.Credit) {
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return locales.GetMessage(locales.NewLocalizer(), "MsgSuggesterCredit", map[string]interface{}{
+		"Name": strings.Join(names, ", "),
+	}, nil)
+}
+
+// credited reports whether a suggester with the given answer to the credit question is named.
+func (m *Manager) credited(choice *bool) bool {
+	if choice != nil {
+		return *choice
+	}
+	return m.creditSuggesters
+}
@@ -0,0 +1,157 @@
+package suggestions
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const (
+	// directSuggestionCallbackPrefix prefixes callback data of the "Submit this as a suggestion?" prompt.
+	directSuggestionCallbackPrefix = "directsug:"
+	directSuggestionYesData        = directSuggestionCallbackPrefix + "yes"
+	directSuggestionNoData         = directSuggestionCallbackPrefix + "no"
+	// directOfferTTL is how long a user can confirm a direct suggestion prompt.
+	directOfferTTL = 10 * time.Minute
+)
+
+// directOffer is media sent by a user outside of /suggest, waiting for confirmation.
+type directOffer struct {
+	messages  []telego.Message
+	createdAt time.Time
+}
+
+// SetAcceptDirectSuggestions enables or disables offering media sent outside of /suggest as suggestions.
+// It is disabled by default.
+func (m *Manager) SetAcceptDirectSuggestions(enabled bool) {
+	m.directOffersMutex.Lock()
+	defer m.directOffersMutex.Unlock()
+	m.acceptDirectSuggestions = enabled
+}
+
+// OfferDirectSuggestion asks a non-admin user who sent media without /suggest whether to submit it
+// as a suggestion. messages is a single photo message or all messages of a photo album.
+// It returns false if direct suggestions are disabled or the media can't be suggested,
+// so the caller can fall back to its usual response.
+func (m *Manager) OfferDirectSuggestion(ctx context.Context, messages []telego.Message) (bool, error) {
+	if len(messages) == 0 || messages[0].From == nil {
+		return false, nil
+	}
+	for _, msg := range messages {
+		if len(msg.Photo) == 0 {
+			return false, nil // The suggestion pipeline accepts photos only
+		}
+	}
+
+	m.directOffersMutex.Lock()
+	accept := m.acceptDirectSuggestions
+	m.directOffersMutex.Unlock()
+	if !accept {
+		return false, nil
+	}
+
+	// Users who haven't agreed to the privacy notice get it instead; they can send the media again afterwards
+	first := messages[0]
+	localizer := m.localizerForUser(ctx, first.From)
+	if consented, err := m.requireConsent(ctx, localizer, first.From.ID, first.Chat.ID); !consented {
+		return true, err
+	}
+
+	m.directOffersMutex.Lock()
+	m.purgeExpiredDirectOffers()
+	m.directOffers[first.From.ID] = &directOffer{messages: messages, createdAt: time.Now()}
+	m.directOffersMutex.Unlock()
+
+	keyboard := tu.InlineKeyboard(tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnYes", nil, nil)).WithCallbackData(directSuggestionYesData),
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnNo", nil, nil)).WithCallbackData(directSuggestionNoData),
+	))
+	prompt := tu.Message(tu.ID(first.Chat.ID), locales.GetMessage(localizer, "MsgDirectSuggestionPrompt", nil, nil)).
+		WithReplyMarkup(keyboard).
+		WithReplyParameters(&telego.ReplyParameters{MessageID: first.MessageID, AllowSendingWithoutReply: true})
+	if _, err := m.bot.SendMessage(ctx, prompt); err != nil {
+		return true, fmt.Errorf("failed to send direct suggestion prompt: %w", err)
+	}
+	log.Printf("[DirectSuggestion User:%d] Offered %d message(s) as a suggestion", first.From.ID, len(messages))
+	return true, nil
+}
+
+// takeDirectOffer removes and returns the user's pending offer, if it hasn't expired.
+func (m *Manager) takeDirectOffer(userID int64) (*directOffer, bool) {
+	m.directOffersMutex.Lock()
+	defer m.directOffersMutex.Unlock()
+	offer, ok := m.directOffers[userID]
+	delete(m.directOffers, userID)
+	if !ok || time.Since(offer.createdAt) > directOfferTTL {
+		return nil, false
+	}
+	return offer, true
+}
+
+// purgeExpiredDirectOffers drops offers that can no longer be confirmed, so prompts users never
+// answered don't keep their messages in memory. The caller must hold directOffersMutex.
+func (m *Manager) purgeExpiredDirectOffers() {
+	for userID, offer := range m.directOffers {
+		if time.Since(offer.createdAt) > directOfferTTL {
+			delete(m.directOffers, userID)
+		}
+	}
+}
+
+// handleDirectSuggestionCallback handles the Yes/No buttons of the direct suggestion prompt.
+// On Yes, the media goes through the same checks and pipeline as content sent after /suggest.
+func (m *Manager) handleDirectSuggestionCallback(ctx context.Context, query telego.CallbackQuery) error {
+	userID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	chatID := userID
+	if msg, ok := query.Message.(*telego.Message); ok && msg != nil {
+		chatID = msg.Chat.ID
+		go m.deleteReviewMessages(context.Background(), chatID, nil, msg.MessageID) // Remove the prompt
+	}
+
+	offer, ok := m.takeDirectOffer(userID)
+	if !ok {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgDirectSuggestionExpired", nil, nil), true)
+		return nil
+	}
+	if strings.TrimPrefix(query.Data, directSuggestionCallbackPrefix) != "yes" {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgDirectSuggestionDeclined", nil, nil), false)
+		return nil
+	}
+	_ = m.answerCallbackQuery(ctx, query.ID, "", false)
+
+	if m.GetUserState(userID) != StateIdle {
+		msg := locales.GetMessage(localizer, "MsgSuggestAlreadyWaitingForContent", nil, nil)
+		_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), msg))
+		return err
+	}
+
+	isSubscribed, err := m.CheckSubscription(ctx, userID)
+	if err != nil {
+		msg := locales.GetMessage(localizer, "MsgSuggestErrorCheckingSubscription", nil, nil)
+		_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), msg))
+		return fmt.Errorf("failed to check subscription for direct suggestion of user %d: %w", userID, err)
+	}
+	if !isSubscribed {
+		msg := locales.GetMessage(localizer, "MsgSuggestRequiresSubscription", nil, nil)
+		_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), msg))
+		return err
+	}
+
+	// Feed the same pipeline as content sent after /suggest; Yes already confirmed it, so it isn't previewed
+	ctx = withConfirmedSubmission(ctx)
+	m.SetUserState(userID, StateAwaitingSuggestion)
+	first := offer.messages[0]
+	if first.MediaGroupID != "" {
+		return m.processSuggestionMediaGroup(ctx, first.MediaGroupID, offer.messages)
+	}
+	_, err = m.handleSuggestionContent(ctx, &first)
+	return err
+}
@@ -0,0 +1,237 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/format"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/pkg/utils"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// maxListedSuggestions is how many pending suggestions /mysuggestions shows.
+	maxListedSuggestions = 10
+	// mySuggestionsCallbackPrefix prefixes callback data of /mysuggestions buttons ("mysug:<hex>:<action>").
+	mySuggestionsCallbackPrefix = "mysug:"
+	// maxListedCaptionLength is how much of a caption is shown in the /mysuggestions list.
+	maxListedCaptionLength = 60
+)
+
+// isSuggestionClaimed reports whether a suggestion is currently held by an admin's review session.
+func (m *Manager) isSuggestionClaimed(id primitive.ObjectID) bool {
+	m.reviewSessionsMutex.RLock()
+	defer m.reviewSessionsMutex.RUnlock()
+	_, claimed := m.claimedSuggestions[id]
+	return claimed
+}
+
+// setEditTarget puts the user into an editing state for the given suggestion.
+func (m *Manager) setEditTarget(userID int64, state UserState, suggestionID primitive.ObjectID) {
+	m.muUserStates.Lock()
+	defer m.muUserStates.Unlock()
+	m.storeUserState(userID, userStateEntry{State: state, EditTarget: suggestionID})
+}
+
+// getEditTarget returns the suggestion the user is currently editing.
+func (m *Manager) getEditTarget(userID int64) (primitive.ObjectID, bool) {
+	m.muUserStates.RLock()
+	defer m.muUserStates.RUnlock()
+	id := m.loadUserState(userID).EditTarget
+	return id, !id.IsZero()
+}
+
+// HandleMySuggestionsCommand handles the /mysuggestions command.
+// It lists the user's pending suggestions with buttons to replace the caption or attach another photo.
+// Suggestions already claimed by a review session are listed without buttons.
+func (m *Manager) HandleMySuggestionsCommand(ctx context.Context, update telego.Update) error {
+	if update.Message == nil || update.Message.From == nil {
+		return fmt.Errorf("invalid update received for mysuggestions command")
+	}
+	chatID := update.Message.Chat.ID
+	userID := update.Message.From.ID
+	localizer := m.localizerForUser(ctx, update.Message.From)
+
+	pending, err := m.repo.GetSuggestionsBySuggester(ctx, userID, string(models.StatusPending), maxListedSuggestions)
+	if err != nil {
+		errorMsg := locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil)
+		_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), errorMsg))
+		return fmt.Errorf("failed to list suggestions of user %d: %w", userID, err)
+	}
+
+	if len(pending) == 0 {
+		msg := locales.GetMessage(localizer, "MsgMySuggestionsEmpty", nil, nil)
+		_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), msg))
+		return err
+	}
+
+	var text strings.Builder
+	text.WriteString(locales.GetMessage(localizer, "MsgMySuggestionsHeader", nil, nil))
+	rows := make([][]telego.InlineKeyboardButton, 0, len(pending))
+	for i, suggestion := range pending {
+		index := i + 1
+		caption := suggestion.Caption
+		if len([]rune(caption)) > maxListedCaptionLength {
+			caption = string([]rune(caption)[:maxListedCaptionLength]) + "…"
+		}
+		text.WriteString("\n\n")
+		text.WriteString(locales.GetMessage(localizer, "MsgMySuggestionsItem", map[string]interface{}{
+			"Index":   index,
+			"Files":   len(suggestion.FileIDs),
+			"Date":    utils.FormatTime(suggestion.SubmittedAt, m.location),
+			"Ago":     format.RelativeTime(localizer, suggestion.SubmittedAt, time.Now()),
+			"Caption": caption,
+			"Code":    suggestion.Ref(),
+		}, nil))
+
+		if m.isSuggestionClaimed(suggestion.ID) {
+			text.WriteString(locales.GetMessage(localizer, "MsgMySuggestionsInReviewSuffix", nil, nil))
+			continue
+		}
+		data := map[string]interface{}{"Index": index}
+		row := []telego.InlineKeyboardButton{
+			tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnEditCaption", data, nil)).
+				WithCallbackData(mySuggestionsCallbackPrefix + suggestion.Ref() + ":caption"),
+		}
+		if len(suggestion.FileIDs) < m.mediaGroupMgr.MaxGroupSize() {
+			row = append(row, tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnAddPhoto", data, nil)).
+				WithCallbackData(mySuggestionsCallbackPrefix+suggestion.Ref()+":photo"))
+		}
+		rows = append(rows, row)
+	}
+
+	msg := tu.Message(tu.ID(chatID), text.String())
+	if len(rows) > 0 {
+		msg = msg.WithReplyMarkup(tu.InlineKeyboard(rows...))
+	}
+	_, err = m.bot.SendMessage(ctx, msg)
+	return err
+}
+
+// handleMySuggestionsCallback handles the Edit buttons of /mysuggestions ("mysug:<ref>:caption|photo").
+func (m *Manager) handleMySuggestionsCallback(ctx context.Context, query telego.CallbackQuery) error {
+	userID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	parts := strings.Split(strings.TrimPrefix(query.Data, mySuggestionsCallbackPrefix), ":")
+	if len(parts) != 2 || !isSuggestionRef(parts[0]) {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("invalid mysuggestions callback data: %s", query.Data)
+	}
+
+	suggestion, err := m.suggestionByRef(ctx, parts[0])
+	if err != nil && !errors.Is(err, database.ErrSuggestionNotFound) {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("failed to load suggestion %s for editing: %w", parts[0], err)
+	}
+	if suggestion == nil || suggestion.SuggesterID != userID || suggestion.Status != string(models.StatusPending) || m.isSuggestionClaimed(suggestion.ID) {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgSuggestionEditNotAllowed", nil, nil), true)
+		return nil
+	}
+
+	var state UserState
+	var promptKey string
+	switch parts[1] {
+	case "caption":
+		state, promptKey = StateEditingCaption, "MsgSuggestionEditCaptionPrompt"
+	case "photo":
+		if len(suggestion.FileIDs) >= m.mediaGroupMgr.MaxGroupSize() {
+			_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgSuggestionEditFull", map[string]interface{}{"Max": m.mediaGroupMgr.MaxGroupSize()}, nil), true)
+			return nil
+		}
+		state, promptKey = StateAddingPhoto, "MsgSuggestionEditPhotoPrompt"
+	default:
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("unknown mysuggestions action: %s", parts[1])
+	}
+
+	m.setEditTarget(userID, state, suggestion.ID)
+	_ = m.answerCallbackQuery(ctx, query.ID, "", false)
+
+	chatID := userID
+	if msg, ok := query.Message.(*telego.Message); ok && msg != nil {
+		chatID = msg.Chat.ID
+	}
+	_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, promptKey, nil, nil)))
+	return err
+}
+
+// handleEditContent handles a message while the user is editing one of their pending suggestions.
+// Commands cancel the edit and are passed on (processed=false) so they run normally.
+func (m *Manager) handleEditContent(ctx context.Context, message *telego.Message, state UserState) (processed bool, err error) {
+	userID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := m.localizerForUser(ctx, message.From)
+
+	if strings.HasPrefix(message.Text, "/") {
+		m.SetUserState(userID, StateIdle)
+		return false, nil
+	}
+
+	suggestionID, ok := m.getEditTarget(userID)
+	if !ok {
+		m.SetUserState(userID, StateIdle)
+		return false, nil
+	}
+
+	// The suggestion may have been claimed for review since the edit started
+	if m.isSuggestionClaimed(suggestionID) {
+		m.SetUserState(userID, StateIdle)
+		_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgSuggestionEditNotAllowed", nil, nil)))
+		return true, err
+	}
+
+	var confirmKey, caption string
+	switch state {
+	case StateEditingCaption:
+		if message.Text == "" {
+			_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgSuggestionEditRequiresText", nil, nil)))
+			return true, err // Let the user try again
+		}
+		var allowed bool
+		caption, allowed = m.screenEditedCaption(ctx, userID, message.Text)
+		if !allowed {
+			_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgWordFilterEditBlocked", nil, nil)))
+			return true, err // Let the user try again
+		}
+		err = m.repo.UpdateSuggestionCaption(ctx, suggestionID, userID, caption)
+		confirmKey = "MsgSuggestionCaptionUpdated"
+	case StateAddingPhoto:
+		if len(message.Photo) == 0 || message.MediaGroupID != "" {
+			_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgSuggestionEditSinglePhoto", nil, nil)))
+			return true, err // Let the user try again
+		}
+		fileID := message.Photo[len(message.Photo)-1].FileID
+		err = m.repo.AddSuggestionFile(ctx, suggestionID, userID, fileID, m.mediaGroupMgr.MaxGroupSize())
+		confirmKey = "MsgSuggestionPhotoAdded"
+	default:
+		return false, nil
+	}
+
+	m.SetUserState(userID, StateIdle)
+	if errors.Is(err, database.ErrSuggestionNotEditable) {
+		_, sendErr := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgSuggestionEditNotAllowed", nil, nil)))
+		return true, sendErr
+	}
+	if err != nil {
+		log.Printf("[EditSuggestion User:%d Sug:%s] Error saving edit: %v", userID, suggestionID.Hex(), err)
+		_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil)))
+		return true, err
+	}
+
+	log.Printf("[EditSuggestion User:%d Sug:%s] Saved edit (%s)", userID, suggestionID.Hex(), state)
+	if state == StateEditingCaption {
+		m.translateSuggestionCaption(suggestionID, caption)
+	}
+	_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, confirmKey, nil, nil)))
+	return true, err
+}
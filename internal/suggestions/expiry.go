@@ -0,0 +1,94 @@
+package suggestions
+
+import (
+	"context"
+	"log"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/format"
+	"vrcmemes-bot/internal/locales"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// expiryCheckInterval is how often pending suggestions are checked for expiry.
+	expiryCheckInterval = time.Hour
+	// expiryBatch bounds how many suggestions are expired per update.
+	expiryBatch = 100
+)
+
+// SetSuggestionExpiry enables rejecting pending suggestions older than after as expired, so the
+// queue only holds memes still worth reviewing. With notify, suggesters are told gently that
+// their suggestion expired. 0 never expires suggestions.
+func (m *Manager) SetSuggestionExpiry(after time.Duration, notify bool) {
+	m.expireAfter = after
+	m.notifyExpired = notify
+}
+
+// StartExpiryJanitor periodically expires pending suggestions, until ctx is done. It does
+// nothing unless suggestion expiry is enabled.
+func (m *Manager) StartExpiryJanitor(ctx context.Context) {
+	if m.expireAfter <= 0 {
+		return
+	}
+	go func() {
+		m.expireStale(ctx, time.Now())
+		ticker := time.NewTicker(expiryCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.expireStale(ctx, time.Now())
+			}
+		}
+	}()
+}
+
+// expireStale rejects the suggestions submitted before now minus the expiry age, in batches.
+// Suggestions in a review session are left alone: their reviewer is about to decide on them.
+func (m *Manager) expireStale(ctx context.Context, now time.Time) {
+	m.reviewSessionsMutex.RLock()
+	claimed := make([]primitive.ObjectID, 0, len(m.claimedSuggestions))
+	for id := range m.claimedSuggestions {
+		claimed = append(claimed, id)
+	}
+	m.reviewSessionsMutex.RUnlock()
+
+	total := 0
+	for ctx.Err() == nil {
+		expired, err := m.repo.ExpireSuggestions(ctx, now.Add(-m.expireAfter), claimed, expiryBatch)
+		if err != nil {
+			log.Printf("[Expiry] Error expiring suggestions: %v", err)
+			break
+		}
+		for i := range expired {
+			m.notifyExpiredSuggestion(ctx, &expired[i])
+		}
+		total += len(expired)
+		if len(expired) < expiryBatch {
+			break
+		}
+	}
+	if total > 0 {
+		log.Printf("[Expiry] Expired %d suggestions pending for over %s", total, m.expireAfter)
+	}
+}
+
+// notifyExpiredSuggestion tells the suggester their suggestion expired, if enabled. Errors are
+// logged: the suggestion is expired either way.
+func (m *Manager) notifyExpiredSuggestion(ctx context.Context, suggestion *models.Suggestion) {
+	if !m.notifyExpired {
+		return
+	}
+	localizer := m.localizerForUserID(ctx, suggestion.SuggesterID)
+	text := locales.GetMessage(localizer, "MsgSuggestionExpired", map[string]interface{}{
+		"Code":     suggestion.Ref(),
+		"Duration": format.Duration(localizer, m.expireAfter),
+	}, nil)
+	if err := m.sendNotification(ctx, models.NotificationKindExpiry, suggestion.SuggesterID, text); err != nil {
+		log.Printf("[Expiry User:%d] Error telling the suggester suggestion %s expired: %v", suggestion.SuggesterID, suggestion.ID.Hex(), err)
+	}
+}
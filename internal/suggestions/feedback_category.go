@@ -0,0 +1,129 @@
+package suggestions
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// feedbackCategoryCallbackPrefix prefixes callback data of the category buttons shown by /feedback ("fbcat:<category>").
+const feedbackCategoryCallbackPrefix = "fbcat:"
+
+// Message IDs of the feedback category and status labels.
+var (
+	feedbackCategoryKeys = map[string]string{
+		models.FeedbackCategoryBug:       "BtnFeedbackCategoryBug",
+		models.FeedbackCategoryIdea:      "BtnFeedbackCategoryIdea",
+		models.FeedbackCategoryComplaint: "BtnFeedbackCategoryComplaint",
+		models.FeedbackCategoryOther:     "BtnFeedbackCategoryOther",
+	}
+	feedbackStatusKeys = map[string]string{
+		models.FeedbackStatusNew:        "MsgFeedbackStatusNew",
+		models.FeedbackStatusInProgress: "MsgFeedbackStatusInProgress",
+		models.FeedbackStatusResolved:   "MsgFeedbackStatusResolved",
+	}
+	feedbackStatusButtonKeys = map[string]string{
+		models.FeedbackStatusNew:        "BtnFeedbackStatusNew",
+		models.FeedbackStatusInProgress: "BtnFeedbackStatusInProgress",
+		models.FeedbackStatusResolved:   "BtnFeedbackStatusResolved",
+	}
+)
+
+// FeedbackCategoryLabel returns the localized name of a feedback category.
+// Feedback stored before categories existed is shown as "other".
+func FeedbackCategoryLabel(localizer *i18n.Localizer, category string) string {
+	key, ok := feedbackCategoryKeys[category]
+	if !ok {
+		key = feedbackCategoryKeys[models.FeedbackCategoryOther]
+	}
+	return locales.GetMessage(localizer, key, nil, nil)
+}
+
+// FeedbackStatusLabel returns the localized name of a feedback triage status.
+func FeedbackStatusLabel(localizer *i18n.Localizer, status string) string {
+	key, ok := feedbackStatusKeys[status]
+	if !ok {
+		return status
+	}
+	return locales.GetMessage(localizer, key, nil, nil)
+}
+
+// setFeedbackCategory remembers the category chosen for the user's next feedback and waits for its content.
+func (m *Manager) setFeedbackCategory(userID int64, category string) {
+	m.muUserStates.Lock()
+	defer m.muUserStates.Unlock()
+	m.storeUserState(userID, userStateEntry{State: StateAwaitingFeedback, FeedbackCategory: category})
+}
+
+// getFeedbackCategory returns the category chosen for the user's feedback, or "other" if none was.
+func (m *Manager) getFeedbackCategory(userID int64) string {
+	m.muUserStates.RLock()
+	defer m.muUserStates.RUnlock()
+	if category := m.loadUserState(userID).FeedbackCategory; category != "" {
+		return category
+	}
+	return models.FeedbackCategoryOther
+}
+
+// sendFeedbackCategoryPicker asks the user what kind of feedback they want to send.
+func (m *Manager) sendFeedbackCategoryPicker(ctx context.Context, localizer *i18n.Localizer, chatID int64) error {
+	buttons := make([][]telego.InlineKeyboardButton, 0, len(models.FeedbackCategories))
+	for _, category := range models.FeedbackCategories {
+		buttons = append(buttons, tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton(FeedbackCategoryLabel(localizer, category)).
+				WithCallbackData(feedbackCategoryCallbackPrefix+category),
+		))
+	}
+	msg := tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgFeedbackChooseCategory", nil, nil)).
+		WithReplyMarkup(tu.InlineKeyboard(buttons...))
+	_, err := m.bot.SendMessage(ctx, msg)
+	return err
+}
+
+// handleFeedbackCategoryCallback handles a category button of /feedback and asks for the feedback itself.
+func (m *Manager) handleFeedbackCategoryCallback(ctx context.Context, query telego.CallbackQuery) error {
+	userID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	category := strings.TrimPrefix(query.Data, feedbackCategoryCallbackPrefix)
+	if _, ok := feedbackCategoryKeys[category]; !ok {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("unknown feedback category: %s", category)
+	}
+
+	// The picker is only valid until the feedback is sent or another command is used
+	state := m.GetUserState(userID)
+	if state != StateChoosingFeedbackCategory && state != StateAwaitingFeedback {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgFeedbackCategoryExpired", nil, nil), true)
+		return nil
+	}
+
+	m.setFeedbackCategory(userID, category)
+	_ = m.answerCallbackQuery(ctx, query.ID, "", false)
+	log.Printf("[FeedbackCategory User:%d] Chose category %s", userID, category)
+
+	prompt := locales.GetMessage(localizer, "MsgFeedbackPrompt", map[string]interface{}{
+		"Category": FeedbackCategoryLabel(localizer, category),
+	}, nil)
+	if picker, ok := query.Message.(*telego.Message); ok && picker != nil {
+		// Replace the picker with the prompt so the category can't be chosen twice
+		_, err := m.bot.EditMessageText(ctx, &telego.EditMessageTextParams{
+			ChatID:    tu.ID(picker.Chat.ID),
+			MessageID: picker.MessageID,
+			Text:      prompt,
+		})
+		if err == nil {
+			return nil
+		}
+		log.Printf("[FeedbackCategory User:%d] Error replacing category picker: %v", userID, err)
+	}
+	_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(userID), prompt))
+	return err
+}
@@ -0,0 +1,90 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// feedbackContactCallbackPrefix prefixes callback data of the buttons asking the author of
+// feedback whether they want a reply ("fbcontact:<hex>:yes|no").
+const feedbackContactCallbackPrefix = "fbcontact:"
+
+// sendFeedbackContactQuestion thanks the user for their feedback and asks whether they want a
+// reply, telling them admins may then contact them. Admins can only reply if they agree.
+func (m *Manager) sendFeedbackContactQuestion(ctx context.Context, localizer *i18n.Localizer, chatID int64, feedback *models.Feedback) error {
+	if feedback.ID.IsZero() {
+		// Without an ID the answer couldn't be stored
+		_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgFeedbackReceivedConfirmation", nil, nil)))
+		return err
+	}
+	data := feedbackContactCallbackPrefix + feedback.ID.Hex() + ":"
+	keyboard := tu.InlineKeyboard(tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnFeedbackContactYes", nil, nil)).WithCallbackData(data+"yes"),
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnFeedbackContactNo", nil, nil)).WithCallbackData(data+"no"),
+	))
+	question := locales.GetMessage(localizer, "MsgFeedbackContactQuestion", nil, nil)
+	_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), question).WithReplyMarkup(keyboard))
+	return err
+}
+
+// handleFeedbackContactCallback stores the answer of the feedback's author to whether they want a
+// reply and replaces the question with it.
+func (m *Manager) handleFeedbackContactCallback(ctx context.Context, query telego.CallbackQuery) error {
+	userID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	parts := strings.Split(strings.TrimPrefix(query.Data, feedbackContactCallbackPrefix), ":")
+	if len(parts) != 2 || (parts[1] != "yes" && parts[1] != "no") {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("invalid feedback contact callback data: %s", query.Data)
+	}
+	feedbackID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("invalid feedback ID in callback data: %w", err)
+	}
+
+	optIn := parts[1] == "yes"
+	err = m.feedbackRepo.SetFeedbackContactOptIn(ctx, feedbackID, userID, optIn)
+	if errors.Is(err, database.ErrFeedbackNotFound) {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgFeedbackNotFound", nil, nil), true)
+		return nil
+	}
+	if err != nil {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return err
+	}
+	log.Printf("[FeedbackContact User:%d Feedback:%s] Contact opt-in: %t", userID, feedbackID.Hex(), optIn)
+	_ = m.answerCallbackQuery(ctx, query.ID, "", false)
+
+	answerKey := "MsgFeedbackContactDeclined"
+	if optIn {
+		answerKey = "MsgFeedbackContactAllowed"
+	}
+	answer := locales.GetMessage(localizer, answerKey, nil, nil)
+	if question, ok := query.Message.(*telego.Message); ok && question != nil {
+		// Replace the question so it can't be answered twice
+		_, err = m.bot.EditMessageText(ctx, &telego.EditMessageTextParams{
+			ChatID:    tu.ID(question.Chat.ID),
+			MessageID: question.MessageID,
+			Text:      answer,
+		})
+		if err == nil {
+			return nil
+		}
+		log.Printf("[FeedbackContact User:%d] Error replacing contact question: %v", userID, err)
+	}
+	_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(userID), answer))
+	return err
+}
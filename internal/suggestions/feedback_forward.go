@@ -0,0 +1,287 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// feedbackCallbackPrefix prefixes callback data of the buttons on forwarded feedback ("feedback:<hex>:reply|<status>").
+const feedbackCallbackPrefix = "feedback:"
+
+// SetFeedbackChat sets the chat, and optionally the forum topic in it, where new feedback is
+// forwarded for admins. A zero chat ID disables forwarding; feedback is then only stored.
+func (m *Manager) SetFeedbackChat(chatID int64, topicID int) {
+	m.feedbackChatID = chatID
+	m.feedbackTopicID = topicID
+}
+
+// forwardFeedback sends saved feedback to the feedback chat: its media first, then a card
+// with the sender, category, triage status, text, and Reply/status buttons.
+// Errors are only logged, since the feedback is already stored.
+func (m *Manager) forwardFeedback(ctx context.Context, feedback *models.Feedback) {
+	if m.feedbackChatID == 0 {
+		return
+	}
+	chatID := tu.ID(m.feedbackChatID)
+
+	if err := m.forwardFeedbackMedia(ctx, chatID, feedback); err != nil {
+		log.Printf("[ForwardFeedback Feedback:%s] Error forwarding media to chat %d: %v", feedback.ID.Hex(), m.feedbackChatID, err)
+	}
+
+	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+	text, keyboard := feedbackCard(localizer, feedback, "")
+	msg := tu.Message(chatID, text).WithReplyMarkup(keyboard)
+	if m.feedbackTopicID != 0 {
+		msg = msg.WithMessageThreadID(m.feedbackTopicID)
+	}
+	if _, err := m.bot.SendMessage(ctx, msg); err != nil {
+		log.Printf("[ForwardFeedback Feedback:%s] Error sending feedback card to chat %d: %v", feedback.ID.Hex(), m.feedbackChatID, err)
+	}
+}
+
+// feedbackCard builds the text and buttons of a feedback card in the feedback chat.
+// changedBy names the admin who last changed the status, or is empty.
+func feedbackCard(localizer *i18n.Localizer, feedback *models.Feedback, changedBy string) (string, *telego.InlineKeyboardMarkup) {
+	status := FeedbackStatusLabel(localizer, feedback.CurrentStatus())
+	if changedBy != "" {
+		status = locales.GetMessage(localizer, "MsgFeedbackStatusChangedBy", map[string]interface{}{
+			"Status": status,
+			"Admin":  changedBy,
+		}, nil)
+	}
+	text := locales.GetMessage(localizer, "MsgFeedbackForwardCard", map[string]interface{}{
+		"User":     feedbackSenderName(feedback),
+		"UserID":   feedback.UserID,
+		"Category": FeedbackCategoryLabel(localizer, feedback.Category),
+		"Status":   status,
+		"Text":     feedback.Text,
+	}, nil)
+
+	idHex := feedback.ID.Hex()
+	statusRow := make([]telego.InlineKeyboardButton, 0, len(models.FeedbackStatuses)-1)
+	for _, s := range models.FeedbackStatuses {
+		if s == feedback.CurrentStatus() {
+			continue
+		}
+		statusRow = append(statusRow, tu.InlineKeyboardButton(locales.GetMessage(localizer, feedbackStatusButtonKeys[s], nil, nil)).
+			WithCallbackData(feedbackCallbackPrefix+idHex+":"+s))
+	}
+	keyboard := tu.InlineKeyboard(
+		tu.InlineKeyboardRow(tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnFeedbackReply", nil, nil)).
+			WithCallbackData(feedbackCallbackPrefix+idHex+":reply")),
+		statusRow,
+	)
+	return text, keyboard
+}
+
+// forwardFeedbackMedia sends the photos and videos attached to feedback, if any.
+func (m *Manager) forwardFeedbackMedia(ctx context.Context, chatID telego.ChatID, feedback *models.Feedback) error {
+	media := make([]telego.InputMedia, 0, len(feedback.PhotoIDs)+len(feedback.VideoIDs))
+	for _, id := range feedback.PhotoIDs {
+		media = append(media, tu.MediaPhoto(tu.FileFromID(id)))
+	}
+	for _, id := range feedback.VideoIDs {
+		media = append(media, tu.MediaVideo(tu.FileFromID(id)))
+	}
+
+	switch {
+	case len(media) == 0:
+		return nil
+	case len(feedback.PhotoIDs) == 1 && len(media) == 1:
+		params := tu.Photo(chatID, tu.FileFromID(feedback.PhotoIDs[0]))
+		params.MessageThreadID = m.feedbackTopicID
+		_, err := m.bot.SendPhoto(ctx, params)
+		return err
+	case len(feedback.VideoIDs) == 1 && len(media) == 1:
+		params := tu.Video(chatID, tu.FileFromID(feedback.VideoIDs[0]))
+		params.MessageThreadID = m.feedbackTopicID
+		_, err := m.bot.SendVideo(ctx, params)
+		return err
+	default:
+		params := tu.MediaGroup(chatID, media...)
+		params.MessageThreadID = m.feedbackTopicID
+		_, err := m.bot.SendMediaGroup(ctx, params)
+		return err
+	}
+}
+
+// feedbackSenderName returns how the sender of feedback is shown to admins.
+func feedbackSenderName(feedback *models.Feedback) string {
+	if feedback.Username != "" {
+		return "@" + feedback.Username
+	}
+	return feedback.FirstName
+}
+
+// handleFeedbackCallback handles the Reply and status buttons on forwarded feedback.
+func (m *Manager) handleFeedbackCallback(ctx context.Context, query telego.CallbackQuery) error {
+	adminID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	parts := strings.Split(strings.TrimPrefix(query.Data, feedbackCallbackPrefix), ":")
+	if len(parts) != 2 {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("invalid feedback callback data: %s", query.Data)
+	}
+	feedbackID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("invalid feedback ID in callback data: %w", err)
+	}
+
+	isAdmin, err := m.adminChecker.IsAdmin(ctx, adminID)
+	if err != nil || !isAdmin {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil), true)
+		return err
+	}
+
+	feedback, err := m.feedbackRepo.GetFeedbackByID(ctx, feedbackID)
+	if errors.Is(err, database.ErrFeedbackNotFound) {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgFeedbackNotFound", nil, nil), true)
+		return nil
+	}
+	if err != nil {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("failed to load feedback %s: %w", feedbackID.Hex(), err)
+	}
+
+	switch action := parts[1]; action {
+	case "reply":
+		if !feedback.ContactOptIn {
+			_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgFeedbackReplyNotOptedIn", nil, nil), true)
+			return nil
+		}
+		return m.startFeedbackReply(ctx, query, feedback)
+	case models.FeedbackStatusNew, models.FeedbackStatusInProgress, models.FeedbackStatusResolved:
+		return m.changeFeedbackStatus(ctx, query, feedback, action)
+	default:
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("unknown feedback action: %s", parts[1])
+	}
+}
+
+// startFeedbackReply asks the admin, in their private chat, for the reply to send to the feedback's author.
+func (m *Manager) startFeedbackReply(ctx context.Context, query telego.CallbackQuery, feedback *models.Feedback) error {
+	adminID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	m.setEditTarget(adminID, StateReplyingToFeedback, feedback.ID)
+	prompt := locales.GetMessage(localizer, "MsgFeedbackReplyPrompt", map[string]interface{}{
+		"User": feedbackSenderName(feedback),
+	}, nil)
+	if _, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(adminID), prompt)); err != nil {
+		// The admin never started a private chat with the bot
+		m.SetUserState(adminID, StateIdle)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgFeedbackReplyStartBot", nil, nil), true)
+		return fmt.Errorf("failed to send feedback reply prompt to admin %d: %w", adminID, err)
+	}
+	return m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgFeedbackReplyPromptSent", nil, nil), false)
+}
+
+// changeFeedbackStatus sets the triage status of feedback and updates its card.
+func (m *Manager) changeFeedbackStatus(ctx context.Context, query telego.CallbackQuery, feedback *models.Feedback, status string) error {
+	adminID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	changed, err := m.feedbackRepo.SetFeedbackStatus(ctx, feedback.ID, status, adminID)
+	if err != nil {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return err
+	}
+	if !changed {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgFeedbackStatusUnchanged", nil, nil), false)
+		return nil
+	}
+	log.Printf("[FeedbackStatus Feedback:%s] Status set to %s by admin %d", feedback.ID.Hex(), status, adminID)
+
+	if card, ok := query.Message.(*telego.Message); ok && card != nil {
+		feedback.Status = status
+		chatLocalizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+		text, keyboard := feedbackCard(chatLocalizer, feedback, adminDisplayName(query.From))
+		_, err = m.bot.EditMessageText(ctx, &telego.EditMessageTextParams{
+			ChatID:      tu.ID(card.Chat.ID),
+			MessageID:   card.MessageID,
+			Text:        text,
+			ReplyMarkup: keyboard,
+		})
+		if err != nil {
+			log.Printf("[FeedbackStatus Feedback:%s] Error updating feedback card: %v", feedback.ID.Hex(), err)
+		}
+	}
+	return m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgFeedbackStatusChanged", map[string]interface{}{
+		"Status": FeedbackStatusLabel(localizer, status),
+	}, nil), false)
+}
+
+// adminDisplayName returns how an admin is shown in the feedback chat.
+func adminDisplayName(user telego.User) string {
+	if user.Username != "" {
+		return "@" + user.Username
+	}
+	return user.FirstName
+}
+
+// handleFeedbackReplyContent sends an admin's reply to the author of the feedback they are replying to.
+// Commands cancel the reply and are passed on (processed=false) so they run normally.
+func (m *Manager) handleFeedbackReplyContent(ctx context.Context, message *telego.Message) (processed bool, err error) {
+	adminID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := m.localizerForUser(ctx, message.From)
+
+	if strings.HasPrefix(message.Text, "/") {
+		m.SetUserState(adminID, StateIdle)
+		return false, nil
+	}
+
+	feedbackID, ok := m.getEditTarget(adminID)
+	if !ok {
+		m.SetUserState(adminID, StateIdle)
+		return false, nil
+	}
+
+	if message.Text == "" {
+		_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgFeedbackReplyRequiresText", nil, nil)))
+		return true, err // Let the admin try again
+	}
+
+	m.SetUserState(adminID, StateIdle)
+	feedback, err := m.feedbackRepo.GetFeedbackByID(ctx, feedbackID)
+	if err != nil {
+		log.Printf("[FeedbackReply Admin:%d Feedback:%s] Error loading feedback: %v", adminID, feedbackID.Hex(), err)
+		_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil)))
+		return true, err
+	}
+	if !feedback.ContactOptIn {
+		_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgFeedbackReplyNotOptedIn", nil, nil)))
+		return true, err
+	}
+
+	userLocalizer := m.localizerForUserID(ctx, feedback.UserID)
+	reply := locales.GetMessage(userLocalizer, "MsgFeedbackReplyToUser", map[string]interface{}{
+		"Reply": message.Text,
+	}, nil)
+	replyMsg := tu.Message(tu.ID(feedback.OriginalChatID), reply).WithReplyParameters(&telego.ReplyParameters{
+		MessageID:                feedback.MessageID,
+		AllowSendingWithoutReply: true,
+	})
+	if _, err = m.bot.SendMessage(ctx, replyMsg); err != nil {
+		log.Printf("[FeedbackReply Admin:%d Feedback:%s] Error sending reply to user %d: %v", adminID, feedbackID.Hex(), feedback.UserID, err)
+		_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgFeedbackReplyFailed", nil, nil)))
+		return true, err
+	}
+
+	log.Printf("[FeedbackReply Admin:%d Feedback:%s] Sent reply to user %d", adminID, feedbackID.Hex(), feedback.UserID)
+	_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgFeedbackReplySent", nil, nil)))
+	return true, err
+}
@@ -0,0 +1,182 @@
+package suggestions
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"vrcmemes-bot/internal/format"
+	"vrcmemes-bot/internal/locales"
+
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// feedbackDuplicateWindow is how long sent feedback text is remembered for duplicate detection.
+	feedbackDuplicateWindow = 24 * time.Hour
+	// feedbackViolationWindow is how long violations count towards a mute.
+	feedbackViolationWindow = time.Hour
+)
+
+// Feedback spam violations, also used in logs.
+const (
+	feedbackViolationMuted     = "muted"
+	feedbackViolationRateLimit = "rate_limit"
+	feedbackViolationDuplicate = "duplicate"
+	feedbackViolationTooShort  = "too_short"
+)
+
+// FeedbackLimits configures spam protection for the feedback feature.
+// Zero values disable the corresponding check. The defaults are config.DefaultFeedback*.
+type FeedbackLimits struct {
+	RateLimit    int
+	RateWindow   time.Duration
+	MinLength    int
+	MuteAfter    int
+	MuteDuration time.Duration
+}
+
+// feedbackActivity is the recent feedback history of a user.
+type feedbackActivity struct {
+	sentAt     []time.Time
+	texts      map[string]time.Time // Normalized text -> when it was last sent
+	violations []time.Time
+	mutedUntil time.Time
+}
+
+// SetFeedbackLimits configures the feedback spam limits.
+func (m *Manager) SetFeedbackLimits(limits FeedbackLimits) {
+	m.feedbackGuardMutex.Lock()
+	defer m.feedbackGuardMutex.Unlock()
+	m.feedbackLimits = limits
+}
+
+// feedbackMutedUntil returns when the user's feedback mute ends, or the zero time if they are not muted.
+func (m *Manager) feedbackMutedUntil(userID int64) time.Time {
+	m.feedbackGuardMutex.Lock()
+	defer m.feedbackGuardMutex.Unlock()
+	if activity, ok := m.feedbackActivity[userID]; ok && time.Now().Before(activity.mutedUntil) {
+		return activity.mutedUntil
+	}
+	return time.Time{}
+}
+
+// admitFeedback checks new feedback against the spam limits and records it if allowed.
+// It returns the violation ("" if the feedback is allowed), or feedbackViolationMuted if this
+// violation got the user muted. Text-only feedback must meet the minimum length; media feedback is exempt.
+func (m *Manager) admitFeedback(userID int64, text string, hasMedia bool) string {
+	m.feedbackGuardMutex.Lock()
+	defer m.feedbackGuardMutex.Unlock()
+
+	now := time.Now()
+	limits := m.feedbackLimits
+	activity, ok := m.feedbackActivity[userID]
+	if !ok {
+		activity = &feedbackActivity{texts: make(map[string]time.Time)}
+		m.feedbackActivity[userID] = activity
+	}
+	activity.prune(now, limits.RateWindow)
+
+	if now.Before(activity.mutedUntil) {
+		return feedbackViolationMuted
+	}
+
+	defer m.pruneFeedbackActivity(now, limits.RateWindow)
+
+	normalized := normalizeFeedbackText(text)
+	violation := ""
+	switch {
+	case limits.RateLimit > 0 && len(activity.sentAt) >= limits.RateLimit:
+		violation = feedbackViolationRateLimit
+	case normalized != "" && !activity.texts[normalized].IsZero():
+		violation = feedbackViolationDuplicate
+	case !hasMedia && limits.MinLength > 0 && utf8.RuneCountInString(normalized) < limits.MinLength:
+		violation = feedbackViolationTooShort
+	}
+
+	if violation != "" {
+		activity.violations = append(activity.violations, now)
+		log.Printf("[FeedbackSpam User:%d] Violation %q (%d in the last %s)", userID, violation, len(activity.violations), feedbackViolationWindow)
+		if limits.MuteAfter > 0 && limits.MuteDuration > 0 && len(activity.violations) >= limits.MuteAfter {
+			activity.mutedUntil = now.Add(limits.MuteDuration)
+			activity.violations = nil
+			log.Printf("[FeedbackSpam User:%d] Muted from feedback until %s", userID, activity.mutedUntil.Format(time.RFC3339))
+			return feedbackViolationMuted // Tell them about the mute rather than the last violation
+		}
+		return violation
+	}
+
+	activity.sentAt = append(activity.sentAt, now)
+	if normalized != "" {
+		activity.texts[normalized] = now
+	}
+	return ""
+}
+
+// prune drops history older than the windows it is checked against.
+func (a *feedbackActivity) prune(now time.Time, rateWindow time.Duration) {
+	a.sentAt = pruneBefore(a.sentAt, now.Add(-rateWindow))
+	a.violations = pruneBefore(a.violations, now.Add(-feedbackViolationWindow))
+	for text, sentAt := range a.texts {
+		if now.Sub(sentAt) > feedbackDuplicateWindow {
+			delete(a.texts, text)
+		}
+	}
+}
+
+// pruneFeedbackActivity forgets users whose feedback history has all expired, so the activity
+// map only holds users who sent feedback recently. The caller must hold feedbackGuardMutex.
+func (m *Manager) pruneFeedbackActivity(now time.Time, rateWindow time.Duration) {
+	for userID, activity := range m.feedbackActivity {
+		activity.prune(now, rateWindow)
+		if activity.empty(now) {
+			delete(m.feedbackActivity, userID)
+		}
+	}
+}
+
+// empty reports whether the history has nothing left to check new feedback against.
+func (a *feedbackActivity) empty(now time.Time) bool {
+	return len(a.sentAt) == 0 && len(a.texts) == 0 && len(a.violations) == 0 && !now.Before(a.mutedUntil)
+}
+
+// pruneBefore removes times before cutoff from a chronologically ordered slice.
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	return times[i:]
+}
+
+// normalizeFeedbackText lowercases text and collapses whitespace so trivial variations count as duplicates.
+func normalizeFeedbackText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// sendFeedbackViolation tells the user why their feedback was not accepted.
+func (m *Manager) sendFeedbackViolation(ctx context.Context, localizer *i18n.Localizer, userID, chatID int64, violation string) error {
+	var text string
+	switch violation {
+	case feedbackViolationMuted:
+		// Rounded up to whole minutes, so the last minute isn't shown as "just now"
+		now := time.Now()
+		remaining := m.feedbackMutedUntil(userID).Sub(now).Truncate(time.Minute) + time.Minute
+		text = locales.GetMessage(localizer, "MsgFeedbackMuted", map[string]interface{}{
+			"When": format.RelativeTime(localizer, now.Add(remaining), now),
+		}, nil)
+	case feedbackViolationRateLimit:
+		text = locales.GetMessage(localizer, "MsgFeedbackRateLimited", nil, nil)
+	case feedbackViolationDuplicate:
+		text = locales.GetMessage(localizer, "MsgFeedbackDuplicate", nil, nil)
+	case feedbackViolationTooShort:
+		m.feedbackGuardMutex.Lock()
+		minLength := m.feedbackLimits.MinLength
+		m.feedbackGuardMutex.Unlock()
+		text = locales.GetPluralMessage(localizer, "MsgFeedbackTooShort", minLength, nil)
+	}
+	_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), text))
+	return err
+}
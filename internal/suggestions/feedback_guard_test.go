@@ -0,0 +1,68 @@
+package suggestions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFeedbackGuard returns a manager with only the feedback spam protection set up.
+func newFeedbackGuard(limits FeedbackLimits) *Manager {
+	return &Manager{feedbackActivity: make(map[int64]*feedbackActivity), feedbackLimits: limits}
+}
+
+func TestFeedbackRateLimit(t *testing.T) {
+	m := newFeedbackGuard(FeedbackLimits{RateLimit: 2, RateWindow: 50 * time.Millisecond})
+
+	assert.Empty(t, m.admitFeedback(1, "first idea", false))
+	assert.Empty(t, m.admitFeedback(1, "second idea", false))
+	assert.Equal(t, feedbackViolationRateLimit, m.admitFeedback(1, "third idea", false))
+	// Other users have their own limit
+	assert.Empty(t, m.admitFeedback(2, "first idea", false))
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Empty(t, m.admitFeedback(1, "third idea", false), "rate limit outlived its window")
+}
+
+func TestFeedbackDuplicatesAndMinLength(t *testing.T) {
+	m := newFeedbackGuard(FeedbackLimits{MinLength: 10})
+
+	assert.Empty(t, m.admitFeedback(1, "The bot is great", false))
+	assert.Equal(t, feedbackViolationDuplicate, m.admitFeedback(1, "  the BOT is   great ", false))
+	assert.Equal(t, feedbackViolationTooShort, m.admitFeedback(1, "short", false))
+	assert.Empty(t, m.admitFeedback(1, "", true), "media feedback needs no text")
+}
+
+func TestFeedbackMuteExpires(t *testing.T) {
+	m := newFeedbackGuard(FeedbackLimits{MinLength: 10, MuteAfter: 2, MuteDuration: 50 * time.Millisecond})
+
+	assert.Equal(t, feedbackViolationTooShort, m.admitFeedback(1, "short", false))
+	assert.Equal(t, feedbackViolationMuted, m.admitFeedback(1, "short", false), "second violation didn't mute")
+	assert.False(t, m.feedbackMutedUntil(1).IsZero())
+	assert.Equal(t, feedbackViolationMuted, m.admitFeedback(1, "a long enough message", false))
+
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, m.feedbackMutedUntil(1).IsZero())
+	assert.Empty(t, m.admitFeedback(1, "a long enough message", false), "mute outlived its duration")
+}
+
+func TestFeedbackActivityIsForgottenOnceExpired(t *testing.T) {
+	m := newFeedbackGuard(FeedbackLimits{RateLimit: 3, RateWindow: time.Minute})
+
+	require.Empty(t, m.admitFeedback(1, "an old message", false))
+	require.Empty(t, m.admitFeedback(2, "a recent message", false))
+	// User 1's feedback is older than every window it is checked against
+	old := time.Now().Add(-feedbackDuplicateWindow - time.Minute)
+	activity := m.feedbackActivity[1]
+	activity.sentAt = []time.Time{old}
+	for text := range activity.texts {
+		activity.texts[text] = old
+	}
+
+	require.Empty(t, m.admitFeedback(3, "a new message", false))
+	assert.NotContains(t, m.feedbackActivity, int64(1))
+	assert.Contains(t, m.feedbackActivity, int64(2))
+	assert.Contains(t, m.feedbackActivity, int64(3))
+}
@@ -0,0 +1,2460 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"vrcmemes-bot/internal/auth"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/engagement"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/mediagroups"
+	"vrcmemes-bot/internal/publisher"
+	"vrcmemes-bot/internal/simulation"
+	"vrcmemes-bot/internal/thumbnail"
+	"vrcmemes-bot/internal/translate"
+	"vrcmemes-bot/internal/videopolicy"
+	"vrcmemes-bot/pkg/telegoapi"
+	"vrcmemes-bot/pkg/telegoapi/telegoapitest"
+	"vrcmemes-bot/pkg/utils"
+
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// --- In-memory fakes ---
+
+// memorySuggestionRepo is an in-memory SuggestionRepository.
+type memorySuggestionRepo struct {
+	mu          sync.Mutex
+	suggestions []*models.Suggestion
+}
+
+func (r *memorySuggestionRepo) CreateSuggestion(ctx context.Context, suggestion *models.Suggestion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if suggestion.RefCode != "" && s.RefCode == suggestion.RefCode {
+			return database.ErrRefCodeTaken
+		}
+	}
+	suggestion.ID = primitive.NewObjectID()
+	stored := *suggestion
+	r.suggestions = append(r.suggestions, &stored)
+	return nil
+}
+
+func (r *memorySuggestionRepo) GetSuggestionByID(ctx context.Context, id primitive.ObjectID) (*models.Suggestion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id {
+			found := *s
+			return &found, nil
+		}
+	}
+	return nil, errors.New("suggestion not found")
+}
+
+func (r *memorySuggestionRepo) GetSuggestionByRefCode(ctx context.Context, refCode string) (*models.Suggestion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.RefCode == refCode {
+			found := *s
+			return &found, nil
+		}
+	}
+	return nil, database.ErrSuggestionNotFound
+}
+
+func (r *memorySuggestionRepo) UpdateSuggestionStatus(ctx context.Context, id primitive.ObjectID, status string, adminID int64, adminUsername string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id {
+			s.History = append(s.History, models.StatusChange{From: s.Status, To: status, By: adminID, At: time.Now()})
+			s.Status = status
+			return nil
+		}
+	}
+	return errors.New("suggestion not found")
+}
+
+func (r *memorySuggestionRepo) GetPendingSuggestions(ctx context.Context, limit int, offset int) ([]models.Suggestion, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var pending []models.Suggestion
+	for _, s := range r.suggestions {
+		if s.Status == string(StatusPending) {
+			pending = append(pending, *s)
+		}
+	}
+	total := int64(len(pending))
+	if offset >= len(pending) {
+		return nil, total, nil
+	}
+	pending = pending[offset:]
+	if len(pending) > limit {
+		pending = pending[:limit]
+	}
+	return pending, total, nil
+}
+
+func (r *memorySuggestionRepo) DeleteSuggestion(ctx context.Context, id primitive.ObjectID) error {
+	return nil
+}
+
+func (r *memorySuggestionRepo) DeleteTestSuggestions(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var kept []*models.Suggestion
+	for _, s := range r.suggestions {
+		if !s.Test {
+			kept = append(kept, s)
+		}
+	}
+	deleted := int64(len(r.suggestions) - len(kept))
+	r.suggestions = kept
+	return deleted, nil
+}
+
+func (r *memorySuggestionRepo) ResetDailyLimits(ctx context.Context) error {
+	return nil
+}
+
+func (r *memorySuggestionRepo) GetSuggestionsBySuggester(ctx context.Context, suggesterID int64, status string, limit int) ([]models.Suggestion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []models.Suggestion
+	for i := len(r.suggestions) - 1; i >= 0 && len(result) < limit; i-- {
+		if s := r.suggestions[i]; s.SuggesterID == suggesterID && s.Status == status {
+			result = append(result, *s)
+		}
+	}
+	return result, nil
+}
+
+func (r *memorySuggestionRepo) UpdateSuggestionCaption(ctx context.Context, id primitive.ObjectID, suggesterID int64, caption string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id && s.SuggesterID == suggesterID && s.Status == string(models.StatusPending) {
+			s.Caption = caption
+			return nil
+		}
+	}
+	return database.ErrSuggestionNotEditable
+}
+
+func (r *memorySuggestionRepo) AddSuggestionFile(ctx context.Context, id primitive.ObjectID, suggesterID int64, fileID string, maxFiles int) error {
+	return nil
+}
+
+func (r *memorySuggestionRepo) SetSuggestionTags(ctx context.Context, id primitive.ObjectID, tags []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id {
+			s.Tags = tags
+			return nil
+		}
+	}
+	return errors.New("suggestion not found")
+}
+
+func (r *memorySuggestionRepo) SetSuggestionCredit(ctx context.Context, id primitive.ObjectID, suggesterID int64, credit bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id && s.SuggesterID == suggesterID {
+			s.Credit = &credit
+			return nil
+		}
+	}
+	return errors.New("suggestion not found")
+}
+
+func (r *memorySuggestionRepo) AddSuggestionComment(ctx context.Context, id primitive.ObjectID, comment models.SuggestionComment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id {
+			s.Comments = append(s.Comments, comment)
+			return nil
+		}
+	}
+	return errors.New("suggestion not found")
+}
+
+func (r *memorySuggestionRepo) GetPendingSuggestionsInOrder(ctx context.Context, limit int, order database.PendingOrder) ([]models.Suggestion, int64, error) {
+	return r.GetPendingSuggestions(ctx, limit, 0)
+}
+
+func (r *memorySuggestionRepo) GetAssignedPendingSuggestions(ctx context.Context, adminID int64, limit int) ([]models.Suggestion, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var assigned []models.Suggestion
+	for _, s := range r.suggestions {
+		if s.Status == string(StatusPending) && s.AssignedTo == adminID {
+			assigned = append(assigned, *s)
+		}
+	}
+	total := int64(len(assigned))
+	if len(assigned) > limit {
+		assigned = assigned[:limit]
+	}
+	return assigned, total, nil
+}
+
+func (r *memorySuggestionRepo) AssignSuggestion(ctx context.Context, id primitive.ObjectID, adminID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id {
+			s.AssignedTo = adminID
+			s.AssignedAt = time.Now()
+			s.History = append(s.History, models.StatusChange{
+				From: s.Status, To: s.Status, At: s.AssignedAt, Reason: models.HistoryReasonAssigned, Assignee: adminID,
+			})
+			return nil
+		}
+	}
+	return errors.New("suggestion not found")
+}
+
+func (r *memorySuggestionRepo) GetStaleAssignments(ctx context.Context, assignedBefore time.Time, limit int) ([]models.Suggestion, error) {
+	return nil, nil
+}
+
+func (r *memorySuggestionRepo) ExpireSuggestions(ctx context.Context, submittedBefore time.Time, exclude []primitive.ObjectID, limit int) ([]models.Suggestion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var expired []models.Suggestion
+	for _, s := range r.suggestions {
+		if len(expired) == limit {
+			break
+		}
+		if s.Status != string(StatusPending) || !s.SubmittedAt.Before(submittedBefore) || s.ReopenedAt.After(submittedBefore) || slices.Contains(exclude, s.ID) {
+			continue
+		}
+		s.Status, s.RejectionReason = string(StatusRejected), models.RejectionReasonExpired
+		s.History = append(s.History, models.StatusChange{
+			From: string(StatusPending), To: s.Status, At: time.Now(), Reason: s.RejectionReason,
+		})
+		expired = append(expired, *s)
+	}
+	return expired, nil
+}
+
+func (r *memorySuggestionRepo) SetSuggestionRubric(ctx context.Context, id primitive.ObjectID, rubric string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id {
+			s.Rubric = rubric
+			return nil
+		}
+	}
+	return errors.New("suggestion not found")
+}
+
+func (r *memorySuggestionRepo) SetSuggestionStyle(ctx context.Context, id primitive.ObjectID, style string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id {
+			s.Style = style
+			return nil
+		}
+	}
+	return errors.New("suggestion not found")
+}
+
+func (r *memorySuggestionRepo) SetSuggestionProtectContent(ctx context.Context, id primitive.ObjectID, protect bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id {
+			s.ProtectContent = &protect
+			return nil
+		}
+	}
+	return errors.New("suggestion not found")
+}
+
+func (r *memorySuggestionRepo) SetSuggestionVideoPolicyOverride(ctx context.Context, id primitive.ObjectID, override bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id {
+			s.VideoPolicyOverride = override
+			return nil
+		}
+	}
+	return errors.New("suggestion not found")
+}
+
+func (r *memorySuggestionRepo) SetSuggestionThumbnail(ctx context.Context, id primitive.ObjectID, thumbnail *models.VideoThumbnail) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id {
+			s.Thumbnail = thumbnail
+			return nil
+		}
+	}
+	return errors.New("suggestion not found")
+}
+
+func (r *memorySuggestionRepo) SetSuggestionTranslation(ctx context.Context, id primitive.ObjectID, language, text string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id {
+			s.CaptionLanguage, s.TranslatedCaption = language, text
+			return nil
+		}
+	}
+	return errors.New("suggestion not found")
+}
+
+func (r *memorySuggestionRepo) SetSuggestionCaptionChoice(ctx context.Context, id primitive.ObjectID, choice string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id {
+			s.CaptionChoice = choice
+			return nil
+		}
+	}
+	return errors.New("suggestion not found")
+}
+
+func (r *memorySuggestionRepo) SetSuggestionPreview(ctx context.Context, id primitive.ObjectID, chatID int64, messageIDs []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id {
+			s.PreviewChatID, s.PreviewMessageIDs = chatID, messageIDs
+			return nil
+		}
+	}
+	return errors.New("suggestion not found")
+}
+
+func (r *memorySuggestionRepo) SetSuggestionPublished(ctx context.Context, id primitive.ObjectID, messageID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id {
+			s.PublishedMessageID = messageID
+			return nil
+		}
+	}
+	return errors.New("suggestion not found")
+}
+
+func (r *memorySuggestionRepo) MarkSuggestionDuplicate(ctx context.Context, id, duplicateOf primitive.ObjectID, reviewerID int64, reviewerUsername string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id && s.Status == string(StatusPending) {
+			s.Status = string(StatusRejected)
+			s.RejectionReason, s.DuplicateOf, s.ReviewedBy = models.RejectionReasonDuplicate, duplicateOf, reviewerID
+			s.History = append(s.History, models.StatusChange{
+				From: string(StatusPending), To: s.Status, By: reviewerID, At: time.Now(), Reason: s.RejectionReason,
+			})
+			return nil
+		}
+	}
+	return database.ErrSuggestionNotFound
+}
+
+func (r *memorySuggestionRepo) ReopenSuggestion(ctx context.Context, id primitive.ObjectID, adminID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id && s.Status == string(StatusRejected) {
+			s.Status, s.ReopenedAt = string(StatusPending), time.Now()
+			s.ReviewedBy, s.ReviewedAt, s.RejectionReason, s.DuplicateOf = 0, time.Time{}, "", primitive.NilObjectID
+			s.History = append(s.History, models.StatusChange{From: string(StatusRejected), To: s.Status, By: adminID, At: s.ReopenedAt})
+			return nil
+		}
+	}
+	return database.ErrSuggestionNotFound
+}
+
+func (r *memorySuggestionRepo) AddCoSuggesters(ctx context.Context, id primitive.ObjectID, coSuggesters []models.CoSuggester) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id && s.Status == string(StatusPending) {
+			s.CoSuggesters = append(s.CoSuggesters, coSuggesters...)
+			return nil
+		}
+	}
+	return database.ErrSuggestionNotFound
+}
+
+func (r *memorySuggestionRepo) SetSuggestionOCR(ctx context.Context, id primitive.ObjectID, text, key, similarTo string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.suggestions {
+		if s.ID == id {
+			s.OCRText, s.OCRKey, s.SimilarTo = text, key, similarTo
+			return nil
+		}
+	}
+	return database.ErrSuggestionNotFound
+}
+
+func (r *memorySuggestionRepo) FindSuggestionByOCRKey(ctx context.Context, key string, exclude primitive.ObjectID) (*models.Suggestion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.suggestions) - 1; i >= 0; i-- {
+		s := r.suggestions[i]
+		if s.ID != exclude && s.OCRKey == key && s.Status != string(StatusRejected) {
+			found := *s
+			return &found, nil
+		}
+	}
+	return nil, database.ErrSuggestionNotFound
+}
+
+func (r *memorySuggestionRepo) SearchSuggestions(ctx context.Context, query string, limit int) ([]models.Suggestion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	query = strings.ToLower(query)
+	var found []models.Suggestion
+	for i := len(r.suggestions) - 1; i >= 0 && len(found) < limit; i-- {
+		s := r.suggestions[i]
+		if strings.Contains(strings.ToLower(s.Caption), query) || strings.Contains(strings.ToLower(s.OCRText), query) {
+			found = append(found, *s)
+		}
+	}
+	return found, nil
+}
+
+// onlyStatus returns the stored status of the only suggestion.
+func (r *memorySuggestionRepo) onlyStatus(t *testing.T) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	require.Len(t, r.suggestions, 1)
+	return r.suggestions[0].Status
+}
+
+// memoryPostLogger records the logged posts.
+type memoryPostLogger struct {
+	mu    sync.Mutex
+	posts []models.PostLog
+}
+
+func (l *memoryPostLogger) LogPublishedPost(post models.PostLog) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.posts = append(l.posts, post)
+	return nil
+}
+
+func (l *memoryPostLogger) logged() []models.PostLog {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]models.PostLog(nil), l.posts...)
+}
+
+// memoryFeedbackRepo stores feedback in memory.
+type memoryFeedbackRepo struct {
+	stubFeedbackRepo
+	mu       sync.Mutex
+	feedback []*models.Feedback
+}
+
+func (r *memoryFeedbackRepo) AddFeedback(ctx context.Context, feedback *models.Feedback) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	feedback.ID = primitive.NewObjectID()
+	stored := *feedback
+	r.feedback = append(r.feedback, &stored)
+	return nil
+}
+
+func (r *memoryFeedbackRepo) GetFeedbackByID(ctx context.Context, id primitive.ObjectID) (*models.Feedback, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, f := range r.feedback {
+		if f.ID == id {
+			found := *f
+			return &found, nil
+		}
+	}
+	return nil, database.ErrFeedbackNotFound
+}
+
+func (r *memoryFeedbackRepo) SetFeedbackContactOptIn(ctx context.Context, id primitive.ObjectID, userID int64, optIn bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, f := range r.feedback {
+		if f.ID == id && f.UserID == userID {
+			f.ContactOptIn = optIn
+			return nil
+		}
+	}
+	return database.ErrFeedbackNotFound
+}
+
+// stubFeedbackRepo discards feedback.
+type stubFeedbackRepo struct{}
+
+func (stubFeedbackRepo) AddFeedback(ctx context.Context, feedback *models.Feedback) error {
+	return nil
+}
+
+func (stubFeedbackRepo) GetFeedbackByID(ctx context.Context, id primitive.ObjectID) (*models.Feedback, error) {
+	return nil, database.ErrFeedbackNotFound
+}
+
+func (stubFeedbackRepo) SetFeedbackStatus(ctx context.Context, id primitive.ObjectID, status string, adminID int64) (bool, error) {
+	return false, nil
+}
+
+func (stubFeedbackRepo) SetFeedbackContactOptIn(ctx context.Context, id primitive.ObjectID, userID int64, optIn bool) error {
+	return database.ErrFeedbackNotFound
+}
+
+func (stubFeedbackRepo) ListFeedback(ctx context.Context, statuses []string, limit int) ([]models.Feedback, error) {
+	return nil, nil
+}
+
+// stubUserRepo knows no users and saves nothing.
+type stubUserRepo struct{}
+
+func (stubUserRepo) UpdateUser(ctx context.Context, user telego.User, isAdmin bool, action string) error {
+	return nil
+}
+
+func (stubUserRepo) GetUser(ctx context.Context, userID int64) (*models.User, error) {
+	return nil, nil
+}
+
+func (stubUserRepo) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	return nil, nil
+}
+
+func (stubUserRepo) IncrementUserCounter(ctx context.Context, userID int64, counter string) error {
+	return nil
+}
+
+func (stubUserRepo) SetUserConsent(ctx context.Context, userID int64, at time.Time) error {
+	return nil
+}
+
+func (stubUserRepo) GetUserLanguage(ctx context.Context, userID int64) (string, error) {
+	return "", nil
+}
+
+func (stubUserRepo) SetUserLanguage(ctx context.Context, userID int64, lang string) error {
+	return nil
+}
+
+func (stubUserRepo) GetUserFirstSeen(ctx context.Context, userID int64) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (stubUserRepo) SetNotificationMuted(ctx context.Context, userID int64, category string, muted bool) error {
+	return nil
+}
+
+func (stubUserRepo) DeleteUser(ctx context.Context, userID int64) error {
+	return nil
+}
+
+// stubMembershipRepo has no tracked memberships, so subscriptions are checked with GetChatMember.
+type stubMembershipRepo struct{}
+
+func (stubMembershipRepo) SaveMemberStatus(ctx context.Context, member models.ChannelMember) error {
+	return nil
+}
+
+func (stubMembershipRepo) GetMemberStatus(ctx context.Context, chatID, userID int64) (*models.ChannelMember, error) {
+	return nil, nil
+}
+
+func (stubMembershipRepo) RecordMembershipEvent(ctx context.Context, event *models.MembershipEvent) error {
+	return nil
+}
+
+func (stubMembershipRepo) GetMembershipGrowth(ctx context.Context, chatID int64, since time.Time) (int64, int64, error) {
+	return 0, 0, nil
+}
+
+// staticAdminChecker treats a fixed set of users as admins.
+type staticAdminChecker map[int64]bool
+
+func (c staticAdminChecker) IsAdmin(ctx context.Context, userID int64) (bool, error) {
+	return c[userID], nil
+}
+
+func (c staticAdminChecker) Refresh(ctx context.Context) ([]int64, error) {
+	adminIDs := make([]int64, 0, len(c))
+	for id, isAdmin := range c {
+		if isAdmin {
+			adminIDs = append(adminIDs, id)
+		}
+	}
+	return adminIDs, nil
+}
+
+func (c staticAdminChecker) Invalidate(userID int64) {}
+
+func (c staticAdminChecker) IsSuperAdmin(userID int64) bool {
+	return false
+}
+
+func (c staticAdminChecker) SuperAdminIDs() []int64 {
+	return nil
+}
+
+// --- Flow test ---
+
+// TestMain loads the locales once: publish callbacks of earlier tests may still be using them
+// while later tests run.
+func TestMain(m *testing.M) {
+	locales.Init("en")
+	os.Exit(m.Run())
+}
+
+const testChannelID int64 = -100123
+
+// dispatchTo routes updates to the manager the way the bot's update loop does for the commands used here.
+func dispatchTo(t *testing.T, m *Manager) telegoapitest.DispatchFunc {
+	return func(ctx context.Context, update telego.Update) {
+		var err error
+		switch {
+		case update.CallbackQuery != nil:
+			_, err = m.HandleCallbackQuery(ctx, *update.CallbackQuery)
+		case update.Message != nil && update.Message.Text == "/suggest":
+			err = m.HandleSuggestCommand(ctx, update)
+		case update.Message != nil && update.Message.Text == "/feedback":
+			err = m.HandleFeedbackCommand(ctx, update)
+		case update.Message != nil && update.Message.Text == "/mysuggestions":
+			err = m.HandleMySuggestionsCommand(ctx, update)
+		case update.Message != nil && update.Message.Text == "/review":
+			err = m.HandleReviewCommand(ctx, update, "")
+		case update.Message != nil && strings.HasPrefix(update.Message.Text, "/status "):
+			err = m.HandleSuggestionStatusCommand(ctx, update, strings.TrimPrefix(update.Message.Text, "/status "))
+		case update.Message != nil && strings.HasPrefix(update.Message.Text, "/find "):
+			err = m.HandleFindCommand(ctx, update, strings.TrimPrefix(update.Message.Text, "/find "))
+		case update.Message != nil && update.Message.Text == "/queue export":
+			err = m.HandleQueueExportCommand(ctx, update)
+		case update.Message != nil && strings.HasPrefix(update.Message.Text, "/apply "):
+			err = m.HandleApplyCommand(ctx, update, strings.TrimPrefix(update.Message.Text, "/apply "))
+		case update.Message != nil && strings.HasPrefix(update.Message.Text, "/reopen "):
+			err = m.HandleReopenCommand(ctx, update, strings.TrimPrefix(update.Message.Text, "/reopen "))
+		case update.Message != nil:
+			_, err = m.HandleMessage(ctx, update)
+		}
+		require.NoError(t, err)
+	}
+}
+
+func TestSuggestReviewPublishFlow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+
+	user := telegoapitest.User(42, "suggester")
+	admin := telegoapitest.User(7, "admin")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{admin.ID: true}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	postLogger := &memoryPostLogger{}
+	manager.SetPostLogger(postLogger)
+	unitOfWork := &countingUnitOfWork{}
+	manager.SetUnitOfWork(unitOfWork)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+
+	// The user has to accept the privacy notice before suggesting
+	harness.SendText(ctx, user, "/suggest")
+	assert.Equal(t, StateIdle, manager.GetUserState(user.ID))
+	notices := bot.CallsTo("SendMessage", user.ID)
+	require.NotEmpty(t, notices)
+	agreeData, ok := telegoapitest.ButtonData(notices[len(notices)-1], consentAgreeData)
+	require.True(t, ok, "privacy notice has no agree button")
+	harness.PressButton(ctx, user, nil, agreeData)
+
+	// The user suggests a photo
+	harness.SendText(ctx, user, "/suggest")
+	assert.Equal(t, StateAwaitingSuggestion, manager.GetUserState(user.ID))
+	harness.SendPhoto(ctx, user, "photo-file-id", "look at this")
+	assert.Equal(t, StateIdle, manager.GetUserState(user.ID))
+	assert.Equal(t, string(StatusPending), repo.onlyStatus(t))
+
+	// The admin reviews it and gets the photo with review buttons
+	harness.SendText(ctx, admin, "/review")
+	reviewPhotos := bot.CallsTo("SendPhoto", admin.ID)
+	require.Len(t, reviewPhotos, 1)
+	approveData, ok := telegoapitest.ButtonData(reviewPhotos[0], ":approve:")
+	require.True(t, ok, "review message has no approve button")
+
+	// Approving publishes the photo to the channel in the background
+	harness.PressButton(ctx, admin, nil, approveData)
+	assert.Equal(t, string(StatusApproved), repo.onlyStatus(t))
+	assert.Equal(t, 1, unitOfWork.runs, "the approval should be written in a unit of work")
+
+	call, published := bot.WaitForCall("SendMediaGroup", testChannelID, 5*time.Second)
+	require.True(t, published, "suggestion was not published to the channel")
+	params := call.Params.(*telego.SendMediaGroupParams)
+	require.Len(t, params.Media, 1)
+	photo, isPhoto := params.Media[0].(*telego.InputMediaPhoto)
+	require.True(t, isPhoto)
+	assert.Equal(t, "photo-file-id", photo.Media.FileID)
+
+	// The post is logged as coming from the suggestion
+	require.Eventually(t, func() bool { return len(postLogger.logged()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	post := postLogger.logged()[0]
+	assert.Equal(t, models.PostOriginSuggestion, post.Origin)
+	assert.Equal(t, user.ID, post.SenderID)
+	repo.mu.Lock()
+	assert.Equal(t, repo.suggestions[0].ID, post.SuggestionID)
+	repo.mu.Unlock()
+}
+
+// grantAwareAdminChecker treats users granted the command being handled as admins, like auth.AdminChecker.
+type grantAwareAdminChecker struct {
+	staticAdminChecker
+}
+
+func (c grantAwareAdminChecker) IsAdmin(ctx context.Context, userID int64) (bool, error) {
+	return auth.Granted(ctx, userID) || c.staticAdminChecker[userID], nil
+}
+
+func TestReviewGranteeApprovesAndRejects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+	user := telegoapitest.User(42, "suggester")
+	helper := telegoapitest.User(9, "helper") // Granted /review with /perm, not a channel admin
+	manager := NewManager(bot, repo, testChannelID, grantAwareAdminChecker{staticAdminChecker{}}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	manager.rememberConsent(user.ID)
+	dispatch := dispatchTo(t, manager)
+	// Grants the helper's /review command and the buttons a /review grant covers, as the bot does
+	harness := telegoapitest.NewHarness(bot, func(ctx context.Context, update telego.Update) {
+		switch {
+		case update.Message != nil && update.Message.From.ID == helper.ID && update.Message.Text == "/review",
+			update.CallbackQuery != nil && update.CallbackQuery.From.ID == helper.ID && IsGrantableReviewCallback(update.CallbackQuery.Data):
+			ctx = auth.WithGrant(ctx, helper.ID)
+		}
+		dispatch(ctx, update)
+	})
+	for _, caption := range []string{"first", "second"} {
+		harness.SendText(ctx, user, "/suggest")
+		harness.SendPhoto(ctx, user, caption, "")
+	}
+
+	harness.SendText(ctx, helper, "/review")
+	reviewPhotos := bot.CallsTo("SendPhoto", helper.ID)
+	require.Len(t, reviewPhotos, 1)
+
+	// Buttons beyond approving and rejecting stay with channel admins
+	lockData, ok := telegoapitest.ButtonData(reviewPhotos[0], ":lock:")
+	require.True(t, ok, "review message has no protect button")
+	harness.PressButton(ctx, helper, nil, lockData)
+	answers := bot.CallsTo("AnswerCallbackQuery", 0)
+	require.NotEmpty(t, answers)
+	assert.Contains(t, answers[len(answers)-1].Params.(*telego.AnswerCallbackQueryParams).Text, "only available to administrators")
+
+	approveData, ok := telegoapitest.ButtonData(reviewPhotos[0], ":approve:")
+	require.True(t, ok, "review message has no approve button")
+	harness.PressButton(ctx, helper, nil, approveData)
+	reviewPhotos = bot.CallsTo("SendPhoto", helper.ID)
+	rejectData, ok := telegoapitest.ButtonData(reviewPhotos[len(reviewPhotos)-1], ":reject:")
+	require.True(t, ok, "review message has no reject button")
+	harness.PressButton(ctx, helper, nil, rejectData)
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	assert.Equal(t, string(StatusApproved), repo.suggestions[0].Status)
+	require.NotEmpty(t, repo.suggestions[0].History)
+	assert.Equal(t, helper.ID, repo.suggestions[0].History[len(repo.suggestions[0].History)-1].By)
+	assert.Equal(t, string(StatusRejected), repo.suggestions[1].Status)
+}
+
+func TestSuggestionPreviewSubmitsOnlyOnConfirm(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+
+	user := telegoapitest.User(42, "suggester")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	manager.SetSuggestionPreview(true)
+	manager.rememberConsent(user.ID)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+
+	// prompt returns the preview prompt the buttons are pressed on
+	prompt := func() *telego.Message {
+		manager.pendingSubmissionsMutex.Lock()
+		defer manager.pendingSubmissionsMutex.Unlock()
+		pending, ok := manager.pendingSubmissions[user.ID]
+		require.True(t, ok, "no suggestion is waiting for confirmation")
+		return &telego.Message{MessageID: pending.promptMessageID, Chat: telego.Chat{ID: user.ID}}
+	}
+
+	// The photo is shown back with Confirm and Cancel buttons, and nothing is stored yet
+	harness.SendText(ctx, user, "/suggest")
+	harness.SendPhoto(ctx, user, "photo-file-id", "look at this")
+	assert.Equal(t, StateConfirmingSuggestion, manager.GetUserState(user.ID))
+	assert.Empty(t, repo.suggestions)
+	previews := bot.CallsTo("SendPhoto", user.ID)
+	require.Len(t, previews, 1)
+	assert.Equal(t, "photo-file-id", previews[0].Params.(*telego.SendPhotoParams).Photo.FileID)
+	messages := bot.CallsTo("SendMessage", user.ID)
+	_, ok := telegoapitest.ButtonData(messages[len(messages)-1], submitConfirmData)
+	require.True(t, ok, "preview has no Confirm button")
+
+	// Cancel drops the suggestion and its preview
+	cancelled := prompt()
+	harness.PressButton(ctx, user, cancelled, submitCancelData)
+	assert.Equal(t, StateIdle, manager.GetUserState(user.ID))
+	assert.Empty(t, repo.suggestions)
+	assert.Len(t, bot.CallsTo("DeleteMessage", user.ID), 2)
+
+	// Confirm submits it, and a stale button doesn't submit it twice
+	harness.SendText(ctx, user, "/suggest")
+	harness.SendPhoto(ctx, user, "photo-file-id", "look at this")
+	confirmed := prompt()
+	harness.PressButton(ctx, user, confirmed, submitConfirmData)
+	assert.Equal(t, string(StatusPending), repo.onlyStatus(t))
+	assert.Equal(t, StateIdle, manager.GetUserState(user.ID))
+	harness.PressButton(ctx, user, confirmed, submitConfirmData)
+	harness.PressButton(ctx, user, cancelled, submitConfirmData)
+	assert.Equal(t, string(StatusPending), repo.onlyStatus(t))
+}
+
+func TestFeedbackReplyRequiresContactOptIn(t *testing.T) {
+	ctx := context.Background()
+
+	bot := telegoapitest.NewFakeBot()
+	feedbackRepo := &memoryFeedbackRepo{}
+	user := telegoapitest.User(42, "author")
+	admin := telegoapitest.User(7, "admin")
+	manager := NewManager(bot, &memorySuggestionRepo{}, testChannelID, staticAdminChecker{admin.ID: true}, feedbackRepo,
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, publisher.NewQueue(time.Millisecond))
+	manager.rememberConsent(user.ID)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+
+	harness.SendText(ctx, user, "/feedback")
+	harness.PressButton(ctx, user, &telego.Message{MessageID: 1, Chat: telego.Chat{ID: user.ID}}, feedbackCategoryCallbackPrefix+models.FeedbackCategoryIdea)
+	harness.SendText(ctx, user, "Please add a dark theme to the channel")
+	require.Len(t, feedbackRepo.feedback, 1)
+	feedback := feedbackRepo.feedback[0]
+
+	messages := bot.CallsTo("SendMessage", user.ID)
+	yes, ok := telegoapitest.ButtonData(messages[len(messages)-1], ":yes")
+	require.True(t, ok, "feedback author wasn't asked whether they want a reply")
+	reply := feedbackCallbackPrefix + feedback.ID.Hex() + ":reply"
+	card := &telego.Message{MessageID: 100, Chat: telego.Chat{ID: -100}}
+
+	// Without the author's consent admins can't reply
+	harness.PressButton(ctx, admin, card, reply)
+	assert.Equal(t, StateIdle, manager.GetUserState(admin.ID))
+	assert.Empty(t, bot.CallsTo("SendMessage", admin.ID))
+
+	// Only the author can give it
+	question := &telego.Message{MessageID: 2, Chat: telego.Chat{ID: user.ID}}
+	harness.PressButton(ctx, admin, question, yes)
+	assert.False(t, feedbackRepo.feedback[0].ContactOptIn)
+	harness.PressButton(ctx, user, question, yes)
+	assert.True(t, feedbackRepo.feedback[0].ContactOptIn)
+
+	harness.PressButton(ctx, admin, card, reply)
+	assert.Equal(t, StateReplyingToFeedback, manager.GetUserState(admin.ID))
+	harness.SendText(ctx, admin, "Dark theme is coming next week")
+	replies := bot.CallsTo("SendMessage", user.ID)
+	assert.Contains(t, replies[len(replies)-1].Params.(*telego.SendMessageParams).Text, "Dark theme is coming next week")
+}
+
+func TestSuggestionStatusByRefCode(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+
+	user := telegoapitest.User(42, "suggester")
+	admin := telegoapitest.User(7, "admin")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{admin.ID: true}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	manager.rememberConsent(user.ID)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+
+	// lastReply returns the text of the last message sent to the user
+	lastReply := func() string {
+		calls := bot.CallsTo("SendMessage", user.ID)
+		require.NotEmpty(t, calls)
+		return calls[len(calls)-1].Params.(*telego.SendMessageParams).Text
+	}
+
+	harness.SendText(ctx, user, "/suggest")
+	harness.SendPhoto(ctx, user, "photo-file-id", "")
+	require.Len(t, repo.suggestions, 1)
+	code := repo.suggestions[0].RefCode
+	require.Regexp(t, `^S-[2-9A-HJ-NP-Z]{4}$`, code)
+	assert.Contains(t, lastReply(), "/status "+code, "the confirmation should give the reference code")
+
+	// Codes are accepted as typed, and only the suggester's own suggestions are found
+	harness.SendText(ctx, user, "/status "+strings.ToLower(strings.TrimPrefix(code, models.RefCodePrefix)))
+	assert.Contains(t, lastReply(), "is waiting for review")
+	harness.SendText(ctx, admin, "/status "+code)
+	assert.Contains(t, bot.CallsTo("SendMessage", admin.ID)[0].Params.(*telego.SendMessageParams).Text, "no suggestion with the code")
+	harness.SendText(ctx, admin, "/find "+code)
+	assert.Contains(t, bot.CallsTo("SendMessage", admin.ID)[1].Params.(*telego.SendMessageParams).Text, "From: @suggester (ID 42)")
+
+	harness.SendText(ctx, admin, "/review")
+	assert.Contains(t, bot.CallsTo("SendPhoto", admin.ID)[0].Params.(*telego.SendPhotoParams).Caption, utils.EscapeMarkdownV2(code), "the review should show the reference code")
+	harness.SendText(ctx, user, "/status "+code)
+	assert.Contains(t, lastReply(), "is being reviewed")
+
+	approveData, ok := telegoapitest.ButtonData(bot.CallsTo("SendPhoto", admin.ID)[0], ":approve:")
+	require.True(t, ok, "review message has no approve button")
+	harness.PressButton(ctx, admin, nil, approveData)
+	_, published := bot.WaitForCall("SendMediaGroup", testChannelID, 5*time.Second)
+	require.True(t, published, "suggestion was not published to the channel")
+	require.Eventually(t, func() bool {
+		harness.SendText(ctx, user, "/status "+code)
+		return strings.Contains(lastReply(), "https://t.me/c/123/")
+	}, 5*time.Second, 10*time.Millisecond, "status should link to the published post")
+}
+
+func TestPublishRetriesAfterRateLimit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := telegoapitest.NewFakeBot()
+	bot.RateLimitNext("SendMediaGroup", time.Second)
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+
+	done := make(chan error, 1)
+	queue.Submit(ctx, "suggestion:test", func(ctx context.Context) error {
+		_, err := bot.SendMediaGroup(ctx, &telego.SendMediaGroupParams{ChatID: telego.ChatID{ID: testChannelID}})
+		return err
+	}, func(ctx context.Context, err error) {
+		done <- err
+	})
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("publication did not finish")
+	}
+	assert.Len(t, bot.CallsTo("SendMediaGroup", testChannelID), 2, "rate-limited publication should be retried once")
+}
+
+func TestMediaGroupFallsBackToSingleItems(t *testing.T) {
+	bot := telegoapitest.NewFakeBot()
+	manager := NewManager(bot, &memorySuggestionRepo{}, testChannelID, staticAdminChecker{}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, publisher.NewQueue(time.Millisecond))
+	publication := newMediaGroupPublication(testChannelID, []telego.InputMedia{
+		&telego.InputMediaPhoto{Type: telego.MediaTypePhoto, Media: telego.InputFile{FileID: "a"}, Caption: "caption"},
+		&telego.InputMediaPhoto{Type: telego.MediaTypePhoto, Media: telego.InputFile{FileID: "b"}},
+		&telego.InputMediaPhoto{Type: telego.MediaTypePhoto, Media: telego.InputFile{FileID: "c"}},
+	})
+
+	bot.FailNext("SendMediaGroup", telegoapitest.APIError(400, "Bad Request: group send failed", 0))
+	bot.RateLimitNext("SendPhoto", time.Second)
+	bot.FailNext("SendPhoto", telegoapitest.APIError(400, "Bad Request: wrong file", 0))
+	err := manager.sendMediaGroup(context.Background(), publication)
+	_, limited := telegoapi.RetryAfter(err)
+	require.True(t, limited, "rate limiting should be left to the publish queue")
+
+	// The retried attempt continues one by one and doesn't send the group again
+	err = manager.sendMediaGroup(context.Background(), publication)
+	assert.ErrorIs(t, err, errMediaGroupIncomplete)
+	assert.Len(t, bot.CallsTo("SendMediaGroup", testChannelID), 1)
+	assert.Equal(t, []bool{false, true, true}, publication.published)
+
+	report := publication.report(locales.NewLocalizer())
+	assert.Contains(t, report, "Published: 2, 3")
+	assert.Contains(t, report, "Failed: 1")
+}
+
+func TestMediaGroupTransportErrorIsNotResent(t *testing.T) {
+	bot := telegoapitest.NewFakeBot()
+	manager := NewManager(bot, &memorySuggestionRepo{}, testChannelID, staticAdminChecker{}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, publisher.NewQueue(time.Millisecond))
+	publication := newMediaGroupPublication(testChannelID, []telego.InputMedia{
+		&telego.InputMediaPhoto{Type: telego.MediaTypePhoto, Media: telego.InputFile{FileID: "a"}},
+		&telego.InputMediaPhoto{Type: telego.MediaTypePhoto, Media: telego.InputFile{FileID: "b"}},
+	})
+
+	// The group may have been posted before the connection broke, so its items aren't sent again
+	bot.FailNext("SendMediaGroup", context.DeadlineExceeded)
+	err := manager.sendMediaGroup(context.Background(), publication)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, publication.fallback)
+	assert.Empty(t, bot.CallsTo("SendPhoto", testChannelID))
+}
+
+// failingEventRepo fails to record membership events.
+type failingEventRepo struct {
+	stubMembershipRepo
+}
+
+func (failingEventRepo) RecordMembershipEvent(ctx context.Context, event *models.MembershipEvent) error {
+	return errors.New("write conflict")
+}
+
+// countingUnitOfWork counts the units of work and the errors they returned.
+type countingUnitOfWork struct {
+	runs, failures int
+}
+
+func (u *countingUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	u.runs++
+	err := fn(ctx)
+	if err != nil {
+		u.failures++
+	}
+	return err
+}
+
+func TestChatMemberUpdateRunsInUnitOfWork(t *testing.T) {
+	queue := publisher.NewQueue(time.Millisecond)
+	manager := NewManager(telegoapitest.NewFakeBot(), &memorySuggestionRepo{}, testChannelID, staticAdminChecker{}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, failingEventRepo{}, queue)
+	uow := &countingUnitOfWork{}
+	manager.SetUnitOfWork(uow)
+
+	user := telegoapitest.User(42, "joiner")
+	err := manager.HandleChatMemberUpdate(context.Background(), telego.ChatMemberUpdated{
+		Chat:          telego.Chat{ID: testChannelID},
+		Date:          time.Now().Unix(),
+		OldChatMember: &telego.ChatMemberLeft{Status: telego.MemberStatusLeft, User: user},
+		NewChatMember: &telego.ChatMemberMember{Status: telego.MemberStatusMember, User: user},
+	})
+
+	// The failed event write fails the whole unit, so the saved status is rolled back with it
+	assert.ErrorContains(t, err, "failed to record membership event")
+	assert.Equal(t, 1, uow.runs)
+	assert.Equal(t, 1, uow.failures)
+}
+
+func TestSuggestRulesChecklist(t *testing.T) {
+	ctx := context.Background()
+
+	bot := telegoapitest.NewFakeBot()
+	user := telegoapitest.User(42, "suggester")
+	manager := NewManager(bot, &memorySuggestionRepo{}, testChannelID, staticAdminChecker{}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, publisher.NewQueue(time.Millisecond))
+	manager.SetRulesChecklist(true)
+	manager.rememberConsent(user.ID)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+
+	// /suggest shows the checklist and waits for the button before accepting content
+	harness.SendText(ctx, user, "/suggest")
+	assert.Equal(t, StateIdle, manager.GetUserState(user.ID))
+	messages := bot.CallsTo("SendMessage", user.ID)
+	require.NotEmpty(t, messages)
+	readData, ok := telegoapitest.ButtonData(messages[len(messages)-1], rulesReadData)
+	require.True(t, ok, "checklist has no rules button")
+
+	harness.PressButton(ctx, user, nil, readData)
+	assert.Equal(t, StateAwaitingSuggestion, manager.GetUserState(user.ID))
+	assert.Len(t, bot.CallsTo("SendMessage", user.ID), len(messages)+1, "content prompt was not sent")
+}
+
+func TestReviewerTagsArePublishedAsHashtags(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+
+	user := telegoapitest.User(42, "suggester")
+	admin := telegoapitest.User(7, "admin")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{admin.ID: true}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	manager.SetTags([]string{"#Cats", "low effort", "bad tag!"})
+	manager.rememberConsent(user.ID)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+
+	harness.SendText(ctx, user, "/suggest")
+	harness.SendPhoto(ctx, user, "photo-file-id", "")
+
+	harness.SendText(ctx, admin, "/review")
+	reviewPhotos := bot.CallsTo("SendPhoto", admin.ID)
+	require.Len(t, reviewPhotos, 1)
+	tagsData, ok := telegoapitest.ButtonData(reviewPhotos[0], ":tags:")
+	require.True(t, ok, "review message has no tags button")
+
+	// The picker lists the valid tags, and toggling one saves it
+	harness.PressButton(ctx, admin, nil, tagsData)
+	edits := bot.CallsTo("EditMessageReplyMarkup", admin.ID)
+	require.Len(t, edits, 1)
+	_, ok = telegoapitest.ButtonData(edits[0], tagCallbackPrefix)
+	require.True(t, ok, "tag picker was not shown")
+	picker := edits[0].Params.(*telego.EditMessageReplyMarkupParams).ReplyMarkup.InlineKeyboard
+	require.Len(t, picker, 2, "expected a row of tags and a done row")
+	assert.Len(t, picker[0], 2)
+	catsData := picker[0][0].CallbackData
+	harness.PressButton(ctx, admin, nil, catsData)
+	repo.mu.Lock()
+	assert.Equal(t, []string{"cats"}, repo.suggestions[0].Tags)
+	repo.mu.Unlock()
+
+	// "Done" brings back the review buttons
+	edits = bot.CallsTo("EditMessageReplyMarkup", admin.ID)
+	doneData, ok := telegoapitest.ButtonData(edits[len(edits)-1], ":"+tagDone)
+	require.True(t, ok, "tag picker has no done button")
+	harness.PressButton(ctx, admin, nil, doneData)
+	edits = bot.CallsTo("EditMessageReplyMarkup", admin.ID)
+	approveData, ok := telegoapitest.ButtonData(edits[len(edits)-1], ":approve:")
+	require.True(t, ok, "review buttons were not restored")
+
+	harness.PressButton(ctx, admin, nil, approveData)
+	call, published := bot.WaitForCall("SendMediaGroup", testChannelID, 5*time.Second)
+	require.True(t, published, "suggestion was not published to the channel")
+	photo, isPhoto := call.Params.(*telego.SendMediaGroupParams).Media[0].(*telego.InputMediaPhoto)
+	require.True(t, isPhoto)
+	assert.Equal(t, "#cats", photo.Caption)
+}
+
+func TestPreviewFirstThenPromote(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const previewChannelID = -100777
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+
+	user := telegoapitest.User(42, "suggester")
+	admin := telegoapitest.User(7, "admin")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{admin.ID: true}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	manager.SetPreviewChannelID(previewChannelID)
+	manager.rememberConsent(user.ID)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+
+	harness.SendText(ctx, user, "/suggest")
+	harness.SendPhoto(ctx, user, "photo-file-id", "")
+	harness.SendText(ctx, admin, "/review")
+	reviewPhotos := bot.CallsTo("SendPhoto", admin.ID)
+	require.Len(t, reviewPhotos, 1)
+	previewData, ok := telegoapitest.ButtonData(reviewPhotos[0], ":preview:")
+	require.True(t, ok, "review message has no preview button")
+
+	harness.PressButton(ctx, admin, nil, previewData)
+	_, posted := bot.WaitForCall("SendMediaGroup", previewChannelID, 5*time.Second)
+	require.True(t, posted, "suggestion was not posted to the preview channel")
+	assert.Empty(t, bot.CallsTo("SendMediaGroup", testChannelID), "preview must not reach the channel")
+
+	var promoteData string
+	require.Eventually(t, func() bool {
+		for _, call := range bot.CallsTo("SendMessage", admin.ID) {
+			if data, ok := telegoapitest.ButtonData(call, promoteCallbackPrefix); ok {
+				promoteData = data
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond, "Promote button was not sent")
+
+	harness.PressButton(ctx, admin, nil, promoteData)
+	call, promoted := bot.WaitForCall("CopyMessages", testChannelID, 5*time.Second)
+	require.True(t, promoted, "preview was not promoted to the channel")
+	params := call.Params.(*telego.CopyMessagesParams)
+	assert.Equal(t, int64(previewChannelID), params.FromChatID.ID)
+	assert.Len(t, params.MessageIDs, 1)
+}
+
+func TestTestSuggestionsAreNeverPreviewedOrPromoted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const previewChannelID = -100777
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+
+	admin := telegoapitest.User(7, "admin")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{admin.ID: true}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	manager.SetPreviewChannelID(previewChannelID)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+
+	// A test suggestion with a preview, as if it had been previewed before the guard
+	suggestion := &models.Suggestion{
+		RefCode:           "S-TEST",
+		FileIDs:           []string{"photo-file-id"},
+		Status:            string(models.StatusApproved),
+		Test:              true,
+		PreviewChatID:     previewChannelID,
+		PreviewMessageIDs: []int{1},
+	}
+	require.NoError(t, repo.CreateSuggestion(ctx, suggestion))
+
+	_, err := manager.previewSuggestion(ctx, suggestion, admin.ID, admin.ID)
+	require.NoError(t, err)
+	assert.Zero(t, queue.Pending())
+
+	harness.PressButton(ctx, admin, nil, promoteCallbackPrefix+suggestion.Ref())
+	assert.Zero(t, queue.Pending())
+	assert.Empty(t, bot.CallsTo("CopyMessages", testChannelID), "test suggestion must not reach the channel")
+	answers := bot.CallsTo("AnswerCallbackQuery", 0)
+	require.Len(t, answers, 1)
+	assert.True(t, answers[0].Params.(*telego.AnswerCallbackQueryParams).ShowAlert)
+}
+
+// memoryRubricRepo is an in-memory RubricRepository.
+type memoryRubricRepo struct {
+	mu      sync.Mutex
+	rubrics map[string]*models.Rubric
+}
+
+func (r *memoryRubricRepo) CreateRubric(ctx context.Context, rubric *models.Rubric) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.rubrics[rubric.Name]; ok {
+		return database.ErrRubricExists
+	}
+	stored := *rubric
+	r.rubrics[rubric.Name] = &stored
+	return nil
+}
+
+func (r *memoryRubricRepo) GetRubric(ctx context.Context, name string) (*models.Rubric, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rubric, ok := r.rubrics[name]
+	if !ok {
+		return nil, nil
+	}
+	found := *rubric
+	return &found, nil
+}
+
+func (r *memoryRubricRepo) ListRubrics(ctx context.Context) ([]models.Rubric, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var rubrics []models.Rubric
+	for _, rubric := range r.rubrics {
+		rubrics = append(rubrics, *rubric)
+	}
+	return rubrics, nil
+}
+
+func (r *memoryRubricRepo) DeleteRubric(ctx context.Context, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.rubrics, name)
+	return nil
+}
+
+func (r *memoryRubricRepo) SetRubricCounter(ctx context.Context, name string, counter int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rubrics[name].Counter = counter
+	return nil
+}
+
+func (r *memoryRubricRepo) SetRubricReaction(ctx context.Context, name, emoji string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rubrics[name].Reaction = emoji
+	return nil
+}
+
+func (r *memoryRubricRepo) SetRubricPrompt(ctx context.Context, name, prompt string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rubrics[name].Prompt = prompt
+	return nil
+}
+
+func (r *memoryRubricRepo) NextRubricNumber(ctx context.Context, name string) (*models.Rubric, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rubric, ok := r.rubrics[name]
+	if !ok {
+		return nil, database.ErrRubricNotFound
+	}
+	rubric.Counter++
+	numbered := *rubric
+	return &numbered, nil
+}
+
+func TestRubricNumbersPublishedSuggestion(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	rubrics := &memoryRubricRepo{rubrics: map[string]*models.Rubric{
+		"meme_of_the_day": {Name: "meme_of_the_day", Template: "#meme_of_the_day No.{number}", Counter: 41, Reaction: "🔥", Prompt: "Rate it!"},
+	}}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+
+	user := telegoapitest.User(42, "suggester")
+	admin := telegoapitest.User(7, "admin")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{admin.ID: true}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	manager.SetRubricRepository(rubrics)
+	prompter := engagement.NewPrompter(bot, testChannelID, rubrics)
+	manager.SetEngagementPrompter(prompter)
+	manager.rememberConsent(user.ID)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+
+	harness.SendText(ctx, user, "/suggest")
+	harness.SendPhoto(ctx, user, "photo-file-id", "")
+	harness.SendText(ctx, admin, "/review")
+	reviewPhotos := bot.CallsTo("SendPhoto", admin.ID)
+	require.Len(t, reviewPhotos, 1)
+	rubricData, ok := telegoapitest.ButtonData(reviewPhotos[0], ":rubric:")
+	require.True(t, ok, "review message has no rubric button")
+
+	// Picking a rubric saves it and brings back the review buttons
+	harness.PressButton(ctx, admin, nil, rubricData)
+	edits := bot.CallsTo("EditMessageReplyMarkup", admin.ID)
+	require.Len(t, edits, 1)
+	pickData, ok := telegoapitest.ButtonData(edits[0], rubricCallbackPrefix)
+	require.True(t, ok, "rubric picker was not shown")
+	assert.True(t, strings.HasSuffix(pickData, ":meme_of_the_day"))
+	harness.PressButton(ctx, admin, nil, pickData)
+	repo.mu.Lock()
+	assert.Equal(t, "meme_of_the_day", repo.suggestions[0].Rubric)
+	repo.mu.Unlock()
+
+	edits = bot.CallsTo("EditMessageReplyMarkup", admin.ID)
+	approveData, ok := telegoapitest.ButtonData(edits[len(edits)-1], ":approve:")
+	require.True(t, ok, "review buttons were not restored")
+	harness.PressButton(ctx, admin, nil, approveData)
+
+	call, published := bot.WaitForCall("SendMediaGroup", testChannelID, 5*time.Second)
+	require.True(t, published, "suggestion was not published to the channel")
+	photo, isPhoto := call.Params.(*telego.SendMediaGroupParams).Media[0].(*telego.InputMediaPhoto)
+	require.True(t, isPhoto)
+	assert.Equal(t, "#meme_of_the_day No.42", photo.Caption)
+
+	// The rubric's reaction is set on the post, and its comment goes under the discussion group copy
+	call, reacted := bot.WaitForCall("SetMessageReaction", testChannelID, 5*time.Second)
+	require.True(t, reacted, "the rubric reaction was not set")
+	reaction := call.Params.(*telego.SetMessageReactionParams)
+	assert.Equal(t, "🔥", reaction.Reaction[0].(*telego.ReactionTypeEmoji).Emoji)
+	const discussionGroupID = -1002
+	commented, err := prompter.HandleAutomaticForward(ctx, telego.Message{
+		MessageID:          77,
+		Chat:               telego.Chat{ID: discussionGroupID, Type: telego.ChatTypeSupergroup},
+		IsAutomaticForward: true,
+		ForwardOrigin:      &telego.MessageOriginChannel{Chat: telego.Chat{ID: testChannelID}, MessageID: reaction.MessageID},
+	})
+	require.NoError(t, err)
+	assert.True(t, commented)
+	comments := bot.CallsTo("SendMessage", discussionGroupID)
+	require.Len(t, comments, 1)
+	comment := comments[0].Params.(*telego.SendMessageParams)
+	assert.Equal(t, "Rate it!", comment.Text)
+	assert.Equal(t, 77, comment.ReplyParameters.MessageID)
+}
+
+// memorySettingsRepo is an in-memory SettingsRepository.
+type memorySettingsRepo map[string]string
+
+func (r memorySettingsRepo) GetSetting(ctx context.Context, key string) (*models.Setting, error) {
+	value, ok := r[key]
+	if !ok {
+		return nil, nil
+	}
+	return &models.Setting{Key: key, Value: value}, nil
+}
+
+func (r memorySettingsRepo) SetSetting(ctx context.Context, key, value string, updatedBy int64) error {
+	r[key] = value
+	return nil
+}
+
+func (r memorySettingsRepo) DeleteSetting(ctx context.Context, key string) error {
+	delete(r, key)
+	return nil
+}
+
+func TestQuietHours(t *testing.T) {
+	quiet, err := ParseQuietHours("23:00-08:00")
+	require.NoError(t, err)
+	assert.Equal(t, "23:00-08:00", quiet.String())
+	moscow := time.FixedZone("MSK", 3*60*60)
+	assert.True(t, quiet.Contains(time.Date(2025, 1, 1, 23, 30, 0, 0, moscow), moscow))
+	assert.True(t, quiet.Contains(time.Date(2025, 1, 1, 7, 59, 0, 0, moscow), moscow))
+	assert.False(t, quiet.Contains(time.Date(2025, 1, 1, 8, 0, 0, 0, moscow), moscow))
+	// 20:30 UTC is 23:30 in Moscow
+	assert.True(t, quiet.Contains(time.Date(2025, 1, 1, 20, 30, 0, 0, time.UTC), moscow))
+	for _, invalid := range []string{"", "23:00", "25:00-08:00", "08:00-08:00"} {
+		_, err := ParseQuietHours(invalid)
+		assert.ErrorIs(t, err, ErrQuietHoursInvalid, invalid)
+	}
+}
+
+// refreshCountingAdminChecker counts how often the admin list is reloaded.
+type refreshCountingAdminChecker struct {
+	staticAdminChecker
+	refreshes int
+}
+
+func (c *refreshCountingAdminChecker) Refresh(ctx context.Context) ([]int64, error) {
+	c.refreshes++
+	return c.staticAdminChecker.Refresh(ctx)
+}
+
+func TestNewSuggestionPingsHeldBackDuringQuietHours(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bot := telegoapitest.NewFakeBot()
+	settings := memorySettingsRepo{}
+	user := telegoapitest.User(42, "suggester")
+	admin := telegoapitest.User(7, "admin")
+	adminChecker := &refreshCountingAdminChecker{staticAdminChecker: staticAdminChecker{admin.ID: true}}
+	manager := NewManager(bot, &memorySuggestionRepo{}, testChannelID, adminChecker, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, publisher.NewQueue(time.Millisecond))
+	manager.SetSettingsRepository(settings)
+	manager.SetNewSuggestionPings(true)
+	manager.rememberConsent(user.ID)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+
+	// Quiet hours from an hour ago to an hour from now
+	now := time.Now().UTC()
+	sinceMidnight := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute
+	day := 24 * time.Hour
+	quiet := &QuietHours{Start: (sinceMidnight - time.Hour + day) % day, End: (sinceMidnight + time.Hour) % day}
+	require.NoError(t, manager.SetQuietHours(ctx, admin.ID, quiet))
+	assert.Equal(t, quiet.String(), settings[models.QuietHoursSettingKey(admin.ID)])
+
+	for _, caption := range []string{"first", "second"} {
+		harness.SendText(ctx, user, "/suggest")
+		harness.SendPhoto(ctx, user, "photo-file-id", caption)
+	}
+	assert.Empty(t, bot.CallsTo("SendMessage", admin.ID), "ping was sent during quiet hours")
+	assert.Equal(t, 1, adminChecker.refreshes, "the admin list should be reused between pings")
+	assert.Contains(t, settings, models.SettingHeldNotifications)
+
+	// Still quiet: nothing is sent yet
+	manager.sendQuietHoursSummaries(ctx, now)
+	assert.Empty(t, bot.CallsTo("SendMessage", admin.ID))
+
+	// The held back pings survive a restart
+	bot = telegoapitest.NewFakeBot()
+	manager = NewManager(bot, &memorySuggestionRepo{}, testChannelID, adminChecker, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, publisher.NewQueue(time.Millisecond))
+	manager.SetSettingsRepository(settings)
+	manager.StartQuietHoursSummaries(ctx)
+
+	// After the quiet hours the held back pings arrive as a summary
+	manager.sendQuietHoursSummaries(ctx, now.Add(2*time.Hour))
+	summaries := bot.CallsTo("SendMessage", admin.ID)
+	require.Len(t, summaries, 1)
+	assert.Contains(t, summaries[0].Params.(*telego.SendMessageParams).Text, "2 notifications")
+	assert.NotContains(t, settings, models.SettingHeldNotifications)
+	manager.sendQuietHoursSummaries(ctx, now.Add(2*time.Hour))
+	assert.Len(t, bot.CallsTo("SendMessage", admin.ID), 1, "summary was sent twice")
+}
+
+func TestReviewReminderSnoozeAndDismiss(t *testing.T) {
+	ctx := context.Background()
+	bot := telegoapitest.NewFakeBot()
+	user := telegoapitest.User(42, "suggester")
+	admin := telegoapitest.User(7, "admin")
+	manager := NewManager(bot, &memorySuggestionRepo{}, testChannelID, staticAdminChecker{admin.ID: true}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, publisher.NewQueue(time.Millisecond))
+	manager.SetReviewReminderAfter(10 * time.Minute)
+	manager.rememberConsent(user.ID)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+
+	harness.SendText(ctx, user, "/suggest")
+	harness.SendPhoto(ctx, user, "photo-file-id", "")
+	harness.SendText(ctx, admin, "/review")
+	messagesBefore := len(bot.CallsTo("SendMessage", admin.ID))
+
+	// Not idle long enough yet
+	now := time.Now()
+	manager.sendReviewReminders(ctx, now)
+	assert.Len(t, bot.CallsTo("SendMessage", admin.ID), messagesBefore)
+
+	manager.reviewSessionsMutex.Lock()
+	manager.reviewSessions[admin.ID].LastActivityAt = now.Add(-11 * time.Minute)
+	manager.reviewSessionsMutex.Unlock()
+	manager.sendReviewReminders(ctx, now)
+	manager.sendReviewReminders(ctx, now)
+	messages := bot.CallsTo("SendMessage", admin.ID)
+	require.Len(t, messages, messagesBefore+1, "expected exactly one reminder")
+	snoozeData, ok := telegoapitest.ButtonData(messages[len(messages)-1], reviewSessionSnoozeData)
+	require.True(t, ok, "reminder has no snooze button")
+
+	// A snoozed reminder comes back after the snooze, and the session is kept meanwhile
+	harness.PressButton(ctx, admin, nil, snoozeData)
+	manager.sendReviewReminders(ctx, now.Add(30*time.Minute))
+	assert.Len(t, bot.CallsTo("SendMessage", admin.ID), messagesBefore+1)
+	_, active := manager.activeReviewSession(admin.ID)
+	assert.True(t, active)
+	manager.sendReviewReminders(ctx, now.Add(ReviewReminderSnooze+time.Minute))
+	messages = bot.CallsTo("SendMessage", admin.ID)
+	require.Len(t, messages, messagesBefore+2, "snoozed reminder was not sent again")
+
+	// Dismissing ends the session and releases its suggestions
+	dismissData, ok := telegoapitest.ButtonData(messages[len(messages)-1], reviewSessionDismissData)
+	require.True(t, ok, "reminder has no dismiss button")
+	harness.PressButton(ctx, admin, nil, dismissData)
+	_, active = manager.activeReviewSession(admin.ID)
+	assert.False(t, active)
+	manager.reviewSessionsMutex.RLock()
+	assert.Empty(t, manager.claimedSuggestions)
+	manager.reviewSessionsMutex.RUnlock()
+}
+
+func TestIntakeGroupPhotoBecomesSuggestion(t *testing.T) {
+	ctx := context.Background()
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, publisher.NewQueue(time.Millisecond))
+	const intakeChatID int64 = -100777
+	manager.SetIntakeChatID(intakeChatID)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+
+	poster := telegoapitest.User(42, "poster")
+	groupMessage := func(from telego.User, mutate func(*telego.Message)) {
+		msg := telego.Message{
+			MessageID: 500,
+			Date:      time.Now().Unix(),
+			Chat:      telego.Chat{ID: intakeChatID, Type: telego.ChatTypeSupergroup},
+			From:      &from,
+		}
+		mutate(&msg)
+		harness.Inject(ctx, telego.Update{Message: &msg})
+	}
+
+	// Text and posts from bots are ignored
+	groupMessage(poster, func(msg *telego.Message) { msg.Text = "nice channel" })
+	groupMessage(telego.User{ID: 99, IsBot: true}, func(msg *telego.Message) {
+		msg.Photo = []telego.PhotoSize{{FileID: "bot-photo"}}
+	})
+	assert.Empty(t, repo.suggestions)
+
+	groupMessage(poster, func(msg *telego.Message) {
+		msg.Photo = []telego.PhotoSize{{FileID: "small"}, {FileID: "large"}}
+		msg.Caption = "look"
+	})
+	require.Len(t, repo.suggestions, 1)
+	suggestion := repo.suggestions[0]
+	assert.Equal(t, poster.ID, suggestion.SuggesterID)
+	assert.Equal(t, poster.ID, suggestion.ChatID)
+	assert.Equal(t, []string{"large"}, suggestion.FileIDs)
+	assert.Equal(t, "look", suggestion.Caption)
+	assert.Equal(t, string(StatusPending), suggestion.Status)
+	assert.NotEmpty(t, bot.CallsTo("SendMessage", poster.ID), "poster was not told about the suggestion")
+	assert.Empty(t, bot.CallsTo("SendMessage", intakeChatID))
+}
+
+func TestMergeDuplicateCreditsBothSuggesters(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+
+	first := telegoapitest.User(42, "first")
+	second := telegoapitest.User(43, "second")
+	admin := telegoapitest.User(7, "admin")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{admin.ID: true}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	manager.SetCreditSuggesters(true)
+	manager.SetCreditCoSuggesters(true)
+	uow := &countingUnitOfWork{}
+	manager.SetUnitOfWork(uow)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+	for _, user := range []telego.User{first, second} {
+		manager.rememberConsent(user.ID)
+		harness.SendText(ctx, user, "/suggest")
+		harness.SendPhoto(ctx, user, "same-meme", "")
+	}
+
+	harness.SendText(ctx, admin, "/review")
+	reviewPhotos := bot.CallsTo("SendPhoto", admin.ID)
+	require.Len(t, reviewPhotos, 1)
+	mergeData, ok := telegoapitest.ButtonData(reviewPhotos[0], ":merge:")
+	require.True(t, ok, "review message has no merge button")
+
+	// The picker lists the other suggestion of the batch and a back button
+	harness.PressButton(ctx, admin, nil, mergeData)
+	edits := bot.CallsTo("EditMessageReplyMarkup", admin.ID)
+	require.Len(t, edits, 1)
+	picker := edits[0].Params.(*telego.EditMessageReplyMarkupParams).ReplyMarkup.InlineKeyboard
+	require.Len(t, picker, 2)
+	harness.PressButton(ctx, admin, nil, picker[0][0].CallbackData)
+
+	// The duplicate is rejected and its suggester added to the kept suggestion in one unit of work
+	assert.Equal(t, 1, uow.runs)
+	repo.mu.Lock()
+	kept, duplicate := repo.suggestions[0], repo.suggestions[1]
+	assert.Equal(t, string(StatusRejected), duplicate.Status)
+	assert.Equal(t, models.RejectionReasonDuplicate, duplicate.RejectionReason)
+	assert.Equal(t, kept.ID, duplicate.DuplicateOf)
+	require.Len(t, kept.CoSuggesters, 1)
+	assert.Equal(t, second.ID, kept.CoSuggesters[0].SuggesterID)
+	repo.mu.Unlock()
+
+	// The kept suggestion is shown again, without the merge button now that it is alone
+	reviewPhotos = bot.CallsTo("SendPhoto", admin.ID)
+	require.Len(t, reviewPhotos, 2)
+	_, ok = telegoapitest.ButtonData(reviewPhotos[1], ":merge:")
+	assert.False(t, ok)
+	approveData, ok := telegoapitest.ButtonData(reviewPhotos[1], ":approve:")
+	require.True(t, ok, "review message has no approve button")
+
+	harness.PressButton(ctx, admin, nil, approveData)
+	call, published := bot.WaitForCall("SendMediaGroup", testChannelID, 5*time.Second)
+	require.True(t, published, "suggestion was not published to the channel")
+	photo, isPhoto := call.Params.(*telego.SendMediaGroupParams).Media[0].(*telego.InputMediaPhoto)
+	require.True(t, isPhoto)
+	assert.Contains(t, photo.Caption, "@first, @second")
+}
+
+// fixedTextRecognizer "recognizes" the text mapped to each file ID.
+type fixedTextRecognizer map[string]string
+
+func (r fixedTextRecognizer) ReadFile(ctx context.Context, fileID string) (string, error) {
+	return r[fileID], nil
+}
+
+func TestRecognizedTextFlagsDuplicatesAndIsSearchable(t *testing.T) {
+	ctx := context.Background()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	admin := telegoapitest.User(7, "admin")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{admin.ID: true}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, publisher.NewQueue(time.Millisecond))
+	manager.SetTextRecognizer(fixedTextRecognizer{
+		"first-copy":  "When the code\nCOMPILES on the first try!",
+		"second-copy": "when the code compiles on the first try",
+	})
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+
+	for i, fileID := range []string{"first-copy", "second-copy"} {
+		user := telegoapitest.User(int64(42+i), "suggester")
+		manager.rememberConsent(user.ID)
+		harness.SendText(ctx, user, "/suggest")
+		harness.SendPhoto(ctx, user, fileID, "")
+		require.Eventually(t, func() bool {
+			repo.mu.Lock()
+			defer repo.mu.Unlock()
+			return len(repo.suggestions) == i+1 && repo.suggestions[i].OCRText != ""
+		}, 5*time.Second, 10*time.Millisecond, "text of %s was not recognized", fileID)
+	}
+
+	// The second copy is flagged as having the same text as the first, whatever the case and punctuation
+	repo.mu.Lock()
+	first, second := *repo.suggestions[0], *repo.suggestions[1]
+	repo.mu.Unlock()
+	assert.Empty(t, first.SimilarTo)
+	assert.Equal(t, first.Ref(), second.SimilarTo)
+
+	// /find searches the recognized text when given something other than a code
+	harness.SendText(ctx, admin, "/find first try")
+	replies := bot.CallsTo("SendMessage", admin.ID)
+	require.NotEmpty(t, replies)
+	text := replies[len(replies)-1].Params.(*telego.SendMessageParams).Text
+	assert.Contains(t, text, first.RefCode)
+	assert.Contains(t, text, second.RefCode)
+}
+
+func TestReviewerSwitchesContentProtection(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+
+	user := telegoapitest.User(42, "suggester")
+	admin := telegoapitest.User(7, "admin")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{admin.ID: true}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+	manager.rememberConsent(user.ID)
+	harness.SendText(ctx, user, "/suggest")
+	harness.SendPhoto(ctx, user, "meme", "")
+
+	harness.SendText(ctx, admin, "/review")
+	reviewPhotos := bot.CallsTo("SendPhoto", admin.ID)
+	require.Len(t, reviewPhotos, 1)
+	protectData, ok := telegoapitest.ButtonData(reviewPhotos[0], ":lock:")
+	require.True(t, ok, "review message has no content protection button")
+
+	// The channel doesn't protect content, so the button protects this post
+	harness.PressButton(ctx, admin, nil, protectData)
+	repo.mu.Lock()
+	require.NotNil(t, repo.suggestions[0].ProtectContent)
+	assert.True(t, *repo.suggestions[0].ProtectContent)
+	repo.mu.Unlock()
+
+	approveData, ok := telegoapitest.ButtonData(reviewPhotos[0], ":approve:")
+	require.True(t, ok, "review message has no approve button")
+	harness.PressButton(ctx, admin, nil, approveData)
+	call, published := bot.WaitForCall("SendMediaGroup", testChannelID, 5*time.Second)
+	require.True(t, published, "suggestion was not published to the channel")
+	assert.True(t, call.Params.(*telego.SendMediaGroupParams).ProtectContent)
+}
+
+// germanTranslator "translates" German captions by looking them up.
+type germanTranslator map[string]string
+
+func (g germanTranslator) Translate(ctx context.Context, text, target string) (translate.Translation, error) {
+	return translate.Translation{Text: g[text], SourceLanguage: "de"}, nil
+}
+
+func TestReviewerPublishesTranslatedCaption(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+
+	user := telegoapitest.User(42, "suggester")
+	admin := telegoapitest.User(7, "admin")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{admin.ID: true}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	manager.SetTranslator(germanTranslator{"Wenn der Code kompiliert": "When the code compiles"})
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+	manager.rememberConsent(user.ID)
+	harness.SendText(ctx, user, "/suggest")
+	harness.SendPhoto(ctx, user, "meme", "Wenn der Code kompiliert")
+	require.Eventually(t, func() bool {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		return len(repo.suggestions) == 1 && repo.suggestions[0].TranslatedCaption != ""
+	}, 5*time.Second, 10*time.Millisecond, "caption was not translated")
+
+	// The review message shows the translation next to the original
+	harness.SendText(ctx, admin, "/review")
+	reviewPhotos := bot.CallsTo("SendPhoto", admin.ID)
+	require.Len(t, reviewPhotos, 1)
+	caption := reviewPhotos[0].Params.(*telego.SendPhotoParams).Caption
+	assert.Contains(t, caption, "Wenn der Code kompiliert")
+	assert.Contains(t, caption, "When the code compiles")
+
+	// The button switches from no caption to the original, then to the translation
+	captionData, ok := telegoapitest.ButtonData(reviewPhotos[0], ":lang:")
+	require.True(t, ok, "review message has no caption button")
+	harness.PressButton(ctx, admin, nil, captionData)
+	harness.PressButton(ctx, admin, nil, captionData)
+	repo.mu.Lock()
+	assert.Equal(t, models.CaptionChoiceTranslation, repo.suggestions[0].CaptionChoice)
+	repo.mu.Unlock()
+
+	approveData, ok := telegoapitest.ButtonData(reviewPhotos[0], ":approve:")
+	require.True(t, ok, "review message has no approve button")
+	harness.PressButton(ctx, admin, nil, approveData)
+	call, published := bot.WaitForCall("SendMediaGroup", testChannelID, 5*time.Second)
+	require.True(t, published, "suggestion was not published to the channel")
+	media := call.Params.(*telego.SendMediaGroupParams).Media[0].(*telego.InputMediaPhoto)
+	assert.Contains(t, media.Caption, "When the code compiles")
+	assert.NotContains(t, media.Caption, "Wenn")
+}
+
+type fixedDownloader []byte
+
+func (d fixedDownloader) Download(ctx context.Context, fileID string) ([]byte, error) {
+	return d, nil
+}
+
+func TestQueueExportAndApplyDecisions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+
+	user := telegoapitest.User(42, "suggester")
+	admin := telegoapitest.User(7, "admin")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{admin.ID: true}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	manager.SetFileDownloader(fixedDownloader("\x89PNG\r\n\x1a\n0000"))
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+	manager.rememberConsent(user.ID)
+	for _, caption := range []string{"first", "second"} {
+		harness.SendText(ctx, user, "/suggest")
+		harness.SendPhoto(ctx, user, caption, "")
+	}
+	repo.mu.Lock()
+	require.Len(t, repo.suggestions, 2)
+	approved, rejected := repo.suggestions[0].RefCode, repo.suggestions[1].RefCode
+	repo.mu.Unlock()
+
+	harness.SendText(ctx, admin, "/queue export")
+	documents := bot.CallsTo("SendDocument", admin.ID)
+	require.Len(t, documents, 1)
+	assert.Contains(t, documents[0].Params.(*telego.SendDocumentParams).Caption, "2 of 2")
+
+	harness.SendText(ctx, admin, "/apply "+approved+":a "+strings.ToLower(rejected)+":r nonsense")
+	_, published := bot.WaitForCall("SendMediaGroup", testChannelID, 5*time.Second)
+	require.True(t, published, "approved suggestion was not published to the channel")
+	repo.mu.Lock()
+	assert.Equal(t, string(StatusApproved), repo.suggestions[0].Status)
+	assert.Equal(t, string(StatusRejected), repo.suggestions[1].Status)
+	repo.mu.Unlock()
+	replies := bot.CallsTo("SendMessage", admin.ID)
+	report := replies[len(replies)-1].Params.(*telego.SendMessageParams).Text
+	assert.Contains(t, report, "nonsense")
+
+	// Decisions aren't applied twice
+	harness.SendText(ctx, admin, "/apply "+approved+":r")
+	replies = bot.CallsTo("SendMessage", admin.ID)
+	assert.Contains(t, replies[len(replies)-1].Params.(*telego.SendMessageParams).Text, "already reviewed")
+}
+
+// memoryWordFilterRepo is an in-memory WordFilterRepository with fixed words that records hits.
+type memoryWordFilterRepo struct {
+	database.WordFilterRepository
+	words []models.FilteredWord
+	mu    sync.Mutex
+	hits  []models.FilterHit
+}
+
+func (r *memoryWordFilterRepo) ListFilteredWords(ctx context.Context) ([]models.FilteredWord, error) {
+	return r.words, nil
+}
+
+func (r *memoryWordFilterRepo) LogFilterHits(ctx context.Context, hits []models.FilterHit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hits = append(r.hits, hits...)
+	return nil
+}
+
+func TestWordFilterCensorsAndBlocksCaptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+
+	user := telegoapitest.User(42, "suggester")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	wordFilter := &memoryWordFilterRepo{words: []models.FilteredWord{
+		{Word: "dumb*", Severity: "censor"},
+		{Word: "casino", Severity: "block"},
+	}}
+	manager.SetWordFilterRepository(wordFilter)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+	manager.rememberConsent(user.ID)
+
+	// Censored words are masked and shown to reviewers
+	harness.SendText(ctx, user, "/suggest")
+	harness.SendPhoto(ctx, user, "meme", "Dumbest bug ever")
+	require.Len(t, repo.suggestions, 1)
+	assert.Equal(t, "*** bug ever", repo.suggestions[0].Caption)
+	assert.Equal(t, []string{"dumb*"}, repo.suggestions[0].FilteredWords)
+	assert.Equal(t, string(StatusPending), repo.suggestions[0].Status)
+
+	// Blocked words reject the suggestion
+	harness.SendText(ctx, user, "/suggest")
+	harness.SendPhoto(ctx, user, "spam", "Best casino in town")
+	require.Len(t, repo.suggestions, 2)
+	assert.Equal(t, string(StatusRejected), repo.suggestions[1].Status)
+	assert.Equal(t, RuleWordFilter, repo.suggestions[1].RejectionReason)
+	replies := bot.CallsTo("SendMessage", user.ID)
+	assert.Contains(t, replies[len(replies)-1].Params.(*telego.SendMessageParams).Text, "words that aren't allowed")
+
+	wordFilter.mu.Lock()
+	defer wordFilter.mu.Unlock()
+	require.Len(t, wordFilter.hits, 2)
+	assert.Equal(t, "Dumbest", wordFilter.hits[0].Text)
+	assert.Equal(t, models.FilterSourceSuggestion, wordFilter.hits[1].Source)
+}
+
+func TestExpiryRejectsOldPendingSuggestions(t *testing.T) {
+	ctx := context.Background()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, publisher.NewQueue(time.Millisecond))
+	manager.SetSuggestionExpiry(30*24*time.Hour, true)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+	users := []telego.User{telegoapitest.User(42, "old"), telegoapitest.User(43, "claimed"), telegoapitest.User(44, "fresh")}
+	for _, user := range users {
+		manager.rememberConsent(user.ID)
+		harness.SendText(ctx, user, "/suggest")
+		harness.SendPhoto(ctx, user, "meme-"+user.Username, "")
+	}
+
+	now := time.Now()
+	repo.mu.Lock()
+	require.Len(t, repo.suggestions, 3)
+	old, claimed, fresh := repo.suggestions[0], repo.suggestions[1], repo.suggestions[2]
+	old.SubmittedAt = now.AddDate(0, 0, -40)
+	claimed.SubmittedAt = now.AddDate(0, 0, -40)
+	fresh.SubmittedAt = now.AddDate(0, 0, -10)
+	repo.mu.Unlock()
+	manager.claimedSuggestions[claimed.ID] = 7
+
+	manager.expireStale(ctx, now)
+
+	repo.mu.Lock()
+	assert.Equal(t, string(StatusRejected), old.Status)
+	assert.Equal(t, models.RejectionReasonExpired, old.RejectionReason)
+	assert.Equal(t, string(StatusPending), claimed.Status, "suggestions in a review session don't expire")
+	assert.Equal(t, string(StatusPending), fresh.Status)
+	repo.mu.Unlock()
+
+	messages := bot.CallsTo("SendMessage", users[0].ID)
+	require.NotEmpty(t, messages)
+	notice := messages[len(messages)-1].Params.(*telego.SendMessageParams).Text
+	assert.Contains(t, notice, "has expired")
+	assert.Contains(t, notice, old.Ref())
+	for _, user := range users[1:] {
+		for _, call := range bot.CallsTo("SendMessage", user.ID) {
+			assert.NotContains(t, call.Params.(*telego.SendMessageParams).Text, "has expired")
+		}
+	}
+}
+
+func TestReopenPutsRejectedSuggestionBackInQueue(t *testing.T) {
+	ctx := context.Background()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	user := telegoapitest.User(42, "suggester")
+	admin := telegoapitest.User(7, "admin")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{admin.ID: true}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, publisher.NewQueue(time.Millisecond))
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+	manager.rememberConsent(user.ID)
+	for _, caption := range []string{"first", "second"} {
+		harness.SendText(ctx, user, "/suggest")
+		harness.SendPhoto(ctx, user, caption, "")
+	}
+	repo.mu.Lock()
+	first, second := repo.suggestions[0], repo.suggestions[1]
+	repo.mu.Unlock()
+
+	harness.SendText(ctx, admin, "/review")
+	reviewPhotos := bot.CallsTo("SendPhoto", admin.ID)
+	require.NotEmpty(t, reviewPhotos)
+	rejectData, ok := telegoapitest.ButtonData(reviewPhotos[0], ":reject:")
+	require.True(t, ok, "review message has no reject button")
+	harness.PressButton(ctx, admin, nil, rejectData)
+	reviewPhotos = bot.CallsTo("SendPhoto", admin.ID)
+	approveData, ok := telegoapitest.ButtonData(reviewPhotos[len(reviewPhotos)-1], ":approve:")
+	require.True(t, ok, "review message has no approve button")
+	harness.PressButton(ctx, admin, nil, approveData)
+	repo.mu.Lock()
+	require.Equal(t, string(StatusRejected), first.Status)
+	require.Equal(t, string(StatusApproved), second.Status)
+	repo.mu.Unlock()
+
+	// /find shows the rejected suggestion with a Re-open button
+	harness.SendText(ctx, admin, "/find "+first.RefCode)
+	replies := bot.CallsTo("SendMessage", admin.ID)
+	reopenData, ok := telegoapitest.ButtonData(replies[len(replies)-1], reopenCallbackPrefix)
+	require.True(t, ok, "rejected suggestion has no Re-open button")
+	harness.PressButton(ctx, admin, nil, reopenData)
+	repo.mu.Lock()
+	assert.Equal(t, string(StatusPending), first.Status)
+	assert.Zero(t, first.ReviewedBy)
+	assert.False(t, first.ReopenedAt.IsZero())
+	require.Len(t, first.History, 2)
+	assert.Equal(t, models.StatusChange{From: string(StatusRejected), To: string(StatusPending), By: admin.ID, At: first.ReopenedAt}, first.History[1])
+	repo.mu.Unlock()
+
+	// The review shows how the suggestion got back in the queue
+	harness.SendText(ctx, admin, "/review")
+	reviewPhotos = bot.CallsTo("SendPhoto", admin.ID)
+	caption := reviewPhotos[len(reviewPhotos)-1].Params.(*telego.SendPhotoParams).Caption
+	assert.Contains(t, caption, "History: rejected by admin 7, just now → re\\-opened by admin 7, just now")
+
+	// Approved suggestions can't be reopened
+	harness.SendText(ctx, admin, "/reopen "+strings.ToLower(second.RefCode))
+	replies = bot.CallsTo("SendMessage", admin.ID)
+	assert.Contains(t, replies[len(replies)-1].Params.(*telego.SendMessageParams).Text, "isn't rejected")
+}
+
+func TestEditCaptionFromMySuggestions(t *testing.T) {
+	ctx := context.Background()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	user := telegoapitest.User(42, "suggester")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, publisher.NewQueue(time.Millisecond))
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+	manager.rememberConsent(user.ID)
+	harness.SendText(ctx, user, "/suggest")
+	harness.SendPhoto(ctx, user, "old caption", "")
+	require.Len(t, repo.suggestions, 1)
+
+	harness.SendText(ctx, user, "/mysuggestions")
+	replies := bot.CallsTo("SendMessage", user.ID)
+	editData, ok := telegoapitest.ButtonData(replies[len(replies)-1], ":caption")
+	require.True(t, ok, "/mysuggestions has no Edit caption button")
+	harness.PressButton(ctx, user, nil, editData)
+	assert.Equal(t, StateEditingCaption, manager.GetUserState(user.ID))
+
+	harness.SendText(ctx, user, "new caption")
+	repo.mu.Lock()
+	assert.Equal(t, "new caption", repo.suggestions[0].Caption)
+	repo.mu.Unlock()
+	assert.Equal(t, StateIdle, manager.GetUserState(user.ID))
+}
+
+// memoryMembershipRepo keeps the stored memberships of one chat by user.
+type memoryMembershipRepo struct {
+	stubMembershipRepo
+	members map[int64]models.ChannelMember
+}
+
+func (r *memoryMembershipRepo) SaveMemberStatus(ctx context.Context, member models.ChannelMember) error {
+	r.members[member.UserID] = member
+	return nil
+}
+
+func (r *memoryMembershipRepo) GetMemberStatus(ctx context.Context, chatID, userID int64) (*models.ChannelMember, error) {
+	member, ok := r.members[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &member, nil
+}
+
+func TestStoredMembershipIsTrustedForItsTTL(t *testing.T) {
+	ctx := context.Background()
+	bot := telegoapitest.NewFakeBot()
+	members := &memoryMembershipRepo{members: map[int64]models.ChannelMember{}}
+	manager := NewManager(bot, &memorySuggestionRepo{}, testChannelID, staticAdminChecker{}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, members, publisher.NewQueue(time.Millisecond))
+	manager.SetSubscriptionCacheTTL(0, 0)
+	manager.SetMemberStatusTTL(time.Hour)
+
+	const fresh, stale int64 = 42, 43
+	members.members[fresh] = models.ChannelMember{ChatID: testChannelID, UserID: fresh, Status: telego.MemberStatusMember, UpdatedAt: time.Now()}
+	members.members[stale] = models.ChannelMember{ChatID: testChannelID, UserID: stale, Status: telego.MemberStatusMember, UpdatedAt: time.Now().Add(-2 * time.Hour)}
+	bot.SetMemberStatus(fresh, telego.MemberStatusLeft)
+	bot.SetMemberStatus(stale, telego.MemberStatusLeft)
+
+	// A recent stored membership answers without asking Telegram
+	subscribed, err := manager.CheckSubscription(ctx, fresh)
+	require.NoError(t, err)
+	assert.True(t, subscribed)
+	assert.Empty(t, bot.CallsTo("GetChatMember", testChannelID))
+
+	// An old one is checked with Telegram, and the answer is stored
+	subscribed, err = manager.CheckSubscription(ctx, stale)
+	require.NoError(t, err)
+	assert.False(t, subscribed, "user who left while chat_member updates were missed still counts as subscribed")
+	assert.Len(t, bot.CallsTo("GetChatMember", testChannelID), 1)
+	assert.Equal(t, telego.MemberStatusLeft, members.members[stale].Status)
+	assert.WithinDuration(t, time.Now(), members.members[stale].UpdatedAt, time.Minute)
+
+	// Without a TTL Telegram is always asked
+	manager.SetMemberStatusTTL(0)
+	subscribed, err = manager.CheckSubscription(ctx, fresh)
+	require.NoError(t, err)
+	assert.False(t, subscribed)
+	assert.Len(t, bot.CallsTo("GetChatMember", testChannelID), 2)
+}
+
+func TestSubscriptionChecksAreCachedPerTTL(t *testing.T) {
+	ctx := context.Background()
+	bot := telegoapitest.NewFakeBot()
+	manager := NewManager(bot, &memorySuggestionRepo{}, testChannelID, staticAdminChecker{}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, publisher.NewQueue(time.Millisecond))
+	manager.SetSubscriptionCacheTTL(time.Hour, 20*time.Millisecond)
+
+	const member, outsider int64 = 42, 43
+	bot.SetMemberStatus(member, telego.MemberStatusMember)
+	bot.SetMemberStatus(outsider, telego.MemberStatusLeft)
+	check := func(userID int64) bool {
+		t.Helper()
+		subscribed, err := manager.CheckSubscription(ctx, userID)
+		require.NoError(t, err)
+		return subscribed
+	}
+
+	assert.True(t, check(member))
+	assert.True(t, check(member))
+	assert.False(t, check(outsider))
+	assert.False(t, check(outsider))
+	assert.Len(t, bot.CallsTo("GetChatMember", testChannelID), 2, "cached checks asked Telegram again")
+
+	// A negative result is only cached briefly, so joining is noticed soon
+	bot.SetMemberStatus(outsider, telego.MemberStatusMember)
+	time.Sleep(30 * time.Millisecond)
+	assert.True(t, check(outsider))
+	assert.True(t, check(member))
+	assert.Len(t, bot.CallsTo("GetChatMember", testChannelID), 3)
+}
+
+func TestDirectSuggestionOffers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+	user := telegoapitest.User(42, "suggester")
+	other := telegoapitest.User(43, "other")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	manager.rememberConsent(user.ID)
+	manager.rememberConsent(other.ID)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+	photo := func(from telego.User) []telego.Message {
+		return []telego.Message{{
+			MessageID: 1,
+			From:      &from,
+			Chat:      telego.Chat{ID: from.ID, Type: telego.ChatTypePrivate},
+			Photo:     []telego.PhotoSize{{FileID: "photo-file-id"}},
+		}}
+	}
+
+	// Off by default
+	offered, err := manager.OfferDirectSuggestion(ctx, photo(user))
+	require.NoError(t, err)
+	assert.False(t, offered)
+
+	manager.SetAcceptDirectSuggestions(true)
+	offered, err = manager.OfferDirectSuggestion(ctx, photo(user))
+	require.NoError(t, err)
+	assert.True(t, offered)
+	harness.PressButton(ctx, user, nil, directSuggestionYesData)
+	require.Len(t, repo.suggestions, 1)
+	assert.Equal(t, user.ID, repo.suggestions[0].SuggesterID)
+
+	// An unanswered offer expires, and is dropped once someone else is offered
+	offered, err = manager.OfferDirectSuggestion(ctx, photo(user))
+	require.NoError(t, err)
+	require.True(t, offered)
+	manager.directOffersMutex.Lock()
+	manager.directOffers[user.ID].createdAt = time.Now().Add(-directOfferTTL - time.Minute)
+	manager.directOffersMutex.Unlock()
+	_, err = manager.OfferDirectSuggestion(ctx, photo(other))
+	require.NoError(t, err)
+	manager.directOffersMutex.Lock()
+	assert.NotContains(t, manager.directOffers, user.ID)
+	assert.Contains(t, manager.directOffers, other.ID)
+	manager.directOffersMutex.Unlock()
+
+	harness.PressButton(ctx, user, nil, directSuggestionYesData)
+	answers := bot.CallsTo("AnswerCallbackQuery", 0)
+	require.NotEmpty(t, answers)
+	assert.True(t, answers[len(answers)-1].Params.(*telego.AnswerCallbackQueryParams).ShowAlert, "expired offer was not refused")
+	assert.Len(t, repo.suggestions, 1)
+}
+
+func TestSuggesterIsToldAboutChangedRefCode(t *testing.T) {
+	bot := telegoapitest.NewFakeBot()
+	manager := NewManager(bot, &memorySuggestionRepo{}, testChannelID, staticAdminChecker{}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, publisher.NewQueue(time.Millisecond))
+
+	manager.NotifyRefCodeChanged(context.Background(), &models.Suggestion{SuggesterID: 42, RefCode: "S-NEW2"}, "S-OLD1")
+	manager.NotifyRefCodeChanged(context.Background(), &models.Suggestion{SuggesterID: 43, RefCode: "S-NEW3", Test: true}, "S-OLD2")
+
+	sent := bot.CallsTo("SendMessage", 42)
+	require.Len(t, sent, 1)
+	text := sent[0].Params.(*telego.SendMessageParams).Text
+	assert.Contains(t, text, "S-OLD1")
+	assert.Contains(t, text, "/status S-NEW2")
+	assert.Empty(t, bot.CallsTo("SendMessage", 43), "test suggestions have no suggester to tell")
+}
+
+func TestSimulatedSuggestionIsTestRecord(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fake := telegoapitest.NewFakeBot()
+	bot := simulation.NewBot(fake)
+	repo := &memorySuggestionRepo{}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+
+	const adminChatID int64 = 7
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	// Steps that need the test user to press buttons are skipped
+	manager.SetSuggestionPreview(true)
+	manager.SetRulesChecklist(true)
+	simulator := simulation.NewSimulator(bot, dispatchTo(t, manager))
+
+	simulator.Suggestion(ctx, adminChatID, "photo-file-id", "simulated")
+	require.Len(t, repo.suggestions, 1)
+	suggestion := repo.suggestions[0]
+	assert.True(t, suggestion.Test)
+	assert.Equal(t, simulation.TestUserID, suggestion.SuggesterID)
+	assert.Equal(t, "simulated", suggestion.Caption)
+
+	// The test user's messages go to the admin's chat, not to Telegram
+	assert.Empty(t, fake.CallsTo("SendMessage", simulation.TestUserID))
+	mirrored := fake.CallsTo("SendMessage", adminChatID)
+	require.NotEmpty(t, mirrored)
+	assert.Contains(t, mirrored[0].Params.(*telego.SendMessageParams).Text, "To the test user")
+
+	// Test suggestions are never published, and /purgetest deletes them
+	_, err := manager.publishSuggestion(ctx, suggestion, adminChatID, adminChatID)
+	require.NoError(t, err)
+	assert.Zero(t, queue.Pending())
+	deleted, err := manager.PurgeTestSuggestions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.Empty(t, repo.suggestions)
+}
+
+func TestContentPolicyAcceptsGIFs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+
+	user := telegoapitest.User(42, "suggester")
+	admin := telegoapitest.User(7, "admin")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{admin.ID: true}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	manager.rememberConsent(user.ID)
+	policy, err := ParseContentPolicy("gif, photo")
+	require.NoError(t, err)
+	assert.Equal(t, ContentPolicy{models.MediaTypePhoto, models.MediaTypeAnimation}, policy)
+	manager.SetContentPolicy(policy)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+
+	// A video isn't accepted, and the user is told what is
+	harness.SendText(ctx, user, "/suggest")
+	harness.SendVideo(ctx, user, "video-file-id", "")
+	assert.Equal(t, StateAwaitingSuggestion, manager.GetUserState(user.ID))
+	replies := bot.CallsTo("SendMessage", user.ID)
+	require.NotEmpty(t, replies)
+	assert.Contains(t, replies[len(replies)-1].Params.(*telego.SendMessageParams).Text, "Please send photos or GIFs")
+
+	// A GIF is stored with its media type
+	harness.SendAnimation(ctx, user, "gif-file-id", "funny")
+	assert.Equal(t, StateIdle, manager.GetUserState(user.ID))
+	repo.mu.Lock()
+	require.Len(t, repo.suggestions, 1)
+	assert.Equal(t, []string{"gif-file-id"}, repo.suggestions[0].FileIDs)
+	assert.Equal(t, []string{models.MediaTypeAnimation}, repo.suggestions[0].MediaTypes)
+	repo.mu.Unlock()
+
+	// It is reviewed and published as a GIF, not in a media group
+	harness.SendText(ctx, admin, "/review")
+	reviews := bot.CallsTo("SendAnimation", admin.ID)
+	require.Len(t, reviews, 1)
+	approveData, ok := telegoapitest.ButtonData(reviews[0], ":approve:")
+	require.True(t, ok, "review message has no approve button")
+	harness.PressButton(ctx, admin, nil, approveData)
+
+	call, published := bot.WaitForCall("SendAnimation", testChannelID, 5*time.Second)
+	require.True(t, published, "suggestion was not published to the channel")
+	assert.Equal(t, "gif-file-id", call.Params.(*telego.SendAnimationParams).Animation.FileID)
+	assert.Empty(t, bot.CallsTo("SendMediaGroup", testChannelID))
+}
+
+func TestMediaLimitsRejectOrWarn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+
+	user := telegoapitest.User(42, "suggester")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	manager.rememberConsent(user.ID)
+	manager.SetMediaLimits(MediaLimits{MinResolution: 1000})
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+
+	// The harness sends 1280×720 photos, whose shorter side is too short
+	harness.SendText(ctx, user, "/suggest")
+	harness.SendPhoto(ctx, user, "small-photo", "")
+	assert.Equal(t, StateAwaitingSuggestion, manager.GetUserState(user.ID))
+	repo.mu.Lock()
+	assert.Empty(t, repo.suggestions)
+	repo.mu.Unlock()
+	replies := bot.CallsTo("SendMessage", user.ID)
+	require.NotEmpty(t, replies)
+	assert.Contains(t, replies[len(replies)-1].Params.(*telego.SendMessageParams).Text, "the resolution 1280×720 is too low")
+
+	// With warnings only, the photo is accepted and the issue recorded for reviewers
+	manager.SetMediaLimits(MediaLimits{MinResolution: 1000, WarnOnly: true})
+	harness.SendPhoto(ctx, user, "small-photo", "")
+	assert.Equal(t, StateIdle, manager.GetUserState(user.ID))
+	repo.mu.Lock()
+	require.Len(t, repo.suggestions, 1)
+	suggestion := repo.suggestions[0]
+	repo.mu.Unlock()
+	assert.Equal(t, []models.MediaIssue{{Kind: models.MediaIssueLowResolution, Width: 1280, Height: 720, Limit: 1000}}, suggestion.MediaIssues)
+	assert.Contains(t, manager.buildReviewMessageText(locales.NewLocalizer("en"), suggestion, 0, 1), "Media outside the limits")
+}
+
+func TestReviewerOverridesVideoPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+
+	user := telegoapitest.User(42, "suggester")
+	admin := telegoapitest.User(7, "admin")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{admin.ID: true}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	manager.rememberConsent(user.ID)
+	manager.SetContentPolicy(ContentPolicy{models.MediaTypeVideo})
+	manager.SetMediaLimits(MediaLimits{WarnOnly: true})
+	policy, err := videopolicy.New(5*time.Second, "9:16")
+	require.NoError(t, err)
+	manager.SetVideoPolicy(policy)
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+
+	// The harness sends landscape videos of 10 seconds
+	harness.SendText(ctx, user, "/suggest")
+	harness.SendVideo(ctx, user, "long-video", "")
+	repo.mu.Lock()
+	require.Len(t, repo.suggestions, 1)
+	assert.Len(t, repo.suggestions[0].MediaIssues, 2)
+	repo.mu.Unlock()
+
+	harness.SendText(ctx, admin, "/review")
+	reviewVideos := bot.CallsTo("SendVideo", admin.ID)
+	require.Len(t, reviewVideos, 1)
+	assert.Contains(t, reviewVideos[0].Params.(*telego.SendVideoParams).Caption, "the video is too long")
+	approveData, ok := telegoapitest.ButtonData(reviewVideos[0], ":approve:")
+	require.True(t, ok, "review message has no approve button")
+	videoData, ok := telegoapitest.ButtonData(reviewVideos[0], ":vid:")
+	require.True(t, ok, "review message has no video policy button")
+
+	// Approval is refused until the reviewer overrides the policy
+	harness.PressButton(ctx, admin, nil, approveData)
+	answers := bot.CallsTo("AnswerCallbackQuery", 0)
+	require.NotEmpty(t, answers)
+	assert.Contains(t, answers[len(answers)-1].Params.(*telego.AnswerCallbackQueryParams).Text, "outside the channel's video policy")
+	assert.Empty(t, bot.CallsTo("SendMediaGroup", testChannelID))
+
+	harness.PressButton(ctx, admin, nil, videoData)
+	repo.mu.Lock()
+	assert.True(t, repo.suggestions[0].VideoPolicyOverride)
+	repo.mu.Unlock()
+	harness.PressButton(ctx, admin, nil, approveData)
+	_, published := bot.WaitForCall("SendMediaGroup", testChannelID, 5*time.Second)
+	assert.True(t, published, "suggestion was not published to the channel")
+}
+
+// fixedFrames extracts the same frame at any time up to a video length.
+type fixedFrames time.Duration
+
+func (f fixedFrames) Frame(ctx context.Context, video []byte, at time.Duration) ([]byte, error) {
+	if at > time.Duration(f) {
+		return nil, errors.New("no frame")
+	}
+	return []byte("frame"), nil
+}
+
+func TestReviewerChoosesVideoThumbnail(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := telegoapitest.NewFakeBot()
+	repo := &memorySuggestionRepo{}
+	queue := publisher.NewQueue(time.Millisecond)
+	queue.Start(ctx)
+
+	user := telegoapitest.User(42, "suggester")
+	admin := telegoapitest.User(7, "admin")
+	manager := NewManager(bot, repo, testChannelID, staticAdminChecker{admin.ID: true}, stubFeedbackRepo{},
+		mediagroups.NewManager(), stubUserRepo{}, stubMembershipRepo{}, queue)
+	manager.rememberConsent(user.ID)
+	manager.SetContentPolicy(ContentPolicy{models.MediaTypeVideo})
+	manager.SetThumbnailMaker(thumbnail.NewMaker(fixedDownloader("video"), fixedFrames(10*time.Second)))
+	harness := telegoapitest.NewHarness(bot, dispatchTo(t, manager))
+	harness.SendText(ctx, user, "/suggest")
+	harness.SendVideo(ctx, user, "clip", "")
+
+	harness.SendText(ctx, admin, "/review")
+	reviewVideos := bot.CallsTo("SendVideo", admin.ID)
+	require.Len(t, reviewVideos, 1)
+	thumbData, ok := telegoapitest.ButtonData(reviewVideos[0], ":thumb:")
+	require.True(t, ok, "review message has no thumbnail button")
+
+	// A frame past the end of the video is refused, and the reviewer asked again
+	harness.PressButton(ctx, admin, nil, thumbData)
+	assert.Equal(t, StateChoosingThumbnail, manager.GetUserState(admin.ID))
+	harness.SendText(ctx, admin, "0:30")
+	replies := bot.CallsTo("SendMessage", admin.ID)
+	assert.Contains(t, replies[len(replies)-1].Params.(*telego.SendMessageParams).Text, "Couldn't make this thumbnail")
+	assert.Equal(t, StateChoosingThumbnail, manager.GetUserState(admin.ID))
+
+	harness.SendText(ctx, admin, "0:07")
+	assert.Equal(t, StateIdle, manager.GetUserState(admin.ID))
+	assert.NotEmpty(t, bot.CallsTo("SendPhoto", admin.ID), "the thumbnail is shown to the reviewer")
+	repo.mu.Lock()
+	assert.Equal(t, &models.VideoThumbnail{At: 7}, repo.suggestions[0].Thumbnail)
+	repo.mu.Unlock()
+	edits := bot.CallsTo("EditMessageReplyMarkup", admin.ID)
+	require.NotEmpty(t, edits)
+	_, ok = telegoapitest.ButtonData(edits[len(edits)-1], ":thumb:")
+	assert.True(t, ok)
+
+	// The video is uploaded along with the thumbnail, which Telegram ignores otherwise
+	approveData, ok := telegoapitest.ButtonData(reviewVideos[0], ":approve:")
+	require.True(t, ok, "review message has no approve button")
+	harness.PressButton(ctx, admin, nil, approveData)
+	call, published := bot.WaitForCall("SendMediaGroup", testChannelID, 5*time.Second)
+	require.True(t, published, "suggestion was not published to the channel")
+	video := call.Params.(*telego.SendMediaGroupParams).Media[0].(*telego.InputMediaVideo)
+	assert.NotNil(t, video.Media.File)
+	require.NotNil(t, video.Thumbnail)
+	assert.NotNil(t, video.Thumbnail.File)
+}
@@ -0,0 +1,58 @@
+package suggestions
+
+import (
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+)
+
+// SetCreditForwardSource enables crediting the source channel in the caption
+// when publishing a suggestion that was forwarded from a channel or group.
+func (m *Manager) SetCreditForwardSource(enabled bool) {
+	m.creditForwardSource = enabled
+}
+
+// forwardOriginFromMessage captures the forward_origin of a message, or returns nil if it wasn't forwarded.
+func forwardOriginFromMessage(message *telego.Message) *models.ForwardOrigin {
+	if message == nil || message.ForwardOrigin == nil {
+		return nil
+	}
+	origin := &models.ForwardOrigin{
+		Type: message.ForwardOrigin.OriginType(),
+		Date: time.Unix(message.ForwardOrigin.OriginalDate(), 0),
+	}
+	switch o := message.ForwardOrigin.(type) {
+	case *telego.MessageOriginUser:
+		origin.SenderUserID = o.SenderUser.ID
+		origin.SenderName = strings.TrimSpace(o.SenderUser.FirstName + " " + o.SenderUser.LastName)
+	case *telego.MessageOriginHiddenUser:
+		origin.SenderName = o.SenderUserName
+	case *telego.MessageOriginChat:
+		origin.ChatID = o.SenderChat.ID
+		origin.ChatTitle = o.SenderChat.Title
+		origin.ChatUsername = o.SenderChat.Username
+		origin.SenderName = o.AuthorSignature
+	case *telego.MessageOriginChannel:
+		origin.ChatID = o.Chat.ID
+		origin.ChatTitle = o.Chat.Title
+		origin.ChatUsername = o.Chat.Username
+		origin.MessageID = o.MessageID
+		origin.SenderName = o.AuthorSignature
+	}
+	return origin
+}
+
+// forwardSourceCredit builds the caption crediting the original channel of a repost.
+// The channel audience is shared, so the default language is used.
+func forwardSourceCredit(origin *models.ForwardOrigin) string {
+	source := origin.DisplayName()
+	if link := origin.Link(); link != "" {
+		source = link
+	}
+	return locales.GetMessage(locales.NewLocalizer(), "MsgForwardSourceCredit", map[string]interface{}{
+		"Source": source,
+	}, nil)
+}
@@ -0,0 +1,69 @@
+package suggestions
+
+import (
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/format"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// historySeparator joins the entries of the history line in the review message.
+const historySeparator = " → "
+
+// historyWorthShowing reports whether reviewers should see the history of a suggestion: it was
+// re-opened after a rejection, or handed from one assignee to another.
+func historyWorthShowing(history []models.StatusChange) bool {
+	assignments := 0
+	for _, change := range history {
+		if change.From == string(models.StatusRejected) && change.To == string(models.StatusPending) {
+			return true
+		}
+		if change.Reason == models.HistoryReasonAssigned {
+			assignments++
+		}
+	}
+	return assignments > 1
+}
+
+// historyText returns the compact history line of the review message, or "" if the history
+// isn't worth showing.
+func historyText(localizer *i18n.Localizer, history []models.StatusChange, now time.Time) string {
+	if !historyWorthShowing(history) {
+		return ""
+	}
+	entries := make([]string, 0, len(history))
+	assigned := false
+	for _, change := range history {
+		data := map[string]interface{}{
+			"Admin": change.By,
+			"When":  format.RelativeTime(localizer, change.At, now),
+		}
+		var messageID string
+		switch {
+		case change.Reason == models.HistoryReasonAssigned:
+			data["Admin"] = change.Assignee
+			messageID = "MsgHistoryAssigned"
+			if assigned {
+				messageID = "MsgHistoryReassigned"
+			}
+			assigned = true
+		case change.To == string(models.StatusPending):
+			messageID = "MsgHistoryReopened"
+		case change.To == string(models.StatusApproved):
+			messageID = "MsgHistoryApproved"
+		case change.Reason == models.RejectionReasonExpired:
+			messageID = "MsgHistoryExpired"
+		case change.By == 0:
+			messageID = "MsgHistoryAutoRejected"
+		default:
+			messageID = "MsgHistoryRejected"
+		}
+		entries = append(entries, locales.GetMessage(localizer, messageID, data, nil))
+	}
+	return locales.GetMessage(localizer, "MsgReviewHistory", map[string]interface{}{
+		"Entries": strings.Join(entries, historySeparator),
+	}, nil)
+}
@@ -0,0 +1,105 @@
+package suggestions
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+)
+
+// SetIntakeChatID sets the intake group: every photo or album posted there becomes a pending
+// suggestion attributed to the poster, without /suggest. Zero disables the intake group.
+func (m *Manager) SetIntakeChatID(chatID int64) {
+	m.intakeChatID = chatID
+}
+
+// IsIntakeChat reports whether a chat is the intake group.
+func (m *Manager) IsIntakeChat(chatID int64) bool {
+	return m.intakeChatID != 0 && chatID == m.intakeChatID
+}
+
+// handleIntakeMessage turns a photo posted in the intake group into a suggestion. Albums are
+// collected by the media group manager first. Commands are left to the command handlers, and
+// other messages in the group are ignored.
+func (m *Manager) handleIntakeMessage(ctx context.Context, message *telego.Message) (processed bool, err error) {
+	if strings.HasPrefix(message.Text, "/") {
+		return false, nil
+	}
+	if !isIntakePoster(message) || len(message.Photo) == 0 {
+		return true, nil
+	}
+	if message.MediaGroupID != "" {
+		if err := m.mediaGroupMgr.HandleMessage(ctx, *message, m.processIntakeMediaGroup); err != nil {
+			return true, fmt.Errorf("failed to collect intake album %s: %w", message.MediaGroupID, err)
+		}
+		return true, nil
+	}
+	return true, m.submitIntakeSuggestion(ctx, message, []string{message.Photo[len(message.Photo)-1].FileID})
+}
+
+// processIntakeMediaGroup turns an album posted in the intake group into one suggestion with
+// its photos. Matches the mediagroups.ProcessFunc signature.
+func (m *Manager) processIntakeMediaGroup(ctx context.Context, groupID string, msgs []telego.Message) error {
+	if len(msgs) == 0 || !isIntakePoster(&msgs[0]) {
+		return nil
+	}
+	fileIDs := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		if len(msg.Photo) > 0 {
+			fileIDs = append(fileIDs, msg.Photo[len(msg.Photo)-1].FileID)
+		}
+	}
+	if len(fileIDs) == 0 {
+		log.Printf("[Intake Group:%s] No photos in album, ignoring.", groupID)
+		return nil
+	}
+	return m.submitIntakeSuggestion(ctx, &msgs[0], fileIDs)
+}
+
+// isIntakePoster reports whether a message in the intake group comes from a person who can be
+// credited: posts on behalf of chats and messages from bots are skipped.
+func isIntakePoster(message *telego.Message) bool {
+	return message.From != nil && !message.From.IsBot && message.SenderChat == nil
+}
+
+// submitIntakeSuggestion stores photos posted in the intake group as a pending suggestion of
+// the poster. Auto-reject rules apply as for /suggest. The poster is told in private chat,
+// which only works if they have started the bot, so the group isn't flooded with replies.
+func (m *Manager) submitIntakeSuggestion(ctx context.Context, message *telego.Message, fileIDs []string) error {
+	poster := message.From
+	localizer := m.localizerForUser(ctx, poster)
+	suggestion := &models.Suggestion{
+		SuggesterID: poster.ID,
+		Username:    poster.Username,
+		FirstName:   poster.FirstName,
+		MessageID:   message.MessageID,
+		ChatID:      poster.ID, // Replies about the suggestion go to the poster's private chat
+		FileIDs:     fileIDs,
+		Caption:     message.Caption,
+		Status:      string(StatusPending),
+		SubmittedAt: time.Now(),
+
+		ForwardOrigin: forwardOriginFromMessage(message),
+	}
+	if rejected, err := m.applyAutoRejectRules(ctx, localizer, suggestion); rejected {
+		return err
+	}
+	if err := m.AddSuggestion(ctx, suggestion); err != nil {
+		return fmt.Errorf("failed to add intake suggestion of user %d: %w", poster.ID, err)
+	}
+	log.Printf("[Intake User:%d] Message %d in the intake group became suggestion %s", poster.ID, message.MessageID, suggestion.ID.Hex())
+
+	msg := locales.GetMessage(localizer, "MsgIntakeSuggestionReceived", nil, nil)
+	if refCode := refCodeText(localizer, suggestion); refCode != "" {
+		msg += "\n" + refCode
+	}
+	if err := m.sendNotification(ctx, models.NotificationKindIntakeReceipt, poster.ID, msg); err != nil {
+		log.Printf("[Intake User:%d] Could not confirm the suggestion in private chat: %v", poster.ID, err)
+	}
+	return nil
+}
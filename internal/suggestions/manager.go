@@ -5,48 +5,135 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 	"vrcmemes-bot/internal/auth"
+	"vrcmemes-bot/internal/cache"
+	"vrcmemes-bot/internal/config"
 	"vrcmemes-bot/internal/database"
 	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/engagement"
+	"vrcmemes-bot/internal/footers"
 	"vrcmemes-bot/internal/locales"
 	"vrcmemes-bot/internal/mediagroups"
+	"vrcmemes-bot/internal/mediaproxy"
+	"vrcmemes-bot/internal/publisher"
+	"vrcmemes-bot/internal/simulation"
+	"vrcmemes-bot/internal/thumbnail"
+	"vrcmemes-bot/internal/translate"
+	"vrcmemes-bot/internal/videopolicy"
 	telegoapi "vrcmemes-bot/pkg/telegoapi"
 
 	"github.com/mymmrac/telego"
 	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-const (
-	// Define constants used in this file
-	mediaGroupProcessDelay = 3 * time.Second // Delay for processing suggestion/feedback media groups
-	maxMediaGroupSize      = 10              // Max items in suggestion/feedback media groups
-)
-
 // Manager handles the suggestion logic and storage.
 type Manager struct {
-	userStates   map[int64]UserState
-	muUserStates sync.RWMutex
+	cache        cache.Cache  // User states and subscription checks
+	muUserStates sync.RWMutex // Serializes changes of user states
 
 	bot             telegoapi.BotAPI
 	targetChannelID int64
 	repo            database.SuggestionRepository
-	adminChecker    auth.AdminCheckerInterface
-
-	adminCache      []telego.ChatMember
-	adminCacheTime  time.Time
-	adminCacheMutex sync.RWMutex
-	adminCacheTTL   time.Duration
+	adminChecker    auth.AdminCheckerInterface // Caches admin status; see auth.AdminChecker
 
 	reviewSessions      map[int64]*ReviewSession
-	reviewSessionsMutex sync.RWMutex
+	reviewSessionsMutex sync.RWMutex                 // Also guards claimedSuggestions, reviewSessionTTL and reviewReminderAfter
+	claimedSuggestions  map[primitive.ObjectID]int64 // Suggestion ID -> admin whose session holds it
+	reviewSessionTTL    time.Duration
+	reviewReminderAfter time.Duration // Inactivity after which an admin is reminded of their session; 0 disables
 
-	feedbackRepo database.FeedbackRepository
+	intakeChatID     int64 // Group whose photos become suggestions; 0 if none
+	previewChannelID int64 // Channel approved suggestions can be previewed in before promoting them; 0 if none
+
+	subscriptionCacheMutex  sync.RWMutex  // Guards the subscription TTLs
+	subscriptionTTL         time.Duration // How long a positive subscription check is trusted
+	subscriptionNegativeTTL time.Duration // How long a negative check is trusted (shorter, so new subscribers aren't blocked long)
+	memberStatusTTL         time.Duration // How long a membership stored from chat_member updates is trusted
+
+	feedbackRepo   database.FeedbackRepository
+	userRepo       database.UserRepository       // Used to resolve saved language preferences
+	membershipRepo database.MembershipRepository // Channel membership tracked from chat_member updates
 
 	// Universal media group manager
 	mediaGroupMgr *mediagroups.Manager
+	// Paces all channel publications
+	publishQueue *publisher.Queue
+
+	directOffers            map[int64]*directOffer // Media sent without /suggest, awaiting Yes/No
+	directOffersMutex       sync.Mutex             // Also guards acceptDirectSuggestions
+	acceptDirectSuggestions bool
+
+	// Suggestions previewed to their suggester before submission, see submission_preview.go
+	pendingSubmissions      map[int64]*pendingSubmission
+	pendingSubmissionsMutex sync.Mutex // Also guards previewSuggestions
+	previewSuggestions      bool
+
+	creditForwardSource bool                      // Add a source link when publishing reposts from other channels
+	creditSuggesters    bool                      // Name suggesters who didn't answer the credit question, see credit.go
+	creditCoSuggesters  bool                      // Also name the suggesters of merged duplicates, see merge.go
+	customEmoji         bool                      // Keep the custom emoji of caption styles, see styles.go
+	protectContent      bool                      // Publish with protected content unless switched per post, see protect.go
+	contentPolicy       ContentPolicy             // Media types accepted in suggestions, see content_policy.go
+	rulesChecklist      bool                      // Confirm the rules before /suggest waits for content, see checklist.go
+	tags                []string                  // Tags reviewers can attach, see tags.go
+	rubricRepo          database.RubricRepository // Rubrics reviewers can pick; nil disables them, see rubrics.go
+	engagement          *engagement.Prompter      // Reactions and comments of rubric posts; nil adds none
+	location            *time.Location            // Channel time zone for user-facing times
+	autoRejectRules     AutoRejectRules           // Checks applied on submission, see rules.go
+	mediaLimits         MediaLimits               // Size and resolution checks of suggested media, see media_limits.go
+	videoPolicy         videopolicy.Policy        // Duration and aspect ratio checks of suggested videos
+	thumbnails          *thumbnail.Maker          // Custom video thumbnails, see thumbnail.go; nil disables them
+	reviewOrder         ReviewOrder               // Default order of /review, see review_order.go
+	reviewAssignment    bool                      // Assign new suggestions to admins in turn, see assignment.go
+	reassignAfter       time.Duration             // Hand untouched assignments to the next admin; 0 never does
+	lastAssignee        int64                     // Admin the last suggestion was assigned to
+	assignMutex         sync.Mutex                // Guards lastAssignee
+	expireAfter         time.Duration             // Reject pending suggestions older than this as expired; 0 never does
+	notifyExpired       bool                      // Tell suggesters their suggestion expired, see expiry.go
+
+	feedbackActivity   map[int64]*feedbackActivity // Recent feedback per user, for spam limits
+	feedbackLimits     FeedbackLimits
+	feedbackGuardMutex sync.Mutex // Guards feedbackActivity and feedbackLimits
+
+	// Where new feedback is forwarded for admins; see SetFeedbackChat
+	feedbackChatID  int64
+	feedbackTopicID int
+
+	// Groups writes to several collections; see SetUnitOfWork
+	unitOfWork database.UnitOfWork
+
+	// Admin notifications and quiet hours, see admin_notify.go
+	settingsRepo          database.SettingsRepository
+	newSuggestionPings    bool
+	quietHours            map[int64]*QuietHours // Cached per admin; nil means none
+	deferredNotifications map[int64][]string    // Held back during quiet hours
+	adminIDs              []int64               // Admins of the last admin checker refresh, see channelAdmins
+	adminIDsAt            time.Time             // When adminIDs were refreshed
+	notifyMutex           sync.Mutex            // Guards quietHours, deferredNotifications and adminIDs
+	heldSaveMutex         sync.Mutex            // Keeps saves of deferredNotifications in order
+	notifier              Notifier              // Stores notifications for reliable delivery; nil sends them directly
+
+	consented    map[int64]struct{} // Users known to have agreed to the privacy notice, see consent.go
+	consentMutex sync.Mutex
+
+	mediaProxy *mediaproxy.Proxy // Re-uploads suggested media in privacy mode; nil reuses file IDs, see media_proxy.go
+
+	textRecognizer TextRecognizer // Reads the text in suggested photos; nil disables OCR, see ocr.go
+
+	translator translate.Provider // Translates captions into the channel language; nil disables it, see translation.go
+
+	wordFilterRepo database.WordFilterRepository // Words screened in captions; nil disables the filter, see word_filter.go
+
+	postLogger database.PostLogger // Logs published suggestions with the other channel posts; nil doesn't
+
+	footerRotator *footers.Rotator // Rotates hashtag footers under published captions; nil adds none, see styles.go
+
+	fileDownloader FileDownloader // Downloads the thumbnails of /queue export; nil leaves them out, see queue_export.go
 }
 
 // NewManager creates a new suggestion manager.
@@ -57,6 +144,9 @@ func NewManager(
 	adminChecker auth.AdminCheckerInterface,
 	feedbackRepo database.FeedbackRepository,
 	mediaGroupMgr *mediagroups.Manager,
+	userRepo database.UserRepository,
+	membershipRepo database.MembershipRepository,
+	publishQueue *publisher.Queue,
 ) *Manager {
 	if bot == nil {
 		log.Fatal("Suggestion Manager: BotAPI instance is nil")
@@ -76,21 +166,61 @@ func NewManager(
 	if mediaGroupMgr == nil {
 		log.Fatal("Suggestion Manager: Media group manager is nil")
 	}
+	if userRepo == nil {
+		log.Fatal("Suggestion Manager: User repository is nil")
+	}
+	if membershipRepo == nil {
+		log.Fatal("Suggestion Manager: Membership repository is nil")
+	}
+	if publishQueue == nil {
+		log.Fatal("Suggestion Manager: Publish queue is nil")
+	}
 
 	return &Manager{
-		userStates:      make(map[int64]UserState),
-		bot:             bot,
-		targetChannelID: targetChannelID,
-		repo:            repo,
-		feedbackRepo:    feedbackRepo,
-		adminChecker:    adminChecker,
-		mediaGroupMgr:   mediaGroupMgr,
-		adminCache:      make([]telego.ChatMember, 0),
-		adminCacheTTL:   5 * time.Minute,
-		reviewSessions:  make(map[int64]*ReviewSession),
+		cache:              cache.NewMemory(),
+		bot:                bot,
+		targetChannelID:    targetChannelID,
+		repo:               repo,
+		feedbackRepo:       feedbackRepo,
+		adminChecker:       adminChecker,
+		mediaGroupMgr:      mediaGroupMgr,
+		userRepo:           userRepo,
+		membershipRepo:     membershipRepo,
+		publishQueue:       publishQueue,
+		reviewSessions:     make(map[int64]*ReviewSession),
+		claimedSuggestions: make(map[primitive.ObjectID]int64),
+		reviewSessionTTL:   DefaultReviewSessionTTL,
+		reviewOrder:        ReviewOrderFIFO,
+		unitOfWork:         database.NoTransaction,
+		location:           time.UTC,
+		consented:          make(map[int64]struct{}),
+
+		quietHours:            make(map[int64]*QuietHours),
+		deferredNotifications: make(map[int64][]string),
+
+		directOffers:       make(map[int64]*directOffer),
+		pendingSubmissions: make(map[int64]*pendingSubmission),
+
+		subscriptionTTL:         config.DefaultSubscriptionCacheTTL,
+		subscriptionNegativeTTL: config.DefaultSubscriptionNegativeCacheTTL,
+		memberStatusTTL:         config.DefaultMemberStatusTTL,
+
+		feedbackActivity: make(map[int64]*feedbackActivity),
+		feedbackLimits: FeedbackLimits{
+			RateLimit:    config.DefaultFeedbackRateLimit,
+			RateWindow:   config.DefaultFeedbackRateWindow,
+			MinLength:    config.DefaultFeedbackMinLength,
+			MuteAfter:    config.DefaultFeedbackMuteAfter,
+			MuteDuration: config.DefaultFeedbackMuteDuration,
+		},
 	}
 }
 
+// SetLocation sets the channel time zone, in which user-facing times are shown. Defaults to UTC.
+func (m *Manager) SetLocation(loc *time.Location) {
+	m.location = loc
+}
+
 // HandleSuggestCommand handles the /suggest command.
 func (m *Manager) HandleSuggestCommand(ctx context.Context, update telego.Update) error {
 	if update.Message == nil || update.Message.From == nil {
@@ -100,10 +230,7 @@ func (m *Manager) HandleSuggestCommand(ctx context.Context, update telego.Update
 
 	chatID := update.Message.Chat.ID
 	userID := update.Message.From.ID
-
-	// Default to Russian
-	lang := locales.GetDefaultLanguageTag().String()
-	localizer := locales.NewLocalizer(lang)
+	localizer := m.localizerForUser(ctx, update.Message.From)
 
 	if m.GetUserState(userID) == StateAwaitingSuggestion {
 		msg := locales.GetMessage(localizer, "MsgSuggestAlreadyWaitingForContent", nil, nil)
@@ -111,6 +238,10 @@ func (m *Manager) HandleSuggestCommand(ctx context.Context, update telego.Update
 		return err
 	}
 
+	if consented, err := m.requireConsent(ctx, localizer, userID, chatID); !consented {
+		return err
+	}
+
 	isSubscribed, err := m.CheckSubscription(ctx, userID)
 	if err != nil {
 		log.Printf("Error checking subscription for user %d: %v", userID, err)
@@ -128,9 +259,19 @@ func (m *Manager) HandleSuggestCommand(ctx context.Context, update telego.Update
 		return err
 	}
 
+	// Optionally make the user confirm the rules before sending anything; the test user of
+	// /simulate can't tick the checklist
+	if m.rulesChecklist && !simulation.Active(ctx) {
+		return m.sendRulesChecklist(ctx, localizer, chatID)
+	}
+	return m.startSuggestion(ctx, localizer, userID, chatID)
+}
+
+// startSuggestion waits for the user's suggestion content and asks them to send it.
+func (m *Manager) startSuggestion(ctx context.Context, localizer *i18n.Localizer, userID, chatID int64) error {
 	m.SetUserState(userID, StateAwaitingSuggestion)
 	promptMsg := locales.GetMessage(localizer, "MsgSuggestSendContentPrompt", nil, nil)
-	_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), promptMsg))
+	_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), promptMsg))
 	if err != nil {
 		m.SetUserState(userID, StateIdle) // Rollback state if sending prompt fails
 		log.Printf("Error sending suggest prompt to user %d: %v", userID, err)
@@ -151,6 +292,10 @@ func (m *Manager) HandleMessage(ctx context.Context, update telego.Update) (proc
 		return false, nil // Not a message we can process here
 	}
 
+	if m.IsIntakeChat(update.Message.Chat.ID) {
+		return m.handleIntakeMessage(ctx, update.Message)
+	}
+
 	userID := update.Message.From.ID
 	currentState := m.GetUserState(userID)
 	log.Printf("[Suggest Manager HandleMessage User:%d] Current state: %v", userID, currentState)
@@ -163,6 +308,41 @@ func (m *Manager) HandleMessage(ctx context.Context, update telego.Update) (proc
 		log.Printf("[Suggest Manager HandleMessage User:%d] Handling as feedback...", userID)
 		// Pass the feedback repository needed by handleFeedbackContent
 		return m.handleFeedbackContent(ctx, update.Message)
+	case StateEditingCaption, StateAddingPhoto:
+		log.Printf("[Suggest Manager HandleMessage User:%d] Handling as suggestion edit...", userID)
+		return m.handleEditContent(ctx, update.Message, currentState)
+	case StateReplyingToFeedback:
+		log.Printf("[Suggest Manager HandleMessage User:%d] Handling as feedback reply...", userID)
+		return m.handleFeedbackReplyContent(ctx, update.Message)
+	case StateAskingSuggester:
+		log.Printf("[Suggest Manager HandleMessage User:%d] Handling as question to suggester...", userID)
+		return m.handleSuggesterQuestionContent(ctx, update.Message)
+	case StateAnsweringReviewer:
+		log.Printf("[Suggest Manager HandleMessage User:%d] Handling as answer to reviewer...", userID)
+		return m.handleReviewerAnswerContent(ctx, update.Message)
+	case StateChoosingThumbnail:
+		log.Printf("[Suggest Manager HandleMessage User:%d] Handling as video thumbnail...", userID)
+		return m.handleThumbnailContent(ctx, update.Message)
+	case StateConfirmingSuggestion:
+		if strings.HasPrefix(update.Message.Text, "/") || !m.hasPendingSubmission(userID) {
+			// Another command abandons the previewed suggestion
+			m.dropPendingSubmission(userID)
+			m.SetUserState(userID, StateIdle)
+			return false, nil
+		}
+		localizer := m.localizerForUser(ctx, update.Message.From)
+		msg := locales.GetMessage(localizer, "MsgSuggestConfirmFirst", nil, nil)
+		_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(update.Message.Chat.ID), msg))
+		return true, err
+	case StateChoosingFeedbackCategory:
+		if strings.HasPrefix(update.Message.Text, "/") {
+			m.SetUserState(userID, StateIdle) // Another command abandons the feedback
+			return false, nil
+		}
+		localizer := m.localizerForUser(ctx, update.Message.From)
+		msg := locales.GetMessage(localizer, "MsgFeedbackChooseCategoryFirst", nil, nil)
+		_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(update.Message.Chat.ID), msg))
+		return true, err
 	default:
 		log.Printf("[Suggest Manager HandleMessage User:%d] State is not AwaitingSuggestion or AwaitingFeedback, returning processed=false", userID)
 		return false, nil
@@ -173,13 +353,15 @@ func (m *Manager) HandleMessage(ctx context.Context, update telego.Update) (proc
 func (m *Manager) handleSuggestionContent(ctx context.Context, message *telego.Message) (processed bool, err error) {
 	userID := message.From.ID
 	chatID := message.Chat.ID
-	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+	localizer := m.localizerForUser(ctx, message.From)
+	media := suggestionMedia(message)
+	policy := m.ContentPolicy()
 
 	// Handle Media Group for Suggestion
 	if message.MediaGroupID != "" {
-		// Only handle photos in suggestion media groups for now
-		if len(message.Photo) == 0 {
-			log.Printf("[HandleSuggestionContent] Received non-photo message part of media group %s from user %d. Ignoring.", message.MediaGroupID, userID)
+		// Only handle the media types the content policy accepts
+		if !policy.Accepts(media.Type) {
+			log.Printf("[HandleSuggestionContent] Received %q message part of media group %s from user %d, not accepted by the content policy %s. Ignoring.", media.Type, message.MediaGroupID, userID, policy)
 			return true, nil // Processed (ignored), state remains awaiting
 		}
 
@@ -187,9 +369,7 @@ func (m *Manager) handleSuggestionContent(ctx context.Context, message *telego.M
 		err = m.mediaGroupMgr.HandleMessage(
 			ctx, // Pass parent context
 			*message,
-			m.processSuggestionMediaGroup,   // Handler function
-			mediagroups.DefaultProcessDelay, // Use default delay
-			mediagroups.DefaultMaxGroupSize, // Use default size
+			m.processSuggestionMediaGroup, // Handler function
 		)
 		if err != nil {
 			log.Printf("[HandleSuggestionContent] Error delegating media group %s to manager: %v", message.MediaGroupID, err)
@@ -198,9 +378,16 @@ func (m *Manager) handleSuggestionContent(ctx context.Context, message *telego.M
 		return true, err // Indicate message was handled (or attempted) by media group logic
 	}
 
-	// If it wasn't a media group, handle Single Photo for Suggestion
-	if message.Photo != nil && len(message.Photo) > 0 {
-		fileIDs := []string{message.Photo[len(message.Photo)-1].FileID}
+	// If it wasn't a media group, handle a single file for the suggestion
+	if policy.Accepts(media.Type) {
+		issues := m.checkMedia(0, media)
+		if len(issues) > 0 && !m.mediaLimits.WarnOnly {
+			log.Printf("[HandleSuggestionContent] User %d sent media outside the media limits: %+v", userID, issues)
+			_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), mediaRejectedMessage(localizer, issues, false)))
+			// Do not reset state here, let the user send better media
+			return true, err
+		}
+		fileIDs := []string{media.FileID}
 		caption := message.Caption // User-provided caption for admin review
 
 		suggestionForDB := &models.Suggestion{
@@ -210,31 +397,40 @@ func (m *Manager) handleSuggestionContent(ctx context.Context, message *telego.M
 			MessageID:   message.MessageID,
 			ChatID:      chatID,
 			FileIDs:     fileIDs,
+			MediaTypes:  storedMediaTypes([]string{media.Type}),
+			MediaIssues: issues,
 			Caption:     caption,
 			Status:      string(StatusPending),
 			SubmittedAt: time.Now(),
+
+			ForwardOrigin: forwardOriginFromMessage(message),
+		}
+		if rejected, err := m.applyAutoRejectRules(ctx, localizer, suggestionForDB); rejected {
+			m.SetUserState(userID, StateIdle)
+			return true, err
+		}
+		if m.suggestionPreviewEnabled() && !submissionConfirmed(ctx) && !simulation.Active(ctx) {
+			return true, m.previewSubmission(ctx, localizer, suggestionForDB)
 		}
 		err = m.AddSuggestion(ctx, suggestionForDB)
 		if err != nil {
-			log.Printf("[HandleSuggestionContent] Error saving single photo suggestion for user %d: %v", userID, err)
-			errorMsg := locales.GetMessage(localizer, "MsgSuggestInternalProcessingError", nil, nil)
+			log.Printf("[HandleSuggestionContent] Error saving single-file suggestion for user %d: %v", userID, err)
+			errorMsg := locales.GetMessage(localizer, suggestionSaveErrorMessage(err), nil, nil)
 			_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), errorMsg))
 			m.SetUserState(userID, StateIdle) // Reset state on error
 			return true, err                  // Processed (with error)
 		}
 
 		m.SetUserState(userID, StateIdle) // Reset state after success
-		confirmationMsg := locales.GetMessage(localizer, "MsgSuggestionReceivedConfirmation", nil, nil)
-		_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), confirmationMsg))
-		if err != nil {
-			log.Printf("[HandleSuggestionContent] Error sending single photo confirmation to user %d: %v", userID, err)
+		if err := m.sendSuggestionReceived(ctx, localizer, chatID, suggestionForDB); err != nil {
+			log.Printf("[HandleSuggestionContent] Error sending single-file confirmation to user %d: %v", userID, err)
 		}
 		return true, nil // Processed successfully
 	}
 
 	// Wrong Message Type for Suggestion
-	log.Printf("[HandleSuggestionContent] User %d sent non-photo/non-media-group message while awaiting suggestion.", userID)
-	errorMsg := locales.GetMessage(localizer, "MsgSuggestionRequiresPhoto", nil, nil)
+	log.Printf("[HandleSuggestionContent] User %d sent a message the content policy %s doesn't accept while awaiting suggestion.", userID, policy)
+	errorMsg := m.contentRequiredMessage(localizer)
 	_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), errorMsg))
 	// Do not reset state here, let the user try again
 	return true, err // Processed (with error message sent)
@@ -279,7 +475,7 @@ func extractFeedbackContent(message *telego.Message) (text string, photoIDs []st
 func (m *Manager) handleFeedbackContent(ctx context.Context, message *telego.Message) (processed bool, err error) {
 	userID := message.From.ID
 	chatID := message.Chat.ID
-	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+	localizer := m.localizerForUser(ctx, message.From)
 	mediaGroupID := message.MediaGroupID
 
 	// --- Handle Media Group for Feedback ---
@@ -295,8 +491,6 @@ func (m *Manager) handleFeedbackContent(ctx context.Context, message *telego.Mes
 			ctx,
 			*message,
 			m.processFeedbackMediaGroup,
-			mediagroups.DefaultProcessDelay,
-			mediagroups.DefaultMaxGroupSize,
 		)
 		if err != nil {
 			log.Printf("[HandleFeedbackContent] Error delegating feedback media group %s to manager: %v", mediaGroupID, err)
@@ -319,6 +513,14 @@ func (m *Manager) handleFeedbackContent(ctx context.Context, message *telego.Mes
 		return true, err // Processed (with error sent)
 	}
 
+	// --- Spam Limits ---
+	if violation := m.admitFeedback(userID, feedbackText, len(photoIDs) > 0 || len(videoIDs) > 0); violation != "" {
+		if violation != feedbackViolationTooShort {
+			m.SetUserState(userID, StateIdle) // Let them retry only if the text was too short
+		}
+		return true, m.sendFeedbackViolation(ctx, localizer, userID, chatID, violation)
+	}
+
 	// --- Save Feedback ---
 	feedbackForDB := &models.Feedback{
 		UserID:         userID,
@@ -328,6 +530,7 @@ func (m *Manager) handleFeedbackContent(ctx context.Context, message *telego.Mes
 		PhotoIDs:       photoIDs,
 		VideoIDs:       videoIDs,
 		MediaGroupID:   "", // Empty for single messages
+		Category:       m.getFeedbackCategory(userID),
 		OriginalChatID: chatID,
 		MessageID:      message.MessageID,
 		// SubmittedAt will be set by the repository
@@ -342,11 +545,12 @@ func (m *Manager) handleFeedbackContent(ctx context.Context, message *telego.Mes
 		return true, err                  // Processed (with error)
 	}
 
-	// --- Confirm and Reset State ---
+	m.countUserActivity(ctx, userID, models.UserCounterFeedback)
+	m.forwardFeedback(ctx, feedbackForDB)
+
+	// --- Confirm, Ask About a Reply and Reset State ---
 	m.SetUserState(userID, StateIdle) // Reset state after successful submission
-	confirmationMsg := locales.GetMessage(localizer, "MsgFeedbackReceivedConfirmation", nil, nil)
-	_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), confirmationMsg))
-	if err != nil {
+	if err = m.sendFeedbackContactQuestion(ctx, localizer, chatID, feedbackForDB); err != nil {
 		log.Printf("[HandleFeedbackContent] Error sending feedback confirmation to user %d: %v", userID, err)
 	}
 	return true, nil // Processed successfully
@@ -361,14 +565,22 @@ func (m *Manager) HandleFeedbackCommand(ctx context.Context, update telego.Updat
 
 	chatID := update.Message.Chat.ID
 	userID := update.Message.From.ID
-	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+	localizer := m.localizerForUser(ctx, update.Message.From)
+
+	// Muted users are told right away instead of being asked for feedback that would be refused
+	if !m.feedbackMutedUntil(userID).IsZero() {
+		log.Printf("[HandleFeedbackCommand User:%d] User is muted from feedback", userID)
+		return m.sendFeedbackViolation(ctx, localizer, userID, chatID, feedbackViolationMuted)
+	}
+
+	if consented, err := m.requireConsent(ctx, localizer, userID, chatID); !consented {
+		return err
+	}
 
-	// Set user state to await feedback content
-	m.SetUserState(userID, StateAwaitingFeedback)
+	// Ask for the category first; the content is requested once one is chosen
+	m.SetUserState(userID, StateChoosingFeedbackCategory)
 
-	// Send prompt to user
-	promptMsg := locales.GetMessage(localizer, "MsgFeedbackPrompt", nil, nil)
-	_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), promptMsg))
+	err := m.sendFeedbackCategoryPicker(ctx, localizer, chatID)
 	if err != nil {
 		// Rollback state if prompt fails
 		m.SetUserState(userID, StateIdle)
@@ -378,30 +590,52 @@ func (m *Manager) HandleFeedbackCommand(ctx context.Context, update telego.Updat
 		return fmt.Errorf("failed to send feedback prompt: %w", err)
 	}
 
-	log.Printf("[HandleFeedbackCommand User:%d] State set to StateAwaitingFeedback", userID)
+	log.Printf("[HandleFeedbackCommand User:%d] State set to StateChoosingFeedbackCategory", userID)
 	return nil
 }
 
-// SetUserState sets the state for a given user.
-func (m *Manager) SetUserState(userID int64, state UserState) {
-	m.muUserStates.Lock()
-	defer m.muUserStates.Unlock()
-	if state == StateIdle {
-		delete(m.userStates, userID)
-	} else {
-		m.userStates[userID] = state
+// userLanguage resolves the language for a user: the saved preference first,
+// then the Telegram client language, then the bot's default language.
+func (m *Manager) userLanguage(ctx context.Context, userID int64, telegramLang string) string {
+	savedLang, err := m.userRepo.GetUserLanguage(ctx, userID)
+	if err != nil {
+		log.Printf("Error loading saved language for user %d, using Telegram/default language: %v", userID, err)
+		savedLang = ""
 	}
+	return locales.ResolveLanguage(savedLang, telegramLang)
 }
 
-// GetUserState retrieves the state for a given user.
-func (m *Manager) GetUserState(userID int64) UserState {
-	m.muUserStates.RLock()
-	defer m.muUserStates.RUnlock()
-	return m.userStates[userID]
+// localizerForUser returns a localizer in the preferred language of the given Telegram user.
+func (m *Manager) localizerForUser(ctx context.Context, user *telego.User) *i18n.Localizer {
+	if user == nil {
+		return locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+	}
+	return locales.NewLocalizer(m.userLanguage(ctx, user.ID, user.LanguageCode))
+}
+
+// localizerForUserID returns a localizer for a user known only by ID (e.g., the admin of a review session).
+func (m *Manager) localizerForUserID(ctx context.Context, userID int64) *i18n.Localizer {
+	return locales.NewLocalizer(m.userLanguage(ctx, userID, ""))
 }
 
 // CheckSubscription checks if a user is a member of the target channel.
+// Results are cached per user (see SetSubscriptionCacheTTL). On a cache miss,
+// membership tracked from chat_member updates is used when available;
+// otherwise the status is fetched with GetChatMember.
 func (m *Manager) CheckSubscription(ctx context.Context, userID int64) (bool, error) {
+	if subscribed, ok := m.cachedSubscription(ctx, userID); ok {
+		return subscribed, nil
+	}
+
+	stored, err := m.membershipRepo.GetMemberStatus(ctx, m.targetChannelID, userID)
+	if err != nil {
+		log.Printf("Error loading stored membership for user %d, falling back to GetChatMember: %v", userID, err)
+	} else if stored != nil && m.memberStatusFresh(stored) {
+		subscribed := isSubscribedStatus(stored.Status)
+		m.cacheSubscription(ctx, userID, subscribed)
+		return subscribed, nil
+	}
+
 	memberPtr, err := m.bot.GetChatMember(ctx, &telego.GetChatMemberParams{
 		ChatID: telego.ChatID{ID: m.targetChannelID},
 		UserID: userID,
@@ -416,55 +650,83 @@ func (m *Manager) CheckSubscription(ctx context.Context, userID int64) (bool, er
 		return false, fmt.Errorf("failed to get chat member info (nil interface)")
 	}
 
-	// Get status using type assertion on the interface value
-	var status string
-	switch t := memberPtr.(type) {
-	case *telego.ChatMemberOwner:
-		status = t.Status
-	case *telego.ChatMemberAdministrator:
-		status = t.Status
-	case *telego.ChatMemberMember:
-		status = t.Status
-	case *telego.ChatMemberRestricted:
-		status = t.Status // Might still be considered a member depending on rules
-	case *telego.ChatMemberLeft:
-		status = t.Status // User has left
-	case *telego.ChatMemberBanned:
-		status = t.Status // User is banned
-	default:
-		log.Printf("Unknown chat member type for user %d in channel %d: %T", userID, m.targetChannelID, t)
-		return false, fmt.Errorf("unknown chat member type")
-	}
-
-	// Check against allowed statuses
-	allowedStatuses := []string{
-		telego.MemberStatusCreator,
-		telego.MemberStatusAdministrator,
-		telego.MemberStatusMember,
-		// telego.MemberStatusRestricted, // Decide if restricted users count
+	status := memberPtr.MemberStatus()
+	subscribed := isSubscribedStatus(status)
+	m.cacheSubscription(ctx, userID, subscribed)
+	if stored != nil {
+		// A chat_member update may have been missed; the fresh status is trusted for another TTL
+		m.refreshMemberStatus(ctx, stored, status)
 	}
-	for _, allowed := range allowedStatuses {
-		if status == allowed {
-			return true, nil
-		}
+	if !subscribed {
+		log.Printf("User %d has status '%s' in channel %d, which is not sufficient for subscription.", userID, status, m.targetChannelID)
 	}
+	return subscribed, nil
+}
 
-	log.Printf("User %d has status '%s' in channel %d, which is not sufficient for subscription.", userID, status, m.targetChannelID)
-	return false, nil // Not subscribed or has insufficient status (left, banned, restricted?)
+// SetUnitOfWork sets how operations that write to several collections are grouped,
+// e.g. in a MongoDB transaction. By default the writes run one by one.
+func (m *Manager) SetUnitOfWork(uow database.UnitOfWork) {
+	if uow == nil {
+		uow = database.NoTransaction
+	}
+	m.unitOfWork = uow
 }
 
 // AddSuggestion saves a new suggestion to the database.
 func (m *Manager) AddSuggestion(ctx context.Context, suggestion *models.Suggestion) error {
 	suggestion.Status = string(StatusPending)
-	err := m.repo.CreateSuggestion(ctx, suggestion)
+	if simulation.Active(ctx) {
+		suggestion.Test = true // Sent by the test user of /simulate
+	}
+	priority, err := m.suggestionPriority(ctx, suggestion.SuggesterID)
+	if err != nil {
+		log.Printf("Error getting review priority for user %d, using none: %v", suggestion.SuggesterID, err)
+	}
+	suggestion.Priority = priority
+	if suggestion.Source == "" {
+		suggestion.Source = m.suggestionSource(ctx, suggestion.SuggesterID)
+	}
+	err = m.createSuggestion(ctx, suggestion)
 	if err != nil {
 		log.Printf("Error creating suggestion in DB for user %d: %v", suggestion.SuggesterID, err)
 		return err
 	}
 	log.Printf("Created suggestion in DB with ID %s from user %d", suggestion.ID.Hex(), suggestion.SuggesterID)
+	m.countUserActivity(ctx, suggestion.SuggesterID, models.UserCounterSuggestions)
+	m.assignSuggestion(ctx, suggestion)
+	m.pingAdminsAboutSuggestion(ctx, suggestion)
+	m.recognizeSuggestionText(suggestion)
+	m.translateSuggestionCaption(suggestion.ID, suggestion.Caption)
 	return nil
 }
 
+// PurgeTestSuggestions deletes the test suggestions of /simulate and returns how many there were.
+func (m *Manager) PurgeTestSuggestions(ctx context.Context) (int64, error) {
+	deleted, err := m.repo.DeleteTestSuggestions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Deleted %d test suggestions", deleted)
+	return deleted, nil
+}
+
+// suggestionSaveErrorMessage returns the message ID telling a user why their suggestion
+// couldn't be saved.
+func suggestionSaveErrorMessage(err error) string {
+	if errors.Is(err, database.ErrSuggestionQuotaExceeded) {
+		return "MsgSuggestQueueFull"
+	}
+	return "MsgSuggestInternalProcessingError"
+}
+
+// countUserActivity increments a profile counter of a user. The counters are informational,
+// so errors are only logged.
+func (m *Manager) countUserActivity(ctx context.Context, userID int64, counter string) {
+	if err := m.userRepo.IncrementUserCounter(ctx, userID, counter); err != nil {
+		log.Printf("[UserStats User:%d] Error incrementing %s: %v", userID, counter, err)
+	}
+}
+
 // GetPendingSuggestions retrieves pending suggestions from the database.
 func (m *Manager) GetPendingSuggestions(ctx context.Context, limit int, offset int) ([]models.Suggestion, int64, error) {
 	return m.repo.GetPendingSuggestions(ctx, limit, offset)
@@ -501,22 +763,33 @@ func (m *Manager) processSuggestionMediaGroup(ctx context.Context, groupID strin
 	firstMessage := msgs[0]
 	userID := firstMessage.From.ID
 	chatID := firstMessage.Chat.ID
-	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+	localizer := m.localizerForUser(ctx, firstMessage.From)
 
 	log.Printf("[ProcessSuggestionMediaGroup Group:%s User:%d] Processing %d messages.", groupID, userID, len(msgs))
 
+	policy := m.ContentPolicy()
 	fileIDs := make([]string, 0, len(msgs))
+	mediaTypes := make([]string, 0, len(msgs))
+	var issues []models.MediaIssue
 	for _, msg := range msgs {
-		if msg.Photo != nil && len(msg.Photo) > 0 {
-			fileIDs = append(fileIDs, msg.Photo[len(msg.Photo)-1].FileID)
+		if media := suggestionMedia(&msg); policy.Accepts(media.Type) {
+			issues = append(issues, m.checkMedia(len(fileIDs), media)...)
+			fileIDs = append(fileIDs, media.FileID)
+			mediaTypes = append(mediaTypes, media.Type)
 		}
 	}
 
 	if len(fileIDs) == 0 {
-		log.Printf("[ProcessSuggestionMediaGroup Group:%s User:%d] No valid photos found in media group.", groupID, userID)
+		log.Printf("[ProcessSuggestionMediaGroup Group:%s User:%d] No accepted media found in media group.", groupID, userID)
 		m.SetUserState(userID, StateIdle) // Reset state
 		// Send error message?
-		return fmt.Errorf("no valid photos found in suggestion media group %s", groupID)
+		return fmt.Errorf("no accepted media found in suggestion media group %s", groupID)
+	}
+	if len(issues) > 0 && !m.mediaLimits.WarnOnly {
+		log.Printf("[ProcessSuggestionMediaGroup Group:%s User:%d] Media outside the media limits: %+v", groupID, userID, issues)
+		// The state is kept, so the user can send better media
+		_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), mediaRejectedMessage(localizer, issues, true)))
+		return err
 	}
 
 	// Use caption from the first message if available
@@ -529,24 +802,34 @@ func (m *Manager) processSuggestionMediaGroup(ctx context.Context, groupID strin
 		MessageID:   firstMessage.MessageID, // Use first message ID for reference
 		ChatID:      chatID,
 		FileIDs:     fileIDs,
+		MediaTypes:  storedMediaTypes(mediaTypes),
+		MediaIssues: issues,
 		Caption:     caption,
 		Status:      string(StatusPending),
 		SubmittedAt: time.Now(),
+
+		ForwardOrigin: forwardOriginFromMessage(&firstMessage),
+	}
+
+	if rejected, err := m.applyAutoRejectRules(ctx, localizer, suggestionForDB); rejected {
+		m.SetUserState(userID, StateIdle)
+		return err
+	}
+	if m.suggestionPreviewEnabled() && !submissionConfirmed(ctx) {
+		return m.previewSubmission(ctx, localizer, suggestionForDB)
 	}
 
 	err := m.AddSuggestion(ctx, suggestionForDB)
 	if err != nil {
 		log.Printf("[ProcessSuggestionMediaGroup Group:%s User:%d] Error saving suggestion: %v", groupID, userID, err)
-		errorMsg := locales.GetMessage(localizer, "MsgSuggestInternalProcessingError", nil, nil)
+		errorMsg := locales.GetMessage(localizer, suggestionSaveErrorMessage(err), nil, nil)
 		_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), errorMsg))
 		m.SetUserState(userID, StateIdle) // Reset state on error
 		return fmt.Errorf("failed to add suggestion for group %s: %w", groupID, err)
 	}
 
 	m.SetUserState(userID, StateIdle) // Reset state after successful processing
-	confirmationMsg := locales.GetMessage(localizer, "MsgSuggestionReceivedConfirmation", nil, nil)
-	_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), confirmationMsg))
-	if err != nil {
+	if err := m.sendSuggestionReceived(ctx, localizer, chatID, suggestionForDB); err != nil {
 		log.Printf("[ProcessSuggestionMediaGroup Group:%s User:%d] Error sending confirmation: %v", groupID, userID, err)
 		// Don't return error here, main operation succeeded
 	}
@@ -563,7 +846,7 @@ func (m *Manager) processFeedbackMediaGroup(ctx context.Context, groupID string,
 	firstMessage := msgs[0]
 	userID := firstMessage.From.ID
 	chatID := firstMessage.Chat.ID
-	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+	localizer := m.localizerForUser(ctx, firstMessage.From)
 
 	log.Printf("[ProcessFeedbackMediaGroup Group:%s User:%d] Processing %d messages.", groupID, userID, len(msgs))
 
@@ -592,6 +875,11 @@ func (m *Manager) processFeedbackMediaGroup(ctx context.Context, groupID string,
 		return fmt.Errorf("no valid media found in feedback media group %s", groupID)
 	}
 
+	if violation := m.admitFeedback(userID, feedbackText, true); violation != "" {
+		m.SetUserState(userID, StateIdle)
+		return m.sendFeedbackViolation(ctx, localizer, userID, chatID, violation)
+	}
+
 	feedbackForDB := &models.Feedback{
 		UserID:         userID,
 		Username:       firstMessage.From.Username,
@@ -600,6 +888,7 @@ func (m *Manager) processFeedbackMediaGroup(ctx context.Context, groupID string,
 		PhotoIDs:       photoIDs,
 		VideoIDs:       videoIDs,
 		MediaGroupID:   groupID,
+		Category:       m.getFeedbackCategory(userID),
 		SubmittedAt:    time.Now(),
 		OriginalChatID: chatID,
 		MessageID:      firstMessage.MessageID,
@@ -614,10 +903,11 @@ func (m *Manager) processFeedbackMediaGroup(ctx context.Context, groupID string,
 		return fmt.Errorf("failed to add feedback for group %s: %w", groupID, err)
 	}
 
+	m.countUserActivity(ctx, userID, models.UserCounterFeedback)
+	m.forwardFeedback(ctx, feedbackForDB)
+
 	m.SetUserState(userID, StateIdle) // Reset state after successful processing
-	confirmationMsg := locales.GetMessage(localizer, "MsgFeedbackReceivedConfirmation", nil, nil)
-	_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), confirmationMsg))
-	if err != nil {
+	if err = m.sendFeedbackContactQuestion(ctx, localizer, chatID, feedbackForDB); err != nil {
 		log.Printf("[ProcessFeedbackMediaGroup Group:%s User:%d] Error sending confirmation: %v", groupID, userID, err)
 	}
 	return nil // Success
@@ -630,6 +920,9 @@ func (m *Manager) HandleCombinedMediaGroup(ctx context.Context, groupID string,
 	}
 
 	firstMessage := messages[0]
+	if m.IsIntakeChat(firstMessage.Chat.ID) {
+		return m.processIntakeMediaGroup(ctx, groupID, messages)
+	}
 	userID := firstMessage.From.ID
 	currentState := m.GetUserState(userID)
 
@@ -640,6 +933,32 @@ func (m *Manager) HandleCombinedMediaGroup(ctx context.Context, groupID string,
 		return m.processSuggestionMediaGroup(ctx, groupID, messages)
 	case StateAwaitingFeedback:
 		return m.processFeedbackMediaGroup(ctx, groupID, messages)
+	case StateEditingCaption, StateAddingPhoto:
+		// Edits take a single photo or text message; albums are not accepted
+		localizer := m.localizerForUser(ctx, firstMessage.From)
+		msg := locales.GetMessage(localizer, "MsgSuggestionEditSinglePhoto", nil, nil)
+		_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(firstMessage.Chat.ID), msg))
+		return err
+	case StateReplyingToFeedback:
+		localizer := m.localizerForUser(ctx, firstMessage.From)
+		msg := locales.GetMessage(localizer, "MsgFeedbackReplyRequiresText", nil, nil)
+		_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(firstMessage.Chat.ID), msg))
+		return err
+	case StateAskingSuggester, StateAnsweringReviewer:
+		localizer := m.localizerForUser(ctx, firstMessage.From)
+		msg := locales.GetMessage(localizer, "MsgCommentRequiresText", map[string]interface{}{"Max": maxCommentLength}, nil)
+		_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(firstMessage.Chat.ID), msg))
+		return err
+	case StateConfirmingSuggestion:
+		localizer := m.localizerForUser(ctx, firstMessage.From)
+		msg := locales.GetMessage(localizer, "MsgSuggestConfirmFirst", nil, nil)
+		_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(firstMessage.Chat.ID), msg))
+		return err
+	case StateChoosingFeedbackCategory:
+		localizer := m.localizerForUser(ctx, firstMessage.From)
+		msg := locales.GetMessage(localizer, "MsgFeedbackChooseCategoryFirst", nil, nil)
+		_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(firstMessage.Chat.ID), msg))
+		return err
 	default:
 		log.Printf("[Manager.HandleCombinedMediaGroup Group:%s] User %d state %v is not awaiting suggestion or feedback. Ignoring.", groupID, userID, currentState)
 		return nil // Not an error, just not handled here
@@ -0,0 +1,118 @@
+package suggestions
+
+import (
+	"fmt"
+	"strings"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/videopolicy"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// MediaLimits are the size and resolution checks of suggested media, so reviewers don't spend
+// time on media that can't be published. Zero values disable the corresponding check, and
+// properties Telegram doesn't report, such as the resolution of documents, aren't checked.
+type MediaLimits struct {
+	MinResolution int   // Smallest allowed shorter side of photos, videos and GIFs, in pixels
+	MaxFileSize   int64 // Largest allowed file, in bytes
+	// WarnOnly accepts media outside the limits and shows the issues to reviewers instead of
+	// asking the suggester for other media
+	WarnOnly bool
+}
+
+// SetMediaLimits configures the checks of suggested media.
+func (m *Manager) SetMediaLimits(limits MediaLimits) {
+	m.mediaLimits = limits
+}
+
+// SetVideoPolicy configures the duration and aspect ratio checks of suggested videos. Videos
+// outside it are rejected or shown to reviewers like media outside the media limits, and
+// reviewers must override the policy before publishing them.
+func (m *Manager) SetVideoPolicy(policy videopolicy.Policy) {
+	m.videoPolicy = policy
+}
+
+// checkMedia returns the issues of the media at index of a suggestion with the media limits and,
+// for videos, the video policy.
+func (m *Manager) checkMedia(index int, media suggestedMedia) []models.MediaIssue {
+	issues := m.mediaLimits.check(index, media)
+	if media.Type == models.MediaTypeVideo {
+		issues = append(issues, m.videoPolicy.Check(index, media.Width, media.Height, media.Duration)...)
+	}
+	return issues
+}
+
+// check returns the issues of the media at index of a suggestion with the limits.
+func (l MediaLimits) check(index int, media suggestedMedia) []models.MediaIssue {
+	var issues []models.MediaIssue
+	if l.MinResolution > 0 && media.Width > 0 && media.Height > 0 && min(media.Width, media.Height) < l.MinResolution {
+		issues = append(issues, models.MediaIssue{
+			Index:  index,
+			Kind:   models.MediaIssueLowResolution,
+			Width:  media.Width,
+			Height: media.Height,
+			Limit:  int64(l.MinResolution),
+		})
+	}
+	if l.MaxFileSize > 0 && media.FileSize > l.MaxFileSize {
+		issues = append(issues, models.MediaIssue{
+			Index:    index,
+			Kind:     models.MediaIssueFileTooLarge,
+			FileSize: media.FileSize,
+			Limit:    l.MaxFileSize,
+		})
+	}
+	return issues
+}
+
+// DescribeMediaIssues lists media issues on separate lines. Issues of albums name the item.
+func DescribeMediaIssues(localizer *i18n.Localizer, issues []models.MediaIssue, album bool) string {
+	lines := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		var line string
+		switch issue.Kind {
+		case models.MediaIssueLowResolution:
+			line = locales.GetMessage(localizer, "MsgMediaIssueLowResolution", map[string]interface{}{
+				"Width":  issue.Width,
+				"Height": issue.Height,
+				"Min":    issue.Limit,
+			}, nil)
+		case models.MediaIssueFileTooLarge:
+			line = locales.GetMessage(localizer, "MsgMediaIssueFileTooLarge", map[string]interface{}{
+				"Size": megabytes(issue.FileSize),
+				"Max":  megabytes(issue.Limit),
+			}, nil)
+		case models.MediaIssueVideoTooLong:
+			line = locales.GetMessage(localizer, "MsgMediaIssueVideoTooLong", map[string]interface{}{
+				"Duration": issue.Duration,
+				"Max":      issue.Limit,
+			}, nil)
+		case models.MediaIssueAspectRatio:
+			line = locales.GetMessage(localizer, "MsgMediaIssueAspectRatio", map[string]interface{}{
+				"Width":  issue.Width,
+				"Height": issue.Height,
+				"Range":  issue.Range,
+			}, nil)
+		default:
+			continue
+		}
+		if album {
+			line = locales.GetMessage(localizer, "MsgMediaIssueItem", map[string]interface{}{"Item": issue.Index + 1, "Issue": line}, nil)
+		}
+		lines = append(lines, "• "+line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mediaRejectedMessage asks a suggester for other media than the media with the issues.
+func mediaRejectedMessage(localizer *i18n.Localizer, issues []models.MediaIssue, album bool) string {
+	return locales.GetMessage(localizer, "MsgSuggestionMediaRejected", map[string]interface{}{
+		"Issues": DescribeMediaIssues(localizer, issues, album),
+	}, nil)
+}
+
+// megabytes formats a size in bytes as megabytes with one decimal, e.g. "12.5".
+func megabytes(size int64) string {
+	return fmt.Sprintf("%.1f", float64(size)/(1<<20))
+}
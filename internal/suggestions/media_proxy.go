@@ -0,0 +1,92 @@
+package suggestions
+
+import (
+	"context"
+	"fmt"
+	"vrcmemes-bot/internal/mediaproxy"
+	"vrcmemes-bot/pkg/utils"
+
+	"github.com/mymmrac/telego"
+)
+
+// SetMediaProxy enables privacy mode: suggested media is downloaded and uploaded again for review
+// and publishing, so posts don't reuse the file IDs users sent. Nil sends the original file IDs.
+func (m *Manager) SetMediaProxy(proxy *mediaproxy.Proxy) {
+	m.mediaProxy = proxy
+}
+
+// proxiedMedia returns copies of media whose files are replaced by re-uploads through the media
+// proxy. Each call downloads afresh what hasn't been uploaded yet, so a failed send can be retried
+// with a new call. Without a proxy, media is returned as is.
+func (m *Manager) proxiedMedia(ctx context.Context, media []telego.InputMedia) ([]telego.InputMedia, error) {
+	if m.mediaProxy == nil {
+		return media, nil
+	}
+	proxied := make([]telego.InputMedia, len(media))
+	for i, item := range media {
+		switch original := item.(type) {
+		case *telego.InputMediaPhoto:
+			file, err := m.mediaProxy.InputFile(ctx, original.Media.FileID, "photo.jpg")
+			if err != nil {
+				return nil, fmt.Errorf("failed to proxy photo %d: %w", i+1, err)
+			}
+			photo := *original
+			photo.Media = file
+			proxied[i] = &photo
+		case *telego.InputMediaVideo:
+			if original.Media.File != nil {
+				// Already uploaded with a custom thumbnail, see thumbnail.go
+				proxied[i] = original
+				continue
+			}
+			file, err := m.mediaProxy.InputFile(ctx, original.Media.FileID, "video.mp4")
+			if err != nil {
+				return nil, fmt.Errorf("failed to proxy video %d: %w", i+1, err)
+			}
+			video := *original
+			video.Media = file
+			proxied[i] = &video
+		case *telego.InputMediaAnimation:
+			file, err := m.mediaProxy.InputFile(ctx, original.Media.FileID, "animation.mp4")
+			if err != nil {
+				return nil, fmt.Errorf("failed to proxy GIF %d: %w", i+1, err)
+			}
+			animation := *original
+			animation.Media = file
+			proxied[i] = &animation
+		case *telego.InputMediaDocument:
+			file, err := m.mediaProxy.InputFile(ctx, original.Media.FileID, "document")
+			if err != nil {
+				return nil, fmt.Errorf("failed to proxy document %d: %w", i+1, err)
+			}
+			document := *original
+			document.Media = file
+			proxied[i] = &document
+		default:
+			return nil, fmt.Errorf("can't proxy media of type %s", item.MediaType())
+		}
+	}
+	return proxied, nil
+}
+
+// rememberProxied records the files of sent messages as the bot's uploads of the original media,
+// which is in the same order.
+func (m *Manager) rememberProxied(media []telego.InputMedia, sent []telego.Message) {
+	if m.mediaProxy == nil {
+		return
+	}
+	for i := 0; i < len(media) && i < len(sent); i++ {
+		var original string
+		switch item := media[i].(type) {
+		case *telego.InputMediaPhoto:
+			original = item.Media.FileID
+		case *telego.InputMediaVideo:
+			original = item.Media.FileID
+		case *telego.InputMediaAnimation:
+			original = item.Media.FileID
+		case *telego.InputMediaDocument:
+			original = item.Media.FileID
+		}
+		m.mediaProxy.Remember(original, utils.MediaFileID(&sent[i]))
+	}
+}
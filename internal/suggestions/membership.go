@@ -0,0 +1,176 @@
+package suggestions
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+	"vrcmemes-bot/internal/auth"
+	"vrcmemes-bot/internal/cache"
+	"vrcmemes-bot/internal/database/models"
+
+	"github.com/mymmrac/telego"
+)
+
+// subscriptionKeyPrefix starts the cache keys of subscription checks.
+const subscriptionKeyPrefix = "subscription:"
+
+// subscriptionKey returns the cache key of a user's subscription check.
+func subscriptionKey(userID int64) string {
+	return subscriptionKeyPrefix + strconv.FormatInt(userID, 10)
+}
+
+// isSubscribedStatus reports whether a Telegram member status counts as subscribed to the channel.
+// Restricted users are not counted as subscribers.
+func isSubscribedStatus(status string) bool {
+	switch status {
+	case telego.MemberStatusCreator, telego.MemberStatusAdministrator, telego.MemberStatusMember:
+		return true
+	default:
+		return false
+	}
+}
+
+// HandleChatMemberUpdate records a chat_member update for the target channel.
+// It keeps the stored membership status current (used by CheckSubscription),
+// invalidates the cached admin status on promotions and demotions, and
+// logs joins/leaves as membership events for growth analytics.
+func (m *Manager) HandleChatMemberUpdate(ctx context.Context, update telego.ChatMemberUpdated) error {
+	if update.Chat.ID != m.targetChannelID {
+		return nil // Only the target channel is tracked
+	}
+	if update.OldChatMember == nil || update.NewChatMember == nil {
+		return fmt.Errorf("chat member update for chat %d is missing member info", update.Chat.ID)
+	}
+
+	oldStatus := update.OldChatMember.MemberStatus()
+	newStatus := update.NewChatMember.MemberStatus()
+	user := update.NewChatMember.MemberUser()
+	occurredAt := time.Unix(update.Date, 0)
+
+	wasMember := isSubscribedStatus(oldStatus)
+	isMember := isSubscribedStatus(newStatus)
+
+	member := models.ChannelMember{
+		ChatID:    update.Chat.ID,
+		UserID:    user.ID,
+		Username:  user.Username,
+		Status:    newStatus,
+		UpdatedAt: occurredAt,
+	}
+	if isMember && !wasMember {
+		member.JoinedAt = occurredAt
+	}
+	m.invalidateSubscription(user.ID) // The cached check is stale regardless of whether saving succeeds
+	if auth.IsAdminStatus(oldStatus) != auth.IsAdminStatus(newStatus) {
+		m.adminChecker.Invalidate(user.ID) // Promoted or demoted
+		m.forgetChannelAdmins()
+	}
+
+	// The member status and the join/leave event are saved together
+	err := m.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := m.membershipRepo.SaveMemberStatus(ctx, member); err != nil {
+			return fmt.Errorf("failed to save member status: %w", err)
+		}
+		if wasMember == isMember {
+			return nil // Status change within the same side (e.g., promoted to admin), not a join/leave
+		}
+		event := &models.MembershipEvent{
+			ChatID:     update.Chat.ID,
+			UserID:     user.ID,
+			Username:   user.Username,
+			OldStatus:  oldStatus,
+			NewStatus:  newStatus,
+			Joined:     isMember,
+			OccurredAt: occurredAt,
+		}
+		if err := m.membershipRepo.RecordMembershipEvent(ctx, event); err != nil {
+			return fmt.Errorf("failed to record membership event: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[Membership User:%d Chat:%d] %s -> %s", user.ID, update.Chat.ID, oldStatus, newStatus)
+	return nil
+}
+
+// SetSubscriptionCacheTTL configures how long subscription checks are cached, by default
+// config.DefaultSubscriptionCacheTTL and config.DefaultSubscriptionNegativeCacheTTL.
+// A zero duration disables caching for that kind of result. Expired checks are evicted by the cache.
+func (m *Manager) SetSubscriptionCacheTTL(positive, negative time.Duration) {
+	m.subscriptionCacheMutex.Lock()
+	defer m.subscriptionCacheMutex.Unlock()
+	m.subscriptionTTL = positive
+	m.subscriptionNegativeTTL = negative
+	if err := m.cache.DeletePrefix(context.Background(), subscriptionKeyPrefix); err != nil {
+		log.Printf("[SubscriptionCache] Error dropping cached subscription checks: %v", err)
+	}
+}
+
+// SetMemberStatusTTL sets how long a membership stored from chat_member updates is trusted by
+// subscription checks, after which Telegram is asked again. Zero always asks Telegram.
+func (m *Manager) SetMemberStatusTTL(ttl time.Duration) {
+	m.subscriptionCacheMutex.Lock()
+	defer m.subscriptionCacheMutex.Unlock()
+	m.memberStatusTTL = ttl
+}
+
+// memberStatusFresh reports whether a stored membership is recent enough to be trusted.
+func (m *Manager) memberStatusFresh(member *models.ChannelMember) bool {
+	m.subscriptionCacheMutex.RLock()
+	ttl := m.memberStatusTTL
+	m.subscriptionCacheMutex.RUnlock()
+	return time.Since(member.UpdatedAt) < ttl
+}
+
+// refreshMemberStatus stores the status Telegram reported for a user with a stale stored membership.
+func (m *Manager) refreshMemberStatus(ctx context.Context, stored *models.ChannelMember, status string) {
+	member := models.ChannelMember{
+		ChatID:    stored.ChatID,
+		UserID:    stored.UserID,
+		Status:    status,
+		UpdatedAt: time.Now(),
+	}
+	if status != stored.Status {
+		log.Printf("[Membership User:%d Chat:%d] Stored status %s was stale, Telegram reports %s", stored.UserID, stored.ChatID, stored.Status, status)
+	}
+	if err := m.membershipRepo.SaveMemberStatus(ctx, member); err != nil {
+		log.Printf("[Membership User:%d Chat:%d] Error refreshing stored status: %v", stored.UserID, stored.ChatID, err)
+	}
+}
+
+// cachedSubscription returns the cached subscription result for a user, if still valid.
+func (m *Manager) cachedSubscription(ctx context.Context, userID int64) (subscribed bool, ok bool) {
+	found, err := cache.GetJSON(ctx, m.cache, subscriptionKey(userID), &subscribed)
+	if err != nil {
+		log.Printf("[SubscriptionCache User:%d] Error reading cached subscription check: %v", userID, err)
+		return false, false
+	}
+	return subscribed, found
+}
+
+// cacheSubscription stores a subscription result using the positive or negative TTL.
+func (m *Manager) cacheSubscription(ctx context.Context, userID int64, subscribed bool) {
+	m.subscriptionCacheMutex.RLock()
+	ttl := m.subscriptionNegativeTTL
+	if subscribed {
+		ttl = m.subscriptionTTL
+	}
+	m.subscriptionCacheMutex.RUnlock()
+	if ttl <= 0 {
+		return
+	}
+	if err := cache.SetJSON(ctx, m.cache, subscriptionKey(userID), subscribed, ttl); err != nil {
+		log.Printf("[SubscriptionCache User:%d] Error caching subscription check: %v", userID, err)
+	}
+}
+
+// invalidateSubscription drops the cached subscription result for a user.
+func (m *Manager) invalidateSubscription(userID int64) {
+	if err := m.cache.Delete(context.Background(), subscriptionKey(userID)); err != nil {
+		log.Printf("[SubscriptionCache User:%d] Error dropping cached subscription check: %v", userID, err)
+	}
+}
@@ -0,0 +1,268 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// mergeCallbackPrefix starts the callback data of the merge picker buttons: merge:<ref>:<index>:<duplicate ref>.
+// An empty duplicate reference closes the picker.
+const mergeCallbackPrefix = "merge:"
+
+// mergeCallback is the parsed callback data of a merge picker button.
+type mergeCallback struct {
+	Ref       string // Reference of the suggestion that is kept
+	Index     int    // Position of the kept suggestion in the admin's review batch
+	Duplicate string // Reference of the duplicate to reject, or "" to close the picker
+}
+
+// String formats the callback data for an inline button.
+func (c mergeCallback) String() string {
+	return fmt.Sprintf("%s%s:%d:%s", mergeCallbackPrefix, c.Ref, c.Index, c.Duplicate)
+}
+
+// errMergeCallbackMalformed is returned by parseMergeCallback for data not produced by mergeCallback.String.
+var errMergeCallbackMalformed = errors.New("malformed merge callback data")
+
+// parseMergeCallback parses merge picker callback data.
+func parseMergeCallback(data string) (mergeCallback, error) {
+	parts := strings.SplitN(strings.TrimPrefix(data, mergeCallbackPrefix), ":", 3)
+	if !strings.HasPrefix(data, mergeCallbackPrefix) || len(data) > maxCallbackDataLength || len(parts) != 3 {
+		return mergeCallback{}, fmt.Errorf("%w: %q", errMergeCallbackMalformed, data)
+	}
+	if !isSuggestionRef(parts[0]) {
+		return mergeCallback{}, fmt.Errorf("%w: invalid suggestion reference %q", errMergeCallbackMalformed, parts[0])
+	}
+	index, err := strconv.Atoi(parts[1])
+	if err != nil || index < 0 {
+		return mergeCallback{}, fmt.Errorf("%w: invalid index %q", errMergeCallbackMalformed, parts[1])
+	}
+	if parts[2] != "" && (!isSuggestionRef(parts[2]) || parts[2] == parts[0]) {
+		return mergeCallback{}, fmt.Errorf("%w: invalid duplicate reference %q", errMergeCallbackMalformed, parts[2])
+	}
+	return mergeCallback{Ref: parts[0], Index: index, Duplicate: parts[2]}, nil
+}
+
+// SetCreditCoSuggesters sets whether the suggesters of duplicates merged into a published
+// suggestion are named in its caption next to its own suggester. Each is credited by the same
+// rules as a suggester.
+func (m *Manager) SetCreditCoSuggesters(enabled bool) {
+	m.creditCoSuggesters = enabled
+}
+
+// mergePickerKeyboard lists the other suggestions of the review batch, so the reviewer can pick
+// the duplicate of the current one.
+func mergePickerKeyboard(localizer *i18n.Localizer, suggestions []models.Suggestion, index int) *telego.InlineKeyboardMarkup {
+	kept := &suggestions[index]
+	var rows [][]telego.InlineKeyboardButton
+	for i := range suggestions {
+		if i == index {
+			continue
+		}
+		text := locales.GetMessage(localizer, "BtnMergeCandidate", map[string]interface{}{
+			"Index": i + 1,
+			"Code":  suggestions[i].Ref(),
+			"Name":  suggesterDisplayName(&suggestions[i]),
+		}, nil)
+		data := mergeCallback{Ref: kept.Ref(), Index: index, Duplicate: suggestions[i].Ref()}.String()
+		rows = append(rows, tu.InlineKeyboardRow(tu.InlineKeyboardButton(text).WithCallbackData(data)))
+	}
+	cancelData := mergeCallback{Ref: kept.Ref(), Index: index}.String()
+	rows = append(rows, tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnMergeCancel", nil, nil)).WithCallbackData(cancelData),
+	))
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// showMergePicker replaces the review buttons of the current suggestion with the merge picker.
+func (m *Manager) showMergePicker(ctx context.Context, queryID string, adminID int64, session *ReviewSession, index int) error {
+	localizer := m.localizerForUserID(ctx, adminID)
+
+	m.reviewSessionsMutex.RLock()
+	if len(session.Suggestions) < 2 {
+		m.reviewSessionsMutex.RUnlock()
+		return m.answerCallbackQuery(ctx, queryID, locales.GetMessage(localizer, "MsgMergeNoCandidates", nil, nil), true)
+	}
+	keyboard := mergePickerKeyboard(localizer, session.Suggestions, index)
+	suggestionID := session.Suggestions[index].ID
+	chatID, messageID := session.ReviewChatID, session.CurrentControlMessageID
+	m.reviewSessionsMutex.RUnlock()
+
+	_ = m.answerCallbackQuery(ctx, queryID, locales.GetMessage(localizer, "MsgMergePick", nil, nil), false)
+	_, err := m.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+		ChatID:      tu.ID(chatID),
+		MessageID:   messageID,
+		ReplyMarkup: keyboard,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to show merge picker for suggestion %s: %w", suggestionID.Hex(), err)
+	}
+	return nil
+}
+
+// handleMergeCallback handles the merge picker buttons. The picked duplicate is rejected and its
+// suggester, and those merged into it before, become co-suggesters of the current suggestion; both
+// documents are updated in one unit of work. The duplicate leaves the batch and the current
+// suggestion is shown again.
+func (m *Manager) handleMergeCallback(ctx context.Context, query telego.CallbackQuery) error {
+	adminID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	parsed, err := parseMergeCallback(query.Data)
+	if err != nil {
+		log.Printf("[MergeCallback] Rejected callback data %q: %v", query.Data, err)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return err
+	}
+
+	isAdmin, err := m.adminChecker.IsAdmin(ctx, adminID)
+	if err != nil || !isAdmin {
+		log.Printf("[MergeCallback] User %d is not admin (err: %v), ignoring merge action.", adminID, err)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil), true)
+		return nil
+	}
+
+	session, ok := m.activeReviewSession(adminID)
+	m.reviewSessionsMutex.RLock()
+	valid := ok && parsed.Index < len(session.Suggestions) && session.Suggestions[parsed.Index].Ref() == parsed.Ref
+	var kept, duplicate models.Suggestion
+	duplicateFound := false
+	if valid {
+		kept = session.Suggestions[parsed.Index]
+		for i := range session.Suggestions {
+			if parsed.Duplicate != "" && session.Suggestions[i].Ref() == parsed.Duplicate {
+				duplicate, duplicateFound = session.Suggestions[i], true
+			}
+		}
+	}
+	total := 0
+	var chatID int64
+	var messageID int
+	if ok {
+		total = len(session.Suggestions)
+		chatID, messageID = session.ReviewChatID, session.CurrentControlMessageID
+	}
+	m.reviewSessionsMutex.RUnlock()
+	if !valid || (parsed.Duplicate != "" && !duplicateFound) {
+		log.Printf("[MergeCallback] Invalid session or suggestion mismatch for admin %d, index %d, ref %s", adminID, parsed.Index, parsed.Ref)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgReviewSessionExpired", nil, nil), true)
+		return nil
+	}
+	m.touchReviewSession(adminID)
+
+	if parsed.Duplicate == "" {
+		_ = m.answerCallbackQuery(ctx, query.ID, "", false)
+		if _, err := m.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+			ChatID:      tu.ID(chatID),
+			MessageID:   messageID,
+			ReplyMarkup: m.reviewKeyboard(localizer, &kept, parsed.Index, total),
+		}); err != nil {
+			log.Printf("[MergeCallback] Error restoring review buttons for suggestion %s: %v", kept.ID.Hex(), err)
+		}
+		return nil
+	}
+
+	coSuggesters := mergedCoSuggesters(&kept, &duplicate)
+	err = m.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := m.repo.MarkSuggestionDuplicate(ctx, duplicate.ID, kept.ID, adminID, query.From.Username); err != nil {
+			return fmt.Errorf("failed to reject duplicate %s: %w", duplicate.ID.Hex(), err)
+		}
+		if len(coSuggesters) == 0 {
+			return nil
+		}
+		if err := m.repo.AddCoSuggesters(ctx, kept.ID, coSuggesters); err != nil {
+			return fmt.Errorf("failed to add co-suggesters to %s: %w", kept.ID.Hex(), err)
+		}
+		return nil
+	})
+	if errors.Is(err, database.ErrSuggestionNotFound) {
+		log.Printf("[MergeCallback] Admin %d could not merge %s into %s: %v", adminID, duplicate.ID.Hex(), kept.ID.Hex(), err)
+		return m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgMergeUnavailable", nil, nil), true)
+	}
+	if err != nil {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return err
+	}
+	log.Printf("[MergeCallback] Admin %d merged suggestion %s into %s", adminID, duplicate.ID.Hex(), kept.ID.Hex())
+
+	_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgMergeDone", map[string]interface{}{
+		"Duplicate": duplicate.Ref(),
+		"Code":      kept.Ref(),
+	}, nil), false)
+	go m.deleteReviewMessages(context.Background(), chatID, session.CurrentMediaMessageIDs, messageID)
+
+	// Positions in the batch may have changed meanwhile, so both suggestions are looked up by ID
+	m.reviewSessionsMutex.Lock()
+	currentSession, ok := m.reviewSessions[adminID]
+	if !ok {
+		m.reviewSessionsMutex.Unlock()
+		log.Printf("[MergeCallback Admin:%d] Session disappeared before removing the duplicate.", adminID)
+		return nil
+	}
+	for i := range currentSession.Suggestions {
+		if currentSession.Suggestions[i].ID == duplicate.ID {
+			currentSession.Suggestions = append(currentSession.Suggestions[:i], currentSession.Suggestions[i+1:]...)
+			break
+		}
+	}
+	m.releaseClaimLocked(duplicate.ID)
+	keptIndex := -1
+	for i := range currentSession.Suggestions {
+		if currentSession.Suggestions[i].ID == kept.ID {
+			currentSession.Suggestions[i].CoSuggesters = append(currentSession.Suggestions[i].CoSuggesters, coSuggesters...)
+			keptIndex = i
+		}
+	}
+	if keptIndex < 0 {
+		err := m.sendNextOrFinishReview(ctx, adminID, currentSession)
+		m.reviewSessionsMutex.Unlock()
+		return err
+	}
+	m.reviewSessionsMutex.Unlock()
+	return m.SendReviewMessage(ctx, chatID, adminID, keptIndex)
+}
+
+// mergedCoSuggesters returns who becomes a co-suggester of kept when duplicate is merged into it:
+// the duplicate's suggester and co-suggesters, leaving out kept's suggester and co-suggesters.
+func mergedCoSuggesters(kept, duplicate *models.Suggestion) []models.CoSuggester {
+	candidates := append([]models.CoSuggester{{
+		SuggesterID: duplicate.SuggesterID,
+		Username:    duplicate.Username,
+		FirstName:   duplicate.FirstName,
+		RefCode:     duplicate.RefCode,
+		Credit:      duplicate.Credit,
+	}}, duplicate.CoSuggesters...)
+
+	known := map[int64]bool{kept.SuggesterID: true}
+	for _, co := range kept.CoSuggesters {
+		known[co.SuggesterID] = true
+	}
+	var added []models.CoSuggester
+	for _, co := range candidates {
+		if known[co.SuggesterID] {
+			continue
+		}
+		known[co.SuggesterID] = true
+		added = append(added, co)
+	}
+	return added
+}
+
+// coSuggesterDisplayName returns how a co-suggester is named, like suggesterDisplayName.
+func coSuggesterDisplayName(co models.CoSuggester) string {
+	if co.Username != "" {
+		return "@" + co.Username
+	}
+	return co.FirstName
+}
@@ -1,6 +1,7 @@
 package suggestions
 
 import (
+	"time"
 	"vrcmemes-bot/internal/database/models"
 )
 
@@ -17,9 +18,17 @@ const (
 type UserState string
 
 const (
-	StateIdle               UserState = ""                    // Default state
-	StateAwaitingSuggestion UserState = "awaiting_suggestion" // Bot is waiting for the user to send suggestion content
-	StateAwaitingFeedback   UserState = "awaiting_feedback"   // Bot is waiting for the user to send feedback content
+	StateIdle                     UserState = ""                           // Default state
+	StateAwaitingSuggestion       UserState = "awaiting_suggestion"        // Bot is waiting for the user to send suggestion content
+	StateAwaitingFeedback         UserState = "awaiting_feedback"          // Bot is waiting for the user to send feedback content
+	StateEditingCaption           UserState = "editing_caption"            // Bot is waiting for a new caption for the user's pending suggestion
+	StateAddingPhoto              UserState = "adding_photo"               // Bot is waiting for an extra photo for the user's pending suggestion
+	StateReplyingToFeedback       UserState = "replying_to_feedback"       // Bot is waiting for an admin's reply to forwarded feedback
+	StateChoosingFeedbackCategory UserState = "choosing_feedback_category" // Bot is waiting for the user to pick a feedback category
+	StateAskingSuggester          UserState = "asking_suggester"           // Bot is waiting for a reviewer's question to the suggester
+	StateAnsweringReviewer        UserState = "answering_reviewer"         // Bot is waiting for the suggester's answer to a reviewer's question
+	StateConfirmingSuggestion     UserState = "confirming_suggestion"      // Bot is waiting for the user to confirm their previewed suggestion
+	StateChoosingThumbnail        UserState = "choosing_thumbnail"         // Bot is waiting for a reviewer's frame time or image for a video thumbnail
 )
 
 // ReviewSession stores the state for an admin's review process.
@@ -31,6 +40,10 @@ type ReviewSession struct {
 	CurrentMediaMessageIDs  []int               // IDs of the messages containing the media being reviewed
 	CurrentControlMessageID int                 // ID of the message containing the Approve/Reject/Next buttons
 	LastReviewMessageID     int                 // Message ID of the last sent review prompt
+	StartedAt               time.Time           // When the session was started
+	LastActivityAt          time.Time           // Last admin interaction; used to expire inactive sessions
+	ReminderMessageID       int                 // ID of the unanswered "Continue reviewing?" reminder, if any
+	SnoozedUntil            time.Time           // When a snoozed reminder is due again; the session doesn't expire before
 }
 
 // Note: The 'Suggestion' struct defined in the original file seems like a local representation
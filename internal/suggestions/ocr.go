@@ -0,0 +1,75 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/ocr"
+)
+
+// TextRecognizer reads the text in a Telegram photo. *ocr.Reader implements it.
+type TextRecognizer interface {
+	ReadFile(ctx context.Context, fileID string) (string, error)
+}
+
+// SetTextRecognizer enables OCR of suggested photos: the recognized text is stored with the
+// suggestion, found by /find and compared with earlier suggestions. nil disables it.
+func (m *Manager) SetTextRecognizer(recognizer TextRecognizer) {
+	m.textRecognizer = recognizer
+}
+
+// recognizeSuggestionText reads the text of a new suggestion's photos in the background and stores
+// it, together with the earlier suggestion with the same text if there is one, so reviewers see
+// likely duplicates even when neither has a caption. Errors are only logged.
+func (m *Manager) recognizeSuggestionText(suggestion *models.Suggestion) {
+	if m.textRecognizer == nil || len(suggestion.FileIDs) == 0 {
+		return
+	}
+	id := suggestion.ID
+	var fileIDs []string
+	for i, fileID := range suggestion.FileIDs {
+		if suggestion.MediaType(i) == models.MediaTypePhoto {
+			fileIDs = append(fileIDs, fileID)
+		}
+	}
+	go func() {
+		ctx := context.Background()
+		var texts []string
+		for _, fileID := range fileIDs {
+			text, err := m.textRecognizer.ReadFile(ctx, fileID)
+			if err != nil {
+				log.Printf("[OCR Suggestion:%s] Error recognizing text of file %s: %v", id.Hex(), fileID, err)
+				continue
+			}
+			if text != "" {
+				texts = append(texts, text)
+			}
+		}
+		if len(texts) == 0 {
+			return
+		}
+		text := strings.Join(texts, "\n")
+		key := ocr.Key(text)
+
+		similarTo := ""
+		if key != "" {
+			similar, err := m.repo.FindSuggestionByOCRKey(ctx, key, id)
+			switch {
+			case err == nil:
+				similarTo = similar.Ref()
+			case !errors.Is(err, database.ErrSuggestionNotFound):
+				log.Printf("[OCR Suggestion:%s] Error looking for suggestions with the same text: %v", id.Hex(), err)
+			}
+		}
+		if err := m.repo.SetSuggestionOCR(ctx, id, text, key, similarTo); err != nil {
+			log.Printf("[OCR Suggestion:%s] Error saving recognized text: %v", id.Hex(), err)
+			return
+		}
+		if similarTo != "" {
+			log.Printf("[OCR Suggestion:%s] Recognized text matches suggestion %s", id.Hex(), similarTo)
+		}
+	}()
+}
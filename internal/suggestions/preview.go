@@ -0,0 +1,140 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/publisher"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// promoteCallbackPrefix prefixes callback data of the Promote button sent after a preview ("promote:<ref>").
+const promoteCallbackPrefix = "promote:"
+
+// SetPreviewChannelID sets the preview channel: reviewers can approve a suggestion with "Preview first",
+// which posts it there, and promote the preview to the channel once it looks right. Zero disables it.
+func (m *Manager) SetPreviewChannelID(chatID int64) {
+	m.previewChannelID = chatID
+}
+
+// previewSuggestion queues the approved suggestion for the preview channel and returns its position
+// in the publish queue. Once it is posted, the reviewer is sent a Promote button in reviewChatID.
+// The caption, rubric number included, is built for the preview and copied as is when promoted.
+func (m *Manager) previewSuggestion(ctx context.Context, suggestion *models.Suggestion, reviewChatID, adminID int64) (int, error) {
+	if suggestion.Test {
+		// Test suggestions of /simulate are not posted to the preview channel either
+		log.Printf("[Preview Suggestion:%s] Not previewing test suggestion", suggestion.Ref())
+		return 0, nil
+	}
+	return m.queueSuggestionPublication(ctx, suggestion, m.previewChannelID, "preview:"+suggestion.ID.Hex(), reviewChatID, adminID, func(ctx context.Context, messageIDs []int) {
+		if err := m.repo.SetSuggestionPreview(ctx, suggestion.ID, m.previewChannelID, messageIDs); err != nil {
+			log.Printf("[Preview Suggestion:%s] Error saving preview messages: %v", suggestion.ID.Hex(), err)
+			return
+		}
+		localizer := m.localizerForUserID(ctx, adminID)
+		keyboard := tu.InlineKeyboard(tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnPromote", nil, nil)).WithCallbackData(promoteCallbackPrefix + suggestion.Ref()),
+		))
+		text := locales.GetMessage(localizer, "MsgPreviewPosted", nil, nil)
+		if _, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(reviewChatID), text).WithReplyMarkup(keyboard)); err != nil {
+			log.Printf("[Preview Suggestion:%s] Error sending Promote button to admin %d: %v", suggestion.ID.Hex(), adminID, err)
+		}
+	})
+}
+
+// handlePromoteCallback copies a previewed suggestion from the preview channel to the channel.
+// The publication shares its publish queue key with direct approvals, so a suggestion is never
+// promoted twice.
+func (m *Manager) handlePromoteCallback(ctx context.Context, query telego.CallbackQuery) error {
+	adminID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	ref := strings.TrimPrefix(query.Data, promoteCallbackPrefix)
+	if !isSuggestionRef(ref) {
+		log.Printf("[Promote Admin:%d] Rejected callback data %q", adminID, query.Data)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("malformed promote callback data %q: %w", query.Data, errInvalidSuggestionRef)
+	}
+	isAdmin, err := m.adminChecker.IsAdmin(ctx, adminID)
+	if err != nil {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("promote admin check failed for user %d: %w", adminID, err)
+	}
+	if !isAdmin {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil), true)
+		return nil
+	}
+
+	suggestion, err := m.suggestionByRef(ctx, ref)
+	if err != nil && !errors.Is(err, database.ErrSuggestionNotFound) {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("failed to load suggestion %s: %w", ref, err)
+	}
+	if suggestion == nil || suggestion.Status != string(models.StatusApproved) || len(suggestion.PreviewMessageIDs) == 0 {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgPromoteUnavailable", nil, nil), true)
+		return nil
+	}
+	if suggestion.Test {
+		log.Printf("[Promote Admin:%d] Not promoting test suggestion %s", adminID, suggestion.Ref())
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgPromoteUnavailable", nil, nil), true)
+		return nil
+	}
+
+	idHex := suggestion.ID.Hex()
+
+	var promptMsg *telego.Message
+	reviewChatID := adminID
+	if msg, ok := query.Message.(*telego.Message); ok && msg != nil {
+		promptMsg, reviewChatID = msg, msg.Chat.ID
+	}
+	log.Printf("[Promote Admin:%d] Queueing preview of suggestion %s for channel %d", adminID, idHex, m.targetChannelID)
+	m.publishQueue.Submit(ctx, "suggestion:"+idHex, func(ctx context.Context) error {
+		copied, err := m.bot.CopyMessages(ctx, &telego.CopyMessagesParams{
+			ChatID:     tu.ID(m.targetChannelID),
+			FromChatID: tu.ID(suggestion.PreviewChatID),
+			MessageIDs: suggestion.PreviewMessageIDs,
+		})
+		if err == nil {
+			messageIDs := make([]int, 0, len(copied))
+			for _, id := range copied {
+				messageIDs = append(messageIDs, id.MessageID)
+			}
+			m.recordPublishedPost(ctx, suggestion, messageIDs)
+		}
+		return err
+	}, func(ctx context.Context, err error) {
+		key := "MsgPreviewPromoted"
+		switch {
+		case errors.Is(err, publisher.ErrAlreadyPublished):
+			log.Printf("[Promote Admin:%d] Suggestion %s was already published, not promoting it again", adminID, idHex)
+			key = "MsgPromoteAlreadyPublished"
+		case err != nil:
+			log.Printf("[Promote Admin:%d] Error promoting suggestion %s: %v", adminID, idHex, err)
+			if _, sendErr := m.bot.SendMessage(ctx, tu.Message(tu.ID(reviewChatID), locales.GetMessage(localizer, telegoapi.UserMessageID(err, "MsgReviewErrorDuringPublishing"), nil, nil))); sendErr != nil {
+				log.Printf("[Promote Admin:%d] Error notifying about failed promotion: %v", adminID, sendErr)
+			}
+			return // Keep the button, so promoting can be retried
+		default:
+			log.Printf("[Promote Admin:%d] Promoted suggestion %s", adminID, idHex)
+		}
+		if promptMsg == nil {
+			return
+		}
+		if _, editErr := m.bot.EditMessageText(ctx, &telego.EditMessageTextParams{
+			ChatID:    tu.ID(promptMsg.Chat.ID),
+			MessageID: promptMsg.MessageID,
+			Text:      locales.GetMessage(localizer, key, nil, nil),
+		}); editErr != nil {
+			log.Printf("[Promote Admin:%d] Error updating Promote message: %v", adminID, editErr)
+		}
+	})
+	return m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgPromoteQueued", nil, nil), false)
+}
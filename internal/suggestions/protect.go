@@ -0,0 +1,76 @@
+package suggestions
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// SetProtectContent sets whether approved suggestions are published with protected content, which
+// channel members can't forward or save, unless the reviewer switched it for the post. It is
+// chosen in /setup and defaults to PROTECT_CONTENT.
+func (m *Manager) SetProtectContent(enabled bool) {
+	m.protectContent = enabled
+}
+
+// protected reports whether a suggestion is published with protected content: as the reviewer
+// switched it, or else as set for the channel.
+func (m *Manager) protected(suggestion *models.Suggestion) bool {
+	if suggestion.ProtectContent != nil {
+		return *suggestion.ProtectContent
+	}
+	return m.protectContent
+}
+
+// toggleProtectContent switches whether the suggestion at index of the admin's review session is
+// published with protected content, and updates the review buttons.
+func (m *Manager) toggleProtectContent(ctx context.Context, queryID string, adminID int64, session *ReviewSession, index int) error {
+	localizer := m.localizerForUserID(ctx, adminID)
+
+	m.reviewSessionsMutex.RLock()
+	valid := index < len(session.Suggestions)
+	var suggestion models.Suggestion
+	if valid {
+		suggestion = session.Suggestions[index]
+	}
+	m.reviewSessionsMutex.RUnlock()
+	if !valid {
+		_ = m.answerCallbackQuery(ctx, queryID, locales.GetMessage(localizer, "MsgReviewSessionExpired", nil, nil), true)
+		return nil
+	}
+
+	protect := !m.protected(&suggestion)
+	if err := m.repo.SetSuggestionProtectContent(ctx, suggestion.ID, protect); err != nil {
+		_ = m.answerCallbackQuery(ctx, queryID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("failed to save content protection of suggestion %s: %w", suggestion.ID.Hex(), err)
+	}
+
+	m.reviewSessionsMutex.Lock()
+	if index < len(session.Suggestions) && session.Suggestions[index].ID == suggestion.ID {
+		session.Suggestions[index].ProtectContent = &protect
+		suggestion = session.Suggestions[index]
+	}
+	total := len(session.Suggestions)
+	chatID, messageID := session.ReviewChatID, session.CurrentControlMessageID
+	m.reviewSessionsMutex.Unlock()
+	log.Printf("[CallbackQuery] Admin %d set content protection of suggestion %s to %t", adminID, suggestion.ID.Hex(), protect)
+
+	toastID := "MsgProtectContentOff"
+	if protect {
+		toastID = "MsgProtectContentOn"
+	}
+	_ = m.answerCallbackQuery(ctx, queryID, locales.GetMessage(localizer, toastID, nil, nil), false)
+	if _, err := m.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+		ChatID:      tu.ID(chatID),
+		MessageID:   messageID,
+		ReplyMarkup: m.reviewKeyboard(localizer, &suggestion, index, total),
+	}); err != nil {
+		log.Printf("[CallbackQuery] Error updating review buttons for suggestion %s: %v", suggestion.ID.Hex(), err)
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"vrcmemes-bot/internal/locales"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// errMediaGroupIncomplete is returned when some items of a media group couldn't be published,
+// even one by one.
+var errMediaGroupIncomplete = errors.New("media group published incompletely")
+
+// mediaGroupPublication tracks which items of a media group reached the chat it is published to,
+// across the attempts of one queued publication, so a retried attempt never sends an item twice.
+type mediaGroupPublication struct {
+	chatID     int64 // The channel, or the preview channel, see preview.go
+	media      []telego.InputMedia
+	published  []bool        // Per item
+	messageIDs []int         // Per item, of the published items
+	failed     map[int]error // Last error of items that couldn't be sent one by one
+	fallback   bool          // The group failed, items are being sent one by one
+	protect    bool          // Publish with protected content, see protect.go
+	thumbnail  *videoUpload  // Video uploaded with its custom thumbnail, see thumbnail.go; nil if none
+}
+
+// newMediaGroupPublication starts tracking the publication of a media group.
+func newMediaGroupPublication(chatID int64, media []telego.InputMedia) *mediaGroupPublication {
+	return &mediaGroupPublication{
+		chatID:     chatID,
+		media:      media,
+		published:  make([]bool, len(media)),
+		messageIDs: make([]int, len(media)),
+		failed:     make(map[int]error),
+	}
+}
+
+// sendMediaGroup publishes a media group to its chat. If Telegram rejects the group or returns
+// fewer messages than items, the missing items are sent one by one. Rate limiting is returned as
+// is, so the publish queue retries the attempt, which continues where the previous one stopped.
+// Other errors, such as timeouts, don't say whether the group was posted, so they are returned
+// without sending the items again.
+// A GIF can't be part of a media group and is sent on its own right away.
+func (m *Manager) sendMediaGroup(ctx context.Context, publication *mediaGroupPublication) error {
+	if !publication.fallback && groupable(publication.media) {
+		media, err := m.proxiedMedia(ctx, publication.thumbnail.apply(publication.media))
+		if err != nil {
+			return err
+		}
+		sent, err := m.bot.SendMediaGroup(ctx, &telego.SendMediaGroupParams{
+			ChatID:         tu.ID(publication.chatID),
+			Media:          media,
+			ProtectContent: publication.protect,
+		})
+		m.rememberProxied(publication.media, sent)
+		if err == nil && len(sent) >= len(media) {
+			for i := range publication.published {
+				publication.published[i] = true
+				publication.messageIDs[i] = sent[i].MessageID
+			}
+			return nil
+		}
+		if _, limited := telegoapi.RetryAfter(err); limited {
+			return err
+		}
+		if err != nil && !telegoapi.IsRejected(err) {
+			return fmt.Errorf("media group may not have been published: %w", err)
+		}
+		// Telegram keeps the order of the items, so the messages returned are the first ones
+		for i := 0; i < len(sent) && i < len(publication.published); i++ {
+			publication.published[i] = true
+			publication.messageIDs[i] = sent[i].MessageID
+		}
+		log.Printf("[publishSuggestion] Media group sent %d of %d items (err: %v), sending the rest one by one", len(sent), len(media), err)
+		publication.fallback = true
+	}
+
+	for i, item := range publication.media {
+		if publication.published[i] {
+			continue
+		}
+		messageID, err := m.sendSingleMedia(ctx, publication.chatID, item, publication.thumbnail, publication.protect)
+		if _, limited := telegoapi.RetryAfter(err); limited {
+			return err
+		}
+		if err != nil {
+			log.Printf("[publishSuggestion] Error sending item %d of %d on its own: %v", i+1, len(publication.media), err)
+			publication.failed[i] = err
+			continue
+		}
+		publication.published[i] = true
+		publication.messageIDs[i] = messageID
+		delete(publication.failed, i)
+	}
+	if len(publication.failed) > 0 {
+		return fmt.Errorf("%w: %d of %d items failed", errMediaGroupIncomplete, len(publication.failed), len(publication.media))
+	}
+	return nil
+}
+
+// sendSingleMedia sends one item of a media group to a chat as a message of its own and returns
+// the ID of the message. The video of upload is sent with its thumbnail.
+func (m *Manager) sendSingleMedia(ctx context.Context, chatID int64, item telego.InputMedia, upload *videoUpload, protect bool) (int, error) {
+	proxied, err := m.proxiedMedia(ctx, upload.apply([]telego.InputMedia{item}))
+	if err != nil {
+		return 0, err
+	}
+	sent, err := m.sendMediaMessage(ctx, chatID, proxied[0], protect, nil)
+	if err != nil {
+		return 0, err
+	}
+	m.rememberProxied([]telego.InputMedia{item}, []telego.Message{*sent})
+	return sent.MessageID, nil
+}
+
+// sendMediaMessage sends input media with its caption as a message of its own, with the reply
+// markup if it isn't nil. GIFs can't be part of media groups, so they are always sent this way.
+func (m *Manager) sendMediaMessage(ctx context.Context, chatID int64, item telego.InputMedia, protect bool, markup telego.ReplyMarkup) (*telego.Message, error) {
+	switch media := item.(type) {
+	case *telego.InputMediaPhoto:
+		return m.bot.SendPhoto(ctx, &telego.SendPhotoParams{
+			ChatID:          tu.ID(chatID),
+			Photo:           media.Media,
+			Caption:         media.Caption,
+			ParseMode:       media.ParseMode,
+			CaptionEntities: media.CaptionEntities,
+			ProtectContent:  protect,
+			ReplyMarkup:     markup,
+		})
+	case *telego.InputMediaVideo:
+		return m.bot.SendVideo(ctx, &telego.SendVideoParams{
+			ChatID:          tu.ID(chatID),
+			Video:           media.Media,
+			Thumbnail:       media.Thumbnail,
+			Caption:         media.Caption,
+			ParseMode:       media.ParseMode,
+			CaptionEntities: media.CaptionEntities,
+			ProtectContent:  protect,
+			ReplyMarkup:     markup,
+		})
+	case *telego.InputMediaAnimation:
+		return m.bot.SendAnimation(ctx, &telego.SendAnimationParams{
+			ChatID:          tu.ID(chatID),
+			Animation:       media.Media,
+			Caption:         media.Caption,
+			ParseMode:       media.ParseMode,
+			CaptionEntities: media.CaptionEntities,
+			ProtectContent:  protect,
+			ReplyMarkup:     markup,
+		})
+	case *telego.InputMediaDocument:
+		return m.bot.SendDocument(ctx, &telego.SendDocumentParams{
+			ChatID:          tu.ID(chatID),
+			Document:        media.Media,
+			Caption:         media.Caption,
+			ParseMode:       media.ParseMode,
+			CaptionEntities: media.CaptionEntities,
+			ProtectContent:  protect,
+			ReplyMarkup:     markup,
+		})
+	}
+	return nil, fmt.Errorf("can't send media of type %s on its own", item.MediaType())
+}
+
+// groupable reports whether media can be sent as a media group, i.e. contains no GIFs.
+func groupable(media []telego.InputMedia) bool {
+	for _, item := range media {
+		if _, ok := item.(*telego.InputMediaAnimation); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// sentMessageIDs returns the IDs of the published messages, in item order.
+func (p *mediaGroupPublication) sentMessageIDs() []int {
+	var ids []int
+	for i, ok := range p.published {
+		if ok {
+			ids = append(ids, p.messageIDs[i])
+		}
+	}
+	return ids
+}
+
+// report describes, for the reviewing admin, which items were published and which failed after
+// the media group had to be sent one by one, or returns "" if the group was sent as a whole.
+func (p *mediaGroupPublication) report(localizer *i18n.Localizer) string {
+	if !p.fallback {
+		return ""
+	}
+	var published, failed []string
+	for i, ok := range p.published {
+		if ok {
+			published = append(published, strconv.Itoa(i+1))
+		} else {
+			failed = append(failed, strconv.Itoa(i+1))
+		}
+	}
+	none := locales.GetMessage(localizer, "MsgPublishItemsNone", nil, nil)
+	orNone := func(items []string) string {
+		if len(items) == 0 {
+			return none
+		}
+		return strings.Join(items, ", ")
+	}
+	return locales.GetMessage(localizer, "MsgPublishPartialReport", map[string]interface{}{
+		"Total":     len(p.media),
+		"Published": orNone(published),
+		"Failed":    orNone(failed),
+	}, nil)
+}
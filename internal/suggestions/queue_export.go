@@ -0,0 +1,220 @@
+package suggestions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/queueexport"
+	"vrcmemes-bot/pkg/utils"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const (
+	// maxExportedSuggestions bounds how many pending suggestions /queue export puts in a snapshot.
+	maxExportedSuggestions = 50
+	// maxAppliedDecisions bounds how many decisions one /apply takes.
+	maxAppliedDecisions = 50
+)
+
+// FileDownloader fetches the contents of a Telegram file. *mediaproxy.Proxy implements it.
+type FileDownloader interface {
+	Download(ctx context.Context, fileID string) ([]byte, error)
+}
+
+// SetFileDownloader sets how /queue export downloads the thumbnails of suggestions. Without it,
+// the snapshot has no thumbnails.
+func (m *Manager) SetFileDownloader(downloader FileDownloader) {
+	m.fileDownloader = downloader
+}
+
+// Decision is a review decision sent with /apply, as "<code>:a" to approve or "<code>:r" to reject.
+type Decision struct {
+	Code    string
+	Approve bool
+}
+
+// ParseDecisions parses the decisions of /apply. It returns the decisions and the words that
+// aren't decisions.
+func ParseDecisions(text string) ([]Decision, []string) {
+	var decisions []Decision
+	var invalid []string
+	for _, word := range strings.Fields(text) {
+		code, action, found := strings.Cut(word, ":")
+		code = models.NormalizeRefCode(code)
+		action = strings.ToLower(action)
+		if !found || !models.IsRefCode(code) || (action != "a" && action != "r") {
+			invalid = append(invalid, word)
+			continue
+		}
+		decisions = append(decisions, Decision{Code: code, Approve: action == "a"})
+	}
+	return decisions, invalid
+}
+
+// HandleQueueExportCommand sends an admin a snapshot of the pending suggestions, in review order:
+// a zipped HTML page with their reference codes, suggesters, captions and first photos, to go
+// through offline. The caller checks that the user is an admin.
+func (m *Manager) HandleQueueExportCommand(ctx context.Context, update telego.Update) error {
+	if update.Message == nil || update.Message.From == nil {
+		return fmt.Errorf("invalid update received for queue export command")
+	}
+	chatID := update.Message.Chat.ID
+	adminID := update.Message.From.ID
+	localizer := m.localizerForUser(ctx, update.Message.From)
+
+	pending, total, err := m.pendingInReviewOrder(ctx, adminID, m.reviewOrder, maxExportedSuggestions)
+	if err != nil {
+		errorMsg := locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil)
+		_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), errorMsg))
+		return fmt.Errorf("failed to get pending suggestions: %w", err)
+	}
+	if len(pending) == 0 {
+		msg := locales.GetMessage(localizer, "MsgReviewQueueIsEmpty", nil, nil)
+		_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), msg))
+		return err
+	}
+
+	page := queueexport.Page{
+		Title:     locales.GetMessage(localizer, "MsgQueueExportTitle", map[string]interface{}{"Date": utils.FormatTime(time.Now(), m.location)}, nil),
+		Intro:     locales.GetMessage(localizer, "MsgQueueExportIntro", nil, nil),
+		NoPreview: locales.GetMessage(localizer, "MsgQueueExportNoPreview", nil, nil),
+		Items:     make([]queueexport.Item, len(pending)),
+	}
+	for i := range pending {
+		page.Items[i] = m.exportedSuggestion(ctx, &pending[i])
+	}
+	var archive bytes.Buffer
+	if err := queueexport.Write(&archive, page); err != nil {
+		errorMsg := locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil)
+		_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), errorMsg))
+		return fmt.Errorf("failed to export the queue: %w", err)
+	}
+	log.Printf("[Cmd:queue Admin:%d] Exported %d of %d pending suggestions (%d bytes)", adminID, len(pending), total, archive.Len())
+
+	name := fmt.Sprintf("queue-%s.zip", time.Now().In(m.location).Format("2006-01-02-1504"))
+	caption := locales.GetPluralMessage(localizer, "MsgQueueExportCaption", len(pending), map[string]interface{}{"Total": total})
+	_, err = m.bot.SendDocument(ctx, tu.Document(tu.ID(chatID), tu.File(tu.NameReader(&archive, name))).WithCaption(caption))
+	return err
+}
+
+// exportedSuggestion describes a pending suggestion for the queue snapshot, with its first photo
+// as the thumbnail. Other media and files that can't be downloaded leave the thumbnail out.
+func (m *Manager) exportedSuggestion(ctx context.Context, suggestion *models.Suggestion) queueexport.Item {
+	suggester := suggestion.FirstName
+	if suggestion.Username != "" {
+		suggester = "@" + suggestion.Username
+	}
+	item := queueexport.Item{
+		Code:      suggestion.Ref(),
+		Suggester: suggester,
+		Submitted: utils.FormatTime(suggestion.SubmittedAt, m.location),
+		Caption:   suggestion.Caption,
+		Media:     len(suggestion.FileIDs),
+	}
+	if m.fileDownloader != nil && len(suggestion.FileIDs) > 0 && suggestion.MediaType(0) == models.MediaTypePhoto {
+		thumbnail, err := m.fileDownloader.Download(ctx, suggestion.FileIDs[0])
+		if err != nil {
+			log.Printf("[Cmd:queue] Error downloading the thumbnail of suggestion %s: %v", suggestion.Ref(), err)
+		} else {
+			item.Thumbnail = thumbnail
+		}
+	}
+	return item
+}
+
+// HandleApplyCommand applies review decisions made offline, e.g. from a /queue export snapshot:
+// each suggestion is approved and published, or rejected, as if it was reviewed with /review.
+// Suggestions that were reviewed meanwhile or are held by another admin's review session are
+// skipped. The caller checks that the user is an admin.
+func (m *Manager) HandleApplyCommand(ctx context.Context, update telego.Update, text string) error {
+	if update.Message == nil || update.Message.From == nil {
+		return fmt.Errorf("invalid update received for apply command")
+	}
+	chatID := update.Message.Chat.ID
+	admin := update.Message.From
+	localizer := m.localizerForUser(ctx, admin)
+
+	decisions, invalid := ParseDecisions(text)
+	if len(decisions) > maxAppliedDecisions {
+		msg := locales.GetMessage(localizer, "MsgApplyTooMany", map[string]interface{}{"Max": maxAppliedDecisions}, nil)
+		_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), msg))
+		return err
+	}
+
+	var report strings.Builder
+	report.WriteString(locales.GetMessage(localizer, "MsgApplyHeader", nil, nil))
+	for _, decision := range decisions {
+		messageID := m.applyDecision(ctx, chatID, admin, decision)
+		report.WriteString("\n")
+		report.WriteString(locales.GetMessage(localizer, messageID, map[string]interface{}{"Code": decision.Code}, nil))
+	}
+	for _, word := range invalid {
+		report.WriteString("\n")
+		report.WriteString(locales.GetMessage(localizer, "MsgApplyInvalid", map[string]interface{}{"Decision": word}, nil))
+	}
+	_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), report.String()))
+	return err
+}
+
+// applyDecision approves or rejects a pending suggestion and returns the message ID of the
+// outcome for the /apply report.
+func (m *Manager) applyDecision(ctx context.Context, chatID int64, admin *telego.User, decision Decision) string {
+	suggestion, err := m.repo.GetSuggestionByRefCode(ctx, decision.Code)
+	if errors.Is(err, database.ErrSuggestionNotFound) {
+		return "MsgApplyNotFound"
+	}
+	if err != nil {
+		log.Printf("[Cmd:apply Admin:%d] Error looking up suggestion %s: %v", admin.ID, decision.Code, err)
+		return "MsgApplyFailed"
+	}
+	if suggestion.Status != string(models.StatusPending) {
+		return "MsgApplyAlreadyReviewed"
+	}
+
+	// Hold the suggestion like a review session does, so no other admin decides it meanwhile
+	m.reviewSessionsMutex.Lock()
+	_, claimed := m.claimedSuggestions[suggestion.ID]
+	if !claimed {
+		m.claimedSuggestions[suggestion.ID] = admin.ID
+	}
+	m.reviewSessionsMutex.Unlock()
+	if claimed {
+		return "MsgApplyInReview"
+	}
+	defer func() {
+		m.reviewSessionsMutex.Lock()
+		m.releaseClaimLocked(suggestion.ID)
+		m.reviewSessionsMutex.Unlock()
+	}()
+
+	if !decision.Approve {
+		if err := m.UpdateSuggestionStatus(ctx, suggestion.ID, models.StatusRejected, admin.ID, admin.Username); err != nil {
+			return "MsgApplyFailed"
+		}
+		log.Printf("[Cmd:apply Admin:%d] Rejected suggestion %s", admin.ID, decision.Code)
+		return "MsgApplyRejected"
+	}
+	if suggestion.VideoPolicyBlocked() {
+		// Overriding the video policy is a deliberate choice made in /review
+		return "MsgApplyVideoPolicy"
+	}
+	if err := m.UpdateSuggestionStatus(ctx, suggestion.ID, models.StatusApproved, admin.ID, admin.Username); err != nil {
+		return "MsgApplyFailed"
+	}
+	m.countUserActivity(ctx, suggestion.SuggesterID, models.UserCounterApprovals)
+	if _, err := m.publishSuggestion(ctx, suggestion, chatID, admin.ID); err != nil {
+		log.Printf("[Cmd:apply Admin:%d] Error publishing suggestion %s: %v", admin.ID, decision.Code, err)
+		return "MsgApplyPublishFailed"
+	}
+	log.Printf("[Cmd:apply Admin:%d] Approved suggestion %s", admin.ID, decision.Code)
+	return "MsgApplyApproved"
+}
@@ -0,0 +1,83 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxRefCodeAttempts bounds how many random reference codes are tried for a new suggestion.
+const maxRefCodeAttempts = 5
+
+// errInvalidSuggestionRef is returned for callback data whose suggestion reference is neither
+// a reference code nor an ObjectID.
+var errInvalidSuggestionRef = errors.New("invalid suggestion reference")
+
+// createSuggestion stores a new suggestion with a fresh reference code, drawing another code
+// while the drawn one is taken.
+func (m *Manager) createSuggestion(ctx context.Context, suggestion *models.Suggestion) error {
+	var err error
+	for attempt := 0; attempt < maxRefCodeAttempts; attempt++ {
+		suggestion.RefCode = models.NewRefCode()
+		if err = m.repo.CreateSuggestion(ctx, suggestion); !errors.Is(err, database.ErrRefCodeTaken) {
+			return err
+		}
+		log.Printf("Reference code %s is taken, drawing another one for the suggestion of user %d", suggestion.RefCode, suggestion.SuggesterID)
+	}
+	return err
+}
+
+// NotifyRefCodeChanged tells the suggester that their suggestion, buffered while the database
+// was unavailable, was stored under a new reference code because the one they were given got taken.
+func (m *Manager) NotifyRefCodeChanged(ctx context.Context, suggestion *models.Suggestion, oldCode string) {
+	if suggestion.Test {
+		return // The test user of /simulate has no chat
+	}
+	localizer := m.localizerForUserID(ctx, suggestion.SuggesterID)
+	text := locales.GetMessage(localizer, "MsgSuggestionRefCodeChanged", map[string]interface{}{
+		"OldCode": oldCode,
+		"Code":    suggestion.RefCode,
+	}, nil)
+	if err := m.sendNotification(ctx, models.NotificationKindRefCodeChanged, suggestion.SuggesterID, text); err != nil {
+		log.Printf("Error telling user %d that suggestion %s is now %s: %v", suggestion.SuggesterID, oldCode, suggestion.RefCode, err)
+	}
+}
+
+// isSuggestionRef reports whether ref, taken from callback data, can refer to a suggestion: a
+// reference code, or the lowercase hex ObjectID used before reference codes were introduced.
+func isSuggestionRef(ref string) bool {
+	if models.IsRefCode(ref) {
+		return true
+	}
+	id, err := primitive.ObjectIDFromHex(ref)
+	return err == nil && id.Hex() == ref
+}
+
+// suggestionByRef loads the suggestion a reference from callback data (see models.Suggestion.Ref)
+// refers to. Buttons sent before reference codes were introduced carry the ObjectID.
+func (m *Manager) suggestionByRef(ctx context.Context, ref string) (*models.Suggestion, error) {
+	if models.IsRefCode(ref) {
+		return m.repo.GetSuggestionByRefCode(ctx, ref)
+	}
+	id, err := primitive.ObjectIDFromHex(ref)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q", errInvalidSuggestionRef, ref)
+	}
+	return m.repo.GetSuggestionByID(ctx, id)
+}
+
+// refCodeText returns the line telling the suggester the reference code of their suggestion,
+// or "" if it has none.
+func refCodeText(localizer *i18n.Localizer, suggestion *models.Suggestion) string {
+	if suggestion.RefCode == "" {
+		return ""
+	}
+	return locales.GetMessage(localizer, "MsgSuggestionRefCode", map[string]interface{}{"Code": suggestion.RefCode}, nil)
+}
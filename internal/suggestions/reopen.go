@@ -0,0 +1,129 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// reopenCallbackPrefix prefixes callback data of the Re-open button under rejected suggestions in /find ("reopen:<ref>").
+const reopenCallbackPrefix = "reopen:"
+
+// reopenKeyboard returns the Re-open button for a rejected suggestion, or nil for other suggestions.
+func reopenKeyboard(localizer *i18n.Localizer, suggestion *models.Suggestion) *telego.InlineKeyboardMarkup {
+	if suggestion.Status != string(models.StatusRejected) {
+		return nil
+	}
+	return tu.InlineKeyboard(tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnReopen", nil, nil)).WithCallbackData(reopenCallbackPrefix + suggestion.Ref()),
+	))
+}
+
+// reopenSuggestion puts a rejected suggestion back in the review queue. It returns the message
+// telling the admin how it went.
+func (m *Manager) reopenSuggestion(ctx context.Context, localizer *i18n.Localizer, adminID int64, suggestion *models.Suggestion) (string, error) {
+	data := map[string]interface{}{"Code": suggestion.Ref()}
+	if suggestion.Status != string(models.StatusRejected) {
+		return locales.GetMessage(localizer, "MsgReopenNotRejected", data, nil), nil
+	}
+	err := m.repo.ReopenSuggestion(ctx, suggestion.ID, adminID)
+	if errors.Is(err, database.ErrSuggestionNotFound) {
+		// Reopened by someone else in the meantime
+		return locales.GetMessage(localizer, "MsgReopenNotRejected", data, nil), nil
+	}
+	if err != nil {
+		return locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), err
+	}
+	log.Printf("[Reopen Admin:%d] Reopened suggestion %s (rejected: %q)", adminID, suggestion.ID.Hex(), suggestion.RejectionReason)
+	return locales.GetMessage(localizer, "MsgReopenDone", data, nil), nil
+}
+
+// HandleReopenCommand puts a rejected or expired suggestion, looked up by its reference code,
+// back in the review queue. The caller checks that the user is an admin.
+func (m *Manager) HandleReopenCommand(ctx context.Context, update telego.Update, code string) error {
+	if update.Message == nil || update.Message.From == nil {
+		return fmt.Errorf("invalid update received for reopen command")
+	}
+	chatID := update.Message.Chat.ID
+	adminID := update.Message.From.ID
+	localizer := m.localizerForUser(ctx, update.Message.From)
+	code = models.NormalizeRefCode(code)
+
+	suggestion, err := m.repo.GetSuggestionByRefCode(ctx, code)
+	if errors.Is(err, database.ErrSuggestionNotFound) {
+		msg := locales.GetMessage(localizer, "MsgReopenNotFound", map[string]interface{}{"Code": code}, nil)
+		_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), msg))
+		return err
+	}
+	if err != nil {
+		errorMsg := locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil)
+		_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), errorMsg))
+		return fmt.Errorf("failed to look up suggestion %s: %w", code, err)
+	}
+
+	msg, reopenErr := m.reopenSuggestion(ctx, localizer, adminID, suggestion)
+	if _, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), msg)); err != nil {
+		return err
+	}
+	if reopenErr != nil {
+		return fmt.Errorf("failed to reopen suggestion %s: %w", code, reopenErr)
+	}
+	return nil
+}
+
+// handleReopenCallback handles the Re-open button: it puts the suggestion back in the review
+// queue and removes the button.
+func (m *Manager) handleReopenCallback(ctx context.Context, query telego.CallbackQuery) error {
+	adminID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	ref := strings.TrimPrefix(query.Data, reopenCallbackPrefix)
+	if !isSuggestionRef(ref) {
+		log.Printf("[Reopen Admin:%d] Rejected callback data %q", adminID, query.Data)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("malformed reopen callback data %q: %w", query.Data, errInvalidSuggestionRef)
+	}
+	isAdmin, err := m.adminChecker.IsAdmin(ctx, adminID)
+	if err != nil {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("reopen admin check failed for user %d: %w", adminID, err)
+	}
+	if !isAdmin {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil), true)
+		return nil
+	}
+
+	suggestion, err := m.suggestionByRef(ctx, ref)
+	if errors.Is(err, database.ErrSuggestionNotFound) {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgReopenNotFound", map[string]interface{}{"Code": ref}, nil), true)
+		return nil
+	}
+	if err != nil {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("failed to load suggestion %s: %w", ref, err)
+	}
+
+	msg, reopenErr := m.reopenSuggestion(ctx, localizer, adminID, suggestion)
+	_ = m.answerCallbackQuery(ctx, query.ID, msg, reopenErr != nil)
+	if reopenErr != nil {
+		return fmt.Errorf("failed to reopen suggestion %s: %w", ref, reopenErr)
+	}
+	if prompt, ok := query.Message.(*telego.Message); ok && prompt != nil {
+		if _, err := m.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+			ChatID:    tu.ID(prompt.Chat.ID),
+			MessageID: prompt.MessageID,
+		}); err != nil {
+			log.Printf("[Reopen Admin:%d] Error removing the Re-open button: %v", adminID, err)
+		}
+	}
+	return nil
+}
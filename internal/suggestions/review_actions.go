@@ -2,29 +2,60 @@ package suggestions
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/footers"
 	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/publisher"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
 
-	"github.com/mymmrac/telego"
 	tu "github.com/mymmrac/telego/telegoutil"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// handleApproveAction approves a suggestion, posts it, cleans up messages, and proceeds.
-func (m *Manager) handleApproveAction(ctx context.Context, queryID string, adminID int64, adminUsername string, session *ReviewSession, index int, _ int, suggestionID primitive.ObjectID) error {
-	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String()) // Use tag
+// handleApproveAction approves a suggestion, posts it (to the preview channel if preview is set,
+// see preview.go), cleans up messages, and proceeds.
+func (m *Manager) handleApproveAction(ctx context.Context, queryID string, adminID int64, adminUsername string, session *ReviewSession, index int, _ int, suggestionID primitive.ObjectID, preview bool) error {
+	localizer := m.localizerForUserID(ctx, adminID)
 
-	// Approve and publish
-	dbErr := m.UpdateSuggestionStatus(ctx, suggestionID, models.StatusApproved, adminID, adminUsername)
+	// The approval and the suggester's approval count are written together. Publishing can't be
+	// part of the unit: it is queued and sent asynchronously, after the unit has ended
+	var suggestion *models.Suggestion
+	dbErr := m.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := m.UpdateSuggestionStatus(ctx, suggestionID, models.StatusApproved, adminID, adminUsername); err != nil {
+			return err
+		}
+		approved, err := m.GetSuggestionByID(ctx, suggestionID)
+		if err != nil {
+			return fmt.Errorf("failed to load approved suggestion %s: %w", suggestionID.Hex(), err)
+		}
+		if err := m.userRepo.IncrementUserCounter(ctx, approved.SuggesterID, models.UserCounterApprovals); err != nil {
+			return fmt.Errorf("failed to count the approval of suggestion %s: %w", suggestionID.Hex(), err)
+		}
+		suggestion = approved
+		return nil
+	})
+	if dbErr != nil {
+		log.Printf("[ApproveAction] Error approving suggestion %s: %v", suggestionID.Hex(), dbErr)
+	}
 	// Find the full suggestion details for publishing
-	suggestion, errFind := m.GetSuggestionByID(ctx, suggestionID)
-	if errFind != nil {
-		log.Printf("[ApproveAction] Could not find suggestion %s for publishing after DB update: %v", suggestionID.Hex(), errFind)
-		// Handle this error - maybe rollback status or just log?
+	var position int
+	var publishErr error
+	if suggestion == nil {
+		suggestion, publishErr = m.GetSuggestionByID(ctx, suggestionID)
+	}
+	if publishErr != nil {
+		log.Printf("[ApproveAction] Could not find suggestion %s for publishing: %v", suggestionID.Hex(), publishErr)
+	} else {
+		if preview {
+			position, publishErr = m.previewSuggestion(ctx, suggestion, session.ReviewChatID, adminID)
+		} else {
+			position, publishErr = m.publishSuggestion(ctx, suggestion, session.ReviewChatID, adminID)
+		}
 	}
-	publishErr := m.publishSuggestion(ctx, *suggestion)
 
 	// Determine response message
 	var responseMsg string
@@ -37,6 +68,12 @@ func (m *Manager) handleApproveAction(ctx context.Context, queryID string, admin
 		}
 	} else {
 		responseMsg = locales.GetMessage(localizer, "MsgReviewActionApproved", nil, nil)
+		if preview {
+			responseMsg = locales.GetMessage(localizer, "MsgReviewActionPreviewed", nil, nil)
+		} else if position > 1 {
+			// Others are ahead in the publish queue; tell the admin it will be posted shortly
+			responseMsg = locales.GetPluralMessage(localizer, "MsgReviewActionApprovedQueued", position-1, nil)
+		}
 		if dbErr != nil {
 			// Append DB error suffix if publishing succeeded but DB update failed
 			responseMsg = locales.GetMessage(localizer, "MsgReviewActionApprovedWithDBError", nil, nil)
@@ -63,6 +100,7 @@ func (m *Manager) handleApproveAction(ctx context.Context, queryID string, admin
 		return fmt.Errorf("invalid index %d during approve action", index)
 	}
 	currentSession.Suggestions = append(currentSession.Suggestions[:index], currentSession.Suggestions[index+1:]...)
+	m.releaseClaimLocked(suggestionID)
 	err := m.sendNextOrFinishReview(ctx, adminID, currentSession)
 	m.reviewSessionsMutex.Unlock()
 	return err
@@ -70,7 +108,7 @@ func (m *Manager) handleApproveAction(ctx context.Context, queryID string, admin
 
 // handleRejectAction rejects a suggestion, cleans up messages, and proceeds.
 func (m *Manager) handleRejectAction(ctx context.Context, queryID string, adminID int64, adminUsername string, session *ReviewSession, index int, _ int, suggestionID primitive.ObjectID) error {
-	localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String()) // Use tag
+	localizer := m.localizerForUserID(ctx, adminID)
 
 	// Reject suggestion in DB
 	dbErr := m.UpdateSuggestionStatus(ctx, suggestionID, models.StatusRejected, adminID, adminUsername)
@@ -102,6 +140,7 @@ func (m *Manager) handleRejectAction(ctx context.Context, queryID string, adminI
 		return fmt.Errorf("invalid index %d during reject action", index)
 	}
 	currentSession.Suggestions = append(currentSession.Suggestions[:index], currentSession.Suggestions[index+1:]...)
+	m.releaseClaimLocked(suggestionID)
 	err := m.sendNextOrFinishReview(ctx, adminID, currentSession)
 	m.reviewSessionsMutex.Unlock()
 	return err
@@ -179,11 +218,10 @@ func (m *Manager) sendNextOrFinishReview(ctx context.Context, adminID int64, ses
 	} else {
 		// No more suggestions in this batch
 		log.Printf("[sendNextOrFinishReview Admin:%d] Review batch finished.", adminID)
-		delete(m.reviewSessions, adminID) // Delete the session
+		m.removeReviewSessionLocked(adminID) // Delete the session and release its claims
 
 		m.reviewSessionsMutex.Unlock()
-		lang := locales.GetDefaultLanguageTag().String()
-		localizer := locales.NewLocalizer(lang)
+		localizer := m.localizerForUserID(ctx, adminID)
 		queueEmptyMsg := locales.GetMessage(localizer, "MsgReviewQueueIsEmpty", nil, nil)
 		_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(session.ReviewChatID), queueEmptyMsg))
 		m.reviewSessionsMutex.Lock() // Re-acquire lock
@@ -194,26 +232,111 @@ func (m *Manager) sendNextOrFinishReview(ctx context.Context, adminID int64, ses
 	}
 }
 
-// publishSuggestion sends the approved suggestion to the target channel.
-func (m *Manager) publishSuggestion(ctx context.Context, suggestion models.Suggestion) error {
-	inputMedia := m.createInputMediaFromSuggestion(suggestion)
+// publishSuggestion queues the approved suggestion for publishing to the target channel
+// and returns its position in the publish queue. If publishing eventually fails, or the media
+// group had to be sent item by item, the reviewing admin is notified in reviewChatID.
+func (m *Manager) publishSuggestion(ctx context.Context, suggestion *models.Suggestion, reviewChatID, adminID int64) (int, error) {
+	if suggestion.Test {
+		// Test suggestions of /simulate go through review but never reach the channel
+		log.Printf("[Publish] Not publishing test suggestion %s", suggestion.Ref())
+		return 0, nil
+	}
+	return m.queueSuggestionPublication(ctx, suggestion, m.targetChannelID, "suggestion:"+suggestion.ID.Hex(), reviewChatID, adminID, func(ctx context.Context, messageIDs []int) {
+		m.recordPublishedPost(ctx, suggestion, messageIDs)
+	})
+}
+
+// queueSuggestionPublication queues the approved suggestion for publishing to chatID under the
+// publish queue key and returns its position in the queue. onPublished, if not nil, is called
+// with the IDs of the sent messages once the suggestion was published without errors.
+func (m *Manager) queueSuggestionPublication(ctx context.Context, suggestion *models.Suggestion, chatID int64, key string, reviewChatID, adminID int64, onPublished func(ctx context.Context, messageIDs []int)) (int, error) {
+	inputMedia := m.createInputMediaFromSuggestion(*suggestion)
 	if len(inputMedia) == 0 {
-		return fmt.Errorf("no valid media found to publish for suggestion %s", suggestion.ID.Hex())
+		return 0, fmt.Errorf("no valid media found to publish for suggestion %s", suggestion.ID.Hex())
 	}
 
-	log.Printf("[publishSuggestion] Publishing suggestion %s to channel %d...", suggestion.ID.Hex(), m.targetChannelID)
-	_, err := m.bot.SendMediaGroup(ctx, &telego.SendMediaGroupParams{
-		ChatID: tu.ID(m.targetChannelID),
-		Media:  inputMedia,
+	log.Printf("[publishSuggestion] Queueing suggestion %s for chat %d...", suggestion.ID.Hex(), chatID)
+	captioned := false
+	publication := newMediaGroupPublication(chatID, inputMedia)
+	publication.protect = m.protected(suggestion)
+	position := m.publishQueue.Submit(ctx, key, func(ctx context.Context) error {
+		// The caption is built once, when the post is first sent: the rubric number is taken
+		// in publication order and not again when a rate-limited send is retried. The custom
+		// thumbnail is made once too, as it downloads the video
+		if !captioned {
+			caption, err := m.publishedCaption(ctx, suggestion)
+			if err != nil {
+				return err
+			}
+			if caption != "" {
+				setMediaCaption(inputMedia[0], caption, "", m.styleEntities(ctx, suggestion, caption))
+			}
+			publication.thumbnail = m.prepareThumbnail(ctx, suggestion, inputMedia)
+			captioned = true
+		}
+		return m.sendMediaGroup(ctx, publication)
+	}, func(ctx context.Context, err error) {
+		if errors.Is(err, publisher.ErrAlreadyPublished) {
+			log.Printf("[publishSuggestion] Suggestion %s was already published, not sending it again", suggestion.ID.Hex())
+			return
+		}
+		if report := publication.report(m.localizerForUserID(ctx, adminID)); report != "" {
+			log.Printf("[publishSuggestion] Suggestion %s was published item by item (err: %v)", suggestion.ID.Hex(), err)
+			if _, sendErr := m.bot.SendMessage(ctx, tu.Message(tu.ID(reviewChatID), report)); sendErr != nil {
+				log.Printf("[publishSuggestion] Error sending publication report to admin %d: %v", adminID, sendErr)
+			}
+			if err == nil && onPublished != nil {
+				onPublished(ctx, publication.sentMessageIDs())
+			}
+			return
+		}
+		if err != nil {
+			log.Printf("[publishSuggestion] Error sending media group for suggestion %s: %v", suggestion.ID.Hex(), err)
+			errorMsg := locales.GetMessage(m.localizerForUserID(ctx, adminID), telegoapi.UserMessageID(err, "MsgReviewErrorDuringPublishing"), nil, nil)
+			if _, sendErr := m.bot.SendMessage(ctx, tu.Message(tu.ID(reviewChatID), errorMsg)); sendErr != nil {
+				log.Printf("[publishSuggestion] Error notifying admin %d about failed publication: %v", adminID, sendErr)
+			}
+			return
+		}
+		log.Printf("[publishSuggestion] Successfully published suggestion %s to chat %d", suggestion.ID.Hex(), chatID)
+		if onPublished != nil {
+			onPublished(ctx, publication.sentMessageIDs())
+		}
 	})
+	return position, nil
+}
 
+// publishedCaption assembles the channel caption of an approved suggestion: the numbered rubric
+// caption, the suggester's name if they are credited, the source credit of reposts (if enabled)
+// and the tags as hashtags, decorated with the picked caption style, then the next hashtag footer
+// of the rotation. The suggester's comment is never published.
+func (m *Manager) publishedCaption(ctx context.Context, suggestion *models.Suggestion) (string, error) {
+	var parts []string
+	rubricCaption, err := m.rubricCaption(ctx, suggestion)
 	if err != nil {
-		log.Printf("[publishSuggestion] Error sending media group for suggestion %s: %v", suggestion.ID.Hex(), err)
-		return fmt.Errorf("failed to send media group to channel: %w", err)
+		return "", err
 	}
-
-	log.Printf("[publishSuggestion] Successfully published suggestion %s", suggestion.ID.Hex())
-	return nil
+	if rubricCaption != "" {
+		parts = append(parts, rubricCaption)
+	}
+	if caption := chosenCaption(suggestion); caption != "" {
+		parts = append(parts, caption)
+	}
+	if credit := m.suggesterCredit(suggestion); credit != "" {
+		parts = append(parts, credit)
+	}
+	if m.creditForwardSource && suggestion.ForwardOrigin.IsChannelRepost() {
+		parts = append(parts, forwardSourceCredit(suggestion.ForwardOrigin))
+	}
+	if len(suggestion.Tags) > 0 {
+		parts = append(parts, hashtags(suggestion.Tags))
+	}
+	caption := m.styledCaption(ctx, suggestion, strings.Join(parts, "\n\n"))
+	footer, err := m.footerRotator.Next(ctx)
+	if err != nil {
+		log.Printf("[publishSuggestion] Hashtag footer rotation for suggestion %s: %v", suggestion.ID.Hex(), err)
+	}
+	return footers.Append(caption, footer), nil
 }
 
 // processNextSuggestion (REMOVED/REPLACED by sendNextOrFinishReview)
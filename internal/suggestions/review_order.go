@@ -0,0 +1,109 @@
+package suggestions
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+)
+
+// ReviewOrder is the order in which /review hands out pending suggestions.
+type ReviewOrder string
+
+const (
+	ReviewOrderFIFO       ReviewOrder = "fifo"        // Oldest first
+	ReviewOrderLIFO       ReviewOrder = "lifo"        // Newest first
+	ReviewOrderPriority   ReviewOrder = "priority"    // Suggesters with more approved suggestions first, then oldest first
+	ReviewOrderRandom     ReviewOrder = "random"      // Shuffled
+	ReviewOrderRoundRobin ReviewOrder = "round_robin" // One suggestion per suggester in turn, oldest first
+	ReviewOrderMine       ReviewOrder = "mine"        // Only suggestions assigned to the reviewer, oldest first; see assignment.go
+)
+
+// ReviewOrders lists the available review orders.
+var ReviewOrders = []ReviewOrder{ReviewOrderFIFO, ReviewOrderLIFO, ReviewOrderPriority, ReviewOrderRandom, ReviewOrderRoundRobin, ReviewOrderMine}
+
+// reviewOrderWindow is how many of the oldest pending suggestions are shuffled or interleaved
+// by the random and round-robin orders, which can't be sorted by the database.
+const reviewOrderWindow = 200
+
+// ParseReviewOrder parses a review order name, case-insensitively.
+func ParseReviewOrder(name string) (ReviewOrder, error) {
+	for _, order := range ReviewOrders {
+		if strings.EqualFold(name, string(order)) {
+			return order, nil
+		}
+	}
+	return "", fmt.Errorf("unknown review order %q", name)
+}
+
+// SetReviewOrder sets the order /review uses when no order is given. Defaults to FIFO.
+func (m *Manager) SetReviewOrder(order ReviewOrder) {
+	m.reviewOrder = order
+}
+
+// pendingInReviewOrder returns up to limit pending suggestions in the given review order
+// (the default one if empty) for an admin, and how many suggestions are pending in total.
+func (m *Manager) pendingInReviewOrder(ctx context.Context, adminID int64, order ReviewOrder, limit int) ([]models.Suggestion, int64, error) {
+	if order == "" {
+		order = m.reviewOrder
+	}
+	switch order {
+	case ReviewOrderMine:
+		return m.repo.GetAssignedPendingSuggestions(ctx, adminID, limit)
+	case ReviewOrderLIFO:
+		return m.repo.GetPendingSuggestionsInOrder(ctx, limit, database.PendingNewestFirst)
+	case ReviewOrderPriority:
+		return m.repo.GetPendingSuggestionsInOrder(ctx, limit, database.PendingPriorityFirst)
+	case ReviewOrderRandom, ReviewOrderRoundRobin:
+		window, total, err := m.repo.GetPendingSuggestionsInOrder(ctx, max(limit, reviewOrderWindow), database.PendingOldestFirst)
+		if err != nil {
+			return nil, 0, err
+		}
+		if order == ReviewOrderRandom {
+			rand.Shuffle(len(window), func(i, j int) { window[i], window[j] = window[j], window[i] })
+		} else {
+			window = roundRobinBySuggester(window)
+		}
+		if len(window) > limit {
+			window = window[:limit]
+		}
+		return window, total, nil
+	default:
+		return m.repo.GetPendingSuggestionsInOrder(ctx, limit, database.PendingOldestFirst)
+	}
+}
+
+// roundRobinBySuggester interleaves suggestions so each suggester gets one turn per round,
+// keeping each suggester's suggestions and the suggesters themselves in their original order.
+func roundRobinBySuggester(suggestions []models.Suggestion) []models.Suggestion {
+	var suggesters []int64
+	bySuggester := make(map[int64][]models.Suggestion)
+	for _, suggestion := range suggestions {
+		if _, seen := bySuggester[suggestion.SuggesterID]; !seen {
+			suggesters = append(suggesters, suggestion.SuggesterID)
+		}
+		bySuggester[suggestion.SuggesterID] = append(bySuggester[suggestion.SuggesterID], suggestion)
+	}
+
+	result := make([]models.Suggestion, 0, len(suggestions))
+	for round := 0; len(result) < len(suggestions); round++ {
+		for _, suggester := range suggesters {
+			if queue := bySuggester[suggester]; round < len(queue) {
+				result = append(result, queue[round])
+			}
+		}
+	}
+	return result
+}
+
+// suggestionPriority returns the review priority of a new suggestion from a user: how many of
+// their suggestions have been approved so far. Errors are logged by the caller and count as none.
+func (m *Manager) suggestionPriority(ctx context.Context, userID int64) (int, error) {
+	user, err := m.userRepo.GetUser(ctx, userID)
+	if err != nil || user == nil {
+		return 0, err
+	}
+	return user.ApprovalsCount, nil
+}
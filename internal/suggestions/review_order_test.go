@@ -0,0 +1,25 @@
+package suggestions
+
+import (
+	"testing"
+	"vrcmemes-bot/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundRobinBySuggester(t *testing.T) {
+	queue := []models.Suggestion{
+		{SuggesterID: 1, Caption: "a1"},
+		{SuggesterID: 1, Caption: "a2"},
+		{SuggesterID: 1, Caption: "a3"},
+		{SuggesterID: 2, Caption: "b1"},
+		{SuggesterID: 3, Caption: "c1"},
+		{SuggesterID: 2, Caption: "b2"},
+	}
+
+	var captions []string
+	for _, suggestion := range roundRobinBySuggester(queue) {
+		captions = append(captions, suggestion.Caption)
+	}
+	assert.Equal(t, []string{"a1", "b1", "c1", "a2", "b2", "a3"}, captions)
+}
@@ -0,0 +1,343 @@
+package suggestions
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/format"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// DefaultReviewSessionTTL is how long a review session may stay inactive before it expires.
+	DefaultReviewSessionTTL = 15 * time.Minute
+	// reviewSessionSweepInterval is how often stale review sessions are looked for.
+	reviewSessionSweepInterval = time.Minute
+	// ReviewReminderSnooze is how long the "Snooze" button of a review reminder postpones it.
+	ReviewReminderSnooze = time.Hour
+
+	// Callback data for the "Resume previous session?" prompt and the review reminder.
+	reviewSessionCallbackPrefix = "reviewsession:"
+	reviewSessionResumeData     = reviewSessionCallbackPrefix + "resume"
+	reviewSessionRestartData    = reviewSessionCallbackPrefix + "restart"
+	reviewSessionSnoozeData     = reviewSessionCallbackPrefix + "snooze"
+	reviewSessionDismissData    = reviewSessionCallbackPrefix + "dismiss"
+)
+
+// SetReviewSessionTTL configures how long a review session may stay inactive before it expires.
+// A zero duration disables expiry.
+func (m *Manager) SetReviewSessionTTL(ttl time.Duration) {
+	m.reviewSessionsMutex.Lock()
+	defer m.reviewSessionsMutex.Unlock()
+	m.reviewSessionTTL = ttl
+}
+
+// SetReviewReminderAfter configures how long a review session may stay inactive before the admin
+// is reminded of it. A zero duration disables reminders.
+func (m *Manager) SetReviewReminderAfter(after time.Duration) {
+	m.reviewSessionsMutex.Lock()
+	defer m.reviewSessionsMutex.Unlock()
+	m.reviewReminderAfter = after
+}
+
+// StartReviewSessionJanitor periodically expires inactive review sessions and reminds admins
+// of abandoned ones until ctx is done.
+func (m *Manager) StartReviewSessionJanitor(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(reviewSessionSweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.expireStaleReviewSessions(ctx)
+				m.sendReviewReminders(ctx, time.Now())
+			}
+		}
+	}()
+}
+
+// isReviewSessionExpiredLocked reports whether a session has been inactive longer than the TTL.
+// A snoozed session only starts expiring when the snooze ends.
+// The caller must hold reviewSessionsMutex.
+func (m *Manager) isReviewSessionExpiredLocked(session *ReviewSession) bool {
+	idleSince := session.LastActivityAt
+	if session.SnoozedUntil.After(idleSince) {
+		idleSince = session.SnoozedUntil
+	}
+	return m.reviewSessionTTL > 0 && time.Since(idleSince) > m.reviewSessionTTL
+}
+
+// expireStaleReviewSessions ends inactive sessions, deletes their review messages,
+// releases their claimed suggestions, and notifies the admins.
+func (m *Manager) expireStaleReviewSessions(ctx context.Context) {
+	m.reviewSessionsMutex.Lock()
+	expired := make([]*ReviewSession, 0)
+	for adminID, session := range m.reviewSessions {
+		if m.isReviewSessionExpiredLocked(session) {
+			expired = append(expired, m.removeReviewSessionLocked(adminID))
+		}
+	}
+	ttl := m.reviewSessionTTL
+	m.reviewSessionsMutex.Unlock()
+
+	for _, session := range expired {
+		log.Printf("[ReviewSession Admin:%d] Session expired after %v of inactivity", session.AdminID, ttl)
+		m.deleteReviewMessages(ctx, session.ReviewChatID, session.CurrentMediaMessageIDs, session.CurrentControlMessageID)
+		if session.ReminderMessageID != 0 {
+			m.deleteReviewMessages(ctx, session.ReviewChatID, nil, session.ReminderMessageID)
+		}
+
+		localizer := m.localizerForUserID(ctx, session.AdminID)
+		msg := locales.GetMessage(localizer, "MsgReviewSessionTimedOut", map[string]interface{}{
+			"Duration": format.Duration(localizer, ttl),
+		}, nil)
+		if _, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(session.ReviewChatID), msg)); err != nil {
+			log.Printf("[ReviewSession Admin:%d] Error sending session expiry message: %v", session.AdminID, err)
+		}
+	}
+}
+
+// removeReviewSessionLocked deletes an admin's session and releases the suggestions it claimed.
+// It returns the removed session, or nil if there was none. The caller must hold reviewSessionsMutex.
+func (m *Manager) removeReviewSessionLocked(adminID int64) *ReviewSession {
+	session, ok := m.reviewSessions[adminID]
+	if !ok {
+		return nil
+	}
+	delete(m.reviewSessions, adminID)
+	for id, claimedBy := range m.claimedSuggestions {
+		if claimedBy == adminID {
+			delete(m.claimedSuggestions, id)
+		}
+	}
+	return session
+}
+
+// claimUnclaimedLocked filters out suggestions claimed by other admins, claims up to limit
+// of the rest for adminID, and returns them. The caller must hold reviewSessionsMutex.
+func (m *Manager) claimUnclaimedLocked(adminID int64, candidates []models.Suggestion, limit int) []models.Suggestion {
+	claimed := make([]models.Suggestion, 0, limit)
+	for _, suggestion := range candidates {
+		if len(claimed) >= limit {
+			break
+		}
+		if owner, ok := m.claimedSuggestions[suggestion.ID]; ok && owner != adminID {
+			continue
+		}
+		m.claimedSuggestions[suggestion.ID] = adminID
+		claimed = append(claimed, suggestion)
+	}
+	return claimed
+}
+
+// releaseClaimLocked releases a single suggestion once it has been decided.
+// The caller must hold reviewSessionsMutex.
+func (m *Manager) releaseClaimLocked(suggestionID primitive.ObjectID) {
+	delete(m.claimedSuggestions, suggestionID)
+}
+
+// activeReviewSession returns the admin's session if it exists and has not expired.
+func (m *Manager) activeReviewSession(adminID int64) (*ReviewSession, bool) {
+	m.reviewSessionsMutex.RLock()
+	defer m.reviewSessionsMutex.RUnlock()
+	session, ok := m.reviewSessions[adminID]
+	if !ok || m.isReviewSessionExpiredLocked(session) {
+		return nil, false
+	}
+	return session, true
+}
+
+// touchReviewSession marks the admin's session as active now. A pending reminder is
+// removed and a snooze is cancelled, since the admin is back.
+func (m *Manager) touchReviewSession(adminID int64) {
+	m.reviewSessionsMutex.Lock()
+	defer m.reviewSessionsMutex.Unlock()
+	if session, ok := m.reviewSessions[adminID]; ok {
+		m.markReviewSessionActiveLocked(session)
+	}
+}
+
+// markReviewSessionActiveLocked records activity in a session and clears its reminder state.
+// The caller must hold reviewSessionsMutex.
+func (m *Manager) markReviewSessionActiveLocked(session *ReviewSession) {
+	session.LastActivityAt = time.Now()
+	session.SnoozedUntil = time.Time{}
+	if session.ReminderMessageID != 0 {
+		go m.deleteReviewMessages(context.Background(), session.ReviewChatID, nil, session.ReminderMessageID)
+		session.ReminderMessageID = 0
+	}
+}
+
+// isReviewReminderDueLocked reports whether an admin should be reminded of a session at now:
+// it has been inactive for the reminder interval, or its snooze has ended, and the admin
+// hasn't been reminded since. The caller must hold reviewSessionsMutex.
+func (m *Manager) isReviewReminderDueLocked(session *ReviewSession, now time.Time) bool {
+	if m.reviewReminderAfter <= 0 || session.ReminderMessageID != 0 {
+		return false
+	}
+	if !session.SnoozedUntil.IsZero() {
+		return !now.Before(session.SnoozedUntil)
+	}
+	return now.Sub(session.LastActivityAt) >= m.reviewReminderAfter
+}
+
+// sendReviewReminders reminds admins of review sessions they left unfinished, with buttons to
+// resume the session, snooze the reminder, or end the session. Admins in their quiet hours
+// are reminded once the quiet hours end, if the session is still around.
+func (m *Manager) sendReviewReminders(ctx context.Context, now time.Time) {
+	type reminder struct {
+		adminID, chatID int64
+		remaining       int
+	}
+	m.reviewSessionsMutex.RLock()
+	var due []reminder
+	for adminID, session := range m.reviewSessions {
+		if !m.isReviewSessionExpiredLocked(session) && m.isReviewReminderDueLocked(session, now) {
+			due = append(due, reminder{adminID: adminID, chatID: session.ReviewChatID, remaining: len(session.Suggestions)})
+		}
+	}
+	m.reviewSessionsMutex.RUnlock()
+
+	for _, r := range due {
+		quiet, err := m.GetQuietHours(ctx, r.adminID)
+		if err != nil {
+			log.Printf("[ReviewSession Admin:%d] Error getting quiet hours, reminding anyway: %v", r.adminID, err)
+		}
+		if quiet != nil && quiet.Contains(now, m.location) {
+			continue
+		}
+
+		localizer := m.localizerForUserID(ctx, r.adminID)
+		text := locales.GetPluralMessage(localizer, "MsgReviewReminder", r.remaining, nil)
+		keyboard := tu.InlineKeyboard(
+			tu.InlineKeyboardRow(
+				tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnReviewResume", nil, nil)).WithCallbackData(reviewSessionResumeData),
+				tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnReviewSnooze", nil, nil)).WithCallbackData(reviewSessionSnoozeData),
+			),
+			tu.InlineKeyboardRow(
+				tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnReviewDismiss", nil, nil)).WithCallbackData(reviewSessionDismissData),
+			),
+		)
+		sent, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(r.chatID), text).WithReplyMarkup(keyboard))
+		if err != nil {
+			log.Printf("[ReviewSession Admin:%d] Error sending review reminder: %v", r.adminID, err)
+			continue
+		}
+		log.Printf("[ReviewSession Admin:%d] Reminded of the unfinished session (%d left)", r.adminID, r.remaining)
+
+		m.reviewSessionsMutex.Lock()
+		session, ok := m.reviewSessions[r.adminID]
+		stillDue := ok && m.isReviewReminderDueLocked(session, now)
+		if stillDue {
+			session.ReminderMessageID = sent.MessageID
+			session.SnoozedUntil = time.Time{}
+		}
+		m.reviewSessionsMutex.Unlock()
+		if !stillDue {
+			// The admin came back or the session ended while the reminder was being sent
+			m.deleteReviewMessages(ctx, r.chatID, nil, sent.MessageID)
+		}
+	}
+}
+
+// promptResumeReviewSession asks an admin with an unfinished session whether to resume it or start over.
+func (m *Manager) promptResumeReviewSession(ctx context.Context, adminID, chatID int64, session *ReviewSession) error {
+	localizer := m.localizerForUserID(ctx, adminID)
+
+	m.reviewSessionsMutex.RLock()
+	remaining := len(session.Suggestions)
+	m.reviewSessionsMutex.RUnlock()
+
+	text := locales.GetPluralMessage(localizer, "MsgReviewResumePrompt", remaining, nil)
+	keyboard := tu.InlineKeyboard(tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnReviewResume", nil, nil)).WithCallbackData(reviewSessionResumeData),
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnReviewStartNew", nil, nil)).WithCallbackData(reviewSessionRestartData),
+	))
+	_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), text).WithReplyMarkup(keyboard))
+	return err
+}
+
+// handleReviewSessionCallback handles the buttons of the "Resume previous session?" prompt
+// and of the review reminder.
+func (m *Manager) handleReviewSessionCallback(ctx context.Context, query telego.CallbackQuery) error {
+	adminID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	var chatID int64
+	if msg, ok := query.Message.(*telego.Message); ok && msg != nil {
+		chatID = msg.Chat.ID
+		// Remove the prompt so it can't be answered twice
+		go m.deleteReviewMessages(context.Background(), chatID, nil, msg.MessageID)
+	} else {
+		chatID = adminID // Inaccessible prompt message; fall back to the private chat
+	}
+
+	isAdmin, err := m.adminChecker.IsAdmin(ctx, adminID)
+	if err != nil || !isAdmin {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil), true)
+		return err
+	}
+
+	action := strings.TrimPrefix(query.Data, reviewSessionCallbackPrefix)
+	session, active := m.activeReviewSession(adminID)
+
+	switch query.Data {
+	case reviewSessionSnoozeData:
+		if !active {
+			_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgReviewSessionExpired", nil, nil), true)
+			return nil
+		}
+		m.reviewSessionsMutex.Lock()
+		session.ReminderMessageID = 0 // The reminder is being deleted above
+		session.SnoozedUntil = time.Now().Add(ReviewReminderSnooze)
+		m.reviewSessionsMutex.Unlock()
+		log.Printf("[ReviewSession Admin:%d] Reminder snoozed for %v", adminID, ReviewReminderSnooze)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgReviewReminderSnoozed", map[string]interface{}{
+			"When": format.RelativeTime(localizer, time.Now().Add(ReviewReminderSnooze), time.Now()),
+		}, nil), false)
+		return nil
+
+	case reviewSessionDismissData:
+		m.reviewSessionsMutex.Lock()
+		old := m.removeReviewSessionLocked(adminID)
+		m.reviewSessionsMutex.Unlock()
+		if old != nil {
+			log.Printf("[ReviewSession Admin:%d] Session dismissed from the reminder", adminID)
+			go m.deleteReviewMessages(context.Background(), old.ReviewChatID, old.CurrentMediaMessageIDs, old.CurrentControlMessageID)
+		}
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgReviewSessionDismissed", nil, nil), false)
+		return nil
+	}
+	_ = m.answerCallbackQuery(ctx, query.ID, "", false)
+
+	if query.Data == reviewSessionResumeData && active {
+		log.Printf("[ReviewSession Admin:%d] Resuming session at index %d", adminID, session.CurrentIndex)
+		m.reviewSessionsMutex.Lock()
+		mediaIDs, controlID, index := session.CurrentMediaMessageIDs, session.CurrentControlMessageID, session.CurrentIndex
+		session.ReminderMessageID = 0 // The reminder, if this came from one, is being deleted above
+		m.markReviewSessionActiveLocked(session)
+		session.ReviewChatID = chatID
+		m.reviewSessionsMutex.Unlock()
+
+		go m.deleteReviewMessages(context.Background(), chatID, mediaIDs, controlID)
+		return m.SendReviewMessage(ctx, chatID, adminID, index)
+	}
+
+	// Start a new session, discarding the previous one (if it is still around)
+	m.reviewSessionsMutex.Lock()
+	old := m.removeReviewSessionLocked(adminID)
+	m.reviewSessionsMutex.Unlock()
+	if old != nil {
+		go m.deleteReviewMessages(context.Background(), old.ReviewChatID, old.CurrentMediaMessageIDs, old.CurrentControlMessageID)
+	}
+	log.Printf("[ReviewSession Admin:%d] Starting new session (action: %s)", adminID, action)
+	return m.startReviewSession(ctx, adminID, chatID, "")
+}
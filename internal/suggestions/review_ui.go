@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/format"
 	"vrcmemes-bot/internal/locales"
 	"vrcmemes-bot/pkg/utils"
 
@@ -23,12 +26,11 @@ func (m *Manager) SendReviewMessage(ctx context.Context, chatID, adminID int64,
 
 	if !ok || suggestionIndex < 0 || suggestionIndex >= len(session.Suggestions) {
 		log.Printf("[SendReviewMessage] Invalid session or index for admin %d, index %d", adminID, suggestionIndex)
-		lang := locales.GetDefaultLanguageTag().String()
-		localizer := locales.NewLocalizer(lang)
+		localizer := m.localizerForUserID(ctx, adminID)
 		msgText := locales.GetMessage(localizer, "MsgReviewQueueIsEmpty", nil, nil) // Use msgText for clarity
 		_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), msgText))
 		m.reviewSessionsMutex.Lock()
-		delete(m.reviewSessions, adminID)
+		m.removeReviewSessionLocked(adminID)
 		m.reviewSessionsMutex.Unlock()
 		return err
 	}
@@ -36,51 +38,21 @@ func (m *Manager) SendReviewMessage(ctx context.Context, chatID, adminID int64,
 	suggestion := session.Suggestions[suggestionIndex]
 	totalSuggestionsInBatch := len(session.Suggestions)
 
-	lang := locales.GetDefaultLanguageTag().String() // Admin's language, can be made more flexible
-	localizer := locales.NewLocalizer(lang)
+	localizer := m.localizerForUserID(ctx, adminID)
 
 	messageText := m.buildReviewMessageText(localizer, &suggestion, suggestionIndex, totalSuggestionsInBatch)
 	suggestionIDHex := suggestion.ID.Hex()
-
-	// --- Keyboard ---
-	approveData := fmt.Sprintf("review:%s:approve:%d", suggestionIDHex, suggestionIndex)
-	rejectData := fmt.Sprintf("review:%s:reject:%d", suggestionIDHex, suggestionIndex)
-	nextData := fmt.Sprintf("review:%s:next:%d", suggestionIDHex, suggestionIndex)
-	previousData := fmt.Sprintf("review:%s:previous:%d", suggestionIDHex, suggestionIndex)
-
-	btnApproveText := locales.GetMessage(localizer, "BtnApprove", nil, nil)
-	btnRejectText := locales.GetMessage(localizer, "BtnReject", nil, nil)
-	btnNextText := locales.GetMessage(localizer, "BtnNext", nil, nil)
-	btnPreviousText := locales.GetMessage(localizer, "BtnPrevious", nil, nil)
-
-	keyboardRows := [][]telego.InlineKeyboardButton{
-		tu.InlineKeyboardRow(
-			tu.InlineKeyboardButton(btnApproveText).WithCallbackData(approveData),
-			tu.InlineKeyboardButton(btnRejectText).WithCallbackData(rejectData),
-		),
-	}
-	navRow := []telego.InlineKeyboardButton{}
-	if suggestionIndex > 0 {
-		navRow = append(navRow, tu.InlineKeyboardButton(btnPreviousText).WithCallbackData(previousData))
-	}
-	if suggestionIndex+1 < totalSuggestionsInBatch {
-		navRow = append(navRow, tu.InlineKeyboardButton(btnNextText).WithCallbackData(nextData))
-	}
-	if len(navRow) > 0 {
-		keyboardRows = append(keyboardRows, navRow)
-	}
-	keyboard := &telego.InlineKeyboardMarkup{
-		InlineKeyboard: keyboardRows,
-	}
-	// --- End Keyboard ---
+	keyboard := m.reviewKeyboard(localizer, &suggestion, suggestionIndex, totalSuggestionsInBatch)
 
 	var sentMediaMessages []*telego.Message
 	var sentControlMessage *telego.Message
-	var mediaSendError error
 
-	inputMedia := m.createInputMediaFromSuggestion(suggestion)
+	originalMedia := m.createInputMediaFromSuggestion(suggestion)
+	inputMedia, mediaSendError := m.proxiedMedia(ctx, originalMedia)
 
-	if len(inputMedia) == 0 {
+	if mediaSendError != nil {
+		log.Printf("[SendReviewMessage] Error re-uploading media of suggestion %s: %v", suggestionIDHex, mediaSendError)
+	} else if len(inputMedia) == 0 {
 		// If no media initially (e.g., text suggestion, though current logic doesn't assume this)
 		// or error in createInputMediaFromSuggestion
 		log.Printf("[SendReviewMessage] No valid media found for suggestion ID %s. Sending text only.", suggestionIDHex)
@@ -96,26 +68,15 @@ func (m *Manager) SendReviewMessage(ctx context.Context, chatID, adminID int64,
 		}
 		sentControlMessage = controlMsg
 	} else if len(inputMedia) == 1 {
-		var fileID string
-		if photoInput, ok := inputMedia[0].(*telego.InputMediaPhoto); ok {
-			fileID = photoInput.Media.FileID
-		}
-		// TODO: Handle other media types like video if necessary
-
-		sendParams := &telego.SendPhotoParams{
-			ChatID:      tu.ID(chatID),
-			Photo:       telego.InputFile{FileID: fileID},
-			Caption:     messageText,
-			ParseMode:   telego.ModeMarkdownV2,
-			ReplyMarkup: keyboard,
-		}
-		msg, err := m.bot.SendPhoto(ctx, sendParams)
+		setMediaCaption(inputMedia[0], messageText, telego.ModeMarkdownV2, nil)
+		msg, err := m.sendMediaMessage(ctx, chatID, inputMedia[0], false, keyboard)
 		if err != nil {
-			log.Printf("[SendReviewMessage] Error sending single review photo for suggestion %s to admin %d: %v", suggestionIDHex, adminID, err)
+			log.Printf("[SendReviewMessage] Error sending single review media for suggestion %s to admin %d: %v", suggestionIDHex, adminID, err)
 			mediaSendError = err // Save media send error
 		} else {
 			sentMediaMessages = append(sentMediaMessages, msg)
 			sentControlMessage = msg
+			m.rememberProxied(originalMedia, []telego.Message{*msg})
 			log.Printf("[SendReviewMessage] Sent single media message ID %d (also control) for suggestion %s to admin %d", msg.MessageID, suggestionIDHex, adminID)
 		}
 	} else { // len(inputMedia) > 1
@@ -127,7 +88,8 @@ func (m *Manager) SendReviewMessage(ctx context.Context, chatID, adminID int64,
 				bareMediaGroup[i] = &telego.InputMediaPhoto{Type: media.Type, Media: media.Media} // Copy without Caption
 			case *telego.InputMediaVideo:
 				bareMediaGroup[i] = &telego.InputMediaVideo{Type: media.Type, Media: media.Media} // Copy without Caption
-				// Add other media types if supported
+			case *telego.InputMediaDocument:
+				bareMediaGroup[i] = &telego.InputMediaDocument{Type: media.Type, Media: media.Media} // Copy without Caption
 			default:
 				log.Printf("[SendReviewMessage] Unsupported media type in media group for suggestion %s", suggestionIDHex)
 				mediaSendError = fmt.Errorf("unsupported media type in group for suggestion %s", suggestionIDHex)
@@ -147,6 +109,7 @@ func (m *Manager) SendReviewMessage(ctx context.Context, chatID, adminID int64,
 				for i := range groupMessages { // Convert to []*telego.Message
 					sentMediaMessages = append(sentMediaMessages, &groupMessages[i])
 				}
+				m.rememberProxied(originalMedia, groupMessages)
 				// Then send a separate message with text and keyboard
 				controlMsg, errCtrl := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), messageText).WithReplyMarkup(keyboard).WithParseMode(telego.ModeMarkdownV2))
 				if errCtrl != nil {
@@ -186,7 +149,7 @@ func (m *Manager) SendReviewMessage(ctx context.Context, chatID, adminID int64,
 		// Send error message about media display WITH KEYBOARD
 		// Use messageText (which contains description) + error message
 		errorNotificationTextKey := "MsgReviewErrorDisplayingMedia" // New localization string
-		rawErrorNotificationText := locales.GetMessage(localizer, errorNotificationTextKey, map[string]interface{}{"SuggestionID": suggestionIDHex}, nil)
+		rawErrorNotificationText := locales.GetMessage(localizer, errorNotificationTextKey, map[string]interface{}{"Code": suggestion.Ref()}, nil)
 		errorNotificationText := utils.EscapeMarkdownV2(rawErrorNotificationText) // <<<< ESCAPE THIS PART
 
 		// Form text that DEFINITELY won't cause Markdown issues
@@ -225,6 +188,7 @@ func (m *Manager) SendReviewMessage(ctx context.Context, chatID, adminID int64,
 			currentSession.CurrentMediaMessageIDs = mediaIDs // Will be empty if media didn't send or were deleted
 			currentSession.CurrentControlMessageID = sentControlMessage.MessageID
 			currentSession.CurrentIndex = suggestionIndex
+			currentSession.LastActivityAt = time.Now()
 			log.Printf("[SendReviewMessage] Stored MediaIDs: %v, ControlID: %d for session of admin %d", mediaIDs, sentControlMessage.MessageID, adminID)
 		} else {
 			log.Printf("[SendReviewMessage] Session for admin %d disappeared before storing message IDs.", adminID)
@@ -250,6 +214,94 @@ func (m *Manager) SendReviewMessage(ctx context.Context, chatID, adminID int64,
 	return nil // Do not return error here to not interrupt /review
 }
 
+// reviewKeyboard builds the review buttons of a suggestion at the given index of a batch.
+func (m *Manager) reviewKeyboard(localizer *i18n.Localizer, suggestion *models.Suggestion, index, total int) *telego.InlineKeyboardMarkup {
+	approveData := reviewCallback{Ref: suggestion.Ref(), Action: ReviewActionApprove, Index: index}.String()
+	rejectData := reviewCallback{Ref: suggestion.Ref(), Action: ReviewActionReject, Index: index}.String()
+	nextData := reviewCallback{Ref: suggestion.Ref(), Action: ReviewActionNext, Index: index}.String()
+	previousData := reviewCallback{Ref: suggestion.Ref(), Action: ReviewActionPrevious, Index: index}.String()
+
+	btnApproveText := locales.GetMessage(localizer, "BtnApprove", nil, nil)
+	btnRejectText := locales.GetMessage(localizer, "BtnReject", nil, nil)
+	btnNextText := locales.GetMessage(localizer, "BtnNext", nil, nil)
+	btnPreviousText := locales.GetMessage(localizer, "BtnPrevious", nil, nil)
+
+	decisionRow := tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(btnApproveText).WithCallbackData(approveData),
+		tu.InlineKeyboardButton(btnRejectText).WithCallbackData(rejectData),
+	)
+	if m.previewChannelID != 0 {
+		previewData := reviewCallback{Ref: suggestion.Ref(), Action: ReviewActionPreview, Index: index}.String()
+		btnPreviewText := locales.GetMessage(localizer, "BtnPreviewFirst", nil, nil)
+		decisionRow = append(decisionRow, tu.InlineKeyboardButton(btnPreviewText).WithCallbackData(previewData))
+	}
+	keyboardRows := [][]telego.InlineKeyboardButton{decisionRow}
+	var extrasRow []telego.InlineKeyboardButton
+	if len(m.tags) > 0 {
+		tagsData := reviewCallback{Ref: suggestion.Ref(), Action: ReviewActionTags, Index: index}.String()
+		btnTagsText := locales.GetMessage(localizer, "BtnTags", map[string]interface{}{"Count": len(suggestion.Tags)}, nil)
+		extrasRow = append(extrasRow, tu.InlineKeyboardButton(btnTagsText).WithCallbackData(tagsData))
+	}
+	if m.rubricRepo != nil {
+		rubricData := reviewCallback{Ref: suggestion.Ref(), Action: ReviewActionRubric, Index: index}.String()
+		btnRubricText := locales.GetMessage(localizer, "BtnRubric", nil, nil)
+		extrasRow = append(extrasRow, tu.InlineKeyboardButton(btnRubricText).WithCallbackData(rubricData))
+	}
+	if m.settingsRepo != nil {
+		styleData := reviewCallback{Ref: suggestion.Ref(), Action: ReviewActionStyle, Index: index}.String()
+		btnStyleText := locales.GetMessage(localizer, "BtnStyle", nil, nil)
+		extrasRow = append(extrasRow, tu.InlineKeyboardButton(btnStyleText).WithCallbackData(styleData))
+	}
+	askData := reviewCallback{Ref: suggestion.Ref(), Action: ReviewActionAsk, Index: index}.String()
+	extrasRow = append(extrasRow, tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnAskSuggester", nil, nil)).WithCallbackData(askData))
+	if total > 1 {
+		mergeData := reviewCallback{Ref: suggestion.Ref(), Action: ReviewActionMerge, Index: index}.String()
+		extrasRow = append(extrasRow, tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnMerge", nil, nil)).WithCallbackData(mergeData))
+	}
+	if len(extrasRow) > 0 {
+		keyboardRows = append(keyboardRows, extrasRow)
+	}
+	protectData := reviewCallback{Ref: suggestion.Ref(), Action: ReviewActionProtect, Index: index}.String()
+	btnProtectID := "BtnProtectContentOff"
+	if m.protected(suggestion) {
+		btnProtectID = "BtnProtectContentOn"
+	}
+	optionsRow := tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, btnProtectID, nil, nil)).WithCallbackData(protectData),
+	)
+	if suggestion.TranslatedCaption != "" {
+		captionData := reviewCallback{Ref: suggestion.Ref(), Action: ReviewActionCaption, Index: index}.String()
+		btnCaptionText := locales.GetMessage(localizer, captionChoiceButton(suggestion.CaptionChoice), nil, nil)
+		optionsRow = append(optionsRow, tu.InlineKeyboardButton(btnCaptionText).WithCallbackData(captionData))
+	}
+	if m.thumbnails != nil && firstVideo(suggestion) >= 0 {
+		thumbData := reviewCallback{Ref: suggestion.Ref(), Action: ReviewActionThumb, Index: index}.String()
+		optionsRow = append(optionsRow, tu.InlineKeyboardButton(thumbnailButton(localizer, suggestion)).WithCallbackData(thumbData))
+	}
+	if suggestion.HasVideoPolicyIssues() {
+		videoData := reviewCallback{Ref: suggestion.Ref(), Action: ReviewActionVideo, Index: index}.String()
+		btnVideoID := "BtnVideoPolicyEnforced"
+		if suggestion.VideoPolicyOverride {
+			btnVideoID = "BtnVideoPolicyOverridden"
+		}
+		optionsRow = append(optionsRow, tu.InlineKeyboardButton(locales.GetMessage(localizer, btnVideoID, nil, nil)).WithCallbackData(videoData))
+	}
+	keyboardRows = append(keyboardRows, optionsRow)
+	navRow := []telego.InlineKeyboardButton{}
+	if index > 0 {
+		navRow = append(navRow, tu.InlineKeyboardButton(btnPreviousText).WithCallbackData(previousData))
+	}
+	if index+1 < total {
+		navRow = append(navRow, tu.InlineKeyboardButton(btnNextText).WithCallbackData(nextData))
+	}
+	if len(navRow) > 0 {
+		keyboardRows = append(keyboardRows, navRow)
+	}
+	return &telego.InlineKeyboardMarkup{
+		InlineKeyboard: keyboardRows,
+	}
+}
+
 // deleteReviewMessages attempts to delete the review prompt message and associated media messages.
 func (m *Manager) deleteReviewMessages(ctx context.Context, chatID int64, mediaMessageIDs []int, controlMessageID int) {
 	// Delete media messages first
@@ -295,12 +347,12 @@ func (m *Manager) answerCallbackQuery(ctx context.Context, queryID string, text
 	return err
 }
 
-// createInputMediaFromSuggestion converts suggestion FileIDs to telego.InputMedia.
+// createInputMediaFromSuggestion converts suggestion FileIDs to telego.InputMedia of their media types.
 func (m *Manager) createInputMediaFromSuggestion(suggestion models.Suggestion) []telego.InputMedia {
 	var inputMedia []telego.InputMedia
 	maxItems := len(suggestion.FileIDs)
 	if maxItems > 10 {
-		log.Printf("[createInputMediaFromSuggestion] Suggestion %s has more than 10 photos (%d), truncating.", suggestion.ID.Hex(), maxItems)
+		log.Printf("[createInputMediaFromSuggestion] Suggestion %s has more than 10 files (%d), truncating.", suggestion.ID.Hex(), maxItems)
 		maxItems = 10
 	}
 
@@ -312,15 +364,39 @@ func (m *Manager) createInputMediaFromSuggestion(suggestion models.Suggestion) [
 			log.Printf("[createInputMediaFromSuggestion] Warning: Empty FileID at index %d for suggestion %s", i, suggestion.ID.Hex())
 			continue // Skip empty file IDs
 		}
-		mediaPhoto := &telego.InputMediaPhoto{
-			Type:  "photo",
-			Media: telego.InputFile{FileID: fileID},
-		}
-		inputMedia = append(inputMedia, mediaPhoto)
+		inputMedia = append(inputMedia, newInputMedia(suggestion.MediaType(i), telego.InputFile{FileID: fileID}))
 	}
 	return inputMedia
 }
 
+// newInputMedia returns input media of a media type for the file.
+func newInputMedia(mediaType string, file telego.InputFile) telego.InputMedia {
+	switch mediaType {
+	case models.MediaTypeVideo:
+		return &telego.InputMediaVideo{Type: telego.MediaTypeVideo, Media: file}
+	case models.MediaTypeAnimation:
+		return &telego.InputMediaAnimation{Type: telego.MediaTypeAnimation, Media: file}
+	case models.MediaTypeDocument:
+		return &telego.InputMediaDocument{Type: telego.MediaTypeDocument, Media: file}
+	default:
+		return &telego.InputMediaPhoto{Type: telego.MediaTypePhoto, Media: file}
+	}
+}
+
+// setMediaCaption sets the caption of input media of any type.
+func setMediaCaption(item telego.InputMedia, caption, parseMode string, entities []telego.MessageEntity) {
+	switch media := item.(type) {
+	case *telego.InputMediaPhoto:
+		media.Caption, media.ParseMode, media.CaptionEntities = caption, parseMode, entities
+	case *telego.InputMediaVideo:
+		media.Caption, media.ParseMode, media.CaptionEntities = caption, parseMode, entities
+	case *telego.InputMediaAnimation:
+		media.Caption, media.ParseMode, media.CaptionEntities = caption, parseMode, entities
+	case *telego.InputMediaDocument:
+		media.Caption, media.ParseMode, media.CaptionEntities = caption, parseMode, entities
+	}
+}
+
 // buildReviewMessageText formats the text for the review message.
 func (m *Manager) buildReviewMessageText(localizer *i18n.Localizer, suggestion *models.Suggestion, index, total int) string {
 	// Part 1: Index text
@@ -328,7 +404,11 @@ func (m *Manager) buildReviewMessageText(localizer *i18n.Localizer, suggestion *
 	rawIndexText := locales.GetMessage(localizer, "MsgReviewCurrentSuggestionIndex", map[string]interface{}{
 		"Index": index + 1, // User-friendly 1-based index
 		"Total": total,
+		"Code":  suggestion.Ref(),
 	}, nil)
+	if suggestion.Test {
+		rawIndexText += "\n" + locales.GetMessage(localizer, "MsgReviewTestSuggestion", nil, nil)
+	}
 	// Escape the entire localized string
 	escapedIndexText := utils.EscapeMarkdownV2(rawIndexText)
 
@@ -350,6 +430,25 @@ func (m *Manager) buildReviewMessageText(localizer *i18n.Localizer, suggestion *
 	}, nil)
 	// Escape the entire localized "From" string
 	escapedFromText := utils.EscapeMarkdownV2(rawFromText)
+	if !suggestion.SubmittedAt.IsZero() {
+		rawSubmittedText := locales.GetMessage(localizer, "MsgReviewSubmitted", map[string]interface{}{
+			"When": format.RelativeTime(localizer, suggestion.SubmittedAt, time.Now()),
+		}, nil)
+		escapedFromText += "\n" + utils.EscapeMarkdownV2(rawSubmittedText)
+	}
+	if len(suggestion.CoSuggesters) > 0 {
+		names := make([]string, 0, len(suggestion.CoSuggesters))
+		for _, co := range suggestion.CoSuggesters {
+			names = append(names, fmt.Sprintf("%s (#%s)", coSuggesterDisplayName(co), co.RefCode))
+		}
+		rawCoText := locales.GetMessage(localizer, "MsgReviewCoSuggesters", map[string]interface{}{
+			"Names": strings.Join(names, ", "),
+		}, nil)
+		escapedFromText += "\n" + utils.EscapeMarkdownV2(rawCoText)
+	}
+	if rawHistoryText := historyText(localizer, suggestion.History, time.Now()); rawHistoryText != "" {
+		escapedFromText += "\n" + utils.EscapeMarkdownV2(rawHistoryText)
+	}
 
 	// Part 3: Caption text
 	var rawCaptionContent string
@@ -367,6 +466,75 @@ func (m *Manager) buildReviewMessageText(localizer *i18n.Localizer, suggestion *
 	rawCaptionLine := rawCaptionPrefix + " " + rawCaptionContent
 	// Escape the entire localized "Caption" line
 	escapedCaptionLine := utils.EscapeMarkdownV2(rawCaptionLine)
+	if suggestion.TranslatedCaption != "" {
+		rawTranslationText := locales.GetMessage(localizer, "MsgReviewTranslation", map[string]interface{}{
+			"Language": suggestion.CaptionLanguage,
+			"Text":     suggestion.TranslatedCaption,
+		}, nil)
+		escapedCaptionLine += "\n" + utils.EscapeMarkdownV2(rawTranslationText)
+	}
+	if len(suggestion.FilteredWords) > 0 {
+		rawFilteredText := locales.GetMessage(localizer, "MsgReviewFilteredWords", map[string]interface{}{
+			"Words": strings.Join(suggestion.FilteredWords, ", "),
+		}, nil)
+		escapedCaptionLine += "\n" + utils.EscapeMarkdownV2(rawFilteredText)
+	}
+
+	if len(suggestion.MediaIssues) > 0 {
+		rawIssuesText := locales.GetMessage(localizer, "MsgReviewMediaIssues", map[string]interface{}{
+			"Issues": DescribeMediaIssues(localizer, suggestion.MediaIssues, len(suggestion.FileIDs) > 1),
+		}, nil)
+		escapedCaptionLine += "\n" + utils.EscapeMarkdownV2(rawIssuesText)
+	}
+
+	// Part 4: Forward origin, so admins can judge reposts from other channels
+	if origin := suggestion.ForwardOrigin; origin != nil {
+		source := origin.DisplayName()
+		if link := origin.Link(); link != "" {
+			source = link
+		}
+		rawForwardText := locales.GetMessage(localizer, "MsgReviewForwardedFrom", map[string]interface{}{
+			"Source": source,
+		}, nil)
+		escapedCaptionLine += "\n" + utils.EscapeMarkdownV2(rawForwardText)
+	}
+
+	// Part 5: Tags picked so far
+	if len(suggestion.Tags) > 0 {
+		rawTagsText := locales.GetMessage(localizer, "MsgReviewTags", map[string]interface{}{
+			"Tags": hashtags(suggestion.Tags),
+		}, nil)
+		escapedCaptionLine += "\n" + utils.EscapeMarkdownV2(rawTagsText)
+	}
+
+	// Part 6: Rubric the post is published in
+	if suggestion.Rubric != "" {
+		rawRubricText := locales.GetMessage(localizer, "MsgReviewRubric", map[string]interface{}{
+			"Name": suggestion.Rubric,
+		}, nil)
+		escapedCaptionLine += "\n" + utils.EscapeMarkdownV2(rawRubricText)
+	}
+
+	// Part 7: Caption style the post is decorated with
+	if suggestion.Style != "" {
+		rawStyleText := locales.GetMessage(localizer, "MsgReviewStyle", map[string]interface{}{
+			"Name": suggestion.Style,
+		}, nil)
+		escapedCaptionLine += "\n" + utils.EscapeMarkdownV2(rawStyleText)
+	}
+
+	// Part 8: Earlier suggestion with the same text in the picture, a likely duplicate
+	if suggestion.SimilarTo != "" {
+		rawSimilarText := locales.GetMessage(localizer, "MsgReviewSimilarText", map[string]interface{}{
+			"Code": suggestion.SimilarTo,
+		}, nil)
+		escapedCaptionLine += "\n" + utils.EscapeMarkdownV2(rawSimilarText)
+	}
+
+	// Part 9: Questions to the suggester and their answers
+	if thread := commentThread(localizer, suggestion.Comments); thread != "" {
+		escapedCaptionLine += "\n" + utils.EscapeMarkdownV2(thread)
+	}
 
 	// Combine all parts with actual newlines.
 	return fmt.Sprintf("%s\n%s\n%s", escapedIndexText, escapedFromText, escapedCaptionLine)
@@ -0,0 +1,206 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/engagement"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// rubricCallbackPrefix starts the callback data of the rubric picker buttons: rubric:<id>:<index>:<name>.
+// An empty name publishes the suggestion without a rubric.
+const rubricCallbackPrefix = "rubric:"
+
+// SetRubricRepository sets where rubrics are stored. With it, reviewers can pick the rubric
+// a suggestion is published in, and the post takes the rubric's next number.
+func (m *Manager) SetRubricRepository(repo database.RubricRepository) {
+	m.rubricRepo = repo
+}
+
+// SetEngagementPrompter sets what reacts to and comments under published suggestions, as set up
+// for their rubric. Without it, posts get neither.
+func (m *Manager) SetEngagementPrompter(prompter *engagement.Prompter) {
+	m.engagement = prompter
+}
+
+// rubricCallback is the parsed callback data of a rubric picker button.
+type rubricCallback struct {
+	Ref    string // Reference of the suggestion, see models.Suggestion.Ref
+	Index  int    // Position of the suggestion in the admin's review batch
+	Rubric string // Picked rubric, or "" for none
+}
+
+// String formats the callback data for an inline button.
+func (c rubricCallback) String() string {
+	return fmt.Sprintf("%s%s:%d:%s", rubricCallbackPrefix, c.Ref, c.Index, c.Rubric)
+}
+
+// errRubricCallbackMalformed is returned by parseRubricCallback for data not produced by rubricCallback.String.
+var errRubricCallbackMalformed = errors.New("malformed rubric callback data")
+
+// parseRubricCallback parses rubric picker callback data.
+func parseRubricCallback(data string) (rubricCallback, error) {
+	parts := strings.SplitN(strings.TrimPrefix(data, rubricCallbackPrefix), ":", 3)
+	if !strings.HasPrefix(data, rubricCallbackPrefix) || len(data) > maxCallbackDataLength || len(parts) != 3 {
+		return rubricCallback{}, fmt.Errorf("%w: %q", errRubricCallbackMalformed, data)
+	}
+	if !isSuggestionRef(parts[0]) {
+		return rubricCallback{}, fmt.Errorf("%w: invalid suggestion reference %q", errRubricCallbackMalformed, parts[0])
+	}
+	index, err := strconv.Atoi(parts[1])
+	if err != nil || index < 0 {
+		return rubricCallback{}, fmt.Errorf("%w: invalid index %q", errRubricCallbackMalformed, parts[1])
+	}
+	return rubricCallback{Ref: parts[0], Index: index, Rubric: parts[2]}, nil
+}
+
+// rubricPickerKeyboard builds the rubric picker for a suggestion: a button per rubric, the
+// current one marked, and a button to publish without a rubric.
+func rubricPickerKeyboard(localizer *i18n.Localizer, suggestion *models.Suggestion, index int, rubrics []models.Rubric) *telego.InlineKeyboardMarkup {
+	var rows [][]telego.InlineKeyboardButton
+	for _, rubric := range rubrics {
+		text := rubric.Name
+		if rubric.Name == suggestion.Rubric {
+			text = "✅ " + text
+		}
+		data := rubricCallback{Ref: suggestion.Ref(), Index: index, Rubric: rubric.Name}.String()
+		rows = append(rows, tu.InlineKeyboardRow(tu.InlineKeyboardButton(text).WithCallbackData(data)))
+	}
+	noneData := rubricCallback{Ref: suggestion.Ref(), Index: index}.String()
+	rows = append(rows, tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnRubricNone", nil, nil)).WithCallbackData(noneData),
+	))
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// showRubricPicker replaces the review buttons of the current suggestion with the rubric picker.
+// If no rubrics are defined, the admin is told how to add one instead.
+func (m *Manager) showRubricPicker(ctx context.Context, queryID string, adminID int64, session *ReviewSession, index int) error {
+	localizer := m.localizerForUserID(ctx, adminID)
+	rubrics, err := m.rubricRepo.ListRubrics(ctx)
+	if err != nil {
+		_ = m.answerCallbackQuery(ctx, queryID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("failed to list rubrics: %w", err)
+	}
+	if len(rubrics) == 0 {
+		return m.answerCallbackQuery(ctx, queryID, locales.GetMessage(localizer, "MsgRubricsNone", nil, nil), true)
+	}
+
+	m.reviewSessionsMutex.RLock()
+	suggestion := session.Suggestions[index]
+	chatID, messageID := session.ReviewChatID, session.CurrentControlMessageID
+	m.reviewSessionsMutex.RUnlock()
+
+	_ = m.answerCallbackQuery(ctx, queryID, "", false)
+	_, err = m.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+		ChatID:      tu.ID(chatID),
+		MessageID:   messageID,
+		ReplyMarkup: rubricPickerKeyboard(localizer, &suggestion, index, rubrics),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to show rubric picker for suggestion %s: %w", suggestion.ID.Hex(), err)
+	}
+	return nil
+}
+
+// handleRubricCallback handles the rubric picker buttons: the picked rubric is saved and the
+// review buttons are shown again.
+func (m *Manager) handleRubricCallback(ctx context.Context, query telego.CallbackQuery) error {
+	adminID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	parsed, err := parseRubricCallback(query.Data)
+	if err != nil {
+		log.Printf("[RubricCallback] Rejected callback data %q: %v", query.Data, err)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return err
+	}
+
+	isAdmin, err := m.adminChecker.IsAdmin(ctx, adminID)
+	if err != nil || !isAdmin {
+		log.Printf("[RubricCallback] User %d is not admin (err: %v), ignoring rubric action.", adminID, err)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil), true)
+		return nil
+	}
+
+	session, ok := m.activeReviewSession(adminID)
+	m.reviewSessionsMutex.RLock()
+	valid := ok && parsed.Index < len(session.Suggestions) && session.Suggestions[parsed.Index].Ref() == parsed.Ref
+	var suggestionID primitive.ObjectID
+	if valid {
+		suggestionID = session.Suggestions[parsed.Index].ID
+	}
+	m.reviewSessionsMutex.RUnlock()
+	if !valid || m.rubricRepo == nil {
+		log.Printf("[RubricCallback] Invalid session or suggestion mismatch for admin %d, index %d, ref %s", adminID, parsed.Index, parsed.Ref)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgReviewSessionExpired", nil, nil), true)
+		return nil
+	}
+	m.touchReviewSession(adminID)
+
+	if parsed.Rubric != "" {
+		rubric, err := m.rubricRepo.GetRubric(ctx, parsed.Rubric)
+		if err != nil {
+			_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+			return fmt.Errorf("failed to get rubric %s: %w", parsed.Rubric, err)
+		}
+		if rubric == nil {
+			return m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgRubricNotFound", map[string]interface{}{
+				"Name": parsed.Rubric,
+			}, nil), true)
+		}
+	}
+	if err := m.repo.SetSuggestionRubric(ctx, suggestionID, parsed.Rubric); err != nil {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("failed to save rubric of suggestion %s: %w", suggestionID.Hex(), err)
+	}
+
+	m.reviewSessionsMutex.Lock()
+	var suggestion models.Suggestion
+	if parsed.Index < len(session.Suggestions) && session.Suggestions[parsed.Index].ID == suggestionID {
+		session.Suggestions[parsed.Index].Rubric = parsed.Rubric
+		suggestion = session.Suggestions[parsed.Index]
+	}
+	total := len(session.Suggestions)
+	chatID, messageID := session.ReviewChatID, session.CurrentControlMessageID
+	m.reviewSessionsMutex.Unlock()
+	log.Printf("[RubricCallback] Admin %d set rubric of suggestion %s to %q", adminID, suggestionID.Hex(), parsed.Rubric)
+
+	_ = m.answerCallbackQuery(ctx, query.ID, "", false)
+	if _, err := m.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+		ChatID:      tu.ID(chatID),
+		MessageID:   messageID,
+		ReplyMarkup: m.reviewKeyboard(localizer, &suggestion, parsed.Index, total),
+	}); err != nil {
+		log.Printf("[RubricCallback] Error updating review buttons for suggestion %s: %v", suggestionID.Hex(), err)
+	}
+	return nil
+}
+
+// rubricCaption takes the next number of the suggestion's rubric and returns the rubric caption,
+// or "" if the suggestion has no rubric or the rubric was deleted.
+func (m *Manager) rubricCaption(ctx context.Context, suggestion *models.Suggestion) (string, error) {
+	if suggestion.Rubric == "" || m.rubricRepo == nil {
+		return "", nil
+	}
+	rubric, err := m.rubricRepo.NextRubricNumber(ctx, suggestion.Rubric)
+	if errors.Is(err, database.ErrRubricNotFound) {
+		log.Printf("[publishSuggestion] Rubric %s of suggestion %s was deleted, publishing without it", suggestion.Rubric, suggestion.ID.Hex())
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to number suggestion %s in rubric %s: %w", suggestion.ID.Hex(), suggestion.Rubric, err)
+	}
+	return rubric.Caption(rubric.Counter), nil
+}
@@ -0,0 +1,258 @@
+package suggestions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// Rule names recorded as the rejection reason of auto-rejected suggestions.
+const (
+	RuleCaptionPattern  = "caption_pattern"
+	RuleMaxMediaCount   = "max_media_count"
+	RuleMinAccountAge   = "min_account_age"
+	RuleMinSubscription = "min_subscription_age"
+	RuleWordFilter      = "word_filter"
+)
+
+// AutoRejectRules are checks evaluated when a suggestion is submitted.
+// A suggestion breaking any rule is rejected immediately instead of entering the review queue.
+// Zero values disable the corresponding rule.
+type AutoRejectRules struct {
+	CaptionPattern     *regexp.Regexp // Reject if the caption matches
+	MaxMediaCount      int            // Reject if the suggestion has more files than this
+	MinAccountAge      time.Duration  // Reject if the bot first saw the user more recently than this
+	MinSubscriptionAge time.Duration  // Reject if the user joined the channel more recently than this
+}
+
+// ruleViolation describes which rule rejected a suggestion and how to explain it to the user.
+type ruleViolation struct {
+	Rule      string
+	MessageID string
+	Count     int // Plural count for the message (0 if the message is not pluralized)
+}
+
+// Rules /autoreject can change, see AutoRejectRules.With.
+const (
+	AutoRejectRuleCaption      = "caption"
+	AutoRejectRuleMedia        = "media"
+	AutoRejectRuleAccount      = "account"
+	AutoRejectRuleSubscription = "subscription"
+)
+
+// autoRejectRulesOff disables a rule in AutoRejectRules.With.
+const autoRejectRulesOff = "off"
+
+// autoRejectSetting is how AutoRejectRules are stored as a setting.
+type autoRejectSetting struct {
+	CaptionPattern      string `json:"caption_regex,omitempty"`
+	MaxMediaCount       int    `json:"max_media,omitempty"`
+	MinAccountAgeDays   int    `json:"min_account_age_days,omitempty"`
+	MinSubscriptionDays int    `json:"min_subscription_days,omitempty"`
+}
+
+// ParseAutoRejectRules parses rules stored with AutoRejectRules.String.
+func ParseAutoRejectRules(value string) (AutoRejectRules, error) {
+	var setting autoRejectSetting
+	if err := json.Unmarshal([]byte(value), &setting); err != nil {
+		return AutoRejectRules{}, fmt.Errorf("invalid auto-reject rules: %w", err)
+	}
+	rules := AutoRejectRules{
+		MaxMediaCount:      setting.MaxMediaCount,
+		MinAccountAge:      time.Duration(setting.MinAccountAgeDays) * 24 * time.Hour,
+		MinSubscriptionAge: time.Duration(setting.MinSubscriptionDays) * 24 * time.Hour,
+	}
+	if setting.CaptionPattern != "" {
+		pattern, err := regexp.Compile(setting.CaptionPattern)
+		if err != nil {
+			return AutoRejectRules{}, fmt.Errorf("invalid auto-reject caption pattern: %w", err)
+		}
+		rules.CaptionPattern = pattern
+	}
+	return rules, nil
+}
+
+// String formats the rules as JSON, to be stored as a setting.
+func (r AutoRejectRules) String() string {
+	setting := autoRejectSetting{
+		MaxMediaCount:       r.MaxMediaCount,
+		MinAccountAgeDays:   durationDays(r.MinAccountAge),
+		MinSubscriptionDays: durationDays(r.MinSubscriptionAge),
+	}
+	if r.CaptionPattern != nil {
+		setting.CaptionPattern = r.CaptionPattern.String()
+	}
+	data, _ := json.Marshal(setting) // Can't fail for plain strings and ints
+	return string(data)
+}
+
+// Empty reports whether no rule is enabled.
+func (r AutoRejectRules) Empty() bool {
+	return r.CaptionPattern == nil && r.MaxMediaCount <= 0 && r.MinAccountAge <= 0 && r.MinSubscriptionAge <= 0
+}
+
+// With returns the rules with one rule changed: a regular expression for AutoRejectRuleCaption and
+// a whole number of files or days for the others. "off" disables the rule.
+func (r AutoRejectRules) With(rule, value string) (AutoRejectRules, error) {
+	value = strings.TrimSpace(value)
+	off := strings.EqualFold(value, autoRejectRulesOff)
+	if rule == AutoRejectRuleCaption {
+		if off {
+			r.CaptionPattern = nil
+			return r, nil
+		}
+		pattern, err := regexp.Compile(value)
+		if err != nil {
+			return r, fmt.Errorf("invalid caption pattern: %w", err)
+		}
+		r.CaptionPattern = pattern
+		return r, nil
+	}
+
+	n := 0
+	if !off {
+		var err error
+		n, err = strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return r, fmt.Errorf("invalid %s limit %q", rule, value)
+		}
+	}
+	switch rule {
+	case AutoRejectRuleMedia:
+		r.MaxMediaCount = n
+	case AutoRejectRuleAccount:
+		r.MinAccountAge = time.Duration(n) * 24 * time.Hour
+	case AutoRejectRuleSubscription:
+		r.MinSubscriptionAge = time.Duration(n) * 24 * time.Hour
+	default:
+		return r, fmt.Errorf("unknown auto-reject rule %q", rule)
+	}
+	return r, nil
+}
+
+// Describe lists the enabled rules, one per line, or returns "" if none are.
+func (r AutoRejectRules) Describe(localizer *i18n.Localizer) string {
+	var lines []string
+	if r.CaptionPattern != nil {
+		lines = append(lines, locales.GetMessage(localizer, "MsgAutoRejectRuleCaption", map[string]interface{}{
+			"Pattern": r.CaptionPattern.String(),
+		}, nil))
+	}
+	if r.MaxMediaCount > 0 {
+		lines = append(lines, locales.GetPluralMessage(localizer, "MsgAutoRejectRuleMedia", r.MaxMediaCount, nil))
+	}
+	if r.MinAccountAge > 0 {
+		lines = append(lines, locales.GetPluralMessage(localizer, "MsgAutoRejectRuleAccount", durationDays(r.MinAccountAge), nil))
+	}
+	if r.MinSubscriptionAge > 0 {
+		lines = append(lines, locales.GetPluralMessage(localizer, "MsgAutoRejectRuleSubscription", durationDays(r.MinSubscriptionAge), nil))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetAutoRejectRules configures the auto-reject rules applied on submission.
+func (m *Manager) SetAutoRejectRules(rules AutoRejectRules) {
+	m.autoRejectRules = rules
+}
+
+// AutoRejectRules returns the auto-reject rules applied on submission.
+func (m *Manager) AutoRejectRules() AutoRejectRules {
+	return m.autoRejectRules
+}
+
+// evaluateAutoRejectRules returns the first rule the suggestion breaks, or nil.
+// Rules whose data can't be determined (e.g. no stored join date) are skipped
+// rather than rejecting the suggestion.
+func (m *Manager) evaluateAutoRejectRules(ctx context.Context, suggestion *models.Suggestion) *ruleViolation {
+	rules := m.autoRejectRules
+
+	if rules.CaptionPattern != nil && suggestion.Caption != "" && rules.CaptionPattern.MatchString(suggestion.Caption) {
+		return &ruleViolation{Rule: RuleCaptionPattern, MessageID: "MsgAutoRejectCaption"}
+	}
+
+	if rules.MaxMediaCount > 0 && len(suggestion.FileIDs) > rules.MaxMediaCount {
+		return &ruleViolation{Rule: RuleMaxMediaCount, MessageID: "MsgAutoRejectTooManyMedia", Count: rules.MaxMediaCount}
+	}
+
+	// The Bot API doesn't expose account creation dates, so the first time the bot saw the user is used instead
+	if rules.MinAccountAge > 0 {
+		firstSeen, err := m.userRepo.GetUserFirstSeen(ctx, suggestion.SuggesterID)
+		if err != nil {
+			log.Printf("[AutoReject User:%d] Skipping account age rule: %v", suggestion.SuggesterID, err)
+		} else if !firstSeen.IsZero() && time.Since(firstSeen) < rules.MinAccountAge {
+			return &ruleViolation{Rule: RuleMinAccountAge, MessageID: "MsgAutoRejectAccountTooNew", Count: durationDays(rules.MinAccountAge)}
+		}
+	}
+
+	if rules.MinSubscriptionAge > 0 {
+		member, err := m.membershipRepo.GetMemberStatus(ctx, m.targetChannelID, suggestion.SuggesterID)
+		if err != nil {
+			log.Printf("[AutoReject User:%d] Skipping subscription age rule: %v", suggestion.SuggesterID, err)
+		} else if member != nil && !member.JoinedAt.IsZero() && time.Since(member.JoinedAt) < rules.MinSubscriptionAge {
+			return &ruleViolation{Rule: RuleMinSubscription, MessageID: "MsgAutoRejectSubscriptionTooRecent", Count: durationDays(rules.MinSubscriptionAge)}
+		}
+	}
+
+	return nil
+}
+
+// applyAutoRejectRules evaluates the auto-reject rules for a new suggestion.
+// If a rule is broken, the suggestion is stored as rejected (with the rule as the reason, for auditing)
+// and the user is told why. It reports whether the suggestion was rejected.
+func (m *Manager) applyAutoRejectRules(ctx context.Context, localizer *i18n.Localizer, suggestion *models.Suggestion) (bool, error) {
+	violation := m.screenCaption(ctx, suggestion)
+	if violation == nil {
+		violation = m.evaluateAutoRejectRules(ctx, suggestion)
+	}
+	if violation == nil {
+		return false, nil
+	}
+	log.Printf("[AutoReject User:%d] Suggestion rejected by rule %s", suggestion.SuggesterID, violation.Rule)
+
+	now := time.Now()
+	suggestion.Status = string(StatusRejected)
+	suggestion.RejectionReason = violation.Rule
+	suggestion.ReviewedAt = now
+	suggestion.History = append(suggestion.History, models.StatusChange{
+		From:   string(StatusPending),
+		To:     string(StatusRejected),
+		At:     now,
+		Reason: violation.Rule,
+	})
+	var storeErr error
+	if err := m.createSuggestion(ctx, suggestion); err != nil {
+		storeErr = fmt.Errorf("failed to store auto-rejected suggestion: %w", err)
+	} else {
+		m.countUserActivity(ctx, suggestion.SuggesterID, models.UserCounterSuggestions)
+	}
+
+	var text string
+	if violation.Count > 0 {
+		text = locales.GetPluralMessage(localizer, violation.MessageID, violation.Count, nil)
+	} else {
+		text = locales.GetMessage(localizer, violation.MessageID, nil, nil)
+	}
+	if storeErr == nil {
+		text += "\n" + refCodeText(localizer, suggestion)
+	}
+	if _, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(suggestion.ChatID), text)); err != nil {
+		log.Printf("[AutoReject User:%d] Error sending rejection message: %v", suggestion.SuggesterID, err)
+	}
+	return true, storeErr
+}
+
+// durationDays rounds a duration up to whole days for display.
+func durationDays(d time.Duration) int {
+	const day = 24 * time.Hour
+	return int((d + day - 1) / day)
+}
@@ -0,0 +1,45 @@
+package suggestions
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+)
+
+const (
+	// suggestionSourceKeyPrefix starts the cache keys of the deep-link sources users came from.
+	suggestionSourceKeyPrefix = "suggestion_source:"
+	// suggestionSourceTTL is how long a suggestion is attributed to the link the user opened.
+	suggestionSourceTTL = time.Hour
+)
+
+// suggestionSourceKey returns the cache key of the source a user came from.
+func suggestionSourceKey(userID int64) string {
+	return suggestionSourceKeyPrefix + strconv.FormatInt(userID, 10)
+}
+
+// RememberSuggestionSource records that a user opened the bot from a "Suggest a meme" deep link,
+// so the suggestion they send within the next hour is attributed to that source.
+func (m *Manager) RememberSuggestionSource(ctx context.Context, userID int64, source string) {
+	if err := m.cache.Set(ctx, suggestionSourceKey(userID), []byte(source), suggestionSourceTTL); err != nil {
+		log.Printf("[SuggestionSource User:%d] Error remembering source %q: %v", userID, source, err)
+	}
+}
+
+// suggestionSource returns the deep-link source a user came from and forgets it, so only their
+// next suggestion is attributed to it. It returns "" if they didn't come from a link.
+func (m *Manager) suggestionSource(ctx context.Context, userID int64) string {
+	value, ok, err := m.cache.Get(ctx, suggestionSourceKey(userID))
+	if err != nil {
+		log.Printf("[SuggestionSource User:%d] Error reading source: %v", userID, err)
+		return ""
+	}
+	if !ok {
+		return ""
+	}
+	if err := m.cache.Delete(ctx, suggestionSourceKey(userID)); err != nil {
+		log.Printf("[SuggestionSource User:%d] Error forgetting source: %v", userID, err)
+	}
+	return string(value)
+}
@@ -0,0 +1,225 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/format"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/pkg/utils"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// rejectionReasonMessages maps auto-reject rules, and merging as a duplicate, to the reason shown by /status.
+var rejectionReasonMessages = map[string]string{
+	RuleCaptionPattern:              "MsgStatusReasonCaption",
+	RuleMaxMediaCount:               "MsgStatusReasonTooManyMedia",
+	RuleMinAccountAge:               "MsgStatusReasonAccountTooNew",
+	RuleMinSubscription:             "MsgStatusReasonSubscriptionTooRecent",
+	models.RejectionReasonDuplicate: "MsgStatusReasonDuplicate",
+	models.RejectionReasonExpired:   "MsgStatusReasonExpired",
+}
+
+// SetPostLogger sets where published suggestions are logged along with the other channel posts,
+// so /stats sources can tell community content from the admins' own posts.
+func (m *Manager) SetPostLogger(logger database.PostLogger) {
+	m.postLogger = logger
+}
+
+// recordPublishedPost stores the first channel message of a published suggestion, so /status can
+// link to the post, and logs the post. Errors are only logged: the post is out either way.
+func (m *Manager) recordPublishedPost(ctx context.Context, suggestion *models.Suggestion, messageIDs []int) {
+	if len(messageIDs) == 0 {
+		return
+	}
+	if err := m.repo.SetSuggestionPublished(ctx, suggestion.ID, messageIDs[0]); err != nil {
+		log.Printf("[publishSuggestion] Error recording the post of suggestion %s: %v", suggestion.ID.Hex(), err)
+	}
+	m.engagement.PromptPost(ctx, messageIDs[0], suggestion.Rubric)
+	if m.postLogger == nil {
+		return
+	}
+	logEntry := models.PostLog{
+		SenderID:       suggestion.SuggesterID,
+		SenderUsername: suggestion.Username,
+		Caption:        chosenCaption(suggestion),
+		MessageType:    "media_group",
+		ReceivedAt:     suggestion.SubmittedAt,
+		PublishedAt:    time.Now(),
+		ChannelID:      m.targetChannelID,
+		ChannelPostID:  messageIDs[0],
+		ProtectContent: m.protected(suggestion),
+		Origin:         models.PostOriginSuggestion,
+		SuggestionID:   suggestion.ID,
+	}
+	if len(suggestion.FileIDs) == 1 {
+		logEntry.MessageType, logEntry.FileID = suggestion.MediaType(0), suggestion.FileIDs[0]
+	}
+	if err := m.postLogger.LogPublishedPost(logEntry); err != nil {
+		log.Printf("[publishSuggestion] Failed attempt to log the post of suggestion %s to DB. Error: %v", suggestion.ID.Hex(), err)
+	}
+}
+
+// HandleSuggestionStatusCommand tells a suggester the status of one of their suggestions, looked
+// up by the reference code they were given on submission. Only the suggester's own suggestions
+// are shown, so codes don't reveal anything about other users' submissions.
+func (m *Manager) HandleSuggestionStatusCommand(ctx context.Context, update telego.Update, code string) error {
+	if update.Message == nil || update.Message.From == nil {
+		return fmt.Errorf("invalid update received for status command")
+	}
+	chatID := update.Message.Chat.ID
+	userID := update.Message.From.ID
+	localizer := m.localizerForUser(ctx, update.Message.From)
+	code = models.NormalizeRefCode(code)
+
+	suggestion, err := m.repo.GetSuggestionByRefCode(ctx, code)
+	if err == nil && suggestion.SuggesterID != userID {
+		err = database.ErrSuggestionNotFound
+	}
+	if err != nil {
+		if errors.Is(err, database.ErrSuggestionNotFound) {
+			msg := locales.GetMessage(localizer, "MsgSuggestionStatusNotFound", map[string]interface{}{"Code": code}, nil)
+			_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), msg))
+			return err
+		}
+		errorMsg := locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil)
+		_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), errorMsg))
+		return fmt.Errorf("failed to look up suggestion %s of user %d: %w", code, userID, err)
+	}
+	log.Printf("[Cmd:status User:%d] Suggestion %s (%s) is %s", userID, code, suggestion.ID.Hex(), suggestion.Status)
+
+	_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), m.suggestionStatusText(localizer, suggestion)))
+	return err
+}
+
+// suggestionStatusText describes the status of a suggestion to its suggester.
+func (m *Manager) suggestionStatusText(localizer *i18n.Localizer, suggestion *models.Suggestion) string {
+	data := map[string]interface{}{
+		"Code": suggestion.RefCode,
+		"Ago":  format.RelativeTime(localizer, suggestion.SubmittedAt, time.Now()),
+	}
+	switch suggestion.Status {
+	case string(models.StatusPending):
+		if m.isSuggestionClaimed(suggestion.ID) {
+			return locales.GetMessage(localizer, "MsgSuggestionStatusInReview", data, nil)
+		}
+		return locales.GetMessage(localizer, "MsgSuggestionStatusPending", data, nil)
+	case string(models.StatusApproved):
+		if link := channelPostLink(m.targetChannelID, suggestion.PublishedMessageID); link != "" {
+			data["Link"] = link
+			return locales.GetMessage(localizer, "MsgSuggestionStatusPublished", data, nil)
+		}
+		return locales.GetMessage(localizer, "MsgSuggestionStatusApproved", data, nil)
+	case string(models.StatusRejected):
+		if reasonID, ok := rejectionReasonMessages[suggestion.RejectionReason]; ok {
+			data["Reason"] = locales.GetMessage(localizer, reasonID, nil, nil)
+			return locales.GetMessage(localizer, "MsgSuggestionStatusRejectedReason", data, nil)
+		}
+		return locales.GetMessage(localizer, "MsgSuggestionStatusRejected", data, nil)
+	default:
+		return locales.GetMessage(localizer, "MsgSuggestionStatusPending", data, nil)
+	}
+}
+
+// channelPostLink returns a t.me link to a post in a channel, or "" if the post is unknown.
+// The link works for members of the channel, private channels included.
+func channelPostLink(channelID int64, messageID int) string {
+	if messageID == 0 {
+		return ""
+	}
+	internalID := strings.TrimPrefix(strconv.FormatInt(channelID, 10), "-100")
+	return fmt.Sprintf("https://t.me/c/%s/%d", internalID, messageID)
+}
+
+// findSearchLimit bounds how many suggestions /find lists when searching by text.
+const findSearchLimit = 10
+
+// HandleFindCommand shows an admin a suggestion looked up by its reference code: its status,
+// suggester and reviewer, with a Re-open button if it was rejected. Anything that isn't a known code is searched for in the captions and
+// recognized text of suggestions instead. The caller checks that the user is an admin.
+func (m *Manager) HandleFindCommand(ctx context.Context, update telego.Update, query string) error {
+	if update.Message == nil || update.Message.From == nil {
+		return fmt.Errorf("invalid update received for find command")
+	}
+	chatID := update.Message.Chat.ID
+	adminID := update.Message.From.ID
+	localizer := m.localizerForUser(ctx, update.Message.From)
+	query = strings.TrimSpace(query)
+	code := models.NormalizeRefCode(query)
+
+	suggestion, err := m.repo.GetSuggestionByRefCode(ctx, code)
+	if !models.IsRefCode(code) || errors.Is(err, database.ErrSuggestionNotFound) {
+		return m.searchSuggestions(ctx, localizer, chatID, adminID, query)
+	}
+	if err != nil {
+		errorMsg := locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil)
+		_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), errorMsg))
+		return fmt.Errorf("failed to look up suggestion %s: %w", code, err)
+	}
+	log.Printf("[Cmd:find Admin:%d] Looked up suggestion %s (%s)", adminID, code, suggestion.ID.Hex())
+
+	var text strings.Builder
+	text.WriteString(m.suggestionStatusText(localizer, suggestion))
+	suggester := suggestion.FirstName
+	if suggestion.Username != "" {
+		suggester = "@" + suggestion.Username
+	}
+	text.WriteString("\n\n")
+	text.WriteString(locales.GetMessage(localizer, "MsgFindSuggester", map[string]interface{}{
+		"Suggester": suggester,
+		"UserID":    suggestion.SuggesterID,
+		"Date":      utils.FormatTime(suggestion.SubmittedAt, m.location),
+	}, nil))
+	if suggestion.ReviewedBy != 0 {
+		text.WriteString("\n")
+		text.WriteString(locales.GetMessage(localizer, "MsgFindReviewer", map[string]interface{}{
+			"AdminID": suggestion.ReviewedBy,
+			"Date":    utils.FormatTime(suggestion.ReviewedAt, m.location),
+		}, nil))
+	} else if suggestion.AssignedTo != 0 {
+		text.WriteString("\n")
+		text.WriteString(locales.GetMessage(localizer, "MsgFindAssignee", map[string]interface{}{
+			"AdminID": suggestion.AssignedTo,
+		}, nil))
+	}
+	msg := tu.Message(tu.ID(chatID), text.String())
+	if keyboard := reopenKeyboard(localizer, suggestion); keyboard != nil {
+		msg = msg.WithReplyMarkup(keyboard)
+	}
+	_, err = m.bot.SendMessage(ctx, msg)
+	return err
+}
+
+// searchSuggestions lists the most recent suggestions whose caption or recognized text contains query.
+func (m *Manager) searchSuggestions(ctx context.Context, localizer *i18n.Localizer, chatID, adminID int64, query string) error {
+	found, err := m.repo.SearchSuggestions(ctx, query, findSearchLimit)
+	if err != nil {
+		errorMsg := locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil)
+		_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), errorMsg))
+		return fmt.Errorf("failed to search suggestions for %q: %w", query, err)
+	}
+	log.Printf("[Cmd:find Admin:%d] Searched suggestions for %q: %d found", adminID, query, len(found))
+	if len(found) == 0 {
+		msg := locales.GetMessage(localizer, "MsgFindNoMatches", map[string]interface{}{"Query": query}, nil)
+		_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), msg))
+		return err
+	}
+
+	var text strings.Builder
+	text.WriteString(locales.GetMessage(localizer, "MsgFindSearchHeader", map[string]interface{}{"Query": query}, nil))
+	for i := range found {
+		text.WriteString("\n")
+		text.WriteString(m.suggestionStatusText(localizer, &found[i]))
+	}
+	_, err = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), text.String()))
+	return err
+}
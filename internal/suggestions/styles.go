@@ -0,0 +1,235 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"vrcmemes-bot/internal/customemoji"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/footers"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// styleCallbackPrefix starts the callback data of the caption style picker buttons: style:<id>:<index>:<name>.
+// An empty name publishes the suggestion without a style.
+const styleCallbackPrefix = "style:"
+
+// styleCallback is the parsed callback data of a caption style picker button.
+type styleCallback struct {
+	Ref   string // Reference of the suggestion, see models.Suggestion.Ref
+	Index int    // Position of the suggestion in the admin's review batch
+	Style string // Picked style, or "" for none
+}
+
+// String formats the callback data for an inline button.
+func (c styleCallback) String() string {
+	return fmt.Sprintf("%s%s:%d:%s", styleCallbackPrefix, c.Ref, c.Index, c.Style)
+}
+
+// errStyleCallbackMalformed is returned by parseStyleCallback for data not produced by styleCallback.String.
+var errStyleCallbackMalformed = errors.New("malformed style callback data")
+
+// parseStyleCallback parses caption style picker callback data.
+func parseStyleCallback(data string) (styleCallback, error) {
+	parts := strings.SplitN(strings.TrimPrefix(data, styleCallbackPrefix), ":", 3)
+	if !strings.HasPrefix(data, styleCallbackPrefix) || len(data) > maxCallbackDataLength || len(parts) != 3 {
+		return styleCallback{}, fmt.Errorf("%w: %q", errStyleCallbackMalformed, data)
+	}
+	if !isSuggestionRef(parts[0]) {
+		return styleCallback{}, fmt.Errorf("%w: invalid suggestion reference %q", errStyleCallbackMalformed, parts[0])
+	}
+	index, err := strconv.Atoi(parts[1])
+	if err != nil || index < 0 {
+		return styleCallback{}, fmt.Errorf("%w: invalid index %q", errStyleCallbackMalformed, parts[1])
+	}
+	return styleCallback{Ref: parts[0], Index: index, Style: parts[2]}, nil
+}
+
+// captionStyles returns the caption styles defined with /style.
+func (m *Manager) captionStyles(ctx context.Context) ([]models.CaptionStyle, error) {
+	setting, err := m.settingsRepo.GetSetting(ctx, models.SettingCaptionStyles)
+	if err != nil {
+		return nil, err
+	}
+	return models.DecodeCaptionStyles(setting)
+}
+
+// stylePickerKeyboard builds the caption style picker for a suggestion: a button per style, the
+// current one marked, and a button to publish without a style.
+func stylePickerKeyboard(localizer *i18n.Localizer, suggestion *models.Suggestion, index int, styles []models.CaptionStyle) *telego.InlineKeyboardMarkup {
+	var rows [][]telego.InlineKeyboardButton
+	for _, style := range styles {
+		text := style.Name
+		if style.Name == suggestion.Style {
+			text = "✅ " + text
+		}
+		data := styleCallback{Ref: suggestion.Ref(), Index: index, Style: style.Name}.String()
+		rows = append(rows, tu.InlineKeyboardRow(tu.InlineKeyboardButton(text).WithCallbackData(data)))
+	}
+	noneData := styleCallback{Ref: suggestion.Ref(), Index: index}.String()
+	rows = append(rows, tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnStyleNone", nil, nil)).WithCallbackData(noneData),
+	))
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// showStylePicker replaces the review buttons of the current suggestion with the caption style picker.
+// If no styles are defined, the admin is told how to add one instead.
+func (m *Manager) showStylePicker(ctx context.Context, queryID string, adminID int64, session *ReviewSession, index int) error {
+	localizer := m.localizerForUserID(ctx, adminID)
+	styles, err := m.captionStyles(ctx)
+	if err != nil {
+		_ = m.answerCallbackQuery(ctx, queryID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("failed to list caption styles: %w", err)
+	}
+	if len(styles) == 0 {
+		return m.answerCallbackQuery(ctx, queryID, locales.GetMessage(localizer, "MsgStylesNone", nil, nil), true)
+	}
+
+	m.reviewSessionsMutex.RLock()
+	suggestion := session.Suggestions[index]
+	chatID, messageID := session.ReviewChatID, session.CurrentControlMessageID
+	m.reviewSessionsMutex.RUnlock()
+
+	_ = m.answerCallbackQuery(ctx, queryID, "", false)
+	_, err = m.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+		ChatID:      tu.ID(chatID),
+		MessageID:   messageID,
+		ReplyMarkup: stylePickerKeyboard(localizer, &suggestion, index, styles),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to show style picker for suggestion %s: %w", suggestion.ID.Hex(), err)
+	}
+	return nil
+}
+
+// handleStyleCallback handles the caption style picker buttons: the picked style is saved and the
+// review buttons are shown again.
+func (m *Manager) handleStyleCallback(ctx context.Context, query telego.CallbackQuery) error {
+	adminID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	parsed, err := parseStyleCallback(query.Data)
+	if err != nil {
+		log.Printf("[StyleCallback] Rejected callback data %q: %v", query.Data, err)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return err
+	}
+
+	isAdmin, err := m.adminChecker.IsAdmin(ctx, adminID)
+	if err != nil || !isAdmin {
+		log.Printf("[StyleCallback] User %d is not admin (err: %v), ignoring style action.", adminID, err)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil), true)
+		return nil
+	}
+
+	session, ok := m.activeReviewSession(adminID)
+	m.reviewSessionsMutex.RLock()
+	valid := ok && parsed.Index < len(session.Suggestions) && session.Suggestions[parsed.Index].Ref() == parsed.Ref
+	var suggestionID primitive.ObjectID
+	if valid {
+		suggestionID = session.Suggestions[parsed.Index].ID
+	}
+	m.reviewSessionsMutex.RUnlock()
+	if !valid || m.settingsRepo == nil {
+		log.Printf("[StyleCallback] Invalid session or suggestion mismatch for admin %d, index %d, ref %s", adminID, parsed.Index, parsed.Ref)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgReviewSessionExpired", nil, nil), true)
+		return nil
+	}
+	m.touchReviewSession(adminID)
+
+	if parsed.Style != "" {
+		styles, err := m.captionStyles(ctx)
+		if err != nil {
+			_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+			return fmt.Errorf("failed to list caption styles: %w", err)
+		}
+		if _, ok := models.FindCaptionStyle(styles, parsed.Style); !ok {
+			return m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgStyleNotFound", map[string]interface{}{
+				"Name": parsed.Style,
+			}, nil), true)
+		}
+	}
+	if err := m.repo.SetSuggestionStyle(ctx, suggestionID, parsed.Style); err != nil {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("failed to save style of suggestion %s: %w", suggestionID.Hex(), err)
+	}
+
+	m.reviewSessionsMutex.Lock()
+	var suggestion models.Suggestion
+	if parsed.Index < len(session.Suggestions) && session.Suggestions[parsed.Index].ID == suggestionID {
+		session.Suggestions[parsed.Index].Style = parsed.Style
+		suggestion = session.Suggestions[parsed.Index]
+	}
+	total := len(session.Suggestions)
+	chatID, messageID := session.ReviewChatID, session.CurrentControlMessageID
+	m.reviewSessionsMutex.Unlock()
+	log.Printf("[StyleCallback] Admin %d set style of suggestion %s to %q", adminID, suggestionID.Hex(), parsed.Style)
+
+	_ = m.answerCallbackQuery(ctx, query.ID, "", false)
+	if _, err := m.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+		ChatID:      tu.ID(chatID),
+		MessageID:   messageID,
+		ReplyMarkup: m.reviewKeyboard(localizer, &suggestion, parsed.Index, total),
+	}); err != nil {
+		log.Printf("[StyleCallback] Error updating review buttons for suggestion %s: %v", suggestionID.Hex(), err)
+	}
+	return nil
+}
+
+// styledCaption decorates the published caption with the suggestion's caption style. If the style
+// was deleted or the styles can't be loaded, the caption is published undecorated.
+func (m *Manager) styledCaption(ctx context.Context, suggestion *models.Suggestion, caption string) string {
+	if suggestion.Style == "" || m.settingsRepo == nil {
+		return caption
+	}
+	styles, err := m.captionStyles(ctx)
+	if err != nil {
+		log.Printf("[publishSuggestion] Error loading caption styles for suggestion %s, publishing without style: %v", suggestion.ID.Hex(), err)
+		return caption
+	}
+	style, ok := models.FindCaptionStyle(styles, suggestion.Style)
+	if !ok {
+		log.Printf("[publishSuggestion] Caption style %s of suggestion %s was deleted, publishing without it", suggestion.Style, suggestion.ID.Hex())
+		return caption
+	}
+	return style.Apply(caption)
+}
+
+// SetFooterRotator sets the pool of hashtag footers added under the captions of approved
+// suggestions, one after the other. Without it, captions get no footer.
+func (m *Manager) SetFooterRotator(rotator *footers.Rotator) {
+	m.footerRotator = rotator
+}
+
+// SetCustomEmoji sets whether the custom emoji of caption styles are kept in published captions.
+// Otherwise they are published as the regular emoji they stand for, see
+// handlers.MessageHandler.SetCustomEmoji.
+func (m *Manager) SetCustomEmoji(enabled bool) {
+	m.customEmoji = enabled
+}
+
+// styleEntities returns the custom emoji entities of the suggestion's caption style in caption,
+// its published caption. There are none if custom emoji are disabled.
+func (m *Manager) styleEntities(ctx context.Context, suggestion *models.Suggestion, caption string) []telego.MessageEntity {
+	if !m.customEmoji || suggestion.Style == "" || m.settingsRepo == nil {
+		return nil
+	}
+	styles, err := m.captionStyles(ctx)
+	if err != nil {
+		return nil // styledCaption has logged it
+	}
+	style, ok := models.FindCaptionStyle(styles, suggestion.Style)
+	if !ok {
+		return nil
+	}
+	return customemoji.Entities(customemoji.Styled(style, caption))
+}
@@ -0,0 +1,204 @@
+package suggestions
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// submitCallbackPrefix prefixes callback data of the Confirm and Cancel buttons under the
+	// preview of a new suggestion.
+	submitCallbackPrefix = "submit:"
+	submitConfirmData    = submitCallbackPrefix + "confirm"
+	submitCancelData     = submitCallbackPrefix + "cancel"
+	// pendingSubmissionTTL is how long a previewed suggestion waits for the suggester to confirm it.
+	pendingSubmissionTTL = time.Hour
+)
+
+// confirmedSubmissionKey marks contexts of suggestions the user already confirmed, such as
+// direct suggestions, which aren't previewed again.
+type confirmedSubmissionKey struct{}
+
+// withConfirmedSubmission returns a context in which new suggestions are submitted without preview.
+func withConfirmedSubmission(ctx context.Context) context.Context {
+	return context.WithValue(ctx, confirmedSubmissionKey{}, true)
+}
+
+// submissionConfirmed reports whether the user already confirmed the suggestion being processed.
+func submissionConfirmed(ctx context.Context) bool {
+	confirmed, _ := ctx.Value(confirmedSubmissionKey{}).(bool)
+	return confirmed
+}
+
+// pendingSubmission is a new suggestion shown to its suggester, not stored until they confirm it.
+type pendingSubmission struct {
+	suggestion      *models.Suggestion
+	mediaMessageIDs []int // Preview of the media
+	promptMessageID int   // Message with the Confirm and Cancel buttons
+	createdAt       time.Time
+}
+
+// SetSuggestionPreview enables showing users their suggestion as it may appear in the channel,
+// with Confirm and Cancel buttons, before it is submitted. This keeps accidental and repeated
+// submissions out of the queue.
+func (m *Manager) SetSuggestionPreview(enabled bool) {
+	m.pendingSubmissionsMutex.Lock()
+	defer m.pendingSubmissionsMutex.Unlock()
+	m.previewSuggestions = enabled
+}
+
+// suggestionPreviewEnabled reports whether new suggestions are previewed before submission.
+func (m *Manager) suggestionPreviewEnabled() bool {
+	m.pendingSubmissionsMutex.Lock()
+	defer m.pendingSubmissionsMutex.Unlock()
+	return m.previewSuggestions
+}
+
+// previewCaption is the caption the suggestion would be published with as it stands: the
+// credits, without the rubric, tags and caption reviewers may still pick.
+func (m *Manager) previewCaption(suggestion *models.Suggestion) string {
+	var parts []string
+	if credit := m.suggesterCredit(suggestion); credit != "" {
+		parts = append(parts, credit)
+	}
+	if m.creditForwardSource && suggestion.ForwardOrigin.IsChannelRepost() {
+		parts = append(parts, forwardSourceCredit(suggestion.ForwardOrigin))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// previewSubmission shows a new suggestion to its suggester as it may appear in the channel and
+// asks them to confirm it. Nothing is stored until they do.
+func (m *Manager) previewSubmission(ctx context.Context, localizer *i18n.Localizer, suggestion *models.Suggestion) error {
+	userID, chatID := suggestion.SuggesterID, suggestion.ChatID
+	pending := &pendingSubmission{suggestion: suggestion, createdAt: time.Now()}
+
+	media := m.createInputMediaFromSuggestion(*suggestion)
+	setMediaCaption(media[0], m.previewCaption(suggestion), "", nil)
+	if len(media) == 1 {
+		sent, err := m.sendMediaMessage(ctx, chatID, media[0], false, nil)
+		if err != nil {
+			return m.failSubmissionPreview(ctx, localizer, suggestion, fmt.Errorf("failed to send suggestion preview: %w", err))
+		}
+		pending.mediaMessageIDs = []int{sent.MessageID}
+	} else {
+		sent, err := m.bot.SendMediaGroup(ctx, tu.MediaGroup(tu.ID(chatID), media...))
+		if err != nil {
+			return m.failSubmissionPreview(ctx, localizer, suggestion, fmt.Errorf("failed to send suggestion preview: %w", err))
+		}
+		for _, msg := range sent {
+			pending.mediaMessageIDs = append(pending.mediaMessageIDs, msg.MessageID)
+		}
+	}
+
+	keyboard := tu.InlineKeyboard(tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnSubmitConfirm", nil, nil)).WithCallbackData(submitConfirmData),
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnSubmitCancel", nil, nil)).WithCallbackData(submitCancelData),
+	))
+	prompt, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgSuggestPreview", nil, nil)).WithReplyMarkup(keyboard))
+	if err != nil {
+		m.deleteReviewMessages(ctx, chatID, pending.mediaMessageIDs, 0)
+		return m.failSubmissionPreview(ctx, localizer, suggestion, fmt.Errorf("failed to send suggestion preview prompt: %w", err))
+	}
+	pending.promptMessageID = prompt.MessageID
+
+	m.pendingSubmissionsMutex.Lock()
+	m.pendingSubmissions[userID] = pending
+	m.pendingSubmissionsMutex.Unlock()
+	m.SetUserState(userID, StateConfirmingSuggestion)
+	log.Printf("[SuggestPreview User:%d] Previewed suggestion with %d photo(s)", userID, len(suggestion.FileIDs))
+	return nil
+}
+
+// failSubmissionPreview tells the suggester their suggestion couldn't be previewed and resets
+// their state.
+func (m *Manager) failSubmissionPreview(ctx context.Context, localizer *i18n.Localizer, suggestion *models.Suggestion, err error) error {
+	m.SetUserState(suggestion.SuggesterID, StateIdle)
+	errorMsg := locales.GetMessage(localizer, "MsgSuggestInternalProcessingError", nil, nil)
+	_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(suggestion.ChatID), errorMsg))
+	return err
+}
+
+// takePendingSubmission removes and returns the user's previewed suggestion if promptMessageID
+// is its prompt and it hasn't expired.
+func (m *Manager) takePendingSubmission(userID int64, promptMessageID int) (*pendingSubmission, bool) {
+	m.pendingSubmissionsMutex.Lock()
+	defer m.pendingSubmissionsMutex.Unlock()
+	pending, ok := m.pendingSubmissions[userID]
+	if !ok || pending.promptMessageID != promptMessageID {
+		return nil, false
+	}
+	delete(m.pendingSubmissions, userID)
+	if time.Since(pending.createdAt) > pendingSubmissionTTL {
+		return nil, false
+	}
+	return pending, true
+}
+
+// hasPendingSubmission reports whether the user has a previewed suggestion to confirm.
+func (m *Manager) hasPendingSubmission(userID int64) bool {
+	m.pendingSubmissionsMutex.Lock()
+	defer m.pendingSubmissionsMutex.Unlock()
+	pending, ok := m.pendingSubmissions[userID]
+	return ok && time.Since(pending.createdAt) <= pendingSubmissionTTL
+}
+
+// dropPendingSubmission forgets the user's previewed suggestion, e.g. when they send a command
+// instead of confirming it.
+func (m *Manager) dropPendingSubmission(userID int64) {
+	m.pendingSubmissionsMutex.Lock()
+	defer m.pendingSubmissionsMutex.Unlock()
+	delete(m.pendingSubmissions, userID)
+}
+
+// handleSubmitCallback handles the Confirm and Cancel buttons under a suggestion preview. Only
+// Confirm submits the suggestion; Cancel removes the preview.
+func (m *Manager) handleSubmitCallback(ctx context.Context, query telego.CallbackQuery) error {
+	userID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	prompt, ok := query.Message.(*telego.Message)
+	if !ok || prompt == nil {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgSuggestPreviewExpired", nil, nil), true)
+		return nil
+	}
+	chatID := prompt.Chat.ID
+	pending, ok := m.takePendingSubmission(userID, prompt.MessageID)
+	if !ok {
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgSuggestPreviewExpired", nil, nil), true)
+		m.deleteReviewMessages(ctx, chatID, nil, prompt.MessageID)
+		return nil
+	}
+	m.SetUserState(userID, StateIdle)
+
+	if query.Data != submitConfirmData {
+		log.Printf("[SuggestPreview User:%d] Suggestion cancelled", userID)
+		_ = m.answerCallbackQuery(ctx, query.ID, "", false)
+		m.deleteReviewMessages(ctx, chatID, pending.mediaMessageIDs, pending.promptMessageID)
+		_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, "MsgSuggestPreviewCancelled", nil, nil)))
+		return err
+	}
+
+	_ = m.answerCallbackQuery(ctx, query.ID, "", false)
+	m.deleteReviewMessages(ctx, chatID, nil, pending.promptMessageID)
+	suggestion := pending.suggestion
+	suggestion.SubmittedAt = time.Now()
+	if err := m.AddSuggestion(ctx, suggestion); err != nil {
+		errorMsg := locales.GetMessage(localizer, suggestionSaveErrorMessage(err), nil, nil)
+		_, _ = m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), errorMsg))
+		return fmt.Errorf("failed to add confirmed suggestion of user %d: %w", userID, err)
+	}
+	if err := m.sendSuggestionReceived(ctx, localizer, chatID, suggestion); err != nil {
+		log.Printf("[SuggestPreview User:%d] Error sending confirmation: %v", userID, err)
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"unicode"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// tagCallbackPrefix starts the callback data of the tag picker buttons: tag:<id>:<index>:<tag index|done>.
+	tagCallbackPrefix = "tag:"
+	// tagDone is the last field of the button that closes the tag picker.
+	tagDone = "done"
+	// maxTags bounds the configured tag list, so the picker stays usable.
+	maxTags = 30
+	// tagPickerColumns is how many tag buttons are shown per row.
+	tagPickerColumns = 3
+)
+
+// SetTags sets the tags reviewers can attach to suggestions. Tags are written without "#";
+// spaces and hyphens become underscores. Tags that can't be hashtags are skipped.
+// With no tags, the tag picker is not shown.
+func (m *Manager) SetTags(tags []string) {
+	m.tags = nil
+	seen := make(map[string]bool)
+	for _, raw := range tags {
+		tag := normalizeTag(raw)
+		if tag == "" {
+			log.Printf("[Tags] Skipping tag %q: hashtags may only contain letters, digits and underscores", raw)
+			continue
+		}
+		if seen[tag] {
+			continue
+		}
+		if len(m.tags) == maxTags {
+			log.Printf("[Tags] Only the first %d tags are used", maxTags)
+			break
+		}
+		seen[tag] = true
+		m.tags = append(m.tags, tag)
+	}
+}
+
+// normalizeTag turns a configured tag into its hashtag form without "#", or returns "" if it can't be one.
+func normalizeTag(tag string) string {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+	tag = strings.NewReplacer(" ", "_", "-", "_").Replace(tag)
+	if tag == "" {
+		return ""
+	}
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return ""
+		}
+	}
+	return strings.ToLower(tag)
+}
+
+// hashtags formats tags as a line of hashtags.
+func hashtags(tags []string) string {
+	parts := make([]string, len(tags))
+	for i, tag := range tags {
+		parts[i] = "#" + tag
+	}
+	return strings.Join(parts, " ")
+}
+
+// tagCallback is the parsed callback data of a tag picker button.
+type tagCallback struct {
+	Ref   string // Reference of the suggestion, see models.Suggestion.Ref
+	Index int    // Position of the suggestion in the admin's review batch
+	Tag   int    // Index of the toggled tag in the configured list, or -1 to close the picker
+}
+
+// String formats the callback data for an inline button.
+func (c tagCallback) String() string {
+	tag := tagDone
+	if c.Tag >= 0 {
+		tag = strconv.Itoa(c.Tag)
+	}
+	return fmt.Sprintf("%s%s:%d:%s", tagCallbackPrefix, c.Ref, c.Index, tag)
+}
+
+// errTagCallbackMalformed is returned by parseTagCallback for data not produced by tagCallback.String.
+var errTagCallbackMalformed = errors.New("malformed tag callback data")
+
+// parseTagCallback parses tag picker callback data.
+func parseTagCallback(data string) (tagCallback, error) {
+	parts := strings.Split(strings.TrimPrefix(data, tagCallbackPrefix), ":")
+	if !strings.HasPrefix(data, tagCallbackPrefix) || len(data) > maxCallbackDataLength || len(parts) != 3 {
+		return tagCallback{}, fmt.Errorf("%w: %q", errTagCallbackMalformed, data)
+	}
+	if !isSuggestionRef(parts[0]) {
+		return tagCallback{}, fmt.Errorf("%w: invalid suggestion reference %q", errTagCallbackMalformed, parts[0])
+	}
+	index, err := strconv.Atoi(parts[1])
+	if err != nil || index < 0 {
+		return tagCallback{}, fmt.Errorf("%w: invalid index %q", errTagCallbackMalformed, parts[1])
+	}
+	tag := -1
+	if parts[2] != tagDone {
+		if tag, err = strconv.Atoi(parts[2]); err != nil || tag < 0 {
+			return tagCallback{}, fmt.Errorf("%w: invalid tag %q", errTagCallbackMalformed, parts[2])
+		}
+	}
+	return tagCallback{Ref: parts[0], Index: index, Tag: tag}, nil
+}
+
+// tagPickerKeyboard builds the tag picker for a suggestion: a toggle button per configured tag,
+// marked when the suggestion has it, and a button back to the review actions.
+func (m *Manager) tagPickerKeyboard(localizer *i18n.Localizer, suggestion *models.Suggestion, index int) *telego.InlineKeyboardMarkup {
+	selected := make(map[string]bool, len(suggestion.Tags))
+	for _, tag := range suggestion.Tags {
+		selected[tag] = true
+	}
+
+	var rows [][]telego.InlineKeyboardButton
+	var row []telego.InlineKeyboardButton
+	for i, tag := range m.tags {
+		text := "#" + tag
+		if selected[tag] {
+			text = "✅ " + text
+		}
+		data := tagCallback{Ref: suggestion.Ref(), Index: index, Tag: i}.String()
+		row = append(row, tu.InlineKeyboardButton(text).WithCallbackData(data))
+		if len(row) == tagPickerColumns {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	doneData := tagCallback{Ref: suggestion.Ref(), Index: index, Tag: -1}.String()
+	rows = append(rows, tu.InlineKeyboardRow(
+		tu.InlineKeyboardButton(locales.GetMessage(localizer, "BtnTagsDone", nil, nil)).WithCallbackData(doneData),
+	))
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// showTagPicker replaces the review buttons of the current suggestion with the tag picker.
+func (m *Manager) showTagPicker(ctx context.Context, queryID string, adminID int64, session *ReviewSession, index int) error {
+	localizer := m.localizerForUserID(ctx, adminID)
+	m.reviewSessionsMutex.RLock()
+	suggestion := session.Suggestions[index]
+	chatID, messageID := session.ReviewChatID, session.CurrentControlMessageID
+	m.reviewSessionsMutex.RUnlock()
+
+	_ = m.answerCallbackQuery(ctx, queryID, "", false)
+	_, err := m.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+		ChatID:      tu.ID(chatID),
+		MessageID:   messageID,
+		ReplyMarkup: m.tagPickerKeyboard(localizer, &suggestion, index),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to show tag picker for suggestion %s: %w", suggestion.ID.Hex(), err)
+	}
+	return nil
+}
+
+// handleTagCallback handles the tag picker buttons: toggling a tag saves it right away,
+// and "Done" brings back the review buttons.
+func (m *Manager) handleTagCallback(ctx context.Context, query telego.CallbackQuery) error {
+	adminID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	parsed, err := parseTagCallback(query.Data)
+	if err != nil {
+		log.Printf("[TagCallback] Rejected callback data %q: %v", query.Data, err)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return err
+	}
+
+	isAdmin, err := m.adminChecker.IsAdmin(ctx, adminID)
+	if err != nil || !isAdmin {
+		log.Printf("[TagCallback] User %d is not admin (err: %v), ignoring tag action.", adminID, err)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorRequiresAdmin", nil, nil), true)
+		return nil
+	}
+
+	session, ok := m.activeReviewSession(adminID)
+	m.reviewSessionsMutex.RLock()
+	valid := ok && parsed.Index < len(session.Suggestions) && session.Suggestions[parsed.Index].Ref() == parsed.Ref &&
+		parsed.Tag < len(m.tags)
+	m.reviewSessionsMutex.RUnlock()
+	if !valid {
+		log.Printf("[TagCallback] Invalid session or suggestion mismatch for admin %d, index %d, ref %s", adminID, parsed.Index, parsed.Ref)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgReviewSessionExpired", nil, nil), true)
+		return nil
+	}
+	m.touchReviewSession(adminID)
+
+	m.reviewSessionsMutex.RLock()
+	suggestion := session.Suggestions[parsed.Index]
+	total := len(session.Suggestions)
+	chatID, messageID := session.ReviewChatID, session.CurrentControlMessageID
+	m.reviewSessionsMutex.RUnlock()
+
+	var keyboard *telego.InlineKeyboardMarkup
+	if parsed.Tag < 0 {
+		keyboard = m.reviewKeyboard(localizer, &suggestion, parsed.Index, total)
+	} else {
+		suggestion.Tags = m.toggleTag(suggestion.Tags, m.tags[parsed.Tag])
+		if err := m.repo.SetSuggestionTags(ctx, suggestion.ID, suggestion.Tags); err != nil {
+			_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+			return fmt.Errorf("failed to save tags of suggestion %s: %w", suggestion.ID.Hex(), err)
+		}
+		m.reviewSessionsMutex.Lock()
+		if parsed.Index < len(session.Suggestions) && session.Suggestions[parsed.Index].ID == suggestion.ID {
+			session.Suggestions[parsed.Index].Tags = suggestion.Tags
+		}
+		m.reviewSessionsMutex.Unlock()
+		log.Printf("[TagCallback] Admin %d set tags of suggestion %s to %v", adminID, suggestion.ID.Hex(), suggestion.Tags)
+		keyboard = m.tagPickerKeyboard(localizer, &suggestion, parsed.Index)
+	}
+
+	_ = m.answerCallbackQuery(ctx, query.ID, "", false)
+	if _, err := m.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+		ChatID:      tu.ID(chatID),
+		MessageID:   messageID,
+		ReplyMarkup: keyboard,
+	}); err != nil {
+		log.Printf("[TagCallback] Error updating review buttons for suggestion %s: %v", suggestion.ID.Hex(), err)
+	}
+	return nil
+}
+
+// toggleTag adds or removes a tag, keeping the tags in the configured order.
+func (m *Manager) toggleTag(tags []string, toggled string) []string {
+	selected := make(map[string]bool, len(tags)+1)
+	for _, tag := range tags {
+		selected[tag] = true
+	}
+	selected[toggled] = !selected[toggled]
+
+	var result []string
+	for _, tag := range m.tags {
+		if selected[tag] {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
@@ -0,0 +1,236 @@
+package suggestions
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/thumbnail"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// thumbnailAuto is what reviewers send to go back to the thumbnail Telegram picks.
+const thumbnailAuto = "auto"
+
+// SetThumbnailMaker enables custom video thumbnails: the review keyboard of suggestions with a
+// video gets a button to choose a frame of the video or an image as its thumbnail. Nil disables
+// them.
+func (m *Manager) SetThumbnailMaker(maker *thumbnail.Maker) {
+	m.thumbnails = maker
+}
+
+// firstVideo returns the position of the first video in the suggestion's files, or -1 if it
+// has none. Custom thumbnails apply to this video.
+func firstVideo(suggestion *models.Suggestion) int {
+	for i := range suggestion.FileIDs {
+		if suggestion.MediaType(i) == models.MediaTypeVideo {
+			return i
+		}
+	}
+	return -1
+}
+
+// thumbnailButton returns the label of the review button choosing the thumbnail, which names the
+// current choice.
+func thumbnailButton(localizer *i18n.Localizer, suggestion *models.Suggestion) string {
+	switch {
+	case suggestion.Thumbnail == nil:
+		return locales.GetMessage(localizer, "BtnThumbnail", nil, nil)
+	case suggestion.Thumbnail.FileID != "":
+		return locales.GetMessage(localizer, "BtnThumbnailImage", nil, nil)
+	default:
+		return locales.GetMessage(localizer, "BtnThumbnailFrame", map[string]interface{}{
+			"Time": thumbnail.FormatTime(time.Duration(suggestion.Thumbnail.At) * time.Second),
+		}, nil)
+	}
+}
+
+// startThumbnailChoice asks the reviewer, in their private chat, for the frame time or image to
+// use as the thumbnail of the video of the suggestion under review.
+func (m *Manager) startThumbnailChoice(ctx context.Context, query telego.CallbackQuery, session *ReviewSession, index int) error {
+	adminID := query.From.ID
+	localizer := m.localizerForUser(ctx, &query.From)
+
+	m.reviewSessionsMutex.RLock()
+	suggestion := session.Suggestions[index]
+	m.reviewSessionsMutex.RUnlock()
+
+	m.setEditTarget(adminID, StateChoosingThumbnail, suggestion.ID)
+	promptID := "MsgThumbnailPromptImageOnly"
+	if m.thumbnails.FramesSupported() {
+		promptID = "MsgThumbnailPrompt"
+	}
+	prompt := locales.GetMessage(localizer, promptID, map[string]interface{}{"Code": suggestion.Ref()}, nil)
+	if _, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(adminID), prompt)); err != nil {
+		// The admin never started a private chat with the bot
+		m.SetUserState(adminID, StateIdle)
+		_ = m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgFeedbackReplyStartBot", nil, nil), true)
+		return fmt.Errorf("failed to send thumbnail prompt to admin %d: %w", adminID, err)
+	}
+	return m.answerCallbackQuery(ctx, query.ID, locales.GetMessage(localizer, "MsgFeedbackReplyPromptSent", nil, nil), false)
+}
+
+// handleThumbnailContent sets the thumbnail a reviewer chose for a suggestion's video: the frame at
+// the time they sent, the image they sent, or the automatic one. Frames and images are made into
+// a thumbnail right away and shown to the reviewer, who is asked again if that fails. Commands
+// cancel the choice and are passed on.
+func (m *Manager) handleThumbnailContent(ctx context.Context, message *telego.Message) (processed bool, err error) {
+	adminID := message.From.ID
+	chatID := message.Chat.ID
+	localizer := m.localizerForUser(ctx, message.From)
+
+	if strings.HasPrefix(message.Text, "/") {
+		m.SetUserState(adminID, StateIdle)
+		return false, nil
+	}
+	suggestionID, ok := m.getEditTarget(adminID)
+	if !ok {
+		m.SetUserState(adminID, StateIdle)
+		return false, nil
+	}
+	reply := func(messageID string) error {
+		_, err := m.bot.SendMessage(ctx, tu.Message(tu.ID(chatID), locales.GetMessage(localizer, messageID, nil, nil)))
+		return err
+	}
+
+	var choice *models.VideoThumbnail
+	switch {
+	case len(message.Photo) > 0:
+		size, ok := thumbnail.PhotoSize(message.Photo)
+		if !ok {
+			return true, reply("MsgThumbnailInvalid") // Let the admin try again
+		}
+		choice = &models.VideoThumbnail{FileID: size.FileID}
+	case strings.EqualFold(strings.TrimSpace(message.Text), thumbnailAuto):
+	default:
+		at, err := thumbnail.ParseTime(message.Text)
+		if err != nil || !m.thumbnails.FramesSupported() {
+			return true, reply("MsgThumbnailInvalid") // Let the admin try again
+		}
+		choice = &models.VideoThumbnail{At: int(at / time.Second)}
+	}
+
+	suggestion, err := m.repo.GetSuggestionByID(ctx, suggestionID)
+	if err != nil || suggestion == nil || suggestion.Status != string(models.StatusPending) || firstVideo(suggestion) < 0 {
+		m.SetUserState(adminID, StateIdle)
+		sendErr := reply("MsgCommentSuggestionReviewed")
+		if err != nil {
+			return true, fmt.Errorf("failed to load suggestion %s: %w", suggestionID.Hex(), err)
+		}
+		return true, sendErr
+	}
+
+	if choice != nil {
+		_, image, err := m.thumbnails.Make(ctx, suggestion.FileIDs[firstVideo(suggestion)], *choice)
+		if err != nil {
+			log.Printf("[Thumbnail Admin:%d Suggestion:%s] Error making thumbnail %+v: %v", adminID, suggestionID.Hex(), *choice, err)
+			return true, reply("MsgThumbnailFailed") // Let the admin try again
+		}
+		caption := locales.GetMessage(localizer, "MsgThumbnailPreview", map[string]interface{}{"Code": suggestion.Ref()}, nil)
+		preview := tu.Photo(tu.ID(chatID), tu.File(tu.NameReader(bytes.NewReader(image), "thumbnail.jpg"))).WithCaption(caption)
+		if _, err := m.bot.SendPhoto(ctx, preview); err != nil {
+			log.Printf("[Thumbnail Admin:%d Suggestion:%s] Error sending thumbnail preview: %v", adminID, suggestionID.Hex(), err)
+		}
+	}
+
+	m.SetUserState(adminID, StateIdle)
+	if err := m.repo.SetSuggestionThumbnail(ctx, suggestionID, choice); err != nil {
+		_ = reply("MsgErrorGeneral")
+		return true, fmt.Errorf("failed to save thumbnail of suggestion %s: %w", suggestionID.Hex(), err)
+	}
+	log.Printf("[Thumbnail Admin:%d Suggestion:%s] Set thumbnail to %+v", adminID, suggestionID.Hex(), choice)
+	m.updateReviewThumbnail(ctx, adminID, localizer, suggestion, choice)
+	if choice == nil {
+		return true, reply("MsgThumbnailReset")
+	}
+	return true, nil
+}
+
+// updateReviewThumbnail records the thumbnail choice in the admin's review session and updates
+// the review buttons if the suggestion is still shown.
+func (m *Manager) updateReviewThumbnail(ctx context.Context, adminID int64, localizer *i18n.Localizer, suggestion *models.Suggestion, choice *models.VideoThumbnail) {
+	session, ok := m.activeReviewSession(adminID)
+	if !ok {
+		return
+	}
+	m.reviewSessionsMutex.Lock()
+	index := -1
+	for i := range session.Suggestions {
+		if session.Suggestions[i].ID == suggestion.ID {
+			session.Suggestions[i].Thumbnail = choice
+			index = i
+		}
+	}
+	shown := index >= 0 && index == session.CurrentIndex
+	var reviewed models.Suggestion
+	if shown {
+		reviewed = session.Suggestions[index]
+	}
+	total := len(session.Suggestions)
+	chatID, messageID := session.ReviewChatID, session.CurrentControlMessageID
+	m.reviewSessionsMutex.Unlock()
+	if !shown {
+		return
+	}
+	if _, err := m.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+		ChatID:      tu.ID(chatID),
+		MessageID:   messageID,
+		ReplyMarkup: m.reviewKeyboard(localizer, &reviewed, index, total),
+	}); err != nil {
+		log.Printf("[Thumbnail Admin:%d] Error updating review buttons for suggestion %s: %v", adminID, suggestion.ID.Hex(), err)
+	}
+}
+
+// videoUpload is a video published with a custom thumbnail. Telegram ignores the thumbnails of
+// videos sent by file ID, so the video is uploaded along with it.
+type videoUpload struct {
+	item      *telego.InputMediaVideo // The video in the publication's media
+	video     []byte
+	thumbnail []byte
+}
+
+// prepareThumbnail makes the thumbnail chosen for the suggestion's video, or returns nil if there
+// is none or it can't be made. The video is then published with the automatic thumbnail.
+func (m *Manager) prepareThumbnail(ctx context.Context, suggestion *models.Suggestion, media []telego.InputMedia) *videoUpload {
+	index := firstVideo(suggestion)
+	if suggestion.Thumbnail == nil || m.thumbnails == nil || index < 0 || index >= len(media) {
+		return nil
+	}
+	item, ok := media[index].(*telego.InputMediaVideo)
+	if !ok {
+		return nil
+	}
+	video, image, err := m.thumbnails.Make(ctx, suggestion.FileIDs[index], *suggestion.Thumbnail)
+	if err != nil {
+		log.Printf("[publishSuggestion] Error making thumbnail of suggestion %s, publishing without it: %v", suggestion.ID.Hex(), err)
+		return nil
+	}
+	return &videoUpload{item: item, video: video, thumbnail: image}
+}
+
+// apply returns copies of media in which the video is uploaded with its thumbnail. Each call
+// makes new readers, so a failed send can be retried. A nil upload returns media as is.
+func (u *videoUpload) apply(media []telego.InputMedia) []telego.InputMedia {
+	if u == nil {
+		return media
+	}
+	applied := make([]telego.InputMedia, len(media))
+	for i, item := range media {
+		applied[i] = item
+		if item == telego.InputMedia(u.item) {
+			video := *u.item
+			video.Media = tu.File(tu.NameReader(bytes.NewReader(u.video), "video.mp4"))
+			thumb := tu.File(tu.NameReader(bytes.NewReader(u.thumbnail), "thumbnail.jpg"))
+			video.Thumbnail = &thumb
+			applied[i] = &video
+		}
+	}
+	return applied
+}
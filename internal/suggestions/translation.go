@@ -0,0 +1,129 @@
+package suggestions
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+	"vrcmemes-bot/internal/translate"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SetTranslator enables translation of suggestion captions into the channel language: reviewers
+// see the translation next to the original and choose which one is published. nil disables it.
+func (m *Manager) SetTranslator(translator translate.Provider) {
+	m.translator = translator
+}
+
+// translateSuggestionCaption translates a suggestion's caption into the channel language in the
+// background and stores the translation if the caption is in another language. Errors are only
+// logged; the suggestion is reviewed with the original caption then.
+func (m *Manager) translateSuggestionCaption(id primitive.ObjectID, caption string) {
+	if m.translator == nil || caption == "" {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), translate.DefaultTimeout)
+		defer cancel()
+		target := locales.GetDefaultLanguageTag().String()
+		translation, err := m.translator.Translate(ctx, caption, target)
+		if err != nil {
+			log.Printf("[Translate Suggestion:%s] Error translating caption: %v", id.Hex(), err)
+			return
+		}
+		if !translate.Needed(caption, translation, target) {
+			return
+		}
+		if err := m.repo.SetSuggestionTranslation(ctx, id, translation.SourceLanguage, translation.Text); err != nil {
+			log.Printf("[Translate Suggestion:%s] Error saving translation: %v", id.Hex(), err)
+			return
+		}
+		log.Printf("[Translate Suggestion:%s] Translated caption from %q to %s", id.Hex(), translation.SourceLanguage, target)
+	}()
+}
+
+// chosenCaption returns the caption the reviewer chose to publish with a suggestion: the
+// original, its translation, or "" for neither.
+func chosenCaption(suggestion *models.Suggestion) string {
+	switch suggestion.CaptionChoice {
+	case models.CaptionChoiceOriginal:
+		return suggestion.Caption
+	case models.CaptionChoiceTranslation:
+		return suggestion.TranslatedCaption
+	default:
+		return ""
+	}
+}
+
+// nextCaptionChoice returns the caption choice the caption button switches to: neither, the
+// original, then the translation.
+func nextCaptionChoice(choice string) string {
+	switch choice {
+	case "":
+		return models.CaptionChoiceOriginal
+	case models.CaptionChoiceOriginal:
+		return models.CaptionChoiceTranslation
+	default:
+		return ""
+	}
+}
+
+// toggleCaptionChoice switches which caption the suggestion at index of the admin's review session
+// is published with, and updates the review buttons.
+func (m *Manager) toggleCaptionChoice(ctx context.Context, queryID string, adminID int64, session *ReviewSession, index int) error {
+	localizer := m.localizerForUserID(ctx, adminID)
+
+	m.reviewSessionsMutex.RLock()
+	valid := index < len(session.Suggestions)
+	var suggestion models.Suggestion
+	if valid {
+		suggestion = session.Suggestions[index]
+	}
+	m.reviewSessionsMutex.RUnlock()
+	if !valid {
+		_ = m.answerCallbackQuery(ctx, queryID, locales.GetMessage(localizer, "MsgReviewSessionExpired", nil, nil), true)
+		return nil
+	}
+
+	choice := nextCaptionChoice(suggestion.CaptionChoice)
+	if err := m.repo.SetSuggestionCaptionChoice(ctx, suggestion.ID, choice); err != nil {
+		_ = m.answerCallbackQuery(ctx, queryID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("failed to save caption choice of suggestion %s: %w", suggestion.ID.Hex(), err)
+	}
+
+	m.reviewSessionsMutex.Lock()
+	if index < len(session.Suggestions) && session.Suggestions[index].ID == suggestion.ID {
+		session.Suggestions[index].CaptionChoice = choice
+		suggestion = session.Suggestions[index]
+	}
+	total := len(session.Suggestions)
+	chatID, messageID := session.ReviewChatID, session.CurrentControlMessageID
+	m.reviewSessionsMutex.Unlock()
+	log.Printf("[CallbackQuery] Admin %d set caption choice of suggestion %s to %q", adminID, suggestion.ID.Hex(), choice)
+
+	_ = m.answerCallbackQuery(ctx, queryID, locales.GetMessage(localizer, captionChoiceButton(choice), nil, nil), false)
+	if _, err := m.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+		ChatID:      tu.ID(chatID),
+		MessageID:   messageID,
+		ReplyMarkup: m.reviewKeyboard(localizer, &suggestion, index, total),
+	}); err != nil {
+		log.Printf("[CallbackQuery] Error updating review buttons for suggestion %s: %v", suggestion.ID.Hex(), err)
+	}
+	return nil
+}
+
+// captionChoiceButton returns the message ID of the caption button label for a caption choice.
+func captionChoiceButton(choice string) string {
+	switch choice {
+	case models.CaptionChoiceOriginal:
+		return "BtnCaptionOriginal"
+	case models.CaptionChoiceTranslation:
+		return "BtnCaptionTranslation"
+	default:
+		return "BtnCaptionNone"
+	}
+}
@@ -0,0 +1,86 @@
+package suggestions
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+	"vrcmemes-bot/internal/cache"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// userStateKeyPrefix starts the cache keys of user states.
+	userStateKeyPrefix = "user_state:"
+	// userStateTimeout bounds reading or writing a user's state in the cache.
+	userStateTimeout = 2 * time.Second
+)
+
+// userStateEntry is a user's state with the context it needs, cached under the user's ID.
+// Idle users have no entry.
+type userStateEntry struct {
+	State            UserState          `json:"state"`
+	EditTarget       primitive.ObjectID `json:"edit_target"`                 // Suggestion being edited or discussed, or feedback being replied to
+	FeedbackCategory string             `json:"feedback_category,omitempty"` // Category chosen for the feedback the user is about to send
+}
+
+// userStateKey returns the cache key of a user's state.
+func userStateKey(userID int64) string {
+	return userStateKeyPrefix + strconv.FormatInt(userID, 10)
+}
+
+// SetCache sets where user states and subscription checks are kept. Defaults to an in-memory
+// cache; a shared cache lets several instances continue each other's conversations.
+// Call it before the manager is used.
+func (m *Manager) SetCache(c cache.Cache) {
+	m.cache = c
+}
+
+// loadUserState returns a user's state entry. Cache errors are logged and the user is treated as idle.
+func (m *Manager) loadUserState(userID int64) userStateEntry {
+	ctx, cancel := context.WithTimeout(context.Background(), userStateTimeout)
+	defer cancel()
+	var entry userStateEntry
+	if _, err := cache.GetJSON(ctx, m.cache, userStateKey(userID), &entry); err != nil {
+		log.Printf("[UserState User:%d] Error reading state, treating the user as idle: %v", userID, err)
+		return userStateEntry{}
+	}
+	return entry
+}
+
+// storeUserState saves a user's state entry, or removes it if the user is idle. User states
+// don't expire: they end when the user finishes or cancels.
+func (m *Manager) storeUserState(userID int64, entry userStateEntry) {
+	ctx, cancel := context.WithTimeout(context.Background(), userStateTimeout)
+	defer cancel()
+	var err error
+	if entry.State == StateIdle {
+		err = m.cache.Delete(ctx, userStateKey(userID))
+	} else {
+		err = cache.SetJSON(ctx, m.cache, userStateKey(userID), entry, 0)
+	}
+	if err != nil {
+		log.Printf("[UserState User:%d] Error saving state %q: %v", userID, entry.State, err)
+	}
+}
+
+// SetUserState sets the state for a given user. Going idle also forgets the edit target and
+// feedback category; other states keep them.
+func (m *Manager) SetUserState(userID int64, state UserState) {
+	m.muUserStates.Lock()
+	defer m.muUserStates.Unlock()
+	entry := userStateEntry{}
+	if state != StateIdle {
+		entry = m.loadUserState(userID)
+		entry.State = state
+	}
+	m.storeUserState(userID, entry)
+}
+
+// GetUserState retrieves the state for a given user.
+func (m *Manager) GetUserState(userID int64) UserState {
+	m.muUserStates.RLock()
+	defer m.muUserStates.RUnlock()
+	return m.loadUserState(userID).State
+}
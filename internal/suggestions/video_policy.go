@@ -0,0 +1,72 @@
+package suggestions
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/locales"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// videoPolicyBlocks tells the admin that a suggestion can't be published because of the video
+// policy until they override it, and reports whether it did.
+func (m *Manager) videoPolicyBlocks(ctx context.Context, queryID string, localizer *i18n.Localizer, suggestion *models.Suggestion) bool {
+	if !suggestion.VideoPolicyBlocked() {
+		return false
+	}
+	_ = m.answerCallbackQuery(ctx, queryID, locales.GetMessage(localizer, "MsgReviewVideoPolicyBlocked", nil, nil), true)
+	return true
+}
+
+// toggleVideoPolicyOverride switches whether the suggestion at index of the admin's review session
+// may be published although its videos are outside the video policy, and updates the review
+// buttons.
+func (m *Manager) toggleVideoPolicyOverride(ctx context.Context, queryID string, adminID int64, session *ReviewSession, index int) error {
+	localizer := m.localizerForUserID(ctx, adminID)
+
+	m.reviewSessionsMutex.RLock()
+	valid := index < len(session.Suggestions)
+	var suggestion models.Suggestion
+	if valid {
+		suggestion = session.Suggestions[index]
+	}
+	m.reviewSessionsMutex.RUnlock()
+	if !valid {
+		_ = m.answerCallbackQuery(ctx, queryID, locales.GetMessage(localizer, "MsgReviewSessionExpired", nil, nil), true)
+		return nil
+	}
+
+	override := !suggestion.VideoPolicyOverride
+	if err := m.repo.SetSuggestionVideoPolicyOverride(ctx, suggestion.ID, override); err != nil {
+		_ = m.answerCallbackQuery(ctx, queryID, locales.GetMessage(localizer, "MsgErrorGeneral", nil, nil), true)
+		return fmt.Errorf("failed to save video policy override of suggestion %s: %w", suggestion.ID.Hex(), err)
+	}
+
+	m.reviewSessionsMutex.Lock()
+	if index < len(session.Suggestions) && session.Suggestions[index].ID == suggestion.ID {
+		session.Suggestions[index].VideoPolicyOverride = override
+		suggestion = session.Suggestions[index]
+	}
+	total := len(session.Suggestions)
+	chatID, messageID := session.ReviewChatID, session.CurrentControlMessageID
+	m.reviewSessionsMutex.Unlock()
+	log.Printf("[CallbackQuery] Admin %d set video policy override of suggestion %s to %t", adminID, suggestion.ID.Hex(), override)
+
+	toastID := "MsgVideoPolicyEnforced"
+	if override {
+		toastID = "MsgVideoPolicyOverridden"
+	}
+	_ = m.answerCallbackQuery(ctx, queryID, locales.GetMessage(localizer, toastID, nil, nil), false)
+	if _, err := m.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+		ChatID:      tu.ID(chatID),
+		MessageID:   messageID,
+		ReplyMarkup: m.reviewKeyboard(localizer, &suggestion, index, total),
+	}); err != nil {
+		log.Printf("[CallbackQuery] Error updating review buttons for suggestion %s: %v", suggestion.ID.Hex(), err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package suggestions
+
+import (
+	"context"
+	"log"
+	"strings"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/wordfilter"
+)
+
+// SetWordFilterRepository enables screening suggestion captions for the words managed with
+// /wordfilter. Captions with blocked words are rejected on submission, censored words are
+// replaced by asterisks, and reviewers see which filtered words a caption contained.
+func (m *Manager) SetWordFilterRepository(repo database.WordFilterRepository) {
+	m.wordFilterRepo = repo
+}
+
+// screenCaption screens the caption of a new suggestion. It censors the caption and records the
+// filtered words found for reviewers, and returns a violation if the caption must be rejected.
+// Captions pass unchanged when the filter can't be loaded.
+func (m *Manager) screenCaption(ctx context.Context, suggestion *models.Suggestion) *ruleViolation {
+	if m.wordFilterRepo == nil || strings.TrimSpace(suggestion.Caption) == "" {
+		return nil
+	}
+	result, filter, err := wordfilter.Screen(ctx, m.wordFilterRepo, models.FilterSourceSuggestion, suggestion.SuggesterID, suggestion.Caption)
+	if err != nil {
+		log.Printf("[WordFilter User:%d] %v", suggestion.SuggesterID, err)
+	}
+	if filter == nil || result.Severity == wordfilter.None {
+		return nil
+	}
+	log.Printf("[WordFilter User:%d] Caption matched filtered words (%s): %s", suggestion.SuggesterID, result.Severity, strings.Join(result.Words(), ", "))
+	if result.Severity == wordfilter.Block {
+		return &ruleViolation{Rule: RuleWordFilter, MessageID: "MsgAutoRejectWordFilter"}
+	}
+	suggestion.Caption = filter.Censor(suggestion.Caption)
+	suggestion.FilteredWords = result.Words()
+	return nil
+}
+
+// screenEditedCaption screens a caption a suggester is editing in. It returns the caption with
+// censored words masked, and whether the caption may be saved at all.
+func (m *Manager) screenEditedCaption(ctx context.Context, userID int64, caption string) (string, bool) {
+	if m.wordFilterRepo == nil {
+		return caption, true
+	}
+	result, filter, err := wordfilter.Screen(ctx, m.wordFilterRepo, models.FilterSourceSuggestion, userID, caption)
+	if err != nil {
+		log.Printf("[WordFilter User:%d] %v", userID, err)
+	}
+	if filter == nil || result.Severity == wordfilter.None {
+		return caption, true
+	}
+	log.Printf("[WordFilter User:%d] Edited caption matched filtered words (%s): %s", userID, result.Severity, strings.Join(result.Words(), ", "))
+	if result.Severity == wordfilter.Block {
+		return caption, false
+	}
+	return filter.Censor(caption), true
+}
@@ -0,0 +1,383 @@
+// Package tenants keeps track of the channels served in multi-tenant mode, where owners connect
+// their own channels to the running bot, and picks the channel each update is for.
+package tenants
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+
+	"github.com/mymmrac/telego"
+)
+
+// CallbackPrefix prefixes callback data of the /channel buttons ("tenant:<channel ID>"), which
+// are always processed by the primary channel's bot.
+const CallbackPrefix = "tenant:"
+
+// primaryCommands are processed by the primary channel's bot whatever channel the user picked.
+var primaryCommands = map[string]bool{"channel": true, "connect": true, "tenants": true}
+
+// StartFunc creates and starts the components serving a connected channel. Their background jobs
+// stop when ctx is canceled, which happens when the channel is disconnected.
+type StartFunc func(ctx context.Context, tenant models.Tenant) error
+
+// Usage is what a connected channel currently uses of its quota.
+type Usage struct {
+	PendingSuggestions int64 // -1 if unknown
+	UpdatesThisMinute  int
+}
+
+// rateWindow counts the updates of a channel in the current minute.
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// Registry holds the connected channels and the channel each user picked. The primary channel,
+// configured with CHANNEL_ID, isn't stored: it is the channel of users who haven't picked one.
+type Registry struct {
+	primaryID int64
+	repo      database.TenantRepository
+	start     StartFunc
+
+	mu           sync.RWMutex
+	base         context.Context // Lifecycle of the channels' components, set by Load
+	tenants      map[int64]models.Tenant
+	stops        map[int64]context.CancelFunc            // Stops the components of each channel
+	suggestions  map[int64]database.SuggestionRepository // Counted for the suggestion quota
+	windows      map[int64]*rateWindow
+	choices      map[int64]int64 // Channel picked by each user, once loaded
+	defaultQuota models.TenantQuota
+	now          func() time.Time
+}
+
+// NewRegistry creates a registry of the channels connected besides the primary one.
+// start is called for every channel loaded or connected.
+func NewRegistry(primaryID int64, repo database.TenantRepository, start StartFunc) *Registry {
+	if repo == nil {
+		log.Fatal("Registry: Tenant repository dependency is nil")
+	}
+	return &Registry{
+		primaryID:   primaryID,
+		repo:        repo,
+		start:       start,
+		tenants:     make(map[int64]models.Tenant),
+		stops:       make(map[int64]context.CancelFunc),
+		suggestions: make(map[int64]database.SuggestionRepository),
+		windows:     make(map[int64]*rateWindow),
+		choices:     make(map[int64]int64),
+		now:         time.Now,
+	}
+}
+
+// SetDefaultQuota sets the quota of connected channels whose quota the host operator didn't set
+// with /tenants. Zero fields are unlimited.
+func (r *Registry) SetDefaultQuota(quota models.TenantQuota) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.defaultQuota = quota
+}
+
+// Load starts the components of all connected channels. A channel that fails to start is
+// logged and skipped, so one broken channel doesn't keep the others down. ctx is the lifecycle
+// of the bot: the components of all channels, including those connected later, run until it is done.
+func (r *Registry) Load(ctx context.Context) error {
+	r.mu.Lock()
+	r.base = ctx
+	r.mu.Unlock()
+	tenants, err := r.repo.ListTenants(ctx)
+	if err != nil {
+		return err
+	}
+	for _, tenant := range tenants {
+		if err := r.startTenant(ctx, tenant); err != nil {
+			log.Printf("[Tenants] Error starting channel %d: %v", tenant.ChannelID, err)
+		}
+	}
+	log.Printf("[Tenants] Serving %d connected channels besides channel %d", len(r.Tenants()), r.primaryID)
+	return nil
+}
+
+// Connect stores a newly connected channel and starts its components. It returns
+// database.ErrTenantExists if the channel is the primary one or already connected.
+func (r *Registry) Connect(ctx context.Context, tenant models.Tenant) error {
+	if tenant.ChannelID == r.primaryID {
+		return database.ErrTenantExists
+	}
+	if err := r.repo.CreateTenant(ctx, &tenant); err != nil {
+		return err
+	}
+	// The components outlive the /connect update that ctx belongs to
+	if err := r.startTenant(r.baseContext(), tenant); err != nil {
+		return err
+	}
+	log.Printf("[Tenants] User %d connected channel %d", tenant.OwnerID, tenant.ChannelID)
+	return nil
+}
+
+// baseContext returns the lifecycle the components of channels run in, or context.Background()
+// before Load.
+func (r *Registry) baseContext() context.Context {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.base == nil {
+		return context.Background()
+	}
+	return r.base
+}
+
+// startTenant starts the components of a channel and makes it routable.
+func (r *Registry) startTenant(ctx context.Context, tenant models.Tenant) error {
+	tenantCtx, stop := context.WithCancel(ctx)
+	if err := r.start(tenantCtx, tenant); err != nil {
+		stop()
+		return fmt.Errorf("failed to start channel %d: %w", tenant.ChannelID, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tenants[tenant.ChannelID] = tenant
+	r.stops[tenant.ChannelID] = stop
+	return nil
+}
+
+// Disconnect stops serving a connected channel. Its data is kept, so connecting it again brings
+// its suggestions and settings back. It returns database.ErrTenantNotFound if the channel isn't
+// connected.
+func (r *Registry) Disconnect(ctx context.Context, channelID int64) error {
+	if err := r.repo.DeleteTenant(ctx, channelID); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stop, ok := r.stops[channelID]; ok {
+		stop()
+	}
+	delete(r.tenants, channelID)
+	delete(r.stops, channelID)
+	delete(r.suggestions, channelID)
+	delete(r.windows, channelID)
+	log.Printf("[Tenants] Disconnected channel %d", channelID)
+	return nil
+}
+
+// SetQuota replaces the quota of a connected channel. It returns database.ErrTenantNotFound if
+// the channel isn't connected.
+func (r *Registry) SetQuota(ctx context.Context, channelID int64, quota models.TenantQuota) error {
+	if !r.isTenant(channelID) {
+		return database.ErrTenantNotFound
+	}
+	if err := r.repo.SetTenantQuota(ctx, channelID, quota); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tenant := r.tenants[channelID]
+	tenant.Quota = quota
+	r.tenants[channelID] = tenant
+	return nil
+}
+
+// Quota returns the quota in effect for a connected channel: its own, with the defaults for
+// the limits the host operator didn't set.
+func (r *Registry) Quota(channelID int64) models.TenantQuota {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	quota := r.tenants[channelID].Quota
+	if quota.MaxPendingSuggestions == 0 {
+		quota.MaxPendingSuggestions = r.defaultQuota.MaxPendingSuggestions
+	}
+	if quota.UpdatesPerMinute == 0 {
+		quota.UpdatesPerMinute = r.defaultQuota.UpdatesPerMinute
+	}
+	return quota
+}
+
+// Usage returns what a connected channel currently uses of its quota.
+func (r *Registry) Usage(ctx context.Context, channelID int64) Usage {
+	r.mu.RLock()
+	repo := r.suggestions[channelID]
+	usage := Usage{PendingSuggestions: -1}
+	if window, ok := r.windows[channelID]; ok && r.now().Sub(window.start) < time.Minute {
+		usage.UpdatesThisMinute = window.count
+	}
+	r.mu.RUnlock()
+	if repo != nil {
+		if pending, err := countPending(ctx, repo); err == nil {
+			usage.PendingSuggestions = pending
+		} else {
+			log.Printf("[Tenants] Error counting the pending suggestions of channel %d: %v", channelID, err)
+		}
+	}
+	return usage
+}
+
+// Allow counts an update for a channel and reports whether the channel is within its rate limit.
+// The primary channel isn't limited.
+func (r *Registry) Allow(channelID int64) bool {
+	if channelID == r.primaryID {
+		return true
+	}
+	limit := r.Quota(channelID).UpdatesPerMinute
+	now := r.now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	window, ok := r.windows[channelID]
+	if !ok || now.Sub(window.start) >= time.Minute {
+		window = &rateWindow{start: now}
+		r.windows[channelID] = window
+	}
+	window.count++
+	return limit <= 0 || window.count <= limit
+}
+
+// LimitSuggestions wraps the suggestion repository of a connected channel so new suggestions are
+// refused with database.ErrSuggestionQuotaExceeded once the channel has as many pending
+// suggestions as its quota allows.
+func (r *Registry) LimitSuggestions(channelID int64, repo database.SuggestionRepository) database.SuggestionRepository {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.suggestions[channelID] = repo
+	return &quotaSuggestionRepository{SuggestionRepository: repo, registry: r, channelID: channelID}
+}
+
+// quotaSuggestionRepository enforces the suggestion quota of a connected channel.
+type quotaSuggestionRepository struct {
+	database.SuggestionRepository
+	registry  *Registry
+	channelID int64
+}
+
+// CreateSuggestion stores a new suggestion unless the channel's queue is full. If the pending
+// suggestions can't be counted, the suggestion is stored anyway.
+func (q *quotaSuggestionRepository) CreateSuggestion(ctx context.Context, suggestion *models.Suggestion) error {
+	if limit := q.registry.Quota(q.channelID).MaxPendingSuggestions; limit > 0 {
+		pending, err := countPending(ctx, q.SuggestionRepository)
+		if err != nil {
+			log.Printf("[Tenants] Error counting the pending suggestions of channel %d, not enforcing the quota: %v", q.channelID, err)
+		} else if pending >= int64(limit) {
+			return fmt.Errorf("%w: channel %d has %d pending suggestions", database.ErrSuggestionQuotaExceeded, q.channelID, pending)
+		}
+	}
+	return q.SuggestionRepository.CreateSuggestion(ctx, suggestion)
+}
+
+// countPending returns how many suggestions are pending in a repository.
+func countPending(ctx context.Context, repo database.SuggestionRepository) (int64, error) {
+	_, pending, err := repo.GetPendingSuggestionsInOrder(ctx, 1, database.PendingOldestFirst)
+	return pending, err
+}
+
+// PrimaryID returns the ID of the primary channel.
+func (r *Registry) PrimaryID() int64 {
+	return r.primaryID
+}
+
+// Tenants returns the connected channels, besides the primary one, oldest first.
+func (r *Registry) Tenants() []models.Tenant {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tenants := make([]models.Tenant, 0, len(r.tenants))
+	for _, tenant := range r.tenants {
+		tenants = append(tenants, tenant)
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].CreatedAt.Before(tenants[j].CreatedAt) })
+	return tenants
+}
+
+// isTenant reports whether a chat is a connected channel.
+func (r *Registry) isTenant(chatID int64) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.tenants[chatID]
+	return ok
+}
+
+// UserTenant returns the channel a user picked, or the primary channel if they haven't picked
+// one or the channel they picked is no longer connected.
+func (r *Registry) UserTenant(ctx context.Context, userID int64) int64 {
+	r.mu.RLock()
+	channelID, loaded := r.choices[userID]
+	r.mu.RUnlock()
+	if !loaded {
+		var err error
+		channelID, err = r.repo.GetUserTenant(ctx, userID)
+		if err != nil {
+			log.Printf("[Tenants] Error loading the channel of user %d, using the primary channel: %v", userID, err)
+			return r.primaryID
+		}
+		r.mu.Lock()
+		r.choices[userID] = channelID
+		r.mu.Unlock()
+	}
+	if !r.isTenant(channelID) {
+		return r.primaryID
+	}
+	return channelID
+}
+
+// SetUserTenant saves the channel a user picked: the primary channel or a connected one.
+func (r *Registry) SetUserTenant(ctx context.Context, userID, channelID int64) error {
+	if channelID != r.primaryID && !r.isTenant(channelID) {
+		return fmt.Errorf("channel %d is not connected", channelID)
+	}
+	if err := r.repo.SetUserTenant(ctx, userID, channelID); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.choices[userID] = channelID
+	return nil
+}
+
+// Route returns the channel an update is for. Updates from a connected channel, such as member
+// changes and reaction counts, are for that channel. Private messages and button presses are for
+// the channel the user picked, except the commands and buttons that pick or connect channels.
+// Everything else is for the primary channel.
+func (r *Registry) Route(ctx context.Context, update telego.Update) int64 {
+	switch {
+	case update.ChatMember != nil:
+		return r.routeChat(update.ChatMember.Chat.ID)
+	case update.MessageReactionCount != nil:
+		return r.routeChat(update.MessageReactionCount.Chat.ID)
+	case update.Message != nil:
+		message := update.Message
+		if message.Chat.Type != telego.ChatTypePrivate || message.From == nil {
+			return r.routeChat(message.Chat.ID)
+		}
+		if primaryCommands[commandName(message.Text)] {
+			return r.primaryID
+		}
+		return r.UserTenant(ctx, message.From.ID)
+	case update.CallbackQuery != nil:
+		if strings.HasPrefix(update.CallbackQuery.Data, CallbackPrefix) {
+			return r.primaryID
+		}
+		return r.UserTenant(ctx, update.CallbackQuery.From.ID)
+	default:
+		return r.primaryID
+	}
+}
+
+// routeChat returns the channel of a chat: the chat itself if it is a connected channel.
+func (r *Registry) routeChat(chatID int64) int64 {
+	if r.isTenant(chatID) {
+		return chatID
+	}
+	return r.primaryID
+}
+
+// commandName returns the command of a message text without the slash and bot username,
+// or "" if the text isn't a command.
+func commandName(text string) string {
+	if !strings.HasPrefix(text, "/") {
+		return ""
+	}
+	name, _, _ := strings.Cut(strings.Fields(text)[0][1:], "@")
+	return name
+}
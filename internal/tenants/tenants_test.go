@@ -0,0 +1,191 @@
+package tenants
+
+import (
+	"context"
+	"testing"
+	"time"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	primaryID = -100111
+	tenantID  = -100222
+)
+
+// memoryTenantRepo is an in-memory database.TenantRepository.
+type memoryTenantRepo struct {
+	tenants []models.Tenant
+	choices map[int64]int64
+}
+
+func (r *memoryTenantRepo) CreateTenant(_ context.Context, tenant *models.Tenant) error {
+	for _, t := range r.tenants {
+		if t.ChannelID == tenant.ChannelID {
+			return database.ErrTenantExists
+		}
+	}
+	r.tenants = append(r.tenants, *tenant)
+	return nil
+}
+
+func (r *memoryTenantRepo) ListTenants(context.Context) ([]models.Tenant, error) {
+	return r.tenants, nil
+}
+
+func (r *memoryTenantRepo) SetTenantQuota(_ context.Context, channelID int64, quota models.TenantQuota) error {
+	for i := range r.tenants {
+		if r.tenants[i].ChannelID == channelID {
+			r.tenants[i].Quota = quota
+			return nil
+		}
+	}
+	return database.ErrTenantNotFound
+}
+
+func (r *memoryTenantRepo) DeleteTenant(_ context.Context, channelID int64) error {
+	for i, t := range r.tenants {
+		if t.ChannelID == channelID {
+			r.tenants = append(r.tenants[:i], r.tenants[i+1:]...)
+			return nil
+		}
+	}
+	return database.ErrTenantNotFound
+}
+
+func (r *memoryTenantRepo) GetUserTenant(_ context.Context, userID int64) (int64, error) {
+	return r.choices[userID], nil
+}
+
+func (r *memoryTenantRepo) SetUserTenant(_ context.Context, userID, channelID int64) error {
+	r.choices[userID] = channelID
+	return nil
+}
+
+// countingSuggestionRepo is a database.SuggestionRepository that only counts created suggestions.
+type countingSuggestionRepo struct {
+	database.SuggestionRepository
+	pending int64
+}
+
+func (r *countingSuggestionRepo) CreateSuggestion(context.Context, *models.Suggestion) error {
+	r.pending++
+	return nil
+}
+
+func (r *countingSuggestionRepo) GetPendingSuggestionsInOrder(context.Context, int, database.PendingOrder) ([]models.Suggestion, int64, error) {
+	return nil, r.pending, nil
+}
+
+func privateMessage(userID int64, text string) telego.Update {
+	return telego.Update{Message: &telego.Message{
+		Chat: telego.Chat{ID: userID, Type: telego.ChatTypePrivate},
+		From: &telego.User{ID: userID},
+		Text: text,
+	}}
+}
+
+func TestRegistryRoutesUpdatesToPickedChannel(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryTenantRepo{choices: map[int64]int64{}}
+	var started []int64
+	registry := NewRegistry(primaryID, repo, func(_ context.Context, tenant models.Tenant) error {
+		started = append(started, tenant.ChannelID)
+		return nil
+	})
+
+	require.NoError(t, registry.Connect(ctx, models.Tenant{ChannelID: tenantID, OwnerID: 1}))
+	assert.Equal(t, []int64{tenantID}, started)
+	assert.ErrorIs(t, registry.Connect(ctx, models.Tenant{ChannelID: tenantID, OwnerID: 2}), database.ErrTenantExists)
+	assert.ErrorIs(t, registry.Connect(ctx, models.Tenant{ChannelID: primaryID, OwnerID: 2}), database.ErrTenantExists)
+
+	// Users who haven't picked a channel use the primary one
+	assert.Equal(t, int64(primaryID), registry.Route(ctx, privateMessage(7, "/suggest")))
+
+	require.NoError(t, registry.SetUserTenant(ctx, 7, tenantID))
+	assert.Equal(t, int64(tenantID), registry.Route(ctx, privateMessage(7, "/suggest")))
+	assert.Equal(t, int64(tenantID), registry.Route(ctx, telego.Update{CallbackQuery: &telego.CallbackQuery{From: telego.User{ID: 7}, Data: "approve:S-4F7K"}}))
+	// Picking and connecting channels always goes to the primary channel
+	assert.Equal(t, int64(primaryID), registry.Route(ctx, privateMessage(7, "/channel@vrcmemes_bot")))
+	assert.Equal(t, int64(primaryID), registry.Route(ctx, telego.Update{CallbackQuery: &telego.CallbackQuery{From: telego.User{ID: 7}, Data: CallbackPrefix + "-100111"}}))
+
+	// Channel updates go to the channel they come from
+	member := telego.Update{ChatMember: &telego.ChatMemberUpdated{Chat: telego.Chat{ID: tenantID}}}
+	assert.Equal(t, int64(tenantID), registry.Route(ctx, member))
+	member.ChatMember.Chat.ID = -100999
+	assert.Equal(t, int64(primaryID), registry.Route(ctx, member))
+
+	assert.Error(t, registry.SetUserTenant(ctx, 7, -100999), "picked a channel that isn't connected")
+
+	// The choice survives a restart
+	reloaded := NewRegistry(primaryID, repo, func(context.Context, models.Tenant) error { return nil })
+	require.NoError(t, reloaded.Load(ctx))
+	assert.Equal(t, int64(tenantID), reloaded.UserTenant(ctx, 7))
+}
+
+func TestConnectedChannelOutlivesTheConnectUpdate(t *testing.T) {
+	lifecycle, shutdown := context.WithCancel(context.Background())
+	defer shutdown()
+	repo := &memoryTenantRepo{choices: map[int64]int64{}}
+	var tenantCtx context.Context
+	registry := NewRegistry(primaryID, repo, func(ctx context.Context, _ models.Tenant) error {
+		tenantCtx = ctx
+		return nil
+	})
+	require.NoError(t, registry.Load(lifecycle))
+
+	// The /connect update is done once the command returns
+	updateCtx, done := context.WithTimeout(context.Background(), time.Minute)
+	require.NoError(t, registry.Connect(updateCtx, models.Tenant{ChannelID: tenantID, OwnerID: 1}))
+	done()
+	require.NoError(t, tenantCtx.Err(), "the channel stopped with the /connect update")
+	require.NoError(t, registry.SetUserTenant(lifecycle, 7, tenantID))
+	assert.Equal(t, int64(tenantID), registry.Route(lifecycle, privateMessage(7, "/suggest")))
+
+	// Shutting the bot down stops it
+	shutdown()
+	assert.Error(t, tenantCtx.Err())
+}
+
+func TestRegistryEnforcesQuotas(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryTenantRepo{choices: map[int64]int64{}}
+	var tenantCtx context.Context
+	registry := NewRegistry(primaryID, repo, func(ctx context.Context, _ models.Tenant) error {
+		tenantCtx = ctx
+		return nil
+	})
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	registry.now = func() time.Time { return now }
+	registry.SetDefaultQuota(models.TenantQuota{MaxPendingSuggestions: 5, UpdatesPerMinute: 2})
+	require.NoError(t, registry.Connect(ctx, models.Tenant{ChannelID: tenantID, OwnerID: 1}))
+
+	// Channels get the default quota for the limits the host operator didn't set
+	require.NoError(t, registry.SetQuota(ctx, tenantID, models.TenantQuota{MaxPendingSuggestions: 1}))
+	assert.Equal(t, models.TenantQuota{MaxPendingSuggestions: 1, UpdatesPerMinute: 2}, registry.Quota(tenantID))
+	assert.ErrorIs(t, registry.SetQuota(ctx, -100999, models.TenantQuota{}), database.ErrTenantNotFound)
+
+	suggestions := registry.LimitSuggestions(tenantID, &countingSuggestionRepo{})
+	require.NoError(t, suggestions.CreateSuggestion(ctx, &models.Suggestion{}))
+	assert.ErrorIs(t, suggestions.CreateSuggestion(ctx, &models.Suggestion{}), database.ErrSuggestionQuotaExceeded)
+
+	assert.True(t, registry.Allow(tenantID))
+	assert.True(t, registry.Allow(tenantID))
+	assert.False(t, registry.Allow(tenantID), "third update in a minute")
+	assert.True(t, registry.Allow(primaryID), "the primary channel isn't limited")
+	assert.Equal(t, Usage{PendingSuggestions: 1, UpdatesThisMinute: 3}, registry.Usage(ctx, tenantID))
+	now = now.Add(time.Minute)
+	assert.True(t, registry.Allow(tenantID), "a new minute started")
+
+	// Disconnected channels are stopped and their users go back to the primary channel
+	require.NoError(t, registry.SetUserTenant(ctx, 7, tenantID))
+	require.NoError(t, registry.Disconnect(ctx, tenantID))
+	assert.Error(t, tenantCtx.Err())
+	assert.Equal(t, int64(primaryID), registry.Route(ctx, privateMessage(7, "/suggest")))
+	assert.Empty(t, repo.tenants)
+	assert.ErrorIs(t, registry.Disconnect(ctx, tenantID), database.ErrTenantNotFound)
+}
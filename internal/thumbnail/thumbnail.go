@@ -0,0 +1,172 @@
+// Package thumbnail makes the custom thumbnails reviewers choose for published videos: a frame of
+// the video, extracted by ffmpeg, or an image they send. Telegram only uses the thumbnail of a
+// video uploaded along with it, so the video is downloaded as well.
+package thumbnail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+
+	"github.com/mymmrac/telego"
+)
+
+const (
+	// MaxSize is the largest thumbnail Telegram accepts, in bytes.
+	MaxSize = 200 << 10
+	// MaxDimension is the largest width and height of a thumbnail, in pixels.
+	MaxDimension = 320
+	// DefaultTimeout bounds downloading a video and making its thumbnail.
+	DefaultTimeout = 2 * time.Minute
+)
+
+// ErrFramesUnsupported is returned for frame thumbnails when no frame extractor is configured.
+var ErrFramesUnsupported = errors.New("video frames can't be extracted without ffmpeg")
+
+// Downloader fetches the contents of a Telegram file. *mediaproxy.Proxy implements it.
+type Downloader interface {
+	Download(ctx context.Context, fileID string) ([]byte, error)
+}
+
+// FrameExtractor extracts a frame of a video as a JPEG thumbnail.
+type FrameExtractor interface {
+	// Frame returns the frame of an encoded video at a time, scaled to fit MaxDimension.
+	Frame(ctx context.Context, video []byte, at time.Duration) ([]byte, error)
+}
+
+// FFmpeg extracts frames by running ffmpeg.
+type FFmpeg struct {
+	path string
+}
+
+// NewFFmpeg creates a frame extractor running the ffmpeg binary at path, or found in PATH by name.
+func NewFFmpeg(path string) (*FFmpeg, error) {
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg not found: %w", err)
+	}
+	return &FFmpeg{path: resolved}, nil
+}
+
+// Frame runs ffmpeg on a temporary copy of the video, since MP4 files can't always be read from
+// a pipe.
+func (f *FFmpeg) Frame(ctx context.Context, video []byte, at time.Duration) ([]byte, error) {
+	file, err := os.CreateTemp("", "thumbnail-*.mp4")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary video file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(video)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write temporary video file: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, f.path, "-v", "error",
+		"-ss", strconv.FormatFloat(at.Seconds(), 'f', 3, 64), "-i", file.Name(),
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease", MaxDimension, MaxDimension),
+		"-q:v", "4", "-f", "mjpeg", "pipe:1")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	frame, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if len(frame) == 0 {
+		// ffmpeg succeeds without output when the time is past the end of the video
+		return nil, fmt.Errorf("video has no frame at %s", FormatTime(at))
+	}
+	return frame, nil
+}
+
+// Maker makes the thumbnails reviewers chose.
+type Maker struct {
+	downloader Downloader
+	frames     FrameExtractor
+}
+
+// NewMaker creates a thumbnail maker downloading files with downloader. Without a frame extractor
+// only images can be thumbnails.
+func NewMaker(downloader Downloader, frames FrameExtractor) *Maker {
+	return &Maker{downloader: downloader, frames: frames}
+}
+
+// FramesSupported reports whether frames of videos can be thumbnails.
+func (m *Maker) FramesSupported() bool {
+	return m.frames != nil
+}
+
+// Make downloads a video and makes the chosen thumbnail for it. It returns the contents of both.
+func (m *Maker) Make(ctx context.Context, videoFileID string, choice models.VideoThumbnail) (video, thumbnail []byte, err error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	if choice.FileID == "" && m.frames == nil {
+		return nil, nil, ErrFramesUnsupported
+	}
+	video, err = m.downloader.Download(ctx, videoFileID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to download video: %w", err)
+	}
+	if choice.FileID != "" {
+		thumbnail, err = m.downloader.Download(ctx, choice.FileID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to download thumbnail image: %w", err)
+		}
+	} else {
+		thumbnail, err = m.frames.Frame(ctx, video, time.Duration(choice.At)*time.Second)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to extract video frame: %w", err)
+		}
+	}
+	if len(thumbnail) > MaxSize {
+		return nil, nil, fmt.Errorf("thumbnail is too large (%d bytes)", len(thumbnail))
+	}
+	return video, thumbnail, nil
+}
+
+// PhotoSize picks the largest size of a photo that fits a thumbnail. It reports false if every
+// size is too large.
+func PhotoSize(sizes []telego.PhotoSize) (telego.PhotoSize, bool) {
+	var best telego.PhotoSize
+	found := false
+	for _, size := range sizes {
+		if size.Width > MaxDimension || size.Height > MaxDimension || (size.FileSize > 0 && size.FileSize > MaxSize) {
+			continue
+		}
+		if !found || size.Width*size.Height > best.Width*best.Height {
+			best, found = size, true
+		}
+	}
+	return best, found
+}
+
+// ParseTime parses the time of a frame written as seconds ("7") or minutes and seconds ("1:07").
+func ParseTime(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	minutes, seconds, hasMinutes := strings.Cut(s, ":")
+	if !hasMinutes {
+		minutes, seconds = "0", s
+	}
+	m, errM := strconv.Atoi(minutes)
+	sec, errS := strconv.Atoi(seconds)
+	if errM != nil || errS != nil || m < 0 || sec < 0 || (hasMinutes && (sec >= 60 || len(seconds) != 2)) {
+		return 0, fmt.Errorf("invalid frame time %q: expected seconds or M:SS", s)
+	}
+	return time.Duration(m)*time.Minute + time.Duration(sec)*time.Second, nil
+}
+
+// FormatTime formats the time of a frame as M:SS, e.g. "1:07".
+func FormatTime(at time.Duration) string {
+	seconds := int(at / time.Second)
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
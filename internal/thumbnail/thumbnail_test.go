@@ -0,0 +1,64 @@
+package thumbnail
+
+import (
+	"context"
+	"testing"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// downloaderFunc adapts a function to Downloader.
+type downloaderFunc func(ctx context.Context, fileID string) ([]byte, error)
+
+func (f downloaderFunc) Download(ctx context.Context, fileID string) ([]byte, error) {
+	return f(ctx, fileID)
+}
+
+// frameFunc adapts a function to FrameExtractor.
+type frameFunc func(ctx context.Context, video []byte, at time.Duration) ([]byte, error)
+
+func (f frameFunc) Frame(ctx context.Context, video []byte, at time.Duration) ([]byte, error) {
+	return f(ctx, video, at)
+}
+
+func TestParseAndFormatTime(t *testing.T) {
+	at, err := ParseTime("1:07")
+	require.NoError(t, err)
+	assert.Equal(t, 67*time.Second, at)
+	at, err = ParseTime(" 7 ")
+	require.NoError(t, err)
+	assert.Equal(t, "0:07", FormatTime(at))
+	for _, invalid := range []string{"", "1:7", "0:60", "-3", "auto"} {
+		_, err := ParseTime(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestMakerMakesFrameOrImageThumbnail(t *testing.T) {
+	ctx := context.Background()
+	downloader := downloaderFunc(func(ctx context.Context, fileID string) ([]byte, error) {
+		return []byte("contents of " + fileID), nil
+	})
+	frames := frameFunc(func(ctx context.Context, video []byte, at time.Duration) ([]byte, error) {
+		return []byte("frame at " + FormatTime(at) + " of " + string(video)), nil
+	})
+
+	video, thumbnail, err := NewMaker(downloader, frames).Make(ctx, "video", models.VideoThumbnail{At: 7})
+	require.NoError(t, err)
+	assert.Equal(t, "contents of video", string(video))
+	assert.Equal(t, "frame at 0:07 of contents of video", string(thumbnail))
+
+	_, thumbnail, err = NewMaker(downloader, nil).Make(ctx, "video", models.VideoThumbnail{FileID: "image"})
+	require.NoError(t, err)
+	assert.Equal(t, "contents of image", string(thumbnail))
+	_, _, err = NewMaker(downloader, nil).Make(ctx, "video", models.VideoThumbnail{At: 7})
+	assert.ErrorIs(t, err, ErrFramesUnsupported)
+
+	size, ok := PhotoSize([]telego.PhotoSize{{FileID: "s", Width: 90, Height: 51}, {FileID: "m", Width: 320, Height: 180}, {FileID: "x", Width: 800, Height: 450}})
+	require.True(t, ok)
+	assert.Equal(t, "m", size.FileID)
+}
@@ -0,0 +1,111 @@
+// Package translate translates suggestion captions written in another language into the channel
+// language, so reviewers can read them and publish the translation. The translation itself is
+// done by a pluggable Provider, such as a LibreTranslate server.
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout bounds translating one caption.
+	DefaultTimeout = 30 * time.Second
+	// maxErrorBody bounds how much of an error response is kept in the error.
+	maxErrorBody = 512
+)
+
+// Translation is a text translated into the target language.
+type Translation struct {
+	Text           string
+	SourceLanguage string // Detected language of the original, e.g. "de"; empty if unknown
+}
+
+// Provider translates texts.
+type Provider interface {
+	// Translate translates text into the target language, e.g. "en", detecting its language.
+	Translate(ctx context.Context, text, target string) (Translation, error)
+}
+
+// LibreTranslate translates with the /translate endpoint of a LibreTranslate server, or of any
+// service with the same API.
+type LibreTranslate struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// NewLibreTranslate creates a provider for the LibreTranslate server at baseURL, e.g.
+// "https://libretranslate.com". apiKey may be empty for servers that don't need one.
+func NewLibreTranslate(baseURL, apiKey string) *LibreTranslate {
+	return &LibreTranslate{
+		url:    strings.TrimRight(baseURL, "/") + "/translate",
+		apiKey: apiKey,
+		client: &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+// libreRequest is the body of a LibreTranslate request.
+type libreRequest struct {
+	Q      string `json:"q"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Format string `json:"format"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// libreResponse is the body of a LibreTranslate response.
+type libreResponse struct {
+	TranslatedText   string `json:"translatedText"`
+	DetectedLanguage struct {
+		Language string `json:"language"`
+	} `json:"detectedLanguage"`
+	Error string `json:"error"`
+}
+
+// Translate sends the text to the server with its language detected automatically.
+func (p *LibreTranslate) Translate(ctx context.Context, text, target string) (Translation, error) {
+	body, err := json.Marshal(libreRequest{Q: text, Source: "auto", Target: target, Format: "text", APIKey: p.apiKey})
+	if err != nil {
+		return Translation{}, fmt.Errorf("failed to encode translation request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return Translation{}, fmt.Errorf("failed to create translation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Translation{}, fmt.Errorf("translation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return Translation{}, fmt.Errorf("translation server returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	var result libreResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Translation{}, fmt.Errorf("failed to decode translation response: %w", err)
+	}
+	if result.Error != "" {
+		return Translation{}, fmt.Errorf("translation failed: %s", result.Error)
+	}
+	return Translation{Text: result.TranslatedText, SourceLanguage: result.DetectedLanguage.Language}, nil
+}
+
+// Needed reports whether a translation is worth showing: the original is in another language
+// than the target, and the translation differs from it.
+func Needed(original string, translation Translation, target string) bool {
+	if translation.SourceLanguage != "" && strings.EqualFold(translation.SourceLanguage, target) {
+		return false
+	}
+	text := strings.TrimSpace(translation.Text)
+	return text != "" && !strings.EqualFold(text, strings.TrimSpace(original))
+}
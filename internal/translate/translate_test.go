@@ -0,0 +1,49 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLibreTranslateDetectsLanguage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/translate", r.URL.Path)
+		var req libreRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "Wenn der Code kompiliert", req.Q)
+		assert.Equal(t, "auto", req.Source)
+		assert.Equal(t, "en", req.Target)
+		assert.Equal(t, "secret", req.APIKey)
+		_, _ = w.Write([]byte(`{"translatedText":"When the code compiles","detectedLanguage":{"confidence":90,"language":"de"}}`))
+	}))
+	defer server.Close()
+
+	translation, err := NewLibreTranslate(server.URL+"/", "secret").Translate(context.Background(), "Wenn der Code kompiliert", "en")
+	require.NoError(t, err)
+	assert.Equal(t, Translation{Text: "When the code compiles", SourceLanguage: "de"}, translation)
+	assert.True(t, Needed("Wenn der Code kompiliert", translation, "en"))
+}
+
+func TestLibreTranslateReportsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":"Invalid API key"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewLibreTranslate(server.URL, "").Translate(context.Background(), "hallo", "en")
+	assert.ErrorContains(t, err, "Invalid API key")
+}
+
+func TestNeeded(t *testing.T) {
+	assert.False(t, Needed("hello", Translation{Text: "hello", SourceLanguage: "en"}, "en"), "already in the target language")
+	assert.False(t, Needed("LOL", Translation{Text: "lol"}, "ru"), "nothing to translate")
+	assert.False(t, Needed("hallo", Translation{Text: " "}, "en"))
+	assert.True(t, Needed("привет", Translation{Text: "hello"}, "en"))
+}
@@ -0,0 +1,130 @@
+// Package videopolicy holds the channel's policy for videos: how long they may be and which
+// aspect ratios fit the channel. Both suggested videos and videos admins post directly are
+// checked against it.
+package videopolicy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+)
+
+// AspectRatio is a width to height ratio such as 16:9.
+type AspectRatio struct {
+	Width  int
+	Height int
+}
+
+// ParseAspectRatio parses a ratio written as "W:H", e.g. "9:16".
+func ParseAspectRatio(s string) (AspectRatio, error) {
+	width, height, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return AspectRatio{}, fmt.Errorf("invalid aspect ratio %q: expected W:H", s)
+	}
+	w, errW := strconv.Atoi(strings.TrimSpace(width))
+	h, errH := strconv.Atoi(strings.TrimSpace(height))
+	if errW != nil || errH != nil || w <= 0 || h <= 0 {
+		return AspectRatio{}, fmt.Errorf("invalid aspect ratio %q: expected positive W:H", s)
+	}
+	return AspectRatio{Width: w, Height: h}, nil
+}
+
+// String formats the ratio as "W:H".
+func (r AspectRatio) String() string {
+	return strconv.Itoa(r.Width) + ":" + strconv.Itoa(r.Height)
+}
+
+// IsZero reports whether the ratio is unset.
+func (r AspectRatio) IsZero() bool {
+	return r.Width == 0 || r.Height == 0
+}
+
+// value returns the ratio as a number, e.g. 1.78 for 16:9.
+func (r AspectRatio) value() float64 {
+	return float64(r.Width) / float64(r.Height)
+}
+
+// Policy is the channel's video policy. Zero values disable the corresponding check.
+type Policy struct {
+	MaxDuration    time.Duration // Longest allowed video
+	MinAspectRatio AspectRatio   // Narrowest allowed video, e.g. 9:16
+	MaxAspectRatio AspectRatio   // Widest allowed video, e.g. 16:9
+}
+
+// New creates the policy allowing videos up to maxDuration long with the aspect ratios of
+// ParseAspectRatios.
+func New(maxDuration time.Duration, aspectRatios string) (Policy, error) {
+	minRatio, maxRatio, err := ParseAspectRatios(aspectRatios)
+	if err != nil {
+		return Policy{}, err
+	}
+	return Policy{MaxDuration: maxDuration, MinAspectRatio: minRatio, MaxAspectRatio: maxRatio}, nil
+}
+
+// ParseAspectRatios parses the range of allowed aspect ratios written as "MIN-MAX", e.g.
+// "9:16-16:9", or a single ratio that videos must match. An empty string allows any ratio.
+func ParseAspectRatios(s string) (minRatio, maxRatio AspectRatio, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return AspectRatio{}, AspectRatio{}, nil
+	}
+	low, high, isRange := strings.Cut(s, "-")
+	if minRatio, err = ParseAspectRatio(low); err != nil {
+		return AspectRatio{}, AspectRatio{}, err
+	}
+	maxRatio = minRatio
+	if isRange {
+		if maxRatio, err = ParseAspectRatio(high); err != nil {
+			return AspectRatio{}, AspectRatio{}, err
+		}
+	}
+	if minRatio.value() > maxRatio.value() {
+		minRatio, maxRatio = maxRatio, minRatio
+	}
+	return minRatio, maxRatio, nil
+}
+
+// Enabled reports whether the policy checks anything.
+func (p Policy) Enabled() bool {
+	return p.MaxDuration > 0 || !p.MinAspectRatio.IsZero()
+}
+
+// AspectRatios formats the allowed aspect ratios, e.g. "9:16–16:9", or a single ratio.
+func (p Policy) AspectRatios() string {
+	if p.MinAspectRatio == p.MaxAspectRatio {
+		return p.MinAspectRatio.String()
+	}
+	return p.MinAspectRatio.String() + "–" + p.MaxAspectRatio.String()
+}
+
+// aspectRatioTolerance absorbs rounding of encoded video sizes, e.g. 1080x1918 for 9:16.
+const aspectRatioTolerance = 0.02
+
+// Check returns the issues of the video at index of a post with the policy. Sizes and durations
+// Telegram doesn't report are zero and aren't checked.
+func (p Policy) Check(index, width, height int, duration time.Duration) []models.MediaIssue {
+	var issues []models.MediaIssue
+	if p.MaxDuration > 0 && duration > p.MaxDuration {
+		issues = append(issues, models.MediaIssue{
+			Index:    index,
+			Kind:     models.MediaIssueVideoTooLong,
+			Duration: int(duration / time.Second),
+			Limit:    int64(p.MaxDuration / time.Second),
+		})
+	}
+	if !p.MinAspectRatio.IsZero() && width > 0 && height > 0 {
+		ratio := float64(width) / float64(height)
+		if ratio < p.MinAspectRatio.value()*(1-aspectRatioTolerance) || ratio > p.MaxAspectRatio.value()*(1+aspectRatioTolerance) {
+			issues = append(issues, models.MediaIssue{
+				Index:  index,
+				Kind:   models.MediaIssueAspectRatio,
+				Width:  width,
+				Height: height,
+				Range:  p.AspectRatios(),
+			})
+		}
+	}
+	return issues
+}
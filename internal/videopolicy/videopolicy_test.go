@@ -0,0 +1,30 @@
+package videopolicy
+
+import (
+	"testing"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyChecksDurationAndAspectRatio(t *testing.T) {
+	minRatio, maxRatio, err := ParseAspectRatios("16:9-9:16")
+	require.NoError(t, err)
+	assert.Equal(t, AspectRatio{Width: 9, Height: 16}, minRatio, "the range is ordered")
+	policy := Policy{MaxDuration: time.Minute, MinAspectRatio: minRatio, MaxAspectRatio: maxRatio}
+	assert.Equal(t, "9:16–16:9", policy.AspectRatios())
+
+	assert.Empty(t, policy.Check(0, 1080, 1918, 59*time.Second), "rounded sizes fit")
+	assert.Empty(t, policy.Check(0, 0, 0, 0), "unknown properties aren't checked")
+
+	issues := policy.Check(2, 2400, 1000, 95*time.Second)
+	require.Len(t, issues, 2)
+	assert.Equal(t, models.MediaIssue{Index: 2, Kind: models.MediaIssueVideoTooLong, Duration: 95, Limit: 60}, issues[0])
+	assert.Equal(t, models.MediaIssue{Index: 2, Kind: models.MediaIssueAspectRatio, Width: 2400, Height: 1000, Range: "9:16–16:9"}, issues[1])
+
+	_, _, err = ParseAspectRatios("wide")
+	assert.Error(t, err)
+	assert.False(t, Policy{}.Enabled())
+}
@@ -0,0 +1,59 @@
+package wordfilter
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"vrcmemes-bot/internal/database/models"
+)
+
+// Store keeps the filtered words and the log of hits. database.WordFilterRepository implements it.
+type Store interface {
+	ListFilteredWords(ctx context.Context) ([]models.FilteredWord, error)
+	LogFilterHits(ctx context.Context, hits []models.FilterHit) error
+}
+
+// Load creates a filter from the words in the store.
+func Load(ctx context.Context, store Store) (*Filter, error) {
+	words, err := store.ListFilteredWords(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(words))
+	for _, word := range words {
+		if severity, ok := ParseSeverity(word.Severity); ok {
+			entries = append(entries, Entry{Word: word.Word, Severity: severity})
+		}
+	}
+	return New(entries), nil
+}
+
+// Screen checks a text from a user against the words in the store and logs the hits, from the
+// given source (see models.FilterSourceSuggestion). It returns the filter too, to censor the text.
+// If only logging the hits fails, the result and filter are returned along with the error.
+func Screen(ctx context.Context, store Store, source string, userID int64, text string) (Result, *Filter, error) {
+	filter, err := Load(ctx, store)
+	if err != nil {
+		return Result{}, nil, fmt.Errorf("failed to load filtered words: %w", err)
+	}
+	result := filter.Check(text)
+	if len(result.Matches) == 0 {
+		return result, filter, nil
+	}
+	now := time.Now()
+	hits := make([]models.FilterHit, len(result.Matches))
+	for i, match := range result.Matches {
+		hits[i] = models.FilterHit{
+			Word:     match.Word,
+			Text:     match.Text,
+			Severity: match.Severity.String(),
+			Source:   source,
+			UserID:   userID,
+			At:       now,
+		}
+	}
+	if err := store.LogFilterHits(ctx, hits); err != nil {
+		return result, filter, fmt.Errorf("failed to log filter hits: %w", err)
+	}
+	return result, filter, nil
+}
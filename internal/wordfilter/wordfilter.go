@@ -0,0 +1,200 @@
+// Package wordfilter screens captions and posts for words admins listed with /wordfilter. Each
+// word has a severity saying what happens to a text containing it: a warning, the word replaced
+// by asterisks, or the text refused.
+package wordfilter
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Severity says what happens to a text containing a filtered word. Higher severities win.
+type Severity int
+
+const (
+	None   Severity = iota // No filtered word found
+	Warn                   // The text passes, but reviewers or the sender are warned
+	Censor                 // The word is replaced by asterisks
+	Block                  // The text is refused
+)
+
+// Severity names, as stored and typed in /wordfilter.
+var severityNames = map[Severity]string{Warn: "warn", Censor: "censor", Block: "block"}
+
+// String returns the name of the severity, or "" for None.
+func (s Severity) String() string {
+	return severityNames[s]
+}
+
+// ParseSeverity parses a severity name. It reports false for unknown names.
+func ParseSeverity(name string) (Severity, bool) {
+	for severity, severityName := range severityNames {
+		if strings.EqualFold(name, severityName) {
+			return severity, true
+		}
+	}
+	return None, false
+}
+
+// Names returns the severity names from the mildest, for usage lines.
+func Names() []string {
+	return []string{Warn.String(), Censor.String(), Block.String()}
+}
+
+// censorMask replaces censored words.
+const censorMask = "***"
+
+// Entry is a filtered word. A word ending with "*" matches every word starting with the rest,
+// e.g. "dumb*" matches "dumbest"; otherwise only whole words match. Matching ignores case.
+type Entry struct {
+	Word     string
+	Severity Severity
+}
+
+// Normalize returns the form entries are stored and compared in: lowercased and trimmed. It
+// returns "" for an entry that isn't a single word of letters and digits, optionally ending with "*".
+func Normalize(word string) string {
+	word = strings.ToLower(strings.TrimSpace(word))
+	stem := strings.TrimSuffix(word, "*")
+	if stem == "" {
+		return ""
+	}
+	for _, r := range stem {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return ""
+		}
+	}
+	return word
+}
+
+// Match is a filtered word found in a text.
+type Match struct {
+	Entry        // The entry that matched
+	Text  string // The word as written in the text
+}
+
+// Result is what screening a text found.
+type Result struct {
+	Severity Severity // Highest severity among the matches
+	Matches  []Match
+}
+
+// Words returns the distinct entries that matched, sorted, for messages and logs.
+func (r Result) Words() []string {
+	seen := make(map[string]struct{}, len(r.Matches))
+	var words []string
+	for _, match := range r.Matches {
+		if _, ok := seen[match.Word]; !ok {
+			seen[match.Word] = struct{}{}
+			words = append(words, match.Word)
+		}
+	}
+	sort.Strings(words)
+	return words
+}
+
+// Filter screens texts against a list of entries.
+type Filter struct {
+	exact    map[string]Entry
+	prefixes []Entry // Entries ending with "*", without it
+}
+
+// New creates a filter from the entries. Entries are normalized; invalid ones are skipped.
+func New(entries []Entry) *Filter {
+	f := &Filter{exact: make(map[string]Entry)}
+	for _, entry := range entries {
+		word := Normalize(entry.Word)
+		if word == "" || entry.Severity == None {
+			continue
+		}
+		if stem, ok := strings.CutSuffix(word, "*"); ok {
+			f.prefixes = append(f.prefixes, Entry{Word: stem, Severity: entry.Severity})
+			continue
+		}
+		if existing, ok := f.exact[word]; !ok || entry.Severity > existing.Severity {
+			f.exact[word] = Entry{Word: word, Severity: entry.Severity}
+		}
+	}
+	return f
+}
+
+// Empty reports whether the filter has no entries, so screening always passes.
+func (f *Filter) Empty() bool {
+	return f == nil || (len(f.exact) == 0 && len(f.prefixes) == 0)
+}
+
+// word is a word of a text with its position, in bytes.
+type word struct {
+	text       string
+	start, end int
+}
+
+// words splits a text into words of letters and digits.
+func words(text string) []word {
+	var result []word
+	start := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			result = append(result, word{text: text[start:i], start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		result = append(result, word{text: text[start:], start: start, end: len(text)})
+	}
+	return result
+}
+
+// match returns the entry matching a word, the most severe if several do.
+func (f *Filter) match(w string) (Entry, bool) {
+	lower := strings.ToLower(w)
+	entry, found := f.exact[lower]
+	for _, prefix := range f.prefixes {
+		if strings.HasPrefix(lower, prefix.Word) && (!found || prefix.Severity > entry.Severity) {
+			entry, found = Entry{Word: prefix.Word + "*", Severity: prefix.Severity}, true
+		}
+	}
+	return entry, found
+}
+
+// Check screens a text.
+func (f *Filter) Check(text string) Result {
+	var result Result
+	if f.Empty() {
+		return result
+	}
+	for _, w := range words(text) {
+		entry, ok := f.match(w.text)
+		if !ok {
+			continue
+		}
+		result.Matches = append(result.Matches, Match{Entry: entry, Text: w.text})
+		if entry.Severity > result.Severity {
+			result.Severity = entry.Severity
+		}
+	}
+	return result
+}
+
+// Censor replaces the words of a text matching censor entries, or more severe ones, by asterisks.
+func (f *Filter) Censor(text string) string {
+	if f.Empty() {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, w := range words(text) {
+		if entry, ok := f.match(w.text); ok && entry.Severity >= Censor {
+			b.WriteString(text[last:w.start])
+			b.WriteString(censorMask)
+			last = w.end
+		}
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
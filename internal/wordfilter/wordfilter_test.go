@@ -0,0 +1,41 @@
+package wordfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckFindsMostSevereWord(t *testing.T) {
+	filter := New([]Entry{
+		{Word: "heck", Severity: Warn},
+		{Word: "Дурак*", Severity: Censor},
+		{Word: "spam", Severity: Block},
+		{Word: "not a word", Severity: Block},
+	})
+
+	result := filter.Check("What the HECK, дураки!")
+	assert.Equal(t, Censor, result.Severity)
+	assert.Equal(t, []string{"heck", "дурак*"}, result.Words())
+	assert.Equal(t, "дураки", result.Matches[1].Text)
+
+	assert.Equal(t, Block, filter.Check("buy spam now").Severity)
+	assert.Equal(t, None, filter.Check("spammer heckle").Severity, "only whole words match entries without *")
+	assert.Equal(t, None, filter.Check("not a word").Severity, "invalid entries are skipped")
+}
+
+func TestCensorMasksCensoredWords(t *testing.T) {
+	filter := New([]Entry{{Word: "heck", Severity: Warn}, {Word: "dumb*", Severity: Censor}})
+	assert.Equal(t, "What the heck, *** idea. ***!", filter.Censor("What the heck, DUMBEST idea. Dumb!"))
+	assert.Equal(t, "clean text", New(nil).Censor("clean text"))
+}
+
+func TestParseSeverity(t *testing.T) {
+	severity, ok := ParseSeverity("Censor")
+	assert.True(t, ok)
+	assert.Equal(t, Censor, severity)
+	_, ok = ParseSeverity("ban")
+	assert.False(t, ok)
+	assert.Equal(t, "", Normalize("two words"))
+	assert.Equal(t, "word*", Normalize(" Word* "))
+}
@@ -6,21 +6,52 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 	"vrcmemes-bot/internal/auth"
+	"vrcmemes-bot/internal/buildinfo"
+	"vrcmemes-bot/internal/cache"
+	"vrcmemes-bot/internal/channelrights"
 	"vrcmemes-bot/internal/config"
+	"vrcmemes-bot/internal/dashboard"
 	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/debugmode"
+	"vrcmemes-bot/internal/degraded"
+	"vrcmemes-bot/internal/engagement"
+	"vrcmemes-bot/internal/errbudget"
+	"vrcmemes-bot/internal/footers"
 	"vrcmemes-bot/internal/handlers"
+	"vrcmemes-bot/internal/incidents"
 	"vrcmemes-bot/internal/locales"
 	"vrcmemes-bot/internal/mediagroups"
+	"vrcmemes-bot/internal/mediaproxy"
+	"vrcmemes-bot/internal/ocr"
+	"vrcmemes-bot/internal/outbox"
+	"vrcmemes-bot/internal/preflight"
+	"vrcmemes-bot/internal/publisher"
+	"vrcmemes-bot/internal/simulation"
+	"vrcmemes-bot/internal/staging"
 	"vrcmemes-bot/internal/suggestions"
+	"vrcmemes-bot/internal/tenants"
+	"vrcmemes-bot/internal/thumbnail"
+	"vrcmemes-bot/internal/translate"
+	"vrcmemes-bot/internal/videopolicy"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
 
 	telegoBot "vrcmemes-bot/bot"
 
 	sentry "github.com/getsentry/sentry-go"
 	telego "github.com/mymmrac/telego"
+	ta "github.com/mymmrac/telego/telegoapi"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/valyala/fasthttp"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	_ "time/tzdata" // TIMEZONE works in images without system time zone data
 	// _ "go.uber.org/automaxprocs" // Uncomment if needed
 )
 
@@ -34,7 +65,7 @@ func initSentry(cfg *config.Config) error {
 	err := sentry.Init(sentry.ClientOptions{
 		Dsn:              cfg.SentryDSN,
 		Environment:      cfg.AppEnv,
-		Release:          cfg.Version,
+		Release:          buildinfo.Get().Version,
 		EnableTracing:    true,
 		TracesSampleRate: 1.0, // Adjust as needed
 		Debug:            cfg.Debug,
@@ -42,14 +73,17 @@ func initSentry(cfg *config.Config) error {
 	if err != nil {
 		return fmt.Errorf("sentry.Init: %w", err)
 	}
+	sentry.ConfigureScope(func(scope *sentry.Scope) {
+		scope.SetTag("commit", buildinfo.Get().Commit)
+	})
 	log.Println("Sentry initialized.")
 	return nil
 }
 
 // connectDatabase establishes a connection to MongoDB.
 // Returns the client, database instance, and any error.
-func connectDatabase(cfg *config.Config) (*mongo.Client, *mongo.Database, error) {
-	client, _, err := database.ConnectDB(cfg)
+func connectDatabase(cfg *config.Config, extra ...*options.ClientOptions) (*mongo.Client, *mongo.Database, error) {
+	client, _, err := database.ConnectDB(cfg, extra...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -60,21 +94,195 @@ func connectDatabase(cfg *config.Config) (*mongo.Client, *mongo.Database, error)
 	return client, db, nil
 }
 
+// primaryChannelScope returns the scope of the primary channel's data. It is unrestricted unless
+// multi-tenant mode is enabled, so single-channel deployments query their collections as before.
+func primaryChannelScope(cfg *config.Config) database.ChannelScope {
+	if !cfg.MultiTenant {
+		return database.ChannelScope{}
+	}
+	// Connected channels have their own data; the data stored earlier belongs to the primary channel
+	return database.NewChannelScope(cfg.ChannelID, true)
+}
+
 // createRepositories initializes all necessary database repositories.
-func createRepositories(db *mongo.Database) (
+func createRepositories(db *mongo.Database, scope database.ChannelScope) (
 	database.SuggestionRepository,
 	database.UserActionLogger,
 	database.PostLogger,
 	database.UserRepository,
 	database.FeedbackRepository,
+	database.MembershipRepository,
 ) {
-	suggestionRepo := database.NewMongoSuggestionRepository(db)
-	userActionLogger := database.NewMongoLogger(db) // Assumes MongoLogger implements UserActionLogger
-	postLogger := database.NewMongoLogger(db)       // Assumes MongoLogger implements PostLogger
-	userRepo := database.NewMongoLogger(db)         // Assumes MongoLogger implements UserRepository
-	feedbackRepo := database.NewFeedbackRepository(db)
+	suggestionRepo := database.NewMongoSuggestionRepository(db).ForChannel(scope)
+	userActionLogger := database.NewMongoLogger(db).ForChannel(scope) // Assumes MongoLogger implements UserActionLogger
+	postLogger := database.NewMongoLogger(db)                         // Assumes MongoLogger implements PostLogger
+	userRepo := database.NewMongoLogger(db)                           // Assumes MongoLogger implements UserRepository
+	feedbackRepo := database.NewChannelFeedbackRepository(db, scope)
+	membershipRepo := database.NewMembershipRepository(db)
 
-	return suggestionRepo, userActionLogger, postLogger, userRepo, feedbackRepo
+	return suggestionRepo, userActionLogger, postLogger, userRepo, feedbackRepo, membershipRepo
+}
+
+// setupDegradedMode creates the database monitor and wraps the repositories whose writes are
+// buffered while MongoDB is unavailable.
+func setupDegradedMode(
+	cfg *config.Config,
+	client *mongo.Client,
+	suggRepo database.SuggestionRepository,
+	postLogger database.PostLogger,
+) (*degraded.Monitor, *degraded.Buffer, *degraded.SuggestionRepository, database.PostLogger, error) {
+	buffer, err := degraded.NewBuffer(cfg.DBBufferSize, cfg.DBBufferSpillPath, cfg.DBBufferMaxSpilled)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to create write buffer: %w", err)
+	}
+	monitor := degraded.NewMonitor(database.NewPinger(client), buffer)
+	monitor.SetCheckInterval(cfg.DBHealthCheckInterval)
+	monitor.SetFailureThreshold(cfg.DBFailureThreshold)
+	return monitor, buffer, degraded.NewSuggestionRepository(suggRepo, monitor), degraded.NewPostLogger(postLogger, monitor), nil
+}
+
+// degradedModeAlert returns an alert function that tells the super admins when degraded mode
+// starts and ends. Messages are in the default language.
+func degradedModeAlert(botAPI telegoapi.BotAPI, adminIDs []int64, recorder *incidents.Recorder) degraded.AlertFunc {
+	var mu sync.Mutex
+	var since time.Time // Start of the current outage
+	return func(ctx context.Context, isDegraded bool, buffered int) {
+		// The outage can only be recorded once the database is back
+		mu.Lock()
+		if isDegraded {
+			since = time.Now()
+		} else if !since.IsZero() {
+			recorder.Record(models.Incident{
+				Type:       models.IncidentTypeDatabaseOutage,
+				OccurredAt: since,
+				Summary: fmt.Sprintf("MongoDB was unavailable for %s, %d buffered writes left to replay",
+					time.Since(since).Round(time.Second), buffered),
+			})
+			since = time.Time{}
+		}
+		mu.Unlock()
+
+		localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+		text := locales.GetMessage(localizer, "MsgDBRecovered", map[string]interface{}{"Remaining": buffered}, nil)
+		if isDegraded {
+			text = locales.GetMessage(localizer, "MsgDBDegraded", map[string]interface{}{"Buffered": buffered}, nil)
+		}
+		for _, id := range adminIDs {
+			if _, err := botAPI.SendMessage(ctx, tu.Message(tu.ID(id), text)); err != nil {
+				log.Printf("[DegradedMode] Error alerting admin %d: %v", id, err)
+			}
+		}
+	}
+}
+
+// resetWebhook removes a webhook left set on the bot, e.g. by set-webhook or another
+// deployment, and records the reset as an incident. Long polling gets no updates while one is set.
+func resetWebhook(ctx context.Context, bot *telego.Bot, recorder *incidents.Recorder) {
+	info, err := bot.GetWebhookInfo(ctx)
+	if err != nil {
+		log.Printf("Warning: could not check whether a webhook is set: %v", err)
+		return
+	}
+	if info.URL == "" {
+		return
+	}
+	incident := models.Incident{Type: models.IncidentTypeWebhookReset, Details: info.LastErrorMessage}
+	if err := bot.DeleteWebhook(ctx, &telego.DeleteWebhookParams{}); err != nil {
+		incident.Summary = fmt.Sprintf("Webhook %s is set and could not be removed, so no updates will arrive: %v", info.URL, err)
+	} else {
+		incident.Summary = fmt.Sprintf("Webhook %s was set and has been removed, so long polling receives updates again", info.URL)
+	}
+	recorder.Record(incident)
+}
+
+// errorBudgetAlert returns the error budget alert function, which tells the alert chat, or the
+// super admins if none is set, which kind of errors went over its budget.
+func errorBudgetAlert(botAPI telegoapi.BotAPI, cfg *config.Config) errbudget.AlertFunc {
+	chatIDs := cfg.SuperAdminIDs
+	if cfg.AlertChatID != 0 {
+		chatIDs = []int64{cfg.AlertChatID}
+	}
+	messageIDs := map[errbudget.Source]string{
+		errbudget.SourceTelegram: "MsgErrorBudgetTelegram",
+		errbudget.SourceDatabase: "MsgErrorBudgetDatabase",
+		errbudget.SourcePanic:    "MsgErrorBudgetPanics",
+	}
+	return func(ctx context.Context, source errbudget.Source, count int, window time.Duration) {
+		localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+		text := locales.GetMessage(localizer, messageIDs[source], map[string]interface{}{
+			"Count":    count,
+			"Window":   window.String(),
+			"Cooldown": cfg.ErrorBudgetCooldown.String(),
+		}, nil)
+		for _, id := range chatIDs {
+			if _, err := botAPI.SendMessage(ctx, tu.Message(tu.ID(id), text)); err != nil {
+				log.Printf("[ErrorBudget] Error alerting chat %d: %v", id, err)
+			}
+		}
+	}
+}
+
+// groupPolicy returns the groups the bot works in: those of ALLOWED_GROUP_IDS and the chats
+// configured for feedback, intake, alerts and the dashboard.
+func groupPolicy(cfg *config.Config) telegoBot.GroupPolicy {
+	allowed := slices.Clone(cfg.AllowedGroupIDs)
+	for _, id := range []int64{cfg.FeedbackChatID, cfg.IntakeChatID, cfg.AlertChatID, cfg.DashboardChatID} {
+		if id != 0 {
+			allowed = append(allowed, id)
+		}
+	}
+	return telegoBot.GroupPolicy{
+		Allowed: allowed,
+		Open:    cfg.UnknownGroups == "allow",
+		Leave:   cfg.UnknownGroups == "leave",
+	}
+}
+
+// channelRightsAlert returns the alert function of the channel rights checker, which tells the alert
+// chat, or the super admins if none is set, that the bot lost or regained the right to post.
+func channelRightsAlert(botAPI telegoapi.BotAPI, cfg *config.Config) channelrights.AlertFunc {
+	chatIDs := cfg.SuperAdminIDs
+	if cfg.AlertChatID != 0 {
+		chatIDs = []int64{cfg.AlertChatID}
+	}
+	return func(ctx context.Context, lost bool, rights channelrights.Rights) {
+		localizer := locales.NewLocalizer(locales.GetDefaultLanguageTag().String())
+		messageID := "MsgChannelRightsRestored"
+		if lost {
+			messageID = "MsgChannelRightsLost"
+		}
+		text := locales.GetMessage(localizer, messageID, map[string]interface{}{
+			"ChannelID": cfg.ChannelID,
+			"Status":    rights.Status,
+		}, nil)
+		for _, id := range chatIDs {
+			if _, err := botAPI.SendMessage(ctx, tu.Message(tu.ID(id), text)); err != nil {
+				log.Printf("[ChannelRights] Error alerting chat %d: %v", id, err)
+			}
+		}
+	}
+}
+
+// logPreflightReport logs the startup check report line by line.
+func logPreflightReport(report preflight.Report) {
+	var buf strings.Builder
+	report.Print(&buf)
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+		log.Printf("[Preflight] %s", line)
+	}
+}
+
+// newCache creates the cache configured by CACHE_BACKEND.
+func newCache(ctx context.Context, cfg *config.Config) (cache.Cache, error) {
+	if cfg.CacheBackend != "redis" {
+		return cache.NewMemory(), nil
+	}
+	redisCache, err := cache.NewRedis(ctx, cfg.RedisURL, cfg.CacheKeyPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up the Redis cache: %w", err)
+	}
+	log.Printf("Using the Redis cache with key prefix %q", cfg.CacheKeyPrefix)
+	return redisCache, nil
 }
 
 // setupBotComponents creates the core application components like admin checker,
@@ -82,27 +290,83 @@ func createRepositories(db *mongo.Database) (
 func setupBotComponents(
 	cfg *config.Config,
 	bot *telego.Bot,
+	botAPI telegoapi.BotAPI, // Used for all sends; wraps bot in dry-run mode
 	suggRepo database.SuggestionRepository,
 	actionLogger database.UserActionLogger,
 	postLogger database.PostLogger,
 	userRepo database.UserRepository,
 	feedbackRepo database.FeedbackRepository,
+	membershipRepo database.MembershipRepository,
 	mediaGroupMgr *mediagroups.Manager,
+	publishQueue *publisher.Queue,
+	appCache cache.Cache,
 ) (*auth.AdminChecker, *suggestions.Manager, *handlers.MessageHandler, error) {
 
 	adminChecker, err := auth.NewAdminChecker(bot, cfg.ChannelID)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to create admin checker: %w", err)
 	}
+	adminChecker.SetCache(appCache)
+	adminChecker.SetCacheTTL(cfg.AdminCacheTTL)
+	adminChecker.SetSuperAdmins(cfg.SuperAdminIDs)
 
 	suggestionManager := suggestions.NewManager(
-		bot,
+		botAPI,
 		suggRepo,
 		cfg.ChannelID,
 		adminChecker,
 		feedbackRepo,
 		mediaGroupMgr,
+		userRepo,
+		membershipRepo,
+		publishQueue,
 	)
+	suggestionManager.SetCache(appCache)
+	suggestionManager.SetSubscriptionCacheTTL(cfg.SubscriptionCacheTTL, cfg.SubscriptionNegativeCacheTTL)
+	suggestionManager.SetMemberStatusTTL(cfg.MemberStatusTTL)
+	suggestionManager.SetReviewSessionTTL(cfg.ReviewSessionTTL)
+	suggestionManager.SetReviewReminderAfter(cfg.ReviewReminderAfter)
+	suggestionManager.SetReviewOrder(suggestions.ReviewOrder(cfg.ReviewOrder))
+	suggestionManager.SetReviewAssignment(cfg.ReviewAssignment, cfg.ReviewReassignAfter)
+	suggestionManager.SetSuggestionExpiry(cfg.SuggestionExpireAfter, cfg.NotifyExpiredSuggestions)
+	suggestionManager.SetPostLogger(postLogger)
+	suggestionManager.SetAcceptDirectSuggestions(cfg.AcceptDirectSuggestions)
+	suggestionManager.SetSuggestionPreview(cfg.PreviewSuggestions)
+	suggestionManager.SetCreditForwardSource(cfg.CreditForwardSource)
+	suggestionManager.SetCreditSuggesters(cfg.CreditSuggesters)
+	suggestionManager.SetCreditCoSuggesters(cfg.CreditCoSuggesters)
+	suggestionManager.SetCustomEmoji(cfg.CustomEmoji)
+	if cfg.MediaPrivacyMode {
+		suggestionManager.SetMediaProxy(mediaproxy.New(bot))
+	}
+	suggestionManager.SetFileDownloader(mediaproxy.New(bot))
+	suggestionManager.SetRulesChecklist(cfg.SuggestRulesChecklist)
+	suggestionManager.SetTags(cfg.SuggestionTags)
+	suggestionManager.SetLocation(cfg.TimeZone)
+	suggestionManager.SetNewSuggestionPings(cfg.NewSuggestionPings)
+	suggestionManager.SetAutoRejectRules(suggestions.AutoRejectRules{
+		CaptionPattern:     cfg.AutoRejectCaptionPattern,
+		MaxMediaCount:      cfg.AutoRejectMaxMedia,
+		MinAccountAge:      cfg.AutoRejectMinAccountAge,
+		MinSubscriptionAge: cfg.AutoRejectMinSubscriptionAge,
+	})
+	suggestionManager.SetMediaLimits(suggestions.MediaLimits{
+		MinResolution: cfg.SuggestionMinResolution,
+		MaxFileSize:   cfg.SuggestionMaxFileSize,
+		WarnOnly:      cfg.SuggestionMediaWarnOnly,
+	})
+	videoPolicy, _ := videopolicy.New(cfg.VideoMaxDuration, cfg.VideoAspectRatios) // Validated by the config
+	suggestionManager.SetVideoPolicy(videoPolicy)
+	suggestionManager.SetFeedbackLimits(suggestions.FeedbackLimits{
+		RateLimit:    cfg.FeedbackRateLimit,
+		RateWindow:   cfg.FeedbackRateWindow,
+		MinLength:    cfg.FeedbackMinLength,
+		MuteAfter:    cfg.FeedbackMuteAfter,
+		MuteDuration: cfg.FeedbackMuteDuration,
+	})
+	suggestionManager.SetFeedbackChat(cfg.FeedbackChatID, cfg.FeedbackTopicID)
+	suggestionManager.SetIntakeChatID(cfg.IntakeChatID)
+	suggestionManager.SetPreviewChannelID(cfg.PreviewChannelID)
 
 	messageHandler := handlers.NewMessageHandler(
 		cfg.ChannelID,
@@ -112,13 +376,48 @@ func setupBotComponents(
 		suggestionManager,
 		adminChecker,
 		feedbackRepo,
-		cfg.Version,
+		membershipRepo,
+		publishQueue,
+		buildinfo.Get(),
 	)
+	messageHandler.SetMaxPostFileSize(cfg.MaxPostFileSize)
+	messageHandler.SetVideoPolicy(videoPolicy)
+	messageHandler.SetGreetingLinks(cfg.ChannelURL, cfg.RulesURL)
+	messageHandler.SetLocation(cfg.TimeZone)
+	messageHandler.SetCustomEmoji(cfg.CustomEmoji)
+
+	if cfg.OCRCommand != "" {
+		engine, err := ocr.NewCommandEngine(cfg.OCRCommand)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid OCR_COMMAND: %w", err)
+		}
+		reader := ocr.NewReader(mediaproxy.New(bot), engine)
+		suggestionManager.SetTextRecognizer(reader)
+		messageHandler.SetTextRecognizer(reader)
+	}
+	var frames thumbnail.FrameExtractor
+	if cfg.FFmpegPath != "" {
+		ffmpeg, err := thumbnail.NewFFmpeg(cfg.FFmpegPath)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid FFMPEG_PATH: %w", err)
+		}
+		frames = ffmpeg
+	}
+	suggestionManager.SetThumbnailMaker(thumbnail.NewMaker(mediaproxy.New(bot), frames))
+	if cfg.TranslateURL != "" {
+		suggestionManager.SetTranslator(translate.NewLibreTranslate(cfg.TranslateURL, cfg.TranslateAPIKey))
+	}
 
 	return adminChecker, suggestionManager, messageHandler, nil
 }
 
-func main() {
+// runServe runs the bot until it receives an interrupt or termination signal.
+func runServe(cmd command, args []string) error {
+	fs := newFlagSet(cmd)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -137,8 +436,14 @@ func main() {
 		defer sentry.Flush(2 * time.Second)
 	}
 
+	// Count Telegram, database and panic errors, alerting when one goes over its budget
+	errorBudget := errbudget.NewTracker(cfg.ErrorBudgetWindow, cfg.ErrorBudgetCooldown)
+	errorBudget.SetThreshold(errbudget.SourceTelegram, cfg.ErrorBudgetTelegram)
+	errorBudget.SetThreshold(errbudget.SourceDatabase, cfg.ErrorBudgetDatabase)
+	errorBudget.SetThreshold(errbudget.SourcePanic, cfg.ErrorBudgetPanics)
+
 	// Connect to Database
-	client, db, err := connectDatabase(cfg)
+	client, db, err := connectDatabase(cfg, options.Client().SetMonitor(errorBudget.CommandMonitor()))
 	if err != nil {
 		sentry.CaptureException(err) // Capture connection error
 		log.Fatal(err)
@@ -156,10 +461,21 @@ func main() {
 	}()
 
 	// Create Repositories
-	suggestionRepo, userActionLogger, postLogger, userRepo, feedbackRepo := createRepositories(db)
+	primaryScope := primaryChannelScope(cfg)
+	suggestionRepo, userActionLogger, postLogger, userRepo, feedbackRepo, membershipRepo := createRepositories(db, primaryScope)
+
+	// Buffer post logs and new suggestions while MongoDB is unavailable
+	dbMonitor, writeBuffer, bufferedSuggestionRepo, postLogger, err := setupDegradedMode(cfg, client, suggestionRepo, postLogger)
+	if err != nil {
+		sentry.CaptureException(err)
+		log.Fatal(err)
+	}
+	suggestionRepo = bufferedSuggestionRepo
 
 	// Create Media Group Manager
 	mediaGroupMgr := mediagroups.NewManager()
+	mediaGroupMgr.SetLimits(cfg.MediaGroupDelay, cfg.MediaGroupMaxSize)
+	mediaGroupMgr.SetIdleDelay(cfg.MediaGroupIdleDelay)
 
 	// Creating application lifecycle context
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
@@ -167,9 +483,11 @@ func main() {
 
 	// --- Bot Initialization ---
 	// 1. Create the raw telego bot instance
-	botOpts := []telego.BotOption{telego.WithDefaultLogger(false, false)}
-	if cfg.Debug {
-		botOpts = []telego.BotOption{telego.WithDefaultDebugLogger()}
+	// Debug logging starts as DEBUG says and can be flipped at runtime with /debug
+	debugSwitch := debugmode.New(cfg.Debug)
+	botOpts := []telego.BotOption{
+		telego.WithLogger(debugSwitch.Logger(cfg.BotToken)),
+		telego.WithAPICaller(errorBudget.TelegramCaller(ta.FastHTTPCaller{Client: &fasthttp.Client{}})),
 	}
 	bot, err := telego.NewBot(cfg.BotToken, botOpts...)
 	if err != nil {
@@ -177,28 +495,133 @@ func main() {
 		log.Fatalf("Failed to create telego bot: %v", err)
 	}
 
+	// In dry-run mode nothing is sent to, copied into or deleted from the channel
+	var botAPI telegoapi.BotAPI = bot
+	if cfg.DryRun {
+		log.Printf("DRY_RUN is enabled: channel %d will not be modified", cfg.ChannelID)
+		botAPI = telegoapi.NewDryRunBot(bot, cfg.ChannelID)
+	}
+	// Messages to the test user of /simulate are shown in the admin's chat instead
+	simulationBot := simulation.NewBot(botAPI)
+	botAPI = simulationBot
+
+	// Fail fast on a token, channel, database or locale problem instead of on the first update
+	preflightReport := preflight.Run(ctx, cfg, botAPI, database.NewPinger(client))
+	logPreflightReport(preflightReport)
+	if failed := preflightReport.Count(preflight.Error); failed > 0 {
+		sentry.CaptureException(fmt.Errorf("startup checks failed: %d errors", failed))
+		log.Fatalf("Startup checks failed with %d errors, see above. Run check-config to re-check.", failed)
+	}
+
+	// Significant runtime failures are recorded for /incidents
+	incidentRepo := database.NewIncidentRepository(db)
+	incidentRecorder := incidents.NewRecorder(incidentRepo)
+
+	// Telegram refuses getUpdates while a webhook is set, so a leftover one is removed
+	resetWebhook(ctx, bot, incidentRecorder)
+
 	// 1.5 Get updates channel BEFORE creating components that need the BotAPI interface
-	updatesChan, err := bot.UpdatesViaLongPolling(ctx, nil)
+	// Only request the update types the bot actually processes
+	updateTypes := allowedUpdates(cfg)
+	log.Printf("Requesting update types: %v", updateTypes)
+	updatesChan, err := bot.UpdatesViaLongPolling(ctx, &telego.GetUpdatesParams{AllowedUpdates: updateTypes})
 	if err != nil {
 		log.Fatalf("Failed to get updates channel: %v", err)
 	}
 
 	// 2. Setup Core Bot Components (Checker, Manager, Handler)
 	// Pass the concrete *telego.Bot to components that need it for specific methods
+	// All channel publications go through one paced worker to avoid flood limits
+	publishQueue := publisher.NewQueue(cfg.PublishMinInterval)
+	publishQueue.SetStore(database.NewChannelPublicationRepository(db, primaryScope)) // Skip content that was already published
+	// Publications are refused up front, with an explicit error, once the bot can't post to the channel
+	channelRights := channelrights.NewChecker(botAPI, cfg.ChannelID, cfg.ChannelRightsCacheTTL)
+	channelRights.SetAlertFunc(channelRightsAlert(botAPI, cfg))
+	publishQueue.SetPreflight(channelRights.Check)
+	publishQueue.SetFailureFunc(func(key string, err error) {
+		if telegoapi.IsForbidden(err) {
+			channelRights.Invalidate() // The rights may have changed since they were checked
+		}
+		go incidentRecorder.Record(models.Incident{
+			Type:    models.IncidentTypePublishFailure,
+			Summary: fmt.Sprintf("Publishing %s failed: %v", key, err),
+		})
+	})
+	// Hot data is cached in memory, or in Redis to share it between instances
+	appCache, err := newCache(ctx, cfg)
+	if err != nil {
+		sentry.CaptureException(err)
+		log.Fatal(err)
+	}
 	_, suggestionManager, messageHandler, err := setupBotComponents(
-		cfg, bot, suggestionRepo, userActionLogger, postLogger, userRepo, feedbackRepo, mediaGroupMgr,
+		cfg, bot, botAPI, suggestionRepo, userActionLogger, postLogger, userRepo, feedbackRepo, membershipRepo, mediaGroupMgr, publishQueue, appCache,
 	)
 	if err != nil {
 		sentry.CaptureException(err)
 		log.Fatalf("Failed to setup bot components: %v", err)
 	}
+	messageHandler.SetDatabasePinger(database.NewPinger(client))
+	// Suggesters whose buffered suggestion lost its reference code are told the new one
+	bufferedSuggestionRepo.SetRefCodeChangedFunc(suggestionManager.NotifyRefCodeChanged)
+	settingsRepo := cache.NewSettingsRepository(database.NewChannelSettingsRepository(db, primaryScope), appCache, cfg.SettingsCacheTTL)
+	messageHandler.SetSettingsRepository(settingsRepo)    // Greeting and caption styles set with /setgreeting and /style
+	suggestionManager.SetSettingsRepository(settingsRepo) // Quiet hours and caption styles picked in review
+	footerRotator := footers.NewRotator(cfg.HashtagFooters, settingsRepo)
+	suggestionManager.SetFooterRotator(footerRotator)
+	// The default language, credit and content protection settings chosen in /setup override the environment
+	messageHandler.SetChannelRights(channelRights)
+	messageHandler.SetCreditDefaults(cfg.CreditSuggesters, cfg.CreditForwardSource)
+	messageHandler.SetProtectContentDefault(cfg.ProtectContent)
+	contentPolicy, _ := suggestions.ParseContentPolicy(cfg.SuggestionContentTypes) // Validated by the config
+	messageHandler.SetContentPolicyDefault(contentPolicy)
+	messageHandler.SetAutoRejectRulesDefault(suggestionManager.AutoRejectRules()) // From AUTO_REJECT_*
+	messageHandler.ApplySetupSettings(ctx)
+	messageHandler.SetStatsRepository(database.NewChannelStatsRepository(db, primaryScope))
+	rubricRepo := database.NewChannelRubricRepository(db, primaryScope)
+	messageHandler.SetRubricRepository(rubricRepo)
+	suggestionManager.SetRubricRepository(rubricRepo)
+	engagementPrompter := engagement.NewPrompter(botAPI, cfg.ChannelID, rubricRepo)
+	messageHandler.SetEngagementPrompter(engagementPrompter)
+	suggestionManager.SetEngagementPrompter(engagementPrompter)
+	messageHandler.SetThrowbackRepository(database.NewThrowbackRepository(db))
+	messageHandler.SetIncidentRepository(incidentRepo)
+	messageHandler.SetExperimentRepository(database.NewChannelExperimentRepository(db, primaryScope), cfg.CaptionTestWindow)
+	messageHandler.SetPinRepository(database.NewChannelPinRepository(db, primaryScope))
+	messageHandler.SetPermissionRepository(database.NewChannelPermissionRepository(db, primaryScope))
+	wordFilterRepo := database.NewChannelWordFilterRepository(db, primaryScope)
+	messageHandler.SetWordFilterRepository(wordFilterRepo)    // Screens admin posts
+	suggestionManager.SetWordFilterRepository(wordFilterRepo) // Screens suggestion captions
+	// Admin pings, quiet hours summaries and intake receipts are stored before they are sent
+	notificationOutbox := outbox.New(database.NewOutboxRepository(db), botAPI)
+	notificationOutbox.SetMaxAttempts(cfg.OutboxMaxAttempts)
+	suggestionManager.SetNotifier(notificationOutbox)
+	messageHandler.SetOutbox(notificationOutbox)
+	// /trace mirrors raw updates to the alert chat, or to the chat it was sent in
+	messageHandler.SetDebugSwitch(debugSwitch, cfg.AlertChatID)
+	// Multi-collection writes run in transactions when MongoDB is a replica set
+	suggestionManager.SetUnitOfWork(database.NewUnitOfWork(client))
+
+	// Duplicate reference codes are only detected with the unique index, so don't serve without it
+	if _, err := database.EnsureUniqueIndexes(ctx, db); err != nil {
+		sentry.CaptureException(err)
+		log.Fatalf("%v (run the migrate subcommand to give existing suggestions unique reference codes)", err)
+	}
+
+	// Optionally remember processed update IDs in MongoDB so resent updates are skipped after restarts
+	var updateStore database.ProcessedUpdateStore
+	if cfg.PersistUpdateIDs {
+		updateStore = database.NewProcessedUpdateRepository(db)
+		if err := updateStore.EnsureRetention(ctx, cfg.UpdateIDRetention); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
 
 	// 3. Create the Bot Application Wrapper
 	// Pass the updatesChan to BotDeps
 	appBotDeps := telegoBot.BotDeps{
-		Bot:           bot,         // Pass the concrete bot as BotAPI
+		Bot:           botAPI,      // Pass the (possibly dry-run) bot as BotAPI
 		UpdatesChan:   updatesChan, // Pass the channel
-		Debug:         cfg.Debug,
+		Debug:         debugSwitch,
 		ChannelID:     cfg.ChannelID,
 		CaptionProv:   messageHandler, // Assuming MessageHandler implements CaptionProvider
 		PostLogger:    postLogger,
@@ -209,12 +632,106 @@ func main() {
 		ActionLogger:  userActionLogger,
 		MediaGroupMgr: mediaGroupMgr,
 		Handler:       messageHandler, // Pass concrete handler
+		PublishQueue:  publishQueue,
+		Timeouts: telegoBot.UpdateTimeouts{
+			Message:       cfg.MessageTimeout,
+			CallbackQuery: cfg.CallbackQueryTimeout,
+			ChatMember:    cfg.ChatMemberTimeout,
+			MediaGroup:    cfg.MediaGroupTimeout,
+		},
+		UpdateStore: updateStore,
+		ErrorBudget: errorBudget,
+		Incidents:   incidentRecorder,
+		Groups:      groupPolicy(cfg),
 	}
 	appBot, err := telegoBot.New(appBotDeps)
 	if err != nil {
 		sentry.CaptureException(err)
 		log.Fatalf("Failed to create application bot wrapper: %v", err)
 	}
+	messageHandler.SetSimulator(simulation.NewSimulator(simulationBot, appBot.Inject))
+
+	// Serve the channels connected with /connect from the same process
+	if cfg.MultiTenant {
+		tenantStack := tenantComponents{
+			cfg:            cfg,
+			db:             db,
+			bot:            bot,
+			updatesChan:    updatesChan,
+			postLogger:     postLogger,
+			userRepo:       userRepo,
+			membershipRepo: membershipRepo,
+			appCache:       appCache,
+			notifier:       notificationOutbox,
+			errorBudget:    errorBudget,
+			incidents:      incidentRecorder,
+			debug:          debugSwitch,
+		}
+		tenantRegistry := tenants.NewRegistry(cfg.ChannelID, database.NewTenantRepository(db), func(ctx context.Context, tenant models.Tenant) error {
+			tenantBot, err := tenantStack.start(ctx, tenant)
+			if err != nil {
+				return err
+			}
+			appBot.AddTenant(tenant.ChannelID, tenantBot)
+			go func() {
+				<-ctx.Done() // The channel was disconnected, or the bot is shutting down
+				appBot.RemoveTenant(tenant.ChannelID)
+			}()
+			return nil
+		})
+		tenantRegistry.SetDefaultQuota(models.TenantQuota{
+			MaxPendingSuggestions: cfg.TenantMaxPendingSuggestions,
+			UpdatesPerMinute:      cfg.TenantUpdatesPerMinute,
+		})
+		tenantStack.registry = tenantRegistry
+		messageHandler.SetTenants(tenantRegistry)
+		appBot.SetTenantRouter(tenantRegistry)
+		if err := tenantRegistry.Load(ctx); err != nil {
+			sentry.CaptureException(err)
+			log.Printf("Warning: failed to load connected channels: %v", err)
+		}
+	}
+
+	// Alert super admins about database outages and replay buffered writes when it is back
+	dbMonitor.SetAlertFunc(degradedModeAlert(botAPI, cfg.SuperAdminIDs, incidentRecorder))
+	errorBudget.SetAlertFunc(errorBudgetAlert(botAPI, cfg))
+	dbMonitor.Start(ctx)
+
+	// Expire inactive review sessions in the background
+	suggestionManager.StartReviewSessionJanitor(ctx)
+	// Send admins the notifications held back during their quiet hours
+	suggestionManager.StartQuietHoursSummaries(ctx)
+	// Hand suggestions left untouched by their assignee to the next admin
+	suggestionManager.StartAssignmentReassigner(ctx)
+	suggestionManager.StartExpiryJanitor(ctx)
+	// Measure the reactions of /abtest posts once their window has passed
+	messageHandler.StartCaptionExperimentMeasurements(ctx)
+	// Unpin /pin posts whose time is up and pin the queued ones in their place
+	messageHandler.StartPinScheduler(ctx, botAPI)
+	publishQueue.Start(ctx)
+	notificationOutbox.Start(ctx)
+
+	// Keep the pinned dashboard in the admin chat up to date
+	if cfg.DashboardChatID != 0 {
+		dashboard.NewUpdater(botAPI, cfg.DashboardChatID, cfg.ChannelID, settingsRepo, suggestionRepo,
+			database.NewChannelStatsRepository(db, primaryScope), publishQueue, errorBudget, cfg.TimeZone, cfg.DashboardInterval).Start(ctx)
+	}
+
+	// Publish files dropped into the staging directory by automated pipelines
+	if cfg.StagingDir != "" {
+		stagingWatcher := staging.NewWatcher(cfg.StagingDir, cfg.StagingPollInterval, cfg.MaxPostFileSize, botAPI, cfg.ChannelID, publishQueue, postLogger)
+		stagingWatcher.SetFooterRotator(footerRotator)
+		if err := stagingWatcher.Start(ctx); err != nil {
+			sentry.CaptureException(err)
+			log.Printf("Warning: staging directory disabled: %v", err)
+		}
+	}
+
+	// Register the command menus for everyone, private chats and each admin
+	if err := messageHandler.SyncCommandMenus(ctx, botAPI); err != nil {
+		sentry.CaptureException(err)
+		log.Printf("Warning: failed to register command menus: %v", err)
+	}
 
 	// Start the bot wrapper's processing loop
 	go appBot.Start(ctx)
@@ -232,5 +749,12 @@ func main() {
 		mediaGroupMgr.Shutdown()
 	}
 
+	// Keep writes still waiting for the database for the next run
+	if err := writeBuffer.Spill(); err != nil {
+		sentry.CaptureException(err)
+		log.Printf("Error saving buffered writes: %v", err)
+	}
+
 	// Disconnect from MongoDB using the application context
+	return nil
 }
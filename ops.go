@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+	"vrcmemes-bot/internal/config"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/history"
+	"vrcmemes-bot/internal/preflight"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	telegoBot "vrcmemes-bot/bot"
+
+	telego "github.com/mymmrac/telego"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// runMigrate creates the MongoDB indexes, gives older suggestions a reference code, and creates
+// the TTL index of processed update IDs when PERSIST_UPDATE_IDS is enabled.
+func runMigrate(cmd command, args []string) error {
+	fs := newFlagSet(cmd)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	return withDatabase(cfg, func(ctx context.Context, db *mongo.Database) error {
+		names, err := database.EnsureIndexes(ctx, db)
+		for _, name := range names {
+			fmt.Printf("Index %s is in place\n", name)
+		}
+		if err != nil {
+			return err
+		}
+		backfilled, err := database.BackfillRefCodes(ctx, db)
+		if backfilled > 0 {
+			fmt.Printf("Gave %d suggestions a reference code\n", backfilled)
+		}
+		if err != nil {
+			return err
+		}
+		if cfg.PersistUpdateIDs {
+			if err := database.NewProcessedUpdateRepository(db).EnsureRetention(ctx, cfg.UpdateIDRetention); err != nil {
+				return err
+			}
+			fmt.Printf("Processed update IDs expire after %s\n", cfg.UpdateIDRetention)
+		}
+		return nil
+	})
+}
+
+// runExport writes collections to <dir>/<collection>.jsonl, all of them if none are named.
+func runExport(cmd command, args []string) error {
+	fs := newFlagSet(cmd)
+	dir := fs.String("dir", "export-"+time.Now().Format("20060102-150405"), "directory to write the files to")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	return withDatabase(cfg, func(ctx context.Context, db *mongo.Database) error {
+		collections := fs.Args()
+		if len(collections) == 0 {
+			if collections, err = db.ListCollectionNames(ctx, bson.D{}); err != nil {
+				return fmt.Errorf("failed to list collections: %w", err)
+			}
+			sort.Strings(collections)
+		}
+		if err := os.MkdirAll(*dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create export directory: %w", err)
+		}
+		for _, collection := range collections {
+			path := filepath.Join(*dir, collection+".jsonl")
+			file, err := os.Create(path)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", path, err)
+			}
+			count, err := database.ExportCollection(ctx, db, collection, file)
+			if closeErr := file.Close(); err == nil && closeErr != nil {
+				err = fmt.Errorf("failed to write %s: %w", path, closeErr)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Exported %d documents of %s to %s\n", count, collection, path)
+		}
+		return nil
+	})
+}
+
+// runImportHistory logs the posts of a Telegram Desktop export of the channel, so reports
+// and /throwback also cover posts published before the bot logged them.
+func runImportHistory(cmd command, args []string) error {
+	fs := newFlagSet(cmd)
+	dryRun := fs.Bool("dry-run", false, "only parse the export and report how many posts it has")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError("expected the path of the export's result.json")
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to open export: %w", err)
+	}
+	logs, err := history.ReadExport(file, cfg.ChannelID)
+	_ = file.Close()
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		fmt.Printf("The export has %d posts\n", len(logs))
+		return nil
+	}
+	return withDatabase(cfg, func(ctx context.Context, db *mongo.Database) error {
+		imported, err := database.NewHistoryRepository(db).ImportPostLogs(ctx, logs)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Imported %d of %d posts, %d were already logged\n", imported, len(logs), len(logs)-imported)
+		return nil
+	})
+}
+
+// runCheckConfig loads the configuration, which validates it, prints a summary and runs the
+// startup checks of serve: bot token, channel rights, MongoDB, Sentry DSN and locales.
+// With -offline, the checks that need Telegram or MongoDB are skipped.
+func runCheckConfig(cmd command, args []string) error {
+	fs := newFlagSet(cmd)
+	offline := fs.Bool("offline", false, "skip the checks that connect to Telegram and MongoDB")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Environment\t%s\n", cfg.AppEnv)
+	fmt.Fprintf(w, "Bot\t%s\n", botIDFromToken(cfg.BotToken))
+	fmt.Fprintf(w, "Channel\t%d\n", cfg.ChannelID)
+	fmt.Fprintf(w, "Database\t%s\n", cfg.MongoDBDatabase)
+	fmt.Fprintf(w, "Default language\t%s\n", cfg.DefaultLanguage)
+	fmt.Fprintf(w, "Time zone\t%s\n", cfg.TimeZone)
+	fmt.Fprintf(w, "Update types\t%s\n", strings.Join(allowedUpdates(cfg), ", "))
+	fmt.Fprintf(w, "Super admins\t%d\n", len(cfg.SuperAdminIDs))
+	fmt.Fprintf(w, "Cache\t%s\n", cfg.CacheBackend)
+	fmt.Fprintf(w, "Dry run\t%t\n", cfg.DryRun)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Println()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var bot telegoapi.BotAPI
+	var pinger database.Pinger
+	if !*offline {
+		if bot, err = telego.NewBot(cfg.BotToken, telego.WithDefaultLogger(false, false)); err != nil {
+			bot = nil
+			fmt.Printf("[%s] Bot token: %v\n\n", preflight.Error, err)
+		}
+		client, _, err := database.ConnectDB(cfg)
+		if err != nil {
+			pinger = pingerFunc(func(context.Context) error { return err })
+		} else {
+			defer func() { _ = client.Disconnect(context.Background()) }()
+			pinger = database.NewPinger(client)
+		}
+	}
+	report := preflight.Run(ctx, cfg, bot, pinger)
+	report.Print(os.Stdout)
+
+	if failed := report.Count(preflight.Error); failed > 0 {
+		return fmt.Errorf("%d check(s) failed, %d warning(s)", failed, report.Count(preflight.Warning))
+	}
+	fmt.Printf("\nConfiguration is valid, %d warning(s)\n", report.Count(preflight.Warning))
+	return nil
+}
+
+// pingerFunc adapts a function to database.Pinger.
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) Ping(ctx context.Context) error { return f(ctx) }
+
+// runSetWebhook sets the bot's webhook to a URL, or deletes or shows it. The bot itself uses
+// long polling, which Telegram refuses while a webhook is set: delete it before running serve.
+func runSetWebhook(cmd command, args []string) error {
+	fs := newFlagSet(cmd)
+	remove := fs.Bool("delete", false, "delete the webhook instead of setting it")
+	info := fs.Bool("info", false, "show the current webhook")
+	secret := fs.String("secret", "", "secret token Telegram sends in the X-Telegram-Bot-Api-Secret-Token header")
+	dropPending := fs.Bool("drop-pending", false, "drop the updates waiting to be delivered")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	var webhookURL *url.URL
+	if *remove || *info {
+		if fs.NArg() != 0 {
+			return usageError("no URL is expected with -delete or -info")
+		}
+	} else {
+		if fs.NArg() != 1 {
+			return usageError("expected the webhook URL")
+		}
+		parsed, err := url.Parse(fs.Arg(0))
+		if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			return usageError(fmt.Sprintf("webhook URL %q must be an absolute https URL", fs.Arg(0)))
+		}
+		webhookURL = parsed
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	bot, err := telego.NewBot(cfg.BotToken, telego.WithDefaultLogger(false, false))
+	if err != nil {
+		return fmt.Errorf("invalid bot token: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	switch {
+	case *info:
+		webhook, err := bot.GetWebhookInfo(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get webhook info: %w", err)
+		}
+		if webhook.URL == "" {
+			fmt.Println("No webhook is set: the bot receives updates with long polling")
+			return nil
+		}
+		fmt.Printf("Webhook: %s\nPending updates: %d\n", webhook.URL, webhook.PendingUpdateCount)
+		if webhook.LastErrorMessage != "" {
+			fmt.Printf("Last error (%s): %s\n", time.Unix(webhook.LastErrorDate, 0).Format(time.RFC3339), webhook.LastErrorMessage)
+		}
+		return nil
+
+	case *remove:
+		if err := bot.DeleteWebhook(ctx, &telego.DeleteWebhookParams{DropPendingUpdates: *dropPending}); err != nil {
+			return fmt.Errorf("failed to delete webhook: %w", err)
+		}
+		fmt.Println("Webhook deleted: the bot can receive updates with long polling again")
+		return nil
+	}
+
+	err = bot.SetWebhook(ctx, &telego.SetWebhookParams{
+		URL:                webhookURL.String(),
+		AllowedUpdates:     allowedUpdates(cfg),
+		DropPendingUpdates: *dropPending,
+		SecretToken:        *secret,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+	fmt.Printf("Webhook set to %s. Run '%s set-webhook -delete' before serve, which uses long polling\n", webhookURL, programName())
+	return nil
+}
+
+// allowedUpdates returns the update types the bot requests from Telegram.
+func allowedUpdates(cfg *config.Config) []string {
+	return telegoBot.AllowedUpdates(
+		telegoBot.UpdateCapabilities{ChatMembers: cfg.TrackChatMembers, Reactions: cfg.TrackReactions},
+		cfg.AllowedUpdates,
+	)
+}
+
+// botIDFromToken returns the bot ID part of a token, so the summary doesn't print the secret.
+func botIDFromToken(token string) string {
+	id, _, found := strings.Cut(token, ":")
+	if !found {
+		return "(malformed token)"
+	}
+	return id
+}
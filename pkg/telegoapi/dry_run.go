@@ -0,0 +1,180 @@
+package telegoapi
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/mymmrac/telego"
+)
+
+// DryRunBot wraps a BotAPI and skips every call that would change the channel
+// (sending, copying, editing or deleting messages there), logging it instead. Calls for
+// other chats, such as replies to admins, go through unchanged, so the bot can be
+// exercised against production data without touching the channel.
+// Skipped sends return placeholder messages with negative IDs.
+type DryRunBot struct {
+	BotAPI
+	channelID int64
+	lastID    atomic.Int64
+}
+
+// NewDryRunBot creates a BotAPI that doesn't modify the given channel.
+func NewDryRunBot(bot BotAPI, channelID int64) *DryRunBot {
+	return &DryRunBot{BotAPI: bot, channelID: channelID}
+}
+
+// isChannel reports whether a call targets the protected channel.
+func (d *DryRunBot) isChannel(chatID telego.ChatID) bool {
+	return chatID.ID == d.channelID
+}
+
+// placeholder returns a fake channel message standing in for one that was not sent.
+func (d *DryRunBot) placeholder() telego.Message {
+	return telego.Message{
+		MessageID: int(d.lastID.Add(-1)),
+		Date:      time.Now().Unix(),
+		Chat:      telego.Chat{ID: d.channelID, Type: telego.ChatTypeChannel},
+	}
+}
+
+// SendMessage skips text messages to the channel.
+func (d *DryRunBot) SendMessage(ctx context.Context, params *telego.SendMessageParams) (*telego.Message, error) {
+	if !d.isChannel(params.ChatID) {
+		return d.BotAPI.SendMessage(ctx, params)
+	}
+	msg := d.placeholder()
+	log.Printf("[DryRun] Skipped SendMessage to channel %d (%d chars), placeholder ID %d", d.channelID, len(params.Text), msg.MessageID)
+	return &msg, nil
+}
+
+// SendPhoto skips photos sent to the channel.
+func (d *DryRunBot) SendPhoto(ctx context.Context, params *telego.SendPhotoParams) (*telego.Message, error) {
+	if !d.isChannel(params.ChatID) {
+		return d.BotAPI.SendPhoto(ctx, params)
+	}
+	msg := d.placeholder()
+	log.Printf("[DryRun] Skipped SendPhoto to channel %d, placeholder ID %d", d.channelID, msg.MessageID)
+	return &msg, nil
+}
+
+// SendVideo skips videos sent to the channel.
+func (d *DryRunBot) SendVideo(ctx context.Context, params *telego.SendVideoParams) (*telego.Message, error) {
+	if !d.isChannel(params.ChatID) {
+		return d.BotAPI.SendVideo(ctx, params)
+	}
+	msg := d.placeholder()
+	log.Printf("[DryRun] Skipped SendVideo to channel %d, placeholder ID %d", d.channelID, msg.MessageID)
+	return &msg, nil
+}
+
+// SendAnimation skips GIFs sent to the channel.
+func (d *DryRunBot) SendAnimation(ctx context.Context, params *telego.SendAnimationParams) (*telego.Message, error) {
+	if !d.isChannel(params.ChatID) {
+		return d.BotAPI.SendAnimation(ctx, params)
+	}
+	msg := d.placeholder()
+	log.Printf("[DryRun] Skipped SendAnimation to channel %d, placeholder ID %d", d.channelID, msg.MessageID)
+	return &msg, nil
+}
+
+// SendDocument skips documents sent to the channel.
+func (d *DryRunBot) SendDocument(ctx context.Context, params *telego.SendDocumentParams) (*telego.Message, error) {
+	if !d.isChannel(params.ChatID) {
+		return d.BotAPI.SendDocument(ctx, params)
+	}
+	msg := d.placeholder()
+	log.Printf("[DryRun] Skipped SendDocument to channel %d, placeholder ID %d", d.channelID, msg.MessageID)
+	return &msg, nil
+}
+
+// SendMediaGroup skips albums sent to the channel.
+func (d *DryRunBot) SendMediaGroup(ctx context.Context, params *telego.SendMediaGroupParams) ([]telego.Message, error) {
+	if !d.isChannel(params.ChatID) {
+		return d.BotAPI.SendMediaGroup(ctx, params)
+	}
+	msgs := make([]telego.Message, len(params.Media))
+	for i := range msgs {
+		msgs[i] = d.placeholder()
+	}
+	log.Printf("[DryRun] Skipped SendMediaGroup to channel %d (%d items)", d.channelID, len(params.Media))
+	return msgs, nil
+}
+
+// CopyMessage skips copies into the channel.
+func (d *DryRunBot) CopyMessage(ctx context.Context, params *telego.CopyMessageParams) (*telego.MessageID, error) {
+	if !d.isChannel(params.ChatID) {
+		return d.BotAPI.CopyMessage(ctx, params)
+	}
+	msg := d.placeholder()
+	log.Printf("[DryRun] Skipped CopyMessage of message %d from chat %d to channel %d, placeholder ID %d", params.MessageID, params.FromChatID.ID, d.channelID, msg.MessageID)
+	return &telego.MessageID{MessageID: msg.MessageID}, nil
+}
+
+// CopyMessages skips copies of several messages into the channel.
+func (d *DryRunBot) CopyMessages(ctx context.Context, params *telego.CopyMessagesParams) ([]telego.MessageID, error) {
+	if !d.isChannel(params.ChatID) {
+		return d.BotAPI.CopyMessages(ctx, params)
+	}
+	ids := make([]telego.MessageID, len(params.MessageIDs))
+	for i := range ids {
+		ids[i] = telego.MessageID{MessageID: d.placeholder().MessageID}
+	}
+	log.Printf("[DryRun] Skipped CopyMessages of %d messages from chat %d to channel %d", len(params.MessageIDs), params.FromChatID.ID, d.channelID)
+	return ids, nil
+}
+
+// DeleteMessage skips deletions in the channel.
+func (d *DryRunBot) DeleteMessage(ctx context.Context, params *telego.DeleteMessageParams) error {
+	if !d.isChannel(params.ChatID) {
+		return d.BotAPI.DeleteMessage(ctx, params)
+	}
+	log.Printf("[DryRun] Skipped DeleteMessage of message %d in channel %d", params.MessageID, d.channelID)
+	return nil
+}
+
+// SetMessageReaction skips reacting to channel messages.
+func (d *DryRunBot) SetMessageReaction(ctx context.Context, params *telego.SetMessageReactionParams) error {
+	if !d.isChannel(params.ChatID) {
+		return d.BotAPI.SetMessageReaction(ctx, params)
+	}
+	log.Printf("[DryRun] Skipped SetMessageReaction of message %d in channel %d", params.MessageID, d.channelID)
+	return nil
+}
+
+// PinChatMessage skips pinning channel messages.
+func (d *DryRunBot) PinChatMessage(ctx context.Context, params *telego.PinChatMessageParams) error {
+	if !d.isChannel(params.ChatID) {
+		return d.BotAPI.PinChatMessage(ctx, params)
+	}
+	log.Printf("[DryRun] Skipped PinChatMessage of message %d in channel %d", params.MessageID, d.channelID)
+	return nil
+}
+
+// UnpinChatMessage skips unpinning channel messages.
+func (d *DryRunBot) UnpinChatMessage(ctx context.Context, params *telego.UnpinChatMessageParams) error {
+	if !d.isChannel(params.ChatID) {
+		return d.BotAPI.UnpinChatMessage(ctx, params)
+	}
+	log.Printf("[DryRun] Skipped UnpinChatMessage of message %d in channel %d", params.MessageID, d.channelID)
+	return nil
+}
+
+// EditMessageText skips edits of channel messages.
+func (d *DryRunBot) EditMessageText(ctx context.Context, params *telego.EditMessageTextParams) (*telego.Message, error) {
+	if !d.isChannel(params.ChatID) {
+		return d.BotAPI.EditMessageText(ctx, params)
+	}
+	log.Printf("[DryRun] Skipped EditMessageText of message %d in channel %d", params.MessageID, d.channelID)
+	return &telego.Message{MessageID: params.MessageID, Chat: telego.Chat{ID: d.channelID, Type: telego.ChatTypeChannel}, Text: params.Text}, nil
+}
+
+// EditMessageReplyMarkup skips edits of channel messages.
+func (d *DryRunBot) EditMessageReplyMarkup(ctx context.Context, params *telego.EditMessageReplyMarkupParams) (*telego.Message, error) {
+	if !d.isChannel(params.ChatID) {
+		return d.BotAPI.EditMessageReplyMarkup(ctx, params)
+	}
+	log.Printf("[DryRun] Skipped EditMessageReplyMarkup of message %d in channel %d", params.MessageID, d.channelID)
+	return &telego.Message{MessageID: params.MessageID, Chat: telego.Chat{ID: d.channelID, Type: telego.ChatTypeChannel}}, nil
+}
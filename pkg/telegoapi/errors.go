@@ -0,0 +1,99 @@
+package telegoapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	ta "github.com/mymmrac/telego/telegoapi"
+)
+
+// ErrNoPostingRights is returned for publications refused before sending because the bot can't
+// post to the channel (anymore).
+var ErrNoPostingRights = errors.New("the bot has no right to post to channel")
+
+// defaultRetryAfter is used when a 429 response doesn't say how long to wait.
+const defaultRetryAfter = 5 * time.Second
+
+// APIError returns the Telegram Bot API error wrapped in err, if there is one.
+// telego wraps API responses with %w, so errors.As finds them through any wrapping.
+func APIError(err error) (*ta.Error, bool) {
+	var apiErr *ta.Error
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// ErrorCode returns the Bot API error code of err, or 0 if err is not an API error.
+func ErrorCode(err error) int {
+	if apiErr, ok := APIError(err); ok {
+		return apiErr.ErrorCode
+	}
+	return 0
+}
+
+// RetryAfter reports whether err is a 429 Too Many Requests response and how long to wait before retrying,
+// taken from the response's parameters.retry_after.
+func RetryAfter(err error) (time.Duration, bool) {
+	apiErr, ok := APIError(err)
+	if !ok || apiErr.ErrorCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if apiErr.Parameters != nil && apiErr.Parameters.RetryAfter > 0 {
+		return time.Duration(apiErr.Parameters.RetryAfter) * time.Second, true
+	}
+	return defaultRetryAfter, true
+}
+
+// IsForbidden reports whether err is a 403 response, e.g. the user blocked the bot
+// or the bot lacks rights in the chat.
+func IsForbidden(err error) bool {
+	return ErrorCode(err) == http.StatusForbidden
+}
+
+// IsRejected reports whether err is a 4xx response, i.e. Telegram received the request and refused
+// it. Transport errors, timeouts and 5xx responses leave it unknown whether the request took effect.
+func IsRejected(err error) bool {
+	code := ErrorCode(err)
+	return code >= http.StatusBadRequest && code < http.StatusInternalServerError
+}
+
+// IsUserNotFound reports whether err is a 400 response saying the user is not in the chat.
+// The Bot API has no finer error codes, so the description of the typed error is checked.
+func IsUserNotFound(err error) bool {
+	apiErr, ok := APIError(err)
+	return ok && apiErr.ErrorCode == http.StatusBadRequest &&
+		strings.Contains(strings.ToLower(apiErr.Description), "user not found")
+}
+
+// IsMessageNotFound reports whether err is a 400 response saying the message to edit or delete
+// doesn't exist (anymore).
+func IsMessageNotFound(err error) bool {
+	apiErr, ok := APIError(err)
+	return ok && apiErr.ErrorCode == http.StatusBadRequest &&
+		strings.Contains(strings.ToLower(apiErr.Description), "message to edit not found")
+}
+
+// IsMessageNotModified reports whether err is a 400 response saying an edit left the message as it was.
+func IsMessageNotModified(err error) bool {
+	apiErr, ok := APIError(err)
+	return ok && apiErr.ErrorCode == http.StatusBadRequest &&
+		strings.Contains(strings.ToLower(apiErr.Description), "message is not modified")
+}
+
+// UserMessageID maps an error to the locale message ID to show the user.
+// API errors with a specific cause get a specific message; anything else gets fallbackID.
+func UserMessageID(err error, fallbackID string) string {
+	switch {
+	case ErrorCode(err) == http.StatusTooManyRequests:
+		return "MsgErrorTelegramRateLimited"
+	case errors.Is(err, ErrNoPostingRights):
+		return "MsgErrorNoPostingRights"
+	case IsForbidden(err):
+		return "MsgErrorBotForbidden"
+	default:
+		return fallbackID
+	}
+}
@@ -12,7 +12,11 @@ type BotAPI interface {
 	SendMessage(ctx context.Context, params *telego.SendMessageParams) (*telego.Message, error)
 	GetMe(ctx context.Context) (*telego.User, error) // Used by some constructors/checks
 	CopyMessage(ctx context.Context, params *telego.CopyMessageParams) (*telego.MessageID, error)
+	// Used to promote preview posts, albums included, to the channel
+	CopyMessages(ctx context.Context, params *telego.CopyMessagesParams) ([]telego.MessageID, error)
 	SetMyCommands(ctx context.Context, params *telego.SetMyCommandsParams) error
+	// Used to drop the admin command menu of demoted admins
+	DeleteMyCommands(ctx context.Context, params *telego.DeleteMyCommandsParams) error
 	AnswerCallbackQuery(ctx context.Context, params *telego.AnswerCallbackQueryParams) error
 	SendMediaGroup(ctx context.Context, params *telego.SendMediaGroupParams) ([]telego.Message, error) // Added based on usage in bot/bot.go
 
@@ -20,5 +24,25 @@ type BotAPI interface {
 	GetChatMember(ctx context.Context, params *telego.GetChatMemberParams) (telego.ChatMember, error)
 	SendPhoto(ctx context.Context, params *telego.SendPhotoParams) (*telego.Message, error)
 	DeleteMessage(ctx context.Context, params *telego.DeleteMessageParams) error
-	// Add EditMessageMedia, EditMessageReplyMarkup if needed by review UI
+	SendVideo(ctx context.Context, params *telego.SendVideoParams) (*telego.Message, error) // Used for staged video posts
+	// Used to review and publish GIF suggestions
+	SendAnimation(ctx context.Context, params *telego.SendAnimationParams) (*telego.Message, error)
+	// Used to mark forwarded feedback resolved
+	EditMessageText(ctx context.Context, params *telego.EditMessageTextParams) (*telego.Message, error)
+	// Used by /diag
+	GetWebhookInfo(ctx context.Context) (*telego.WebhookInfo, error)
+	// Used to switch review messages to the tag picker and back
+	EditMessageReplyMarkup(ctx context.Context, params *telego.EditMessageReplyMarkupParams) (*telego.Message, error)
+	// Used by /suggestbutton to find the channel's discussion group and pin the button
+	GetChat(ctx context.Context, params *telego.GetChatParams) (*telego.ChatFullInfo, error)
+	PinChatMessage(ctx context.Context, params *telego.PinChatMessageParams) error
+	// Used to send /queue export snapshots
+	SendDocument(ctx context.Context, params *telego.SendDocumentParams) (*telego.Message, error)
+	// Used by /pin to unpin posts whose time is up
+	UnpinChatMessage(ctx context.Context, params *telego.UnpinChatMessageParams) error
+	// Used to leave groups the bot wasn't meant to be added to
+	LeaveChat(ctx context.Context, params *telego.LeaveChatParams) error
+	// Used to suggest a reaction under posts of rubrics with one
+	SetMessageReaction(ctx context.Context, params *telego.SetMessageReactionParams) error
+	// Add EditMessageMedia if needed by review UI
 }
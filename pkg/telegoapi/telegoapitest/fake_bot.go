@@ -0,0 +1,351 @@
+// Package telegoapitest provides an in-memory telegoapi.BotAPI and helpers for
+// driving the bot with synthetic updates in tests, without talking to Telegram.
+package telegoapitest
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	"github.com/mymmrac/telego"
+	ta "github.com/mymmrac/telego/telegoapi"
+)
+
+// Call is a single recorded Bot API call.
+type Call struct {
+	Method string // BotAPI method name, e.g. "SendMessage"
+	ChatID int64  // Target chat, or 0 for calls without one
+	Params any    // The params pointer passed to the method
+}
+
+// FakeBot is an in-memory telegoapi.BotAPI. It records every call, returns
+// plausible results with increasing message IDs, and can be told to fail the
+// next calls of a method (e.g. with a rate limit). It is safe for concurrent use.
+type FakeBot struct {
+	mu            sync.Mutex
+	calls         []Call
+	failures      map[string][]error // Errors to return from the next calls, per method
+	memberStatus  map[int64]string   // GetChatMember status per user
+	defaultStatus string
+	lastMessageID int
+	notify        chan struct{} // Closed and replaced whenever a call is recorded
+	me            telego.User
+}
+
+var _ telegoapi.BotAPI = (*FakeBot)(nil)
+
+// NewFakeBot creates a FakeBot. GetChatMember reports every user as a channel member
+// unless SetMemberStatus says otherwise.
+func NewFakeBot() *FakeBot {
+	return &FakeBot{
+		failures:      make(map[string][]error),
+		memberStatus:  make(map[int64]string),
+		defaultStatus: telego.MemberStatusMember,
+		notify:        make(chan struct{}),
+		me:            telego.User{ID: 1, IsBot: true, FirstName: "Test Bot", Username: "test_bot"},
+	}
+}
+
+// SetMemberStatus sets the status GetChatMember returns for a user (e.g. telego.MemberStatusLeft).
+func (f *FakeBot) SetMemberStatus(userID int64, status string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.memberStatus[userID] = status
+}
+
+// FailNext makes the next call of method return err. Repeated calls queue further failures.
+func (f *FakeBot) FailNext(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[method] = append(f.failures[method], err)
+}
+
+// RateLimitNext makes the next call of method fail with 429 Too Many Requests and the given retry_after.
+func (f *FakeBot) RateLimitNext(method string, retryAfter time.Duration) {
+	f.FailNext(method, RateLimitError(retryAfter))
+}
+
+// RateLimitError returns the error telego reports for a 429 Too Many Requests response.
+func RateLimitError(retryAfter time.Duration) error {
+	return APIError(http.StatusTooManyRequests, "Too Many Requests: retry later", int(retryAfter.Seconds()))
+}
+
+// APIError returns a Bot API error with the given code, description and retry_after seconds (0 for none).
+func APIError(code int, description string, retryAfterSeconds int) error {
+	apiErr := &ta.Error{ErrorCode: code, Description: description}
+	if retryAfterSeconds > 0 {
+		apiErr.Parameters = &ta.ResponseParameters{RetryAfter: retryAfterSeconds}
+	}
+	return apiErr
+}
+
+// Calls returns all recorded calls in order.
+func (f *FakeBot) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// CallsTo returns the recorded calls of method to chatID, in order.
+func (f *FakeBot) CallsTo(method string, chatID int64) []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var matched []Call
+	for _, c := range f.calls {
+		if c.Method == method && c.ChatID == chatID {
+			matched = append(matched, c)
+		}
+	}
+	return matched
+}
+
+// WaitForCall waits until method has been called for chatID at least once, for work
+// that happens in the background such as queued publications.
+func (f *FakeBot) WaitForCall(method string, chatID int64, timeout time.Duration) (Call, bool) {
+	deadline := time.After(timeout)
+	for {
+		f.mu.Lock()
+		for _, c := range f.calls {
+			if c.Method == method && c.ChatID == chatID {
+				f.mu.Unlock()
+				return c, true
+			}
+		}
+		notify := f.notify
+		f.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-deadline:
+			return Call{}, false
+		}
+	}
+}
+
+// Reset forgets all recorded calls.
+func (f *FakeBot) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+}
+
+// record stores a call and returns the queued failure for its method, if any.
+func (f *FakeBot) record(method string, chatID int64, params any) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, Call{Method: method, ChatID: chatID, Params: params})
+	close(f.notify)
+	f.notify = make(chan struct{})
+
+	if queued := f.failures[method]; len(queued) > 0 {
+		f.failures[method] = queued[1:]
+		return queued[0]
+	}
+	return nil
+}
+
+// newMessage returns a message with the next message ID, as Telegram would for a sent message.
+func (f *FakeBot) newMessage(chatID int64) telego.Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastMessageID++
+	return telego.Message{
+		MessageID: f.lastMessageID,
+		Date:      time.Now().Unix(),
+		Chat:      telego.Chat{ID: chatID},
+		From:      &f.me,
+	}
+}
+
+// SendMessage records the call and returns a sent message.
+func (f *FakeBot) SendMessage(ctx context.Context, params *telego.SendMessageParams) (*telego.Message, error) {
+	if err := f.record("SendMessage", params.ChatID.ID, params); err != nil {
+		return nil, err
+	}
+	msg := f.newMessage(params.ChatID.ID)
+	msg.Text = params.Text
+	return &msg, nil
+}
+
+// GetMe returns the fake bot user.
+func (f *FakeBot) GetMe(ctx context.Context) (*telego.User, error) {
+	if err := f.record("GetMe", 0, nil); err != nil {
+		return nil, err
+	}
+	me := f.me
+	return &me, nil
+}
+
+// CopyMessage records the call and returns the ID of the copy.
+func (f *FakeBot) CopyMessage(ctx context.Context, params *telego.CopyMessageParams) (*telego.MessageID, error) {
+	if err := f.record("CopyMessage", params.ChatID.ID, params); err != nil {
+		return nil, err
+	}
+	msg := f.newMessage(params.ChatID.ID)
+	return &telego.MessageID{MessageID: msg.MessageID}, nil
+}
+
+// CopyMessages records the call and returns the IDs of the copies.
+func (f *FakeBot) CopyMessages(ctx context.Context, params *telego.CopyMessagesParams) ([]telego.MessageID, error) {
+	if err := f.record("CopyMessages", params.ChatID.ID, params); err != nil {
+		return nil, err
+	}
+	ids := make([]telego.MessageID, len(params.MessageIDs))
+	for i := range ids {
+		ids[i] = telego.MessageID{MessageID: f.newMessage(params.ChatID.ID).MessageID}
+	}
+	return ids, nil
+}
+
+// SetMyCommands records the call.
+func (f *FakeBot) SetMyCommands(ctx context.Context, params *telego.SetMyCommandsParams) error {
+	return f.record("SetMyCommands", 0, params)
+}
+
+// DeleteMyCommands records the call.
+func (f *FakeBot) DeleteMyCommands(ctx context.Context, params *telego.DeleteMyCommandsParams) error {
+	return f.record("DeleteMyCommands", 0, params)
+}
+
+// GetWebhookInfo records the call and reports that no webhook is set.
+func (f *FakeBot) GetWebhookInfo(ctx context.Context) (*telego.WebhookInfo, error) {
+	if err := f.record("GetWebhookInfo", 0, nil); err != nil {
+		return nil, err
+	}
+	return &telego.WebhookInfo{}, nil
+}
+
+// GetChat records the call and returns the chat without a discussion group.
+func (f *FakeBot) GetChat(ctx context.Context, params *telego.GetChatParams) (*telego.ChatFullInfo, error) {
+	if err := f.record("GetChat", params.ChatID.ID, params); err != nil {
+		return nil, err
+	}
+	return &telego.ChatFullInfo{ID: params.ChatID.ID}, nil
+}
+
+// PinChatMessage records the call.
+func (f *FakeBot) PinChatMessage(ctx context.Context, params *telego.PinChatMessageParams) error {
+	return f.record("PinChatMessage", params.ChatID.ID, params)
+}
+
+// UnpinChatMessage records the call.
+func (f *FakeBot) UnpinChatMessage(ctx context.Context, params *telego.UnpinChatMessageParams) error {
+	return f.record("UnpinChatMessage", params.ChatID.ID, params)
+}
+
+// LeaveChat records the call.
+func (f *FakeBot) LeaveChat(ctx context.Context, params *telego.LeaveChatParams) error {
+	return f.record("LeaveChat", params.ChatID.ID, params)
+}
+
+// SetMessageReaction records the call.
+func (f *FakeBot) SetMessageReaction(ctx context.Context, params *telego.SetMessageReactionParams) error {
+	return f.record("SetMessageReaction", params.ChatID.ID, params)
+}
+
+// AnswerCallbackQuery records the call.
+func (f *FakeBot) AnswerCallbackQuery(ctx context.Context, params *telego.AnswerCallbackQueryParams) error {
+	return f.record("AnswerCallbackQuery", 0, params)
+}
+
+// SendMediaGroup records the call and returns one sent message per media item.
+func (f *FakeBot) SendMediaGroup(ctx context.Context, params *telego.SendMediaGroupParams) ([]telego.Message, error) {
+	if err := f.record("SendMediaGroup", params.ChatID.ID, params); err != nil {
+		return nil, err
+	}
+	msgs := make([]telego.Message, len(params.Media))
+	for i := range msgs {
+		msgs[i] = f.newMessage(params.ChatID.ID)
+	}
+	return msgs, nil
+}
+
+// GetChatMember records the call and returns the user's configured status (member by default).
+func (f *FakeBot) GetChatMember(ctx context.Context, params *telego.GetChatMemberParams) (telego.ChatMember, error) {
+	if err := f.record("GetChatMember", params.ChatID.ID, params); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	status, ok := f.memberStatus[params.UserID]
+	if !ok {
+		status = f.defaultStatus
+	}
+	f.mu.Unlock()
+
+	user := telego.User{ID: params.UserID}
+	switch status {
+	case telego.MemberStatusCreator:
+		return &telego.ChatMemberOwner{Status: status, User: user}, nil
+	case telego.MemberStatusAdministrator:
+		return &telego.ChatMemberAdministrator{Status: status, User: user}, nil
+	case telego.MemberStatusLeft:
+		return &telego.ChatMemberLeft{Status: status, User: user}, nil
+	case telego.MemberStatusBanned:
+		return &telego.ChatMemberBanned{Status: status, User: user}, nil
+	default:
+		return &telego.ChatMemberMember{Status: status, User: user}, nil
+	}
+}
+
+// SendPhoto records the call and returns a sent message.
+func (f *FakeBot) SendPhoto(ctx context.Context, params *telego.SendPhotoParams) (*telego.Message, error) {
+	if err := f.record("SendPhoto", params.ChatID.ID, params); err != nil {
+		return nil, err
+	}
+	msg := f.newMessage(params.ChatID.ID)
+	msg.Caption = params.Caption
+	return &msg, nil
+}
+
+// DeleteMessage records the call.
+func (f *FakeBot) DeleteMessage(ctx context.Context, params *telego.DeleteMessageParams) error {
+	return f.record("DeleteMessage", params.ChatID.ID, params)
+}
+
+// SendVideo records the call and returns a sent message.
+func (f *FakeBot) SendVideo(ctx context.Context, params *telego.SendVideoParams) (*telego.Message, error) {
+	if err := f.record("SendVideo", params.ChatID.ID, params); err != nil {
+		return nil, err
+	}
+	msg := f.newMessage(params.ChatID.ID)
+	msg.Caption = params.Caption
+	return &msg, nil
+}
+
+// SendAnimation records the call and returns a sent message.
+func (f *FakeBot) SendAnimation(ctx context.Context, params *telego.SendAnimationParams) (*telego.Message, error) {
+	if err := f.record("SendAnimation", params.ChatID.ID, params); err != nil {
+		return nil, err
+	}
+	msg := f.newMessage(params.ChatID.ID)
+	msg.Caption = params.Caption
+	return &msg, nil
+}
+
+// SendDocument records the call and returns a sent message.
+func (f *FakeBot) SendDocument(ctx context.Context, params *telego.SendDocumentParams) (*telego.Message, error) {
+	if err := f.record("SendDocument", params.ChatID.ID, params); err != nil {
+		return nil, err
+	}
+	msg := f.newMessage(params.ChatID.ID)
+	msg.Caption = params.Caption
+	return &msg, nil
+}
+
+// EditMessageText records the call and returns the edited message.
+func (f *FakeBot) EditMessageText(ctx context.Context, params *telego.EditMessageTextParams) (*telego.Message, error) {
+	if err := f.record("EditMessageText", params.ChatID.ID, params); err != nil {
+		return nil, err
+	}
+	return &telego.Message{MessageID: params.MessageID, Chat: telego.Chat{ID: params.ChatID.ID}, Text: params.Text}, nil
+}
+
+// EditMessageReplyMarkup records the call and returns the edited message.
+func (f *FakeBot) EditMessageReplyMarkup(ctx context.Context, params *telego.EditMessageReplyMarkupParams) (*telego.Message, error) {
+	if err := f.record("EditMessageReplyMarkup", params.ChatID.ID, params); err != nil {
+		return nil, err
+	}
+	return &telego.Message{MessageID: params.MessageID, Chat: telego.Chat{ID: params.ChatID.ID}, ReplyMarkup: params.ReplyMarkup}, nil
+}
@@ -0,0 +1,148 @@
+package telegoapitest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mymmrac/telego"
+)
+
+// DispatchFunc processes a single update, the way the bot's update loop would.
+type DispatchFunc func(ctx context.Context, update telego.Update)
+
+// Harness injects synthetic updates into a dispatcher, assigning update and message
+// IDs like Telegram does. Tests use it together with FakeBot to run whole flows
+// (e.g. suggest → review → publish) and then inspect the recorded Bot API calls.
+type Harness struct {
+	Bot      *FakeBot
+	dispatch DispatchFunc
+
+	mu            sync.Mutex
+	lastUpdateID  int
+	lastMessageID int
+	lastQueryID   int
+}
+
+// NewHarness creates a harness that passes injected updates to dispatch.
+func NewHarness(bot *FakeBot, dispatch DispatchFunc) *Harness {
+	return &Harness{Bot: bot, dispatch: dispatch, lastMessageID: 1000}
+}
+
+// User returns a private-chat user for use in injected updates.
+func User(id int64, username string) telego.User {
+	return telego.User{ID: id, FirstName: username, Username: username, LanguageCode: "en"}
+}
+
+// Inject passes an update to the dispatcher, filling in its update ID, and waits for it to be processed.
+func (h *Harness) Inject(ctx context.Context, update telego.Update) telego.Update {
+	h.mu.Lock()
+	h.lastUpdateID++
+	update.UpdateID = h.lastUpdateID
+	h.mu.Unlock()
+
+	h.dispatch(ctx, update)
+	return update
+}
+
+// SendText injects a text message (or a command such as "/suggest") from user in their private chat.
+func (h *Harness) SendText(ctx context.Context, user telego.User, text string) telego.Update {
+	msg := h.newMessage(user)
+	msg.Text = text
+	if strings.HasPrefix(text, "/") {
+		command := strings.Fields(text)[0]
+		msg.Entities = []telego.MessageEntity{{Type: telego.EntityTypeBotCommand, Offset: 0, Length: len(command)}}
+	}
+	return h.Inject(ctx, telego.Update{Message: &msg})
+}
+
+// SendPhoto injects a photo with an optional caption from user in their private chat.
+func (h *Harness) SendPhoto(ctx context.Context, user telego.User, fileID, caption string) telego.Update {
+	msg := h.newMessage(user)
+	msg.Photo = []telego.PhotoSize{{FileID: fileID, FileUniqueID: fileID, Width: 1280, Height: 720}}
+	msg.Caption = caption
+	return h.Inject(ctx, telego.Update{Message: &msg})
+}
+
+// SendVideo injects a video with an optional caption from user in their private chat.
+func (h *Harness) SendVideo(ctx context.Context, user telego.User, fileID, caption string) telego.Update {
+	msg := h.newMessage(user)
+	msg.Video = &telego.Video{FileID: fileID, FileUniqueID: fileID, Width: 1280, Height: 720, Duration: 10}
+	msg.Caption = caption
+	return h.Inject(ctx, telego.Update{Message: &msg})
+}
+
+// SendAnimation injects a GIF with an optional caption from user in their private chat. Like
+// Telegram, it sets both the animation and the document of the message.
+func (h *Harness) SendAnimation(ctx context.Context, user telego.User, fileID, caption string) telego.Update {
+	msg := h.newMessage(user)
+	msg.Animation = &telego.Animation{FileID: fileID, FileUniqueID: fileID, Width: 480, Height: 270, Duration: 3}
+	msg.Document = &telego.Document{FileID: fileID, FileUniqueID: fileID, MimeType: "video/mp4"}
+	msg.Caption = caption
+	return h.Inject(ctx, telego.Update{Message: &msg})
+}
+
+// PressButton injects a callback query from user pressing a button with the given data on message.
+func (h *Harness) PressButton(ctx context.Context, user telego.User, message *telego.Message, data string) telego.Update {
+	h.mu.Lock()
+	h.lastQueryID++
+	queryID := fmt.Sprintf("query-%d", h.lastQueryID)
+	h.mu.Unlock()
+
+	query := telego.CallbackQuery{
+		ID:   queryID,
+		From: user,
+		Data: data,
+	}
+	if message != nil {
+		query.Message = message
+	}
+	return h.Inject(ctx, telego.Update{CallbackQuery: &query})
+}
+
+// newMessage returns a private-chat message from user with the next message ID.
+func (h *Harness) newMessage(user telego.User) telego.Message {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastMessageID++
+	from := user
+	return telego.Message{
+		MessageID: h.lastMessageID,
+		Date:      time.Now().Unix(),
+		Chat:      telego.Chat{ID: user.ID, Type: telego.ChatTypePrivate},
+		From:      &from,
+	}
+}
+
+// ButtonData returns the callback data of the first inline button whose data contains fragment,
+// searching the reply markup of a recorded SendMessage, SendPhoto, SendAnimation or
+// EditMessageReplyMarkup call.
+func ButtonData(call Call, fragment string) (string, bool) {
+	var markup telego.ReplyMarkup
+	switch params := call.Params.(type) {
+	case *telego.SendMessageParams:
+		markup = params.ReplyMarkup
+	case *telego.SendPhotoParams:
+		markup = params.ReplyMarkup
+	case *telego.SendAnimationParams:
+		markup = params.ReplyMarkup
+	case *telego.SendVideoParams:
+		markup = params.ReplyMarkup
+	case *telego.EditMessageReplyMarkupParams:
+		markup = params.ReplyMarkup
+	}
+	keyboard, ok := markup.(*telego.InlineKeyboardMarkup)
+	if !ok || keyboard == nil {
+		return "", false
+	}
+	for _, row := range keyboard.InlineKeyboard {
+		for _, button := range row {
+			if strings.Contains(button.CallbackData, fragment) {
+				return button.CallbackData, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/mymmrac/telego"
+)
+
+// EscapeHTML escapes text for Telegram HTML formatting.
+func EscapeHTML(s string) string {
+	return html.EscapeString(s)
+}
+
+// EntitiesToHTML converts text with Telegram message entities to Telegram HTML, so formatted
+// text can be stored and sent again later. Entity offsets are in UTF-16 code units, as in the
+// Bot API. Entities Telegram detects by itself (links, mentions, hashtags, ...) are kept as plain text.
+func EntitiesToHTML(text string, entities []telego.MessageEntity) string {
+	units := utf16.Encode([]rune(text))
+
+	formatted := make([]telego.MessageEntity, 0, len(entities))
+	for _, entity := range entities {
+		if _, ok := htmlTags(entity); ok && entity.Length > 0 && entity.Offset >= 0 && entity.Offset < len(units) {
+			formatted = append(formatted, entity)
+		}
+	}
+	// Outer entities first, so the tags nest
+	sort.SliceStable(formatted, func(i, j int) bool {
+		if formatted[i].Offset != formatted[j].Offset {
+			return formatted[i].Offset < formatted[j].Offset
+		}
+		return formatted[i].Length > formatted[j].Length
+	})
+
+	type openTag struct {
+		end   int
+		close string
+	}
+	var (
+		result strings.Builder
+		stack  []openTag
+		next   int
+	)
+	for i := 0; i <= len(units); {
+		for len(stack) > 0 && stack[len(stack)-1].end <= i {
+			result.WriteString(stack[len(stack)-1].close)
+			stack = stack[:len(stack)-1]
+		}
+		if i == len(units) {
+			break
+		}
+		for next < len(formatted) && formatted[next].Offset <= i {
+			entity := formatted[next]
+			next++
+			end := min(entity.Offset+entity.Length, len(units))
+			if len(stack) > 0 {
+				end = min(end, stack[len(stack)-1].end) // Overlapping entities are cut to nest
+			}
+			tags, _ := htmlTags(entity)
+			result.WriteString(tags[0])
+			stack = append(stack, openTag{end: end, close: tags[1]})
+		}
+
+		size := 1
+		if utf16.IsSurrogate(rune(units[i])) && i+1 < len(units) {
+			size = 2
+		}
+		result.WriteString(html.EscapeString(string(utf16.Decode(units[i : i+size]))))
+		i += size
+	}
+	return result.String()
+}
+
+// htmlTags returns the opening and closing HTML tags of a formatting entity.
+func htmlTags(entity telego.MessageEntity) ([2]string, bool) {
+	switch entity.Type {
+	case telego.EntityTypeBold:
+		return [2]string{"<b>", "</b>"}, true
+	case telego.EntityTypeItalic:
+		return [2]string{"<i>", "</i>"}, true
+	case telego.EntityTypeUnderline:
+		return [2]string{"<u>", "</u>"}, true
+	case telego.EntityTypeStrikethrough:
+		return [2]string{"<s>", "</s>"}, true
+	case telego.EntityTypeSpoiler:
+		return [2]string{"<tg-spoiler>", "</tg-spoiler>"}, true
+	case telego.EntityTypeCode:
+		return [2]string{"<code>", "</code>"}, true
+	case telego.EntityTypePre:
+		if entity.Language != "" {
+			return [2]string{`<pre><code class="language-` + html.EscapeString(entity.Language) + `">`, "</code></pre>"}, true
+		}
+		return [2]string{"<pre>", "</pre>"}, true
+	case telego.EntityTypeBlockquote:
+		return [2]string{"<blockquote>", "</blockquote>"}, true
+	case telego.EntityTypeExpandableBlockquote:
+		return [2]string{"<blockquote expandable>", "</blockquote>"}, true
+	case telego.EntityTypeTextLink:
+		return [2]string{`<a href="` + html.EscapeString(entity.URL) + `">`, "</a>"}, true
+	case telego.EntityTypeTextMention:
+		if entity.User == nil {
+			return [2]string{}, false
+		}
+		return [2]string{`<a href="tg://user?id=` + strconv.FormatInt(entity.User.ID, 10) + `">`, "</a>"}, true
+	case telego.EntityTypeCustomEmoji:
+		return [2]string{`<tg-emoji emoji-id="` + html.EscapeString(entity.CustomEmojiID) + `">`, "</tg-emoji>"}, true
+	default:
+		return [2]string{}, false
+	}
+}
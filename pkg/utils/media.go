@@ -0,0 +1,21 @@
+package utils
+
+import "github.com/mymmrac/telego"
+
+// MediaFileID returns the file ID of the photo (largest size), video, GIF or document of a
+// message, or "" if it has none.
+func MediaFileID(message *telego.Message) string {
+	switch {
+	case message == nil:
+		return ""
+	case len(message.Photo) > 0:
+		return message.Photo[len(message.Photo)-1].FileID
+	case message.Video != nil:
+		return message.Video.FileID
+	case message.Animation != nil:
+		return message.Animation.FileID
+	case message.Document != nil:
+		return message.Document.FileID
+	}
+	return ""
+}
@@ -0,0 +1,26 @@
+package utils
+
+import "time"
+
+// DisplayTimeLayout is the layout of user-facing times, e.g. "2025-04-01 18:30 MSK".
+const DisplayTimeLayout = "2006-01-02 15:04 MST"
+
+// FormatTime formats a user-facing time in the given time zone; nil means UTC.
+func FormatTime(t time.Time, loc *time.Location) string {
+	return t.In(orUTC(loc)).Format(DisplayTimeLayout)
+}
+
+// StartOfDay returns midnight of the day t falls on in the given time zone; nil means UTC.
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	loc = orUTC(loc)
+	year, month, day := t.In(loc).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// orUTC returns loc, or UTC if it is nil.
+func orUTC(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.UTC
+	}
+	return loc
+}
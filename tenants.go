@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"vrcmemes-bot/internal/cache"
+	"vrcmemes-bot/internal/channelrights"
+	"vrcmemes-bot/internal/config"
+	"vrcmemes-bot/internal/database"
+	"vrcmemes-bot/internal/database/models"
+	"vrcmemes-bot/internal/debugmode"
+	"vrcmemes-bot/internal/engagement"
+	"vrcmemes-bot/internal/errbudget"
+	"vrcmemes-bot/internal/footers"
+	"vrcmemes-bot/internal/incidents"
+	"vrcmemes-bot/internal/mediagroups"
+	"vrcmemes-bot/internal/outbox"
+	"vrcmemes-bot/internal/publisher"
+	"vrcmemes-bot/internal/suggestions"
+	"vrcmemes-bot/internal/tenants"
+	telegoapi "vrcmemes-bot/pkg/telegoapi"
+
+	telegoBot "vrcmemes-bot/bot"
+
+	telego "github.com/mymmrac/telego"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// tenantComponents holds what the channels connected in multi-tenant mode share with the primary
+// channel: the Telegram bot, the database, users and the cache.
+type tenantComponents struct {
+	cfg            *config.Config
+	db             *mongo.Database
+	bot            *telego.Bot
+	updatesChan    <-chan telego.Update // Only to satisfy BotDeps: tenant bots aren't started
+	registry       *tenants.Registry    // Enforces the suggestion quotas
+	postLogger     database.PostLogger
+	userRepo       database.UserRepository
+	membershipRepo database.MembershipRepository
+	appCache       cache.Cache
+	notifier       *outbox.Outbox
+	errorBudget    *errbudget.Tracker
+	incidents      *incidents.Recorder
+	debug          *debugmode.Switch // Shared with the main bot; /debug and /trace stay with its super admins
+}
+
+// start creates the components serving a connected channel and starts their background jobs.
+// The channel gets its own admins, with its owner as super admin, suggestion queue, settings,
+// publish queue and cached data, and every document it stores is stamped with the channel. The
+// intake, preview and feedback chats and the staging directory belong to the primary channel
+// and aren't used.
+func (c tenantComponents) start(ctx context.Context, tenant models.Tenant) (*telegoBot.Bot, error) {
+	cfg := *c.cfg
+	cfg.ChannelID = tenant.ChannelID
+	cfg.SuperAdminIDs = []int64{tenant.OwnerID}
+	cfg.AlertChatID, cfg.IntakeChatID, cfg.PreviewChannelID = 0, 0, 0
+	cfg.FeedbackChatID, cfg.FeedbackTopicID = 0, 0
+	cfg.ChannelURL, cfg.RulesURL = "", ""
+
+	var botAPI telegoapi.BotAPI = c.bot
+	if cfg.DryRun {
+		botAPI = telegoapi.NewDryRunBot(c.bot, cfg.ChannelID)
+	}
+	tenantCache := cache.WithPrefix(c.appCache, fmt.Sprintf("tenant:%d:", tenant.ChannelID))
+	scope := database.NewChannelScope(tenant.ChannelID, false)
+	suggestionRepo := c.registry.LimitSuggestions(tenant.ChannelID, database.NewMongoSuggestionRepository(c.db).ForChannel(scope))
+	actionLogger := database.NewMongoLogger(c.db).ForChannel(scope)
+	feedbackRepo := database.NewChannelFeedbackRepository(c.db, scope)
+
+	mediaGroupMgr := mediagroups.NewManager()
+	mediaGroupMgr.SetLimits(cfg.MediaGroupDelay, cfg.MediaGroupMaxSize)
+	mediaGroupMgr.SetIdleDelay(cfg.MediaGroupIdleDelay)
+
+	publishQueue := publisher.NewQueue(cfg.PublishMinInterval)
+	publishQueue.SetStore(database.NewChannelPublicationRepository(c.db, scope))
+	channelRights := channelrights.NewChecker(botAPI, cfg.ChannelID, cfg.ChannelRightsCacheTTL)
+	channelRights.SetAlertFunc(channelRightsAlert(botAPI, &cfg))
+	publishQueue.SetPreflight(channelRights.Check)
+	publishQueue.SetFailureFunc(func(key string, err error) {
+		if telegoapi.IsForbidden(err) {
+			channelRights.Invalidate()
+		}
+	})
+
+	_, suggestionManager, messageHandler, err := setupBotComponents(
+		&cfg, c.bot, botAPI, suggestionRepo, actionLogger, c.postLogger, c.userRepo, feedbackRepo, c.membershipRepo, mediaGroupMgr, publishQueue, tenantCache,
+	)
+	if err != nil {
+		return nil, err
+	}
+	settingsRepo := cache.NewSettingsRepository(database.NewChannelSettingsRepository(c.db, scope), tenantCache, cfg.SettingsCacheTTL)
+	messageHandler.SetSettingsRepository(settingsRepo)
+	suggestionManager.SetSettingsRepository(settingsRepo)
+	suggestionManager.SetFooterRotator(footers.NewRotator(cfg.HashtagFooters, settingsRepo))
+	messageHandler.SetDefaultLanguageShared(true)
+	messageHandler.SetChannelRights(channelRights)
+	messageHandler.SetCreditDefaults(cfg.CreditSuggesters, cfg.CreditForwardSource)
+	messageHandler.SetProtectContentDefault(cfg.ProtectContent)
+	contentPolicy, _ := suggestions.ParseContentPolicy(cfg.SuggestionContentTypes) // Validated by the config
+	messageHandler.SetContentPolicyDefault(contentPolicy)
+	messageHandler.ApplySetupSettings(ctx)
+	messageHandler.SetStatsRepository(database.NewChannelStatsRepository(c.db, scope))
+	rubricRepo := database.NewChannelRubricRepository(c.db, scope)
+	messageHandler.SetRubricRepository(rubricRepo)
+	suggestionManager.SetRubricRepository(rubricRepo)
+	engagementPrompter := engagement.NewPrompter(botAPI, cfg.ChannelID, rubricRepo)
+	messageHandler.SetEngagementPrompter(engagementPrompter)
+	suggestionManager.SetEngagementPrompter(engagementPrompter)
+	messageHandler.SetThrowbackRepository(database.NewThrowbackRepository(c.db))
+	messageHandler.SetExperimentRepository(database.NewChannelExperimentRepository(c.db, scope), cfg.CaptionTestWindow)
+	messageHandler.SetPinRepository(database.NewChannelPinRepository(c.db, scope))
+	messageHandler.SetPermissionRepository(database.NewChannelPermissionRepository(c.db, scope))
+	wordFilterRepo := database.NewChannelWordFilterRepository(c.db, scope)
+	messageHandler.SetWordFilterRepository(wordFilterRepo)
+	suggestionManager.SetWordFilterRepository(wordFilterRepo)
+	suggestionManager.SetNotifier(c.notifier)
+	messageHandler.SetOutbox(c.notifier)
+
+	tenantBot, err := telegoBot.New(telegoBot.BotDeps{
+		Bot:           botAPI,
+		UpdatesChan:   c.updatesChan,
+		Debug:         c.debug,
+		ChannelID:     cfg.ChannelID,
+		CaptionProv:   messageHandler,
+		PostLogger:    c.postLogger,
+		HandlerProv:   messageHandler,
+		SuggestionMgr: suggestionManager,
+		CallbackProc:  messageHandler,
+		UserRepo:      c.userRepo,
+		ActionLogger:  actionLogger,
+		MediaGroupMgr: mediaGroupMgr,
+		Handler:       messageHandler,
+		PublishQueue:  publishQueue,
+		Timeouts: telegoBot.UpdateTimeouts{
+			Message:       cfg.MessageTimeout,
+			CallbackQuery: cfg.CallbackQueryTimeout,
+			ChatMember:    cfg.ChatMemberTimeout,
+			MediaGroup:    cfg.MediaGroupTimeout,
+		},
+		ErrorBudget: c.errorBudget,
+		Incidents:   c.incidents,
+		Groups:      groupPolicy(&cfg),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the bot of channel %d: %w", tenant.ChannelID, err)
+	}
+
+	suggestionManager.StartReviewSessionJanitor(ctx)
+	suggestionManager.StartQuietHoursSummaries(ctx)
+	suggestionManager.StartAssignmentReassigner(ctx)
+	suggestionManager.StartExpiryJanitor(ctx)
+	messageHandler.StartCaptionExperimentMeasurements(ctx)
+	messageHandler.StartPinScheduler(ctx, botAPI)
+	publishQueue.Start(ctx)
+	return tenantBot, nil
+}